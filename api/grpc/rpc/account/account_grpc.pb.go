@@ -22,11 +22,20 @@ const _ = grpc.SupportPackageIsVersion7
 type AccountClient interface {
 	Signup(ctx context.Context, in *SignupRequest, opts ...grpc.CallOption) (*rpc.UserResponse, error)
 	SendConfirmUser(ctx context.Context, in *SendConfirmRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
-	ConfirmUser(ctx context.Context, in *ConfirmUserRequest, opts ...grpc.CallOption) (*rpc.BearerResponse, error)
+	ConfirmUser(ctx context.Context, in *ConfirmUserRequest, opts ...grpc.CallOption) (*rpc.UserResponse, error)
 	Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*rpc.UserResponse, error)
+	VerifyMFA(ctx context.Context, in *VerifyMFARequest, opts ...grpc.CallOption) (*rpc.UserResponse, error)
+	ChangeExpiredPassword(ctx context.Context, in *ChangeExpiredPasswordRequest, opts ...grpc.CallOption) (*rpc.UserResponse, error)
+	BeginWebAuthnLogin(ctx context.Context, in *BeginWebAuthnLoginRequest, opts ...grpc.CallOption) (*rpc.WebAuthnResponse, error)
+	FinishWebAuthnLogin(ctx context.Context, in *FinishWebAuthnLoginRequest, opts ...grpc.CallOption) (*rpc.BearerResponse, error)
+	SendMagicLink(ctx context.Context, in *MagicLinkRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ConfirmMagicLink(ctx context.Context, in *ConfirmMagicLinkRequest, opts ...grpc.CallOption) (*rpc.UserResponse, error)
+	SendPhoneLogin(ctx context.Context, in *PhoneLoginRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	PhoneLogin(ctx context.Context, in *ConfirmPhoneLoginRequest, opts ...grpc.CallOption) (*rpc.UserResponse, error)
 	Logout(ctx context.Context, in *LogoutRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	SendResetPassword(ctx context.Context, in *ResetPasswordRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
-	ConfirmResetPassword(ctx context.Context, in *ConfirmPasswordRequest, opts ...grpc.CallOption) (*rpc.BearerResponse, error)
+	ConfirmResetPassword(ctx context.Context, in *ConfirmPasswordRequest, opts ...grpc.CallOption) (*rpc.UserResponse, error)
+	ConfirmUnlock(ctx context.Context, in *ConfirmUnlockRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 }
 
 type accountClient struct {
@@ -55,8 +64,8 @@ func (c *accountClient) SendConfirmUser(ctx context.Context, in *SendConfirmRequ
 	return out, nil
 }
 
-func (c *accountClient) ConfirmUser(ctx context.Context, in *ConfirmUserRequest, opts ...grpc.CallOption) (*rpc.BearerResponse, error) {
-	out := new(rpc.BearerResponse)
+func (c *accountClient) ConfirmUser(ctx context.Context, in *ConfirmUserRequest, opts ...grpc.CallOption) (*rpc.UserResponse, error) {
+	out := new(rpc.UserResponse)
 	err := c.cc.Invoke(ctx, "/gothic.api.Account/ConfirmUser", in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -73,6 +82,78 @@ func (c *accountClient) Login(ctx context.Context, in *LoginRequest, opts ...grp
 	return out, nil
 }
 
+func (c *accountClient) VerifyMFA(ctx context.Context, in *VerifyMFARequest, opts ...grpc.CallOption) (*rpc.UserResponse, error) {
+	out := new(rpc.UserResponse)
+	err := c.cc.Invoke(ctx, "/gothic.api.Account/VerifyMFA", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountClient) ChangeExpiredPassword(ctx context.Context, in *ChangeExpiredPasswordRequest, opts ...grpc.CallOption) (*rpc.UserResponse, error) {
+	out := new(rpc.UserResponse)
+	err := c.cc.Invoke(ctx, "/gothic.api.Account/ChangeExpiredPassword", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountClient) BeginWebAuthnLogin(ctx context.Context, in *BeginWebAuthnLoginRequest, opts ...grpc.CallOption) (*rpc.WebAuthnResponse, error) {
+	out := new(rpc.WebAuthnResponse)
+	err := c.cc.Invoke(ctx, "/gothic.api.Account/BeginWebAuthnLogin", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountClient) FinishWebAuthnLogin(ctx context.Context, in *FinishWebAuthnLoginRequest, opts ...grpc.CallOption) (*rpc.BearerResponse, error) {
+	out := new(rpc.BearerResponse)
+	err := c.cc.Invoke(ctx, "/gothic.api.Account/FinishWebAuthnLogin", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountClient) SendMagicLink(ctx context.Context, in *MagicLinkRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/gothic.api.Account/SendMagicLink", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountClient) ConfirmMagicLink(ctx context.Context, in *ConfirmMagicLinkRequest, opts ...grpc.CallOption) (*rpc.UserResponse, error) {
+	out := new(rpc.UserResponse)
+	err := c.cc.Invoke(ctx, "/gothic.api.Account/ConfirmMagicLink", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountClient) SendPhoneLogin(ctx context.Context, in *PhoneLoginRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/gothic.api.Account/SendPhoneLogin", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountClient) PhoneLogin(ctx context.Context, in *ConfirmPhoneLoginRequest, opts ...grpc.CallOption) (*rpc.UserResponse, error) {
+	out := new(rpc.UserResponse)
+	err := c.cc.Invoke(ctx, "/gothic.api.Account/PhoneLogin", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *accountClient) Logout(ctx context.Context, in *LogoutRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	out := new(emptypb.Empty)
 	err := c.cc.Invoke(ctx, "/gothic.api.Account/Logout", in, out, opts...)
@@ -91,8 +172,8 @@ func (c *accountClient) SendResetPassword(ctx context.Context, in *ResetPassword
 	return out, nil
 }
 
-func (c *accountClient) ConfirmResetPassword(ctx context.Context, in *ConfirmPasswordRequest, opts ...grpc.CallOption) (*rpc.BearerResponse, error) {
-	out := new(rpc.BearerResponse)
+func (c *accountClient) ConfirmResetPassword(ctx context.Context, in *ConfirmPasswordRequest, opts ...grpc.CallOption) (*rpc.UserResponse, error) {
+	out := new(rpc.UserResponse)
 	err := c.cc.Invoke(ctx, "/gothic.api.Account/ConfirmResetPassword", in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -100,17 +181,35 @@ func (c *accountClient) ConfirmResetPassword(ctx context.Context, in *ConfirmPas
 	return out, nil
 }
 
+func (c *accountClient) ConfirmUnlock(ctx context.Context, in *ConfirmUnlockRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/gothic.api.Account/ConfirmUnlock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AccountServer is the server API for Account service.
 // All implementations must embed UnimplementedAccountServer
 // for forward compatibility
 type AccountServer interface {
 	Signup(context.Context, *SignupRequest) (*rpc.UserResponse, error)
 	SendConfirmUser(context.Context, *SendConfirmRequest) (*emptypb.Empty, error)
-	ConfirmUser(context.Context, *ConfirmUserRequest) (*rpc.BearerResponse, error)
+	ConfirmUser(context.Context, *ConfirmUserRequest) (*rpc.UserResponse, error)
 	Login(context.Context, *LoginRequest) (*rpc.UserResponse, error)
+	VerifyMFA(context.Context, *VerifyMFARequest) (*rpc.UserResponse, error)
+	ChangeExpiredPassword(context.Context, *ChangeExpiredPasswordRequest) (*rpc.UserResponse, error)
+	BeginWebAuthnLogin(context.Context, *BeginWebAuthnLoginRequest) (*rpc.WebAuthnResponse, error)
+	FinishWebAuthnLogin(context.Context, *FinishWebAuthnLoginRequest) (*rpc.BearerResponse, error)
+	SendMagicLink(context.Context, *MagicLinkRequest) (*emptypb.Empty, error)
+	ConfirmMagicLink(context.Context, *ConfirmMagicLinkRequest) (*rpc.UserResponse, error)
+	SendPhoneLogin(context.Context, *PhoneLoginRequest) (*emptypb.Empty, error)
+	PhoneLogin(context.Context, *ConfirmPhoneLoginRequest) (*rpc.UserResponse, error)
 	Logout(context.Context, *LogoutRequest) (*emptypb.Empty, error)
 	SendResetPassword(context.Context, *ResetPasswordRequest) (*emptypb.Empty, error)
-	ConfirmResetPassword(context.Context, *ConfirmPasswordRequest) (*rpc.BearerResponse, error)
+	ConfirmResetPassword(context.Context, *ConfirmPasswordRequest) (*rpc.UserResponse, error)
+	ConfirmUnlock(context.Context, *ConfirmUnlockRequest) (*emptypb.Empty, error)
 	mustEmbedUnimplementedAccountServer()
 }
 
@@ -124,21 +223,48 @@ func (UnimplementedAccountServer) Signup(context.Context, *SignupRequest) (*rpc.
 func (UnimplementedAccountServer) SendConfirmUser(context.Context, *SendConfirmRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SendConfirmUser not implemented")
 }
-func (UnimplementedAccountServer) ConfirmUser(context.Context, *ConfirmUserRequest) (*rpc.BearerResponse, error) {
+func (UnimplementedAccountServer) ConfirmUser(context.Context, *ConfirmUserRequest) (*rpc.UserResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ConfirmUser not implemented")
 }
 func (UnimplementedAccountServer) Login(context.Context, *LoginRequest) (*rpc.UserResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Login not implemented")
 }
+func (UnimplementedAccountServer) VerifyMFA(context.Context, *VerifyMFARequest) (*rpc.UserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyMFA not implemented")
+}
+func (UnimplementedAccountServer) ChangeExpiredPassword(context.Context, *ChangeExpiredPasswordRequest) (*rpc.UserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChangeExpiredPassword not implemented")
+}
+func (UnimplementedAccountServer) BeginWebAuthnLogin(context.Context, *BeginWebAuthnLoginRequest) (*rpc.WebAuthnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BeginWebAuthnLogin not implemented")
+}
+func (UnimplementedAccountServer) FinishWebAuthnLogin(context.Context, *FinishWebAuthnLoginRequest) (*rpc.BearerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FinishWebAuthnLogin not implemented")
+}
+func (UnimplementedAccountServer) SendMagicLink(context.Context, *MagicLinkRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendMagicLink not implemented")
+}
+func (UnimplementedAccountServer) ConfirmMagicLink(context.Context, *ConfirmMagicLinkRequest) (*rpc.UserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmMagicLink not implemented")
+}
+func (UnimplementedAccountServer) SendPhoneLogin(context.Context, *PhoneLoginRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendPhoneLogin not implemented")
+}
+func (UnimplementedAccountServer) PhoneLogin(context.Context, *ConfirmPhoneLoginRequest) (*rpc.UserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PhoneLogin not implemented")
+}
 func (UnimplementedAccountServer) Logout(context.Context, *LogoutRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Logout not implemented")
 }
 func (UnimplementedAccountServer) SendResetPassword(context.Context, *ResetPasswordRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SendResetPassword not implemented")
 }
-func (UnimplementedAccountServer) ConfirmResetPassword(context.Context, *ConfirmPasswordRequest) (*rpc.BearerResponse, error) {
+func (UnimplementedAccountServer) ConfirmResetPassword(context.Context, *ConfirmPasswordRequest) (*rpc.UserResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ConfirmResetPassword not implemented")
 }
+func (UnimplementedAccountServer) ConfirmUnlock(context.Context, *ConfirmUnlockRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmUnlock not implemented")
+}
 func (UnimplementedAccountServer) mustEmbedUnimplementedAccountServer() {}
 
 // UnsafeAccountServer may be embedded to opt out of forward compatibility for this service.
@@ -224,6 +350,150 @@ func _Account_Login_Handler(srv interface{}, ctx context.Context, dec func(inter
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Account_VerifyMFA_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyMFARequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServer).VerifyMFA(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.Account/VerifyMFA",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServer).VerifyMFA(ctx, req.(*VerifyMFARequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Account_ChangeExpiredPassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangeExpiredPasswordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServer).ChangeExpiredPassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.Account/ChangeExpiredPassword",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServer).ChangeExpiredPassword(ctx, req.(*ChangeExpiredPasswordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Account_BeginWebAuthnLogin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BeginWebAuthnLoginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServer).BeginWebAuthnLogin(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.Account/BeginWebAuthnLogin",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServer).BeginWebAuthnLogin(ctx, req.(*BeginWebAuthnLoginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Account_FinishWebAuthnLogin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FinishWebAuthnLoginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServer).FinishWebAuthnLogin(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.Account/FinishWebAuthnLogin",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServer).FinishWebAuthnLogin(ctx, req.(*FinishWebAuthnLoginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Account_SendMagicLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MagicLinkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServer).SendMagicLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.Account/SendMagicLink",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServer).SendMagicLink(ctx, req.(*MagicLinkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Account_ConfirmMagicLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfirmMagicLinkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServer).ConfirmMagicLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.Account/ConfirmMagicLink",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServer).ConfirmMagicLink(ctx, req.(*ConfirmMagicLinkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Account_SendPhoneLogin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PhoneLoginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServer).SendPhoneLogin(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.Account/SendPhoneLogin",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServer).SendPhoneLogin(ctx, req.(*PhoneLoginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Account_PhoneLogin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfirmPhoneLoginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServer).PhoneLogin(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.Account/PhoneLogin",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServer).PhoneLogin(ctx, req.(*ConfirmPhoneLoginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Account_Logout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(LogoutRequest)
 	if err := dec(in); err != nil {
@@ -278,6 +548,24 @@ func _Account_ConfirmResetPassword_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Account_ConfirmUnlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfirmUnlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServer).ConfirmUnlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.Account/ConfirmUnlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServer).ConfirmUnlock(ctx, req.(*ConfirmUnlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Account_ServiceDesc is the grpc.ServiceDesc for Account service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -301,6 +589,38 @@ var Account_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Login",
 			Handler:    _Account_Login_Handler,
 		},
+		{
+			MethodName: "VerifyMFA",
+			Handler:    _Account_VerifyMFA_Handler,
+		},
+		{
+			MethodName: "ChangeExpiredPassword",
+			Handler:    _Account_ChangeExpiredPassword_Handler,
+		},
+		{
+			MethodName: "BeginWebAuthnLogin",
+			Handler:    _Account_BeginWebAuthnLogin_Handler,
+		},
+		{
+			MethodName: "FinishWebAuthnLogin",
+			Handler:    _Account_FinishWebAuthnLogin_Handler,
+		},
+		{
+			MethodName: "SendMagicLink",
+			Handler:    _Account_SendMagicLink_Handler,
+		},
+		{
+			MethodName: "ConfirmMagicLink",
+			Handler:    _Account_ConfirmMagicLink_Handler,
+		},
+		{
+			MethodName: "SendPhoneLogin",
+			Handler:    _Account_SendPhoneLogin_Handler,
+		},
+		{
+			MethodName: "PhoneLogin",
+			Handler:    _Account_PhoneLogin_Handler,
+		},
 		{
 			MethodName: "Logout",
 			Handler:    _Account_Logout_Handler,
@@ -313,6 +633,10 @@ var Account_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ConfirmResetPassword",
 			Handler:    _Account_ConfirmResetPassword_Handler,
 		},
+		{
+			MethodName: "ConfirmUnlock",
+			Handler:    _Account_ConfirmUnlock_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "account.proto",
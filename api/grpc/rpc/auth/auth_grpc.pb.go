@@ -8,6 +8,7 @@ import (
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
 )
 
 // This is a compile-time assertion to ensure that this generated file
@@ -20,6 +21,9 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type AuthClient interface {
 	RefreshBearerToken(ctx context.Context, in *RefreshTokenRequest, opts ...grpc.CallOption) (*rpc.BearerResponse, error)
+	GetJSONWebKeys(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*JSONWebKeySetResponse, error)
+	GetAuthorizationURL(ctx context.Context, in *AuthorizationURLRequest, opts ...grpc.CallOption) (*AuthorizationURLResponse, error)
+	AuthorizeUser(ctx context.Context, in *AuthorizeUserRequest, opts ...grpc.CallOption) (*rpc.UserResponse, error)
 }
 
 type authClient struct {
@@ -39,11 +43,41 @@ func (c *authClient) RefreshBearerToken(ctx context.Context, in *RefreshTokenReq
 	return out, nil
 }
 
+func (c *authClient) GetJSONWebKeys(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*JSONWebKeySetResponse, error) {
+	out := new(JSONWebKeySetResponse)
+	err := c.cc.Invoke(ctx, "/gothic.api.Auth/GetJSONWebKeys", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authClient) GetAuthorizationURL(ctx context.Context, in *AuthorizationURLRequest, opts ...grpc.CallOption) (*AuthorizationURLResponse, error) {
+	out := new(AuthorizationURLResponse)
+	err := c.cc.Invoke(ctx, "/gothic.api.Auth/GetAuthorizationURL", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authClient) AuthorizeUser(ctx context.Context, in *AuthorizeUserRequest, opts ...grpc.CallOption) (*rpc.UserResponse, error) {
+	out := new(rpc.UserResponse)
+	err := c.cc.Invoke(ctx, "/gothic.api.Auth/AuthorizeUser", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AuthServer is the server API for Auth service.
 // All implementations must embed UnimplementedAuthServer
 // for forward compatibility
 type AuthServer interface {
 	RefreshBearerToken(context.Context, *RefreshTokenRequest) (*rpc.BearerResponse, error)
+	GetJSONWebKeys(context.Context, *emptypb.Empty) (*JSONWebKeySetResponse, error)
+	GetAuthorizationURL(context.Context, *AuthorizationURLRequest) (*AuthorizationURLResponse, error)
+	AuthorizeUser(context.Context, *AuthorizeUserRequest) (*rpc.UserResponse, error)
 	mustEmbedUnimplementedAuthServer()
 }
 
@@ -54,6 +88,15 @@ type UnimplementedAuthServer struct {
 func (UnimplementedAuthServer) RefreshBearerToken(context.Context, *RefreshTokenRequest) (*rpc.BearerResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RefreshBearerToken not implemented")
 }
+func (UnimplementedAuthServer) GetJSONWebKeys(context.Context, *emptypb.Empty) (*JSONWebKeySetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetJSONWebKeys not implemented")
+}
+func (UnimplementedAuthServer) GetAuthorizationURL(context.Context, *AuthorizationURLRequest) (*AuthorizationURLResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAuthorizationURL not implemented")
+}
+func (UnimplementedAuthServer) AuthorizeUser(context.Context, *AuthorizeUserRequest) (*rpc.UserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AuthorizeUser not implemented")
+}
 func (UnimplementedAuthServer) mustEmbedUnimplementedAuthServer() {}
 
 // UnsafeAuthServer may be embedded to opt out of forward compatibility for this service.
@@ -85,6 +128,60 @@ func _Auth_RefreshBearerToken_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Auth_GetJSONWebKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).GetJSONWebKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.Auth/GetJSONWebKeys",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).GetJSONWebKeys(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_GetAuthorizationURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuthorizationURLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).GetAuthorizationURL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.Auth/GetAuthorizationURL",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).GetAuthorizationURL(ctx, req.(*AuthorizationURLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_AuthorizeUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuthorizeUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).AuthorizeUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.Auth/AuthorizeUser",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).AuthorizeUser(ctx, req.(*AuthorizeUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Auth_ServiceDesc is the grpc.ServiceDesc for Auth service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -96,6 +193,18 @@ var Auth_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RefreshBearerToken",
 			Handler:    _Auth_RefreshBearerToken_Handler,
 		},
+		{
+			MethodName: "GetJSONWebKeys",
+			Handler:    _Auth_GetJSONWebKeys_Handler,
+		},
+		{
+			MethodName: "GetAuthorizationURL",
+			Handler:    _Auth_GetAuthorizationURL_Handler,
+		},
+		{
+			MethodName: "AuthorizeUser",
+			Handler:    _Auth_AuthorizeUser_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "auth.proto",
@@ -24,6 +24,33 @@ type UserClient interface {
 	UpdateUser(ctx context.Context, in *UpdateUserRequest, opts ...grpc.CallOption) (*rpc.UserResponse, error)
 	SendConfirmUser(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	ChangePassword(ctx context.Context, in *ChangePasswordRequest, opts ...grpc.CallOption) (*rpc.BearerResponse, error)
+	SendChangePhone(ctx context.Context, in *ChangePhoneRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ConfirmChangePhone(ctx context.Context, in *ConfirmChangePhoneRequest, opts ...grpc.CallOption) (*rpc.UserResponse, error)
+	EnrollTOTP(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*EnrollTOTPResponse, error)
+	ConfirmTOTP(ctx context.Context, in *TOTPRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	DisableTOTP(ctx context.Context, in *TOTPRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	BeginWebAuthnRegistration(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*rpc.WebAuthnResponse, error)
+	FinishWebAuthnRegistration(ctx context.Context, in *FinishWebAuthnRegistrationRequest, opts ...grpc.CallOption) (*WebAuthnCredential, error)
+	ListWebAuthnCredentials(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*WebAuthnCredentialsResponse, error)
+	RenameWebAuthnCredential(ctx context.Context, in *RenameWebAuthnCredentialRequest, opts ...grpc.CallOption) (*WebAuthnCredential, error)
+	DeleteWebAuthnCredential(ctx context.Context, in *WebAuthnCredentialRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ListSessions(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*SessionsResponse, error)
+	RevokeSession(ctx context.Context, in *SessionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	RevokeOtherSessions(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*RevokeSessionsResponse, error)
+	CreatePersonalToken(ctx context.Context, in *CreatePersonalTokenRequest, opts ...grpc.CallOption) (*PersonalToken, error)
+	ListPersonalTokens(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*PersonalTokensResponse, error)
+	RevokePersonalToken(ctx context.Context, in *PersonalTokenRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	CreateOrg(ctx context.Context, in *CreateOrgRequest, opts ...grpc.CallOption) (*Org, error)
+	ListOrgs(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*OrgsResponse, error)
+	GetOrg(ctx context.Context, in *OrgRequest, opts ...grpc.CallOption) (*Org, error)
+	UpdateOrg(ctx context.Context, in *UpdateOrgRequest, opts ...grpc.CallOption) (*Org, error)
+	DeleteOrg(ctx context.Context, in *OrgRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ListOrgMembers(ctx context.Context, in *OrgRequest, opts ...grpc.CallOption) (*OrgMembersResponse, error)
+	ChangeOrgMemberRole(ctx context.Context, in *OrgMemberRoleRequest, opts ...grpc.CallOption) (*OrgMember, error)
+	RemoveOrgMember(ctx context.Context, in *OrgMemberRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	SendOrgInvite(ctx context.Context, in *OrgInviteRequest, opts ...grpc.CallOption) (*OrgInvite, error)
+	AcceptOrgInvite(ctx context.Context, in *AcceptOrgInviteRequest, opts ...grpc.CallOption) (*OrgMember, error)
+	SwitchOrg(ctx context.Context, in *SwitchOrgRequest, opts ...grpc.CallOption) (*rpc.BearerResponse, error)
 }
 
 type userClient struct {
@@ -70,6 +97,249 @@ func (c *userClient) ChangePassword(ctx context.Context, in *ChangePasswordReque
 	return out, nil
 }
 
+func (c *userClient) SendChangePhone(ctx context.Context, in *ChangePhoneRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/gothic.api.User/SendChangePhone", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) ConfirmChangePhone(ctx context.Context, in *ConfirmChangePhoneRequest, opts ...grpc.CallOption) (*rpc.UserResponse, error) {
+	out := new(rpc.UserResponse)
+	err := c.cc.Invoke(ctx, "/gothic.api.User/ConfirmChangePhone", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) EnrollTOTP(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*EnrollTOTPResponse, error) {
+	out := new(EnrollTOTPResponse)
+	err := c.cc.Invoke(ctx, "/gothic.api.User/EnrollTOTP", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) ConfirmTOTP(ctx context.Context, in *TOTPRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/gothic.api.User/ConfirmTOTP", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) DisableTOTP(ctx context.Context, in *TOTPRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/gothic.api.User/DisableTOTP", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) BeginWebAuthnRegistration(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*rpc.WebAuthnResponse, error) {
+	out := new(rpc.WebAuthnResponse)
+	err := c.cc.Invoke(ctx, "/gothic.api.User/BeginWebAuthnRegistration", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) FinishWebAuthnRegistration(ctx context.Context, in *FinishWebAuthnRegistrationRequest, opts ...grpc.CallOption) (*WebAuthnCredential, error) {
+	out := new(WebAuthnCredential)
+	err := c.cc.Invoke(ctx, "/gothic.api.User/FinishWebAuthnRegistration", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) ListWebAuthnCredentials(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*WebAuthnCredentialsResponse, error) {
+	out := new(WebAuthnCredentialsResponse)
+	err := c.cc.Invoke(ctx, "/gothic.api.User/ListWebAuthnCredentials", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) RenameWebAuthnCredential(ctx context.Context, in *RenameWebAuthnCredentialRequest, opts ...grpc.CallOption) (*WebAuthnCredential, error) {
+	out := new(WebAuthnCredential)
+	err := c.cc.Invoke(ctx, "/gothic.api.User/RenameWebAuthnCredential", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) DeleteWebAuthnCredential(ctx context.Context, in *WebAuthnCredentialRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/gothic.api.User/DeleteWebAuthnCredential", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) ListSessions(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*SessionsResponse, error) {
+	out := new(SessionsResponse)
+	err := c.cc.Invoke(ctx, "/gothic.api.User/ListSessions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) RevokeSession(ctx context.Context, in *SessionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/gothic.api.User/RevokeSession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) RevokeOtherSessions(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*RevokeSessionsResponse, error) {
+	out := new(RevokeSessionsResponse)
+	err := c.cc.Invoke(ctx, "/gothic.api.User/RevokeOtherSessions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) CreatePersonalToken(ctx context.Context, in *CreatePersonalTokenRequest, opts ...grpc.CallOption) (*PersonalToken, error) {
+	out := new(PersonalToken)
+	err := c.cc.Invoke(ctx, "/gothic.api.User/CreatePersonalToken", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) ListPersonalTokens(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*PersonalTokensResponse, error) {
+	out := new(PersonalTokensResponse)
+	err := c.cc.Invoke(ctx, "/gothic.api.User/ListPersonalTokens", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) RevokePersonalToken(ctx context.Context, in *PersonalTokenRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/gothic.api.User/RevokePersonalToken", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) CreateOrg(ctx context.Context, in *CreateOrgRequest, opts ...grpc.CallOption) (*Org, error) {
+	out := new(Org)
+	err := c.cc.Invoke(ctx, "/gothic.api.User/CreateOrg", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) ListOrgs(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*OrgsResponse, error) {
+	out := new(OrgsResponse)
+	err := c.cc.Invoke(ctx, "/gothic.api.User/ListOrgs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) GetOrg(ctx context.Context, in *OrgRequest, opts ...grpc.CallOption) (*Org, error) {
+	out := new(Org)
+	err := c.cc.Invoke(ctx, "/gothic.api.User/GetOrg", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) UpdateOrg(ctx context.Context, in *UpdateOrgRequest, opts ...grpc.CallOption) (*Org, error) {
+	out := new(Org)
+	err := c.cc.Invoke(ctx, "/gothic.api.User/UpdateOrg", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) DeleteOrg(ctx context.Context, in *OrgRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/gothic.api.User/DeleteOrg", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) ListOrgMembers(ctx context.Context, in *OrgRequest, opts ...grpc.CallOption) (*OrgMembersResponse, error) {
+	out := new(OrgMembersResponse)
+	err := c.cc.Invoke(ctx, "/gothic.api.User/ListOrgMembers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) ChangeOrgMemberRole(ctx context.Context, in *OrgMemberRoleRequest, opts ...grpc.CallOption) (*OrgMember, error) {
+	out := new(OrgMember)
+	err := c.cc.Invoke(ctx, "/gothic.api.User/ChangeOrgMemberRole", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) RemoveOrgMember(ctx context.Context, in *OrgMemberRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/gothic.api.User/RemoveOrgMember", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) SendOrgInvite(ctx context.Context, in *OrgInviteRequest, opts ...grpc.CallOption) (*OrgInvite, error) {
+	out := new(OrgInvite)
+	err := c.cc.Invoke(ctx, "/gothic.api.User/SendOrgInvite", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) AcceptOrgInvite(ctx context.Context, in *AcceptOrgInviteRequest, opts ...grpc.CallOption) (*OrgMember, error) {
+	out := new(OrgMember)
+	err := c.cc.Invoke(ctx, "/gothic.api.User/AcceptOrgInvite", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) SwitchOrg(ctx context.Context, in *SwitchOrgRequest, opts ...grpc.CallOption) (*rpc.BearerResponse, error) {
+	out := new(rpc.BearerResponse)
+	err := c.cc.Invoke(ctx, "/gothic.api.User/SwitchOrg", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // UserServer is the server API for User service.
 // All implementations must embed UnimplementedUserServer
 // for forward compatibility
@@ -78,6 +348,33 @@ type UserServer interface {
 	UpdateUser(context.Context, *UpdateUserRequest) (*rpc.UserResponse, error)
 	SendConfirmUser(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
 	ChangePassword(context.Context, *ChangePasswordRequest) (*rpc.BearerResponse, error)
+	SendChangePhone(context.Context, *ChangePhoneRequest) (*emptypb.Empty, error)
+	ConfirmChangePhone(context.Context, *ConfirmChangePhoneRequest) (*rpc.UserResponse, error)
+	EnrollTOTP(context.Context, *emptypb.Empty) (*EnrollTOTPResponse, error)
+	ConfirmTOTP(context.Context, *TOTPRequest) (*emptypb.Empty, error)
+	DisableTOTP(context.Context, *TOTPRequest) (*emptypb.Empty, error)
+	BeginWebAuthnRegistration(context.Context, *emptypb.Empty) (*rpc.WebAuthnResponse, error)
+	FinishWebAuthnRegistration(context.Context, *FinishWebAuthnRegistrationRequest) (*WebAuthnCredential, error)
+	ListWebAuthnCredentials(context.Context, *emptypb.Empty) (*WebAuthnCredentialsResponse, error)
+	RenameWebAuthnCredential(context.Context, *RenameWebAuthnCredentialRequest) (*WebAuthnCredential, error)
+	DeleteWebAuthnCredential(context.Context, *WebAuthnCredentialRequest) (*emptypb.Empty, error)
+	ListSessions(context.Context, *emptypb.Empty) (*SessionsResponse, error)
+	RevokeSession(context.Context, *SessionRequest) (*emptypb.Empty, error)
+	RevokeOtherSessions(context.Context, *emptypb.Empty) (*RevokeSessionsResponse, error)
+	CreatePersonalToken(context.Context, *CreatePersonalTokenRequest) (*PersonalToken, error)
+	ListPersonalTokens(context.Context, *emptypb.Empty) (*PersonalTokensResponse, error)
+	RevokePersonalToken(context.Context, *PersonalTokenRequest) (*emptypb.Empty, error)
+	CreateOrg(context.Context, *CreateOrgRequest) (*Org, error)
+	ListOrgs(context.Context, *emptypb.Empty) (*OrgsResponse, error)
+	GetOrg(context.Context, *OrgRequest) (*Org, error)
+	UpdateOrg(context.Context, *UpdateOrgRequest) (*Org, error)
+	DeleteOrg(context.Context, *OrgRequest) (*emptypb.Empty, error)
+	ListOrgMembers(context.Context, *OrgRequest) (*OrgMembersResponse, error)
+	ChangeOrgMemberRole(context.Context, *OrgMemberRoleRequest) (*OrgMember, error)
+	RemoveOrgMember(context.Context, *OrgMemberRequest) (*emptypb.Empty, error)
+	SendOrgInvite(context.Context, *OrgInviteRequest) (*OrgInvite, error)
+	AcceptOrgInvite(context.Context, *AcceptOrgInviteRequest) (*OrgMember, error)
+	SwitchOrg(context.Context, *SwitchOrgRequest) (*rpc.BearerResponse, error)
 	mustEmbedUnimplementedUserServer()
 }
 
@@ -97,6 +394,87 @@ func (UnimplementedUserServer) SendConfirmUser(context.Context, *emptypb.Empty)
 func (UnimplementedUserServer) ChangePassword(context.Context, *ChangePasswordRequest) (*rpc.BearerResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ChangePassword not implemented")
 }
+func (UnimplementedUserServer) SendChangePhone(context.Context, *ChangePhoneRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendChangePhone not implemented")
+}
+func (UnimplementedUserServer) ConfirmChangePhone(context.Context, *ConfirmChangePhoneRequest) (*rpc.UserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmChangePhone not implemented")
+}
+func (UnimplementedUserServer) EnrollTOTP(context.Context, *emptypb.Empty) (*EnrollTOTPResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnrollTOTP not implemented")
+}
+func (UnimplementedUserServer) ConfirmTOTP(context.Context, *TOTPRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmTOTP not implemented")
+}
+func (UnimplementedUserServer) DisableTOTP(context.Context, *TOTPRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DisableTOTP not implemented")
+}
+func (UnimplementedUserServer) BeginWebAuthnRegistration(context.Context, *emptypb.Empty) (*rpc.WebAuthnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BeginWebAuthnRegistration not implemented")
+}
+func (UnimplementedUserServer) FinishWebAuthnRegistration(context.Context, *FinishWebAuthnRegistrationRequest) (*WebAuthnCredential, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FinishWebAuthnRegistration not implemented")
+}
+func (UnimplementedUserServer) ListWebAuthnCredentials(context.Context, *emptypb.Empty) (*WebAuthnCredentialsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWebAuthnCredentials not implemented")
+}
+func (UnimplementedUserServer) RenameWebAuthnCredential(context.Context, *RenameWebAuthnCredentialRequest) (*WebAuthnCredential, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RenameWebAuthnCredential not implemented")
+}
+func (UnimplementedUserServer) DeleteWebAuthnCredential(context.Context, *WebAuthnCredentialRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteWebAuthnCredential not implemented")
+}
+func (UnimplementedUserServer) ListSessions(context.Context, *emptypb.Empty) (*SessionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSessions not implemented")
+}
+func (UnimplementedUserServer) RevokeSession(context.Context, *SessionRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeSession not implemented")
+}
+func (UnimplementedUserServer) RevokeOtherSessions(context.Context, *emptypb.Empty) (*RevokeSessionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeOtherSessions not implemented")
+}
+func (UnimplementedUserServer) CreatePersonalToken(context.Context, *CreatePersonalTokenRequest) (*PersonalToken, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePersonalToken not implemented")
+}
+func (UnimplementedUserServer) ListPersonalTokens(context.Context, *emptypb.Empty) (*PersonalTokensResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPersonalTokens not implemented")
+}
+func (UnimplementedUserServer) RevokePersonalToken(context.Context, *PersonalTokenRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokePersonalToken not implemented")
+}
+func (UnimplementedUserServer) CreateOrg(context.Context, *CreateOrgRequest) (*Org, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateOrg not implemented")
+}
+func (UnimplementedUserServer) ListOrgs(context.Context, *emptypb.Empty) (*OrgsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListOrgs not implemented")
+}
+func (UnimplementedUserServer) GetOrg(context.Context, *OrgRequest) (*Org, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrg not implemented")
+}
+func (UnimplementedUserServer) UpdateOrg(context.Context, *UpdateOrgRequest) (*Org, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateOrg not implemented")
+}
+func (UnimplementedUserServer) DeleteOrg(context.Context, *OrgRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteOrg not implemented")
+}
+func (UnimplementedUserServer) ListOrgMembers(context.Context, *OrgRequest) (*OrgMembersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListOrgMembers not implemented")
+}
+func (UnimplementedUserServer) ChangeOrgMemberRole(context.Context, *OrgMemberRoleRequest) (*OrgMember, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChangeOrgMemberRole not implemented")
+}
+func (UnimplementedUserServer) RemoveOrgMember(context.Context, *OrgMemberRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveOrgMember not implemented")
+}
+func (UnimplementedUserServer) SendOrgInvite(context.Context, *OrgInviteRequest) (*OrgInvite, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendOrgInvite not implemented")
+}
+func (UnimplementedUserServer) AcceptOrgInvite(context.Context, *AcceptOrgInviteRequest) (*OrgMember, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AcceptOrgInvite not implemented")
+}
+func (UnimplementedUserServer) SwitchOrg(context.Context, *SwitchOrgRequest) (*rpc.BearerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SwitchOrg not implemented")
+}
 func (UnimplementedUserServer) mustEmbedUnimplementedUserServer() {}
 
 // UnsafeUserServer may be embedded to opt out of forward compatibility for this service.
@@ -182,28 +560,622 @@ func _User_ChangePassword_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
-// User_ServiceDesc is the grpc.ServiceDesc for User service.
-// It's only intended for direct use with grpc.RegisterService,
-// and not to be introspected or modified (even as a copy)
-var User_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "gothic.api.User",
-	HandlerType: (*UserServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "GetUser",
-			Handler:    _User_GetUser_Handler,
-		},
-		{
-			MethodName: "UpdateUser",
-			Handler:    _User_UpdateUser_Handler,
-		},
-		{
-			MethodName: "SendConfirmUser",
-			Handler:    _User_SendConfirmUser_Handler,
-		},
-		{
-			MethodName: "ChangePassword",
-			Handler:    _User_ChangePassword_Handler,
+func _User_SendChangePhone_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangePhoneRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).SendChangePhone(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.User/SendChangePhone",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).SendChangePhone(ctx, req.(*ChangePhoneRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_ConfirmChangePhone_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfirmChangePhoneRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).ConfirmChangePhone(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.User/ConfirmChangePhone",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).ConfirmChangePhone(ctx, req.(*ConfirmChangePhoneRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_EnrollTOTP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).EnrollTOTP(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.User/EnrollTOTP",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).EnrollTOTP(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_ConfirmTOTP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TOTPRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).ConfirmTOTP(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.User/ConfirmTOTP",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).ConfirmTOTP(ctx, req.(*TOTPRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_DisableTOTP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TOTPRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).DisableTOTP(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.User/DisableTOTP",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).DisableTOTP(ctx, req.(*TOTPRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_BeginWebAuthnRegistration_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).BeginWebAuthnRegistration(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.User/BeginWebAuthnRegistration",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).BeginWebAuthnRegistration(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_FinishWebAuthnRegistration_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FinishWebAuthnRegistrationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).FinishWebAuthnRegistration(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.User/FinishWebAuthnRegistration",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).FinishWebAuthnRegistration(ctx, req.(*FinishWebAuthnRegistrationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_ListWebAuthnCredentials_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).ListWebAuthnCredentials(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.User/ListWebAuthnCredentials",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).ListWebAuthnCredentials(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_RenameWebAuthnCredential_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenameWebAuthnCredentialRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).RenameWebAuthnCredential(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.User/RenameWebAuthnCredential",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).RenameWebAuthnCredential(ctx, req.(*RenameWebAuthnCredentialRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_DeleteWebAuthnCredential_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WebAuthnCredentialRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).DeleteWebAuthnCredential(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.User/DeleteWebAuthnCredential",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).DeleteWebAuthnCredential(ctx, req.(*WebAuthnCredentialRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_ListSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).ListSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.User/ListSessions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).ListSessions(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_RevokeSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).RevokeSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.User/RevokeSession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).RevokeSession(ctx, req.(*SessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_RevokeOtherSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).RevokeOtherSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.User/RevokeOtherSessions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).RevokeOtherSessions(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_CreatePersonalToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreatePersonalTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).CreatePersonalToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.User/CreatePersonalToken",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).CreatePersonalToken(ctx, req.(*CreatePersonalTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_ListPersonalTokens_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).ListPersonalTokens(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.User/ListPersonalTokens",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).ListPersonalTokens(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_RevokePersonalToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PersonalTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).RevokePersonalToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.User/RevokePersonalToken",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).RevokePersonalToken(ctx, req.(*PersonalTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_CreateOrg_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateOrgRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).CreateOrg(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.User/CreateOrg",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).CreateOrg(ctx, req.(*CreateOrgRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_ListOrgs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).ListOrgs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.User/ListOrgs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).ListOrgs(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_GetOrg_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OrgRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).GetOrg(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.User/GetOrg",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).GetOrg(ctx, req.(*OrgRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_UpdateOrg_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateOrgRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).UpdateOrg(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.User/UpdateOrg",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).UpdateOrg(ctx, req.(*UpdateOrgRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_DeleteOrg_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OrgRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).DeleteOrg(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.User/DeleteOrg",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).DeleteOrg(ctx, req.(*OrgRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_ListOrgMembers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OrgRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).ListOrgMembers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.User/ListOrgMembers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).ListOrgMembers(ctx, req.(*OrgRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_ChangeOrgMemberRole_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OrgMemberRoleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).ChangeOrgMemberRole(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.User/ChangeOrgMemberRole",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).ChangeOrgMemberRole(ctx, req.(*OrgMemberRoleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_RemoveOrgMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OrgMemberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).RemoveOrgMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.User/RemoveOrgMember",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).RemoveOrgMember(ctx, req.(*OrgMemberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_SendOrgInvite_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OrgInviteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).SendOrgInvite(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.User/SendOrgInvite",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).SendOrgInvite(ctx, req.(*OrgInviteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_AcceptOrgInvite_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AcceptOrgInviteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).AcceptOrgInvite(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.User/AcceptOrgInvite",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).AcceptOrgInvite(ctx, req.(*AcceptOrgInviteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_SwitchOrg_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SwitchOrgRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).SwitchOrg(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gothic.api.User/SwitchOrg",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).SwitchOrg(ctx, req.(*SwitchOrgRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// User_ServiceDesc is the grpc.ServiceDesc for User service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var User_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gothic.api.User",
+	HandlerType: (*UserServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetUser",
+			Handler:    _User_GetUser_Handler,
+		},
+		{
+			MethodName: "UpdateUser",
+			Handler:    _User_UpdateUser_Handler,
+		},
+		{
+			MethodName: "SendConfirmUser",
+			Handler:    _User_SendConfirmUser_Handler,
+		},
+		{
+			MethodName: "ChangePassword",
+			Handler:    _User_ChangePassword_Handler,
+		},
+		{
+			MethodName: "SendChangePhone",
+			Handler:    _User_SendChangePhone_Handler,
+		},
+		{
+			MethodName: "ConfirmChangePhone",
+			Handler:    _User_ConfirmChangePhone_Handler,
+		},
+		{
+			MethodName: "EnrollTOTP",
+			Handler:    _User_EnrollTOTP_Handler,
+		},
+		{
+			MethodName: "ConfirmTOTP",
+			Handler:    _User_ConfirmTOTP_Handler,
+		},
+		{
+			MethodName: "DisableTOTP",
+			Handler:    _User_DisableTOTP_Handler,
+		},
+		{
+			MethodName: "BeginWebAuthnRegistration",
+			Handler:    _User_BeginWebAuthnRegistration_Handler,
+		},
+		{
+			MethodName: "FinishWebAuthnRegistration",
+			Handler:    _User_FinishWebAuthnRegistration_Handler,
+		},
+		{
+			MethodName: "ListWebAuthnCredentials",
+			Handler:    _User_ListWebAuthnCredentials_Handler,
+		},
+		{
+			MethodName: "RenameWebAuthnCredential",
+			Handler:    _User_RenameWebAuthnCredential_Handler,
+		},
+		{
+			MethodName: "DeleteWebAuthnCredential",
+			Handler:    _User_DeleteWebAuthnCredential_Handler,
+		},
+		{
+			MethodName: "ListSessions",
+			Handler:    _User_ListSessions_Handler,
+		},
+		{
+			MethodName: "RevokeSession",
+			Handler:    _User_RevokeSession_Handler,
+		},
+		{
+			MethodName: "RevokeOtherSessions",
+			Handler:    _User_RevokeOtherSessions_Handler,
+		},
+		{
+			MethodName: "CreatePersonalToken",
+			Handler:    _User_CreatePersonalToken_Handler,
+		},
+		{
+			MethodName: "ListPersonalTokens",
+			Handler:    _User_ListPersonalTokens_Handler,
+		},
+		{
+			MethodName: "RevokePersonalToken",
+			Handler:    _User_RevokePersonalToken_Handler,
+		},
+		{
+			MethodName: "CreateOrg",
+			Handler:    _User_CreateOrg_Handler,
+		},
+		{
+			MethodName: "ListOrgs",
+			Handler:    _User_ListOrgs_Handler,
+		},
+		{
+			MethodName: "GetOrg",
+			Handler:    _User_GetOrg_Handler,
+		},
+		{
+			MethodName: "UpdateOrg",
+			Handler:    _User_UpdateOrg_Handler,
+		},
+		{
+			MethodName: "DeleteOrg",
+			Handler:    _User_DeleteOrg_Handler,
+		},
+		{
+			MethodName: "ListOrgMembers",
+			Handler:    _User_ListOrgMembers_Handler,
+		},
+		{
+			MethodName: "ChangeOrgMemberRole",
+			Handler:    _User_ChangeOrgMemberRole_Handler,
+		},
+		{
+			MethodName: "RemoveOrgMember",
+			Handler:    _User_RemoveOrgMember_Handler,
+		},
+		{
+			MethodName: "SendOrgInvite",
+			Handler:    _User_SendOrgInvite_Handler,
+		},
+		{
+			MethodName: "AcceptOrgInvite",
+			Handler:    _User_AcceptOrgInvite_Handler,
+		},
+		{
+			MethodName: "SwitchOrg",
+			Handler:    _User_SwitchOrg_Handler,
 		},
 	},
 	Streams:  []grpc.StreamDesc{},
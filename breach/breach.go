@@ -0,0 +1,192 @@
+package breach
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrBreachedPassword is returned when a password was found in a breach.
+var ErrBreachedPassword = errors.New("password found in a data breach")
+
+// RangeLen is the length of the hash prefix of a range in a range dump.
+const RangeLen = 5
+
+// Load loads the breached password filter at path. The file may either be a
+// filter built with WriteTo, or a SHA-1 dump (e.g. from Have I Been Pwned)
+// which is read into a new filter. If path is a directory it is read as a
+// range dump (see ReadRangeDump). If path is empty Load returns nil.
+func Load(path string) (*Filter, error) {
+	if path == "" {
+		return nil, nil
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		return BuildRangeFilter(path, DefaultFalsePositiveRate)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	hdr := make([]byte, len(magic))
+	n, err := io.ReadFull(f, hdr)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	_, err = f.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+	if isFilter(hdr[:n]) {
+		return ReadFilter(f)
+	}
+	return BuildFilter(f, DefaultFalsePositiveRate)
+}
+
+// BuildFilter builds a new filter from a SHA-1 dump with a false positive rate of p.
+func BuildFilter(r io.ReadSeeker, p float64) (*Filter, error) {
+	return buildFilter(func(fn func([sha1.Size]byte) error) error {
+		_, err := r.Seek(0, io.SeekStart)
+		if err != nil {
+			return err
+		}
+		return ReadDump(r, fn)
+	}, p)
+}
+
+// BuildRangeFilter builds a new filter from the SHA-1 range dump in dir
+// with a false positive rate of p.
+func BuildRangeFilter(dir string, p float64) (*Filter, error) {
+	return buildFilter(func(fn func([sha1.Size]byte) error) error {
+		return ReadRangeDump(dir, fn)
+	}, p)
+}
+
+// buildFilter reads the dump twice, first to size the filter and then to fill it.
+func buildFilter(read func(fn func([sha1.Size]byte) error) error, p float64) (*Filter, error) {
+	var n uint64
+	err := read(func([sha1.Size]byte) error {
+		n++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	f := NewFilter(n, p)
+	err = read(func(sum [sha1.Size]byte) error {
+		f.Add(sum)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// ReadDump reads the SHA-1 hashes in a dump and calls fn for each one. Each
+// line of the dump is a hex encoded SHA-1 hash optionally followed by a colon
+// and a count (the format of the Have I Been Pwned pwned passwords downloads).
+func ReadDump(r io.Reader, fn func(sum [sha1.Size]byte) error) error {
+	return readHashes(r, nil, fn)
+}
+
+// ReadRange reads the SHA-1 hashes in a range of a range dump and calls fn
+// for each one. Each line of the range is the hex encoded suffix of a SHA-1
+// hash that starts with prefix, optionally followed by a colon and a count
+// (the format of the Have I Been Pwned range api).
+func ReadRange(r io.Reader, prefix string, fn func(sum [sha1.Size]byte) error) error {
+	if !isRange(prefix) {
+		return fmt.Errorf("invalid range: %s", prefix)
+	}
+	return readHashes(r, []byte(prefix), fn)
+}
+
+// ReadRangeDump reads the SHA-1 hashes in the range dump in dir and calls fn
+// for each one. A range dump has one file per range, named for the prefix of
+// the range (e.g. 5BAA6.txt), as written by the Have I Been Pwned downloader.
+// Files that are not named for a range are skipped.
+func ReadRangeDump(dir string, fn func(sum [sha1.Size]byte) error) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		name := e.Name()
+		prefix := strings.TrimSuffix(name, filepath.Ext(name))
+		if e.IsDir() || !isRange(prefix) {
+			continue
+		}
+		err = readRangeFile(filepath.Join(dir, name), prefix, fn)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readRangeFile(path, prefix string, fn func(sum [sha1.Size]byte) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	err = ReadRange(f, prefix, fn)
+	if err != nil {
+		return fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+func isRange(prefix string) bool {
+	if len(prefix) != RangeLen {
+		return false
+	}
+	for _, c := range prefix {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return false
+		}
+	}
+	return true
+}
+
+func readHashes(r io.Reader, prefix []byte, fn func(sum [sha1.Size]byte) error) error {
+	sc := bufio.NewScanner(r)
+	var line int
+	for sc.Scan() {
+		line++
+		b := bytes.TrimSpace(sc.Bytes())
+		if len(b) == 0 {
+			continue
+		}
+		if i := bytes.IndexByte(b, ':'); i >= 0 {
+			b = b[:i]
+		}
+		if len(prefix) > 0 {
+			b = append(append([]byte{}, prefix...), b...)
+		}
+		var sum [sha1.Size]byte
+		if len(b) != hex.EncodedLen(sha1.Size) {
+			return fmt.Errorf("invalid sha1 hash on line %d", line)
+		}
+		_, err := hex.Decode(sum[:], b)
+		if err != nil {
+			return fmt.Errorf("invalid sha1 hash on line %d: %w", line, err)
+		}
+		err = fn(sum)
+		if err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
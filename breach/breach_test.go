@@ -0,0 +1,176 @@
+package breach
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testDump  = "testdata/pwned.txt"
+	testRange = "testdata/range"
+)
+
+var breached = []string{"password", "123456", "qwerty"}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+	f, err := Load("")
+	assert.NoError(t, err)
+	assert.Nil(t, f)
+	_, err = Load("testdata/missing.txt")
+	assert.Error(t, err)
+	// dump
+	f, err = Load(testDump)
+	require.NoError(t, err)
+	for _, pw := range breached {
+		assert.True(t, f.Contains(pw))
+	}
+	assert.False(t, f.Contains("SXJAm7qJ4?3dH!aN8T3f5p!oNnpXbaRy#Gtx#8jG"))
+	// range dump
+	rf, err := Load(testRange)
+	require.NoError(t, err)
+	for _, pw := range breached {
+		assert.True(t, rf.Contains(pw))
+	}
+	// filter
+	path := filepath.Join(t.TempDir(), "breached.bloom")
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	_, err = f.WriteTo(file)
+	require.NoError(t, err)
+	err = file.Close()
+	require.NoError(t, err)
+	f2, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, f, f2)
+	// empty & bad files
+	empty := filepath.Join(t.TempDir(), "empty.txt")
+	err = os.WriteFile(empty, nil, 0600)
+	require.NoError(t, err)
+	f, err = Load(empty)
+	require.NoError(t, err)
+	assert.False(t, f.Contains("password"))
+	bad := filepath.Join(t.TempDir(), "bad.txt")
+	err = os.WriteFile(bad, []byte("password"), 0600)
+	require.NoError(t, err)
+	_, err = Load(bad)
+	assert.Error(t, err)
+}
+
+func TestBuildFilter(t *testing.T) {
+	t.Parallel()
+	b, err := os.ReadFile(testDump)
+	require.NoError(t, err)
+	f, err := BuildFilter(bytes.NewReader(b), 0.01)
+	require.NoError(t, err)
+	for _, pw := range breached {
+		assert.True(t, f.Contains(pw))
+	}
+	_, err = BuildFilter(strings.NewReader("bad"), 0.01)
+	assert.Error(t, err)
+}
+
+func TestReadDump(t *testing.T) {
+	t.Parallel()
+	b, err := os.ReadFile(testDump)
+	require.NoError(t, err)
+	var sums [][sha1.Size]byte
+	err = ReadDump(bytes.NewReader(b), func(sum [sha1.Size]byte) error {
+		sums = append(sums, sum)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, sums, len(breached))
+	for i, pw := range breached {
+		assert.Equal(t, sha1.Sum([]byte(pw)), sums[i])
+	}
+	// hashes without counts & lower case
+	const dump = "5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8\n"
+	err = ReadDump(strings.NewReader(dump), func(sum [sha1.Size]byte) error {
+		assert.Equal(t, sha1.Sum([]byte("password")), sum)
+		return nil
+	})
+	assert.NoError(t, err)
+	bad := []string{
+		"password",
+		"5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8X:1",
+		"ZBAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:1",
+	}
+	for _, test := range bad {
+		err = ReadDump(strings.NewReader(test), func([sha1.Size]byte) error {
+			return nil
+		})
+		assert.Error(t, err)
+	}
+	testErr := errors.New("test")
+	err = ReadDump(bytes.NewReader(b), func([sha1.Size]byte) error {
+		return testErr
+	})
+	assert.ErrorIs(t, err, testErr)
+}
+
+func TestBuildRangeFilter(t *testing.T) {
+	t.Parallel()
+	f, err := BuildRangeFilter(testRange, 0.01)
+	require.NoError(t, err)
+	for _, pw := range breached {
+		assert.True(t, f.Contains(pw))
+	}
+	_, err = BuildRangeFilter("testdata/missing", 0.01)
+	assert.Error(t, err)
+}
+
+func TestReadRange(t *testing.T) {
+	t.Parallel()
+	const rng = "1e4c9b93f3f0682250b6cf8331b7ee68fd8:1\n"
+	var sums [][sha1.Size]byte
+	err := ReadRange(strings.NewReader(rng), "5baa6", func(sum [sha1.Size]byte) error {
+		sums = append(sums, sum)
+		return nil
+	})
+	assert.NoError(t, err)
+	require.Len(t, sums, 1)
+	assert.Equal(t, sha1.Sum([]byte("password")), sums[0])
+	bad := []string{"", "5BAA", "5BAA6X", "ZBAA6"}
+	for _, prefix := range bad {
+		err = ReadRange(strings.NewReader(rng), prefix, func([sha1.Size]byte) error {
+			return nil
+		})
+		assert.Error(t, err)
+	}
+	// a full hash is not a suffix
+	err = ReadRange(strings.NewReader("5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:1"), "5BAA6",
+		func([sha1.Size]byte) error {
+			return nil
+		})
+	assert.Error(t, err)
+}
+
+func TestReadRangeDump(t *testing.T) {
+	t.Parallel()
+	var sums [][sha1.Size]byte
+	err := ReadRangeDump(testRange, func(sum [sha1.Size]byte) error {
+		sums = append(sums, sum)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, sums, len(breached))
+	for i, pw := range breached {
+		assert.Equal(t, sha1.Sum([]byte(pw)), sums[i])
+	}
+	dir := t.TempDir()
+	err = os.WriteFile(filepath.Join(dir, "5BAA6.txt"), []byte("password"), 0600)
+	require.NoError(t, err)
+	err = ReadRangeDump(dir, func([sha1.Size]byte) error {
+		return nil
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,183 @@
+package breach
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// DefaultFalsePositiveRate is the default false positive rate of a filter.
+const DefaultFalsePositiveRate = 0.001
+
+// magic identifies a breached password bloom filter file.
+var magic = [8]byte{'g', 'o', 't', 'h', 'b', 'l', 'm', 1}
+
+// ErrInvalidFilter is returned when a filter file cannot be read.
+var ErrInvalidFilter = errors.New("invalid breached password filter")
+
+const (
+	// headerSize is the size of the magic, k & m filter header.
+	headerSize = len(magic) + 4 + 8
+	// maxHashes is the largest number of hashes a filter can use.
+	maxHashes = 64
+	// maxBits is the largest filter that can be read (16 GiB).
+	maxBits = 1 << 37
+)
+
+// Filter is a bloom filter of breached password SHA-1 hashes. A password
+// that is not in the filter was definitely not breached, while a password
+// that is in the filter was breached with a small chance of false positive.
+type Filter struct {
+	k    uint32
+	m    uint64
+	bits []uint64
+}
+
+// NewFilter returns a new filter sized for n hashes with a false positive rate of p.
+func NewFilter(n uint64, p float64) *Filter {
+	if n < 1 {
+		n = 1
+	}
+	if p <= 0 || p >= 1 {
+		p = DefaultFalsePositiveRate
+	}
+	m := uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	k := uint32(math.Round(float64(m) / float64(n) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return newFilter(k, m)
+}
+
+func newFilter(k uint32, m uint64) *Filter {
+	// round up to a whole word
+	m = (m + 63) &^ 63
+	return &Filter{k: k, m: m, bits: make([]uint64, m/64)}
+}
+
+// Add adds a SHA-1 hash to the filter.
+func (f *Filter) Add(sum [sha1.Size]byte) {
+	h1, h2 := split(sum)
+	for i := uint64(0); i < uint64(f.k); i++ {
+		n := (h1 + i*h2) % f.m
+		f.bits[n/64] |= 1 << (n % 64)
+	}
+}
+
+// Has returns true if the SHA-1 hash is in the filter.
+func (f *Filter) Has(sum [sha1.Size]byte) bool {
+	h1, h2 := split(sum)
+	for i := uint64(0); i < uint64(f.k); i++ {
+		n := (h1 + i*h2) % f.m
+		if f.bits[n/64]&(1<<(n%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Contains returns true if the password is in the filter.
+func (f *Filter) Contains(password string) bool {
+	if f == nil {
+		return false
+	}
+	return f.Has(sha1.Sum([]byte(password)))
+}
+
+// split splits a hash into the two halves used for double hashing. SHA-1
+// hashes are uniformly distributed, so they do not need to be rehashed.
+func split(sum [sha1.Size]byte) (uint64, uint64) {
+	h1 := binary.BigEndian.Uint64(sum[0:8])
+	h2 := binary.BigEndian.Uint64(sum[8:16]) | 1
+	return h1, h2
+}
+
+// WriteTo writes the filter to w.
+func (f *Filter) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	hdr := make([]byte, headerSize)
+	copy(hdr, magic[:])
+	binary.LittleEndian.PutUint32(hdr[len(magic):], f.k)
+	binary.LittleEndian.PutUint64(hdr[len(magic)+4:], f.m)
+	n, err := bw.Write(hdr)
+	total := int64(n)
+	if err != nil {
+		return total, err
+	}
+	var word [8]byte
+	for _, b := range f.bits {
+		binary.LittleEndian.PutUint64(word[:], b)
+		n, err = bw.Write(word[:])
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, bw.Flush()
+}
+
+// ReadFilter reads a filter written by WriteTo from r. If r is an io.Seeker
+// the size of the filter in the header must match the size of the rest of r.
+func ReadFilter(r io.Reader) (*Filter, error) {
+	size, sized, err := remaining(r)
+	if err != nil {
+		return nil, err
+	}
+	br := bufio.NewReader(r)
+	hdr := make([]byte, headerSize)
+	_, err = io.ReadFull(br, hdr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+	}
+	if !isFilter(hdr) {
+		return nil, ErrInvalidFilter
+	}
+	k := binary.LittleEndian.Uint32(hdr[len(magic):])
+	m := binary.LittleEndian.Uint64(hdr[len(magic)+4:])
+	if k < 1 || k > maxHashes || m < 64 || m > maxBits || m%64 != 0 {
+		return nil, ErrInvalidFilter
+	}
+	if sized && size-int64(headerSize) != int64(m/8) {
+		return nil, fmt.Errorf("%w: size mismatch", ErrInvalidFilter)
+	}
+	f := newFilter(k, m)
+	var word [8]byte
+	for i := range f.bits {
+		_, err = io.ReadFull(br, word[:])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+		}
+		f.bits[i] = binary.LittleEndian.Uint64(word[:])
+	}
+	return f, nil
+}
+
+// remaining returns the number of bytes left to read in r
+// and true if r is an io.Seeker, otherwise it returns false.
+func remaining(r io.Reader) (int64, bool, error) {
+	rs, ok := r.(io.Seeker)
+	if !ok {
+		return 0, false, nil
+	}
+	cur, err := rs.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, false, err
+	}
+	end, err := rs.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, false, err
+	}
+	_, err = rs.Seek(cur, io.SeekStart)
+	if err != nil {
+		return 0, false, err
+	}
+	return end - cur, true, nil
+}
+
+func isFilter(hdr []byte) bool {
+	return len(hdr) >= len(magic) && string(hdr[:len(magic)]) == string(magic[:])
+}
@@ -0,0 +1,105 @@
+package breach
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFilter(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		n uint64
+		p float64
+		k uint32
+		m uint64
+	}{
+		{0, 0, 10, 64},
+		{1000, 0.01, 7, 9600},
+		{1000, DefaultFalsePositiveRate, 10, 14400},
+		{1000, 1, 10, 14400},
+	}
+	for _, test := range tests {
+		f := NewFilter(test.n, test.p)
+		assert.Equal(t, test.k, f.k)
+		assert.Equal(t, test.m, f.m)
+		assert.Len(t, f.bits, int(test.m/64))
+	}
+}
+
+func TestFilter_Contains(t *testing.T) {
+	t.Parallel()
+	const count = 10000
+	f := NewFilter(count, DefaultFalsePositiveRate)
+	for i := 0; i < count; i++ {
+		f.Add(sha1.Sum([]byte(fmt.Sprintf("password%d", i))))
+	}
+	for i := 0; i < count; i++ {
+		assert.True(t, f.Contains(fmt.Sprintf("password%d", i)))
+	}
+	var fp int
+	for i := 0; i < count; i++ {
+		if f.Contains(fmt.Sprintf("secret%d", i)) {
+			fp++
+		}
+	}
+	assert.Less(t, float64(fp)/count, DefaultFalsePositiveRate*5)
+	var nilFilter *Filter
+	assert.False(t, nilFilter.Contains("password0"))
+}
+
+func TestFilter_WriteTo(t *testing.T) {
+	t.Parallel()
+	f := NewFilter(100, 0.01)
+	f.Add(sha1.Sum([]byte("password")))
+	var buf bytes.Buffer
+	n, err := f.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.EqualValues(t, buf.Len(), n)
+	b := buf.Bytes()
+	f2, err := ReadFilter(bytes.NewReader(b))
+	require.NoError(t, err)
+	assert.Equal(t, f, f2)
+	assert.True(t, f2.Contains("password"))
+	assert.False(t, f2.Contains("SXJAm7qJ4?3dH!aN8T3f5p!oNnpXbaRy#Gtx#8jG"))
+	// bad filters
+	hdr := headerSize
+	header := func(k uint32, m uint64) []byte {
+		h := append([]byte{}, magic[:]...)
+		h = binary.LittleEndian.AppendUint32(h, k)
+		return binary.LittleEndian.AppendUint64(h, m)
+	}
+	bad := [][]byte{
+		nil,
+		b[:len(magic)],
+		append([]byte("notmagic"), b[len(magic):]...),
+		b[:len(b)-1],
+		append(append([]byte{}, b[:len(magic)]...), make([]byte, len(b)-len(magic))...),
+		append(append(append([]byte{}, b[:len(magic)+4]...), 1, 0, 0, 0, 0, 0, 0, 0), b[hdr:]...),
+		append(header(maxHashes+1, f.m), b[hdr:]...),
+		append(header(f.k, f.m*2), b[hdr:]...),
+		append(header(f.k, f.m/2), b[hdr:]...),
+		append(header(f.k, math.MaxUint64-63), b[hdr:]...),
+		append(append([]byte{}, b...), 0),
+	}
+	for _, test := range bad {
+		_, err = ReadFilter(bytes.NewReader(test))
+		assert.ErrorIs(t, err, ErrInvalidFilter)
+	}
+	// corrupted headers are rejected before reading the filter
+	corrupt := [][]byte{
+		header(f.k, maxBits+64),
+		header(f.k, math.MaxUint64-63),
+	}
+	for _, test := range corrupt {
+		_, err = ReadFilter(io.MultiReader(bytes.NewReader(test), bytes.NewReader(b[hdr:])))
+		assert.ErrorIs(t, err, ErrInvalidFilter)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jrapoport/gothic/breach"
+	"github.com/jrapoport/gothic/cmd/cli/root"
+	"github.com/spf13/cobra"
+)
+
+// this command runs offline and does not require a config or server
+var breachCmd = &cobra.Command{
+	Use: "breach [DUMP FILE|RANGE DIR] [FILTER FILE]",
+	Long: "breach builds a breached password filter from a SHA-1 dump (e.g. from Have I Been Pwned). " +
+		"The dump is either a file of full hashes, or a directory of range files named for their " +
+		"5 character prefix (e.g. 5BAA6.txt) that each hold the hash suffixes of the range.",
+	RunE:              breachRunE,
+	Args:              cobra.ExactArgs(2),
+	PersistentPreRunE: func(*cobra.Command, []string) error { return nil },
+}
+
+var breachRate float64
+
+func init() {
+	fs := breachCmd.Flags()
+	fs.Float64VarP(&breachRate, "rate", "r", breach.DefaultFalsePositiveRate, "false positive rate of the filter")
+	root.AddCommand(breachCmd)
+}
+
+func breachRunE(_ *cobra.Command, args []string) error {
+	var (
+		dumpFile   = args[0]
+		filterFile = args[1]
+	)
+	fmt.Printf("building breached password filter from %s...\n", dumpFile)
+	f, err := buildFilter(dumpFile)
+	if err != nil {
+		return err
+	}
+	out, err := os.Create(filterFile)
+	if err != nil {
+		return err
+	}
+	n, err := f.WriteTo(out)
+	if err != nil {
+		_ = out.Close()
+		return err
+	}
+	err = out.Close()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("wrote breached password filter to %s (%d bytes)\n", filterFile, n)
+	return nil
+}
+
+func buildFilter(dumpFile string) (*breach.Filter, error) {
+	fi, err := os.Stat(dumpFile)
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		return breach.BuildRangeFilter(dumpFile, breachRate)
+	}
+	in, err := os.Open(dumpFile)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+	return breach.BuildFilter(in, breachRate)
+}
@@ -3,12 +3,16 @@ package main
 import (
 	"fmt"
 
+	"github.com/jrapoport/gothic/cmd/cli/role"
 	"github.com/jrapoport/gothic/cmd/cli/root"
+	"github.com/jrapoport/gothic/cmd/cli/service"
 	"github.com/jrapoport/gothic/cmd/cli/user"
 )
 
 func init() {
 	root.AddCommand(user.Cmd)
+	root.AddCommand(service.Cmd)
+	root.AddCommand(role.Cmd)
 	root.AddCommand(codeCmd)
 	root.AddCommand(migrateCmd)
 }
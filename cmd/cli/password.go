@@ -5,9 +5,9 @@ import (
 
 	"github.com/jrapoport/gothic/cmd/cli/root"
 	"github.com/jrapoport/gothic/core/users"
+	"github.com/jrapoport/gothic/hasher"
 	"github.com/jrapoport/gothic/models/user"
 	"github.com/jrapoport/gothic/store"
-	"github.com/jrapoport/gothic/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -46,7 +46,14 @@ func passwordRunE(_ *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	hash := utils.HashPassword(newPassword)
+	h, err := hasher.New(cfg.Hash)
+	if err != nil {
+		return err
+	}
+	hash, err := h.Hash(newPassword)
+	if err != nil {
+		return err
+	}
 	err = conn.Model(su).Update("password", hash).Error
 	if err != nil {
 		return err
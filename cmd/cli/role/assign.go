@@ -0,0 +1,96 @@
+package role
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jrapoport/gothic/api/grpc/rpc/admin"
+	"github.com/jrapoport/gothic/cmd/cli/root"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/spf13/cobra"
+)
+
+var assignCmd = &cobra.Command{
+	Use:  "assign [NAME] [USER ID]",
+	Long: "assign a custom role to a user",
+	RunE: assignRoleRunE,
+	Args: cobra.ExactArgs(2),
+}
+
+var unassignCmd = &cobra.Command{
+	Use:  "unassign [NAME] [USER ID]",
+	Long: "remove a custom role from a user",
+	RunE: unassignRoleRunE,
+	Args: cobra.ExactArgs(2),
+}
+
+var userCmd = &cobra.Command{
+	Use:  "user [USER ID]",
+	Long: "list the custom roles & permissions of a user",
+	RunE: userRolesRunE,
+	Args: cobra.ExactArgs(1),
+}
+
+func assignRoleRunE(_ *cobra.Command, args []string) error {
+	client, err := root.NewAdminClient()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		client.Close()
+	}()
+	req := &admin.UserRoleRequest{
+		Role:   args[0],
+		UserId: args[1],
+	}
+	_, err = client.AssignUserRole(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("assigned role %s: %s\n", req.GetRole(), req.GetUserId())
+	return nil
+}
+
+func unassignRoleRunE(_ *cobra.Command, args []string) error {
+	client, err := root.NewAdminClient()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		client.Close()
+	}()
+	req := &admin.UserRoleRequest{
+		Role:   args[0],
+		UserId: args[1],
+	}
+	yes := root.ConfirmAction("Unassign role %s from %s", req.GetRole(), req.GetUserId())
+	if !yes {
+		return nil
+	}
+	_, err = client.UnassignUserRole(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("unassigned role %s: %s\n", req.GetRole(), req.GetUserId())
+	return nil
+}
+
+func userRolesRunE(_ *cobra.Command, args []string) error {
+	client, err := root.NewAdminClient()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		client.Close()
+	}()
+	req := &admin.UserRolesRequest{UserId: args[0]}
+	res, err := client.ListUserRoles(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	for _, r := range res.GetRoles() {
+		fmt.Printf("%s [%s]\n", r.GetName(), strings.Join(r.GetPermissions(), " "))
+	}
+	fmt.Printf("permissions: %s\n", strings.Join(res.GetPermissions(), " "))
+	return nil
+}
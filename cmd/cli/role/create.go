@@ -0,0 +1,51 @@
+package role
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jrapoport/gothic/api/grpc/rpc/admin"
+	"github.com/jrapoport/gothic/cmd/cli/root"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/spf13/cobra"
+)
+
+var createCmd = &cobra.Command{
+	Use:  "create [NAME]",
+	Long: "create a new custom role with permissions",
+	RunE: createRoleRunE,
+	Args: cobra.ExactArgs(1),
+}
+
+var (
+	description string
+	permissions []string
+)
+
+func init() {
+	fs := createCmd.Flags()
+	fs.StringVarP(&description, "description", "d", "", "description of the role")
+	fs.StringSliceVarP(&permissions, "permission", "p", nil, "permissions granted by the role")
+}
+
+func createRoleRunE(_ *cobra.Command, args []string) error {
+	client, err := root.NewAdminClient()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		client.Close()
+	}()
+	req := &admin.CreateRoleRequest{
+		Name:        args[0],
+		Description: description,
+		Permissions: permissions,
+	}
+	res, err := client.CreateRole(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("created role: %s\n", res.GetName())
+	fmt.Printf("permissions: %s\n", strings.Join(res.GetPermissions(), " "))
+	return nil
+}
@@ -0,0 +1,38 @@
+package role
+
+import (
+	"fmt"
+
+	"github.com/jrapoport/gothic/api/grpc/rpc/admin"
+	"github.com/jrapoport/gothic/cmd/cli/root"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/spf13/cobra"
+)
+
+var deleteCmd = &cobra.Command{
+	Use:  "delete [NAME]",
+	RunE: deleteRoleRunE,
+	Args: cobra.ExactArgs(1),
+}
+
+func deleteRoleRunE(_ *cobra.Command, args []string) error {
+	client, err := root.NewAdminClient()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		client.Close()
+	}()
+	name := args[0]
+	yes := root.ConfirmAction("Delete role %s", name)
+	if !yes {
+		return nil
+	}
+	req := &admin.RoleRequest{Name: name}
+	_, err = client.DeleteRole(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("deleted role: %s\n", name)
+	return nil
+}
@@ -0,0 +1,37 @@
+package role
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jrapoport/gothic/cmd/cli/root"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+var listCmd = &cobra.Command{
+	Use:  "list",
+	RunE: listRolesRunE,
+	Args: cobra.NoArgs,
+}
+
+func listRolesRunE(_ *cobra.Command, _ []string) error {
+	client, err := root.NewAdminClient()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		client.Close()
+	}()
+	res, err := client.ListRoles(context.Background(), &emptypb.Empty{})
+	if err != nil {
+		return err
+	}
+	for _, r := range res.GetRoles() {
+		fmt.Printf("%s [%s] %s\n", r.GetName(),
+			strings.Join(r.GetPermissions(), " "), r.GetDescription())
+	}
+	fmt.Printf("%d roles\n", len(res.GetRoles()))
+	return nil
+}
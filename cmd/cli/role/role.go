@@ -0,0 +1,20 @@
+package role
+
+import "github.com/spf13/cobra"
+
+// Cmd is the role command
+var Cmd = &cobra.Command{
+	Use:     "role",
+	Aliases: []string{"roles"},
+	Long:    "manage custom roles and their permissions",
+}
+
+func init() {
+	Cmd.AddCommand(createCmd)
+	Cmd.AddCommand(listCmd)
+	Cmd.AddCommand(updateCmd)
+	Cmd.AddCommand(deleteCmd)
+	Cmd.AddCommand(assignCmd)
+	Cmd.AddCommand(unassignCmd)
+	Cmd.AddCommand(userCmd)
+}
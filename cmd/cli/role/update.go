@@ -0,0 +1,48 @@
+package role
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jrapoport/gothic/api/grpc/rpc/admin"
+	"github.com/jrapoport/gothic/cmd/cli/root"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/spf13/cobra"
+)
+
+var updateCmd = &cobra.Command{
+	Use:  "update [NAME]",
+	Long: "update the description or permissions of a custom role",
+	RunE: updateRoleRunE,
+	Args: cobra.ExactArgs(1),
+}
+
+func init() {
+	fs := updateCmd.Flags()
+	fs.StringVarP(&description, "description", "d", "", "description of the role")
+	fs.StringSliceVarP(&permissions, "permission", "p", nil, "permissions granted by the role")
+}
+
+func updateRoleRunE(cmd *cobra.Command, args []string) error {
+	client, err := root.NewAdminClient()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		client.Close()
+	}()
+	req := &admin.UpdateRoleRequest{
+		Name:        args[0],
+		Permissions: permissions,
+	}
+	if cmd.Flags().Changed("description") {
+		req.Description = &description
+	}
+	res, err := client.UpdateRole(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("updated role: %s\n", res.GetName())
+	fmt.Printf("permissions: %s\n", strings.Join(res.GetPermissions(), " "))
+	return nil
+}
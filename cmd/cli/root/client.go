@@ -59,6 +59,11 @@ func newConnection(address, pw string) (*grpc.ClientConn, error) {
 			ctx = metadata.AppendToOutgoingContext(ctx, rpc.RootPassword, pw)
 			return invoker(ctx, method, req, reply, cc, opts...)
 		}),
+		grpc.WithStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc,
+			cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			ctx = metadata.AppendToOutgoingContext(ctx, rpc.RootPassword, pw)
+			return streamer(ctx, desc, cc, method, opts...)
+		}),
 	}
 	return grpc.Dial(address, opts...)
 }
@@ -42,6 +42,7 @@ func initConfig(cmd *cobra.Command, _ []string) (err error) {
 	cfg.DB.AutoMigrate = false
 	cfg.Signup.Default.Username = true
 	cfg.Validation.PasswordRegex = ""
+	cfg.Password = config.Password{}
 	if rootPassword != "" {
 		cfg.RootPassword = rootPassword
 	}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jrapoport/gothic/api/grpc/rpc/admin"
+	"github.com/jrapoport/gothic/cmd/cli/root"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/spf13/cobra"
+)
+
+var createCmd = &cobra.Command{
+	Use:  "create [NAME]",
+	Long: "create a new service client for the client credentials grant",
+	RunE: createServiceRunE,
+	Args: cobra.ExactArgs(1),
+}
+
+var (
+	scopes []string
+	owner  string
+)
+
+func init() {
+	fs := createCmd.Flags()
+	fs.StringSliceVarP(&scopes, "scope", "s", nil, "scopes the service client is allowed")
+	fs.StringVarP(&owner, "owner", "o", "", "user id of the owner of the service client")
+}
+
+func createServiceRunE(_ *cobra.Command, args []string) error {
+	client, err := root.NewAdminClient()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		client.Close()
+	}()
+	req := &admin.CreateServiceClientRequest{
+		Name:    args[0],
+		Scopes:  scopes,
+		OwnerId: owner,
+	}
+	res, err := client.CreateServiceClient(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("created service client: %s (%s)\n", res.GetClientId(), res.GetName())
+	fmt.Printf("scopes: %s\n", strings.Join(res.GetScopes(), " "))
+	fmt.Printf("client secret: %s\n", res.GetClientSecret())
+	fmt.Println("the client secret will not be shown again")
+	return nil
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/jrapoport/gothic/api/grpc/rpc/admin"
+	"github.com/jrapoport/gothic/cmd/cli/root"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/spf13/cobra"
+)
+
+var deleteCmd = &cobra.Command{
+	Use:  "delete [CLIENT ID]",
+	RunE: deleteServiceRunE,
+	Args: cobra.ExactArgs(1),
+}
+
+func deleteServiceRunE(_ *cobra.Command, args []string) error {
+	client, err := root.NewAdminClient()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		client.Close()
+	}()
+	clientID := args[0]
+	yes := root.ConfirmAction("Delete service client %s", clientID)
+	if !yes {
+		return nil
+	}
+	req := &admin.DeleteClientRequest{ClientId: clientID}
+	_, err = client.DeleteServiceClient(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("deleted service client: %s\n", clientID)
+	return nil
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jrapoport/gothic/cmd/cli/root"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+var listCmd = &cobra.Command{
+	Use:  "list",
+	RunE: listServicesRunE,
+	Args: cobra.NoArgs,
+}
+
+func listServicesRunE(_ *cobra.Command, _ []string) error {
+	client, err := root.NewAdminClient()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		client.Close()
+	}()
+	res, err := client.ListServiceClients(context.Background(), &emptypb.Empty{})
+	if err != nil {
+		return err
+	}
+	for _, svc := range res.GetClients() {
+		fmt.Printf("%s %s [%s] owner: %s\n", svc.GetClientId(), svc.GetName(),
+			strings.Join(svc.GetScopes(), " "), svc.GetOwnerId())
+	}
+	fmt.Printf("%d service clients\n", len(res.GetClients()))
+	return nil
+}
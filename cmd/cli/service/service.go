@@ -0,0 +1,15 @@
+package service
+
+import "github.com/spf13/cobra"
+
+// Cmd is the service command
+var Cmd = &cobra.Command{
+	Use:     "service",
+	Aliases: []string{"services"},
+}
+
+func init() {
+	Cmd.AddCommand(createCmd)
+	Cmd.AddCommand(listCmd)
+	Cmd.AddCommand(deleteCmd)
+}
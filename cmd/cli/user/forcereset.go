@@ -0,0 +1,41 @@
+package user
+
+import (
+	"fmt"
+	"net/mail"
+
+	"github.com/jrapoport/gothic/api/grpc/rpc/admin"
+	"github.com/jrapoport/gothic/cmd/cli/root"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/spf13/cobra"
+)
+
+var forceResetCmd = &cobra.Command{
+	Use:  "force-reset [ID or EMAIL]",
+	RunE: forceResetRunE,
+	Args: cobra.ExactArgs(1),
+}
+
+func forceResetRunE(_ *cobra.Command, args []string) error {
+	client, err := root.NewAdminClient()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		client.Close()
+	}()
+	userID := args[0]
+	req := &admin.ForcePasswordChangeRequest{
+		User: &admin.ForcePasswordChangeRequest_UserId{UserId: userID},
+	}
+	addr, err := mail.ParseAddress(userID)
+	if err == nil {
+		req.User = &admin.ForcePasswordChangeRequest_Email{Email: addr.Address}
+	}
+	res, err := client.ForcePasswordChange(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("forced password reset: %s\n", res.GetUserId())
+	return nil
+}
@@ -0,0 +1,124 @@
+package user
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jrapoport/gothic/api/grpc/rpc/admin"
+	"github.com/jrapoport/gothic/cmd/cli/root"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/core/users"
+	"github.com/jrapoport/gothic/hasher"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+var importCmd = &cobra.Command{
+	Use:  "import [FILE]",
+	RunE: importUsersRunE,
+	Args: cobra.ExactArgs(1),
+}
+
+var (
+	importFormat string
+	firebase     hasher.FirebaseParams
+)
+
+func init() {
+	fs := importCmd.Flags()
+	fs.StringVar(&importFormat, "format", "", "file format (json or csv). defaults to the file extension")
+	fs.StringVar(&firebase.SignerKey, "firebase-signer-key", "", "firebase project hash signer key")
+	fs.StringVar(&firebase.SaltSeparator, "firebase-salt-separator", "", "firebase project hash salt separator")
+	fs.IntVar(&firebase.Rounds, "firebase-rounds", 0, "firebase project hash rounds")
+	fs.IntVar(&firebase.MemCost, "firebase-mem-cost", 0, "firebase project hash memory cost")
+}
+
+func importUsersRunE(_ *cobra.Command, args []string) error {
+	file := args[0]
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	format := importFormat
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(file), ".")
+	}
+	imp, err := users.ReadImport(f, format)
+	if err != nil {
+		return err
+	}
+	if firebase.SignerKey != "" {
+		imp.Firebase = &firebase
+	}
+	client, err := root.NewAdminClient()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		client.Close()
+	}()
+	stream, err := client.ImportUsers(context.Background())
+	if err != nil {
+		return err
+	}
+	if fb := imp.Firebase; fb != nil {
+		err = stream.Send(&admin.ImportUsersRequest{
+			Request: &admin.ImportUsersRequest_Options{
+				Options: &admin.ImportOptions{
+					Firebase: &admin.FirebaseScrypt{
+						SignerKey:     fb.SignerKey,
+						SaltSeparator: fb.SaltSeparator,
+						Rounds:        int32(fb.Rounds),
+						MemCost:       int32(fb.MemCost),
+					},
+				},
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, rec := range imp.Users {
+		u := &admin.ImportUser{
+			Email:         rec.Email,
+			Username:      rec.Username,
+			PasswordHash:  rec.Hash,
+			HashAlgorithm: rec.Algorithm,
+			Salt:          rec.Salt,
+			Confirmed:     rec.Confirmed,
+		}
+		if len(rec.Data) > 0 {
+			u.Data, err = structpb.NewStruct(rec.Data)
+			if err != nil {
+				return err
+			}
+		}
+		if len(rec.Metadata) > 0 {
+			u.Metadata, err = structpb.NewStruct(rec.Metadata)
+			if err != nil {
+				return err
+			}
+		}
+		err = stream.Send(&admin.ImportUsersRequest{
+			Request: &admin.ImportUsersRequest_User{User: u},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	for _, r := range res.GetResults() {
+		if r.GetError() == "" {
+			continue
+		}
+		fmt.Printf("row %d (%s): %s\n", r.GetRow(), r.GetEmail(), r.GetError())
+	}
+	fmt.Printf("imported users: %d (failed: %d)\n", res.GetImported(), res.GetFailed())
+	return nil
+}
@@ -0,0 +1,41 @@
+package user
+
+import (
+	"fmt"
+	"net/mail"
+
+	"github.com/jrapoport/gothic/api/grpc/rpc/admin"
+	"github.com/jrapoport/gothic/cmd/cli/root"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/spf13/cobra"
+)
+
+var unlockCmd = &cobra.Command{
+	Use:  "unlock [ID or EMAIL]",
+	RunE: unlockUserRunE,
+	Args: cobra.ExactArgs(1),
+}
+
+func unlockUserRunE(_ *cobra.Command, args []string) error {
+	client, err := root.NewAdminClient()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		client.Close()
+	}()
+	userID := args[0]
+	req := &admin.UnlockUserRequest{
+		User: &admin.UnlockUserRequest_UserId{UserId: userID},
+	}
+	addr, err := mail.ParseAddress(userID)
+	if err == nil {
+		req.User = &admin.UnlockUserRequest_Email{Email: addr.Address}
+	}
+	res, err := client.UnlockUser(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("unlocked user: %s\n", res.GetUserId())
+	return nil
+}
@@ -4,11 +4,15 @@ import "github.com/spf13/cobra"
 
 // Cmd is the user command
 var Cmd = &cobra.Command{
-	Use: "user",
+	Use:     "user",
+	Aliases: []string{"users"},
 }
 
 func init() {
 	Cmd.AddCommand(createCmd)
 	Cmd.AddCommand(roleCmd)
 	Cmd.AddCommand(deleteCmd)
+	Cmd.AddCommand(unlockCmd)
+	Cmd.AddCommand(forceResetCmd)
+	Cmd.AddCommand(importCmd)
 }
@@ -1,6 +1,9 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"net"
 	"net/url"
 	"strings"
 
@@ -42,13 +45,17 @@ func (a *Authorization) normalize(srv Service, host string) error {
 			return err
 		}
 	}
+	return a.Providers.normalize(host)
+}
+
+func (p Providers) normalize(host string) error {
 	const urlFormat = "http://" + hostToken + "/account/auth/callback"
-	for k, p := range a.Providers {
-		if p.ClientKey == "" {
-			delete(a.Providers, k)
+	for k, prov := range p {
+		if prov.ClientKey == "" {
+			delete(p, k)
 			continue
 		}
-		callback := p.CallbackURL
+		callback := prov.CallbackURL
 		if callback == "" {
 			callback = urlFormat
 		}
@@ -57,8 +64,12 @@ func (a *Authorization) normalize(srv Service, host string) error {
 		if err != nil {
 			return err
 		}
-		p.CallbackURL = callback
-		a.Providers[k] = p
+		prov.CallbackURL = callback
+		err = prov.normalize()
+		if err != nil {
+			return fmt.Errorf("%s provider: %w", k, err)
+		}
+		p[k] = prov
 	}
 	return nil
 }
@@ -69,6 +80,90 @@ type Provider struct {
 	Secret      string   `json:"secret"`
 	CallbackURL string   `json:"callback_url" yaml:"callback_url" mapstructure:"callback_url"`
 	Scopes      []string `json:"scopes"`
+	// Issuer is the issuer of a generic OpenID Connect provider. If set,
+	// the provider is configured with OpenID Connect discovery.
+	Issuer string `json:"issuer"`
+	// AuthURL is the authorization url of a generic OAuth2 provider. If
+	// set, the TokenURL & UserInfoURL are required.
+	AuthURL string `json:"auth_url" yaml:"auth_url" mapstructure:"auth_url"`
+	// TokenURL is the token url of a generic OAuth2 provider.
+	TokenURL string `json:"token_url" yaml:"token_url" mapstructure:"token_url"`
+	// UserInfoURL is the url of the user info of a generic OAuth2 provider.
+	UserInfoURL string `json:"userinfo_url" yaml:"userinfo_url" mapstructure:"userinfo_url"`
+	// Attributes maps the user info of a generic OAuth2 provider to a user.
+	Attributes Attributes `json:"attributes"`
+}
+
+// Generic returns true if the provider is a generic OpenID
+// Connect or OAuth2 provider instead of a known provider.
+func (p Provider) Generic() bool {
+	return p.Issuer != "" || p.AuthURL != ""
+}
+
+func (p *Provider) normalize() error {
+	if !p.Generic() {
+		return nil
+	}
+	if p.Issuer != "" && p.AuthURL != "" {
+		return errors.New("issuer & auth url are exclusive")
+	}
+	if p.Issuer != "" {
+		return endpointURL(p.Issuer)
+	}
+	if p.TokenURL == "" || p.UserInfoURL == "" {
+		return errors.New("token & userinfo urls required")
+	}
+	for _, u := range []string{p.AuthURL, p.TokenURL, p.UserInfoURL} {
+		err := endpointURL(u)
+		if err != nil {
+			return err
+		}
+	}
+	if p.Attributes.ID == "" {
+		p.Attributes.ID = attributeID
+	}
+	if p.Attributes.Email == "" {
+		p.Attributes.Email = attributeEmail
+	}
+	return nil
+}
+
+// endpointURL returns an error if the url of a provider endpoint is not an
+// absolute https url. http is only allowed for loopback hosts (e.g. tests).
+func endpointURL(s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid url: %s", s)
+	}
+	switch u.Scheme {
+	case "https":
+		return nil
+	case "http":
+		if isLoopback(u.Hostname()) {
+			return nil
+		}
+	}
+	return fmt.Errorf("https url required: %s", s)
+}
+
+func isLoopback(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Attributes holds the JSON paths of the user attributes in the user info
+// of a generic OAuth2 provider, e.g. "data.emails.0.value".
+type Attributes struct {
+	ID     string `json:"id"`
+	Email  string `json:"email"`
+	Name   string `json:"name"`
+	Avatar string `json:"avatar"`
 }
 
 /*
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"io/ioutil"
+	"path/filepath"
 	"testing"
 
 	"github.com/jrapoport/gothic/models/types/provider"
@@ -104,3 +106,85 @@ func TestAuthorization_InternalProvider(t *testing.T) {
 	c.Authorization.UseInternal = false
 	assert.EqualValues(t, provider.Unknown, c.Provider())
 }
+
+func TestProviders_Generic(t *testing.T) {
+	const (
+		issuer   = "https://id.example.com"
+		authURL  = "https://oauth.example.com/authorize"
+		tokenURL = "https://oauth.example.com/token"
+		infoURL  = "https://oauth.example.com/userinfo"
+	)
+	const cfg = `
+provider:
+  example:
+    client_key: "foo"
+    secret: "i-am-a-secret"
+    auth_url: "` + authURL + `"
+    token_url: "` + tokenURL + `"
+    userinfo_url: "` + infoURL + `"
+    attributes:
+      id: "data.user_id"
+      avatar: "data.picture"
+  example-oidc:
+    client_key: "foo"
+    issuer: "` + issuer + `"
+`
+	clearEnv()
+	setEnv(t, ENVPrefix+"_SITE_URL", siteURL)
+	setEnv(t, ENVPrefix+"_ROOT_PASSWORD", rootPassword)
+	setEnv(t, ENVPrefix+"_JWT_SECRET", jwtSecret)
+	setEnv(t, ENVPrefix+"_DB_DSN", dsn)
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	err := ioutil.WriteFile(file, []byte(cfg), 0600)
+	require.NoError(t, err)
+	c, err := loadNormalized(file)
+	require.NoError(t, err)
+	p, ok := c.Providers["example"]
+	require.True(t, ok)
+	assert.True(t, p.Generic())
+	assert.Equal(t, authURL, p.AuthURL)
+	assert.Equal(t, tokenURL, p.TokenURL)
+	assert.Equal(t, infoURL, p.UserInfoURL)
+	assert.Equal(t, "data.user_id", p.Attributes.ID)
+	assert.Equal(t, attributeEmail, p.Attributes.Email)
+	assert.Empty(t, p.Attributes.Name)
+	assert.Equal(t, "data.picture", p.Attributes.Avatar)
+	p, ok = c.Providers["example-oidc"]
+	require.True(t, ok)
+	assert.True(t, p.Generic())
+	assert.Equal(t, issuer, p.Issuer)
+	assert.False(t, c.Providers[provider.Google].Generic())
+	// bad providers
+	tests := []Provider{
+		{ClientKey: clientKey, Issuer: "\n"},
+		{ClientKey: clientKey, AuthURL: authURL},
+		{ClientKey: clientKey, AuthURL: authURL, TokenURL: tokenURL},
+		{ClientKey: clientKey, AuthURL: "\n", TokenURL: tokenURL, UserInfoURL: infoURL},
+		{ClientKey: clientKey, AuthURL: authURL, TokenURL: "\n", UserInfoURL: infoURL},
+		{ClientKey: clientKey, Issuer: issuer, AuthURL: authURL, TokenURL: tokenURL, UserInfoURL: infoURL},
+		{ClientKey: clientKey, Issuer: "id.example.com"},
+		{ClientKey: clientKey, Issuer: "/issuer"},
+		{ClientKey: clientKey, Issuer: "http://id.example.com"},
+		{ClientKey: clientKey, Issuer: "ftp://id.example.com"},
+		{ClientKey: clientKey, AuthURL: "http://oauth.example.com/authorize", TokenURL: tokenURL, UserInfoURL: infoURL},
+		{ClientKey: clientKey, AuthURL: authURL, TokenURL: "/token", UserInfoURL: infoURL},
+		{ClientKey: clientKey, AuthURL: authURL, TokenURL: tokenURL, UserInfoURL: "http://api.example.com/me"},
+	}
+	for _, test := range tests {
+		ps := Providers{"example": test}
+		err = ps.normalize("")
+		assert.Error(t, err)
+	}
+	// http is allowed for loopback hosts
+	tests = []Provider{
+		{ClientKey: clientKey, Issuer: "http://localhost:8080"},
+		{ClientKey: clientKey, Issuer: "http://127.0.0.1:8080"},
+		{ClientKey: clientKey, AuthURL: "http://[::1]/authorize",
+			TokenURL: "http://127.0.0.1/token", UserInfoURL: "http://localhost/userinfo"},
+	}
+	for _, test := range tests {
+		ps := Providers{"example": test}
+		err = ps.normalize("")
+		assert.NoError(t, err)
+	}
+}
@@ -35,9 +35,15 @@ type Config struct {
 	// Signup is the signup configuration.
 	Signup Signup `json:"signup"`
 
+	// SMS is the sms sender configuration.
+	SMS SMS `json:"sms"`
+
 	// Webhook is the configuration for webhooks
 	Webhook Webhooks `json:"webhook"`
 
+	// Tenants is the multi-tenant configuration.
+	Tenants Tenants `json:"tenants"`
+
 	// Logging is the log configuration.
 	Logger `json:"log"  yaml:"log" mapstructure:"log"`
 }
@@ -120,6 +126,12 @@ func (c *Config) normalize() error {
 	if err != nil {
 		return err
 	}
+	err = c.SMS.normalize(c.Service)
+	if err != nil {
+		return err
+	}
+	// no error is possible
+	c.Tenants.normalize()
 	// do this last
 	return c.Webhook.normalize(c.Service, c.JWT)
 }
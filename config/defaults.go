@@ -11,29 +11,49 @@ import (
 )
 
 const (
-	serviceName        = "gothic"
-	cookieDuration     = 24 * 60 * time.Minute
-	dbDriver           = drivers.MySQL
-	dbMaxRetry         = 3
-	jwtAlgorithm       = "HS256"
-	jwtExpiration      = 60 * time.Minute
-	logLevel           = log.LevelPanic
-	logTimeFormat      = time.RFC3339Nano
-	mailFrom           = ":name <do-not-reply@:link_hostname>"
-	mailTheme          = "default"
-	usernameRegex      = "^[a-zA-Z0-9_]{2,255}$"
-	passwordRegex      = "^[a-zA-Z0-9[:punct:]]{8,40}$"
-	secRateLimit       = 5 * time.Minute
-	smtpAuthentication = "plain"
-	smtpEncryption     = "none"
-	smtpExpiration     = 60 * time.Minute
-	smtpKeepalive      = true
-	smtpPort           = 25
-	smtpSendLimit      = 1 * time.Minute
-	smtpSpamProtection = true
-	webhookMaxRetry    = 3
-	webhookTimeout     = 30 * time.Second
-	host               = "localhost"
+	serviceName         = "gothic"
+	attributeEmail      = "email"
+	attributeID         = "id"
+	cookieDuration      = 24 * 60 * time.Minute
+	hashAlgorithm       = "argon2id"
+	dbDriver            = drivers.MySQL
+	dbMaxRetry          = 3
+	deviceExpiration    = 10 * time.Minute
+	deviceInterval      = 5 * time.Second
+	jwtAlgorithm        = "HS256"
+	jwtExpiration       = 60 * time.Minute
+	impersonationExp    = 15 * time.Minute
+	lockoutAttempts     = 5
+	lockoutDuration     = 15 * time.Minute
+	lockoutWindow       = 15 * time.Minute
+	logLevel            = log.LevelPanic
+	logTimeFormat       = time.RFC3339Nano
+	magicLinkExpiration = 15 * time.Minute
+	mailFrom            = ":name <do-not-reply@:link_hostname>"
+	mailTheme           = "default"
+	mfaExpiration       = 5 * time.Minute
+	oidcExpiration      = 10 * time.Minute
+	usernameRegex       = "^[a-zA-Z0-9_]{2,255}$"
+	passwordMinLength   = 8
+	passwordMaxLength   = 256
+	passwordMinScore    = 2
+	passwordChangeExp   = 15 * time.Minute
+	refreshGrace        = 10 * time.Second
+	secRateLimit        = 5 * time.Minute
+	smsExpiration       = 10 * time.Minute
+	smsMessage          = ":name verification code: :code"
+	smsSendLimit        = 1 * time.Minute
+	smtpAuthentication  = "plain"
+	smtpEncryption      = "none"
+	smtpExpiration      = 60 * time.Minute
+	smtpKeepalive       = true
+	smtpPort            = 25
+	smtpSendLimit       = 1 * time.Minute
+	smtpSpamProtection  = true
+	webauthnExpiration  = 5 * time.Minute
+	webhookMaxRetry     = 3
+	webhookTimeout      = 30 * time.Second
+	host                = "localhost"
 )
 
 var configDefaults = &Config{
@@ -44,7 +64,9 @@ var configDefaults = &Config{
 	DB:            databaseDefaults,
 	Mail:          mailDefaults,
 	Signup:        signupDefaults,
+	SMS:           smsDefaults,
 	Webhook:       webhooksDefaults,
+	Tenants:       tenantsDefaults,
 	Logger:        loggerDefaults,
 }
 
@@ -69,11 +91,41 @@ var securityDefaults = Security{
 	},
 	Validation: Validation{
 		UsernameRegex: usernameRegex,
-		PasswordRegex: passwordRegex,
+	},
+	Password: Password{
+		MinLength:        passwordMinLength,
+		MaxLength:        passwordMaxLength,
+		MinScore:         passwordMinScore,
+		BannedWords:      []string{},
+		ChangeExpiration: passwordChangeExp,
 	},
 	Cookies: Cookies{
 		Duration: cookieDuration,
 	},
+	MFA: MFA{
+		Expiration: mfaExpiration,
+	},
+	MagicLink: MagicLink{
+		Expiration: magicLinkExpiration,
+	},
+	Lockout: Lockout{
+		Attempts: lockoutAttempts,
+		Window:   lockoutWindow,
+		Duration: lockoutDuration,
+	},
+	Refresh: Refresh{
+		Grace: refreshGrace,
+	},
+	Impersonation: Impersonation{
+		Expiration: impersonationExp,
+	},
+	Hash: Hash{
+		Algorithm: hashAlgorithm,
+	},
+	WebAuthn: WebAuthn{
+		Origins:    []string{},
+		Expiration: webauthnExpiration,
+	},
 }
 
 var jwtDefaults = JWT{
@@ -121,6 +173,12 @@ var signupDefaults = Signup{
 	Invites: Admins,
 }
 
+var smsDefaults = SMS{
+	Message:    smsMessage,
+	Expiration: smsExpiration,
+	SendLimit:  smsSendLimit,
+}
+
 var webhooksDefaults = Webhooks{
 	MaxRetries: webhookMaxRetry,
 	Timeout:    webhookTimeout,
@@ -128,6 +186,11 @@ var webhooksDefaults = Webhooks{
 	Events:     []events.Event{},
 }
 
+var tenantsDefaults = Tenants{
+	Header:     "x-tenant",
+	PathPrefix: "/t",
+}
+
 var loggerDefaults = Logger{
 	Level:     logLevel,
 	Timestamp: logTimeFormat,
@@ -6,6 +6,7 @@ import (
 	"github.com/jrapoport/gothic/utils"
 	"github.com/lestrrat-go/jwx/jwk"
 	"io/ioutil"
+	"sort"
 	"strings"
 	"time"
 
@@ -26,8 +27,14 @@ type JWT struct {
 	// Scope is an optional comma separated list of permission
 	// scopes for the token (default: n/a)
 	Scope string `json:"scope"`
-	sk    jwk.Key
-	pk    jwk.Key
+	// KeyID is an optional key id for the signing key. If set, it is
+	// included with JWT tokens as the "kid" header (default: n/a)
+	KeyID string `json:"key_id" yaml:"key_id" mapstructure:"key_id"`
+	// Keys is an optional map of verify-only keys by key id. Tokens signed
+	// with these keys are accepted, but never issued (default: n/a)
+	Keys VerifyKeys `json:"keys,omitempty" yaml:"keys,omitempty"`
+	sk   jwk.Key
+	pk   jwk.Key
 }
 
 // PEM holds the public / private keypair for jwt (if set)
@@ -40,6 +47,11 @@ func (j *JWT) normalize(srv Service, def JWT) {
 	if def.Issuer == "" {
 		def.Issuer = strings.ToLower(srv.Name)
 	}
+	if j.Secret != "" || j.PEM.PrivateKey != "" {
+		// a custom signing key does not share the key ids of the default
+		def.KeyID = ""
+		def.Keys = nil
+	}
 	// no error is possible here since we
 	// control the struct entirely
 	_ = mergo.Merge(j, def)
@@ -50,7 +62,7 @@ func (j *JWT) CheckRequired() error {
 	if j.Secret == "" && j.PEM.PrivateKey == "" {
 		return errors.New("jwt secret or private key is required")
 	} else if j.Secret != "" {
-		return nil
+		return j.Keys.CheckRequired()
 	}
 	if j.PEM.PrivateKey != "" && !utils.PathExists(j.PEM.PrivateKey) {
 		err := fmt.Errorf("jwt private key not found: %s", j.PEM.PrivateKey)
@@ -60,7 +72,7 @@ func (j *JWT) CheckRequired() error {
 		err := fmt.Errorf("jwt public key not found: %s", j.PEM.PublicKey)
 		return err
 	}
-	return nil
+	return j.Keys.CheckRequired()
 }
 
 // PrivateKey returns the jwt private key (if set)
@@ -74,6 +86,7 @@ func (j *JWT) PrivateKey() jwk.Key {
 	} else {
 		j.sk, _ = pemKey(j.PEM.PrivateKey)
 	}
+	j.sk = withKeyID(j.sk, j.KeyID, j.Algorithm)
 	return j.sk
 }
 
@@ -93,9 +106,123 @@ func (j *JWT) PublicKey() jwk.Key {
 		}
 		j.pk, _ = pemKey(pem)
 	}
+	j.pk = withKeyID(j.pk, j.KeyID, j.Algorithm)
 	return j.pk
 }
 
+// VerifyKey returns the public key for the key id and true if it can be
+// used to verify a token. If the key id is empty, the public key is returned.
+func (j *JWT) VerifyKey(kid string) (jwk.Key, bool) {
+	if kid == "" || kid == j.KeyID {
+		key := j.PublicKey()
+		return key, key != nil
+	}
+	vk, ok := j.Keys[kid]
+	if !ok || vk.Expired() {
+		return nil, false
+	}
+	alg := vk.Algorithm
+	if alg == "" {
+		alg = j.Algorithm
+	}
+	key := withKeyID(vk.key(), kid, alg)
+	return key, key != nil
+}
+
+// KeySet returns the public keys that can be used to verify a token.
+func (j *JWT) KeySet() jwk.Set {
+	set := jwk.NewSet()
+	if key, ok := j.VerifyKey(j.KeyID); ok {
+		set.Add(key)
+	}
+	kids := make([]string, 0, len(j.Keys))
+	for kid := range j.Keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+	for _, kid := range kids {
+		if kid == j.KeyID {
+			continue
+		}
+		if key, ok := j.VerifyKey(kid); ok {
+			set.Add(key)
+		}
+	}
+	return set
+}
+
+// VerifyKeys is a map of verify-only keys by key id.
+type VerifyKeys map[string]VerifyKey
+
+// CheckRequired returns error if a verify-only key is invalid.
+func (vk VerifyKeys) CheckRequired() error {
+	for kid, k := range vk {
+		if kid == "" {
+			return errors.New("jwt key id is required")
+		}
+		if k.Secret == "" && k.PublicKey == "" {
+			return fmt.Errorf("jwt secret or public key is required: %s", kid)
+		}
+		if k.Secret == "" && !utils.PathExists(k.PublicKey) {
+			return fmt.Errorf("jwt public key not found: %s", k.PublicKey)
+		}
+		if k.ExpiresAt == "" {
+			continue
+		}
+		_, err := time.Parse(time.RFC3339, k.ExpiresAt)
+		if err != nil {
+			return fmt.Errorf("invalid jwt key expiration %s: %w", kid, err)
+		}
+	}
+	return nil
+}
+
+// VerifyKey holds a key that is only used to verify JWT tokens,
+// e.g. the previous signing key after the keys have been rotated.
+type VerifyKey struct {
+	Secret    string `json:"secret"`
+	PublicKey string `json:"publickey"`
+	// Algorithm is the algorithm of the key (default: JWT.Algorithm)
+	Algorithm string `json:"algorithm"`
+	// ExpiresAt is an optional RFC3339 timestamp after which the key is no
+	// longer accepted, i.e. the end of its grace period (default: n/a)
+	ExpiresAt string `json:"expires_at" yaml:"expires_at" mapstructure:"expires_at"`
+}
+
+// Expired returns true if the grace period for the key has ended.
+func (k VerifyKey) Expired() bool {
+	if k.ExpiresAt == "" {
+		return false
+	}
+	exp, err := time.Parse(time.RFC3339, k.ExpiresAt)
+	if err != nil {
+		return true
+	}
+	return !time.Now().Before(exp)
+}
+
+func (k VerifyKey) key() jwk.Key {
+	if k.Secret != "" {
+		key, _ := jwk.New([]byte(k.Secret))
+		return key
+	}
+	key, _ := pemKey(k.PublicKey)
+	return key
+}
+
+func withKeyID(key jwk.Key, kid, alg string) jwk.Key {
+	if key == nil {
+		return nil
+	}
+	if kid != "" {
+		_ = key.Set(jwk.KeyIDKey, kid)
+	}
+	if alg != "" {
+		_ = key.Set(jwk.AlgorithmKey, alg)
+	}
+	return key
+}
+
 func pemKey(pem string) (jwk.Key, error) {
 	raw, err := ioutil.ReadFile(pem)
 	if err != nil {
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -17,6 +18,7 @@ const (
 	jwtPrvKey = "./testdata/test-key.pem"
 	jwtPubKey = "./testdata/test-key.pem.pub"
 	jwtBadKey = "./testdata/test-key.bad"
+	jwtKeyID  = "key"
 )
 
 func TestJWT(t *testing.T) {
@@ -29,6 +31,15 @@ func TestJWT(t *testing.T) {
 		assert.Equal(t, jwtIss+test.mark, j.Issuer)
 		assert.Equal(t, jwtAud+test.mark, j.Audience)
 		assert.Equal(t, jwtExp, j.Expiration)
+		assert.Equal(t, jwtKeyID+test.mark, j.KeyID)
+		if test.mark == ".yaml" || test.mark == ".json" {
+			require.Len(t, j.Keys, 1)
+			k := j.Keys["old"]
+			assert.Equal(t, jwtPubKey, k.PublicKey)
+			assert.Equal(t, "RS256", k.Algorithm)
+			assert.Equal(t, "2021-04-01T12:00:00Z", k.ExpiresAt)
+			assert.True(t, k.Expired())
+		}
 	})
 }
 
@@ -48,6 +59,7 @@ func TestJWT_Env(t *testing.T) {
 			assert.Equal(t, jwtIss, j.Issuer)
 			assert.Equal(t, jwtAud, j.Audience)
 			assert.Equal(t, jwtExp, j.Expiration)
+			assert.Equal(t, jwtKeyID, j.KeyID)
 		})
 	}
 }
@@ -138,3 +150,93 @@ func TestJWT_Keys(t *testing.T) {
 		test.Nil(t, test.config.pk)
 	}
 }
+
+func TestJWT_KeyID(t *testing.T) {
+	j := JWT{
+		PEM:       PEM{PrivateKey: jwtPrvKey},
+		Algorithm: "RS256",
+		KeyID:     jwtKeyID,
+		Keys: VerifyKeys{
+			"old": {Secret: jwtSecret, Algorithm: "HS256"},
+			"expired": {
+				PublicKey: jwtPubKey,
+				ExpiresAt: time.Now().Add(-time.Minute).Format(time.RFC3339),
+			},
+		},
+	}
+	assert.Equal(t, jwtKeyID, j.PrivateKey().KeyID())
+	assert.Equal(t, jwtKeyID, j.PublicKey().KeyID())
+	assert.Equal(t, "RS256", j.PublicKey().Algorithm())
+	key, ok := j.VerifyKey("")
+	assert.True(t, ok)
+	assert.Equal(t, jwtKeyID, key.KeyID())
+	key, ok = j.VerifyKey(jwtKeyID)
+	assert.True(t, ok)
+	assert.Equal(t, jwtKeyID, key.KeyID())
+	key, ok = j.VerifyKey("old")
+	assert.True(t, ok)
+	assert.Equal(t, "old", key.KeyID())
+	assert.Equal(t, "HS256", key.Algorithm())
+	_, ok = j.VerifyKey("expired")
+	assert.False(t, ok)
+	_, ok = j.VerifyKey("unknown")
+	assert.False(t, ok)
+	set := j.KeySet()
+	require.Equal(t, 2, set.Len())
+	_, ok = set.LookupKeyID(jwtKeyID)
+	assert.True(t, ok)
+	_, ok = set.LookupKeyID("old")
+	assert.True(t, ok)
+}
+
+func TestJWT_KeyIDNormalization(t *testing.T) {
+	def := JWT{
+		KeyID: jwtKeyID,
+		Keys:  VerifyKeys{"old": {Secret: jwtSecret}},
+	}
+	// keys are inherited with the signing key
+	j := JWT{}
+	j.normalize(Service{Name: service}, def)
+	assert.Equal(t, jwtKeyID, j.KeyID)
+	assert.Len(t, j.Keys, 1)
+	// but not with a custom signing key
+	j = JWT{Secret: "custom"}
+	j.normalize(Service{Name: service}, def)
+	assert.Empty(t, j.KeyID)
+	assert.Empty(t, j.Keys)
+}
+
+func TestVerifyKeys_CheckRequired(t *testing.T) {
+	exp := time.Now().Format(time.RFC3339)
+	keyTests := []struct {
+		key VerifyKey
+		Err assert.ErrorAssertionFunc
+	}{
+		{VerifyKey{}, assert.Error},
+		{VerifyKey{Secret: jwtSecret}, assert.NoError},
+		{VerifyKey{PublicKey: jwtPubKey}, assert.NoError},
+		{VerifyKey{PublicKey: "bad"}, assert.Error},
+		{VerifyKey{Secret: jwtSecret, ExpiresAt: exp}, assert.NoError},
+		{VerifyKey{Secret: jwtSecret, ExpiresAt: "bad"}, assert.Error},
+	}
+	for _, test := range keyTests {
+		err := VerifyKeys{jwtKeyID: test.key}.CheckRequired()
+		test.Err(t, err)
+		j := JWT{Secret: jwtSecret, Keys: VerifyKeys{jwtKeyID: test.key}}
+		err = j.CheckRequired()
+		test.Err(t, err)
+	}
+	err := VerifyKeys{"": {Secret: jwtSecret}}.CheckRequired()
+	assert.Error(t, err)
+}
+
+func TestVerifyKey_Expired(t *testing.T) {
+	k := VerifyKey{}
+	assert.False(t, k.Expired())
+	k.ExpiresAt = time.Now().Add(time.Hour).Format(time.RFC3339)
+	assert.False(t, k.Expired())
+	k.ExpiresAt = time.Now().Add(-time.Hour).Format(time.RFC3339)
+	assert.True(t, k.Expired())
+	k.ExpiresAt = "bad"
+	assert.True(t, k.Expired())
+}
@@ -45,27 +45,40 @@ type MailFormat struct {
 
 func (m *Mail) normalize(srv Service) error {
 	if m.Name == "" {
-		n := strings.ToLower(srv.Name)
-		n = strings.Title(n)
-		m.Name = n
+		m.Name = defaultMailName(srv)
 	}
 	if m.Link == "" {
 		m.Link = srv.SiteURL
 	}
 	if m.Link != "" {
-		u, err := url.Parse(m.Link)
-		if err != nil {
-			return err
-		}
 		if m.From == "" {
 			m.From = mailDefaults.From
 		}
-		m.From = strings.Replace(m.From, ":name", m.Name, 1)
-		m.From = strings.Replace(m.From, ":link_hostname", u.Hostname(), 1)
+		from, err := formatFrom(m.From, m.Name, m.Link)
+		if err != nil {
+			return err
+		}
+		m.From = from
 	}
 	return m.MailTemplates.normalize(srv)
 }
 
+func defaultMailName(srv Service) string {
+	n := strings.ToLower(srv.Name)
+	return strings.Title(n)
+}
+
+// formatFrom replaces the ':name' and ':link_hostname' in the from address.
+func formatFrom(from, name, link string) (string, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", err
+	}
+	from = strings.Replace(from, ":name", name, 1)
+	from = strings.Replace(from, ":link_hostname", u.Hostname(), 1)
+	return from, nil
+}
+
 // CheckSendLimit returns ErrRateLimitExceeded if lest exceeds the send limit
 func (m Mail) CheckSendLimit(last *time.Time) error {
 	if last == nil {
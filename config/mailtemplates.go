@@ -20,10 +20,18 @@ type MailTemplates struct {
 	ConfirmUser MailTemplate `json:"confirm_user" yaml:"confirm_user" mapstructure:"confirm_user"`
 	// InviteUser email customization
 	InviteUser MailTemplate `json:"invite_user" yaml:"invite_user" mapstructure:"invite_user"`
+	// MagicLink email customization
+	MagicLink MailTemplate `json:"magic_link" yaml:"magic_link" mapstructure:"magic_link"`
+	// PasswordExpiry email customization
+	PasswordExpiry MailTemplate `json:"password_expiry" yaml:"password_expiry" mapstructure:"password_expiry"`
 	// ResetPassword email customization
 	ResetPassword MailTemplate `json:"reset_password" yaml:"reset_password" mapstructure:"reset_password"`
 	// SignupCode email customization
 	SignupCode MailTemplate `json:"signupcode"`
+	// TokenReused email customization
+	TokenReused MailTemplate `json:"token_reused" yaml:"token_reused" mapstructure:"token_reused"`
+	// UnlockUser email customization
+	UnlockUser MailTemplate `json:"unlock_user" yaml:"unlock_user" mapstructure:"unlock_user"`
 }
 
 func (mt *MailTemplates) normalize(srv Service) error {
@@ -31,8 +39,12 @@ func (mt *MailTemplates) normalize(srv Service) error {
 		&mt.ChangeEmail.ReferralURL,
 		&mt.ConfirmUser.ReferralURL,
 		&mt.InviteUser.ReferralURL,
+		&mt.MagicLink.ReferralURL,
+		&mt.PasswordExpiry.ReferralURL,
 		&mt.ResetPassword.ReferralURL,
 		&mt.SignupCode.ReferralURL,
+		&mt.TokenReused.ReferralURL,
+		&mt.UnlockUser.ReferralURL,
 	}
 	for _, ref := range referralURLs {
 		if *ref == "" {
@@ -64,12 +76,24 @@ var templateDefaults = MailTemplates{
 	InviteUser: MailTemplate{
 		LinkFormat: defaultLinkFormat,
 	},
+	MagicLink: MailTemplate{
+		LinkFormat: defaultLinkFormat,
+	},
+	PasswordExpiry: MailTemplate{
+		LinkFormat: defaultLinkFormat,
+	},
 	ResetPassword: MailTemplate{
 		LinkFormat: defaultLinkFormat,
 	},
 	SignupCode: MailTemplate{
 		LinkFormat: "/" + mailLinkAction,
 	},
+	TokenReused: MailTemplate{
+		LinkFormat: defaultLinkFormat,
+	},
+	UnlockUser: MailTemplate{
+		LinkFormat: defaultLinkFormat,
+	},
 }
 
 // MailTemplate holds the configuration for emails.
@@ -19,8 +19,12 @@ func TestMailTemplates(t *testing.T) {
 			m.ChangeEmail,
 			m.ConfirmUser,
 			m.InviteUser,
+			m.MagicLink,
 			m.SignupCode,
 			m.ResetPassword,
+			m.PasswordExpiry,
+			m.TokenReused,
+			m.UnlockUser,
 		}
 		for _, tmpl := range tmpls {
 			assert.Equal(t, template+test.mark, tmpl.Template)
@@ -44,8 +48,12 @@ func TestMailTemplates_Env(t *testing.T) {
 				m.ChangeEmail,
 				m.ConfirmUser,
 				m.InviteUser,
+				m.MagicLink,
 				m.SignupCode,
 				m.ResetPassword,
+				m.PasswordExpiry,
+				m.TokenReused,
+				m.UnlockUser,
 			}
 			for _, tmpl := range tmpls {
 				assert.Equal(t, template, tmpl.Template)
@@ -68,8 +76,12 @@ func TestMailTemplates_Normalization(t *testing.T) {
 		mt.ChangeEmail.ReferralURL,
 		mt.ConfirmUser.ReferralURL,
 		mt.InviteUser.ReferralURL,
+		mt.MagicLink.ReferralURL,
 		mt.ResetPassword.ReferralURL,
+		mt.PasswordExpiry.ReferralURL,
 		mt.SignupCode.ReferralURL,
+		mt.TokenReused.ReferralURL,
+		mt.UnlockUser.ReferralURL,
 	}
 	for _, ref := range referralURLs {
 		assert.Equal(t, siteURL, ref)
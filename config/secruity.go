@@ -3,7 +3,9 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"regexp"
+	"strings"
 	"time"
 )
 
@@ -21,8 +23,26 @@ type Security struct {
 	Recaptcha Recaptcha `json:"recaptcha"`
 	// Validation is the validation to apply to user submitted data.
 	Validation Validation `json:"validation"`
+	// Password is the password policy.
+	Password Password `json:"password"`
 	// Cookies is the configuration for cookies
 	Cookies Cookies `json:"cookies"`
+	// MFA is the multi-factor authentication configuration.
+	MFA MFA `json:"mfa"`
+	// WebAuthn is the webauthn (passkey) configuration.
+	WebAuthn WebAuthn `json:"webauthn"`
+	// MagicLink is the passwordless email login configuration.
+	MagicLink MagicLink `json:"magic_link" yaml:"magic_link" mapstructure:"magic_link"`
+	// Lockout is the failed login lockout configuration.
+	Lockout Lockout `json:"lockout"`
+	// Refresh is the refresh token configuration.
+	Refresh Refresh `json:"refresh"`
+	// Impersonation is the admin impersonation configuration.
+	Impersonation Impersonation `json:"impersonation"`
+	// OIDC is the OpenID Connect provider configuration.
+	OIDC OIDC `json:"oidc"`
+	// Hash is the password hashing configuration.
+	Hash Hash `json:"hash"`
 }
 
 func (s *Security) normalize(srv Service) error {
@@ -43,12 +63,66 @@ func (s *Security) normalize(srv Service) error {
 			return err
 		}
 	}
+	err := s.Password.normalize()
+	if err != nil {
+		return err
+	}
 	if s.Cookies.Duration == 0 {
 		s.Cookies.Duration = cookieDuration
 	}
+	if s.MFA.Issuer == "" {
+		s.MFA.Issuer = srv.Name
+	}
+	if s.MFA.Expiration == 0 {
+		s.MFA.Expiration = mfaExpiration
+	}
+	if s.MagicLink.Expiration == 0 {
+		s.MagicLink.Expiration = magicLinkExpiration
+	}
+	s.Lockout.normalize()
+	if s.Refresh.Expiration < 0 || s.Refresh.Idle < 0 || s.Refresh.Grace < 0 {
+		return errors.New("invalid refresh token lifetime")
+	}
+	if s.Impersonation.Expiration == 0 {
+		s.Impersonation.Expiration = impersonationExp
+	}
+	err = s.OIDC.normalize(srv, s.JWT)
+	if err != nil {
+		return err
+	}
+	s.Hash.normalize()
+	return s.WebAuthn.normalize(srv)
+}
+
+func (w *WebAuthn) normalize(srv Service) error {
+	uri, err := url.Parse(srv.SiteURL)
+	if err != nil {
+		return err
+	}
+	if w.RPID == "" {
+		w.RPID = uri.Hostname()
+	}
+	if w.RPName == "" {
+		w.RPName = srv.Name
+	}
+	if len(w.Origins) == 0 && uri.Host != "" {
+		w.Origins = []string{uri.Scheme + "://" + uri.Host}
+	}
+	if w.Expiration == 0 {
+		w.Expiration = webauthnExpiration
+	}
 	return nil
 }
 
+func (l *Lockout) normalize() {
+	if l.Window == 0 {
+		l.Window = lockoutWindow
+	}
+	if l.Duration == 0 {
+		l.Duration = lockoutDuration
+	}
+}
+
 // CheckRequired returns error if the required settings are not found.
 func (s *Security) CheckRequired() error {
 	if s.RootPassword == "" {
@@ -67,9 +141,309 @@ type Recaptcha struct {
 type Validation struct {
 	UsernameRegex string `json:"username_regex" yaml:"username_regex" mapstructure:"username_regex"`
 	PasswordRegex string `json:"password_regex" yaml:"password_regex" mapstructure:"password_regex"`
+	// BreachedPasswords is the path to a breached password bloom filter, or SHA-1 dump
+	// (e.g. from Have I Been Pwned). Passwords found in it are rejected when they are set.
+	BreachedPasswords string `json:"breached_passwords" yaml:"breached_passwords" mapstructure:"breached_passwords"`
+}
+
+// Password config. Zero values disable a rule.
+type Password struct {
+	// MinLength is the min number of characters in a password.
+	MinLength int `json:"min_length" yaml:"min_length" mapstructure:"min_length"`
+	// MaxLength is the max number of characters in a password.
+	MaxLength int `json:"max_length" yaml:"max_length" mapstructure:"max_length"`
+	// Lowercase requires a password to contain a lowercase letter.
+	Lowercase bool `json:"lowercase"`
+	// Uppercase requires a password to contain an uppercase letter.
+	Uppercase bool `json:"uppercase"`
+	// Digit requires a password to contain a digit.
+	Digit bool `json:"digit"`
+	// Symbol requires a password to contain a symbol.
+	Symbol bool `json:"symbol"`
+	// MinScore is the min strength score of a password from 0 (weakest) to 4
+	// (strongest). The score is estimated the same way as zxcvbn.
+	MinScore int `json:"min_score" yaml:"min_score" mapstructure:"min_score"`
+	// BannedWords are words a password may not contain. The service
+	// name, and the email and username of the user are always banned.
+	BannedWords []string `json:"banned_words" yaml:"banned_words" mapstructure:"banned_words"`
+	// History is the number of previous passwords, including the current
+	// password, that a user may not reuse.
+	History int `json:"history"`
+	// MinAge is the length of time before a user may change their password again.
+	MinAge time.Duration `json:"min_age" yaml:"min_age" mapstructure:"min_age"`
+	// MaxAge is the length of time before a password expires and must be changed.
+	MaxAge time.Duration `json:"max_age" yaml:"max_age" mapstructure:"max_age"`
+	// Reminder is the length of time before a password expires that a reminder is sent.
+	Reminder time.Duration `json:"reminder"`
+	// ChangeExpiration is the length of time a password change token is valid.
+	ChangeExpiration time.Duration `json:"change_expiration" yaml:"change_expiration" mapstructure:"change_expiration"`
+}
+
+// MaxScore is the max password strength score.
+const MaxScore = 4
+
+// Enabled returns true if any password rule is enabled.
+func (p Password) Enabled() bool {
+	return p.MinLength > 0 || p.MaxLength > 0 ||
+		p.Lowercase || p.Uppercase || p.Digit || p.Symbol ||
+		p.MinScore > 0 || len(p.BannedWords) > 0
+}
+
+func (p *Password) normalize() error {
+	if p.MinLength < 0 || p.MaxLength < 0 {
+		return errors.New("invalid password length")
+	}
+	if p.MaxLength > 0 && p.MinLength > p.MaxLength {
+		err := fmt.Errorf("invalid password length: min %d > max %d",
+			p.MinLength, p.MaxLength)
+		return err
+	}
+	if p.MinScore < 0 || p.MinScore > MaxScore {
+		return fmt.Errorf("invalid password score: %d", p.MinScore)
+	}
+	if p.History < 0 || p.MinAge < 0 {
+		return errors.New("invalid password history")
+	}
+	if p.MaxAge < 0 || p.Reminder < 0 {
+		return errors.New("invalid password expiration")
+	}
+	if p.MaxAge > 0 && p.MinAge >= p.MaxAge {
+		err := fmt.Errorf("invalid password age: min %s >= max %s",
+			p.MinAge, p.MaxAge)
+		return err
+	}
+	if p.ChangeExpiration == 0 {
+		p.ChangeExpiration = passwordChangeExp
+	}
+	return nil
 }
 
 // Cookies config
 type Cookies struct {
 	Duration time.Duration `json:"duration"`
 }
+
+// MFA config
+type MFA struct {
+	// Issuer is the issuer name used for totp enrollments (default: ServiceName).
+	Issuer string `json:"issuer"`
+	// Expiration is the length of time an mfa challenge is valid.
+	Expiration time.Duration `json:"expiration"`
+}
+
+// WebAuthn config
+type WebAuthn struct {
+	// RPID is the relying party id (default: the SiteURL hostname).
+	RPID string `json:"rp_id" yaml:"rp_id" mapstructure:"rp_id"`
+	// RPName is the relying party display name (default: ServiceName).
+	RPName string `json:"rp_name" yaml:"rp_name" mapstructure:"rp_name"`
+	// Origins are the origins permitted to use webauthn (default: SiteURL).
+	Origins []string `json:"origins"`
+	// Expiration is the length of time a webauthn ceremony is valid.
+	Expiration time.Duration `json:"expiration"`
+}
+
+// MagicLink config
+type MagicLink struct {
+	// Expiration is the length of time a magic link is valid.
+	Expiration time.Duration `json:"expiration"`
+}
+
+// Lockout config
+type Lockout struct {
+	// Attempts is the number of failed logins allowed for a user within
+	// the window before the user is temporarily locked (0 = disabled).
+	Attempts int `json:"attempts"`
+	// IPAttempts is the number of failed logins allowed from an ip address
+	// within the window before its logins are refused (0 = disabled).
+	IPAttempts int `json:"ip_attempts" yaml:"ip_attempts" mapstructure:"ip_attempts"`
+	// Window is the length of time failed logins are counted.
+	Window time.Duration `json:"window"`
+	// Duration is the length of time a user is locked.
+	Duration time.Duration `json:"duration"`
+	// Delay is the delay enforced after a failed login. The delay
+	// doubles with each subsequent failure (0 = disabled).
+	Delay time.Duration `json:"delay"`
+}
+
+// Enabled returns true if failed logins are counted.
+func (l Lockout) Enabled() bool {
+	return l.Attempts > 0 || l.IPAttempts > 0 || l.Delay > 0
+}
+
+// RetryAt returns the time a user may retry a login after
+// the number of failed logins, the last of which was at last.
+func (l Lockout) RetryAt(failures int, last time.Time) time.Time {
+	if l.Delay <= 0 || failures <= 0 {
+		return last
+	}
+	delay := l.Delay
+	for i := 1; i < failures && delay < l.Duration; i++ {
+		delay *= 2
+	}
+	if l.Duration > 0 && delay > l.Duration {
+		delay = l.Duration
+	}
+	return last.Add(delay)
+}
+
+// CheckRetry returns ErrRateLimitExceeded if the user
+// may not retry a login yet after the failed logins.
+func (l Lockout) CheckRetry(failures int, last *time.Time) error {
+	if last == nil {
+		return nil
+	}
+	if l.RetryAt(failures, *last).After(time.Now().UTC()) {
+		return ErrRateLimitExceeded
+	}
+	return nil
+}
+
+// Refresh config. Zero values disable a lifetime.
+type Refresh struct {
+	// Expiration is the absolute lifetime of a session. Once a session
+	// expires the user must login again, even if it is still in use.
+	Expiration time.Duration `json:"expiration"`
+	// Idle is the length of time a refresh token may go unused before it expires.
+	Idle time.Duration `json:"idle"`
+	// Grace is the length of time a swapped refresh token may be used again
+	// before it is treated as reused, e.g. when a client retries a refresh.
+	Grace time.Duration `json:"grace"`
+	// ReuseAlert mails the user when the reuse of a refresh token is detected.
+	ReuseAlert bool `json:"reuse_alert" yaml:"reuse_alert" mapstructure:"reuse_alert"`
+}
+
+// Impersonation config
+type Impersonation struct {
+	// Expiration is the length of time an impersonation token is valid.
+	Expiration time.Duration `json:"expiration"`
+}
+
+// ExpiresAt returns the time a refresh token issued now for a session that
+// signed in at signedIn expires, or nil if the refresh token does not expire.
+func (r Refresh) ExpiresAt(signedIn time.Time) *time.Time {
+	var exp *time.Time
+	if r.Expiration > 0 {
+		t := signedIn.Add(r.Expiration)
+		exp = &t
+	}
+	if r.Idle > 0 {
+		t := time.Now().UTC().Add(r.Idle)
+		if exp == nil || t.Before(*exp) {
+			exp = &t
+		}
+	}
+	return exp
+}
+
+// InGrace returns true if a refresh token that was swapped at swappedAt
+// is still within the reuse grace period.
+func (r Refresh) InGrace(swappedAt time.Time) bool {
+	if r.Grace <= 0 || swappedAt.IsZero() {
+		return false
+	}
+	return time.Now().UTC().Before(swappedAt.Add(r.Grace))
+}
+
+// OIDC config
+type OIDC struct {
+	// Issuer is the issuer url for ID tokens and discovery (default: SiteURL).
+	Issuer string `json:"issuer"`
+	// Expiration is the length of time an authorization code is valid.
+	Expiration time.Duration `json:"expiration"`
+	// ConsentURL is the url of the consent screen. If set, it is published
+	// as the authorization endpoint instead of the authorization api.
+	ConsentURL string `json:"consent_url" yaml:"consent_url" mapstructure:"consent_url"`
+	// DeviceExpiration is the length of time a device code is valid.
+	DeviceExpiration time.Duration `json:"device_expiration" yaml:"device_expiration" mapstructure:"device_expiration"`
+	// DeviceInterval is the minimum length of time a device must wait between polls.
+	DeviceInterval time.Duration `json:"device_interval" yaml:"device_interval" mapstructure:"device_interval"`
+	// VerificationURL is the url of the device verification page. If set,
+	// it is returned to devices instead of the device verification api.
+	VerificationURL string `json:"verification_url" yaml:"verification_url" mapstructure:"verification_url"`
+}
+
+func (o *OIDC) normalize(srv Service, j JWT) error {
+	if o.Issuer == "" {
+		o.Issuer = srv.SiteURL
+	}
+	if o.Issuer != "" {
+		_, err := url.Parse(o.Issuer)
+		if err != nil {
+			return err
+		}
+	}
+	if o.ConsentURL != "" {
+		_, err := url.Parse(o.ConsentURL)
+		if err != nil {
+			return err
+		}
+	}
+	if o.VerificationURL != "" {
+		_, err := url.Parse(o.VerificationURL)
+		if err != nil {
+			return err
+		}
+	}
+	// ID tokens must never be accepted as bearer tokens
+	if o.Issuer != "" && o.Issuer == j.Issuer {
+		return errors.New("oidc issuer must not be the jwt issuer")
+	}
+	if o.Expiration == 0 {
+		o.Expiration = oidcExpiration
+	}
+	if o.DeviceExpiration == 0 {
+		o.DeviceExpiration = deviceExpiration
+	}
+	if o.DeviceInterval == 0 {
+		o.DeviceInterval = deviceInterval
+	}
+	return nil
+}
+
+// Hash config
+type Hash struct {
+	// Algorithm is the password hashing algorithm used for new
+	// passwords (argon2id, bcrypt or scrypt). Passwords hashed with
+	// a different algorithm or parameters are rehashed on login.
+	Algorithm string `json:"algorithm"`
+	// Argon2 is the argon2id configuration.
+	Argon2 Argon2 `json:"argon2"`
+	// Bcrypt is the bcrypt configuration.
+	Bcrypt Bcrypt `json:"bcrypt"`
+	// Scrypt is the scrypt configuration.
+	Scrypt Scrypt `json:"scrypt"`
+}
+
+func (h *Hash) normalize() {
+	h.Algorithm = strings.ToLower(h.Algorithm)
+	if h.Algorithm == "" {
+		h.Algorithm = hashAlgorithm
+	}
+}
+
+// Argon2 config. Zero values use the hasher defaults.
+type Argon2 struct {
+	// Memory is the amount of memory used in KiB.
+	Memory uint32 `json:"memory"`
+	// Iterations is the number of passes over the memory.
+	Iterations uint32 `json:"iterations"`
+	// Parallelism is the number of threads used.
+	Parallelism uint8 `json:"parallelism"`
+}
+
+// Bcrypt config. Zero values use the hasher defaults.
+type Bcrypt struct {
+	// Cost is the bcrypt cost.
+	Cost int `json:"cost"`
+}
+
+// Scrypt config. Zero values use the hasher defaults.
+type Scrypt struct {
+	// N is the CPU/memory cost. It must be a power of two.
+	N int `json:"n"`
+	// R is the block size.
+	R int `json:"r"`
+	// P is the parallelization.
+	P int `json:"p"`
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -15,7 +16,33 @@ const (
 	recapLogin   = false
 	userRx       = "[A-Za-z]{3}[0-9][A-Z]{2}[!@#$%^&*]"
 	passRx       = "FOO[A-Z]{10}[0-9]{2}"
+	breachedPw   = "./breached.bloom"
+	passMin      = 10
+	passMax      = 100
+	passScore    = 3
+	bannedWord   = "banned"
+	bannedWord2  = "words"
+	passHistory  = 5
+	passMaxAge   = 1000 * time.Minute
 	duration     = 100 * time.Minute
+	mfaIssuer    = "issuer"
+	rpID         = "rp.example.com"
+	rpName       = "rp-name"
+	rpOrigin     = "https://rp.example.com"
+	loginOrigin  = "https://login.example.com"
+	attempts     = 10
+	ipAttempts   = 100
+	hashAlg      = "scrypt"
+	hashMemory   = 1024
+	hashIters    = 10
+	hashThreads  = 10
+	hashCost     = 10
+	hashN        = 1024
+	hashR        = 10
+	hashP        = 10
+	oidcIssuer   = "https://id.example.com"
+	consentURL   = "https://www.example.com/consent"
+	verifyURL    = "https://www.example.com/device"
 )
 
 func TestSecurity(t *testing.T) {
@@ -28,7 +55,58 @@ func TestSecurity(t *testing.T) {
 		assert.Equal(t, recapLogin, s.Recaptcha.Login)
 		assert.Equal(t, userRx+test.mark, s.Validation.UsernameRegex)
 		assert.Equal(t, passRx+test.mark, s.Validation.PasswordRegex)
+		assert.Equal(t, breachedPw+test.mark, s.Validation.BreachedPasswords)
+		assert.Equal(t, passMin, s.Password.MinLength)
+		assert.Equal(t, passMax, s.Password.MaxLength)
+		assert.True(t, s.Password.Lowercase)
+		assert.True(t, s.Password.Uppercase)
+		assert.True(t, s.Password.Digit)
+		assert.True(t, s.Password.Symbol)
+		assert.Equal(t, passScore, s.Password.MinScore)
+		assert.Equal(t, []string{
+			bannedWord + test.mark,
+			bannedWord2 + test.mark,
+		}, s.Password.BannedWords)
+		assert.Equal(t, passHistory, s.Password.History)
+		assert.Equal(t, duration, s.Password.MinAge)
+		assert.Equal(t, passMaxAge, s.Password.MaxAge)
+		assert.Equal(t, duration, s.Password.Reminder)
+		assert.Equal(t, duration, s.Password.ChangeExpiration)
 		assert.Equal(t, duration, s.Cookies.Duration)
+		assert.Equal(t, mfaIssuer+test.mark, s.MFA.Issuer)
+		assert.Equal(t, duration, s.MFA.Expiration)
+		assert.Equal(t, rpID+test.mark, s.WebAuthn.RPID)
+		assert.Equal(t, rpName+test.mark, s.WebAuthn.RPName)
+		assert.Equal(t, []string{
+			rpOrigin + test.mark,
+			loginOrigin + test.mark,
+		}, s.WebAuthn.Origins)
+		assert.Equal(t, duration, s.WebAuthn.Expiration)
+		assert.Equal(t, duration, s.MagicLink.Expiration)
+		assert.Equal(t, attempts, s.Lockout.Attempts)
+		assert.Equal(t, ipAttempts, s.Lockout.IPAttempts)
+		assert.Equal(t, duration, s.Lockout.Window)
+		assert.Equal(t, duration, s.Lockout.Duration)
+		assert.Equal(t, duration, s.Lockout.Delay)
+		assert.Equal(t, passMaxAge, s.Refresh.Expiration)
+		assert.Equal(t, duration, s.Refresh.Idle)
+		assert.Equal(t, duration, s.Refresh.Grace)
+		assert.True(t, s.Refresh.ReuseAlert)
+		assert.Equal(t, duration, s.Impersonation.Expiration)
+		assert.Equal(t, oidcIssuer+test.mark, s.OIDC.Issuer)
+		assert.Equal(t, duration, s.OIDC.Expiration)
+		assert.Equal(t, consentURL+test.mark, s.OIDC.ConsentURL)
+		assert.Equal(t, duration, s.OIDC.DeviceExpiration)
+		assert.Equal(t, duration, s.OIDC.DeviceInterval)
+		assert.Equal(t, verifyURL+test.mark, s.OIDC.VerificationURL)
+		assert.Equal(t, hashAlg+test.mark, s.Hash.Algorithm)
+		assert.Equal(t, uint32(hashMemory), s.Hash.Argon2.Memory)
+		assert.Equal(t, uint32(hashIters), s.Hash.Argon2.Iterations)
+		assert.Equal(t, uint8(hashThreads), s.Hash.Argon2.Parallelism)
+		assert.Equal(t, hashCost, s.Hash.Bcrypt.Cost)
+		assert.Equal(t, hashN, s.Hash.Scrypt.N)
+		assert.Equal(t, hashR, s.Hash.Scrypt.R)
+		assert.Equal(t, hashP, s.Hash.Scrypt.P)
 	})
 }
 
@@ -48,7 +126,58 @@ func TestSecurity_Env(t *testing.T) {
 			assert.Equal(t, recapLogin, s.Recaptcha.Login)
 			assert.Equal(t, userRx, s.Validation.UsernameRegex)
 			assert.Equal(t, passRx, s.Validation.PasswordRegex)
+			assert.Equal(t, breachedPw, s.Validation.BreachedPasswords)
+			assert.Equal(t, passMin, s.Password.MinLength)
+			assert.Equal(t, passMax, s.Password.MaxLength)
+			assert.True(t, s.Password.Lowercase)
+			assert.True(t, s.Password.Uppercase)
+			assert.True(t, s.Password.Digit)
+			assert.True(t, s.Password.Symbol)
+			assert.Equal(t, passScore, s.Password.MinScore)
+			assert.Equal(t, []string{bannedWord, bannedWord2}, s.Password.BannedWords)
+			assert.Equal(t, passHistory, s.Password.History)
+			assert.Equal(t, duration, s.Password.MinAge)
+			assert.Equal(t, passMaxAge, s.Password.MaxAge)
+			assert.Equal(t, duration, s.Password.Reminder)
+			assert.Equal(t, duration, s.Password.ChangeExpiration)
+			assert.Equal(t, passMaxAge, s.Password.MaxAge)
+			assert.Equal(t, duration, s.Password.Reminder)
+			assert.Equal(t, duration, s.Password.ChangeExpiration)
 			assert.Equal(t, duration, s.Cookies.Duration)
+			assert.Equal(t, mfaIssuer, s.MFA.Issuer)
+			assert.Equal(t, duration, s.MFA.Expiration)
+			assert.Equal(t, rpID, s.WebAuthn.RPID)
+			assert.Equal(t, rpName, s.WebAuthn.RPName)
+			assert.Equal(t, []string{
+				rpOrigin,
+				loginOrigin,
+			}, s.WebAuthn.Origins)
+			assert.Equal(t, duration, s.WebAuthn.Expiration)
+			assert.Equal(t, duration, s.MagicLink.Expiration)
+			assert.Equal(t, attempts, s.Lockout.Attempts)
+			assert.Equal(t, ipAttempts, s.Lockout.IPAttempts)
+			assert.Equal(t, duration, s.Lockout.Window)
+			assert.Equal(t, duration, s.Lockout.Duration)
+			assert.Equal(t, duration, s.Lockout.Delay)
+			assert.Equal(t, passMaxAge, s.Refresh.Expiration)
+			assert.Equal(t, duration, s.Refresh.Idle)
+			assert.Equal(t, duration, s.Refresh.Grace)
+			assert.True(t, s.Refresh.ReuseAlert)
+			assert.Equal(t, duration, s.Impersonation.Expiration)
+			assert.Equal(t, oidcIssuer, s.OIDC.Issuer)
+			assert.Equal(t, duration, s.OIDC.Expiration)
+			assert.Equal(t, consentURL, s.OIDC.ConsentURL)
+			assert.Equal(t, duration, s.OIDC.DeviceExpiration)
+			assert.Equal(t, duration, s.OIDC.DeviceInterval)
+			assert.Equal(t, verifyURL, s.OIDC.VerificationURL)
+			assert.Equal(t, hashAlg, s.Hash.Algorithm)
+			assert.Equal(t, uint32(hashMemory), s.Hash.Argon2.Memory)
+			assert.Equal(t, uint32(hashIters), s.Hash.Argon2.Iterations)
+			assert.Equal(t, uint8(hashThreads), s.Hash.Argon2.Parallelism)
+			assert.Equal(t, hashCost, s.Hash.Bcrypt.Cost)
+			assert.Equal(t, hashN, s.Hash.Scrypt.N)
+			assert.Equal(t, hashR, s.Hash.Scrypt.R)
+			assert.Equal(t, hashP, s.Hash.Scrypt.P)
 		})
 	}
 }
@@ -72,6 +201,60 @@ func TestSecurity_Normalization(t *testing.T) {
 	})
 	assert.NoError(t, err)
 	assert.Equal(t, cookieDuration, s.Cookies.Duration)
+	assert.Equal(t, service, s.MFA.Issuer)
+	assert.Equal(t, mfaExpiration, s.MFA.Expiration)
+	assert.Equal(t, "example.com", s.WebAuthn.RPID)
+	assert.Equal(t, service, s.WebAuthn.RPName)
+	assert.Equal(t, []string{siteURL}, s.WebAuthn.Origins)
+	assert.Equal(t, webauthnExpiration, s.WebAuthn.Expiration)
+	assert.Equal(t, magicLinkExpiration, s.MagicLink.Expiration)
+	assert.Equal(t, lockoutWindow, s.Lockout.Window)
+	assert.Equal(t, lockoutDuration, s.Lockout.Duration)
+	assert.Equal(t, hashAlgorithm, s.Hash.Algorithm)
+	assert.Equal(t, passwordChangeExp, s.Password.ChangeExpiration)
+	assert.Equal(t, siteURL, s.OIDC.Issuer)
+	assert.Equal(t, oidcExpiration, s.OIDC.Expiration)
+	assert.Equal(t, deviceExpiration, s.OIDC.DeviceExpiration)
+	assert.Equal(t, deviceInterval, s.OIDC.DeviceInterval)
+	assert.Equal(t, impersonationExp, s.Impersonation.Expiration)
+	s.Password.MinScore = MaxScore + 1
+	err = s.normalize(serviceDefaults)
+	assert.Error(t, err)
+	s.Password.MinScore = 0
+	s.Password.MinLength = 10
+	s.Password.MaxLength = 8
+	err = s.normalize(serviceDefaults)
+	assert.Error(t, err)
+	s.Password.MinLength = -1
+	err = s.normalize(serviceDefaults)
+	assert.Error(t, err)
+	s.Password = Password{History: -1}
+	err = s.normalize(serviceDefaults)
+	assert.Error(t, err)
+	s.Password = Password{MaxAge: -1}
+	err = s.normalize(serviceDefaults)
+	assert.Error(t, err)
+	s.Password = Password{MinAge: time.Hour, MaxAge: time.Hour}
+	err = s.normalize(serviceDefaults)
+	assert.Error(t, err)
+	s.Password = Password{}
+	s.Refresh = Refresh{Idle: -1}
+	err = s.normalize(serviceDefaults)
+	assert.Error(t, err)
+	s.Refresh = Refresh{Grace: -1}
+	err = s.normalize(serviceDefaults)
+	assert.Error(t, err)
+	s.Refresh = Refresh{}
+	s.OIDC = OIDC{Issuer: ":bad"}
+	err = s.normalize(serviceDefaults)
+	assert.Error(t, err)
+	s.OIDC = OIDC{ConsentURL: ":bad"}
+	err = s.normalize(serviceDefaults)
+	assert.Error(t, err)
+	s.OIDC = OIDC{Issuer: s.JWT.Issuer}
+	err = s.normalize(serviceDefaults)
+	assert.Error(t, err)
+	s.OIDC = OIDC{}
 	s.Validation.PasswordRegex = "a(?=r)"
 	err = s.normalize(serviceDefaults)
 	assert.Error(t, err)
@@ -79,3 +262,63 @@ func TestSecurity_Normalization(t *testing.T) {
 	err = s.normalize(serviceDefaults)
 	assert.Error(t, err)
 }
+
+func TestLockout_CheckRetry(t *testing.T) {
+	l := Lockout{Duration: 10 * time.Minute}
+	assert.False(t, l.Enabled())
+	now := time.Now().UTC()
+	err := l.CheckRetry(1, nil)
+	assert.NoError(t, err)
+	// no delay
+	err = l.CheckRetry(1, &now)
+	assert.NoError(t, err)
+	l.Delay = time.Minute
+	assert.True(t, l.Enabled())
+	assert.Equal(t, now, l.RetryAt(0, now))
+	assert.Equal(t, now.Add(time.Minute), l.RetryAt(1, now))
+	assert.Equal(t, now.Add(2*time.Minute), l.RetryAt(2, now))
+	assert.Equal(t, now.Add(4*time.Minute), l.RetryAt(3, now))
+	// capped at the lock duration
+	assert.Equal(t, now.Add(l.Duration), l.RetryAt(10, now))
+	err = l.CheckRetry(1, &now)
+	assert.ErrorIs(t, err, ErrRateLimitExceeded)
+	last := now.Add(-90 * time.Second)
+	err = l.CheckRetry(1, &last)
+	assert.NoError(t, err)
+	err = l.CheckRetry(2, &last)
+	assert.ErrorIs(t, err, ErrRateLimitExceeded)
+}
+
+func TestRefresh_ExpiresAt(t *testing.T) {
+	r := Refresh{}
+	signedIn := time.Now().UTC().Add(-time.Hour)
+	assert.Nil(t, r.ExpiresAt(signedIn))
+	r.Expiration = 2 * time.Hour
+	exp := r.ExpiresAt(signedIn)
+	require.NotNil(t, exp)
+	assert.Equal(t, signedIn.Add(r.Expiration), *exp)
+	// idle before absolute
+	r.Idle = 10 * time.Minute
+	exp = r.ExpiresAt(signedIn)
+	require.NotNil(t, exp)
+	assert.WithinDuration(t, time.Now().Add(r.Idle), *exp, time.Second)
+	// absolute before idle
+	r.Idle = 90 * time.Minute
+	exp = r.ExpiresAt(signedIn)
+	require.NotNil(t, exp)
+	assert.Equal(t, signedIn.Add(r.Expiration), *exp)
+	r.Expiration = 0
+	exp = r.ExpiresAt(signedIn)
+	require.NotNil(t, exp)
+	assert.WithinDuration(t, time.Now().Add(r.Idle), *exp, time.Second)
+}
+
+func TestRefresh_InGrace(t *testing.T) {
+	r := Refresh{}
+	now := time.Now().UTC()
+	assert.False(t, r.InGrace(now))
+	r.Grace = time.Minute
+	assert.True(t, r.InGrace(now))
+	assert.False(t, r.InGrace(now.Add(-2*time.Minute)))
+	assert.False(t, r.InGrace(time.Time{}))
+}
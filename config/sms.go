@@ -0,0 +1,85 @@
+package config
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SMS senders
+const (
+	// SMSHTTP sends sms messages through a generic http provider.
+	SMSHTTP = "http"
+	// SMSFile writes sms messages to a file (or stdout). For dev & testing.
+	SMSFile = "file"
+)
+
+// SMS config
+type SMS struct {
+	// Sender options are "http", "file", "" = offline.
+	Sender string `json:"sender"`
+	// From is the originating phone number or sender id.
+	From string `json:"from"`
+	// URL is the url of the http sms provider.
+	URL string `json:"url"`
+	// Token is the bearer token used to authenticate with the http sms provider.
+	Token string `json:"token"`
+	// File is the path the file sender writes to (default: stdout).
+	File string `json:"file"`
+	// Message is the format of the sms message. Any ':name' and
+	// ':code' strings are replaced with the service name and code.
+	Message    string        `json:"message"`
+	Expiration time.Duration `json:"expiration"`
+	SendLimit  time.Duration `json:"send_limit" yaml:"send_limit" mapstructure:"send_limit"`
+	// Login enables phone & one-time code logins.
+	Login bool `json:"login"`
+}
+
+func (s *SMS) normalize(srv Service) error {
+	s.Sender = strings.ToLower(s.Sender)
+	switch s.Sender {
+	case "", SMSFile:
+		break
+	case SMSHTTP:
+		if s.URL == "" {
+			return errors.New("sms url required")
+		}
+		_, err := url.Parse(s.URL)
+		if err != nil {
+			return err
+		}
+	default:
+		return errors.New("invalid sms sender: " + s.Sender)
+	}
+	if s.Message == "" {
+		s.Message = smsMessage
+	}
+	s.Message = strings.ReplaceAll(s.Message, ":name", srv.Name)
+	if s.Expiration == 0 {
+		s.Expiration = smsExpiration
+	}
+	return nil
+}
+
+// Enabled returns true if an sms sender is configured.
+func (s SMS) Enabled() bool {
+	return s.Sender != ""
+}
+
+// FormatMessage formats the sms message for the code.
+func (s SMS) FormatMessage(code string) string {
+	return strings.ReplaceAll(s.Message, ":code", code)
+}
+
+// CheckSendLimit returns ErrRateLimitExceeded if last exceeds the send limit
+func (s SMS) CheckSendLimit(last *time.Time) error {
+	if last == nil {
+		return nil
+	}
+	// FUTURE happened before now
+	if last.Add(s.SendLimit).After(time.Now().UTC()) {
+		return ErrRateLimitExceeded
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	smsSender  = SMSHTTP
+	smsFrom    = "Gothic"
+	smsURL     = "http://sms.example.com/send"
+	smsToken   = "i-am-an-sms-token"
+	smsFile    = "./sms.log"
+	smsMsg     = "Your code is :code"
+	smsLimit   = 100 * time.Minute
+	smsLogin   = true
+	smsTestPIN = "123456"
+)
+
+func TestSMS(t *testing.T) {
+	runTests(t, func(t *testing.T, test testCase, c *Config) {
+		s := c.SMS
+		assert.Equal(t, smsSender, s.Sender)
+		assert.Equal(t, smsFrom+test.mark, s.From)
+		assert.Equal(t, smsURL+test.mark, s.URL)
+		assert.Equal(t, smsToken+test.mark, s.Token)
+		assert.Equal(t, smsFile+test.mark, s.File)
+		assert.Equal(t, smsMsg+test.mark, s.Message)
+		assert.Equal(t, testTimeout, s.Expiration)
+		assert.Equal(t, smsLimit, s.SendLimit)
+		assert.Equal(t, smsLogin, s.Login)
+		assert.True(t, s.Enabled())
+	})
+}
+
+// tests the ENV vars are correctly taking precedence
+func TestSMS_Env(t *testing.T) {
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clearEnv()
+			loadDotEnv(t)
+			c, err := loadNormalized(test.file)
+			assert.NoError(t, err)
+			s := c.SMS
+			assert.Equal(t, smsSender, s.Sender)
+			assert.Equal(t, smsFrom, s.From)
+			assert.Equal(t, smsURL, s.URL)
+			assert.Equal(t, smsToken, s.Token)
+			assert.Equal(t, smsFile, s.File)
+			assert.Equal(t, smsMsg, s.Message)
+			assert.Equal(t, testTimeout, s.Expiration)
+			assert.Equal(t, smsLimit, s.SendLimit)
+			assert.Equal(t, smsLogin, s.Login)
+		})
+	}
+}
+
+// test the *un-normalized* defaults with load
+func TestSMS_Defaults(t *testing.T) {
+	clearEnv()
+	c, err := load("")
+	assert.NoError(t, err)
+	def := smsDefaults
+	s := c.SMS
+	assert.Equal(t, def, s)
+	assert.False(t, s.Enabled())
+}
+
+func TestSMS_Normalization(t *testing.T) {
+	srv := Service{Name: service}
+	s := SMS{}
+	err := s.normalize(srv)
+	assert.NoError(t, err)
+	assert.False(t, s.Enabled())
+	assert.Equal(t, service+" verification code: :code", s.Message)
+	assert.Equal(t, smsExpiration, s.Expiration)
+	s = SMS{Sender: "FILE"}
+	err = s.normalize(srv)
+	assert.NoError(t, err)
+	assert.Equal(t, SMSFile, s.Sender)
+	assert.True(t, s.Enabled())
+	s = SMS{Sender: SMSHTTP}
+	err = s.normalize(srv)
+	assert.Error(t, err)
+	s = SMS{Sender: SMSHTTP, URL: "\n"}
+	err = s.normalize(srv)
+	assert.Error(t, err)
+	s = SMS{Sender: SMSHTTP, URL: smsURL}
+	err = s.normalize(srv)
+	assert.NoError(t, err)
+	s = SMS{Sender: "bad"}
+	err = s.normalize(srv)
+	assert.Error(t, err)
+}
+
+func TestSMS_FormatMessage(t *testing.T) {
+	s := SMS{Message: smsMsg}
+	assert.Equal(t, "Your code is "+smsTestPIN, s.FormatMessage(smsTestPIN))
+}
+
+func TestSMS_CheckSendLimit(t *testing.T) {
+	s := SMS{SendLimit: smsLimit}
+	err := s.CheckSendLimit(nil)
+	assert.NoError(t, err)
+	now := time.Now().UTC()
+	err = s.CheckSendLimit(&now)
+	assert.ErrorIs(t, err, ErrRateLimitExceeded)
+	last := now.Add(-2 * smsLimit)
+	err = s.CheckSendLimit(&last)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,191 @@
+package config
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	"dario.cat/mergo"
+)
+
+// Tenants config
+type Tenants struct {
+	// Enabled enables multi-tenant mode.
+	Enabled bool `json:"enabled"`
+	// Header is the http header & grpc metadata key used to
+	// resolve the tenant of a request (default: x-tenant).
+	Header string `json:"header"`
+	// PathPrefix is the rest path prefix used to resolve the tenant of a
+	// request, e.g. "/t" resolves "/t/:tenant/signup" (default: /t).
+	PathPrefix string `json:"path_prefix" yaml:"path_prefix" mapstructure:"path_prefix"`
+}
+
+func (t *Tenants) normalize() {
+	if t.Header == "" {
+		t.Header = tenantsDefaults.Header
+	}
+	t.Header = strings.ToLower(t.Header)
+	if t.PathPrefix == "" {
+		t.PathPrefix = tenantsDefaults.PathPrefix
+	}
+	t.PathPrefix = "/" + strings.Trim(t.PathPrefix, "/")
+}
+
+// TenantConfig holds the settings a tenant can override. Settings that are
+// empty are inherited from the base config.
+type TenantConfig struct {
+	// Name is the service name of the tenant.
+	Name string `json:"service,omitempty" yaml:"service,omitempty" mapstructure:"service"`
+	// SiteURL is the url of the tenant.
+	SiteURL string `json:"site_url,omitempty" yaml:"site_url,omitempty" mapstructure:"site_url"`
+	// Mail is the outbound mail branding of the tenant.
+	Mail MailFormat `json:"mail,omitempty" yaml:"mail,omitempty"`
+	// Templates are the mail templates of the tenant.
+	Templates MailTemplates `json:"templates,omitempty" yaml:"templates,omitempty"`
+	// Signup replaces the signup configuration for the tenant.
+	Signup *Signup `json:"signup,omitempty" yaml:"signup,omitempty"`
+	// RedirectURL is the external provider redirect url of the tenant.
+	RedirectURL string `json:"provider_redirect_url,omitempty" yaml:"provider_redirect_url,omitempty" mapstructure:"provider_redirect_url"`
+	// Providers replaces the external providers for the tenant.
+	Providers Providers `json:"provider,omitempty" yaml:"provider,omitempty" mapstructure:"provider"`
+	// JWT is the jwt issuer & signing key of the tenant.
+	JWT TenantJWT `json:"jwt,omitempty" yaml:"jwt,omitempty"`
+}
+
+// TenantJWT holds the jwt settings a tenant can override.
+type TenantJWT struct {
+	Secret    string `json:"secret,omitempty" yaml:"secret,omitempty"`
+	PEM       `yaml:",inline" mapstructure:",squash"`
+	Algorithm string `json:"algorithm,omitempty" yaml:"algorithm,omitempty"`
+	Issuer    string `json:"issuer,omitempty" yaml:"issuer,omitempty"`
+	Audience  string `json:"audience,omitempty" yaml:"audience,omitempty"`
+	KeyID     string `json:"key_id,omitempty" yaml:"key_id,omitempty" mapstructure:"key_id"`
+}
+
+// ForTenant returns a copy of the config with the tenant settings applied.
+// Settings that were derived from the base service name or site url (e.g.
+// the mail link or the jwt issuer) are derived again for the tenant.
+func (c *Config) ForTenant(tc TenantConfig) (*Config, error) {
+	tcfg := *c
+	if tc.Name != "" {
+		tcfg.Name = tc.Name
+	}
+	if tc.SiteURL != "" {
+		_, err := url.Parse(tc.SiteURL)
+		if err != nil {
+			return nil, err
+		}
+		tcfg.SiteURL = tc.SiteURL
+	}
+	err := tcfg.Mail.forTenant(c.Service, tcfg.Service, c.Mail, tc)
+	if err != nil {
+		return nil, err
+	}
+	if tc.Signup != nil {
+		tcfg.Signup = *tc.Signup
+	}
+	if tc.RedirectURL != "" {
+		_, err = url.Parse(tc.RedirectURL)
+		if err != nil {
+			return nil, err
+		}
+		tcfg.RedirectURL = tc.RedirectURL
+	}
+	if tc.Providers != nil {
+		tcfg.Providers = make(Providers, len(tc.Providers))
+		for name, p := range tc.Providers {
+			tcfg.Providers[name] = p
+		}
+		err = tcfg.Providers.normalize(tcfg.RESTAddress)
+		if err != nil {
+			return nil, err
+		}
+	}
+	tcfg.JWT.forTenant(c.Service, tcfg.Service, tc.JWT)
+	err = tcfg.JWT.CheckRequired()
+	if err != nil {
+		return nil, err
+	}
+	if c.OIDC.Issuer == c.SiteURL {
+		tcfg.OIDC.Issuer = tcfg.SiteURL
+	}
+	if tcfg.OIDC.Issuer != "" && tcfg.OIDC.Issuer == tcfg.JWT.Issuer {
+		return nil, errors.New("oidc issuer must not be the jwt issuer")
+	}
+	return &tcfg, nil
+}
+
+func (m *Mail) forTenant(base, srv Service, def Mail, tc TenantConfig) error {
+	if m.Name == defaultMailName(base) {
+		m.Name = defaultMailName(srv)
+	}
+	if m.Link == base.SiteURL {
+		m.Link = srv.SiteURL
+	}
+	// no error is possible here since we
+	// control the struct entirely
+	_ = mergo.Merge(&m.MailFormat, tc.Mail, mergo.WithOverride)
+	from := tc.Mail.From
+	if from == "" {
+		derived, _ := formatFrom(mailDefaults.From, def.Name, def.Link)
+		if def.From != derived {
+			from = def.From
+		} else {
+			from = mailDefaults.From
+		}
+	}
+	if m.Link != "" {
+		var err error
+		from, err = formatFrom(from, m.Name, m.Link)
+		if err != nil {
+			return err
+		}
+	}
+	m.From = from
+	referralURLs := []*string{
+		&m.ChangeEmail.ReferralURL,
+		&m.ConfirmUser.ReferralURL,
+		&m.InviteUser.ReferralURL,
+		&m.MagicLink.ReferralURL,
+		&m.PasswordExpiry.ReferralURL,
+		&m.ResetPassword.ReferralURL,
+		&m.SignupCode.ReferralURL,
+		&m.TokenReused.ReferralURL,
+		&m.UnlockUser.ReferralURL,
+	}
+	for _, ref := range referralURLs {
+		if *ref == base.SiteURL {
+			*ref = srv.SiteURL
+		}
+	}
+	_ = mergo.Merge(&m.MailTemplates, tc.Templates, mergo.WithOverride)
+	return m.MailTemplates.normalize(srv)
+}
+
+func (j *JWT) forTenant(base, srv Service, tj TenantJWT) {
+	if tj.Secret != "" || tj.PEM.PrivateKey != "" {
+		// a tenant signing key does not share the keys of the base
+		j.Secret = tj.Secret
+		j.PEM = tj.PEM
+		j.KeyID = ""
+		j.Keys = nil
+	}
+	if j.Issuer == strings.ToLower(base.Name) {
+		j.Issuer = strings.ToLower(srv.Name)
+	}
+	if tj.Algorithm != "" {
+		j.Algorithm = tj.Algorithm
+	}
+	if tj.Issuer != "" {
+		j.Issuer = tj.Issuer
+	}
+	if tj.Audience != "" {
+		j.Audience = tj.Audience
+	}
+	if tj.KeyID != "" {
+		j.KeyID = tj.KeyID
+	}
+	// the cached keys belong to the base
+	j.sk = nil
+	j.pk = nil
+}
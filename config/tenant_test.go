@@ -0,0 +1,150 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jrapoport/gothic/models/types/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	tenantHeader = "x-tenant-id"
+	tenantPrefix = "/tenants"
+)
+
+func TestTenants(t *testing.T) {
+	runTests(t, func(t *testing.T, test testCase, c *Config) {
+		tn := c.Tenants
+		assert.True(t, tn.Enabled)
+		assert.Equal(t, tenantHeader+test.mark, tn.Header)
+		assert.Equal(t, tenantPrefix+test.mark, tn.PathPrefix)
+	})
+}
+
+// tests the ENV vars are correctly taking precedence
+func TestTenants_Env(t *testing.T) {
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clearEnv()
+			loadDotEnv(t)
+			c, err := loadNormalized(test.file)
+			assert.NoError(t, err)
+			tn := c.Tenants
+			assert.True(t, tn.Enabled)
+			assert.Equal(t, tenantHeader, tn.Header)
+			assert.Equal(t, tenantPrefix, tn.PathPrefix)
+		})
+	}
+}
+
+// test the *un-normalized* defaults with load
+func TestTenants_Defaults(t *testing.T) {
+	clearEnv()
+	c, err := load("")
+	assert.NoError(t, err)
+	def := tenantsDefaults
+	tn := c.Tenants
+	assert.Equal(t, def, tn)
+}
+
+func TestTenants_Normalization(t *testing.T) {
+	tn := Tenants{}
+	tn.normalize()
+	assert.Equal(t, tenantsDefaults, tn)
+	tn = Tenants{
+		Header:     "X-Brand",
+		PathPrefix: "brands/",
+	}
+	tn.normalize()
+	assert.Equal(t, "x-brand", tn.Header)
+	assert.Equal(t, "/brands", tn.PathPrefix)
+}
+
+func TestConfig_ForTenant(t *testing.T) {
+	clearEnv()
+	c := &Config{}
+	c.Service = Service{Name: service, SiteURL: siteURL}
+	c.JWT.Secret = "base-secret"
+	c.JWT.KeyID = "base"
+	err := c.normalize()
+	require.NoError(t, err)
+	baseKey := c.JWT.PrivateKey()
+	require.NotNil(t, baseKey)
+	const (
+		tenantName = "brand"
+		tenantURL  = "http://brand.example.com"
+	)
+	signup := Signup{Disabled: true}
+	tc := TenantConfig{
+		Name:    tenantName,
+		SiteURL: tenantURL,
+		Mail: MailFormat{
+			Logo: "http://brand.example.com/logo.png",
+		},
+		Templates: MailTemplates{
+			ConfirmUser: MailTemplate{Subject: "Welcome to Brand"},
+		},
+		Signup: &signup,
+		Providers: Providers{
+			provider.Google: Provider{ClientKey: "brand-key"},
+			provider.GitHub: Provider{},
+		},
+		JWT: TenantJWT{
+			Secret: "brand-secret",
+		},
+	}
+	tcfg, err := c.ForTenant(tc)
+	require.NoError(t, err)
+	// the base is unchanged
+	assert.Equal(t, service, c.Name)
+	assert.Equal(t, siteURL, c.Mail.Link)
+	assert.Equal(t, siteURL, c.Mail.ConfirmUser.ReferralURL)
+	assert.Equal(t, "", c.Mail.ConfirmUser.Subject)
+	assert.False(t, c.Signup.Disabled)
+	assert.Equal(t, "base-secret", c.JWT.Secret)
+	assert.Equal(t, baseKey, c.JWT.PrivateKey())
+	// the tenant is overridden
+	assert.Equal(t, tenantName, tcfg.Name)
+	assert.Equal(t, tenantURL, tcfg.SiteURL)
+	assert.Equal(t, strings.Title(tenantName), tcfg.Mail.Name)
+	assert.Equal(t, tenantURL, tcfg.Mail.Link)
+	assert.Equal(t, tc.Mail.Logo, tcfg.Mail.Logo)
+	assert.Equal(t, "Brand <do-not-reply@brand.example.com>", tcfg.Mail.From)
+	assert.Equal(t, tenantURL, tcfg.Mail.ConfirmUser.ReferralURL)
+	assert.Equal(t, "Welcome to Brand", tcfg.Mail.ConfirmUser.Subject)
+	assert.Equal(t, c.Mail.ConfirmUser.LinkFormat, tcfg.Mail.ConfirmUser.LinkFormat)
+	assert.True(t, tcfg.Signup.Disabled)
+	assert.Len(t, tcfg.Providers, 1)
+	assert.NotEmpty(t, tcfg.Providers[provider.Google].CallbackURL)
+	assert.Equal(t, "brand-secret", tcfg.JWT.Secret)
+	assert.Equal(t, "", tcfg.JWT.KeyID)
+	assert.Equal(t, tenantName, tcfg.JWT.Issuer)
+	assert.NotEqual(t, baseKey, tcfg.JWT.PrivateKey())
+	assert.Equal(t, tenantURL, tcfg.OIDC.Issuer)
+	// empty settings are inherited
+	tcfg, err = c.ForTenant(TenantConfig{
+		JWT: TenantJWT{Issuer: "brand-issuer"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, c.Name, tcfg.Name)
+	assert.Equal(t, c.Mail.From, tcfg.Mail.From)
+	assert.Equal(t, c.Providers, tcfg.Providers)
+	assert.Equal(t, c.JWT.Secret, tcfg.JWT.Secret)
+	assert.Equal(t, c.JWT.KeyID, tcfg.JWT.KeyID)
+	assert.Equal(t, "brand-issuer", tcfg.JWT.Issuer)
+	// bad settings
+	_, err = c.ForTenant(TenantConfig{SiteURL: "\n"})
+	assert.Error(t, err)
+	_, err = c.ForTenant(TenantConfig{RedirectURL: "\n"})
+	assert.Error(t, err)
+	_, err = c.ForTenant(TenantConfig{Mail: MailFormat{Link: "\n"}})
+	assert.Error(t, err)
+	_, err = c.ForTenant(TenantConfig{JWT: TenantJWT{Issuer: tcfg.OIDC.Issuer}})
+	assert.Error(t, err)
+	_, err = c.ForTenant(TenantConfig{JWT: TenantJWT{
+		PEM: PEM{PrivateKey: "missing.pem"},
+	}})
+	assert.Error(t, err)
+}
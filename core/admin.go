@@ -7,7 +7,10 @@ import (
 	"github.com/google/uuid"
 	"github.com/jrapoport/gothic/core/audit"
 	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/core/roles"
 	"github.com/jrapoport/gothic/core/users"
+	"github.com/jrapoport/gothic/hasher"
+	"github.com/jrapoport/gothic/models/rbac"
 	"github.com/jrapoport/gothic/models/types"
 	"github.com/jrapoport/gothic/models/user"
 	"github.com/jrapoport/gothic/store"
@@ -43,7 +46,7 @@ func (a *API) CreateUser(ctx context.Context, email, username, pw string, data t
 			return err
 		}
 		aid := ctx.AdminID()
-		role, err := a.validateAdmin(tx, aid)
+		role, err := a.validateAdmin(tx, aid, rbac.UsersCreate)
 		if err != nil {
 			return err
 		}
@@ -93,7 +96,7 @@ func (a *API) ChangeRole(ctx context.Context, userID uuid.UUID, role user.Role)
 	var u *user.User
 	err := a.conn.Transaction(func(tx *store.Connection) error {
 		aid := ctx.AdminID()
-		adminRole, err := a.validateAdmin(tx, aid)
+		adminRole, err := a.validateAdmin(tx, aid, rbac.RolesAssign)
 		if err != nil {
 			return err
 		}
@@ -152,7 +155,7 @@ func (a *API) PromoteUser(ctx context.Context, userID uuid.UUID) (*user.User, er
 	var u *user.User
 	err := a.conn.Transaction(func(tx *store.Connection) error {
 		aid := ctx.AdminID()
-		role, err := a.validateAdmin(tx, aid)
+		role, err := a.validateAdmin(tx, aid, rbac.RolesAssign)
 		if err != nil {
 			return err
 		}
@@ -194,7 +197,7 @@ func (a *API) UpdateUserMetadata(ctx context.Context, userID uuid.UUID, meta typ
 	a.log.Debugf("update user  %s metadata: %v", userID, meta)
 	var u *user.User
 	err := a.conn.Transaction(func(tx *store.Connection) (err error) {
-		role, err := a.validateAdmin(tx, ctx.AdminID())
+		role, err := a.validateAdmin(tx, ctx.AdminID(), rbac.UsersWrite)
 		if err != nil {
 			return err
 		}
@@ -239,7 +242,7 @@ func (a *API) DeleteUser(ctx context.Context, userID uuid.UUID, hard bool) error
 	}
 	a.log.Debugf("delete user: %s", userID)
 	err := a.conn.Transaction(func(tx *store.Connection) error {
-		role, err := a.validateAdmin(tx, ctx.AdminID())
+		role, err := a.validateAdmin(tx, ctx.AdminID(), rbac.UsersDelete)
 		if err != nil {
 			return err
 		}
@@ -264,12 +267,74 @@ func (a *API) DeleteUser(ctx context.Context, userID uuid.UUID, hard bool) error
 	return nil
 }
 
-// ValidateAdmin validates the user id as an admin and returns the role
-func (a *API) ValidateAdmin(aid uuid.UUID) (user.Role, error) {
-	return a.validateAdmin(a.conn, aid)
+// ImportUsers imports users with password hashes exported from another service.
+// Each user is imported separately so a bad row is reported in the result and
+// does not stop the import. Foreign hashes are upgraded on the first login.
+// NOTE: This API requires admin user permissions
+func (a *API) ImportUsers(ctx context.Context, imp *users.Import) (*users.ImportResult, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !ctx.IsAdmin() {
+		err := errors.New("admin user required")
+		return nil, a.logError(err)
+	}
+	if imp == nil || len(imp.Users) <= 0 {
+		err := errors.New("users required")
+		return nil, a.logError(err)
+	}
+	_, err := a.ValidateAdmin(ctx.AdminID(), rbac.UsersCreate)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	a.log.Debugf("import users: %d", len(imp.Users))
+	res := &users.ImportResult{}
+	for i, rec := range imp.Users {
+		u, err := a.importUser(ctx, rec, imp.Firebase)
+		if err != nil {
+			a.log.Warnf("import user %d failed: %v", i+1, err)
+		}
+		res.Add(i+1, rec.Email, u, err)
+	}
+	a.log.Debugf("imported users: %d (failed: %d)", res.Imported, res.Failed)
+	return res, nil
+}
+
+func (a *API) importUser(ctx context.Context, rec users.ImportRecord, fp *hasher.FirebaseParams) (*user.User, error) {
+	var u *user.User
+	err := a.conn.Transaction(func(tx *store.Connection) (err error) {
+		rec.Username, err = a.validateUsername(tx, rec.Username)
+		if err != nil {
+			err = fmt.Errorf("username: %w", err)
+			return err
+		}
+		rec.Data = a.useDefaultColor(rec.Data)
+		u, err = users.ImportUser(tx, a.Provider(), rec, fp)
+		if err != nil {
+			return err
+		}
+		alg, err := hasher.Identify(u.Password)
+		if err != nil {
+			return err
+		}
+		return audit.LogImported(ctx, tx, u.ID, string(alg))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
 }
 
-func (a *API) validateAdmin(tx *store.Connection, aid uuid.UUID) (user.Role, error) {
+// ValidateAdmin validates the user id as an admin with the permission
+// and returns the role.
+func (a *API) ValidateAdmin(aid uuid.UUID, perm rbac.Permission) (user.Role, error) {
+	return a.validateAdmin(a.conn, aid, perm)
+}
+
+// validateAdmin validates that the admin has the permission. An admin is a
+// user with the admin role, or a user with a custom role that grants the
+// permission. The super admin has all the permissions.
+func (a *API) validateAdmin(tx *store.Connection, aid uuid.UUID, perm rbac.Permission) (user.Role, error) {
 	if aid == user.SuperAdminID {
 		return user.RoleSuper, nil
 	}
@@ -278,8 +343,12 @@ func (a *API) validateAdmin(tx *store.Connection, aid uuid.UUID) (user.Role, err
 		err = fmt.Errorf("admin required: %w", err)
 		return user.InvalidRole, err
 	}
-	if !adm.IsAdmin() {
-		err = fmt.Errorf("admin required: %s", aid)
+	has, err := roles.HasPermission(tx, adm, perm)
+	if err != nil {
+		return user.InvalidRole, err
+	}
+	if !has {
+		err = fmt.Errorf("permission required: %s %s", aid, perm)
 		return user.InvalidRole, err
 	}
 	return adm.Role, nil
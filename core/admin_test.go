@@ -5,6 +5,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jrapoport/gothic/core/users"
+	"github.com/jrapoport/gothic/models/auditlog"
 	"github.com/jrapoport/gothic/models/types"
 	"github.com/jrapoport/gothic/models/types/key"
 	"github.com/jrapoport/gothic/models/user"
@@ -279,3 +280,66 @@ func TestAPI_DeleteUser(t *testing.T) {
 	err = a.DeleteUser(ctx, adm.ID, true)
 	assert.Error(t, err)
 }
+
+func TestAPI_ImportUsers(t *testing.T) {
+	t.Parallel()
+	const (
+		md5Hash    = "$1$ab$t00rsSdYwJXPEvSlIBoBQ0"
+		pbkdf2Hash = "pbkdf2_sha256$10000$seasalt42$lnv0/PWydD3t+BQPaeQWVQWFFhdDE+Ed38c19dlOXfQ="
+	)
+	a := loginAPI(t)
+	ctx := rootContext(a)
+	imp := &users.Import{
+		Users: []users.ImportRecord{
+			{Email: tutils.RandomEmail(), Hash: md5Hash, Confirmed: true},
+			{Email: "bad", Hash: md5Hash},
+			{Email: tutils.RandomEmail(), Hash: pbkdf2Hash, Confirmed: true},
+			{Email: tutils.RandomEmail(), Hash: "password"},
+		},
+	}
+	_, err := a.ImportUsers(nil, imp)
+	assert.Error(t, err)
+	_, err = a.ImportUsers(ctx, nil)
+	assert.Error(t, err)
+	_, err = a.ImportUsers(ctx, &users.Import{})
+	assert.Error(t, err)
+	bad := testContext(a)
+	bad.SetAdminID(uuid.New())
+	_, err = a.ImportUsers(bad, imp)
+	assert.Error(t, err)
+	res, err := a.ImportUsers(ctx, imp)
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Imported)
+	assert.Equal(t, 2, res.Failed)
+	require.Len(t, res.Results, len(imp.Users))
+	for i, r := range res.Results {
+		assert.Equal(t, i+1, r.Row)
+		assert.Equal(t, imp.Users[i].Email, r.Email)
+	}
+	assert.Empty(t, res.Results[1].UserID)
+	assert.NotEmpty(t, res.Results[1].Error)
+	assert.Empty(t, res.Results[3].UserID)
+	assert.NotEmpty(t, res.Results[3].Error)
+	for _, i := range []int{0, 2} {
+		r := res.Results[i]
+		assert.Empty(t, r.Error)
+		uid, err := uuid.Parse(r.UserID)
+		require.NoError(t, err)
+		hasAuditEntry(t, a, auditlog.Imported, uid)
+		u, err := a.GetUser(uid)
+		require.NoError(t, err)
+		assert.True(t, u.IsConfirmed())
+		assert.True(t, u.NeedsRehash())
+		// the imported hash is upgraded on login
+		u, err = a.Login(testContext(a), r.Email, testPass)
+		require.NoError(t, err)
+		u, err = a.GetUser(u.ID)
+		require.NoError(t, err)
+		assert.False(t, u.NeedsRehash())
+	}
+	// already imported
+	res, err = a.ImportUsers(ctx, &users.Import{Users: imp.Users[:1]})
+	require.NoError(t, err)
+	assert.Equal(t, 0, res.Imported)
+	assert.Equal(t, 1, res.Failed)
+}
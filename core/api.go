@@ -1,24 +1,36 @@
 package core
 
 import (
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/breach"
 	"github.com/jrapoport/gothic/config"
 	"github.com/jrapoport/gothic/core/audit"
 	"github.com/jrapoport/gothic/core/auth"
 	"github.com/jrapoport/gothic/core/events"
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/hasher"
 	"github.com/jrapoport/gothic/log"
 	"github.com/jrapoport/gothic/mail"
+	"github.com/jrapoport/gothic/models/tenant"
 	"github.com/jrapoport/gothic/models/types/provider"
+	"github.com/jrapoport/gothic/sms"
 	"github.com/jrapoport/gothic/store"
 )
 
 // API is the main API
 type API struct {
-	config *config.Config
-	conn   *store.Connection
-	evt    *events.Dispatch
-	mail   *mail.Client
-	ext    *auth.Providers
-	log    log.Logger
+	config    *config.Config
+	conn      *store.Connection
+	evt       *events.Dispatch
+	mail      *mail.Client
+	sms       sms.SMSSender
+	ext       *auth.Providers
+	log       log.Logger
+	breached  *breach.Filter
+	revoked   *tokens.Denylist
+	reminders chan struct{}
+	tenants   *tenancy
+	tenant    *tenant.Tenant
 }
 
 // NewAPI creates a new core API with a configured storage connection
@@ -42,6 +54,15 @@ func (a *API) LoadConfig(c *config.Config) (err error) {
 	// set the log first so we can log other errors appropriately
 	a.log = l.WithName("api-" + a.config.Env())
 	a.evt = events.NewDispatch(c.Name, l)
+	h, err := hasher.New(c.Hash)
+	if err != nil {
+		return a.logError(err)
+	}
+	hasher.Use(h)
+	a.breached, err = breach.Load(c.Validation.BreachedPasswords)
+	if err != nil {
+		return a.logError(err)
+	}
 	a.conn, err = store.Dial(c, a.log)
 	if err != nil {
 		return a.logError(err)
@@ -50,6 +71,19 @@ func (a *API) LoadConfig(c *config.Config) (err error) {
 	if err != nil {
 		return a.logError(err)
 	}
+	err = tokens.DeleteSwappedRefreshTokens(a.conn, c.Refresh)
+	if err != nil {
+		return a.logError(err)
+	}
+	if c.Tenants.Enabled {
+		// the data of the api belongs to the default tenant
+		a.conn = a.conn.Tenant(uuid.Nil)
+		a.tenants = newTenancy()
+	}
+	a.revoked, err = tokens.LoadDenylist(a.conn)
+	if err != nil {
+		return a.logError(err)
+	}
 	err = a.CreateSuperAdmin()
 	if err != nil {
 		return a.logError(err)
@@ -58,6 +92,11 @@ func (a *API) LoadConfig(c *config.Config) (err error) {
 	if err != nil {
 		return a.logError(err)
 	}
+	err = a.OpenSMS()
+	if err != nil {
+		return a.logError(err)
+	}
+	a.startPasswordReminders()
 	a.ext = auth.NewProviders()
 	err = a.ext.LoadProviders(a.config)
 	if err != nil {
@@ -79,6 +118,8 @@ func (a *API) Provider() provider.Name {
 
 // Shutdown shuts down the api service
 func (a *API) Shutdown() error {
+	a.closeTenants()
+	a.stopPasswordReminders()
 	a.CloseMail()
 	a.closeDispatch()
 	err := audit.LogShutdown(a.conn, a.config.Service.Name)
@@ -1,6 +1,10 @@
 package core
 
 import (
+	"crypto/sha1"
+	"fmt"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -133,3 +137,40 @@ func promoteUser(t *testing.T, a *API, u *user.User) *user.User {
 	require.NoError(t, err)
 	return u
 }
+
+func TestNewAPI_Hash(t *testing.T) {
+	t.Parallel()
+	c := tconf.TempDB(t)
+	c.Hash.Algorithm = "md5"
+	_, err := NewAPI(c)
+	assert.Error(t, err)
+	c.Hash.Algorithm = "bcrypt"
+	c.Hash.Bcrypt.Cost = 100
+	_, err = NewAPI(c)
+	assert.Error(t, err)
+}
+
+// breachedDump writes a breached password dump for the passwords.
+func breachedDump(t *testing.T, pws ...string) string {
+	var dump string
+	for _, pw := range pws {
+		dump += fmt.Sprintf("%X:1\n", sha1.Sum([]byte(pw)))
+	}
+	path := filepath.Join(t.TempDir(), "pwned.txt")
+	err := os.WriteFile(path, []byte(dump), 0600)
+	require.NoError(t, err)
+	return path
+}
+
+func TestNewAPI_BreachedPasswords(t *testing.T) {
+	t.Parallel()
+	const breached = "password"
+	c := tconf.TempDB(t)
+	c.Validation.BreachedPasswords = filepath.Join(t.TempDir(), "missing.txt")
+	_, err := NewAPI(c)
+	assert.Error(t, err)
+	c.Validation.BreachedPasswords = breachedDump(t, breached)
+	a := configuredAPI(t, c)
+	assert.True(t, a.breached.Contains(breached))
+	assert.False(t, a.breached.Contains(testPass))
+}
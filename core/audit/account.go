@@ -1,6 +1,8 @@
 package audit
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/jrapoport/gothic/core/context"
 	"github.com/jrapoport/gothic/models/auditlog"
@@ -31,6 +33,18 @@ func LogConfirmSent(ctx context.Context, conn *store.Connection, t token.Token)
 	return err
 }
 
+// LogMagicLinkSent logs a sent magic link token.
+func LogMagicLinkSent(ctx context.Context, conn *store.Connection, t token.Token) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.MagicLinkSent, t.IssuedTo(), logToken(t))
+	return err
+}
+
+// LogPhoneCodeSent logs a sent phone (sms) code.
+func LogPhoneCodeSent(ctx context.Context, conn *store.Connection, t token.Token) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.PhoneCodeSent, t.IssuedTo(), logToken(t))
+	return err
+}
+
 // LogConfirmed logs a confirmed user.
 func LogConfirmed(ctx context.Context, conn *store.Connection, userID uuid.UUID) error {
 	_, err := CreateLogEntry(ctx, conn, auditlog.Confirmed, userID, nil)
@@ -43,8 +57,70 @@ func LogBanned(ctx context.Context, conn *store.Connection, userID uuid.UUID) er
 	return err
 }
 
+// LogLocked logs a locked user.
+func LogLocked(ctx context.Context, conn *store.Connection, userID uuid.UUID, until time.Time) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.Locked, userID, types.Map{
+		key.LockedUntil: until.UTC().Format(time.RFC3339),
+	})
+	return err
+}
+
+// LogUnlocked logs an unlocked user.
+func LogUnlocked(ctx context.Context, conn *store.Connection, userID uuid.UUID) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.Unlocked, userID, nil)
+	return err
+}
+
+// LogPasswordForced logs a user that must change their password.
+func LogPasswordForced(ctx context.Context, conn *store.Connection, userID uuid.UUID) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.PasswordForced, userID, nil)
+	return err
+}
+
 // LogDeleted logs a deleted user.
 func LogDeleted(ctx context.Context, conn *store.Connection, userID uuid.UUID) error {
 	_, err := CreateLogEntry(ctx, conn, auditlog.Deleted, userID, nil)
 	return err
 }
+
+// LogImported logs an imported user.
+func LogImported(ctx context.Context, conn *store.Connection, userID uuid.UUID, alg string) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.Imported, userID, types.Map{
+		key.Algorithm: alg,
+	})
+	return err
+}
+
+// LogMFAEnrolled logs a confirmed mfa enrollment.
+func LogMFAEnrolled(ctx context.Context, conn *store.Connection, userID uuid.UUID) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.MFAEnrolled, userID, nil)
+	return err
+}
+
+// LogMFAVerified logs a verified mfa challenge.
+func LogMFAVerified(ctx context.Context, conn *store.Connection, userID uuid.UUID) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.MFAVerified, userID, nil)
+	return err
+}
+
+// LogMFADisabled logs a disabled mfa enrollment.
+func LogMFADisabled(ctx context.Context, conn *store.Connection, userID uuid.UUID) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.MFADisabled, userID, nil)
+	return err
+}
+
+// LogWebAuthnAdded logs a registered webauthn credential.
+func LogWebAuthnAdded(ctx context.Context, conn *store.Connection, userID uuid.UUID, credentialID string) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.WebAuthnAdded, userID, types.Map{
+		key.ID: credentialID,
+	})
+	return err
+}
+
+// LogWebAuthnRemoved logs a deleted webauthn credential.
+func LogWebAuthnRemoved(ctx context.Context, conn *store.Connection, userID uuid.UUID, credentialID string) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.WebAuthnRemoved, userID, types.Map{
+		key.ID: credentialID,
+	})
+	return err
+}
@@ -51,6 +51,30 @@ func TestLogConfirmationSent(t *testing.T) {
 		})
 }
 
+func TestLogMagicLinkSent(t *testing.T) {
+	t.Parallel()
+	uid := uuid.New()
+	tk := token.NewMagicLinkToken(uid, time.Second)
+	tk.ID = 100
+	tk.CreatedAt = time.Now().UTC()
+	testLogEntry(t, auditlog.MagicLinkSent, uid, logToken(tk),
+		func(ctx context.Context, conn *store.Connection, uid uuid.UUID, _ types.Map) error {
+			return LogMagicLinkSent(ctx, conn, tk)
+		})
+}
+
+func TestLogPhoneCodeSent(t *testing.T) {
+	t.Parallel()
+	uid := uuid.New()
+	tk := token.NewPhoneToken(uid, token.PhoneChange, "+15555550100", time.Second)
+	tk.ID = 100
+	tk.CreatedAt = time.Now().UTC()
+	testLogEntry(t, auditlog.PhoneCodeSent, uid, logToken(tk),
+		func(ctx context.Context, conn *store.Connection, uid uuid.UUID, _ types.Map) error {
+			return LogPhoneCodeSent(ctx, conn, tk)
+		})
+}
+
 func TestLogConfirmed(t *testing.T) {
 	t.Parallel()
 	testLogEntry(t, auditlog.Confirmed, uuid.New(), nil,
@@ -67,6 +91,34 @@ func TestLogBanned(t *testing.T) {
 		})
 }
 
+func TestLogLocked(t *testing.T) {
+	t.Parallel()
+	until := time.Now().Add(time.Hour)
+	testLogEntry(t, auditlog.Locked, uuid.New(),
+		types.Map{
+			key.LockedUntil: until.UTC().Format(time.RFC3339),
+		},
+		func(ctx context.Context, conn *store.Connection, uid uuid.UUID, _ types.Map) error {
+			return LogLocked(ctx, conn, uid, until)
+		})
+}
+
+func TestLogUnlocked(t *testing.T) {
+	t.Parallel()
+	testLogEntry(t, auditlog.Unlocked, uuid.New(), nil,
+		func(ctx context.Context, conn *store.Connection, uid uuid.UUID, _ types.Map) error {
+			return LogUnlocked(ctx, conn, uid)
+		})
+}
+
+func TestLogPasswordForced(t *testing.T) {
+	t.Parallel()
+	testLogEntry(t, auditlog.PasswordForced, uuid.New(), nil,
+		func(ctx context.Context, conn *store.Connection, uid uuid.UUID, _ types.Map) error {
+			return LogPasswordForced(ctx, conn, uid)
+		})
+}
+
 func TestLogDeleted(t *testing.T) {
 	t.Parallel()
 	testLogEntry(t, auditlog.Deleted, uuid.New(), nil,
@@ -74,3 +126,63 @@ func TestLogDeleted(t *testing.T) {
 			return LogDeleted(ctx, conn, uid)
 		})
 }
+
+func TestLogImported(t *testing.T) {
+	t.Parallel()
+	const alg = "bcrypt"
+	testLogEntry(t, auditlog.Imported, uuid.New(),
+		types.Map{
+			key.Algorithm: alg,
+		},
+		func(ctx context.Context, conn *store.Connection, uid uuid.UUID, _ types.Map) error {
+			return LogImported(ctx, conn, uid, alg)
+		})
+}
+
+func TestLogMFAEnrolled(t *testing.T) {
+	t.Parallel()
+	testLogEntry(t, auditlog.MFAEnrolled, uuid.New(), nil,
+		func(ctx context.Context, conn *store.Connection, uid uuid.UUID, _ types.Map) error {
+			return LogMFAEnrolled(ctx, conn, uid)
+		})
+}
+
+func TestLogMFAVerified(t *testing.T) {
+	t.Parallel()
+	testLogEntry(t, auditlog.MFAVerified, uuid.New(), nil,
+		func(ctx context.Context, conn *store.Connection, uid uuid.UUID, _ types.Map) error {
+			return LogMFAVerified(ctx, conn, uid)
+		})
+}
+
+func TestLogMFADisabled(t *testing.T) {
+	t.Parallel()
+	testLogEntry(t, auditlog.MFADisabled, uuid.New(), nil,
+		func(ctx context.Context, conn *store.Connection, uid uuid.UUID, _ types.Map) error {
+			return LogMFADisabled(ctx, conn, uid)
+		})
+}
+
+func TestLogWebAuthnAdded(t *testing.T) {
+	t.Parallel()
+	const credentialID = "credential-id"
+	testLogEntry(t, auditlog.WebAuthnAdded, uuid.New(),
+		types.Map{
+			key.ID: credentialID,
+		},
+		func(ctx context.Context, conn *store.Connection, uid uuid.UUID, _ types.Map) error {
+			return LogWebAuthnAdded(ctx, conn, uid, credentialID)
+		})
+}
+
+func TestLogWebAuthnRemoved(t *testing.T) {
+	t.Parallel()
+	const credentialID = "credential-id"
+	testLogEntry(t, auditlog.WebAuthnRemoved, uuid.New(),
+		types.Map{
+			key.ID: credentialID,
+		},
+		func(ctx context.Context, conn *store.Connection, uid uuid.UUID, _ types.Map) error {
+			return LogWebAuthnRemoved(ctx, conn, uid, credentialID)
+		})
+}
@@ -26,6 +26,10 @@ func CreateLogEntry(ctx context.Context, conn *store.Connection,
 			ctx.Provider() != provider.Unknown {
 			fields[key.Provider] = ctx.Provider()
 		}
+		if fields[key.OrgID] == nil &&
+			ctx.OrgID() != uuid.Nil {
+			fields[key.OrgID] = ctx.OrgID().String()
+		}
 		if fields[key.AdminID] == nil &&
 			ctx.AdminID() != uuid.Nil {
 			fields[key.AdminID] = ctx.AdminID().String()
@@ -33,6 +37,11 @@ func CreateLogEntry(ctx context.Context, conn *store.Connection,
 			ctx.UserID() != uuid.Nil {
 			fields[key.UserID] = ctx.UserID().String()
 		}
+		// changes made while impersonating a user are made by the admin
+		if fields[key.AdminID] == nil &&
+			ctx.ImpersonatorID() != uuid.Nil {
+			fields[key.AdminID] = ctx.ImpersonatorID().String()
+		}
 	}
 	// conn.Logger.LogMode(0).Info(ctx, "%s %s: %s: %v", t, action, userID, fields)
 	le := auditlog.NewAuditLog(t, action, userID, fields)
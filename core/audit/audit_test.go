@@ -113,6 +113,19 @@ func TestCreateLogEntry(t *testing.T) {
 	}
 	assert.Equal(t, fields, le.Fields)
 	assert.Nil(t, le.Fields[key.AdminID])
+	// entries are tagged with the active organization
+	oid := uuid.New()
+	ctx.SetOrgID(oid)
+	le, err = CreateLogEntry(ctx, conn, auditlog.Login, testUID, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, oid.String(), le.Fields[key.OrgID])
+	// entries made while impersonating are tagged with the user and admin
+	aid := uuid.New()
+	ctx.SetImpersonatorID(aid)
+	le, err = CreateLogEntry(ctx, conn, auditlog.Login, testUID, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, testUID.String(), le.Fields[key.UserID])
+	assert.Equal(t, aid.String(), le.Fields[key.AdminID])
 	for _, test := range testCases() {
 		testCreate(t, conn,
 			createTest{
@@ -0,0 +1,91 @@
+package audit
+
+import (
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/models/auditlog"
+	"github.com/jrapoport/gothic/models/client"
+	"github.com/jrapoport/gothic/models/code"
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+)
+
+// LogClientCreated log oauth client created
+func LogClientCreated(ctx context.Context, conn *store.Connection, c *client.Client) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.ClientCreated, user.SystemID, logClient(c))
+	return err
+}
+
+// LogClientDeleted log oauth client deleted
+func LogClientDeleted(ctx context.Context, conn *store.Connection, c *client.Client) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.ClientDeleted, user.SystemID, logClient(c))
+	return err
+}
+
+// LogConsentGranted log user consent granted to an oauth client
+func LogConsentGranted(ctx context.Context, conn *store.Connection, c *client.Consent) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.ConsentGranted, c.UserID, logConsent(c))
+	return err
+}
+
+// LogConsentRevoked log user consent revoked from an oauth client
+func LogConsentRevoked(ctx context.Context, conn *store.Connection, c *client.Consent) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.ConsentRevoked, c.UserID, logConsent(c))
+	return err
+}
+
+// LogDeviceApproved log user approved a device for an oauth client
+func LogDeviceApproved(ctx context.Context, conn *store.Connection, dc *code.DeviceCode) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.DeviceApproved, dc.UserID, logDevice(dc))
+	return err
+}
+
+// LogServiceCreated log service client created
+func LogServiceCreated(ctx context.Context, conn *store.Connection, s *client.Service) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.ServiceCreated, s.OwnerID, logService(s))
+	return err
+}
+
+// LogServiceDeleted log service client deleted
+func LogServiceDeleted(ctx context.Context, conn *store.Connection, s *client.Service) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.ServiceDeleted, s.OwnerID, logService(s))
+	return err
+}
+
+// LogServiceGranted log token granted to a service client
+func LogServiceGranted(ctx context.Context, conn *store.Connection, s *client.Service, tokenID, scope string) error {
+	fields := logService(s)
+	fields[key.TokenID] = tokenID
+	fields[key.Scope] = scope
+	_, err := CreateLogEntry(ctx, conn, auditlog.ServiceGranted, s.OwnerID, fields)
+	return err
+}
+
+func logClient(c *client.Client) types.Map {
+	return types.Map{
+		key.ClientID: c.ClientID,
+		key.Name:     c.Name,
+	}
+}
+
+func logConsent(c *client.Consent) types.Map {
+	return types.Map{
+		key.ClientID: c.ClientID,
+		key.Scope:    c.Scopes,
+	}
+}
+
+func logDevice(dc *code.DeviceCode) types.Map {
+	return types.Map{
+		key.ClientID: dc.ClientID,
+		key.Scope:    dc.Scope,
+	}
+}
+
+func logService(s *client.Service) types.Map {
+	return types.Map{
+		key.ClientID: s.ClientID,
+		key.Name:     s.Name,
+	}
+}
@@ -0,0 +1,95 @@
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/models/auditlog"
+	"github.com/jrapoport/gothic/models/client"
+	"github.com/jrapoport/gothic/models/code"
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+)
+
+func TestLogClientCreated(t *testing.T) {
+	t.Parallel()
+	c := client.NewClient("test", []string{"https://example.com/callback"}, nil, nil)
+	testLogEntry(t, auditlog.ClientCreated, user.SystemID, logClient(c),
+		func(ctx context.Context, conn *store.Connection, _ uuid.UUID, _ types.Map) error {
+			return LogClientCreated(ctx, conn, c)
+		})
+}
+
+func TestLogClientDeleted(t *testing.T) {
+	t.Parallel()
+	c := client.NewClient("test", []string{"https://example.com/callback"}, nil, nil)
+	testLogEntry(t, auditlog.ClientDeleted, user.SystemID, logClient(c),
+		func(ctx context.Context, conn *store.Connection, _ uuid.UUID, _ types.Map) error {
+			return LogClientDeleted(ctx, conn, c)
+		})
+}
+
+func TestLogConsentGranted(t *testing.T) {
+	t.Parallel()
+	uid := uuid.New()
+	c := client.NewConsent(uid, uuid.New().String(), []string{"openid", "email"})
+	testLogEntry(t, auditlog.ConsentGranted, uid, logConsent(c),
+		func(ctx context.Context, conn *store.Connection, _ uuid.UUID, _ types.Map) error {
+			return LogConsentGranted(ctx, conn, c)
+		})
+}
+
+func TestLogConsentRevoked(t *testing.T) {
+	t.Parallel()
+	uid := uuid.New()
+	c := client.NewConsent(uid, uuid.New().String(), []string{"openid"})
+	testLogEntry(t, auditlog.ConsentRevoked, uid, logConsent(c),
+		func(ctx context.Context, conn *store.Connection, _ uuid.UUID, _ types.Map) error {
+			return LogConsentRevoked(ctx, conn, c)
+		})
+}
+
+func TestLogDeviceApproved(t *testing.T) {
+	t.Parallel()
+	dc := code.NewDeviceCode(uuid.New().String(), "openid", time.Minute, time.Second)
+	dc.UserID = uuid.New()
+	testLogEntry(t, auditlog.DeviceApproved, dc.UserID, logDevice(dc),
+		func(ctx context.Context, conn *store.Connection, _ uuid.UUID, _ types.Map) error {
+			return LogDeviceApproved(ctx, conn, dc)
+		})
+}
+
+func TestLogServiceCreated(t *testing.T) {
+	t.Parallel()
+	s := client.NewService("test", []string{"read"}, uuid.New(), nil)
+	testLogEntry(t, auditlog.ServiceCreated, s.OwnerID, logService(s),
+		func(ctx context.Context, conn *store.Connection, _ uuid.UUID, _ types.Map) error {
+			return LogServiceCreated(ctx, conn, s)
+		})
+}
+
+func TestLogServiceDeleted(t *testing.T) {
+	t.Parallel()
+	s := client.NewService("test", []string{"read"}, uuid.New(), nil)
+	testLogEntry(t, auditlog.ServiceDeleted, s.OwnerID, logService(s),
+		func(ctx context.Context, conn *store.Connection, _ uuid.UUID, _ types.Map) error {
+			return LogServiceDeleted(ctx, conn, s)
+		})
+}
+
+func TestLogServiceGranted(t *testing.T) {
+	t.Parallel()
+	s := client.NewService("test", []string{"read"}, uuid.New(), nil)
+	tid := uuid.New().String()
+	fields := logService(s)
+	fields[key.TokenID] = tid
+	fields[key.Scope] = "read"
+	testLogEntry(t, auditlog.ServiceGranted, s.OwnerID, fields,
+		func(ctx context.Context, conn *store.Connection, _ uuid.UUID, _ types.Map) error {
+			return LogServiceGranted(ctx, conn, s, tid, "read")
+		})
+}
@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/models/auditlog"
+	"github.com/jrapoport/gothic/models/org"
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/store"
+)
+
+// LogOrgCreated log organization created
+func LogOrgCreated(ctx context.Context, conn *store.Connection, userID uuid.UUID, o *org.Organization) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.OrgCreated, userID, logOrg(o))
+	return err
+}
+
+// LogOrgUpdated log organization updated
+func LogOrgUpdated(ctx context.Context, conn *store.Connection, userID uuid.UUID, o *org.Organization) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.OrgUpdated, userID, logOrg(o))
+	return err
+}
+
+// LogOrgDeleted log organization deleted
+func LogOrgDeleted(ctx context.Context, conn *store.Connection, userID uuid.UUID, o *org.Organization) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.OrgDeleted, userID, logOrg(o))
+	return err
+}
+
+// LogOrgInviteSent log organization invite sent
+func LogOrgInviteSent(ctx context.Context, conn *store.Connection, inv *org.Invite) error {
+	fields := types.Map{
+		key.OrgID: inv.OrgID.String(),
+		key.Email: inv.Email,
+		key.Role:  inv.Role.String(),
+	}
+	_, err := CreateLogEntry(ctx, conn, auditlog.OrgInviteSent, inv.UserID, fields)
+	return err
+}
+
+// LogOrgJoined log user joined an organization
+func LogOrgJoined(ctx context.Context, conn *store.Connection, m *org.Member) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.OrgJoined, m.UserID, logMember(m))
+	return err
+}
+
+// LogOrgMemberRole log organization member role changed
+func LogOrgMemberRole(ctx context.Context, conn *store.Connection, m *org.Member) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.OrgMemberRole, m.UserID, logMember(m))
+	return err
+}
+
+// LogOrgMemberRemoved log organization member removed
+func LogOrgMemberRemoved(ctx context.Context, conn *store.Connection, m *org.Member) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.OrgMemberRemoved, m.UserID, logMember(m))
+	return err
+}
+
+// LogOrgSwitched log user switched the active organization of a session
+func LogOrgSwitched(ctx context.Context, conn *store.Connection, userID, orgID uuid.UUID) error {
+	fields := types.Map{
+		key.OrgID: orgID.String(),
+	}
+	_, err := CreateLogEntry(ctx, conn, auditlog.OrgSwitched, userID, fields)
+	return err
+}
+
+func logOrg(o *org.Organization) types.Map {
+	return types.Map{
+		key.OrgID: o.ID.String(),
+		key.Name:  o.Name,
+	}
+}
+
+func logMember(m *org.Member) types.Map {
+	return types.Map{
+		key.OrgID: m.OrgID.String(),
+		key.Role:  m.Role.String(),
+	}
+}
@@ -0,0 +1,100 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/models/auditlog"
+	"github.com/jrapoport/gothic/models/org"
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/store"
+)
+
+func testOrg() *org.Organization {
+	return org.NewOrganization("acme", nil)
+}
+
+func testMember() *org.Member {
+	return org.NewMember(uuid.New(), uuid.New(), org.RoleAdmin)
+}
+
+func TestLogOrgCreated(t *testing.T) {
+	t.Parallel()
+	o := testOrg()
+	testLogEntry(t, auditlog.OrgCreated, uuid.New(), logOrg(o),
+		func(ctx context.Context, conn *store.Connection, uid uuid.UUID, _ types.Map) error {
+			return LogOrgCreated(ctx, conn, uid, o)
+		})
+}
+
+func TestLogOrgUpdated(t *testing.T) {
+	t.Parallel()
+	o := testOrg()
+	testLogEntry(t, auditlog.OrgUpdated, uuid.New(), logOrg(o),
+		func(ctx context.Context, conn *store.Connection, uid uuid.UUID, _ types.Map) error {
+			return LogOrgUpdated(ctx, conn, uid, o)
+		})
+}
+
+func TestLogOrgDeleted(t *testing.T) {
+	t.Parallel()
+	o := testOrg()
+	testLogEntry(t, auditlog.OrgDeleted, uuid.New(), logOrg(o),
+		func(ctx context.Context, conn *store.Connection, uid uuid.UUID, _ types.Map) error {
+			return LogOrgDeleted(ctx, conn, uid, o)
+		})
+}
+
+func TestLogOrgInviteSent(t *testing.T) {
+	t.Parallel()
+	uid := uuid.New()
+	inv := org.NewInvite(uuid.New(), uid, "test@example.com", org.RoleMember, 0)
+	fields := types.Map{
+		key.OrgID: inv.OrgID.String(),
+		key.Email: inv.Email,
+		key.Role:  inv.Role.String(),
+	}
+	testLogEntry(t, auditlog.OrgInviteSent, uid, fields,
+		func(ctx context.Context, conn *store.Connection, _ uuid.UUID, _ types.Map) error {
+			return LogOrgInviteSent(ctx, conn, inv)
+		})
+}
+
+func TestLogOrgJoined(t *testing.T) {
+	t.Parallel()
+	m := testMember()
+	testLogEntry(t, auditlog.OrgJoined, m.UserID, logMember(m),
+		func(ctx context.Context, conn *store.Connection, _ uuid.UUID, _ types.Map) error {
+			return LogOrgJoined(ctx, conn, m)
+		})
+}
+
+func TestLogOrgMemberRole(t *testing.T) {
+	t.Parallel()
+	m := testMember()
+	testLogEntry(t, auditlog.OrgMemberRole, m.UserID, logMember(m),
+		func(ctx context.Context, conn *store.Connection, _ uuid.UUID, _ types.Map) error {
+			return LogOrgMemberRole(ctx, conn, m)
+		})
+}
+
+func TestLogOrgMemberRemoved(t *testing.T) {
+	t.Parallel()
+	m := testMember()
+	testLogEntry(t, auditlog.OrgMemberRemoved, m.UserID, logMember(m),
+		func(ctx context.Context, conn *store.Connection, _ uuid.UUID, _ types.Map) error {
+			return LogOrgMemberRemoved(ctx, conn, m)
+		})
+}
+
+func TestLogOrgSwitched(t *testing.T) {
+	t.Parallel()
+	oid := uuid.New()
+	fields := types.Map{key.OrgID: oid.String()}
+	testLogEntry(t, auditlog.OrgSwitched, uuid.New(), fields,
+		func(ctx context.Context, conn *store.Connection, uid uuid.UUID, _ types.Map) error {
+			return LogOrgSwitched(ctx, conn, uid, oid)
+		})
+}
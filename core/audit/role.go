@@ -0,0 +1,49 @@
+package audit
+
+import (
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/models/auditlog"
+	"github.com/jrapoport/gothic/models/rbac"
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+)
+
+// LogRoleCreated log custom role created
+func LogRoleCreated(ctx context.Context, conn *store.Connection, r *rbac.Role) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.RoleCreated, user.SystemID, logRole(r))
+	return err
+}
+
+// LogRoleUpdated log custom role updated
+func LogRoleUpdated(ctx context.Context, conn *store.Connection, r *rbac.Role) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.RoleUpdated, user.SystemID, logRole(r))
+	return err
+}
+
+// LogRoleDeleted log custom role deleted
+func LogRoleDeleted(ctx context.Context, conn *store.Connection, r *rbac.Role) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.RoleDeleted, user.SystemID, logRole(r))
+	return err
+}
+
+// LogRoleAssigned log custom role assigned to a user
+func LogRoleAssigned(ctx context.Context, conn *store.Connection, userID uuid.UUID, r *rbac.Role) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.RoleAssigned, userID, logRole(r))
+	return err
+}
+
+// LogRoleUnassigned log custom role unassigned from a user
+func LogRoleUnassigned(ctx context.Context, conn *store.Connection, userID uuid.UUID, r *rbac.Role) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.RoleUnassigned, userID, logRole(r))
+	return err
+}
+
+func logRole(r *rbac.Role) types.Map {
+	return types.Map{
+		key.Name:        r.Name,
+		key.Permissions: r.Permissions,
+	}
+}
@@ -0,0 +1,62 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/models/auditlog"
+	"github.com/jrapoport/gothic/models/rbac"
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+)
+
+func testRole() *rbac.Role {
+	return rbac.NewRole("support", "", []rbac.Permission{rbac.UsersRead})
+}
+
+func TestLogRoleCreated(t *testing.T) {
+	t.Parallel()
+	r := testRole()
+	testLogEntry(t, auditlog.RoleCreated, user.SystemID, logRole(r),
+		func(ctx context.Context, conn *store.Connection, _ uuid.UUID, _ types.Map) error {
+			return LogRoleCreated(ctx, conn, r)
+		})
+}
+
+func TestLogRoleUpdated(t *testing.T) {
+	t.Parallel()
+	r := testRole()
+	testLogEntry(t, auditlog.RoleUpdated, user.SystemID, logRole(r),
+		func(ctx context.Context, conn *store.Connection, _ uuid.UUID, _ types.Map) error {
+			return LogRoleUpdated(ctx, conn, r)
+		})
+}
+
+func TestLogRoleDeleted(t *testing.T) {
+	t.Parallel()
+	r := testRole()
+	testLogEntry(t, auditlog.RoleDeleted, user.SystemID, logRole(r),
+		func(ctx context.Context, conn *store.Connection, _ uuid.UUID, _ types.Map) error {
+			return LogRoleDeleted(ctx, conn, r)
+		})
+}
+
+func TestLogRoleAssigned(t *testing.T) {
+	t.Parallel()
+	r := testRole()
+	testLogEntry(t, auditlog.RoleAssigned, uuid.New(), logRole(r),
+		func(ctx context.Context, conn *store.Connection, uid uuid.UUID, _ types.Map) error {
+			return LogRoleAssigned(ctx, conn, uid, r)
+		})
+}
+
+func TestLogRoleUnassigned(t *testing.T) {
+	t.Parallel()
+	r := testRole()
+	testLogEntry(t, auditlog.RoleUnassigned, uuid.New(), logRole(r),
+		func(ctx context.Context, conn *store.Connection, uid uuid.UUID, _ types.Map) error {
+			return LogRoleUnassigned(ctx, conn, uid, r)
+		})
+}
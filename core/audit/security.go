@@ -0,0 +1,35 @@
+package audit
+
+import (
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/models/auditlog"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/store"
+)
+
+// LogTokenReused log refresh token reused
+func LogTokenReused(ctx context.Context, conn *store.Connection, rt *token.RefreshToken) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.TokenReused, rt.IssuedTo(), logSession(rt))
+	return err
+}
+
+// LogImpersonated log user impersonated
+func LogImpersonated(ctx context.Context, conn *store.Connection, t token.Token) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.Impersonated, t.IssuedTo(), logToken(t))
+	return err
+}
+
+// LogImpersonation log a request made while impersonating a user
+func LogImpersonation(ctx context.Context, conn *store.Connection, userID uuid.UUID, method, path string) error {
+	fields := types.Map{
+		key.Method: method,
+	}
+	if path != "" {
+		fields[key.Path] = path
+	}
+	_, err := CreateLogEntry(ctx, conn, auditlog.Impersonation, userID, fields)
+	return err
+}
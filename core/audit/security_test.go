@@ -0,0 +1,49 @@
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/models/auditlog"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/store"
+)
+
+func TestLogTokenReused(t *testing.T) {
+	t.Parallel()
+	uid := uuid.New()
+	tk := token.NewSessionRefreshToken(uid, token.NewSession("agent", "127.0.0.1", ""))
+	tk.ID = 100
+	tk.CreatedAt = time.Now().UTC()
+	testLogEntry(t, auditlog.TokenReused, uid, logSession(tk),
+		func(ctx context.Context, conn *store.Connection, uid uuid.UUID, _ types.Map) error {
+			return LogTokenReused(ctx, conn, tk)
+		})
+}
+
+func TestLogImpersonated(t *testing.T) {
+	t.Parallel()
+	uid := uuid.New()
+	tk := token.NewAccessToken(uuid.New().String(), token.InfiniteUse, time.Hour)
+	tk.UserID = uid
+	tk.CreatedAt = time.Now().UTC()
+	testLogEntry(t, auditlog.Impersonated, uid, logToken(tk),
+		func(ctx context.Context, conn *store.Connection, uid uuid.UUID, _ types.Map) error {
+			return LogImpersonated(ctx, conn, tk)
+		})
+}
+
+func TestLogImpersonation(t *testing.T) {
+	t.Parallel()
+	uid := uuid.New()
+	testLogEntry(t, auditlog.Impersonation, uid, types.Map{
+		key.Method: "GET",
+		key.Path:   "/user",
+	}, func(ctx context.Context, conn *store.Connection, uid uuid.UUID, _ types.Map) error {
+		return LogImpersonation(ctx, conn, uid, "GET", "/user")
+	})
+}
@@ -0,0 +1,37 @@
+package audit
+
+import (
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/models/auditlog"
+	"github.com/jrapoport/gothic/models/tenant"
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+)
+
+// LogTenantCreated log tenant created
+func LogTenantCreated(ctx context.Context, conn *store.Connection, t *tenant.Tenant) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.TenantCreated, user.SystemID, logTenant(t))
+	return err
+}
+
+// LogTenantUpdated log tenant updated
+func LogTenantUpdated(ctx context.Context, conn *store.Connection, t *tenant.Tenant) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.TenantUpdated, user.SystemID, logTenant(t))
+	return err
+}
+
+// LogTenantDeleted log tenant deleted
+func LogTenantDeleted(ctx context.Context, conn *store.Connection, t *tenant.Tenant) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.TenantDeleted, user.SystemID, logTenant(t))
+	return err
+}
+
+func logTenant(t *tenant.Tenant) types.Map {
+	return types.Map{
+		key.TenantID: t.ID.String(),
+		key.Name:     t.Name,
+		key.Hostname: t.Host,
+	}
+}
@@ -0,0 +1,48 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/models/auditlog"
+	"github.com/jrapoport/gothic/models/tenant"
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+	"github.com/stretchr/testify/require"
+)
+
+func testTenant(t *testing.T) *tenant.Tenant {
+	tn, err := tenant.NewTenant("acme", "acme.example.com", config.TenantConfig{})
+	require.NoError(t, err)
+	return tn
+}
+
+func TestLogTenantCreated(t *testing.T) {
+	t.Parallel()
+	tn := testTenant(t)
+	testLogEntry(t, auditlog.TenantCreated, user.SystemID, logTenant(tn),
+		func(ctx context.Context, conn *store.Connection, _ uuid.UUID, _ types.Map) error {
+			return LogTenantCreated(ctx, conn, tn)
+		})
+}
+
+func TestLogTenantUpdated(t *testing.T) {
+	t.Parallel()
+	tn := testTenant(t)
+	testLogEntry(t, auditlog.TenantUpdated, user.SystemID, logTenant(tn),
+		func(ctx context.Context, conn *store.Connection, _ uuid.UUID, _ types.Map) error {
+			return LogTenantUpdated(ctx, conn, tn)
+		})
+}
+
+func TestLogTenantDeleted(t *testing.T) {
+	t.Parallel()
+	tn := testTenant(t)
+	testLogEntry(t, auditlog.TenantDeleted, user.SystemID, logTenant(tn),
+		func(ctx context.Context, conn *store.Connection, _ uuid.UUID, _ types.Map) error {
+			return LogTenantDeleted(ctx, conn, tn)
+		})
+}
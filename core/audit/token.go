@@ -3,6 +3,7 @@ package audit
 import (
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/jrapoport/gothic/core/context"
 	"github.com/jrapoport/gothic/models/auditlog"
 	"github.com/jrapoport/gothic/models/token"
@@ -29,6 +30,36 @@ func LogTokenRevoked(ctx context.Context, conn *store.Connection, t token.Token)
 	return err
 }
 
+// LogSessionRevoked log session revoked
+func LogSessionRevoked(ctx context.Context, conn *store.Connection, rt *token.RefreshToken) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.SessionRevoked, rt.IssuedTo(), logSession(rt))
+	return err
+}
+
+// LogSessionsRevoked log sessions revoked
+func LogSessionsRevoked(ctx context.Context, conn *store.Connection, userID uuid.UUID, count int) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.SessionsRevoked, userID, types.Map{
+		key.Count: count,
+	})
+	return err
+}
+
+// LogBearerRevoked log bearer token revoked
+func LogBearerRevoked(ctx context.Context, conn *store.Connection, rt *token.RevokedToken) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.BearerRevoked, rt.UserID, types.Map{
+		key.TokenID: rt.TokenID,
+	})
+	return err
+}
+
+// LogBearersRevoked log bearer tokens issued before a timestamp revoked
+func LogBearersRevoked(ctx context.Context, conn *store.Connection, rt *token.RevokedToken) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.BearersRevoked, rt.UserID, types.Map{
+		key.Revoked: rt.RevokedAt.UTC().Format(time.RFC3339),
+	})
+	return err
+}
+
 /*
 // LogRevokedAll log all tokens revoked
 func LogRevokedAll(ctx context.Context, conn *store.Connection, t token.Token) error {
@@ -37,6 +68,21 @@ func LogRevokedAll(ctx context.Context, conn *store.Connection, t token.Token) e
 }
 */
 
+func logSession(rt *token.RefreshToken) types.Map {
+	data := types.Map{
+		key.SessionID: rt.SessionID.String(),
+		key.Issued:    rt.SignedInAt.UTC().Format(time.RFC3339),
+		key.LastUsed:  rt.LastActive().UTC().Format(time.RFC3339),
+	}
+	if rt.UserAgent != "" {
+		data[key.UserAgent] = rt.UserAgent
+	}
+	if rt.DeviceName != "" {
+		data[key.DeviceName] = rt.DeviceName
+	}
+	return data
+}
+
 func logToken(t token.Token) types.Map {
 	data := types.Map{
 		key.Token:  t.String(),
@@ -9,6 +9,7 @@ import (
 	"github.com/jrapoport/gothic/models/auditlog"
 	"github.com/jrapoport/gothic/models/token"
 	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/models/types/key"
 	"github.com/jrapoport/gothic/store"
 )
 
@@ -52,6 +53,50 @@ func TestLogTokenRevoked(t *testing.T) {
 		})
 }
 
+func TestLogSessionRevoked(t *testing.T) {
+	t.Parallel()
+	uid := uuid.New()
+	tk := token.NewSessionRefreshToken(uid, token.NewSession("agent", "", "laptop"))
+	tk.ID = 100
+	tk.CreatedAt = time.Now().UTC()
+	testLogEntry(t, auditlog.SessionRevoked, uid, logSession(tk),
+		func(ctx context.Context, conn *store.Connection, uid uuid.UUID, _ types.Map) error {
+			return LogSessionRevoked(ctx, conn, tk)
+		})
+}
+
+func TestLogSessionsRevoked(t *testing.T) {
+	t.Parallel()
+	const count = 3
+	testLogEntry(t, auditlog.SessionsRevoked, uuid.New(), types.Map{
+		key.Count: count,
+	}, func(ctx context.Context, conn *store.Connection, uid uuid.UUID, _ types.Map) error {
+		return LogSessionsRevoked(ctx, conn, uid, count)
+	})
+}
+
+func TestLogBearerRevoked(t *testing.T) {
+	t.Parallel()
+	uid := uuid.New()
+	rt := token.NewRevokedToken(uid, uuid.New().String(), time.Hour)
+	testLogEntry(t, auditlog.BearerRevoked, uid, types.Map{
+		key.TokenID: rt.TokenID,
+	}, func(ctx context.Context, conn *store.Connection, uid uuid.UUID, _ types.Map) error {
+		return LogBearerRevoked(ctx, conn, rt)
+	})
+}
+
+func TestLogBearersRevoked(t *testing.T) {
+	t.Parallel()
+	uid := uuid.New()
+	rt := token.NewRevokedBefore(uid, time.Now(), time.Hour)
+	testLogEntry(t, auditlog.BearersRevoked, uid, types.Map{
+		key.Revoked: rt.RevokedAt.UTC().Format(time.RFC3339),
+	}, func(ctx context.Context, conn *store.Connection, uid uuid.UUID, _ types.Map) error {
+		return LogBearersRevoked(ctx, conn, rt)
+	})
+}
+
 /*
 func TestLogRevokedAll(t *testing.T) {
 	uid := uuid.New()
@@ -17,6 +17,15 @@ func LogLogin(ctx context.Context, conn *store.Connection, userID uuid.UUID) err
 	return err
 }
 
+// LogLoginFailed log user failed login. The user id
+// is nil if a user with the email was not found.
+func LogLoginFailed(ctx context.Context, conn *store.Connection, userID uuid.UUID, email string) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.LoginFailed, userID, types.Map{
+		key.Email: email,
+	})
+	return err
+}
+
 // LogLogout log user logout
 func LogLogout(ctx context.Context, conn *store.Connection, userID uuid.UUID) error {
 	_, err := CreateLogEntry(ctx, conn, auditlog.Logout, userID, nil)
@@ -35,6 +44,12 @@ func LogEmailChange(ctx context.Context, conn *store.Connection, userID uuid.UUI
 	return err
 }
 
+// LogPhoneChange log user phone change
+func LogPhoneChange(ctx context.Context, conn *store.Connection, userID uuid.UUID) error {
+	_, err := CreateLogEntry(ctx, conn, auditlog.Phone, userID, nil)
+	return err
+}
+
 // LogUserUpdated log user updated
 func LogUserUpdated(ctx context.Context, conn *store.Connection, userID uuid.UUID) error {
 	_, err := CreateLogEntry(ctx, conn, auditlog.Updated, userID, nil)
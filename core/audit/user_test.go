@@ -46,6 +46,26 @@ func TestLogEmailChange(t *testing.T) {
 		})
 }
 
+func TestLogLoginFailed(t *testing.T) {
+	t.Parallel()
+	const email = "peaches@example.com"
+	testLogEntry(t, auditlog.LoginFailed, uuid.New(),
+		types.Map{
+			key.Email: email,
+		},
+		func(ctx context.Context, conn *store.Connection, uid uuid.UUID, _ types.Map) error {
+			return LogLoginFailed(ctx, conn, uid, email)
+		})
+}
+
+func TestLogPhoneChange(t *testing.T) {
+	t.Parallel()
+	testLogEntry(t, auditlog.Phone, uuid.New(), nil,
+		func(ctx context.Context, conn *store.Connection, uid uuid.UUID, _ types.Map) error {
+			return LogPhoneChange(ctx, conn, uid)
+		})
+}
+
 func TestLogUpdate(t *testing.T) {
 	t.Parallel()
 	testLogEntry(t, auditlog.Updated, uuid.New(), nil,
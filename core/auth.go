@@ -15,15 +15,17 @@ import (
 	"github.com/jrapoport/gothic/store"
 )
 
-// GetAuthorizationURL get the auth url for a configured provider.
-func (a *API) GetAuthorizationURL(ctx context.Context, p provider.Name) (string, error) {
+// GetAuthorizationURL get the auth url for a configured provider. The auth
+// token of the url is bound to the client with the binding, and can only be
+// used to authorize a user by the same client.
+func (a *API) GetAuthorizationURL(ctx context.Context, p provider.Name, b auth.Binding) (string, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
 	ctx.SetProvider(p)
 	var au *auth.URL
 	err := a.conn.Transaction(func(tx *store.Connection) (err error) {
-		au, err = a.ext.GrantAuthURL(a.conn, p, 60*time.Minute)
+		au, err = a.ext.GrantAuthURL(tx, p, 60*time.Minute, b)
 		if err != nil {
 			return err
 		}
@@ -35,14 +37,15 @@ func (a *API) GetAuthorizationURL(ctx context.Context, p provider.Name) (string,
 	return au.URL, nil
 }
 
-// AuthorizeUser authorizes a user with a configured provider.
-func (a *API) AuthorizeUser(ctx context.Context, tok string, data types.Map) (*user.User, error) {
+// AuthorizeUser authorizes a user with a configured provider. The verifier
+// must match the binding of the auth token.
+func (a *API) AuthorizeUser(ctx context.Context, tok string, data types.Map, v auth.Verifier) (*user.User, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
 	var u *user.User
 	err := a.conn.Transaction(func(tx *store.Connection) error {
-		au, err := a.ext.AuthorizeUser(tx, tok, data)
+		au, err := a.ext.AuthorizeUser(tx, tok, data, v)
 		if err != nil {
 			return err
 		}
@@ -1,7 +1,12 @@
 package auth
 
 import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/jrapoport/gothic/core/tokens"
@@ -10,7 +15,10 @@ import (
 	"github.com/jrapoport/gothic/models/types/key"
 	"github.com/jrapoport/gothic/models/types/provider"
 	"github.com/jrapoport/gothic/store"
+	"github.com/jrapoport/gothic/utils"
 	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/google"
+	"github.com/markbates/goth/providers/openidConnect"
 )
 
 // URL holds a authorization url and token.
@@ -19,30 +27,67 @@ type URL struct {
 	Token *token.AuthToken
 }
 
-// GrantAuthURL returns the auth url for a named provider.
-func (pv *Providers) GrantAuthURL(conn *store.Connection, name provider.Name, exp time.Duration) (*URL, error) {
+// Binding binds the auth token of an auth url to the client that requested
+// it to prevent login CSRF. At least one of the code challenge or the secret
+// is required.
+type Binding struct {
+	// CodeChallenge is the PKCE code challenge of a public client.
+	CodeChallenge string
+	// ChallengeMethod is the PKCE code challenge method.
+	ChallengeMethod string
+	// Secret is held by the client, e.g. in a cookie.
+	Secret string
+}
+
+// Verifier proves that the client that authorizes a user is the client
+// that requested the auth url.
+type Verifier struct {
+	// CodeVerifier is the PKCE code verifier for the code challenge.
+	CodeVerifier string
+	// Secret is the secret of the binding.
+	Secret string
+}
+
+// GrantAuthURL returns the auth url for a named provider. If the provider is
+// an OpenID Connect provider, a nonce is added to the auth url.
+func (pv *Providers) GrantAuthURL(conn *store.Connection, name provider.Name, exp time.Duration, b Binding) (*URL, error) {
 	p, err := pv.GetProvider(name)
 	if err != nil {
 		return nil, err
 	}
+	if b.CodeChallenge == "" && b.Secret == "" {
+		return nil, errors.New("client binding required")
+	}
 	var au = &URL{}
 	err = conn.Transaction(func(tx *store.Connection) error {
 		au.Token, err = tokens.GrantAuthToken(tx, name, exp)
 		if err != nil {
 			return err
 		}
-		s, err := p.BeginAuth(au.Token.String())
+		err = au.Token.SetChallenge(b.CodeChallenge, b.ChallengeMethod)
 		if err != nil {
 			return err
 		}
-		err = tx.Model(au.Token).Update(key.Data, types.Map{
-			key.Session: s.Marshal(),
-		}).Error
+		au.Token.SetBinding(b.Secret)
+		s, err := p.BeginAuth(au.Token.String())
 		if err != nil {
 			return err
 		}
 		au.URL, err = s.GetAuthURL()
-		return err
+		if err != nil {
+			return err
+		}
+		if openID(p) {
+			au.Token.Nonce = utils.SecureToken()
+			au.URL, err = addNonce(au.URL, au.Token.Nonce)
+			if err != nil {
+				return err
+			}
+		}
+		au.Token.Data = types.Map{
+			key.Session: s.Marshal(),
+		}
+		return tx.Save(au.Token).Error
 	})
 	if err != nil {
 		return nil, err
@@ -51,13 +96,17 @@ func (pv *Providers) GrantAuthURL(conn *store.Connection, name provider.Name, ex
 }
 
 // AuthorizeUser checks the token and turns the oauth authorized user.
-func (pv *Providers) AuthorizeUser(conn *store.Connection, tok string, data types.Map) (*goth.User, error) {
+func (pv *Providers) AuthorizeUser(conn *store.Connection, tok string, data types.Map, v Verifier) (*goth.User, error) {
 	var u goth.User
 	err := conn.Transaction(func(tx *store.Connection) error {
 		t, err := tokens.GetAuthToken(tx, tok)
 		if err != nil {
 			return err
 		}
+		err = verifyToken(t, v)
+		if err != nil {
+			return err
+		}
 		p, err := pv.GetProvider(t.Provider)
 		if err != nil {
 			return err
@@ -78,6 +127,12 @@ func (pv *Providers) AuthorizeUser(conn *store.Connection, tok string, data type
 		if err != nil {
 			return err
 		}
+		if t.Nonce != "" {
+			err = verifyNonce(u.IDToken, t.Nonce)
+			if err != nil {
+				return err
+			}
+		}
 		return tokens.UseToken(tx, t)
 	})
 	if err != nil {
@@ -85,3 +140,69 @@ func (pv *Providers) AuthorizeUser(conn *store.Connection, tok string, data type
 	}
 	return &u, nil
 }
+
+// verifyToken returns an error if the auth token was not
+// requested by the client of the verifier.
+func verifyToken(t *token.AuthToken, v Verifier) error {
+	if !t.Usable() {
+		return errors.New("invalid token")
+	}
+	if !t.Bound() {
+		return errors.New("client binding required")
+	}
+	if !t.VerifyChallenge(v.CodeVerifier) {
+		return errors.New("invalid code verifier")
+	}
+	if !t.VerifyBinding(v.Secret) {
+		return errors.New("invalid client binding")
+	}
+	return nil
+}
+
+// openID returns true if the provider returns an OpenID Connect id token.
+func openID(p Provider) bool {
+	switch p.(type) {
+	case *openidConnect.Provider, *google.Provider:
+		return true
+	default:
+		return false
+	}
+}
+
+func addNonce(authURL, nonce string) (string, error) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set(key.Nonce, nonce)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// verifyNonce returns an error if the nonce of the id token does not match.
+// The signature of the id token is not verified. The id token was returned by
+// the token endpoint of the provider over TLS when the code was exchanged, so
+// per OpenID Connect Core 3.1.3.7 the TLS server validation authenticates it
+// instead. An id token from any other source must not be trusted this way.
+func verifyNonce(idToken, nonce string) error {
+	parts := strings.Split(idToken, ".")
+	if len(parts) != 3 {
+		return errors.New("invalid id token")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return err
+	}
+	var claims struct {
+		Nonce string `json:"nonce"`
+	}
+	err = json.Unmarshal(b, &claims)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return errors.New("invalid nonce")
+	}
+	return nil
+}
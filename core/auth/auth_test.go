@@ -1,21 +1,40 @@
 package auth
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/models/token"
 	"github.com/jrapoport/gothic/models/types"
 	"github.com/jrapoport/gothic/models/types/key"
 	"github.com/jrapoport/gothic/models/types/provider"
 	"github.com/jrapoport/gothic/test/tconf"
 	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/jrapoport/gothic/test/tutils"
 	"github.com/jrapoport/gothic/utils"
+	"github.com/markbates/goth/providers/openidConnect"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+const (
+	testSecret       = "provider-test-binding-secret"
+	testCodeVerifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+)
+
+var (
+	testBinding  = Binding{Secret: testSecret}
+	testVerifier = Verifier{Secret: testSecret}
+)
+
 func getToken(t *testing.T, authURL string) (string, types.Map) {
 	au, err := url.Parse(authURL)
 	require.NoError(t, err)
@@ -37,24 +56,33 @@ func TestGrantAuthURL(t *testing.T) {
 			// assert.Contains(t, err.Error(), "401 Unauthorized")
 			continue
 		}
-		auth, err := ps.GrantAuthURL(conn, p, 60*time.Minute)
+		auth, err := ps.GrantAuthURL(conn, p, 60*time.Minute, testBinding)
 		assert.NoError(t, err)
 		_, err = url.Parse(auth.URL)
 		assert.NoError(t, err)
 	}
-	_, err = ps.GrantAuthURL(conn, provider.Unknown, 0)
+	_, err = ps.GrantAuthURL(conn, provider.Unknown, 0, testBinding)
 	assert.Error(t, err)
-	_, err = ps.GrantAuthURL(conn, c.Provider(), 0)
+	_, err = ps.GrantAuthURL(conn, c.Provider(), 0, testBinding)
 	assert.Error(t, err)
-	_, err = ps.GrantAuthURL(conn, badProvider, 0)
+	_, err = ps.GrantAuthURL(conn, badProvider, 0, testBinding)
 	assert.Error(t, err)
 	// confirm different tokens are returned
 	_, mock := tconf.MockedProvider(t, c, "")
 	ps.UseProviders(mock)
 	p := provider.Name(mock.Name())
-	auth1, err := ps.GrantAuthURL(conn, p, 60*time.Minute)
+	// no binding
+	_, err = ps.GrantAuthURL(conn, p, 60*time.Minute, Binding{})
+	assert.Error(t, err)
+	// bad challenge method
+	_, err = ps.GrantAuthURL(conn, p, 60*time.Minute, Binding{
+		CodeChallenge:   testCodeVerifier,
+		ChallengeMethod: "bad",
+	})
+	assert.Error(t, err)
+	auth1, err := ps.GrantAuthURL(conn, p, 60*time.Minute, testBinding)
 	assert.NoError(t, err)
-	auth2, err := ps.GrantAuthURL(conn, p, 60*time.Minute)
+	auth2, err := ps.GrantAuthURL(conn, p, 60*time.Minute, testBinding)
 	assert.NoError(t, err)
 	tok1, _ := getToken(t, auth1.URL)
 	assert.Equal(t, auth1.Token.String(), tok1)
@@ -69,33 +97,180 @@ func TestAuthorizeUser(t *testing.T) {
 	_, mock := tconf.MockedProvider(t, c, "")
 	providers.UseProviders(mock)
 	p := provider.Name(mock.Name())
-	authURL, err := providers.GrantAuthURL(conn, p, 0)
+	authURL, err := providers.GrantAuthURL(conn, p, 0, testBinding)
 	require.NoError(t, err)
 	tok, data := getToken(t, authURL.URL)
-	_, err = providers.AuthorizeUser(conn, tok, data)
+	_, err = providers.AuthorizeUser(conn, tok, data, testVerifier)
 	require.NoError(t, err)
 	// cannot reuse token
-	_, err = providers.AuthorizeUser(conn, tok, data)
+	_, err = providers.AuthorizeUser(conn, tok, data, testVerifier)
 	require.Error(t, err)
 	// empty token
-	_, err = providers.AuthorizeUser(conn, "", data)
+	_, err = providers.AuthorizeUser(conn, "", data, testVerifier)
 	require.Error(t, err)
 	// bad token
-	_, err = providers.AuthorizeUser(conn, utils.SecureToken(), data)
+	_, err = providers.AuthorizeUser(conn, utils.SecureToken(), data, testVerifier)
 	require.Error(t, err)
+	// unbound token
+	at, err := tokens.GrantAuthToken(conn, p, 0)
+	require.NoError(t, err)
+	_, err = providers.AuthorizeUser(conn, at.String(), data, Verifier{})
+	assert.Error(t, err)
+	bindToken := func(at *token.AuthToken) {
+		at.SetBinding(testSecret)
+		err = conn.Save(at).Error
+		require.NoError(t, err)
+	}
 	// bad provider
-	at, err := tokens.GrantAuthToken(conn, "bad", 0)
+	at, err = tokens.GrantAuthToken(conn, "bad", 0)
 	require.NoError(t, err)
-	_, err = providers.AuthorizeUser(conn, at.String(), data)
+	bindToken(at)
+	_, err = providers.AuthorizeUser(conn, at.String(), data, testVerifier)
 	assert.Error(t, err)
 	// provider not found
 	at, err = tokens.GrantAuthToken(conn, provider.Google, 0)
 	require.NoError(t, err)
-	_, err = providers.AuthorizeUser(conn, at.String(), data)
+	bindToken(at)
+	_, err = providers.AuthorizeUser(conn, at.String(), data, testVerifier)
 	assert.Error(t, err)
 	// invalid session
 	at, err = tokens.GrantAuthToken(conn, p, 0)
 	require.NoError(t, err)
-	_, err = providers.AuthorizeUser(conn, at.String(), data)
+	bindToken(at)
+	_, err = providers.AuthorizeUser(conn, at.String(), data, testVerifier)
 	assert.Error(t, err)
 }
+
+func TestAuthorizeUser_Binding(t *testing.T) {
+	providers := NewProviders()
+	conn, c := tconn.TempConn(t)
+	_, mock := tconf.MockedProvider(t, c, "")
+	providers.UseProviders(mock)
+	p := provider.Name(mock.Name())
+	sum := sha256.Sum256([]byte(testCodeVerifier))
+	s256 := base64.RawURLEncoding.EncodeToString(sum[:])
+	tests := []struct {
+		b    Binding
+		v    Verifier
+		Err  assert.ErrorAssertionFunc
+		name string
+	}{
+		{testBinding, Verifier{}, assert.Error, "no secret"},
+		{testBinding, Verifier{Secret: "bad"}, assert.Error, "bad secret"},
+		{testBinding, Verifier{Secret: testSecret, CodeVerifier: testCodeVerifier},
+			assert.Error, "unexpected verifier"},
+		{testBinding, testVerifier, assert.NoError, "secret"},
+		{Binding{CodeChallenge: s256, ChallengeMethod: token.ChallengeS256},
+			Verifier{}, assert.Error, "no verifier"},
+		{Binding{CodeChallenge: s256, ChallengeMethod: token.ChallengeS256},
+			Verifier{CodeVerifier: s256}, assert.Error, "bad verifier"},
+		{Binding{CodeChallenge: s256, ChallengeMethod: token.ChallengeS256},
+			Verifier{CodeVerifier: testCodeVerifier, Secret: testSecret},
+			assert.Error, "unexpected secret"},
+		{Binding{CodeChallenge: s256, ChallengeMethod: token.ChallengeS256},
+			Verifier{CodeVerifier: testCodeVerifier}, assert.NoError, "s256"},
+		{Binding{CodeChallenge: testCodeVerifier},
+			Verifier{CodeVerifier: testCodeVerifier}, assert.NoError, "plain"},
+		{Binding{CodeChallenge: s256, ChallengeMethod: token.ChallengeS256, Secret: testSecret},
+			Verifier{CodeVerifier: testCodeVerifier}, assert.Error, "both no secret"},
+		{Binding{CodeChallenge: s256, ChallengeMethod: token.ChallengeS256, Secret: testSecret},
+			Verifier{CodeVerifier: testCodeVerifier, Secret: testSecret}, assert.NoError, "both"},
+	}
+	for _, test := range tests {
+		au, err := providers.GrantAuthURL(conn, p, 0, test.b)
+		require.NoError(t, err)
+		tok, data := getToken(t, au.URL)
+		_, err = providers.AuthorizeUser(conn, tok, data, test.v)
+		test.Err(t, err, test.name)
+	}
+}
+
+func TestAuthorizeUser_Nonce(t *testing.T) {
+	providers := NewProviders()
+	conn, _ := tconn.TempConn(t)
+	op := newOpenIDServer(t)
+	providers.UseProviders(op.provider)
+	p := provider.Name(op.provider.Name())
+	au, err := providers.GrantAuthURL(conn, p, 0, testBinding)
+	require.NoError(t, err)
+	u, err := url.Parse(au.URL)
+	require.NoError(t, err)
+	nonce := u.Query().Get(key.Nonce)
+	require.NotEmpty(t, nonce)
+	assert.Equal(t, au.Token.String(), u.Query().Get(key.State))
+	data := types.Map{key.Code: "code"}
+	// bad nonce
+	op.nonce = "bad"
+	_, err = providers.AuthorizeUser(conn, au.Token.String(), data, testVerifier)
+	assert.Error(t, err)
+	// no nonce
+	op.nonce = ""
+	_, err = providers.AuthorizeUser(conn, au.Token.String(), data, testVerifier)
+	assert.Error(t, err)
+	// nonce
+	op.nonce = nonce
+	gu, err := providers.AuthorizeUser(conn, au.Token.String(), data, testVerifier)
+	require.NoError(t, err)
+	assert.Equal(t, op.subject, gu.UserID)
+	assert.Equal(t, op.email, gu.Email)
+	// the nonce is single use
+	_, err = providers.AuthorizeUser(conn, au.Token.String(), data, testVerifier)
+	assert.Error(t, err)
+}
+
+type openIDServer struct {
+	provider *openidConnect.Provider
+	issuer   string
+	subject  string
+	email    string
+	nonce    string
+}
+
+// newOpenIDServer returns an OpenID Connect provider backed by a test server.
+func newOpenIDServer(t *testing.T) *openIDServer {
+	const clientKey = "provider-test-client-key"
+	op := &openIDServer{
+		subject: uuid.NewString(),
+		email:   tutils.RandomEmail(),
+	}
+	var issuer string
+	mux := http.NewServeMux()
+	mux.HandleFunc(openIDDiscoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(types.Map{
+			"issuer":                 issuer,
+			"authorization_endpoint": issuer + "/authorize",
+			"token_endpoint":         issuer + "/token",
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		claims := types.Map{
+			"iss":   issuer,
+			"aud":   clientKey,
+			"sub":   op.subject,
+			"email": op.email,
+			"exp":   time.Now().Add(time.Hour).Unix(),
+		}
+		if op.nonce != "" {
+			claims[key.Nonce] = op.nonce
+		}
+		enc := base64.RawURLEncoding
+		idToken := enc.EncodeToString([]byte(`{"alg":"none"}`)) + "." +
+			enc.EncodeToString(claims.JSON()) + "." + enc.EncodeToString([]byte("sig"))
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(types.Map{
+			"access_token": utils.SecureToken(),
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     idToken,
+		})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	issuer = srv.URL
+	op.issuer = issuer
+	var err error
+	op.provider, err = openidConnect.New(clientKey, "provider-test-secret",
+		"http://auth.example.com/callback", srv.URL+openIDDiscoveryPath)
+	require.NoError(t, err)
+	return op
+}
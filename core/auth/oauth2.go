@@ -0,0 +1,237 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// OAuth2Provider is a generic OAuth2 provider configured with explicit
+// endpoints. The user is read from the user info of the provider with
+// the JSON paths of the attributes.
+type OAuth2Provider struct {
+	// HTTPClient is used to fetch the user info. If nil, the default
+	// goth http client is used.
+	HTTPClient  *http.Client
+	name        string
+	config      *oauth2.Config
+	userInfoURL string
+	attributes  config.Attributes
+}
+
+var _ goth.Provider = (*OAuth2Provider)(nil)
+
+// NewOAuth2Provider returns a new generic OAuth2 provider.
+func NewOAuth2Provider(name string, p config.Provider) *OAuth2Provider {
+	return &OAuth2Provider{
+		name: name,
+		config: &oauth2.Config{
+			ClientID:     p.ClientKey,
+			ClientSecret: p.Secret,
+			RedirectURL:  p.CallbackURL,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  p.AuthURL,
+				TokenURL: p.TokenURL,
+			},
+			Scopes: p.Scopes,
+		},
+		userInfoURL: p.UserInfoURL,
+		attributes:  p.Attributes,
+	}
+}
+
+// Name returns the name of the provider.
+func (p *OAuth2Provider) Name() string {
+	return p.name
+}
+
+// SetName sets the name of the provider.
+func (p *OAuth2Provider) SetName(name string) {
+	p.name = name
+}
+
+// Client returns the http client used by the provider.
+func (p *OAuth2Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the generic OAuth2 provider.
+func (p *OAuth2Provider) Debug(bool) {}
+
+// BeginAuth returns a session with the auth url for the state.
+func (p *OAuth2Provider) BeginAuth(state string) (goth.Session, error) {
+	return &OAuth2Session{
+		AuthURL: p.config.AuthCodeURL(state),
+	}, nil
+}
+
+// UnmarshalSession unmarshals a session.
+func (p *OAuth2Provider) UnmarshalSession(data string) (goth.Session, error) {
+	s := &OAuth2Session{}
+	err := json.Unmarshal([]byte(data), s)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// FetchUser returns the user from the user info of the provider.
+func (p *OAuth2Provider) FetchUser(session goth.Session) (goth.User, error) {
+	s, ok := session.(*OAuth2Session)
+	if !ok || s.AccessToken == "" {
+		return goth.User{}, fmt.Errorf("%s cannot get user information without access token", p.name)
+	}
+	info, err := p.userInfo(s.AccessToken)
+	if err != nil {
+		return goth.User{}, err
+	}
+	u := goth.User{
+		Provider:     p.name,
+		AccessToken:  s.AccessToken,
+		RefreshToken: s.RefreshToken,
+		ExpiresAt:    s.ExpiresAt,
+		RawData:      info,
+		UserID:       jsonPath(info, p.attributes.ID),
+		Email:        jsonPath(info, p.attributes.Email),
+		Name:         jsonPath(info, p.attributes.Name),
+		AvatarURL:    jsonPath(info, p.attributes.Avatar),
+	}
+	if u.UserID == "" {
+		return goth.User{}, fmt.Errorf("%s user id not found", p.name)
+	}
+	return u, nil
+}
+
+func (p *OAuth2Provider) userInfo(accessToken string) (map[string]interface{}, error) {
+	req, err := http.NewRequest(http.MethodGet, p.userInfoURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+	res, err := p.Client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s responded with a %d trying to fetch user information", p.name, res.StatusCode)
+	}
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	info := map[string]interface{}{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	// keep numeric ids as they are
+	dec.UseNumber()
+	err = dec.Decode(&info)
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// RefreshTokenAvailable returns true.
+func (p *OAuth2Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken returns a new access token for the refresh token.
+func (p *OAuth2Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	ctx := goth.ContextForClient(p.Client())
+	tok := &oauth2.Token{RefreshToken: refreshToken}
+	return p.config.TokenSource(ctx, tok).Token()
+}
+
+// OAuth2Session holds the state of a generic OAuth2 provider flow.
+type OAuth2Session struct {
+	AuthURL      string
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time
+}
+
+var _ goth.Session = (*OAuth2Session)(nil)
+
+// GetAuthURL returns the auth url of the session.
+func (s OAuth2Session) GetAuthURL() (string, error) {
+	if s.AuthURL == "" {
+		return "", errors.New("an AuthURL has not been set")
+	}
+	return s.AuthURL, nil
+}
+
+// Authorize exchanges the code for an access token.
+func (s *OAuth2Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
+	p, ok := provider.(*OAuth2Provider)
+	if !ok {
+		return "", errors.New("invalid provider")
+	}
+	ctx := goth.ContextForClient(p.Client())
+	tok, err := p.config.Exchange(ctx, params.Get(key.Code))
+	if err != nil {
+		return "", err
+	}
+	if !tok.Valid() {
+		return "", errors.New("invalid token received from provider")
+	}
+	s.AccessToken = tok.AccessToken
+	s.RefreshToken = tok.RefreshToken
+	s.ExpiresAt = tok.Expiry
+	return tok.AccessToken, nil
+}
+
+// Marshal marshals the session into a string.
+func (s OAuth2Session) Marshal() string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func (s OAuth2Session) String() string {
+	return s.Marshal()
+}
+
+// jsonPath returns the value at the dot separated path as a string, e.g.
+// "data.emails.0.value". Array elements are selected by their index.
+func jsonPath(data map[string]interface{}, path string) string {
+	if path == "" {
+		return ""
+	}
+	var v interface{} = data
+	for _, k := range strings.Split(path, ".") {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			v = t[k]
+		case []interface{}:
+			i, err := strconv.Atoi(k)
+			if err != nil || i < 0 || i >= len(t) {
+				return ""
+			}
+			v = t[i]
+		default:
+			return ""
+		}
+	}
+	switch t := v.(type) {
+	case string:
+		return t
+	case json.Number:
+		return t.String()
+	case bool:
+		return strconv.FormatBool(t)
+	default:
+		return ""
+	}
+}
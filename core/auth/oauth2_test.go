@@ -0,0 +1,221 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/models/types/provider"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/jrapoport/gothic/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAccessToken = "provider-test-access-token"
+
+type oauth2Server struct {
+	*httptest.Server
+	info types.Map
+}
+
+// newOAuth2Server returns a test OAuth2 server that grants the test access
+// token for any code except "bad", and returns the info for the access token.
+func newOAuth2Server(t *testing.T) *oauth2Server {
+	os := &oauth2Server{
+		info: types.Map{
+			"data": types.Map{
+				"user_id": 12345,
+				"emails": []types.Map{
+					{"value": "test@example.com"},
+				},
+				"profile": types.Map{
+					"name":    "Test User",
+					"picture": "https://example.com/avatar.png",
+				},
+			},
+		},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		err := r.ParseForm()
+		require.NoError(t, err)
+		if r.Form.Get(key.Code) == "bad" {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(types.Map{
+			"access_token":  testAccessToken,
+			"token_type":    "Bearer",
+			"expires_in":    3600,
+			"refresh_token": utils.SecureToken(),
+		})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testAccessToken {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(os.info)
+	})
+	os.Server = httptest.NewServer(mux)
+	t.Cleanup(os.Close)
+	return os
+}
+
+func (os *oauth2Server) config() config.Provider {
+	return config.Provider{
+		ClientKey:   "provider-test-client-key",
+		Secret:      "provider-test-secret",
+		CallbackURL: "http://auth.example.com/callback",
+		Scopes:      []string{"profile"},
+		AuthURL:     os.URL + "/authorize",
+		TokenURL:    os.URL + "/token",
+		UserInfoURL: os.URL + "/userinfo",
+		Attributes: config.Attributes{
+			ID:     "data.user_id",
+			Email:  "data.emails.0.value",
+			Name:   "data.profile.name",
+			Avatar: "data.profile.picture",
+		},
+	}
+}
+
+func TestOAuth2Provider(t *testing.T) {
+	const name = "example"
+	os := newOAuth2Server(t)
+	conn, c := tconn.TempConn(t)
+	c.Providers = config.Providers{name: os.config()}
+	t.Cleanup(func() {
+		delete(provider.External, name)
+	})
+	ps := NewProviders()
+	err := ps.LoadProviders(c)
+	require.NoError(t, err)
+	p, err := ps.GetProvider(name)
+	require.NoError(t, err)
+	assert.IsType(t, &OAuth2Provider{}, p)
+	assert.Equal(t, name, p.Name())
+	au, err := ps.GrantAuthURL(conn, name, 0, testBinding)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(au.URL, os.URL+"/authorize"))
+	u, err := url.Parse(au.URL)
+	require.NoError(t, err)
+	assert.Equal(t, au.Token.String(), u.Query().Get(key.State))
+	assert.Equal(t, "profile", u.Query().Get(key.Scope))
+	// oauth2 providers have no nonce
+	assert.Empty(t, u.Query().Get(key.Nonce))
+	// bad code
+	_, err = ps.AuthorizeUser(conn, au.Token.String(), types.Map{key.Code: "bad"}, testVerifier)
+	assert.Error(t, err)
+	gu, err := ps.AuthorizeUser(conn, au.Token.String(), types.Map{key.Code: "code"}, testVerifier)
+	require.NoError(t, err)
+	assert.Equal(t, name, gu.Provider)
+	assert.Equal(t, "12345", gu.UserID)
+	assert.Equal(t, "test@example.com", gu.Email)
+	assert.Equal(t, "Test User", gu.Name)
+	assert.Equal(t, "https://example.com/avatar.png", gu.AvatarURL)
+	assert.Equal(t, testAccessToken, gu.AccessToken)
+	assert.NotEmpty(t, gu.RefreshToken)
+	tok, err := p.RefreshToken(gu.RefreshToken)
+	require.NoError(t, err)
+	assert.Equal(t, testAccessToken, tok.AccessToken)
+	// user id not found
+	os.info = types.Map{"email": "test@example.com"}
+	au, err = ps.GrantAuthURL(conn, name, 0, testBinding)
+	require.NoError(t, err)
+	_, err = ps.AuthorizeUser(conn, au.Token.String(), types.Map{key.Code: "code"}, testVerifier)
+	assert.Error(t, err)
+	// bad access token
+	_, err = p.FetchUser(&OAuth2Session{AccessToken: "bad"})
+	assert.Error(t, err)
+	_, err = p.FetchUser(&OAuth2Session{})
+	assert.Error(t, err)
+}
+
+func TestOpenIDProvider(t *testing.T) {
+	const name = "example-oidc"
+	op := newOpenIDServer(t)
+	conn, c := tconn.TempConn(t)
+	c.Providers = config.Providers{
+		name: {
+			ClientKey:   op.provider.ClientKey,
+			CallbackURL: "http://auth.example.com/callback",
+			Issuer:      op.issuer + "/",
+		},
+	}
+	t.Cleanup(func() {
+		delete(provider.External, name)
+	})
+	ps := NewProviders()
+	err := ps.LoadProviders(c)
+	require.NoError(t, err)
+	assert.True(t, provider.Name(name).IsExternal())
+	p, err := ps.GetProvider(name)
+	require.NoError(t, err)
+	assert.Equal(t, name, p.Name())
+	au, err := ps.GrantAuthURL(conn, name, 0, testBinding)
+	require.NoError(t, err)
+	u, err := url.Parse(au.URL)
+	require.NoError(t, err)
+	op.nonce = u.Query().Get(key.Nonce)
+	require.NotEmpty(t, op.nonce)
+	gu, err := ps.AuthorizeUser(conn, au.Token.String(), types.Map{key.Code: "code"}, testVerifier)
+	require.NoError(t, err)
+	assert.Equal(t, name, gu.Provider)
+	assert.Equal(t, op.subject, gu.UserID)
+	assert.Equal(t, op.email, gu.Email)
+	// discovery failed
+	c.Providers[name] = config.Provider{
+		ClientKey: op.provider.ClientKey,
+		Issuer:    op.issuer + "/bad",
+	}
+	err = ps.LoadProviders(c)
+	assert.Error(t, err)
+}
+
+func TestJSONPath(t *testing.T) {
+	t.Parallel()
+	const data = `{
+		"id": 12345,
+		"name": "test",
+		"verified": true,
+		"data": {
+			"emails": [
+				{"value": "test@example.com"}
+			]
+		}
+	}`
+	dec := json.NewDecoder(strings.NewReader(data))
+	dec.UseNumber()
+	m := map[string]interface{}{}
+	err := dec.Decode(&m)
+	require.NoError(t, err)
+	tests := []struct {
+		path  string
+		value string
+	}{
+		{"", ""},
+		{"id", "12345"},
+		{"name", "test"},
+		{"verified", "true"},
+		{"data.emails.0.value", "test@example.com"},
+		{"data.emails.1.value", ""},
+		{"data.emails.-1.value", ""},
+		{"data.emails.x.value", ""},
+		{"data.emails", ""},
+		{"name.first", ""},
+		{"missing", ""},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.value, jsonPath(m, test.path), test.path)
+	}
+}
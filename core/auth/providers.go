@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 
 	"github.com/jrapoport/gothic/config"
@@ -70,6 +71,9 @@ import (
 	"github.com/markbates/goth/providers/yandex"
 )
 
+// openIDDiscoveryPath is the path of the OpenID Connect discovery document.
+const openIDDiscoveryPath = "/.well-known/openid-configuration"
+
 // Provider wraps a Provider
 type Provider goth.Provider
 
@@ -94,7 +98,12 @@ func (pv *Providers) LoadProviders(c *config.Config) error {
 	defer pv.mu.Unlock()
 	pv.internal = c.Provider()
 	for name, v := range c.Providers {
-		err := pv.useProvider(name, v.ClientKey, v.Secret, v.CallbackURL, v.Scopes...)
+		var err error
+		if v.Generic() {
+			err = pv.useGenericProvider(name, v)
+		} else {
+			err = pv.useProvider(name, v.ClientKey, v.Secret, v.CallbackURL, v.Scopes...)
+		}
 		if err != nil {
 			err = fmt.Errorf("load %s provider failed: %w", name, err)
 			return err
@@ -326,6 +335,27 @@ func (pv *Providers) useProvider(name provider.Name, clientKey, secret, callback
 	return nil
 }
 
+// useGenericProvider uses a provider declared in the config. If the provider
+// has an issuer it is an OpenID Connect provider configured with discovery,
+// otherwise it is a generic OAuth2 provider.
+func (pv *Providers) useGenericProvider(name provider.Name, p config.Provider) error {
+	var pvdr Provider
+	if p.Issuer != "" {
+		discovery := strings.TrimSuffix(p.Issuer, "/") + openIDDiscoveryPath
+		oidc, err := openidConnect.New(p.ClientKey, p.Secret, p.CallbackURL, discovery, p.Scopes...)
+		if err != nil {
+			return err
+		}
+		pvdr = oidc
+	} else {
+		pvdr = NewOAuth2Provider(string(name), p)
+	}
+	pvdr.SetName(string(name))
+	provider.AddExternal(name)
+	pv.providers[pvdr.Name()] = pvdr
+	return nil
+}
+
 func getEnv(key string) string {
 	return os.Getenv(config.ENVPrefix + "_" + key)
 }
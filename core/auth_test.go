@@ -4,6 +4,7 @@ import (
 	"net/url"
 	"testing"
 
+	"github.com/jrapoport/gothic/core/auth"
 	"github.com/jrapoport/gothic/core/context"
 	"github.com/jrapoport/gothic/core/tokens"
 	"github.com/jrapoport/gothic/models/types"
@@ -14,8 +15,12 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+var testBinding = auth.Binding{Secret: "provider-test-binding-secret"}
+
+var testVerifier = auth.Verifier{Secret: testBinding.Secret}
+
 func authToken(t *testing.T, a *API, p provider.Name) (string, types.Map) {
-	authURL, err := a.GetAuthorizationURL(context.Background(), p)
+	authURL, err := a.GetAuthorizationURL(context.Background(), p, testBinding)
 	require.NoError(t, err)
 	au, err := url.Parse(authURL)
 	require.NoError(t, err)
@@ -32,27 +37,30 @@ func TestAPI_GetAuthorizationURL(t *testing.T) {
 	_, mock := tconf.MockedProvider(t, a.config, "")
 	a.ext.UseProviders(mock)
 	// no context
-	_, err := a.GetAuthorizationURL(ctx, provider.Unknown)
+	_, err := a.GetAuthorizationURL(ctx, provider.Unknown, testBinding)
 	assert.Error(t, err)
 	// no request context
-	_, err = a.GetAuthorizationURL(context.Background(), provider.Unknown)
+	_, err = a.GetAuthorizationURL(context.Background(), provider.Unknown, testBinding)
 	assert.Error(t, err)
 	// bad provider
 	ctx.SetProvider("bad")
-	_, err = a.GetAuthorizationURL(ctx, "bad")
+	_, err = a.GetAuthorizationURL(ctx, "bad", testBinding)
 	assert.Error(t, err)
 	// internal provider
 	ctx.SetProvider(a.Provider())
-	_, err = a.GetAuthorizationURL(ctx, a.Provider())
+	_, err = a.GetAuthorizationURL(ctx, a.Provider(), testBinding)
 	assert.Error(t, err)
 	// disabled provider
 	ctx.SetProvider(provider.BitBucket)
-	_, err = a.GetAuthorizationURL(ctx, provider.BitBucket)
+	_, err = a.GetAuthorizationURL(ctx, provider.BitBucket, testBinding)
 	assert.Error(t, err)
 	// valid external provider
 	p := provider.Name(mock.Name())
 	ctx.SetProvider(p)
-	authURL, err := a.GetAuthorizationURL(ctx, p)
+	// unbound client
+	_, err = a.GetAuthorizationURL(ctx, p, auth.Binding{})
+	assert.Error(t, err)
+	authURL, err := a.GetAuthorizationURL(ctx, p, testBinding)
 	assert.NoError(t, err)
 	_, err = url.Parse(authURL)
 	assert.NoError(t, err)
@@ -63,30 +71,34 @@ func TestAPI_AuthorizeUser(t *testing.T) {
 	_, mock := tconf.MockedProvider(t, a.config, "")
 	a.ext.UseProviders(mock)
 	// no token
-	_, err := a.AuthorizeUser(nil, "", nil)
+	_, err := a.AuthorizeUser(nil, "", nil, testVerifier)
 	assert.Error(t, err)
 	// bad token
-	_, err = a.AuthorizeUser(nil, "bad", nil)
+	_, err = a.AuthorizeUser(nil, "bad", nil, testVerifier)
 	assert.Error(t, err)
 	// bad provider
 	at, err := tokens.GrantAuthToken(a.conn, "bad", 0)
 	require.NoError(t, err)
-	_, err = a.AuthorizeUser(nil, at.Token, nil)
+	_, err = a.AuthorizeUser(nil, at.Token, nil, testVerifier)
 	assert.Error(t, err)
 	// provider not found
 	at, err = tokens.GrantAuthToken(a.conn, provider.Google, 0)
 	require.NoError(t, err)
-	_, err = a.AuthorizeUser(nil, at.Token, nil)
+	_, err = a.AuthorizeUser(nil, at.Token, nil, testVerifier)
 	assert.Error(t, err)
 	// invalid session
 	p := provider.Name(mock.Name())
 	at, err = tokens.GrantAuthToken(a.conn, p, 0)
 	require.NoError(t, err)
-	_, err = a.AuthorizeUser(nil, at.Token, nil)
+	_, err = a.AuthorizeUser(nil, at.Token, nil, testVerifier)
 	assert.Error(t, err)
-	// create
+	// unbound client
 	tok, data := authToken(t, a, p)
-	u, err := a.AuthorizeUser(context.Background(), tok, data)
+	_, err = a.AuthorizeUser(nil, tok, data, auth.Verifier{})
+	assert.Error(t, err)
+	// create
+	tok, data = authToken(t, a, p)
+	u, err := a.AuthorizeUser(context.Background(), tok, data, testVerifier)
 	assert.NoError(t, err)
 	require.NotNil(t, u)
 	assert.True(t, u.IsConfirmed())
@@ -98,7 +110,7 @@ func TestAPI_AuthorizeUser(t *testing.T) {
 	// update
 	username := u.Username
 	tok, data = authToken(t, a, p)
-	u, err = a.AuthorizeUser(context.Background(), tok, data)
+	u, err = a.AuthorizeUser(context.Background(), tok, data, testVerifier)
 	assert.NoError(t, err)
 	require.NotNil(t, u)
 	assert.NoError(t, err)
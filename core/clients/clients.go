@@ -0,0 +1,72 @@
+package clients
+
+import (
+	"errors"
+
+	"github.com/jrapoport/gothic/hasher"
+	"github.com/jrapoport/gothic/models/client"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/store"
+	"github.com/jrapoport/gothic/utils"
+)
+
+// CreateClient creates a new client. If the client is confidential, a
+// secret is generated for the client and returned. Only a hash of the
+// secret is stored, so it can not be returned again.
+func CreateClient(conn *store.Connection, name string, redirectURIs, scopes []string, confidential bool) (*client.Client, string, error) {
+	var secret string
+	var hash []byte
+	if confidential {
+		secret = utils.SecureToken() + utils.SecureToken()
+		var err error
+		hash, err = hasher.Hash(secret)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	c := client.NewClient(name, redirectURIs, scopes, hash)
+	err := conn.Create(c).Error
+	if err != nil {
+		return nil, "", err
+	}
+	return c, secret, nil
+}
+
+// GetClient returns the client with the client id.
+func GetClient(conn *store.Connection, clientID string) (*client.Client, error) {
+	if clientID == "" {
+		return nil, errors.New("invalid client id")
+	}
+	var c client.Client
+	err := conn.First(&c, key.ClientID+" = ?", clientID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// GetClients returns all the clients.
+func GetClients(conn *store.Connection) ([]*client.Client, error) {
+	var list []*client.Client
+	err := conn.Order("created_at").Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// DeleteClient deletes a client and all the consents users have given it.
+func DeleteClient(conn *store.Connection, clientID string) error {
+	return conn.Transaction(func(tx *store.Connection) error {
+		c, err := GetClient(tx, clientID)
+		if err != nil {
+			return err
+		}
+		err = tx.Where(key.ClientID+" = ?", c.ClientID).
+			Delete(&client.Consent{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(c).Error
+	})
+}
@@ -0,0 +1,88 @@
+package clients
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRedirect = "https://example.com/callback"
+
+var testScopes = []string{"openid", "profile", "email"}
+
+func TestCreateClient(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	_, _, err := CreateClient(conn, "", []string{testRedirect}, testScopes, true)
+	assert.Error(t, err)
+	_, _, err = CreateClient(conn, "test", nil, testScopes, true)
+	assert.Error(t, err)
+	_, _, err = CreateClient(conn, "test", []string{"/callback"}, testScopes, true)
+	assert.Error(t, err)
+	c, secret, err := CreateClient(conn, "test", []string{testRedirect}, testScopes, true)
+	require.NoError(t, err)
+	assert.NotEmpty(t, c.ClientID)
+	assert.NotEmpty(t, secret)
+	assert.True(t, c.Confidential())
+	assert.NoError(t, c.Authenticate(secret))
+	assert.Error(t, c.Authenticate(""))
+	assert.Equal(t, testScopes, c.ScopeList())
+	pub, secret, err := CreateClient(conn, "public", []string{testRedirect}, testScopes, false)
+	require.NoError(t, err)
+	assert.Empty(t, secret)
+	assert.False(t, pub.Confidential())
+	assert.NoError(t, pub.Authenticate(""))
+}
+
+func TestGetClient(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	c, _, err := CreateClient(conn, "test", []string{testRedirect}, testScopes, true)
+	require.NoError(t, err)
+	_, err = GetClient(conn, "")
+	assert.Error(t, err)
+	_, err = GetClient(conn, uuid.New().String())
+	assert.Error(t, err)
+	got, err := GetClient(conn, c.ClientID)
+	require.NoError(t, err)
+	assert.Equal(t, c.ID, got.ID)
+	assert.Equal(t, c.Name, got.Name)
+	assert.Equal(t, c.Secret, got.Secret)
+}
+
+func TestGetClients(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	list, err := GetClients(conn)
+	require.NoError(t, err)
+	assert.Len(t, list, 0)
+	const count = 3
+	for i := 0; i < count; i++ {
+		_, _, err = CreateClient(conn, "test", []string{testRedirect}, testScopes, true)
+		require.NoError(t, err)
+	}
+	list, err = GetClients(conn)
+	require.NoError(t, err)
+	assert.Len(t, list, count)
+}
+
+func TestDeleteClient(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	c, _, err := CreateClient(conn, "test", []string{testRedirect}, testScopes, true)
+	require.NoError(t, err)
+	uid := uuid.New()
+	_, err = GrantConsent(conn, uid, c.ClientID, testScopes)
+	require.NoError(t, err)
+	err = DeleteClient(conn, uuid.New().String())
+	assert.Error(t, err)
+	err = DeleteClient(conn, c.ClientID)
+	require.NoError(t, err)
+	_, err = GetClient(conn, c.ClientID)
+	assert.Error(t, err)
+	_, err = GetConsent(conn, uid, c.ClientID)
+	assert.Error(t, err)
+}
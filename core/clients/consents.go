@@ -0,0 +1,63 @@
+package clients
+
+import (
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/client"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/store"
+)
+
+// GrantConsent records the consent of the user for the client to access
+// the scopes. Scopes the user consented to previously are kept.
+func GrantConsent(conn *store.Connection, userID uuid.UUID, clientID string, scopes []string) (*client.Consent, error) {
+	c := client.NewConsent(userID, clientID, nil)
+	err := conn.Transaction(func(tx *store.Connection) error {
+		err := tx.FirstOrCreate(c, key.UserID+" = ? AND "+
+			key.ClientID+" = ?", userID, clientID).Error
+		if err != nil {
+			return err
+		}
+		if c.Covers(scopes) {
+			return nil
+		}
+		c.Grant(scopes)
+		return tx.Model(c).Update("scopes", c.Scopes).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// GetConsent returns the consent of the user for the client.
+func GetConsent(conn *store.Connection, userID uuid.UUID, clientID string) (*client.Consent, error) {
+	var c client.Consent
+	err := conn.First(&c, key.UserID+" = ? AND "+
+		key.ClientID+" = ?", userID, clientID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// GetConsents returns the consents of the user.
+func GetConsents(conn *store.Connection, userID uuid.UUID) ([]*client.Consent, error) {
+	var list []*client.Consent
+	err := conn.Where(key.UserID+" = ?", userID).
+		Order("created_at").Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// RevokeConsent deletes the consent of the user for the client.
+func RevokeConsent(conn *store.Connection, userID uuid.UUID, clientID string) error {
+	return conn.Transaction(func(tx *store.Connection) error {
+		c, err := GetConsent(tx, userID, clientID)
+		if err != nil {
+			return err
+		}
+		return tx.Delete(c).Error
+	})
+}
@@ -0,0 +1,72 @@
+package clients
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrantConsent(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	cid := uuid.New().String()
+	_, err := GrantConsent(conn, user.SystemID, cid, testScopes)
+	assert.Error(t, err)
+	_, err = GrantConsent(conn, uuid.New(), "", testScopes)
+	assert.Error(t, err)
+	uid := uuid.New()
+	c, err := GrantConsent(conn, uid, cid, []string{"openid"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"openid"}, c.ScopeList())
+	// previous scopes are kept
+	c2, err := GrantConsent(conn, uid, cid, []string{"email"})
+	require.NoError(t, err)
+	assert.Equal(t, c.ID, c2.ID)
+	assert.Equal(t, []string{"openid", "email"}, c2.ScopeList())
+	got, err := GetConsent(conn, uid, cid)
+	require.NoError(t, err)
+	assert.Equal(t, c2.Scopes, got.Scopes)
+	assert.True(t, got.Covers([]string{"email", "openid"}))
+	assert.False(t, got.Covers([]string{"profile"}))
+}
+
+func TestGetConsents(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	list, err := GetConsents(conn, uid)
+	require.NoError(t, err)
+	assert.Len(t, list, 0)
+	const count = 3
+	for i := 0; i < count; i++ {
+		_, err = GrantConsent(conn, uid, uuid.New().String(), testScopes)
+		require.NoError(t, err)
+	}
+	_, err = GrantConsent(conn, uuid.New(), uuid.New().String(), testScopes)
+	require.NoError(t, err)
+	list, err = GetConsents(conn, uid)
+	require.NoError(t, err)
+	assert.Len(t, list, count)
+}
+
+func TestRevokeConsent(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	cid := uuid.New().String()
+	err := RevokeConsent(conn, uid, cid)
+	assert.Error(t, err)
+	_, err = GrantConsent(conn, uid, cid, testScopes)
+	require.NoError(t, err)
+	err = RevokeConsent(conn, uid, cid)
+	require.NoError(t, err)
+	_, err = GetConsent(conn, uid, cid)
+	assert.Error(t, err)
+	// consent can be granted again
+	_, err = GrantConsent(conn, uid, cid, testScopes)
+	assert.NoError(t, err)
+}
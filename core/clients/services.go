@@ -0,0 +1,61 @@
+package clients
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/hasher"
+	"github.com/jrapoport/gothic/models/client"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/store"
+	"github.com/jrapoport/gothic/utils"
+)
+
+// CreateService creates a new service client owned by the user. A secret is
+// generated for the service client and returned. Only a hash of the secret is
+// stored, so it can not be returned again.
+func CreateService(conn *store.Connection, name string, scopes []string, ownerID uuid.UUID) (*client.Service, string, error) {
+	secret := utils.SecureToken() + utils.SecureToken()
+	hash, err := hasher.Hash(secret)
+	if err != nil {
+		return nil, "", err
+	}
+	s := client.NewService(name, scopes, ownerID, hash)
+	err = conn.Create(s).Error
+	if err != nil {
+		return nil, "", err
+	}
+	return s, secret, nil
+}
+
+// GetService returns the service client with the client id.
+func GetService(conn *store.Connection, clientID string) (*client.Service, error) {
+	if clientID == "" {
+		return nil, errors.New("invalid client id")
+	}
+	var s client.Service
+	err := conn.First(&s, key.ClientID+" = ?", clientID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// GetServices returns all the service clients.
+func GetServices(conn *store.Connection) ([]*client.Service, error) {
+	var list []*client.Service
+	err := conn.Order("created_at").Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// DeleteService deletes a service client.
+func DeleteService(conn *store.Connection, clientID string) error {
+	s, err := GetService(conn, clientID)
+	if err != nil {
+		return err
+	}
+	return conn.Delete(s).Error
+}
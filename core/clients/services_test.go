@@ -0,0 +1,78 @@
+package clients
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testServiceScopes = []string{"read", "write"}
+
+func TestCreateService(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	oid := uuid.New()
+	_, _, err := CreateService(conn, "", testServiceScopes, oid)
+	assert.Error(t, err)
+	_, _, err = CreateService(conn, "test", testServiceScopes, uuid.Nil)
+	assert.Error(t, err)
+	s, secret, err := CreateService(conn, "test", testServiceScopes, oid)
+	require.NoError(t, err)
+	assert.NotEmpty(t, s.ClientID)
+	assert.NotEmpty(t, secret)
+	assert.Equal(t, oid, s.OwnerID)
+	assert.NoError(t, s.Authenticate(secret))
+	assert.Error(t, s.Authenticate(""))
+	assert.Equal(t, testServiceScopes, s.ScopeList())
+}
+
+func TestGetService(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	s, _, err := CreateService(conn, "test", testServiceScopes, uuid.New())
+	require.NoError(t, err)
+	_, err = GetService(conn, "")
+	assert.Error(t, err)
+	_, err = GetService(conn, uuid.New().String())
+	assert.Error(t, err)
+	got, err := GetService(conn, s.ClientID)
+	require.NoError(t, err)
+	assert.Equal(t, s.ID, got.ID)
+	// an oauth client is not a service client
+	c, _, err := CreateClient(conn, "test", []string{testRedirect}, testScopes, true)
+	require.NoError(t, err)
+	_, err = GetService(conn, c.ClientID)
+	assert.Error(t, err)
+}
+
+func TestGetServices(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	list, err := GetServices(conn)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+	const count = 3
+	for i := 0; i < count; i++ {
+		_, _, err = CreateService(conn, "test", testServiceScopes, uuid.New())
+		require.NoError(t, err)
+	}
+	list, err = GetServices(conn)
+	require.NoError(t, err)
+	assert.Len(t, list, count)
+}
+
+func TestDeleteService(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	s, _, err := CreateService(conn, "test", testServiceScopes, uuid.New())
+	require.NoError(t, err)
+	err = DeleteService(conn, "")
+	assert.Error(t, err)
+	err = DeleteService(conn, s.ClientID)
+	assert.NoError(t, err)
+	_, err = GetService(conn, s.ClientID)
+	assert.Error(t, err)
+}
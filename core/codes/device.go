@@ -0,0 +1,116 @@
+package codes
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/code"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+	"github.com/jrapoport/gothic/utils"
+)
+
+// CreateDeviceCode creates a device code with a unique user code for the client.
+func CreateDeviceCode(conn *store.Connection, clientID, scope string, exp, interval time.Duration) (*code.DeviceCode, error) {
+	if clientID == "" {
+		return nil, errors.New("invalid client id")
+	}
+	var dc *code.DeviceCode
+	err := conn.Transaction(func(tx *store.Connection) error {
+		for {
+			dc = code.NewDeviceCode(clientID, scope, exp, interval)
+			has, err := dc.HasCode(tx)
+			if err != nil {
+				return err
+			}
+			if !has {
+				break
+			}
+		}
+		return tx.Create(dc).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dc, nil
+}
+
+// GetDeviceCode returns the device code for the device code string if found.
+func GetDeviceCode(conn *store.Connection, device string) (*code.DeviceCode, error) {
+	if device == "" {
+		return nil, errors.New("invalid device code")
+	}
+	dc := new(code.DeviceCode)
+	err := conn.First(dc, "device = ?", device).Error
+	if err != nil {
+		return nil, err
+	}
+	return dc, nil
+}
+
+// GetPendingDeviceCode returns the usable device code for the user code
+// if it has not been approved or denied. The user code is formatted first,
+// so it can be entered without a dash or in lowercase.
+func GetPendingDeviceCode(conn *store.Connection, userCode string) (*code.DeviceCode, error) {
+	userCode = utils.FormatUserCode(userCode)
+	if !utils.IsValidCode(userCode) {
+		return nil, errors.New("invalid code")
+	}
+	dc := new(code.DeviceCode)
+	err := conn.First(dc, "token = ?", userCode).Error
+	if err != nil {
+		return nil, err
+	}
+	if !dc.Usable() || !dc.Pending() {
+		return nil, code.ErrUnusableCode
+	}
+	return dc, nil
+}
+
+// PollDeviceCode records a poll of the device code. If the device polled
+// before its interval passed, the interval is increased by slowDown and
+// true is returned.
+func PollDeviceCode(conn *store.Connection, dc *code.DeviceCode, slowDown time.Duration) (bool, error) {
+	tooSoon := dc.PolledTooSoon()
+	if tooSoon {
+		dc.Interval += slowDown
+	}
+	now := time.Now().UTC()
+	dc.PolledAt = &now
+	err := conn.Model(dc).Updates(map[string]interface{}{
+		"polled_at": dc.PolledAt,
+		"interval":  dc.Interval,
+	}).Error
+	if err != nil {
+		return false, err
+	}
+	return tooSoon, nil
+}
+
+// ApproveDeviceCode approves the device code for the user.
+func ApproveDeviceCode(conn *store.Connection, dc *code.DeviceCode, userID uuid.UUID) error {
+	if userID == uuid.Nil || userID == user.SystemID {
+		return errors.New("invalid user id")
+	}
+	if !dc.Pending() {
+		return code.ErrUnusableCode
+	}
+	now := time.Now().UTC()
+	dc.UserID = userID
+	dc.ApprovedAt = &now
+	return conn.Model(dc).Updates(map[string]interface{}{
+		"user_id":     dc.UserID,
+		"approved_at": dc.ApprovedAt,
+	}).Error
+}
+
+// DenyDeviceCode denies the device code.
+func DenyDeviceCode(conn *store.Connection, dc *code.DeviceCode) error {
+	if !dc.Pending() {
+		return code.ErrUnusableCode
+	}
+	now := time.Now().UTC()
+	dc.DeniedAt = &now
+	return conn.Model(dc).Update("denied_at", dc.DeniedAt).Error
+}
@@ -0,0 +1,109 @@
+package codes
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/code"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateDeviceCode(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	_, err := CreateDeviceCode(conn, "", "", time.Minute, time.Second)
+	assert.Error(t, err)
+	dc, err := CreateDeviceCode(conn, "client", "openid", time.Minute, time.Second)
+	require.NoError(t, err)
+	assert.False(t, dc.CreatedAt.IsZero())
+	assert.Equal(t, user.SystemID, dc.UserID)
+	assert.Equal(t, code.UserCode, dc.Format)
+	assert.True(t, dc.Usable())
+	assert.True(t, dc.Pending())
+	got, err := GetDeviceCode(conn, dc.Device)
+	require.NoError(t, err)
+	assert.Equal(t, dc.ID, got.ID)
+	assert.Equal(t, "client", got.ClientID)
+	assert.Equal(t, "openid", got.Scope)
+	_, err = GetDeviceCode(conn, "")
+	assert.Error(t, err)
+	_, err = GetDeviceCode(conn, dc.Code())
+	assert.Error(t, err)
+}
+
+func TestGetPendingDeviceCode(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	dc, err := CreateDeviceCode(conn, "client", "", time.Minute, time.Second)
+	require.NoError(t, err)
+	for _, uc := range []string{
+		dc.Code(),
+		strings.ToLower(dc.Code()),
+		strings.ReplaceAll(dc.Code(), "-", ""),
+	} {
+		got, err := GetPendingDeviceCode(conn, uc)
+		require.NoError(t, err)
+		assert.Equal(t, dc.ID, got.ID)
+	}
+	_, err = GetPendingDeviceCode(conn, "")
+	assert.Error(t, err)
+	_, err = GetPendingDeviceCode(conn, dc.Device)
+	assert.Error(t, err)
+	// denied
+	err = DenyDeviceCode(conn, dc)
+	require.NoError(t, err)
+	_, err = GetPendingDeviceCode(conn, dc.Code())
+	assert.ErrorIs(t, err, code.ErrUnusableCode)
+	// expired
+	dc, err = CreateDeviceCode(conn, "client", "", time.Minute, time.Second)
+	require.NoError(t, err)
+	err = conn.Model(dc).Update("expired_at", time.Now().UTC().Add(-time.Minute)).Error
+	require.NoError(t, err)
+	_, err = GetPendingDeviceCode(conn, dc.Code())
+	assert.ErrorIs(t, err, code.ErrUnusableCode)
+}
+
+func TestPollDeviceCode(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	dc, err := CreateDeviceCode(conn, "client", "", time.Minute, time.Hour)
+	require.NoError(t, err)
+	slow, err := PollDeviceCode(conn, dc, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, slow)
+	assert.NotNil(t, dc.PolledAt)
+	slow, err = PollDeviceCode(conn, dc, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, slow)
+	got, err := GetDeviceCode(conn, dc.Device)
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour+time.Minute, got.Interval)
+	assert.NotNil(t, got.PolledAt)
+}
+
+func TestApproveDeviceCode(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	dc, err := CreateDeviceCode(conn, "client", "", time.Minute, time.Second)
+	require.NoError(t, err)
+	err = ApproveDeviceCode(conn, dc, uuid.Nil)
+	assert.Error(t, err)
+	err = ApproveDeviceCode(conn, dc, user.SystemID)
+	assert.Error(t, err)
+	uid := uuid.New()
+	err = ApproveDeviceCode(conn, dc, uid)
+	require.NoError(t, err)
+	got, err := GetDeviceCode(conn, dc.Device)
+	require.NoError(t, err)
+	assert.Equal(t, uid, got.UserID)
+	assert.True(t, got.Approved())
+	err = ApproveDeviceCode(conn, got, uid)
+	assert.ErrorIs(t, err, code.ErrUnusableCode)
+	err = DenyDeviceCode(conn, got)
+	assert.ErrorIs(t, err, code.ErrUnusableCode)
+}
@@ -14,6 +14,18 @@ type Context interface {
 	IPAddress() string
 	SetIPAddress(string)
 
+	UserAgent() string
+	SetUserAgent(string)
+
+	DeviceName() string
+	SetDeviceName(string)
+
+	SessionID() uuid.UUID
+	SetSessionID(uuid.UUID)
+
+	TokenID() string
+	SetTokenID(string)
+
 	Provider() provider.Name
 	SetProvider(provider.Name)
 
@@ -25,6 +37,14 @@ type Context interface {
 
 	IsAdmin() bool
 
+	ImpersonatorID() uuid.UUID
+	SetImpersonatorID(uuid.UUID)
+
+	Impersonated() bool
+
+	OrgID() uuid.UUID
+	SetOrgID(uuid.UUID)
+
 	Code() string
 	SetCode(string)
 
@@ -65,6 +85,62 @@ func (ctx *apiContext) SetIPAddress(ip string) {
 	ctx.setValue(ipKey{}, ip)
 }
 
+type userAgentKey struct{}
+
+func (ctx apiContext) UserAgent() string {
+	v, _ := ctx.Value(userAgentKey{}).(string)
+	return v
+}
+
+func (ctx *apiContext) SetUserAgent(ua string) {
+	if ua == "" {
+		return
+	}
+	ctx.setValue(userAgentKey{}, ua)
+}
+
+type deviceKey struct{}
+
+func (ctx apiContext) DeviceName() string {
+	v, _ := ctx.Value(deviceKey{}).(string)
+	return v
+}
+
+func (ctx *apiContext) SetDeviceName(name string) {
+	if name == "" {
+		return
+	}
+	ctx.setValue(deviceKey{}, name)
+}
+
+type sidKey struct{}
+
+func (ctx apiContext) SessionID() uuid.UUID {
+	v, _ := ctx.Value(sidKey{}).(uuid.UUID)
+	return v
+}
+
+func (ctx *apiContext) SetSessionID(sid uuid.UUID) {
+	if sid == uuid.Nil {
+		return
+	}
+	ctx.setValue(sidKey{}, sid)
+}
+
+type jtiKey struct{}
+
+func (ctx apiContext) TokenID() string {
+	v, _ := ctx.Value(jtiKey{}).(string)
+	return v
+}
+
+func (ctx *apiContext) SetTokenID(jti string) {
+	if jti == "" {
+		return
+	}
+	ctx.setValue(jtiKey{}, jti)
+}
+
 type providerKey struct{}
 
 func (ctx apiContext) Provider() provider.Name {
@@ -111,6 +187,38 @@ func (ctx apiContext) IsAdmin() bool {
 	return ctx.AdminID() != uuid.Nil
 }
 
+type impKey struct{}
+
+func (ctx apiContext) ImpersonatorID() uuid.UUID {
+	v, _ := ctx.Value(impKey{}).(uuid.UUID)
+	return v
+}
+
+func (ctx *apiContext) SetImpersonatorID(aid uuid.UUID) {
+	if aid == uuid.Nil {
+		return
+	}
+	ctx.setValue(impKey{}, aid)
+}
+
+func (ctx apiContext) Impersonated() bool {
+	return ctx.ImpersonatorID() != uuid.Nil
+}
+
+type orgKey struct{}
+
+func (ctx apiContext) OrgID() uuid.UUID {
+	v, _ := ctx.Value(orgKey{}).(uuid.UUID)
+	return v
+}
+
+func (ctx *apiContext) SetOrgID(oid uuid.UUID) {
+	if oid == uuid.Nil {
+		return
+	}
+	ctx.setValue(orgKey{}, oid)
+}
+
 type codeKey struct{}
 
 func (ctx apiContext) Code() string {
@@ -20,6 +20,12 @@ func TestFromContext(t *testing.T) {
 		recaptcha = utils.SecureToken()
 		sort      = store.Descending
 		token     = utils.SecureToken()
+		agent     = "Mozilla/5.0"
+		device    = "laptop"
+		sid       = uuid.New()
+		jti       = uuid.New().String()
+		oid       = uuid.New()
+		imp       = uuid.New()
 	)
 	ctx := Background()
 	assert.NotNil(t, ctx)
@@ -30,6 +36,12 @@ func TestFromContext(t *testing.T) {
 	ctx.SetCode(token)
 	ctx.SetUserID(uid)
 	ctx.SetAdminID(aid)
+	ctx.SetUserAgent(agent)
+	ctx.SetDeviceName(device)
+	ctx.SetSessionID(sid)
+	ctx.SetTokenID(jti)
+	ctx.SetOrgID(oid)
+	ctx.SetImpersonatorID(imp)
 	assert.Equal(t, ip, ctx.IPAddress())
 	assert.Equal(t, prov, ctx.Provider())
 	assert.Equal(t, recaptcha, ctx.ReCaptcha())
@@ -37,6 +49,13 @@ func TestFromContext(t *testing.T) {
 	assert.Equal(t, token, ctx.Code())
 	assert.Equal(t, uid, ctx.UserID())
 	assert.Equal(t, aid, ctx.AdminID())
+	assert.Equal(t, agent, ctx.UserAgent())
+	assert.Equal(t, device, ctx.DeviceName())
+	assert.Equal(t, sid, ctx.SessionID())
+	assert.Equal(t, jti, ctx.TokenID())
+	assert.Equal(t, oid, ctx.OrgID())
+	assert.Equal(t, imp, ctx.ImpersonatorID())
+	assert.True(t, ctx.Impersonated())
 	ctx = Background()
 	assert.NotNil(t, ctx)
 	ctx.SetIPAddress("")
@@ -46,6 +65,12 @@ func TestFromContext(t *testing.T) {
 	ctx.SetCode("")
 	ctx.SetUserID(uuid.Nil)
 	ctx.SetAdminID(uuid.Nil)
+	ctx.SetUserAgent("")
+	ctx.SetDeviceName("")
+	ctx.SetSessionID(uuid.Nil)
+	ctx.SetTokenID("")
+	ctx.SetOrgID(uuid.Nil)
+	ctx.SetImpersonatorID(uuid.Nil)
 	assert.Equal(t, "", ctx.IPAddress())
 	assert.EqualValues(t, "", ctx.Provider())
 	assert.Equal(t, "", ctx.ReCaptcha())
@@ -53,6 +78,13 @@ func TestFromContext(t *testing.T) {
 	assert.Equal(t, "", ctx.Code())
 	assert.Equal(t, uuid.Nil, ctx.UserID())
 	assert.Equal(t, uuid.Nil, ctx.AdminID())
+	assert.Equal(t, "", ctx.UserAgent())
+	assert.Equal(t, "", ctx.DeviceName())
+	assert.Equal(t, uuid.Nil, ctx.SessionID())
+	assert.Equal(t, "", ctx.TokenID())
+	assert.Equal(t, uuid.Nil, ctx.OrgID())
+	assert.Equal(t, uuid.Nil, ctx.ImpersonatorID())
+	assert.False(t, ctx.Impersonated())
 	ctx = WithValue(ctx, "foo", "bar")
 	v := ctx.Value("foo")
 	assert.Equal(t, "bar", v.(string))
@@ -0,0 +1,123 @@
+package credentials
+
+import (
+	"errors"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/users"
+	"github.com/jrapoport/gothic/models/credential"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+)
+
+// User wraps a user and their credentials as a webauthn user.
+type User struct {
+	*user.User
+	Credentials []*credential.Credential
+}
+
+var _ webauthn.User = (*User)(nil)
+
+// WebAuthnID returns the user handle for the user.
+func (u User) WebAuthnID() []byte {
+	id := u.ID
+	return id[:]
+}
+
+// WebAuthnName returns the name of the user.
+func (u User) WebAuthnName() string {
+	return u.Email
+}
+
+// WebAuthnDisplayName returns the display name of the user.
+func (u User) WebAuthnDisplayName() string {
+	if u.Username != "" {
+		return u.Username
+	}
+	return u.Email
+}
+
+// WebAuthnCredentials returns the webauthn credentials for the user.
+func (u User) WebAuthnCredentials() []webauthn.Credential {
+	creds := make([]webauthn.Credential, len(u.Credentials))
+	for i, c := range u.Credentials {
+		creds[i] = c.WebAuthn()
+	}
+	return creds
+}
+
+// Credential returns the user credential for the raw credential id.
+func (u User) Credential(id []byte) (*credential.Credential, error) {
+	credentialID := credential.EncodeID(id)
+	for _, c := range u.Credentials {
+		if c.CredentialID == credentialID {
+			return c, nil
+		}
+	}
+	return nil, errors.New("credential not found")
+}
+
+// GetUser returns an active user and their credentials.
+func GetUser(conn *store.Connection, userID uuid.UUID) (*User, error) {
+	u, err := users.GetActiveUser(conn, userID)
+	if err != nil {
+		return nil, err
+	}
+	creds, err := GetCredentials(conn, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &User{u, creds}, nil
+}
+
+// GetCredentials returns the credentials for the user.
+func GetCredentials(conn *store.Connection, userID uuid.UUID) ([]*credential.Credential, error) {
+	var creds []*credential.Credential
+	err := conn.Where(key.UserID+" = ?", userID).
+		Order("created_at").Find(&creds).Error
+	if err != nil {
+		return nil, err
+	}
+	return creds, nil
+}
+
+// GetCredential returns the user credential with the credential id.
+func GetCredential(conn *store.Connection, userID uuid.UUID, credentialID string) (*credential.Credential, error) {
+	var c credential.Credential
+	err := conn.First(&c, key.UserID+" = ? AND credential_id = ?",
+		userID, credentialID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// RenameCredential changes the name of a user credential.
+func RenameCredential(conn *store.Connection, userID uuid.UUID, credentialID, name string) (*credential.Credential, error) {
+	var c *credential.Credential
+	err := conn.Transaction(func(tx *store.Connection) (err error) {
+		c, err = GetCredential(tx, userID, credentialID)
+		if err != nil {
+			return err
+		}
+		c.Name = name
+		return tx.Model(c).Update(key.Name, name).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// DeleteCredential deletes a user credential.
+func DeleteCredential(conn *store.Connection, userID uuid.UUID, credentialID string) error {
+	return conn.Transaction(func(tx *store.Connection) error {
+		c, err := GetCredential(tx, userID, credentialID)
+		if err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(c).Error
+	})
+}
@@ -0,0 +1,185 @@
+package credentials
+
+import (
+	"testing"
+	"time"
+
+	"github.com/descope/virtualwebauthn"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/core/users"
+	"github.com/jrapoport/gothic/models/credential"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/jrapoport/gothic/test/tutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testExpiration = time.Minute
+
+type testAuthenticator struct {
+	virtualwebauthn.Authenticator
+	rp   virtualwebauthn.RelyingParty
+	cred virtualwebauthn.Credential
+}
+
+func newTestAuthenticator(c *config.Config) *testAuthenticator {
+	return &testAuthenticator{
+		Authenticator: virtualwebauthn.NewAuthenticator(),
+		rp: virtualwebauthn.RelyingParty{
+			ID:     c.WebAuthn.RPID,
+			Name:   c.WebAuthn.RPName,
+			Origin: c.WebAuthn.Origins[0],
+		},
+		cred: virtualwebauthn.NewCredential(virtualwebauthn.KeyTypeEC2),
+	}
+}
+
+func (a *testAuthenticator) attest(t *testing.T, options []byte) []byte {
+	opts, err := virtualwebauthn.ParseAttestationOptions(string(options))
+	require.NoError(t, err)
+	res := virtualwebauthn.CreateAttestationResponse(a.rp, a.Authenticator, a.cred, *opts)
+	a.Options.UserHandle = []byte(opts.UserID)
+	return []byte(res)
+}
+
+func (a *testAuthenticator) assert(t *testing.T, options []byte) []byte {
+	opts, err := virtualwebauthn.ParseAssertionOptions(string(options))
+	require.NoError(t, err)
+	res := virtualwebauthn.CreateAssertionResponse(a.rp, a.Authenticator, a.cred, *opts)
+	return []byte(res)
+}
+
+func testWebAuthn(t *testing.T, c *config.Config) *webauthn.WebAuthn {
+	wa, err := webauthn.New(&webauthn.Config{
+		RPID:          c.WebAuthn.RPID,
+		RPDisplayName: c.WebAuthn.RPName,
+		RPOrigins:     c.WebAuthn.Origins,
+	})
+	require.NoError(t, err)
+	return wa
+}
+
+func testUser(t *testing.T, conn *store.Connection, c *config.Config) *User {
+	u, err := users.CreateUser(conn, c.Provider(), tutils.RandomEmail(), "", "", nil, nil)
+	require.NoError(t, err)
+	err = users.ConfirmUser(conn, u, time.Now())
+	require.NoError(t, err)
+	wu, err := GetUser(conn, u.ID)
+	require.NoError(t, err)
+	return wu
+}
+
+func testCredential(t *testing.T, conn *store.Connection, userID uuid.UUID) *credential.Credential {
+	c := credential.NewCredential(userID, "", &webauthn.Credential{
+		ID:        []byte(uuid.New().String()),
+		PublicKey: []byte("public-key"),
+	})
+	err := conn.Create(c).Error
+	require.NoError(t, err)
+	return c
+}
+
+func TestUser(t *testing.T) {
+	t.Parallel()
+	u := &User{User: &user.User{
+		ID:    uuid.New(),
+		Email: tutils.RandomEmail(),
+	}}
+	assert.Equal(t, u.ID[:], u.WebAuthnID())
+	assert.Equal(t, u.Email, u.WebAuthnName())
+	assert.Equal(t, u.Email, u.WebAuthnDisplayName())
+	u.Username = "peaches"
+	assert.Equal(t, u.Username, u.WebAuthnDisplayName())
+	assert.Empty(t, u.WebAuthnCredentials())
+	c := credential.NewCredential(u.ID, "", &webauthn.Credential{
+		ID:        []byte("credential-id"),
+		PublicKey: []byte("public-key"),
+	})
+	u.Credentials = append(u.Credentials, c)
+	assert.Len(t, u.WebAuthnCredentials(), 1)
+	test, err := u.Credential([]byte("credential-id"))
+	assert.NoError(t, err)
+	assert.Equal(t, c, test)
+	_, err = u.Credential([]byte("bad"))
+	assert.Error(t, err)
+}
+
+func TestGetUser(t *testing.T) {
+	t.Parallel()
+	conn, c := tconn.TempConn(t)
+	u := testUser(t, conn, c)
+	assert.Empty(t, u.Credentials)
+	cred := testCredential(t, conn, u.ID)
+	u, err := GetUser(conn, u.ID)
+	assert.NoError(t, err)
+	require.Len(t, u.Credentials, 1)
+	assert.Equal(t, cred.CredentialID, u.Credentials[0].CredentialID)
+	_, err = GetUser(conn, uuid.New())
+	assert.Error(t, err)
+}
+
+func TestGetCredentials(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	creds, err := GetCredentials(conn, uid)
+	assert.NoError(t, err)
+	assert.Empty(t, creds)
+	const count = 3
+	for i := 0; i < count; i++ {
+		testCredential(t, conn, uid)
+	}
+	testCredential(t, conn, uuid.New())
+	creds, err = GetCredentials(conn, uid)
+	assert.NoError(t, err)
+	assert.Len(t, creds, count)
+}
+
+func TestGetCredential(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	c := testCredential(t, conn, uid)
+	test, err := GetCredential(conn, uid, c.CredentialID)
+	assert.NoError(t, err)
+	assert.Equal(t, c.ID, test.ID)
+	_, err = GetCredential(conn, uuid.New(), c.CredentialID)
+	assert.Error(t, err)
+	_, err = GetCredential(conn, uid, "")
+	assert.Error(t, err)
+}
+
+func TestRenameCredential(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	c := testCredential(t, conn, uid)
+	const name = "security key"
+	test, err := RenameCredential(conn, uid, c.CredentialID, name)
+	assert.NoError(t, err)
+	assert.Equal(t, name, test.Name)
+	test, err = GetCredential(conn, uid, c.CredentialID)
+	assert.NoError(t, err)
+	assert.Equal(t, name, test.Name)
+	_, err = RenameCredential(conn, uuid.New(), c.CredentialID, name)
+	assert.Error(t, err)
+}
+
+func TestDeleteCredential(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	c := testCredential(t, conn, uid)
+	err := DeleteCredential(conn, uuid.New(), c.CredentialID)
+	assert.Error(t, err)
+	err = DeleteCredential(conn, uid, c.CredentialID)
+	assert.NoError(t, err)
+	_, err = GetCredential(conn, uid, c.CredentialID)
+	assert.Error(t, err)
+	err = DeleteCredential(conn, uid, c.CredentialID)
+	assert.Error(t, err)
+}
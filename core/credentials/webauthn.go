@@ -0,0 +1,177 @@
+package credentials
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/models/credential"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+)
+
+// Ceremony holds a pending webauthn registration or login.
+type Ceremony struct {
+	// Token identifies the ceremony and must be returned to finish it.
+	Token string
+	// Options are the json encoded options for the client.
+	Options json.RawMessage
+	// ExpiresAt is the time the ceremony expires.
+	ExpiresAt time.Time
+}
+
+// BeginRegistration starts a webauthn registration for the user.
+func BeginRegistration(conn *store.Connection, wa *webauthn.WebAuthn,
+	u *User, exp time.Duration) (*Ceremony, error) {
+	exclude := make([]protocol.CredentialDescriptor, len(u.Credentials))
+	for i, c := range u.Credentials {
+		exclude[i] = c.WebAuthn().Descriptor()
+	}
+	opts, session, err := wa.BeginRegistration(u,
+		webauthn.WithExclusions(exclude),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementPreferred))
+	if err != nil {
+		return nil, err
+	}
+	return newCeremony(conn, u.ID, opts, session, exp)
+}
+
+// FinishRegistration finishes a webauthn registration with the session
+// of the used ceremony token and saves the new credential.
+func FinishRegistration(conn *store.Connection, wa *webauthn.WebAuthn, u *User,
+	wt *token.WebAuthnToken, session *webauthn.SessionData, name string, response []byte) (*credential.Credential, error) {
+	if wt.UserID != u.ID {
+		return nil, errors.New("invalid token")
+	}
+	res, err := protocol.ParseCredentialCreationResponseBytes(response)
+	if err != nil {
+		return nil, err
+	}
+	wc, err := wa.CreateCredential(u, *session, res)
+	if err != nil {
+		return nil, err
+	}
+	c := credential.NewCredential(u.ID, name, wc)
+	err = conn.Create(c).Error
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// BeginLogin starts a webauthn login. If the user is nil, a discoverable
+// login is started and the user is identified by their passkey.
+func BeginLogin(conn *store.Connection, wa *webauthn.WebAuthn,
+	u *User, exp time.Duration) (*Ceremony, error) {
+	if u == nil {
+		opts, session, err := wa.BeginDiscoverableLogin()
+		if err != nil {
+			return nil, err
+		}
+		return newCeremony(conn, uuid.Nil, opts, session, exp)
+	}
+	if len(u.Credentials) <= 0 {
+		return nil, errors.New("no credentials")
+	}
+	opts, session, err := wa.BeginLogin(u)
+	if err != nil {
+		return nil, err
+	}
+	return newCeremony(conn, u.ID, opts, session, exp)
+}
+
+// FinishLogin finishes a webauthn login with the session of
+// the used ceremony token and returns the user.
+func FinishLogin(conn *store.Connection, wa *webauthn.WebAuthn,
+	wt *token.WebAuthnToken, session *webauthn.SessionData, response []byte) (*user.User, error) {
+	res, err := protocol.ParseCredentialRequestResponseBytes(response)
+	if err != nil {
+		return nil, err
+	}
+	var u *User
+	var wc *webauthn.Credential
+	if wt.UserID == user.SystemID {
+		handler := func(_, userHandle []byte) (webauthn.User, error) {
+			userID, err := uuid.FromBytes(userHandle)
+			if err != nil {
+				return nil, err
+			}
+			u, err = GetUser(conn, userID)
+			return u, err
+		}
+		wc, err = wa.ValidateDiscoverableLogin(handler, *session, res)
+	} else {
+		u, err = GetUser(conn, wt.UserID)
+		if err != nil {
+			return nil, err
+		}
+		wc, err = wa.ValidateLogin(u, *session, res)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if wc.Authenticator.CloneWarning {
+		return nil, errors.New("possible cloned credential")
+	}
+	c, err := u.Credential(wc.ID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	err = conn.Transaction(func(tx *store.Connection) error {
+		c.Update(wc)
+		c.LastUsedAt = &now
+		err = tx.Save(c).Error
+		if err != nil {
+			return err
+		}
+		u.LoginAt = &now
+		return tx.Model(u.User).Update("login_at", u.LoginAt).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return u.User, nil
+}
+
+func newCeremony(conn *store.Connection, userID uuid.UUID, opts interface{},
+	session *webauthn.SessionData, exp time.Duration) (*Ceremony, error) {
+	b, err := json.Marshal(opts)
+	if err != nil {
+		return nil, err
+	}
+	s, err := json.Marshal(session)
+	if err != nil {
+		return nil, err
+	}
+	wt, err := tokens.GrantWebAuthnToken(conn, userID, s, exp)
+	if err != nil {
+		return nil, err
+	}
+	return &Ceremony{
+		Token:     wt.Token,
+		Options:   b,
+		ExpiresAt: wt.ExpirationDate(),
+	}, nil
+}
+
+// UseCeremony burns the ceremony token and returns its session. Failed
+// attempts must also burn the token, so it is used before the ceremony
+// is finished and outside of the transaction that finishes it.
+func UseCeremony(conn *store.Connection, wt *token.WebAuthnToken) (*webauthn.SessionData, error) {
+	err := tokens.UseToken(conn, wt)
+	if err != nil {
+		return nil, err
+	}
+	var session webauthn.SessionData
+	err = json.Unmarshal(wt.Session, &session)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
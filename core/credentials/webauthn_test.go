@@ -0,0 +1,142 @@
+package credentials
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/store"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ceremonyToken(t *testing.T, conn *store.Connection, c *Ceremony) *token.WebAuthnToken {
+	wt, err := tokens.GetWebAuthnToken(conn, c.Token)
+	require.NoError(t, err)
+	return wt
+}
+
+func useCeremony(t *testing.T, conn *store.Connection, wt *token.WebAuthnToken) *webauthn.SessionData {
+	session, err := UseCeremony(conn, wt)
+	require.NoError(t, err)
+	return session
+}
+
+func TestUseCeremony(t *testing.T) {
+	t.Parallel()
+	conn, c := tconn.TempConn(t)
+	wa := testWebAuthn(t, c)
+	u := testUser(t, conn, c)
+	cer, err := BeginRegistration(conn, wa, u, testExpiration)
+	require.NoError(t, err)
+	wt := ceremonyToken(t, conn, cer)
+	// failed attempts are not rolled back with the transaction
+	err = conn.Transaction(func(tx *store.Connection) error {
+		_, err = UseCeremony(conn, wt)
+		require.NoError(t, err)
+		return errors.New("rollback")
+	})
+	assert.Error(t, err)
+	_, err = tokens.GetWebAuthnToken(conn, cer.Token)
+	assert.Error(t, err)
+	// tokens are single use
+	_, err = UseCeremony(conn, wt)
+	assert.Error(t, err)
+}
+
+func TestRegistration(t *testing.T) {
+	t.Parallel()
+	conn, c := tconn.TempConn(t)
+	wa := testWebAuthn(t, c)
+	u := testUser(t, conn, c)
+	auth := newTestAuthenticator(c)
+	cer, err := BeginRegistration(conn, wa, u, testExpiration)
+	require.NoError(t, err)
+	require.NotNil(t, cer)
+	assert.NotEmpty(t, cer.Token)
+	assert.NotEmpty(t, cer.Options)
+	assert.False(t, cer.ExpiresAt.IsZero())
+	wt := ceremonyToken(t, conn, cer)
+	session := useCeremony(t, conn, wt)
+	res := auth.attest(t, cer.Options)
+	// wrong user
+	_, err = FinishRegistration(conn, wa, testUser(t, conn, c), wt, session, "", res)
+	assert.Error(t, err)
+	// bad response
+	_, err = FinishRegistration(conn, wa, u, wt, session, "", []byte("{}"))
+	assert.Error(t, err)
+	cer, err = BeginRegistration(conn, wa, u, testExpiration)
+	require.NoError(t, err)
+	wt = ceremonyToken(t, conn, cer)
+	res = auth.attest(t, cer.Options)
+	cred, err := FinishRegistration(conn, wa, u, wt, useCeremony(t, conn, wt), "passkey", res)
+	require.NoError(t, err)
+	assert.Equal(t, u.ID, cred.UserID)
+	assert.Equal(t, "passkey", cred.Name)
+	u, err = GetUser(conn, u.ID)
+	require.NoError(t, err)
+	assert.Len(t, u.Credentials, 1)
+	// existing credentials are excluded
+	cer, err = BeginRegistration(conn, wa, u, testExpiration)
+	require.NoError(t, err)
+	assert.Contains(t, string(cer.Options), cred.CredentialID)
+	// duplicate credential
+	wt = ceremonyToken(t, conn, cer)
+	res = auth.attest(t, cer.Options)
+	_, err = FinishRegistration(conn, wa, u, wt, useCeremony(t, conn, wt), "", res)
+	assert.Error(t, err)
+}
+
+func TestLogin(t *testing.T) {
+	t.Parallel()
+	conn, c := tconn.TempConn(t)
+	wa := testWebAuthn(t, c)
+	u := testUser(t, conn, c)
+	// no credentials
+	_, err := BeginLogin(conn, wa, u, testExpiration)
+	assert.Error(t, err)
+	auth := newTestAuthenticator(c)
+	cer, err := BeginRegistration(conn, wa, u, testExpiration)
+	require.NoError(t, err)
+	wt := ceremonyToken(t, conn, cer)
+	_, err = FinishRegistration(conn, wa, u, wt, useCeremony(t, conn, wt),
+		"", auth.attest(t, cer.Options))
+	require.NoError(t, err)
+	u, err = GetUser(conn, u.ID)
+	require.NoError(t, err)
+	login := func(lu *User) error {
+		cer, err := BeginLogin(conn, wa, lu, testExpiration)
+		require.NoError(t, err)
+		wt := ceremonyToken(t, conn, cer)
+		session := useCeremony(t, conn, wt)
+		test, err := FinishLogin(conn, wa, wt, session, auth.assert(t, cer.Options))
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, u.ID, test.ID)
+		assert.NotNil(t, test.LoginAt)
+		return nil
+	}
+	// user login
+	err = login(u)
+	assert.NoError(t, err)
+	// discoverable login
+	err = login(nil)
+	assert.NoError(t, err)
+	cred, err := GetCredential(conn, u.ID, u.Credentials[0].CredentialID)
+	require.NoError(t, err)
+	assert.NotNil(t, cred.LastUsedAt)
+	// unknown user handle
+	auth.Options.UserHandle = []byte("bad")
+	err = login(nil)
+	assert.Error(t, err)
+	// bad response
+	cer, err = BeginLogin(conn, wa, u, testExpiration)
+	require.NoError(t, err)
+	wt = ceremonyToken(t, conn, cer)
+	_, err = FinishLogin(conn, wa, wt, useCeremony(t, conn, wt), []byte("{}"))
+	assert.Error(t, err)
+}
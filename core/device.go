@@ -0,0 +1,265 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/audit"
+	"github.com/jrapoport/gothic/core/clients"
+	"github.com/jrapoport/gothic/core/codes"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/core/oidc"
+	"github.com/jrapoport/gothic/core/roles"
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/core/users"
+	"github.com/jrapoport/gothic/models/client"
+	"github.com/jrapoport/gothic/models/code"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+)
+
+// deviceSlowDown is added to the interval of a device each time it polls too soon.
+const deviceSlowDown = 5 * time.Second
+
+// AuthorizeDevice starts a device authorization request for a client. The device
+// shows the user code and the verification uri to the user, and polls the token
+// endpoint with the device code until the user approves or denies the request.
+func (a *API) AuthorizeDevice(ctx context.Context, req *oidc.DeviceAuthorizationRequest) (*oidc.DeviceAuthorizationResponse, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if req == nil {
+		err := oidc.NewError(oidc.InvalidRequest, "invalid request")
+		return nil, a.logError(err)
+	}
+	c, err := a.authenticateClient(req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+	scopes := oidc.ParseScope(req.Scope)
+	if !oidc.Supported(scopes) || !c.AllowsScopes(scopes) {
+		err = oidc.NewError(oidc.InvalidScope, "")
+		return nil, a.logError(err)
+	}
+	exp := a.config.OIDC.DeviceExpiration
+	interval := a.config.OIDC.DeviceInterval
+	dc, err := codes.CreateDeviceCode(a.conn, c.ClientID, oidc.FormatScope(scopes), exp, interval)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	uri := oidc.VerificationURI(a.config)
+	complete, err := url.Parse(uri)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	q := complete.Query()
+	q.Set(key.UserCode, dc.Code())
+	complete.RawQuery = q.Encode()
+	a.log.Debugf("authorize device %s: %s", c.ClientID, dc.Code())
+	return &oidc.DeviceAuthorizationResponse{
+		DeviceCode:              dc.Device,
+		UserCode:                dc.Code(),
+		VerificationURI:         uri,
+		VerificationURIComplete: complete.String(),
+		ExpiresIn:               int(exp.Seconds()),
+		Interval:                int(interval.Seconds()),
+	}, nil
+}
+
+// GetDeviceAuthorization returns the details of the pending device authorization
+// request for the user code that should be shown to the user before they approve it.
+func (a *API) GetDeviceAuthorization(ctx context.Context, userID uuid.UUID, userCode string) (*oidc.Authorization, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var auth *oidc.Authorization
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		_, err := deviceUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		dc, c, err := a.deviceClient(tx, userCode)
+		if err != nil {
+			return err
+		}
+		auth = &oidc.Authorization{
+			ClientID:   c.ClientID,
+			ClientName: c.Name,
+			Scopes:     oidc.ParseScope(dc.Scope),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	return auth, nil
+}
+
+// ApproveDevice completes the pending device authorization request for the user
+// code. If the user approved the request, the device is granted a bearer token
+// for the user the next time it polls the token endpoint.
+func (a *API) ApproveDevice(ctx context.Context, userID uuid.UUID, userCode string, approved bool) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	err := checkImpersonation(ctx)
+	if err != nil {
+		return a.logError(err)
+	}
+	err = a.conn.Transaction(func(tx *store.Connection) error {
+		u, err := deviceUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		dc, _, err := a.deviceClient(tx, userCode)
+		if err != nil {
+			return err
+		}
+		if !approved {
+			return codes.DenyDeviceCode(tx, dc)
+		}
+		err = codes.ApproveDeviceCode(tx, dc, u.ID)
+		if err != nil {
+			return err
+		}
+		return audit.LogDeviceApproved(ctx, tx, dc)
+	})
+	if err != nil {
+		return a.logError(err)
+	}
+	a.log.Debugf("approved device %s: %s (%t)", userCode, userID, approved)
+	return nil
+}
+
+// deviceUser returns the active user for a device authorization. The device
+// is granted a session token that is not limited to the scopes it requested,
+// so users with admin or role permissions cannot authorize devices.
+func deviceUser(tx *store.Connection, userID uuid.UUID) (*user.User, error) {
+	u, err := users.GetActiveUser(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if u.IsAdmin() {
+		return nil, fmt.Errorf("cannot authorize device for admin: %s", u.ID)
+	}
+	perms, err := roles.GetPermissions(tx, u)
+	if err != nil {
+		return nil, err
+	}
+	if len(perms) > 0 {
+		return nil, fmt.Errorf("cannot authorize device for privileged user: %s", u.ID)
+	}
+	return u, nil
+}
+
+// deviceClient returns the pending device code for the user code and its client.
+func (a *API) deviceClient(tx *store.Connection, userCode string) (*code.DeviceCode, *client.Client, error) {
+	dc, err := codes.GetPendingDeviceCode(tx, userCode)
+	if err != nil {
+		a.logError(err)
+		return nil, nil, oidc.NewError(oidc.InvalidRequest, "invalid user code")
+	}
+	c, err := clients.GetClient(tx, dc.ClientID)
+	if err != nil {
+		a.logError(err)
+		return nil, nil, oidc.NewError(oidc.InvalidClient, "client not found")
+	}
+	return dc, c, nil
+}
+
+// GrantDeviceCode exchanges an approved device code for a bearer token for the
+// user that approved it. Until the device code is approved, the device is told
+// to keep polling, and to slow down if it polls faster than its interval.
+func (a *API) GrantDeviceCode(ctx context.Context, req *oidc.TokenRequest) (*oidc.TokenResponse, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if req == nil {
+		err := oidc.NewError(oidc.InvalidRequest, "invalid request")
+		return nil, a.logError(err)
+	}
+	if req.GrantType != oidc.GrantDeviceCode {
+		err := oidc.NewError(oidc.UnsupportedGrantType, "")
+		return nil, a.logError(err)
+	}
+	c, err := a.authenticateClient(req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+	dc, err := a.pollDeviceCode(c, req.DeviceCode)
+	if err != nil {
+		return nil, err
+	}
+	var res *oidc.TokenResponse
+	err = a.conn.Transaction(func(tx *store.Connection) error {
+		u, err := deviceUser(tx, dc.UserID)
+		if err != nil {
+			a.logError(err)
+			return oidc.NewError(oidc.InvalidGrant, "invalid user")
+		}
+		err = tokens.UseToken(tx, dc)
+		if err != nil {
+			a.logError(err)
+			return oidc.NewError(oidc.InvalidGrant, "invalid device code")
+		}
+		bt, err := tokens.GrantBearerToken(tx, a.config.JWT, a.config.Refresh, u, newSession(ctx))
+		if err != nil {
+			return err
+		}
+		err = audit.LogTokenGranted(ctx, tx, bt)
+		if err != nil {
+			return err
+		}
+		res = &oidc.TokenResponse{
+			AccessToken:  bt.String(),
+			TokenType:    oidc.TokenTypeBearer,
+			ExpiresIn:    int(bt.Expiration.Seconds()),
+			RefreshToken: bt.RefreshToken.String(),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	a.log.Debugf("granted device token %s: %s", c.ClientID, dc.UserID)
+	return res, nil
+}
+
+// pollDeviceCode records a poll of the device code issued to the client and
+// returns the device code if it was approved. If the device code was not
+// approved, the error tells the device if it should keep polling.
+func (a *API) pollDeviceCode(c *client.Client, device string) (*code.DeviceCode, error) {
+	var dc *code.DeviceCode
+	var slow bool
+	err := a.conn.Transaction(func(tx *store.Connection) (err error) {
+		dc, err = codes.GetDeviceCode(tx, device)
+		if err != nil {
+			return err
+		}
+		if dc.ClientID != c.ClientID {
+			return errors.New("invalid client id")
+		}
+		slow, err = codes.PollDeviceCode(tx, dc, deviceSlowDown)
+		return err
+	})
+	if err != nil {
+		a.logError(err)
+		err = oidc.NewError(oidc.InvalidGrant, "invalid device code")
+		return nil, err
+	}
+	switch {
+	case !dc.Usable():
+		return nil, oidc.NewError(oidc.ExpiredToken, "")
+	case dc.Denied():
+		return nil, oidc.NewError(oidc.AccessDenied, "the user denied the request")
+	case slow:
+		return nil, oidc.NewError(oidc.SlowDown, "")
+	case dc.Pending():
+		return nil, oidc.NewError(oidc.AuthorizationPending, "")
+	}
+	return dc, nil
+}
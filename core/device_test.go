@@ -0,0 +1,242 @@
+package core
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/codes"
+	"github.com/jrapoport/gothic/core/oidc"
+	"github.com/jrapoport/gothic/core/roles"
+	"github.com/jrapoport/gothic/core/users"
+	"github.com/jrapoport/gothic/jwt"
+	"github.com/jrapoport/gothic/models/auditlog"
+	"github.com/jrapoport/gothic/models/client"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testDeviceRequest(t *testing.T, a *API, c *client.Client, secret string) *oidc.DeviceAuthorizationResponse {
+	res, err := a.AuthorizeDevice(testContext(a), &oidc.DeviceAuthorizationRequest{
+		ClientID:     c.ClientID,
+		ClientSecret: secret,
+		Scope:        "openid profile",
+	})
+	require.NoError(t, err)
+	return res
+}
+
+func testDeviceToken(c *client.Client, device string) *oidc.TokenRequest {
+	return &oidc.TokenRequest{
+		GrantType:  oidc.GrantDeviceCode,
+		ClientID:   c.ClientID,
+		DeviceCode: device,
+	}
+}
+
+func TestAPI_AuthorizeDevice(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	c, _ := testClient(t, a, false)
+	cc, secret := testClient(t, a, true)
+	ctx := testContext(a)
+	_, err := a.AuthorizeDevice(ctx, nil)
+	assert.Equal(t, oidc.InvalidRequest, oauthErrorCode(t, err))
+	_, err = a.AuthorizeDevice(ctx, &oidc.DeviceAuthorizationRequest{
+		ClientID: uuid.New().String(),
+	})
+	assert.Equal(t, oidc.InvalidClient, oauthErrorCode(t, err))
+	_, err = a.AuthorizeDevice(ctx, &oidc.DeviceAuthorizationRequest{
+		ClientID: cc.ClientID,
+	})
+	assert.Equal(t, oidc.InvalidClient, oauthErrorCode(t, err))
+	_, err = a.AuthorizeDevice(ctx, &oidc.DeviceAuthorizationRequest{
+		ClientID: c.ClientID,
+		Scope:    "openid address",
+	})
+	assert.Equal(t, oidc.InvalidScope, oauthErrorCode(t, err))
+	res, err := a.AuthorizeDevice(nil, &oidc.DeviceAuthorizationRequest{
+		ClientID: c.ClientID,
+		Scope:    "openid profile",
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, res.DeviceCode)
+	assert.NotEmpty(t, res.UserCode)
+	assert.Equal(t, oidc.VerificationURI(a.config), res.VerificationURI)
+	uri, err := url.Parse(res.VerificationURIComplete)
+	require.NoError(t, err)
+	assert.Equal(t, res.UserCode, uri.Query().Get(key.UserCode))
+	assert.Equal(t, int(a.config.OIDC.DeviceExpiration.Seconds()), res.ExpiresIn)
+	assert.Equal(t, int(a.config.OIDC.DeviceInterval.Seconds()), res.Interval)
+	dc, err := codes.GetDeviceCode(a.conn, res.DeviceCode)
+	require.NoError(t, err)
+	assert.Equal(t, c.ClientID, dc.ClientID)
+	assert.Equal(t, "openid profile", dc.Scope)
+	assert.Equal(t, res.UserCode, dc.Code())
+	res = testDeviceRequest(t, a, cc, secret)
+	assert.NotEmpty(t, res.DeviceCode)
+}
+
+func TestAPI_ApproveDevice(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	c, _ := testClient(t, a, false)
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	res := testDeviceRequest(t, a, c, "")
+	ctx := testContext(a)
+	_, err := a.GetDeviceAuthorization(ctx, uuid.New(), res.UserCode)
+	assert.Error(t, err)
+	_, err = a.GetDeviceAuthorization(ctx, u.ID, "BCDF-GHJK")
+	assert.Equal(t, oidc.InvalidRequest, oauthErrorCode(t, err))
+	auth, err := a.GetDeviceAuthorization(nil, u.ID, res.UserCode)
+	require.NoError(t, err)
+	assert.Equal(t, c.ClientID, auth.ClientID)
+	assert.Equal(t, c.Name, auth.ClientName)
+	assert.Equal(t, []string{oidc.ScopeOpenID, oidc.ScopeProfile}, auth.Scopes)
+	// impersonated
+	ictx := testContext(a)
+	ictx.SetImpersonatorID(uuid.New())
+	err = a.ApproveDevice(ictx, u.ID, res.UserCode, true)
+	assert.Error(t, err)
+	err = a.ApproveDevice(ctx, uuid.New(), res.UserCode, true)
+	assert.Error(t, err)
+	err = a.ApproveDevice(ctx, u.ID, "BCDF-GHJK", true)
+	assert.Error(t, err)
+	err = a.ApproveDevice(nil, u.ID, res.UserCode, true)
+	require.NoError(t, err)
+	hasAuditEntry(t, a, auditlog.DeviceApproved, u.ID)
+	dc, err := codes.GetDeviceCode(a.conn, res.DeviceCode)
+	require.NoError(t, err)
+	assert.True(t, dc.Approved())
+	assert.Equal(t, u.ID, dc.UserID)
+	// already approved
+	_, err = a.GetDeviceAuthorization(ctx, u.ID, res.UserCode)
+	assert.Error(t, err)
+	err = a.ApproveDevice(ctx, u.ID, res.UserCode, true)
+	assert.Error(t, err)
+	// denied
+	res = testDeviceRequest(t, a, c, "")
+	err = a.ApproveDevice(ctx, u.ID, res.UserCode, false)
+	require.NoError(t, err)
+	dc, err = codes.GetDeviceCode(a.conn, res.DeviceCode)
+	require.NoError(t, err)
+	assert.True(t, dc.Denied())
+}
+
+func TestAPI_GrantDeviceCode(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	a.config.OIDC.DeviceInterval = 0
+	c, _ := testClient(t, a, false)
+	other, _ := testClient(t, a, false)
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	res := testDeviceRequest(t, a, c, "")
+	ctx := testContext(a)
+	req := testDeviceToken(c, res.DeviceCode)
+	_, err := a.GrantDeviceCode(ctx, nil)
+	assert.Equal(t, oidc.InvalidRequest, oauthErrorCode(t, err))
+	_, err = a.GrantDeviceCode(ctx, &oidc.TokenRequest{
+		GrantType:  oidc.GrantAuthorizationCode,
+		ClientID:   c.ClientID,
+		DeviceCode: res.DeviceCode,
+	})
+	assert.Equal(t, oidc.UnsupportedGrantType, oauthErrorCode(t, err))
+	_, err = a.GrantDeviceCode(ctx, testDeviceToken(c, "bad"))
+	assert.Equal(t, oidc.InvalidGrant, oauthErrorCode(t, err))
+	_, err = a.GrantDeviceCode(ctx, testDeviceToken(other, res.DeviceCode))
+	assert.Equal(t, oidc.InvalidGrant, oauthErrorCode(t, err))
+	// pending
+	_, err = a.GrantDeviceCode(ctx, req)
+	assert.Equal(t, oidc.AuthorizationPending, oauthErrorCode(t, err))
+	_, err = a.GrantDeviceCode(ctx, req)
+	assert.Equal(t, oidc.AuthorizationPending, oauthErrorCode(t, err))
+	// approved
+	err = a.ApproveDevice(ctx, u.ID, res.UserCode, true)
+	require.NoError(t, err)
+	tr, err := a.GrantDeviceCode(nil, req)
+	require.NoError(t, err)
+	assert.Equal(t, oidc.TokenTypeBearer, tr.TokenType)
+	assert.NotEmpty(t, tr.RefreshToken)
+	assert.Equal(t, int(a.config.JWT.Expiration.Seconds()), tr.ExpiresIn)
+	// the token is not limited to the scopes
+	assert.Empty(t, tr.Scope)
+	claims, err := jwt.ParseUserClaims(a.config.JWT, tr.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, u.ID, claims.UserID())
+	assert.NotEqual(t, uuid.Nil, claims.SessionID())
+	hasAuditEntry(t, a, auditlog.Granted, u.ID)
+	bt, err := a.RefreshBearerToken(ctx, tr.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEmpty(t, bt.String())
+	// used
+	_, err = a.GrantDeviceCode(ctx, req)
+	assert.Equal(t, oidc.InvalidGrant, oauthErrorCode(t, err))
+	// denied
+	res = testDeviceRequest(t, a, c, "")
+	err = a.ApproveDevice(ctx, u.ID, res.UserCode, false)
+	require.NoError(t, err)
+	_, err = a.GrantDeviceCode(ctx, testDeviceToken(c, res.DeviceCode))
+	assert.Equal(t, oidc.AccessDenied, oauthErrorCode(t, err))
+	// expired
+	res = testDeviceRequest(t, a, c, "")
+	dc, err := codes.GetDeviceCode(a.conn, res.DeviceCode)
+	require.NoError(t, err)
+	err = a.conn.Model(dc).Update("expired_at", time.Now().UTC().Add(-time.Minute)).Error
+	require.NoError(t, err)
+	_, err = a.GrantDeviceCode(ctx, testDeviceToken(c, res.DeviceCode))
+	assert.Equal(t, oidc.ExpiredToken, oauthErrorCode(t, err))
+}
+
+func TestAPI_GrantDeviceCode_Privileged(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	a.config.OIDC.DeviceInterval = 0
+	c, _ := testClient(t, a, false)
+	ctx := testContext(a)
+	// admins
+	adm := confirmUser(t, a, testUser(t, a))
+	err := users.ChangeRole(a.conn, adm, user.RoleAdmin)
+	require.NoError(t, err)
+	res := testDeviceRequest(t, a, c, "")
+	_, err = a.GetDeviceAuthorization(ctx, adm.ID, res.UserCode)
+	assert.Error(t, err)
+	err = a.ApproveDevice(ctx, adm.ID, res.UserCode, true)
+	assert.Error(t, err)
+	// users with role permissions
+	u := confirmUser(t, a, testUser(t, a))
+	testRole(t, a, "support", testPermissions)
+	_, err = roles.AssignRole(a.conn, u.ID, "support")
+	require.NoError(t, err)
+	err = a.ApproveDevice(ctx, u.ID, res.UserCode, true)
+	assert.Error(t, err)
+	// users that gained permissions after they approved
+	u = confirmUser(t, a, testUser(t, a))
+	err = a.ApproveDevice(ctx, u.ID, res.UserCode, true)
+	require.NoError(t, err)
+	_, err = roles.AssignRole(a.conn, u.ID, "support")
+	require.NoError(t, err)
+	_, err = a.GrantDeviceCode(ctx, testDeviceToken(c, res.DeviceCode))
+	assert.Equal(t, oidc.InvalidGrant, oauthErrorCode(t, err))
+}
+
+func TestAPI_GrantDeviceCode_SlowDown(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	a.config.OIDC.DeviceInterval = time.Hour
+	c, _ := testClient(t, a, false)
+	res := testDeviceRequest(t, a, c, "")
+	ctx := testContext(a)
+	req := testDeviceToken(c, res.DeviceCode)
+	_, err := a.GrantDeviceCode(ctx, req)
+	assert.Equal(t, oidc.AuthorizationPending, oauthErrorCode(t, err))
+	_, err = a.GrantDeviceCode(ctx, req)
+	assert.Equal(t, oidc.SlowDown, oauthErrorCode(t, err))
+	dc, err := codes.GetDeviceCode(a.conn, res.DeviceCode)
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour+deviceSlowDown, dc.Interval)
+}
@@ -5,10 +5,16 @@ type Event string
 
 // Events
 const (
-	Unknown   Event = ""
-	Confirmed Event = "confirmed"
-	Login     Event = "login"
-	Logout    Event = "logout"
-	Signup    Event = "signup"
-	All       Event = "all" // must be last
+	Unknown         Event = ""
+	Confirmed       Event = "confirmed"
+	Login           Event = "login"
+	Logout          Event = "logout"
+	MFADisabled     Event = "mfa_disabled"
+	MFAEnrolled     Event = "mfa_enrolled"
+	MFAVerified     Event = "mfa_verified"
+	Signup          Event = "signup"
+	TokenReused     Event = "token_reused"
+	WebAuthnAdded   Event = "webauthn_added"
+	WebAuthnRemoved Event = "webauthn_removed"
+	All             Event = "all" // must be last
 )
@@ -0,0 +1,243 @@
+package core
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/core/audit"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/core/users"
+	"github.com/jrapoport/gothic/models/rbac"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+)
+
+// reminderInterval is how often password expiry reminders are sent.
+const reminderInterval = time.Hour
+
+// GrantPasswordChange returns a password change token if the user must change
+// their password, or their password has expired. Otherwise, it returns nil.
+func (a *API) GrantPasswordChange(ctx context.Context, u *user.User) (*token.PasswordChangeToken, error) {
+	if u == nil || u.IsLocked() {
+		err := errors.New("invalid user")
+		return nil, a.logError(err)
+	}
+	if u.Provider.IsExternal() {
+		return nil, nil
+	}
+	if !u.NeedsPasswordChange(a.config.Password.MaxAge) {
+		return nil, nil
+	}
+	pt, err := tokens.GrantPasswordChangeToken(a.conn, u.ID, a.config.Password.ChangeExpiration)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	a.log.Debugf("granted password change: %s", u.ID)
+	return pt, nil
+}
+
+// ChangeExpiredPassword changes the password for the user with a password change
+// token and returns the user. Changes may only be attempted token.PasswordChangeAttempts
+// times. The password min age does not apply to password changes with a token.
+func (a *API) ChangeExpiredPassword(ctx context.Context, tok, pw string) (*user.User, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if tok == "" {
+		err := errors.New("token required")
+		return nil, a.logError(err)
+	}
+	pt, err := tokens.GetPasswordChangeToken(a.conn, tok)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	// failed attempts count against the token, so the
+	// token is used outside the change transaction.
+	err = tokens.UseToken(a.conn, pt)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	var u *user.User
+	var rt *token.RevokedToken
+	err = a.conn.Transaction(func(tx *store.Connection) (err error) {
+		u, err = users.GetUser(tx, pt.UserID)
+		if err != nil {
+			return err
+		}
+		if u.IsLocked() {
+			return errors.New("invalid user")
+		}
+		err = a.validatePassword(pw, u.Email, u.Username)
+		if err != nil {
+			return err
+		}
+		err = a.checkPasswordHistory(tx, u, pw, true)
+		if err != nil {
+			return err
+		}
+		err = a.savePasswordHistory(tx, u)
+		if err != nil {
+			return err
+		}
+		err = users.ChangePassword(tx, u, pw)
+		if err != nil {
+			return err
+		}
+		err = tokens.RevokePasswordChangeToken(tx, pt)
+		if err != nil {
+			return err
+		}
+		rt, err = a.revokeTokens(tx, u.ID)
+		if err != nil {
+			return err
+		}
+		return audit.LogPasswordChange(ctx, tx, u.ID)
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	a.revoked.Add(rt)
+	return u, nil
+}
+
+// ForcePasswordChange requires a user to change their password the next
+// time they login. Any bearer & refresh tokens for the user are revoked.
+func (a *API) ForcePasswordChange(ctx context.Context, userID uuid.UUID) (*user.User, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !ctx.IsAdmin() {
+		err := errors.New("admin user required")
+		return nil, a.logError(err)
+	}
+	if userID == uuid.Nil {
+		err := errors.New("user id required")
+		return nil, a.logError(err)
+	}
+	a.log.Debugf("force password change: %s", userID)
+	var u *user.User
+	var rt *token.RevokedToken
+	err := a.conn.Transaction(func(tx *store.Connection) (err error) {
+		role, err := a.validateAdmin(tx, ctx.AdminID(), rbac.UsersWrite)
+		if err != nil {
+			return err
+		}
+		u, err = users.GetUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		if u.IsAdmin() && role != user.RoleSuper {
+			err = errors.New("super admin required to force admin password change")
+			return err
+		}
+		err = users.ForcePasswordChange(tx, u)
+		if err != nil {
+			return err
+		}
+		err = tokens.RevokeAllRefreshTokens(tx, u.ID)
+		if err != nil {
+			return err
+		}
+		rt, err = a.revokeTokens(tx, u.ID)
+		if err != nil {
+			return err
+		}
+		return audit.LogPasswordForced(ctx, tx, u.ID)
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	a.revoked.Add(rt)
+	a.log.Debugf("forced password change: %s", userID)
+	return u, nil
+}
+
+// SendPasswordReminders sends a reminder mail to users whose passwords will
+// expire within the password reminder window and returns the number of
+// reminders sent. Each user is only reminded once per password.
+func (a *API) SendPasswordReminders(ctx context.Context) (int, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	p := a.config.Password
+	if p.MaxAge <= 0 || p.Reminder <= 0 {
+		return 0, nil
+	}
+	if a.mail == nil || a.mail.IsOffline() {
+		return 0, nil
+	}
+	before := time.Now().UTC().Add(p.Reminder - p.MaxAge)
+	expiring, err := users.GetExpiringPasswordUsers(a.conn, a.Provider(), before)
+	if err != nil {
+		return 0, a.logError(err)
+	}
+	var sent int
+	for _, u := range expiring {
+		err = a.sendPasswordReminder(ctx, u)
+		if errors.Is(err, config.ErrRateLimitExceeded) {
+			// try again next time
+			continue
+		}
+		if err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	a.log.Debugf("sent %d password reminders", sent)
+	return sent, nil
+}
+
+func (a *API) sendPasswordReminder(ctx context.Context, u *user.User) error {
+	expires := u.PasswordExpiresAt(a.config.Password.MaxAge)
+	err := a.sendConfirmToken(ctx, u.ID,
+		func(u *user.User) (bool, error) {
+			if u.IsLocked() {
+				err := errors.New("invalid user")
+				return false, err
+			}
+			return true, nil
+		},
+		func(to string, ct *token.ConfirmToken) error {
+			referrerURL := a.config.Mail.PasswordExpiry.ReferralURL
+			return a.mail.SendPasswordExpiry(to, ct.String(), referrerURL, expires)
+		})
+	if err != nil {
+		return err
+	}
+	err = users.PasswordReminded(a.conn, u)
+	return a.logError(err)
+}
+
+func (a *API) startPasswordReminders() {
+	p := a.config.Password
+	if p.MaxAge <= 0 || p.Reminder <= 0 {
+		return
+	}
+	a.reminders = make(chan struct{})
+	go func(done <-chan struct{}) {
+		ticker := time.NewTicker(reminderInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				_, _ = a.SendPasswordReminders(nil)
+			}
+		}
+	}(a.reminders)
+	a.log.Info("password reminders started")
+}
+
+func (a *API) stopPasswordReminders() {
+	if a.reminders == nil {
+		return
+	}
+	close(a.reminders)
+	a.reminders = nil
+	a.log.Info("password reminders stopped")
+}
@@ -0,0 +1,268 @@
+package core
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/core/users"
+	"github.com/jrapoport/gothic/core/validate"
+	"github.com/jrapoport/gothic/jwt"
+	"github.com/jrapoport/gothic/mail/template"
+	"github.com/jrapoport/gothic/models/auditlog"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/models/types/provider"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	expiredPass = "q8Ld!3xPz#w9Vk2m"
+	changedPass = "Zr7&uLq2!mXp4#Tb"
+)
+
+func expireUserPassword(t *testing.T, a *API, u *user.User, age time.Duration) *user.User {
+	changed := time.Now().UTC().Add(-age)
+	err := a.conn.Model(u).Update(key.PasswordChangedAt, changed).Error
+	require.NoError(t, err)
+	u, err = users.GetUser(a.conn, u.ID)
+	require.NoError(t, err)
+	return u
+}
+
+func TestAPI_GrantPasswordChange(t *testing.T) {
+	t.Parallel()
+	a := loginAPI(t)
+	a.config.Password.MaxAge = time.Hour
+	ctx := testContext(a)
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	_, err := a.GrantPasswordChange(ctx, nil)
+	assert.Error(t, err)
+	pt, err := a.GrantPasswordChange(ctx, u)
+	assert.NoError(t, err)
+	assert.Nil(t, pt)
+	// expired
+	u = expireUserPassword(t, a, u, 2*time.Hour)
+	pt, err = a.GrantPasswordChange(ctx, u)
+	assert.NoError(t, err)
+	require.NotNil(t, pt)
+	assert.Equal(t, u.ID, pt.UserID)
+	// passwords do not expire
+	a.config.Password.MaxAge = 0
+	pt, err = a.GrantPasswordChange(ctx, u)
+	assert.NoError(t, err)
+	assert.Nil(t, pt)
+	// forced
+	err = users.ForcePasswordChange(a.conn, u)
+	require.NoError(t, err)
+	pt, err = a.GrantPasswordChange(ctx, u)
+	assert.NoError(t, err)
+	assert.NotNil(t, pt)
+	// external users do not have passwords
+	u.Provider = provider.Google
+	pt, err = a.GrantPasswordChange(ctx, u)
+	assert.NoError(t, err)
+	assert.Nil(t, pt)
+	// banned
+	u = banUser(t, a, u)
+	_, err = a.GrantPasswordChange(ctx, u)
+	assert.Error(t, err)
+}
+
+func TestAPI_ChangeExpiredPassword(t *testing.T) {
+	t.Parallel()
+	a := loginAPI(t)
+	a.config.Password.MaxAge = time.Hour
+	a.config.Password.MinAge = 30 * time.Minute
+	a.config.Password.History = 2
+	ctx := testContext(a)
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	u = expireUserPassword(t, a, u, 2*time.Hour)
+	pt, err := a.GrantPasswordChange(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, pt)
+	// bad token
+	_, err = a.ChangeExpiredPassword(ctx, "", changedPass)
+	assert.Error(t, err)
+	_, err = a.ChangeExpiredPassword(ctx, "bad", changedPass)
+	assert.Error(t, err)
+	// bad password
+	_, err = a.ChangeExpiredPassword(ctx, pt.String(), "")
+	assert.Error(t, err)
+	_, err = a.ChangeExpiredPassword(ctx, pt.String(), testPass)
+	var pe *validate.PasswordError
+	require.ErrorAs(t, err, &pe)
+	assert.Equal(t, []validate.PasswordViolation{validate.PasswordReused}, pe.Violations)
+	// the min age does not apply
+	u, err = a.ChangeExpiredPassword(nil, pt.String(), changedPass)
+	assert.NoError(t, err)
+	require.NotNil(t, u)
+	assert.NoError(t, u.Authenticate(changedPass))
+	assert.False(t, u.NeedsPasswordChange(a.config.Password.MaxAge))
+	hasAuditEntry(t, a, auditlog.Password, u.ID)
+	pt2, err := a.GrantPasswordChange(ctx, u)
+	assert.NoError(t, err)
+	assert.Nil(t, pt2)
+	// tokens cannot be reused
+	_, err = a.ChangeExpiredPassword(ctx, pt.String(), expiredPass)
+	assert.Error(t, err)
+	_, err = a.Login(ctx, u.Email, changedPass)
+	assert.NoError(t, err)
+}
+
+func TestAPI_ChangeExpiredPassword_Attempts(t *testing.T) {
+	t.Parallel()
+	a := loginAPI(t)
+	ctx := testContext(a)
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	err := users.ForcePasswordChange(a.conn, u)
+	require.NoError(t, err)
+	pt, err := a.GrantPasswordChange(ctx, u)
+	require.NoError(t, err)
+	for i := 0; i < token.PasswordChangeAttempts; i++ {
+		_, err = a.ChangeExpiredPassword(ctx, pt.String(), "weak")
+		assert.Error(t, err)
+	}
+	// the token is exhausted
+	_, err = a.ChangeExpiredPassword(ctx, pt.String(), changedPass)
+	assert.Error(t, err)
+	// a new token is granted
+	pt2, err := a.GrantPasswordChange(ctx, u)
+	require.NoError(t, err)
+	assert.NotEqual(t, pt.String(), pt2.String())
+	_, err = a.ChangeExpiredPassword(ctx, pt2.String(), changedPass)
+	assert.NoError(t, err)
+}
+
+func TestAPI_ForcePasswordChange(t *testing.T) {
+	t.Parallel()
+	a := loginAPI(t)
+	ctx := testContext(a)
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	bt, err := a.GrantBearerToken(ctx, u)
+	require.NoError(t, err)
+	claims, err := jwt.ParseUserClaims(a.config.JWT, bt.String())
+	require.NoError(t, err)
+	err = a.ValidateBearerToken(claims)
+	require.NoError(t, err)
+	// not admin
+	_, err = a.ForcePasswordChange(ctx, u.ID)
+	assert.Error(t, err)
+	_, err = a.ForcePasswordChange(nil, u.ID)
+	assert.Error(t, err)
+	ctx = rootContext(a)
+	_, err = a.ForcePasswordChange(ctx, user.SystemID)
+	assert.Error(t, err)
+	u, err = a.ForcePasswordChange(ctx, u.ID)
+	assert.NoError(t, err)
+	assert.True(t, u.MustChangePassword)
+	hasAuditEntry(t, a, auditlog.PasswordForced, u.ID)
+	has, err := tokens.HasUsableRefreshToken(a.conn, u.ID)
+	require.NoError(t, err)
+	assert.False(t, has)
+	// the bearer token is revoked
+	err = a.ValidateBearerToken(claims)
+	assert.ErrorIs(t, err, tokens.ErrTokenRevoked)
+	pt, err := a.GrantPasswordChange(testContext(a), u)
+	require.NoError(t, err)
+	require.NotNil(t, pt)
+	u, err = a.ChangeExpiredPassword(testContext(a), pt.String(), changedPass)
+	assert.NoError(t, err)
+	assert.False(t, u.MustChangePassword)
+	// admin users
+	adm := testUser(t, a)
+	adm = promoteUser(t, a, adm)
+	u2 := testUser(t, a)
+	u2 = promoteUser(t, a, u2)
+	actx := testContext(a)
+	actx.SetAdminID(u2.ID)
+	_, err = a.ForcePasswordChange(actx, adm.ID)
+	assert.Error(t, err)
+	adm, err = a.ForcePasswordChange(ctx, adm.ID)
+	assert.NoError(t, err)
+	assert.True(t, adm.MustChangePassword)
+	// banned
+	u = banUser(t, a, u)
+	_, err = a.ForcePasswordChange(ctx, u.ID)
+	assert.Error(t, err)
+}
+
+func TestAPI_SendPasswordReminders(t *testing.T) {
+	a := loginAPI(t)
+	var mock *tconf.SMTPMock
+	a.config, mock = tconf.MockSMTP(t, a.config)
+	a.config.Mail.SpamProtection = false
+	a.config.Mail.SendLimit = 0
+	err := a.OpenMail()
+	require.NoError(t, err)
+	var sent []string
+	var mu sync.Mutex
+	mock.AddHook(t, func(email string) {
+		mu.Lock()
+		defer mu.Unlock()
+		tok := tconf.GetEmailToken(template.ResetPasswordAction, email)
+		sent = append(sent, tok)
+	})
+	ctx := testContext(a)
+	// disabled
+	n, err := a.SendPasswordReminders(ctx)
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+	a.config.Password.MaxAge = 10 * time.Hour
+	a.config.Password.Reminder = time.Hour
+	u1 := testUser(t, a)
+	u1 = confirmUser(t, a, u1)
+	u2 := testUser(t, a)
+	u2 = confirmUser(t, a, u2)
+	u2 = expireUserPassword(t, a, u2, 9*time.Hour+30*time.Minute)
+	n, err = a.SendPasswordReminders(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(sent) == 1
+	}, 1*time.Second, 10*time.Millisecond)
+	u2, err = users.GetUser(a.conn, u2.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, u2.PasswordRemindedAt)
+	// users are only reminded once
+	n, err = a.SendPasswordReminders(ctx)
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+	// the reminder is a reset password token
+	mu.Lock()
+	tok := sent[0]
+	mu.Unlock()
+	u2, err = a.ConfirmResetPassword(ctx, tok, changedPass)
+	require.NoError(t, err)
+	assert.Nil(t, u2.PasswordRemindedAt)
+	assert.False(t, u2.NeedsPasswordChange(a.config.Password.MaxAge))
+	u1 = expireUserPassword(t, a, u1, 11*time.Hour)
+	n, err = a.SendPasswordReminders(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
+
+func TestAPI_PasswordReminders(t *testing.T) {
+	t.Parallel()
+	c := tconf.TempDB(t)
+	c.Password.MaxAge = 10 * time.Hour
+	c.Password.Reminder = time.Hour
+	a := configuredAPI(t, c)
+	assert.NotNil(t, a.reminders)
+	a.stopPasswordReminders()
+	assert.Nil(t, a.reminders)
+	a.stopPasswordReminders()
+	a.config.Password.Reminder = 0
+	a.startPasswordReminders()
+	assert.Nil(t, a.reminders)
+}
@@ -0,0 +1,140 @@
+package factors
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/factor"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+// QRCodeSize is the width and height of the enrollment qr code in pixels.
+const QRCodeSize = 256
+
+// ErrInvalidCode is returned when a totp code is not valid.
+var ErrInvalidCode = errors.New("invalid totp code")
+
+// Enrollment holds a new totp enrollment.
+type Enrollment struct {
+	// Secret is the base32 encoded shared secret.
+	Secret string
+	// URL is the otpauth:// uri for the enrollment.
+	URL string
+	// QRCode is the otpauth:// uri encoded as a png qr code.
+	QRCode []byte
+}
+
+// EnrollTOTP creates a new unconfirmed totp enrollment for the
+// user. Any previous unconfirmed enrollment is replaced.
+func EnrollTOTP(conn *store.Connection, u *user.User, issuer string) (*Enrollment, error) {
+	k, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      issuer,
+		AccountName: u.Email,
+		Period:      factor.Period,
+		Digits:      factor.Digits,
+		Algorithm:   otp.AlgorithmSHA1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = conn.Transaction(func(tx *store.Connection) error {
+		has, err := HasTOTP(tx, u.ID)
+		if err != nil {
+			return err
+		}
+		if has {
+			return errors.New("totp already enabled")
+		}
+		err = deleteTOTP(tx, u.ID)
+		if err != nil {
+			return err
+		}
+		t := factor.NewTOTP(u.ID, k.Secret())
+		return tx.Create(t).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	img, err := k.Image(QRCodeSize, QRCodeSize)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	err = png.Encode(&buf, img)
+	if err != nil {
+		return nil, err
+	}
+	return &Enrollment{
+		Secret: k.Secret(),
+		URL:    k.URL(),
+		QRCode: buf.Bytes(),
+	}, nil
+}
+
+// ConfirmTOTP confirms a pending totp enrollment with a code.
+func ConfirmTOTP(conn *store.Connection, userID uuid.UUID, code string) error {
+	return conn.Transaction(func(tx *store.Connection) error {
+		t, err := GetTOTP(tx, userID)
+		if err != nil {
+			return err
+		}
+		if t.IsConfirmed() {
+			return errors.New("totp already confirmed")
+		}
+		if !t.Validate(code) {
+			return ErrInvalidCode
+		}
+		now := time.Now().UTC()
+		t.ConfirmedAt = &now
+		return tx.Save(t).Error
+	})
+}
+
+// ValidateTOTP validates a code against a confirmed totp enrollment.
+func ValidateTOTP(conn *store.Connection, userID uuid.UUID, code string) error {
+	return conn.Transaction(func(tx *store.Connection) error {
+		t, err := GetTOTP(tx, userID)
+		if err != nil {
+			return err
+		}
+		if !t.IsConfirmed() {
+			return errors.New("totp not enabled")
+		}
+		if !t.Validate(code) {
+			return ErrInvalidCode
+		}
+		return tx.Model(t).Update("last_step", t.LastStep).Error
+	})
+}
+
+// DisableTOTP removes a user's totp enrollment.
+func DisableTOTP(conn *store.Connection, userID uuid.UUID) error {
+	return deleteTOTP(conn, userID)
+}
+
+// GetTOTP returns the totp enrollment for the user.
+func GetTOTP(conn *store.Connection, userID uuid.UUID) (*factor.TOTP, error) {
+	var t factor.TOTP
+	err := conn.First(&t, key.UserID+" = ?", userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// HasTOTP returns true if the user has a confirmed totp enrollment.
+func HasTOTP(conn *store.Connection, userID uuid.UUID) (bool, error) {
+	const totpQuery = key.UserID + " = ? AND confirmed_at IS NOT NULL"
+	return conn.Has(new(factor.TOTP), totpQuery, userID)
+}
+
+func deleteTOTP(conn *store.Connection, userID uuid.UUID) error {
+	return conn.Where(key.UserID+" = ?", userID).Delete(new(factor.TOTP)).Error
+}
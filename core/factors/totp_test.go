@@ -0,0 +1,143 @@
+package factors
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/factor"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/jrapoport/gothic/test/tutils"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testIssuer = "gothic"
+
+func testCode(t *testing.T, secret string, at time.Time) string {
+	code, err := totp.GenerateCodeCustom(secret, at, totp.ValidateOpts{
+		Period:    factor.Period,
+		Digits:    factor.Digits,
+		Algorithm: otp.AlgorithmSHA1,
+	})
+	require.NoError(t, err)
+	return code
+}
+
+func testUser() *user.User {
+	return &user.User{
+		ID:    uuid.New(),
+		Email: tutils.RandomEmail(),
+	}
+}
+
+func TestEnrollTOTP(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	u := testUser()
+	e, err := EnrollTOTP(conn, u, testIssuer)
+	require.NoError(t, err)
+	require.NotNil(t, e)
+	assert.NotEmpty(t, e.Secret)
+	k, err := otp.NewKeyFromURL(e.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "totp", k.Type())
+	assert.Equal(t, testIssuer, k.Issuer())
+	assert.Equal(t, u.Email, k.AccountName())
+	assert.Equal(t, e.Secret, k.Secret())
+	img, err := png.Decode(bytes.NewReader(e.QRCode))
+	require.NoError(t, err)
+	assert.Equal(t, QRCodeSize, img.Bounds().Dx())
+	has, err := HasTOTP(conn, u.ID)
+	assert.NoError(t, err)
+	assert.False(t, has)
+	// re-enrolling replaces an unconfirmed enrollment
+	e2, err := EnrollTOTP(conn, u, testIssuer)
+	require.NoError(t, err)
+	assert.NotEqual(t, e.Secret, e2.Secret)
+	tp, err := GetTOTP(conn, u.ID)
+	require.NoError(t, err)
+	assert.Equal(t, e2.Secret, tp.Secret)
+	// confirmed enrollments cannot be replaced
+	code := testCode(t, e2.Secret, time.Now().UTC())
+	err = ConfirmTOTP(conn, u.ID, code)
+	require.NoError(t, err)
+	_, err = EnrollTOTP(conn, u, testIssuer)
+	assert.Error(t, err)
+	// system user
+	_, err = EnrollTOTP(conn, &user.User{ID: user.SystemID}, testIssuer)
+	assert.Error(t, err)
+}
+
+func TestConfirmTOTP(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	u := testUser()
+	// not enrolled
+	err := ConfirmTOTP(conn, u.ID, "123456")
+	assert.Error(t, err)
+	e, err := EnrollTOTP(conn, u, testIssuer)
+	require.NoError(t, err)
+	// bad code
+	err = ConfirmTOTP(conn, u.ID, "")
+	assert.Error(t, err)
+	code := testCode(t, e.Secret, time.Now().UTC())
+	err = ConfirmTOTP(conn, u.ID, code)
+	assert.NoError(t, err)
+	has, err := HasTOTP(conn, u.ID)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	// already confirmed
+	err = ConfirmTOTP(conn, u.ID, code)
+	assert.Error(t, err)
+}
+
+func TestValidateTOTP(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	u := testUser()
+	e, err := EnrollTOTP(conn, u, testIssuer)
+	require.NoError(t, err)
+	now := time.Now().UTC()
+	// not confirmed
+	err = ValidateTOTP(conn, u.ID, testCode(t, e.Secret, now))
+	assert.Error(t, err)
+	err = ConfirmTOTP(conn, u.ID, testCode(t, e.Secret, now))
+	require.NoError(t, err)
+	// replayed
+	err = ValidateTOTP(conn, u.ID, testCode(t, e.Secret, now))
+	assert.Error(t, err)
+	next := now.Add(factor.Period * time.Second)
+	err = ValidateTOTP(conn, u.ID, testCode(t, e.Secret, next))
+	assert.NoError(t, err)
+	// replayed
+	err = ValidateTOTP(conn, u.ID, testCode(t, e.Secret, next))
+	assert.Error(t, err)
+	err = ValidateTOTP(conn, uuid.New(), testCode(t, e.Secret, next))
+	assert.Error(t, err)
+}
+
+func TestDisableTOTP(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	u := testUser()
+	e, err := EnrollTOTP(conn, u, testIssuer)
+	require.NoError(t, err)
+	err = ConfirmTOTP(conn, u.ID, testCode(t, e.Secret, time.Now().UTC()))
+	require.NoError(t, err)
+	err = DisableTOTP(conn, u.ID)
+	assert.NoError(t, err)
+	has, err := HasTOTP(conn, u.ID)
+	assert.NoError(t, err)
+	assert.False(t, has)
+	_, err = GetTOTP(conn, u.ID)
+	assert.Error(t, err)
+	// can re-enroll
+	_, err = EnrollTOTP(conn, u, testIssuer)
+	assert.NoError(t, err)
+}
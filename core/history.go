@@ -0,0 +1,50 @@
+package core
+
+import (
+	"time"
+
+	"github.com/jrapoport/gothic/core/users"
+	"github.com/jrapoport/gothic/core/validate"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+)
+
+// checkPasswordHistory returns a password error if the password was used
+// recently by the user, or if the user changed their password too recently.
+// The min password age does not apply to password resets.
+func (a *API) checkPasswordHistory(conn *store.Connection, u *user.User, pw string, reset bool) error {
+	p := a.config.Password
+	var violations []validate.PasswordViolation
+	if p.MinAge > 0 && !reset {
+		at, err := users.PasswordChangedAt(conn, u)
+		if err != nil {
+			return err
+		}
+		if time.Now().Before(at.Add(p.MinAge)) {
+			violations = append(violations, validate.PasswordTooRecent)
+		}
+	}
+	reused, err := users.PasswordReused(conn, u, pw, p.History)
+	if err != nil {
+		return err
+	}
+	if reused {
+		violations = append(violations, validate.PasswordReused)
+	}
+	if len(violations) > 0 {
+		return &validate.PasswordError{Violations: violations}
+	}
+	return nil
+}
+
+// savePasswordHistory adds the current password for the user to the password
+// history before it is changed. The current password is always checked, so the
+// history keeps one less than the configured depth. If a min password age is
+// set, the history keeps at least one hash so the time of the change is known.
+func (a *API) savePasswordHistory(conn *store.Connection, u *user.User) error {
+	n := a.config.Password.History - 1
+	if n < 1 && a.config.Password.MinAge > 0 {
+		n = 1
+	}
+	return users.AddPasswordHistory(conn, u, n)
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/core/validate"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPI_PasswordHistory(t *testing.T) {
+	t.Parallel()
+	const (
+		pass1 = "Xk9!vQz2#mLp4@Rw"
+		pass2 = "Jf7$tBn3%hYc8&Ds"
+		pass3 = "Wq5^eUm6*gKx2(Pa"
+	)
+	a := apiWithTempDB(t)
+	a.config.Password.History = 3
+	ctx := testContext(a)
+	u := testUser(t, a)
+	violations := func(err error) []validate.PasswordViolation {
+		var pe *validate.PasswordError
+		require.ErrorAs(t, err, &pe)
+		return pe.Violations
+	}
+	reused := []validate.PasswordViolation{validate.PasswordReused}
+	// the current password
+	_, err := a.ChangePassword(ctx, u.ID, testPass, testPass)
+	assert.Equal(t, reused, violations(err))
+	// testPass > pass1 > pass2
+	_, err = a.ChangePassword(ctx, u.ID, testPass, pass1)
+	require.NoError(t, err)
+	_, err = a.ChangePassword(ctx, u.ID, pass1, pass2)
+	require.NoError(t, err)
+	for _, pw := range []string{testPass, pass1, pass2} {
+		_, err = a.ChangePassword(ctx, u.ID, pass2, pw)
+		assert.Equal(t, reused, violations(err))
+	}
+	// reset password
+	ct, err := tokens.GrantConfirmToken(a.conn, u.ID, token.NoExpiration)
+	require.NoError(t, err)
+	_, err = a.ConfirmResetPassword(ctx, ct.String(), testPass)
+	assert.Equal(t, reused, violations(err))
+	u, err = a.ConfirmResetPassword(ctx, ct.String(), pass3)
+	require.NoError(t, err)
+	err = u.Authenticate(pass3)
+	assert.NoError(t, err)
+	// testPass has aged out of the history
+	u, err = a.ChangePassword(ctx, u.ID, pass3, testPass)
+	require.NoError(t, err)
+	// min age
+	a.config.Password.History = 0
+	a.config.Password.MinAge = time.Hour
+	_, err = a.ChangePassword(ctx, u.ID, testPass, pass1)
+	assert.Equal(t, []validate.PasswordViolation{
+		validate.PasswordTooRecent,
+	}, violations(err))
+	a.config.Password.History = 2
+	_, err = a.ChangePassword(ctx, u.ID, testPass, pass3)
+	assert.Equal(t, []validate.PasswordViolation{
+		validate.PasswordTooRecent,
+		validate.PasswordReused,
+	}, violations(err))
+	// the min age does not apply to resets
+	ct, err = tokens.GrantConfirmToken(a.conn, u.ID, token.NoExpiration)
+	require.NoError(t, err)
+	u, err = a.ConfirmResetPassword(ctx, ct.String(), pass1)
+	require.NoError(t, err)
+	err = u.Authenticate(pass1)
+	assert.NoError(t, err)
+	a.config.Password.MinAge = time.Nanosecond
+	_, err = a.ChangePassword(ctx, u.ID, pass1, pass2)
+	assert.NoError(t, err)
+}
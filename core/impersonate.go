@@ -0,0 +1,86 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/audit"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/core/users"
+	"github.com/jrapoport/gothic/models/rbac"
+	"github.com/jrapoport/gothic/store"
+)
+
+// ImpersonateUser grants the admin a short-lived bearer token to act as the
+// user. The token cannot be refreshed and carries the admin as its actor.
+// Admins and super admins cannot be impersonated.
+// NOTE: This API requires admin permissions.
+func (a *API) ImpersonateUser(ctx context.Context, userID uuid.UUID) (*tokens.BearerToken, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	aid := ctx.AdminID()
+	if aid == uuid.Nil {
+		err := errors.New("admin user required")
+		return nil, a.logError(err)
+	}
+	if userID == uuid.Nil {
+		err := errors.New("user id required")
+		return nil, a.logError(err)
+	}
+	if userID == aid {
+		err := errors.New("cannot impersonate self")
+		return nil, a.logError(err)
+	}
+	var bt *tokens.BearerToken
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		_, err := a.validateAdmin(tx, aid, rbac.UsersImpersonate)
+		if err != nil {
+			return err
+		}
+		u, err := users.GetUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		if u.IsAdmin() {
+			err = fmt.Errorf("cannot impersonate admin: %s", u.ID)
+			return err
+		}
+		exp := a.config.Impersonation.Expiration
+		bt, err = tokens.GrantImpersonationToken(a.config.JWT, exp, u, aid)
+		if err != nil {
+			return err
+		}
+		return audit.LogImpersonated(ctx, tx, bt)
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	a.log.Debugf("admin %s impersonating user %s", aid, userID)
+	return bt, nil
+}
+
+// LogImpersonation records a request made by an admin while impersonating
+// the user of the context.
+func (a *API) LogImpersonation(ctx context.Context, method, path string) error {
+	if ctx == nil || !ctx.Impersonated() {
+		return nil
+	}
+	err := audit.LogImpersonation(ctx, a.conn, ctx.UserID(), method, path)
+	if err != nil {
+		return a.logError(err)
+	}
+	return nil
+}
+
+// checkImpersonation returns an error if the request is made while
+// impersonating a user. Sensitive changes to a user can only be made
+// by the user.
+func checkImpersonation(ctx context.Context) error {
+	if ctx == nil || !ctx.Impersonated() {
+		return nil
+	}
+	return fmt.Errorf("not allowed while impersonating: %s", ctx.UserID())
+}
@@ -0,0 +1,131 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/audit"
+	"github.com/jrapoport/gothic/jwt"
+	"github.com/jrapoport/gothic/models/auditlog"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPI_ImpersonateUser(t *testing.T) {
+	t.Parallel()
+	a := createAPI(t)
+	adm := promoteUser(t, a, testUser(t, a))
+	_, err := a.GrantBearerToken(nil, adm)
+	require.NoError(t, err)
+	u := confirmUser(t, a, testUser(t, a))
+	// no admin
+	_, err = a.ImpersonateUser(nil, u.ID)
+	assert.Error(t, err)
+	ctx := testContext(a)
+	ctx.SetAdminID(adm.ID)
+	_, err = a.ImpersonateUser(ctx, uuid.Nil)
+	assert.Error(t, err)
+	_, err = a.ImpersonateUser(ctx, uuid.New())
+	assert.Error(t, err)
+	// self
+	_, err = a.ImpersonateUser(ctx, adm.ID)
+	assert.Error(t, err)
+	// admins & super admins
+	adm2 := promoteUser(t, a, testUser(t, a))
+	_, err = a.ImpersonateUser(ctx, adm2.ID)
+	assert.Error(t, err)
+	_, err = a.ImpersonateUser(ctx, user.SuperAdminID)
+	assert.Error(t, err)
+	// no permission
+	bad := testContext(a)
+	bad.SetAdminID(testUser(t, a).ID)
+	_, err = a.ImpersonateUser(bad, u.ID)
+	assert.Error(t, err)
+	a.config.Impersonation.Expiration = 5 * time.Minute
+	bt, err := a.ImpersonateUser(ctx, u.ID)
+	require.NoError(t, err)
+	assert.Equal(t, u.ID, bt.UserID)
+	assert.Nil(t, bt.RefreshToken)
+	require.NotNil(t, bt.ExpiredAt)
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), *bt.ExpiredAt, time.Minute)
+	claims, err := jwt.ParseUserClaims(a.config.JWT, bt.String())
+	require.NoError(t, err)
+	assert.Equal(t, u.ID, claims.UserID())
+	assert.Equal(t, adm.ID, claims.ActorID())
+	assert.False(t, claims.Privileged())
+	logs, err := audit.SearchEntries(a.conn, store.Ascending, store.Filters{
+		key.Action:  auditlog.Impersonated.String(),
+		key.AdminID: adm.ID.String(),
+	}, nil)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	assert.Equal(t, u.ID, logs[0].UserID)
+	// the super admin can impersonate users
+	_, err = a.ImpersonateUser(rootContext(a), u.ID)
+	assert.NoError(t, err)
+}
+
+func TestAPI_LogImpersonation(t *testing.T) {
+	t.Parallel()
+	a := createAPI(t)
+	u := confirmUser(t, a, testUser(t, a))
+	aid := uuid.New()
+	// not impersonating
+	err := a.LogImpersonation(nil, "GET", "/user")
+	assert.NoError(t, err)
+	ctx := testContext(a)
+	ctx.SetUserID(u.ID)
+	err = a.LogImpersonation(ctx, "GET", "/user")
+	assert.NoError(t, err)
+	ctx.SetImpersonatorID(aid)
+	err = a.LogImpersonation(ctx, "GET", "/user")
+	assert.NoError(t, err)
+	logs, err := audit.SearchEntries(a.conn, store.Ascending, store.Filters{
+		key.Action: auditlog.Impersonation.String(),
+	}, nil)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	assert.Equal(t, u.ID, logs[0].UserID)
+	assert.Equal(t, aid.String(), logs[0].Fields[key.AdminID])
+	assert.Equal(t, u.ID.String(), logs[0].Fields[key.UserID])
+	assert.Equal(t, "GET", logs[0].Fields[key.Method])
+	assert.Equal(t, "/user", logs[0].Fields[key.Path])
+}
+
+func TestAPI_Impersonated(t *testing.T) {
+	t.Parallel()
+	a := createAPI(t)
+	u := confirmUser(t, a, testUser(t, a))
+	ctx := testContext(a)
+	ctx.SetUserID(u.ID)
+	ctx.SetImpersonatorID(uuid.New())
+	_, err := a.ChangePassword(ctx, u.ID, testPass, "gj8#xtg6!zr4HQ!1")
+	assert.Error(t, err)
+	err = a.SendChangeEmail(ctx, u.ID, "new@example.com")
+	assert.Error(t, err)
+	_, err = a.CreatePersonalToken(ctx, u.ID, "test", nil, 0)
+	assert.Error(t, err)
+	_, err = a.EnrollTOTP(ctx, u.ID)
+	assert.Error(t, err)
+	err = a.ConfirmTOTP(ctx, u.ID, "123456")
+	assert.Error(t, err)
+	err = a.DisableTOTP(ctx, u.ID, "123456")
+	assert.Error(t, err)
+	_, err = a.BeginWebAuthnRegistration(ctx, u.ID)
+	assert.Error(t, err)
+	_, err = a.FinishWebAuthnRegistration(ctx, u.ID, "tok", "key", nil)
+	assert.Error(t, err)
+	err = a.DeleteWebAuthnCredential(ctx, u.ID, "cred")
+	assert.Error(t, err)
+	_, err = a.Authorize(ctx, u.ID, nil, true)
+	assert.Error(t, err)
+	// the user can make the changes
+	ctx = testContext(a)
+	ctx.SetUserID(u.ID)
+	_, err = a.EnrollTOTP(ctx, u.ID)
+	assert.NoError(t, err)
+}
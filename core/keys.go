@@ -0,0 +1,12 @@
+package core
+
+import (
+	"github.com/jrapoport/gothic/jwt"
+	"github.com/lestrrat-go/jwx/jwk"
+)
+
+// PublicKeys returns the public keys that can be used
+// to verify bearer tokens as a JSON Web Key Set (JWKS).
+func (a *API) PublicKeys() (jwk.Set, error) {
+	return jwt.PublicKeySet(a.config.JWT)
+}
@@ -0,0 +1,25 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/jrapoport/gothic/test/tconf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPI_PublicKeys(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	// secrets are never published
+	set, err := a.PublicKeys()
+	require.NoError(t, err)
+	assert.Equal(t, 0, set.Len())
+	a.config = tconf.RSAKeys(t, a.config)
+	set, err = a.PublicKeys()
+	require.NoError(t, err)
+	require.Equal(t, 1, set.Len())
+	key, ok := set.LookupKeyID(tconf.RSAKeyID)
+	require.True(t, ok)
+	assert.Equal(t, "RS256", key.Algorithm())
+}
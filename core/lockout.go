@@ -0,0 +1,242 @@
+package core
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/core/audit"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/core/factors"
+	"github.com/jrapoport/gothic/core/login"
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/core/users"
+	"github.com/jrapoport/gothic/models/rbac"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+	"gorm.io/gorm"
+)
+
+// checkLockout returns ErrRateLimitExceeded if logins from the ip address
+// have been locked out, or if the user must wait before they try again.
+func (a *API) checkLockout(ctx context.Context, email string) error {
+	l := a.config.Lockout
+	if !l.Enabled() {
+		return nil
+	}
+	ip := ctx.IPAddress()
+	since := time.Now().UTC().Add(-l.Window)
+	if l.IPAttempts > 0 {
+		count, err := login.CountFailedLoginsFromIP(a.conn, ip, since)
+		if err != nil {
+			return err
+		}
+		if count >= int64(l.IPAttempts) {
+			a.log.Warnf("failed login limit exceeded for ip: %s", ip)
+			return config.ErrRateLimitExceeded
+		}
+	}
+	if l.Delay <= 0 {
+		return nil
+	}
+	u, err := users.GetUserWithEmail(a.conn, email)
+	if err != nil {
+		// the login will fail
+		return nil
+	}
+	attempts, err := login.GetFailedLogins(a.conn, u.ID, since)
+	if err != nil {
+		return err
+	}
+	if len(attempts) <= 0 {
+		return nil
+	}
+	last := attempts[len(attempts)-1].CreatedAt
+	err = l.CheckRetry(len(attempts), &last)
+	if err != nil {
+		a.log.Warnf("failed login delay for user: %s", u.ID)
+		return err
+	}
+	return nil
+}
+
+// isFailedLogin returns true if the login error should count as a failed login.
+func isFailedLogin(err error) bool {
+	return errors.Is(err, login.ErrIncorrectPassword) ||
+		errors.Is(err, tokens.ErrInvalidCode) ||
+		errors.Is(err, gorm.ErrRecordNotFound)
+}
+
+// failedLogin records a failed login for the email. If the user
+// has exceeded the failed login limit, they are temporarily locked.
+func (a *API) failedLogin(ctx context.Context, email string) error {
+	l := a.config.Lockout
+	ip := ctx.IPAddress()
+	return a.conn.Transaction(func(tx *store.Connection) error {
+		uid := uuid.Nil
+		u, err := users.GetUserWithEmail(tx, email)
+		if err == nil {
+			uid = u.ID
+		}
+		err = audit.LogLoginFailed(ctx, tx, uid, email)
+		if err != nil {
+			return err
+		}
+		if !l.Enabled() || (uid == uuid.Nil && ip == "") {
+			return nil
+		}
+		_, err = login.FailedLogin(tx, uid, ip)
+		if err != nil {
+			return err
+		}
+		if u == nil || l.Attempts <= 0 || u.IsLocked() {
+			return nil
+		}
+		since := time.Now().UTC().Add(-l.Window)
+		attempts, err := login.GetFailedLogins(tx, u.ID, since)
+		if err != nil {
+			return err
+		}
+		if len(attempts) < l.Attempts {
+			return nil
+		}
+		return a.lockUser(ctx, tx, u)
+	})
+}
+
+// clearFailedLogins clears the failed logins for the user. If the user has
+// mfa enabled they are cleared once the mfa challenge has been verified.
+func clearFailedLogins(tx *store.Connection, userID uuid.UUID) error {
+	has, err := factors.HasTOTP(tx, userID)
+	if err != nil {
+		return err
+	}
+	if has {
+		return nil
+	}
+	return login.ClearFailedLogins(tx, userID)
+}
+
+// lockUser temporarily locks the user & sends them an unlock link.
+func (a *API) lockUser(ctx context.Context, tx *store.Connection, u *user.User) error {
+	l := a.config.Lockout
+	until := time.Now().UTC().Add(l.Duration)
+	a.log.Warnf("failed login limit exceeded, locking user %s until %s", u.ID, until)
+	err := users.LockUserUntil(tx, u, until)
+	if err != nil {
+		return err
+	}
+	err = login.ClearFailedLogins(tx, u.ID)
+	if err != nil {
+		return err
+	}
+	err = audit.LogLocked(ctx, tx, u.ID, until)
+	if err != nil {
+		return err
+	}
+	return a.sendUnlockUser(tx, u)
+}
+
+func (a *API) sendUnlockUser(tx *store.Connection, u *user.User) error {
+	if a.mail == nil || a.mail.IsOffline() {
+		a.log.Warn("mail not found")
+		return nil
+	}
+	ut, err := tokens.GrantUnlockToken(tx, u.ID, a.config.Lockout.Duration)
+	if err != nil {
+		return err
+	}
+	referrerURL := a.config.Mail.UnlockUser.ReferralURL
+	err = a.mail.SendUnlockUser(u.EmailAddress().String(), ut.String(), referrerURL)
+	if err != nil {
+		// the user is still locked, their lock will expire
+		a.log.Errorf("failed to send unlock to user %s: %v", u.ID, err)
+		return nil
+	}
+	return tokens.UnlockTokenSent(tx, ut)
+}
+
+// ConfirmUnlock unlocks the user the unlock token was sent to.
+func (a *API) ConfirmUnlock(ctx context.Context, tok string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if tok == "" {
+		err := errors.New("token required")
+		return a.logError(err)
+	}
+	ut, err := tokens.GetUnlockToken(a.conn, tok)
+	if err != nil {
+		return a.logError(err)
+	}
+	err = tokens.UseToken(a.conn, ut)
+	if err != nil {
+		return a.logError(err)
+	}
+	err = a.conn.Transaction(func(tx *store.Connection) error {
+		u, err := users.GetUser(tx, ut.UserID)
+		if err != nil {
+			return err
+		}
+		return a.unlockUser(ctx, tx, u)
+	})
+	return a.logError(err)
+}
+
+// UnlockUser unlocks a locked user.
+// NOTE: This API requires admin user permissions.
+func (a *API) UnlockUser(ctx context.Context, userID uuid.UUID) (*user.User, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !ctx.IsAdmin() {
+		err := errors.New("admin user required")
+		return nil, a.logError(err)
+	}
+	if userID == uuid.Nil {
+		err := errors.New("user id required")
+		return nil, a.logError(err)
+	}
+	a.log.Debugf("unlock user: %s", userID)
+	var u *user.User
+	err := a.conn.Transaction(func(tx *store.Connection) (err error) {
+		role, err := a.validateAdmin(tx, ctx.AdminID(), rbac.UsersWrite)
+		if err != nil {
+			return err
+		}
+		u, err = users.GetUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		if u.IsAdmin() && role != user.RoleSuper {
+			err = errors.New("super admin required to unlock admin")
+			return err
+		}
+		return a.unlockUser(ctx, tx, u)
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	a.log.Debugf("unlocked user: %s", userID)
+	return u, nil
+}
+
+func (a *API) unlockUser(ctx context.Context, tx *store.Connection, u *user.User) error {
+	if u.Status != user.Locked {
+		return errors.New("user not locked")
+	}
+	err := users.UnlockUser(tx, u)
+	if err != nil {
+		return err
+	}
+	err = login.ClearFailedLogins(tx, u.ID)
+	if err != nil {
+		return err
+	}
+	err = tokens.RevokeUnlockTokens(tx, u.ID)
+	if err != nil {
+		return err
+	}
+	return audit.LogUnlocked(ctx, tx, u.ID)
+}
@@ -0,0 +1,249 @@
+package core
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/core/audit"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/core/users"
+	"github.com/jrapoport/gothic/mail/template"
+	"github.com/jrapoport/gothic/models/attempt"
+	"github.com/jrapoport/gothic/models/auditlog"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+	"github.com/jrapoport/gothic/test/tconf"
+	"github.com/jrapoport/gothic/test/tutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const lockoutAttempts = 3
+
+func lockoutAPI(t *testing.T) *API {
+	a := loginAPI(t)
+	a.config.Lockout = config.Lockout{
+		Attempts: lockoutAttempts,
+		Window:   time.Hour,
+		Duration: time.Hour,
+	}
+	return a
+}
+
+func countAuditEntries(t *testing.T, a *API, action auditlog.Action, f store.Filters) int {
+	if f == nil {
+		f = store.Filters{}
+	}
+	f[key.Action] = action.String()
+	logs, err := audit.SearchEntries(a.conn, store.Ascending, f, nil)
+	require.NoError(t, err)
+	return len(logs)
+}
+
+func lockUser(t *testing.T, a *API, ctx context.Context, u *user.User) {
+	for i := 0; i < lockoutAttempts; i++ {
+		_, err := a.Login(ctx, u.Email, "bad")
+		assert.Error(t, err)
+	}
+	u, err := users.GetUser(a.conn, u.ID)
+	require.NoError(t, err)
+	require.True(t, u.IsLocked())
+}
+
+func TestAPI_Login_Lockout(t *testing.T) {
+	t.Parallel()
+	a := lockoutAPI(t)
+	ctx := testContext(a)
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	for i := 0; i < lockoutAttempts-1; i++ {
+		_, err := a.Login(ctx, u.Email, "bad")
+		assert.Error(t, err)
+	}
+	f := store.Filters{key.UserID: u.ID.String()}
+	assert.Equal(t, lockoutAttempts-1, countAuditEntries(t, a, auditlog.LoginFailed, f))
+	// successful logins reset the count
+	_, err := a.Login(ctx, u.Email, testPass)
+	require.NoError(t, err)
+	var count int64
+	err = a.conn.Model(&attempt.Attempt{}).Where("user_id = ?", u.ID).Count(&count).Error
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	// locked
+	lockUser(t, a, ctx, u)
+	hasAuditEntry(t, a, auditlog.Locked, u.ID)
+	u, err = users.GetUser(a.conn, u.ID)
+	require.NoError(t, err)
+	require.NotNil(t, u.LockedUntil)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), *u.LockedUntil, time.Minute)
+	_, err = a.Login(ctx, u.Email, testPass)
+	assert.Error(t, err)
+	// failed logins for locked users do not relock them
+	_, err = a.Login(ctx, u.Email, "bad")
+	assert.Error(t, err)
+	hasAuditEntry(t, a, auditlog.Locked, u.ID)
+	// expired
+	err = users.LockUserUntil(a.conn, u, time.Now().Add(-time.Second))
+	require.NoError(t, err)
+	u, err = a.Login(ctx, u.Email, testPass)
+	assert.NoError(t, err)
+	assert.True(t, u.IsActive())
+	// unknown users are audited
+	email := tutils.RandomEmail()
+	_, err = a.Login(ctx, email, "bad")
+	assert.Error(t, err)
+	f = store.Filters{key.Email: email}
+	assert.Equal(t, 1, countAuditEntries(t, a, auditlog.LoginFailed, f))
+	// disabled
+	a.config.Lockout.Attempts = 0
+	u2 := testUser(t, a)
+	u2 = confirmUser(t, a, u2)
+	for i := 0; i < lockoutAttempts; i++ {
+		_, err = a.Login(ctx, u2.Email, "bad")
+		assert.Error(t, err)
+	}
+	_, err = a.Login(ctx, u2.Email, testPass)
+	assert.NoError(t, err)
+	f = store.Filters{key.UserID: u2.ID.String()}
+	assert.Equal(t, lockoutAttempts, countAuditEntries(t, a, auditlog.LoginFailed, f))
+}
+
+func TestAPI_Login_LockoutDelay(t *testing.T) {
+	t.Parallel()
+	a := lockoutAPI(t)
+	a.config.Lockout.Attempts = 0
+	a.config.Lockout.Delay = time.Minute
+	ctx := testContext(a)
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	_, err := a.Login(ctx, u.Email, "bad")
+	assert.Error(t, err)
+	// too early
+	_, err = a.Login(ctx, u.Email, testPass)
+	assert.ErrorIs(t, err, config.ErrRateLimitExceeded)
+	past := time.Now().UTC().Add(-90 * time.Second)
+	err = a.conn.Model(&attempt.Attempt{}).
+		Where("user_id = ?", u.ID).
+		UpdateColumn("created_at", past).Error
+	require.NoError(t, err)
+	_, err = a.Login(ctx, u.Email, testPass)
+	assert.NoError(t, err)
+}
+
+func TestAPI_Login_LockoutIP(t *testing.T) {
+	t.Parallel()
+	a := lockoutAPI(t)
+	a.config.Lockout.Attempts = 0
+	a.config.Lockout.IPAttempts = lockoutAttempts
+	ctx := testContext(a)
+	ctx.SetIPAddress(tutils.RandomIP())
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	for i := 0; i < lockoutAttempts; i++ {
+		_, err := a.Login(ctx, tutils.RandomEmail(), "bad")
+		assert.Error(t, err)
+	}
+	_, err := a.Login(ctx, u.Email, testPass)
+	assert.ErrorIs(t, err, config.ErrRateLimitExceeded)
+	// other ip addresses are not locked out
+	ctx.SetIPAddress(tutils.RandomIP())
+	_, err = a.Login(ctx, u.Email, testPass)
+	assert.NoError(t, err)
+}
+
+func TestAPI_ConfirmUnlock(t *testing.T) {
+	a := lockoutAPI(t)
+	var mock *tconf.SMTPMock
+	a.config, mock = tconf.MockSMTP(t, a.config)
+	a.config.Mail.SpamProtection = false
+	err := a.OpenMail()
+	require.NoError(t, err)
+	var tok string
+	var mu sync.Mutex
+	mock.AddHook(t, func(email string) {
+		mu.Lock()
+		defer mu.Unlock()
+		tok = tconf.GetEmailToken(template.UnlockUserAction, email)
+	})
+	ctx := testContext(a)
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	lockUser(t, a, ctx, u)
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return tok != ""
+	}, 1*time.Second, 10*time.Millisecond)
+	ut, err := tokens.GetUnlockToken(a.conn, tok)
+	require.NoError(t, err)
+	assert.Equal(t, u.ID, ut.UserID)
+	assert.NotNil(t, ut.SentAt)
+	// bad token
+	err = a.ConfirmUnlock(ctx, "")
+	assert.Error(t, err)
+	err = a.ConfirmUnlock(ctx, "bad")
+	assert.Error(t, err)
+	err = a.ConfirmUnlock(nil, tok)
+	assert.NoError(t, err)
+	hasAuditEntry(t, a, auditlog.Unlocked, u.ID)
+	_, err = a.Login(ctx, u.Email, testPass)
+	assert.NoError(t, err)
+	// tokens cannot be reused
+	err = a.ConfirmUnlock(ctx, tok)
+	assert.Error(t, err)
+	// not locked
+	ut, err = tokens.GrantUnlockToken(a.conn, u.ID, token.NoExpiration)
+	require.NoError(t, err)
+	err = a.ConfirmUnlock(ctx, ut.String())
+	assert.Error(t, err)
+}
+
+func TestAPI_UnlockUser(t *testing.T) {
+	t.Parallel()
+	a := lockoutAPI(t)
+	ctx := testContext(a)
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	// not admin
+	_, err := a.UnlockUser(ctx, u.ID)
+	assert.Error(t, err)
+	ctx = rootContext(a)
+	_, err = a.UnlockUser(nil, u.ID)
+	assert.Error(t, err)
+	_, err = a.UnlockUser(ctx, user.SystemID)
+	assert.Error(t, err)
+	// not locked
+	_, err = a.UnlockUser(ctx, u.ID)
+	assert.Error(t, err)
+	lockUser(t, a, testContext(a), u)
+	u, err = a.UnlockUser(ctx, u.ID)
+	assert.NoError(t, err)
+	assert.True(t, u.IsActive())
+	assert.Nil(t, u.LockedUntil)
+	hasAuditEntry(t, a, auditlog.Unlocked, u.ID)
+	_, err = a.Login(testContext(a), u.Email, testPass)
+	assert.NoError(t, err)
+	// admin locked users
+	adm := testUser(t, a)
+	adm = promoteUser(t, a, adm)
+	err = users.LockUser(a.conn, adm)
+	require.NoError(t, err)
+	u2 := testUser(t, a)
+	u2 = promoteUser(t, a, u2)
+	actx := testContext(a)
+	actx.SetAdminID(u2.ID)
+	_, err = a.UnlockUser(actx, adm.ID)
+	assert.Error(t, err)
+	adm, err = a.UnlockUser(ctx, adm.ID)
+	assert.NoError(t, err)
+	assert.False(t, adm.IsLocked())
+	// banned
+	u = banUser(t, a, u)
+	_, err = a.UnlockUser(ctx, u.ID)
+	assert.Error(t, err)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/jrapoport/gothic/core/login"
 	"github.com/jrapoport/gothic/core/users"
 	"github.com/jrapoport/gothic/core/validate"
+	"github.com/jrapoport/gothic/models/token"
 	"github.com/jrapoport/gothic/models/types"
 	"github.com/jrapoport/gothic/models/types/key"
 	"github.com/jrapoport/gothic/models/types/provider"
@@ -43,7 +44,17 @@ func (a *API) Login(ctx context.Context, email, pw string) (*user.User, error) {
 			return nil, a.logError(err)
 		}
 	}
-	return a.userLogin(ctx, a.conn, p, email, pw)
+	err = a.checkLockout(ctx, email)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	u, err := a.userLogin(ctx, a.conn, p, email, pw)
+	if isFailedLogin(err) {
+		if lerr := a.failedLogin(ctx, email); lerr != nil {
+			a.log.Error(lerr)
+		}
+	}
+	return u, err
 }
 
 func (a *API) externalLogin(ctx context.Context, conn *store.Connection,
@@ -58,6 +69,10 @@ func (a *API) externalLogin(ctx context.Context, conn *store.Connection,
 		if err != nil {
 			return err
 		}
+		err = clearFailedLogins(tx, u.ID)
+		if err != nil {
+			return err
+		}
 		_, err = accounts.UpdateAccount(tx, la, &email, raw)
 		if err != nil {
 			return err
@@ -89,6 +104,10 @@ func (a *API) userLogin(ctx context.Context, conn *store.Connection,
 		if err != nil {
 			return err
 		}
+		err = clearFailedLogins(tx, u.ID)
+		if err != nil {
+			return err
+		}
 		return audit.LogLogin(ctx, tx, u.ID)
 	})
 	if err != nil {
@@ -103,15 +122,22 @@ func (a *API) userLogin(ctx context.Context, conn *store.Connection,
 	return u, nil
 }
 
-// Logout revokes all refresh token for a user id.
+// Logout revokes the refresh token for the current session of the context.
+// If the context does not have a session, all refresh tokens for a user id
+// are revoked. The bearer token of the context (if any) is also revoked.
 func (a *API) Logout(ctx context.Context, userID uuid.UUID) error {
 	if ctx == nil {
 		ctx = context.Background()
 	}
 	p := ctx.Provider()
 	ip := ctx.IPAddress()
-	err := a.conn.Transaction(func(tx *store.Connection) error {
-		err := login.UserLogout(tx, userID)
+	var rt *token.RevokedToken
+	err := a.conn.Transaction(func(tx *store.Connection) (err error) {
+		err = login.UserLogout(tx, userID, ctx.SessionID())
+		if err != nil {
+			return err
+		}
+		rt, err = a.revokeCurrentToken(ctx, tx, userID)
 		if err != nil {
 			return err
 		}
@@ -120,6 +146,7 @@ func (a *API) Logout(ctx context.Context, userID uuid.UUID) error {
 	if err != nil {
 		return a.logError(err)
 	}
+	a.revoked.Add(rt)
 	a.dispatchEvent(events.Logout, types.Map{
 		key.Provider:  p,
 		key.IPAddress: ip,
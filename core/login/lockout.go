@@ -0,0 +1,62 @@
+package login
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/attempt"
+	"github.com/jrapoport/gothic/store"
+)
+
+// ErrIncorrectPassword is returned when a login fails because of an incorrect password.
+var ErrIncorrectPassword = errors.New("incorrect password")
+
+// FailedLogin records a failed login for the user id from the ip address.
+func FailedLogin(conn *store.Connection, userID uuid.UUID, ip string) (*attempt.Attempt, error) {
+	a := attempt.NewAttempt(userID, ip)
+	err := conn.Create(a).Error
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// GetFailedLogins returns the failed logins for the user id since the time (oldest first).
+func GetFailedLogins(conn *store.Connection, userID uuid.UUID, since time.Time) ([]*attempt.Attempt, error) {
+	if userID == uuid.Nil {
+		return nil, errors.New("invalid user id")
+	}
+	var attempts []*attempt.Attempt
+	err := conn.
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Order("created_at ASC").
+		Find(&attempts).Error
+	if err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+// CountFailedLoginsFromIP returns the number of failed logins from the ip address since the time.
+func CountFailedLoginsFromIP(conn *store.Connection, ip string, since time.Time) (int64, error) {
+	if ip == "" {
+		return 0, nil
+	}
+	var count int64
+	err := conn.Model(&attempt.Attempt{}).
+		Where("ip_address = ? AND created_at >= ?", ip, since).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// ClearFailedLogins clears the failed logins for the user id.
+func ClearFailedLogins(conn *store.Connection, userID uuid.UUID) error {
+	if userID == uuid.Nil {
+		return nil
+	}
+	return conn.Where("user_id = ?", userID).Delete(&attempt.Attempt{}).Error
+}
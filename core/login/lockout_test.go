@@ -0,0 +1,94 @@
+package login
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/users"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/jrapoport/gothic/test/tutils"
+)
+
+func (ts *LoginTestSuite) TestFailedLogins() {
+	const testPass = "SXJAm7qJ4?3dH!aN8T3f5p!oNnpXbaRy#Gtx#8jG"
+	p := ts.c.Provider()
+	conn := tconn.Conn(ts.T(), ts.c)
+	u := testUser(ts.T(), conn, p, tutils.RandomEmail(), testPass)
+	ip := tutils.RandomIP()
+	since := time.Now().UTC().Add(-time.Minute)
+	// incorrect password
+	_, err := UserLogin(conn, p, u.Email, "bad")
+	ts.True(errors.Is(err, ErrIncorrectPassword))
+	// invalid user id
+	_, err = GetFailedLogins(conn, uuid.Nil, since)
+	ts.Error(err)
+	_, err = FailedLogin(conn, uuid.Nil, "")
+	ts.Error(err)
+	// unknown user
+	_, err = FailedLogin(conn, uuid.Nil, ip)
+	ts.NoError(err)
+	for i := 0; i < 3; i++ {
+		_, err = FailedLogin(conn, u.ID, ip)
+		ts.NoError(err)
+	}
+	attempts, err := GetFailedLogins(conn, u.ID, since)
+	ts.NoError(err)
+	ts.Len(attempts, 3)
+	for i := 1; i < len(attempts); i++ {
+		ts.False(attempts[i].CreatedAt.Before(attempts[i-1].CreatedAt))
+	}
+	attempts, err = GetFailedLogins(conn, u.ID, time.Now().UTC().Add(time.Minute))
+	ts.NoError(err)
+	ts.Len(attempts, 0)
+	count, err := CountFailedLoginsFromIP(conn, ip, since)
+	ts.NoError(err)
+	ts.Equal(int64(4), count)
+	count, err = CountFailedLoginsFromIP(conn, "", since)
+	ts.NoError(err)
+	ts.Equal(int64(0), count)
+	err = ClearFailedLogins(conn, u.ID)
+	ts.NoError(err)
+	attempts, err = GetFailedLogins(conn, u.ID, since)
+	ts.NoError(err)
+	ts.Len(attempts, 0)
+	// unknown user attempts are not cleared
+	count, err = CountFailedLoginsFromIP(conn, ip, since)
+	ts.NoError(err)
+	ts.Equal(int64(1), count)
+	err = ClearFailedLogins(conn, uuid.Nil)
+	ts.NoError(err)
+	// successful logins do not clear failed logins, a second factor may be required
+	_, err = FailedLogin(conn, u.ID, ip)
+	ts.NoError(err)
+	_, err = UserLogin(conn, p, u.Email, testPass)
+	ts.NoError(err)
+	attempts, err = GetFailedLogins(conn, u.ID, since)
+	ts.NoError(err)
+	ts.Len(attempts, 1)
+}
+
+func (ts *LoginTestSuite) TestUserLogin_LockExpired() {
+	const testPass = "SXJAm7qJ4?3dH!aN8T3f5p!oNnpXbaRy#Gtx#8jG"
+	p := ts.c.Provider()
+	conn := tconn.Conn(ts.T(), ts.c)
+	u := testUser(ts.T(), conn, p, tutils.RandomEmail(), testPass)
+	err := users.LockUserUntil(conn, u, time.Now().Add(time.Hour))
+	ts.Require().NoError(err)
+	// locked
+	_, err = UserLogin(conn, p, u.Email, testPass)
+	ts.Error(err)
+	err = users.LockUserUntil(conn, u, time.Now().Add(-time.Second))
+	ts.Require().NoError(err)
+	// bad password does not unlock
+	_, err = UserLogin(conn, p, u.Email, "bad")
+	ts.Error(err)
+	u, err = users.GetUser(conn, u.ID)
+	ts.Require().NoError(err)
+	ts.True(u.IsLocked())
+	// expired
+	u, err = UserLogin(conn, p, u.Email, testPass)
+	ts.NoError(err)
+	ts.True(u.IsActive())
+	ts.Nil(u.LockedUntil)
+}
@@ -14,7 +14,8 @@ import (
 	"github.com/jrapoport/gothic/store"
 )
 
-// UserLogin authorizes a user and returns a bearer token
+// UserLogin authorizes a user and returns a bearer token. The failed
+// logins of the user are not cleared, since a second factor may be required.
 func UserLogin(conn *store.Connection, p provider.Name, email, pw string) (*user.User, error) {
 	email, err := validate.Email(email)
 	if err != nil {
@@ -26,6 +27,10 @@ func UserLogin(conn *store.Connection, p provider.Name, email, pw string) (*user
 		if err != nil {
 			return err
 		}
+		err = users.UnlockIfExpired(tx, u)
+		if err != nil {
+			return err
+		}
 		if !u.IsActive() {
 			return errors.New("inactive user")
 		}
@@ -33,9 +38,9 @@ func UserLogin(conn *store.Connection, p provider.Name, email, pw string) (*user
 			return errors.New("invalid provider")
 		}
 		if !p.IsExternal() {
-			err = u.Authenticate(pw)
+			err = users.Authenticate(tx, u, pw)
 			if err != nil {
-				err = fmt.Errorf("incorrect password %w", err)
+				err = fmt.Errorf("%w %v", ErrIncorrectPassword, err)
 				return err
 			}
 		}
@@ -49,7 +54,11 @@ func UserLogin(conn *store.Connection, p provider.Name, email, pw string) (*user
 	return u, nil
 }
 
-// UserLogout logs a user out and revokes all their refresh tokens.
-func UserLogout(conn *store.Connection, userID uuid.UUID) error {
-	return tokens.RevokeAllRefreshTokens(conn, userID)
+// UserLogout logs a user out of a session and revokes its refresh
+// token. If the session id is nil, all their refresh tokens are revoked.
+func UserLogout(conn *store.Connection, userID, sessionID uuid.UUID) error {
+	if sessionID == uuid.Nil {
+		return tokens.RevokeAllRefreshTokens(conn, userID)
+	}
+	return tokens.RevokeSession(conn, userID, sessionID)
 }
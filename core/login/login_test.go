@@ -8,6 +8,9 @@ import (
 	"github.com/jrapoport/gothic/config"
 	"github.com/jrapoport/gothic/core/tokens"
 	"github.com/jrapoport/gothic/core/users"
+	"github.com/jrapoport/gothic/hasher"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/types/key"
 	"github.com/jrapoport/gothic/models/types/provider"
 	"github.com/jrapoport/gothic/models/user"
 	"github.com/jrapoport/gothic/store"
@@ -16,6 +19,7 @@ import (
 	"github.com/jrapoport/gothic/test/tutils"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type LoginTestSuite struct {
@@ -79,7 +83,7 @@ func (ts *LoginTestSuite) TestLogin() {
 			u2, err := UserLogin(tx, p, test.email, test.pw)
 			ts.NoError(err)
 			ts.NotNil(u2)
-			bt, err := tokens.GrantBearerToken(tx, ts.jwt, u2)
+			bt, err := tokens.GrantBearerToken(tx, ts.jwt, config.Refresh{}, u2, token.Session{})
 			ts.NoError(err)
 			ts.NotNil(bt)
 			ts.Equal(u.ID, bt.IssuedTo())
@@ -142,6 +146,35 @@ func (ts *LoginTestSuite) TestLogin_Error() {
 	ts.Error(err)
 }
 
+func (ts *LoginTestSuite) TestLogin_Rehash() {
+	const testPass = "SXJAm7qJ4?3dH!aN8T3f5p!oNnpXbaRy#Gtx#8jG"
+	p := ts.c.Provider()
+	em := tutils.RandomEmail()
+	conn := tconn.Conn(ts.T(), ts.c)
+	u := testUser(ts.T(), conn, p, em, testPass)
+	bc, err := hasher.NewBcrypt(bcrypt.MinCost)
+	ts.Require().NoError(err)
+	legacy, err := bc.Hash(testPass)
+	ts.Require().NoError(err)
+	err = conn.Model(u).Update(key.Password, legacy).Error
+	ts.Require().NoError(err)
+	// failed logins do not rehash
+	_, err = UserLogin(conn, p, em, "bad")
+	ts.Error(err)
+	u, err = users.GetUser(conn, u.ID)
+	ts.Require().NoError(err)
+	ts.Equal(legacy, u.Password)
+	u, err = UserLogin(conn, p, em, testPass)
+	ts.Require().NoError(err)
+	ts.False(u.NeedsRehash())
+	u, err = users.GetUser(conn, u.ID)
+	ts.Require().NoError(err)
+	ts.NotEqual(legacy, u.Password)
+	ts.False(u.NeedsRehash())
+	_, err = UserLogin(conn, p, em, testPass)
+	ts.NoError(err)
+}
+
 func (ts *LoginTestSuite) TestLogout() {
 	const testPass = "SXJAm7qJ4?3dH!aN8T3f5p!oNnpXbaRy#Gtx#8jG"
 	p := ts.c.Provider()
@@ -151,21 +184,32 @@ func (ts *LoginTestSuite) TestLogout() {
 	u, err := UserLogin(conn, p, em, testPass)
 	ts.NoError(err)
 	ts.Require().NotNil(u)
-	bt, err := tokens.GrantBearerToken(conn, ts.jwt, u)
+	bt, err := tokens.GrantBearerToken(conn, ts.jwt, config.Refresh{}, u, token.Session{})
 	ts.NoError(err)
 	ts.Require().NotNil(bt)
 	has, err := tokens.HasUsableRefreshToken(conn, bt.UserID)
 	ts.NoError(err)
 	ts.True(has)
-	err = UserLogout(conn, bt.UserID)
+	// single session
+	bt2, err := tokens.GrantBearerToken(conn, ts.jwt, config.Refresh{}, u, token.Session{})
+	ts.NoError(err)
+	ts.Require().NotNil(bt2)
+	err = UserLogout(conn, bt.UserID, bt2.RefreshToken.SessionID)
+	ts.NoError(err)
+	_, err = tokens.GetUsableRefreshToken(conn, bt2.RefreshToken.String())
+	ts.Error(err)
+	_, err = tokens.GetUsableRefreshToken(conn, bt.RefreshToken.String())
+	ts.NoError(err)
+	// all sessions
+	err = UserLogout(conn, bt.UserID, uuid.Nil)
 	ts.NoError(err)
 	has, err = tokens.HasUsableRefreshToken(conn, bt.UserID)
 	ts.NoError(err)
 	ts.False(has)
 	// system user
-	err = UserLogout(conn, uuid.Nil)
+	err = UserLogout(conn, uuid.Nil, uuid.Nil)
 	ts.NoError(err)
 	// unknown user
-	err = UserLogout(conn, uuid.New())
+	err = UserLogout(conn, uuid.New(), uuid.Nil)
 	ts.NoError(err)
 }
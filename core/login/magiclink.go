@@ -0,0 +1,49 @@
+package login
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/core/users"
+	"github.com/jrapoport/gothic/models/types/provider"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+)
+
+// MagicLinkLogin authorizes a user with a magic link token. The
+// token is burned before the user is checked.
+func MagicLinkLogin(conn *store.Connection, p provider.Name, tok string) (*user.User, error) {
+	mt, err := tokens.GetMagicLinkToken(conn, tok)
+	if err != nil {
+		return nil, err
+	}
+	err = tokens.UseToken(conn, mt)
+	if err != nil {
+		return nil, err
+	}
+	var u *user.User
+	err = conn.Transaction(func(tx *store.Connection) error {
+		u, err = users.GetUser(tx, mt.UserID)
+		if err != nil {
+			return err
+		}
+		err = users.UnlockIfExpired(tx, u)
+		if err != nil {
+			return err
+		}
+		if !u.IsActive() {
+			return errors.New("inactive user")
+		}
+		if u.Provider != p {
+			return errors.New("invalid provider")
+		}
+		now := time.Now().UTC()
+		u.LoginAt = &now
+		return tx.Model(u).Update("login_at", u.LoginAt).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
@@ -0,0 +1,48 @@
+package login
+
+import (
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/types/provider"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/jrapoport/gothic/test/tutils"
+)
+
+func (ts *LoginTestSuite) TestMagicLinkLogin() {
+	p := ts.c.Provider()
+	conn := tconn.Conn(ts.T(), ts.c)
+	u := testUser(ts.T(), conn, p, tutils.RandomEmail(), "")
+	grant := func() string {
+		mt, err := tokens.GrantMagicLinkToken(conn, u.ID, token.NoExpiration)
+		ts.Require().NoError(err)
+		return mt.String()
+	}
+	// bad token
+	_, err := MagicLinkLogin(conn, p, "bad")
+	ts.Error(err)
+	// login
+	tok := grant()
+	u2, err := MagicLinkLogin(conn, p, tok)
+	ts.NoError(err)
+	ts.Require().NotNil(u2)
+	ts.Equal(u.ID, u2.ID)
+	ts.NotNil(u2.LoginAt)
+	// tokens cannot be reused
+	_, err = MagicLinkLogin(conn, p, tok)
+	ts.Error(err)
+	// bad provider
+	tok = grant()
+	_, err = MagicLinkLogin(conn, provider.Google, tok)
+	ts.Error(err)
+	// burned
+	_, err = MagicLinkLogin(conn, p, tok)
+	ts.Error(err)
+	// inactive user
+	u.Status = user.Restricted
+	err = conn.Save(u).Error
+	ts.NoError(err)
+	tok = grant()
+	_, err = MagicLinkLogin(conn, p, tok)
+	ts.Error(err)
+}
@@ -0,0 +1,49 @@
+package login
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/core/users"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/types/provider"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+)
+
+// PhoneLogin authorizes a user with a confirmed phone number and an sms
+// code. The phone token is burned before the code is checked.
+func PhoneLogin(conn *store.Connection, p provider.Name, phone, code string) (*user.User, error) {
+	u, err := users.GetUserWithPhone(conn, phone)
+	if err != nil {
+		return nil, err
+	}
+	pt, err := tokens.VerifyPhoneToken(conn, u.ID, token.PhoneLogin, phone, code)
+	if err != nil {
+		return nil, err
+	}
+	err = conn.Transaction(func(tx *store.Connection) error {
+		err = users.UnlockIfExpired(tx, u)
+		if err != nil {
+			return err
+		}
+		if !u.IsActive() {
+			return errors.New("inactive user")
+		}
+		if u.Provider != p {
+			return errors.New("invalid provider")
+		}
+		err = tokens.RevokePhoneToken(tx, pt)
+		if err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		u.LoginAt = &now
+		return tx.Model(u).Update("login_at", u.LoginAt).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
@@ -0,0 +1,56 @@
+package login
+
+import (
+	"time"
+
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/types/provider"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/jrapoport/gothic/test/tutils"
+)
+
+func (ts *LoginTestSuite) TestPhoneLogin() {
+	p := ts.c.Provider()
+	conn := tconn.Conn(ts.T(), ts.c)
+	u := testUser(ts.T(), conn, p, tutils.RandomEmail(), "")
+	phone := tutils.RandomPhone()
+	grant := func() string {
+		pt, err := tokens.GrantPhoneToken(conn, u.ID, token.PhoneLogin, phone, token.NoExpiration)
+		ts.Require().NoError(err)
+		return pt.Code
+	}
+	// no phone
+	code := grant()
+	_, err := PhoneLogin(conn, p, phone, code)
+	ts.Error(err)
+	now := time.Now().UTC()
+	u.Phone = &phone
+	u.PhoneConfirmedAt = &now
+	err = conn.Save(u).Error
+	ts.Require().NoError(err)
+	// bad code
+	_, err = PhoneLogin(conn, p, phone, "bad")
+	ts.Error(err)
+	// login
+	u2, err := PhoneLogin(conn, p, phone, code)
+	ts.NoError(err)
+	ts.Require().NotNil(u2)
+	ts.Equal(u.ID, u2.ID)
+	ts.NotNil(u2.LoginAt)
+	// codes cannot be reused
+	_, err = PhoneLogin(conn, p, phone, code)
+	ts.Error(err)
+	// bad provider
+	code = grant()
+	_, err = PhoneLogin(conn, provider.Google, phone, code)
+	ts.Error(err)
+	// inactive user
+	u.Status = user.Restricted
+	err = conn.Save(u).Error
+	ts.NoError(err)
+	code = grant()
+	_, err = PhoneLogin(conn, p, phone, code)
+	ts.Error(err)
+}
@@ -208,8 +208,24 @@ func TestAPI_Logout(t *testing.T) {
 	ctx := testContext(a)
 	_, err := a.Login(ctx, u.Email, testPass)
 	require.NoError(t, err)
+	bt1, err := a.GrantBearerToken(ctx, u)
+	require.NoError(t, err)
+	bt2, err := a.GrantBearerToken(ctx, u)
+	require.NoError(t, err)
+	// the current session
+	ctx.SetSessionID(bt1.RefreshToken.SessionID)
+	err = a.Logout(ctx, u.ID)
+	assert.NoError(t, err)
+	sessions, err := a.GetSessions(ctx, u.ID)
+	assert.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, bt2.RefreshToken.SessionID, sessions[0].SessionID)
+	// all sessions
 	err = a.Logout(nil, u.ID)
 	assert.NoError(t, err)
+	sessions, err = a.GetSessions(ctx, u.ID)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 0)
 	a.conn.Error = errors.New("test failure")
 	err = a.Logout(nil, uuid.New())
 	assert.Error(t, err)
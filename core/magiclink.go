@@ -0,0 +1,109 @@
+package core
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jrapoport/gothic/core/audit"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/core/events"
+	"github.com/jrapoport/gothic/core/login"
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/core/users"
+	"github.com/jrapoport/gothic/core/validate"
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+)
+
+// SendMagicLink sends a passwordless login link to the user with the email.
+func (a *API) SendMagicLink(ctx context.Context, email string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ip := ctx.IPAddress()
+	recaptcha := ctx.ReCaptcha()
+	p := a.Provider()
+	ctx.SetProvider(p)
+	a.log.Debugf("send magic link: %s (%s %s %s)", email, p, ip, recaptcha)
+	err := a.ext.IsEnabled(p)
+	if err != nil {
+		return a.logError(err)
+	}
+	email, err = a.ValidateEmail(email)
+	if err != nil {
+		return err
+	}
+	if a.config.Recaptcha.Login {
+		// if recaptcha is disabled this is a no-op
+		err = validate.ReCaptcha(a.config, ip, recaptcha)
+		if err != nil {
+			return a.logError(err)
+		}
+	}
+	if a.mail == nil || a.mail.IsOffline() {
+		a.log.Warn("mail not found")
+		return nil
+	}
+	err = a.conn.Transaction(func(tx *store.Connection) error {
+		u, err := users.GetUserWithEmail(tx, email)
+		if err != nil {
+			return err
+		}
+		if !u.IsActive() {
+			return errors.New("inactive user")
+		}
+		if u.Provider != p {
+			return errors.New("invalid provider")
+		}
+		mt, err := tokens.GrantMagicLinkToken(tx, u.ID, a.config.MagicLink.Expiration)
+		if err != nil {
+			return err
+		}
+		err = a.config.Mail.CheckSendLimit(mt.SentAt)
+		if err != nil {
+			a.log.Warnf("rate limit exceeded for user: %s", u.ID)
+			return err
+		}
+		referrerURL := a.config.Mail.MagicLink.ReferralURL
+		err = a.mail.SendMagicLink(u.EmailAddress().String(), mt.String(), referrerURL)
+		if err != nil {
+			return err
+		}
+		err = tokens.MagicLinkTokenSent(tx, mt)
+		if err != nil {
+			return err
+		}
+		return audit.LogMagicLinkSent(ctx, tx, mt)
+	})
+	return a.logError(err)
+}
+
+// ConfirmMagicLink logs in the user the magic link token was sent to.
+func (a *API) ConfirmMagicLink(ctx context.Context, tok string) (*user.User, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	p := a.Provider()
+	ctx.SetProvider(p)
+	err := a.ext.IsEnabled(p)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	u, err := login.MagicLinkLogin(a.conn, p, tok)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	err = audit.LogLogin(ctx, a.conn, u.ID)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	a.dispatchEvent(events.Login, types.Map{
+		key.Provider:  p,
+		key.IPAddress: ctx.IPAddress(),
+		key.UserID:    u.ID,
+		key.Timestamp: time.Now().UTC(),
+	})
+	return u, nil
+}
@@ -0,0 +1,119 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/core/validate"
+	"github.com/jrapoport/gothic/mail/template"
+	"github.com/jrapoport/gothic/models/auditlog"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/types/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPI_SendMagicLink(t *testing.T) {
+	const referral = "http://www.example.com/login/"
+	a := mailAPI(t)
+	a.config.Recaptcha.Key = ""
+	a.config.Mail.MagicLink.ReferralURL = referral
+	ctx := testContext(a)
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	testSend(t, a, u, template.MagicLinkAction,
+		func() error {
+			return a.SendMagicLink(ctx, u.Email)
+		},
+		func(tok string) {
+			mt, err := tokens.GetMagicLinkToken(a.conn, tok)
+			assert.NoError(t, err)
+			assert.Equal(t, u.ID, mt.UserID)
+			assert.NotNil(t, mt.SentAt)
+		},
+		func() {
+			// rate limit
+			var mt token.MagicLinkToken
+			err := a.conn.First(&mt, "user_id = ?", u.ID).Error
+			assert.NoError(t, err)
+			now := time.Now().UTC()
+			mt.SentAt = &now
+			err = a.conn.Save(mt).Error
+			assert.NoError(t, err)
+		})
+	hasAuditEntry(t, a, auditlog.MagicLinkSent, u.ID)
+	// bad email
+	err := a.SendMagicLink(ctx, "bad")
+	assert.Error(t, err)
+	// unknown user
+	err = a.SendMagicLink(ctx, "quack@example.com")
+	assert.Error(t, err)
+	// inactive user
+	u2 := testUser(t, a)
+	err = a.SendMagicLink(ctx, u2.Email)
+	assert.Error(t, err)
+	// external user
+	u2 = confirmUser(t, a, u2)
+	forceExtProvider(t, a, u2)
+	err = a.SendMagicLink(ctx, u2.Email)
+	assert.Error(t, err)
+}
+
+func TestAPI_SendMagicLink_ReCaptcha(t *testing.T) {
+	t.Parallel()
+	a := loginAPI(t)
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	a.config.Security.Recaptcha.Login = true
+	a.config.Recaptcha.Key = validate.ReCaptchaDebugKey
+	ctx := testContext(a)
+	err := a.SendMagicLink(ctx, u.Email)
+	assert.Error(t, err)
+	ctx.SetReCaptcha("bad")
+	err = a.SendMagicLink(ctx, u.Email)
+	assert.Error(t, err)
+	ctx.SetReCaptcha(validate.ReCaptchaDebugToken)
+	err = a.SendMagicLink(ctx, u.Email)
+	assert.NoError(t, err)
+}
+
+func TestAPI_ConfirmMagicLink(t *testing.T) {
+	t.Parallel()
+	a := loginAPI(t)
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	ctx := testContext(a)
+	grant := func() string {
+		mt, err := tokens.GrantMagicLinkToken(a.conn, u.ID, time.Minute)
+		require.NoError(t, err)
+		return mt.String()
+	}
+	// bad token
+	_, err := a.ConfirmMagicLink(ctx, "bad")
+	assert.Error(t, err)
+	// login
+	tok := grant()
+	lu, err := a.ConfirmMagicLink(nil, tok)
+	require.NoError(t, err)
+	assert.Equal(t, u.ID, lu.ID)
+	assert.NotNil(t, lu.LoginAt)
+	hasAuditEntry(t, a, auditlog.Login, u.ID)
+	// tokens cannot be reused
+	_, err = a.ConfirmMagicLink(ctx, tok)
+	assert.Error(t, err)
+	// expired
+	mt := token.NewMagicLinkToken(u.ID, time.Millisecond)
+	err = a.conn.Create(mt).Error
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+	_, err = a.ConfirmMagicLink(ctx, mt.String())
+	assert.Error(t, err)
+	// external user
+	tok = grant()
+	u.Provider = provider.Google
+	err = a.conn.Save(u).Error
+	require.NoError(t, err)
+	_, err = a.ConfirmMagicLink(ctx, tok)
+	assert.Error(t, err)
+}
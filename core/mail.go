@@ -113,6 +113,10 @@ func (a *API) SendResetPassword(ctx context.Context, userID uuid.UUID) error {
 
 // SendChangeEmail sends an email change confirmation mail to a user
 func (a *API) SendChangeEmail(ctx context.Context, userID uuid.UUID, newAddress string) error {
+	err := checkImpersonation(ctx)
+	if err != nil {
+		return a.logError(err)
+	}
 	if a.mail == nil || a.mail.IsOffline() {
 		return nil
 	}
@@ -28,6 +28,7 @@ func mailAPI(t *testing.T) *API {
 	var c = tconf.TempDB(t)
 	c.Mail.Host = ""
 	c.Validation.PasswordRegex = ""
+	c.Password = config.Password{}
 	c.Signup.AutoConfirm = false
 	//c.Mail.KeepAlive = false
 	c.Mail.SpamProtection = false
@@ -0,0 +1,219 @@
+package core
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/core/audit"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/core/events"
+	"github.com/jrapoport/gothic/core/factors"
+	"github.com/jrapoport/gothic/core/login"
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/core/users"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+)
+
+// EnrollTOTP begins a totp enrollment for the user. The enrollment
+// must be confirmed with ConfirmTOTP before it will be used.
+func (a *API) EnrollTOTP(ctx context.Context, userID uuid.UUID) (*factors.Enrollment, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	err := checkImpersonation(ctx)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	var e *factors.Enrollment
+	err = a.conn.Transaction(func(tx *store.Connection) error {
+		u, err := users.GetActiveUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		e, err = factors.EnrollTOTP(tx, u, a.config.MFA.Issuer)
+		return err
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	a.log.Debugf("totp enrollment started: %s", userID)
+	return e, nil
+}
+
+// ConfirmTOTP confirms a totp enrollment with a code.
+func (a *API) ConfirmTOTP(ctx context.Context, userID uuid.UUID, code string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	err := checkImpersonation(ctx)
+	if err != nil {
+		return a.logError(err)
+	}
+	err = a.conn.Transaction(func(tx *store.Connection) error {
+		u, err := users.GetActiveUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		err = factors.ConfirmTOTP(tx, u.ID, code)
+		if err != nil {
+			return err
+		}
+		return audit.LogMFAEnrolled(ctx, tx, u.ID)
+	})
+	if err != nil {
+		return a.logError(err)
+	}
+	a.dispatchEvent(events.MFAEnrolled, types.Map{
+		key.Provider:  ctx.Provider(),
+		key.IPAddress: ctx.IPAddress(),
+		key.UserID:    userID,
+		key.Timestamp: time.Now().UTC(),
+	})
+	return nil
+}
+
+// DisableTOTP disables totp for the user. A current code is required.
+func (a *API) DisableTOTP(ctx context.Context, userID uuid.UUID, code string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	err := checkImpersonation(ctx)
+	if err != nil {
+		return a.logError(err)
+	}
+	err = a.conn.Transaction(func(tx *store.Connection) error {
+		u, err := users.GetActiveUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		err = factors.ValidateTOTP(tx, u.ID, code)
+		if err != nil {
+			return err
+		}
+		err = factors.DisableTOTP(tx, u.ID)
+		if err != nil {
+			return err
+		}
+		return audit.LogMFADisabled(ctx, tx, u.ID)
+	})
+	if err != nil {
+		return a.logError(err)
+	}
+	a.dispatchEvent(events.MFADisabled, types.Map{
+		key.Provider:  ctx.Provider(),
+		key.IPAddress: ctx.IPAddress(),
+		key.UserID:    userID,
+		key.Timestamp: time.Now().UTC(),
+	})
+	return nil
+}
+
+// GrantMFAChallenge returns an mfa challenge token if the user has mfa
+// enabled. If the user does not have mfa enabled, it returns nil. Users
+// may only be granted token.MFAChallenges within the lockout window.
+func (a *API) GrantMFAChallenge(ctx context.Context, u *user.User) (*token.MFAToken, error) {
+	if u == nil || !u.IsActive() {
+		err := errors.New("invalid user")
+		return nil, a.logError(err)
+	}
+	has, err := factors.HasTOTP(a.conn, u.ID)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	if !has {
+		return nil, nil
+	}
+	var mt *token.MFAToken
+	err = a.conn.Transaction(func(tx *store.Connection) error {
+		mt, err = tokens.GrantMFAToken(tx, u.ID, a.config.MFA.Expiration)
+		if err != nil {
+			return err
+		}
+		since := time.Now().UTC().Add(-a.config.Lockout.Window)
+		count, err := tokens.CountMFATokens(tx, u.ID, since)
+		if err != nil {
+			return err
+		}
+		if count > token.MFAChallenges {
+			a.log.Warnf("mfa challenge limit exceeded for user: %s", u.ID)
+			return config.ErrRateLimitExceeded
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	a.log.Debugf("granted mfa challenge: %s", u.ID)
+	return mt, nil
+}
+
+// VerifyMFAChallenge verifies the code for an mfa challenge and returns
+// the user. Challenges may only be attempted token.MFAAttempts times.
+// Invalid codes are failed logins, and count towards the user lockout.
+func (a *API) VerifyMFAChallenge(ctx context.Context, challenge, code string) (*user.User, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if challenge == "" {
+		err := errors.New("challenge required")
+		return nil, a.logError(err)
+	}
+	if code == "" {
+		err := errors.New("code required")
+		return nil, a.logError(err)
+	}
+	mt, err := tokens.GetMFAToken(a.conn, challenge)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	u, err := users.GetActiveUser(a.conn, mt.UserID)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	err = a.checkLockout(ctx, u.Email)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	// failed attempts count against the challenge, so the
+	// token is used outside the verification transaction.
+	err = tokens.UseToken(a.conn, mt)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	err = a.conn.Transaction(func(tx *store.Connection) error {
+		err = factors.ValidateTOTP(tx, u.ID, code)
+		if err != nil {
+			return err
+		}
+		err = login.ClearFailedLogins(tx, u.ID)
+		if err != nil {
+			return err
+		}
+		err = tokens.RevokeMFAToken(tx, mt)
+		if err != nil {
+			return err
+		}
+		return audit.LogMFAVerified(ctx, tx, u.ID)
+	})
+	if errors.Is(err, factors.ErrInvalidCode) {
+		if lerr := a.failedLogin(ctx, u.Email); lerr != nil {
+			a.log.Error(lerr)
+		}
+	}
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	a.dispatchEvent(events.MFAVerified, types.Map{
+		key.Provider:  u.Provider,
+		key.IPAddress: ctx.IPAddress(),
+		key.UserID:    u.ID,
+		key.Timestamp: time.Now().UTC(),
+	})
+	return u, nil
+}
@@ -0,0 +1,303 @@
+package core
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/core/audit"
+	"github.com/jrapoport/gothic/core/events"
+	"github.com/jrapoport/gothic/models/attempt"
+	"github.com/jrapoport/gothic/models/auditlog"
+	"github.com/jrapoport/gothic/models/factor"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/store"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func totpCode(t *testing.T, secret string, skew int) string {
+	at := time.Now().UTC().Add(time.Duration(skew*factor.Period) * time.Second)
+	code, err := totp.GenerateCodeCustom(secret, at, totp.ValidateOpts{
+		Period:    factor.Period,
+		Digits:    factor.Digits,
+		Algorithm: otp.AlgorithmSHA1,
+	})
+	require.NoError(t, err)
+	return code
+}
+
+func hasAuditEntry(t *testing.T, a *API, action auditlog.Action, uid uuid.UUID) {
+	f := store.Filters{
+		key.Action: action.String(),
+		key.UserID: uid.String(),
+	}
+	logs, err := audit.SearchEntries(a.conn, store.Ascending, f, nil)
+	require.NoError(t, err)
+	assert.Len(t, logs, 1)
+}
+
+func TestAPI_EnrollTOTP(t *testing.T) {
+	t.Parallel()
+	a := loginAPI(t)
+	u := testUser(t, a)
+	ctx := testContext(a)
+	// inactive user
+	_, err := a.EnrollTOTP(ctx, u.ID)
+	assert.Error(t, err)
+	u = confirmUser(t, a, u)
+	e, err := a.EnrollTOTP(nil, u.ID)
+	require.NoError(t, err)
+	require.NotNil(t, e)
+	k, err := otp.NewKeyFromURL(e.URL)
+	require.NoError(t, err)
+	assert.Equal(t, a.config.MFA.Issuer, k.Issuer())
+	assert.Equal(t, u.Email, k.AccountName())
+	assert.NotEmpty(t, e.QRCode)
+	// bad code
+	err = a.ConfirmTOTP(ctx, u.ID, "")
+	assert.Error(t, err)
+	err = a.ConfirmTOTP(ctx, u.ID, totpCode(t, e.Secret, 0))
+	assert.NoError(t, err)
+	hasAuditEntry(t, a, auditlog.MFAEnrolled, u.ID)
+	// already enrolled
+	_, err = a.EnrollTOTP(ctx, u.ID)
+	assert.Error(t, err)
+	err = a.ConfirmTOTP(nil, u.ID, totpCode(t, e.Secret, 1))
+	assert.Error(t, err)
+	// bad user
+	_, err = a.EnrollTOTP(ctx, uuid.New())
+	assert.Error(t, err)
+}
+
+func TestAPI_DisableTOTP(t *testing.T) {
+	t.Parallel()
+	a := loginAPI(t)
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	ctx := testContext(a)
+	// not enrolled
+	err := a.DisableTOTP(ctx, u.ID, "123456")
+	assert.Error(t, err)
+	e, err := a.EnrollTOTP(ctx, u.ID)
+	require.NoError(t, err)
+	err = a.ConfirmTOTP(ctx, u.ID, totpCode(t, e.Secret, 0))
+	require.NoError(t, err)
+	// bad code
+	err = a.DisableTOTP(ctx, u.ID, "")
+	assert.Error(t, err)
+	err = a.DisableTOTP(nil, u.ID, totpCode(t, e.Secret, 1))
+	assert.NoError(t, err)
+	hasAuditEntry(t, a, auditlog.MFADisabled, u.ID)
+	mt, err := a.GrantMFAChallenge(ctx, u)
+	assert.NoError(t, err)
+	assert.Nil(t, mt)
+}
+
+func TestAPI_MFAChallenge(t *testing.T) {
+	t.Parallel()
+	a := loginAPI(t)
+	u := testUser(t, a)
+	ctx := testContext(a)
+	// inactive user
+	_, err := a.GrantMFAChallenge(ctx, u)
+	assert.Error(t, err)
+	_, err = a.GrantMFAChallenge(ctx, nil)
+	assert.Error(t, err)
+	u = confirmUser(t, a, u)
+	// not enrolled
+	mt, err := a.GrantMFAChallenge(ctx, u)
+	assert.NoError(t, err)
+	assert.Nil(t, mt)
+	e, err := a.EnrollTOTP(ctx, u.ID)
+	require.NoError(t, err)
+	// unconfirmed
+	mt, err = a.GrantMFAChallenge(ctx, u)
+	assert.NoError(t, err)
+	assert.Nil(t, mt)
+	err = a.ConfirmTOTP(ctx, u.ID, totpCode(t, e.Secret, 0))
+	require.NoError(t, err)
+	mt, err = a.GrantMFAChallenge(ctx, u)
+	assert.NoError(t, err)
+	require.NotNil(t, mt)
+	assert.Equal(t, token.MFA, mt.Class())
+	assert.Equal(t, u.ID, mt.UserID)
+	code := totpCode(t, e.Secret, 1)
+	tests := []struct {
+		challenge string
+		code      string
+	}{
+		{"", ""},
+		{mt.String(), ""},
+		{"", code},
+		{"bad", code},
+		{mt.String(), "bad"},
+	}
+	for _, test := range tests {
+		_, err = a.VerifyMFAChallenge(ctx, test.challenge, test.code)
+		assert.Error(t, err)
+	}
+	u2, err := a.VerifyMFAChallenge(nil, mt.String(), code)
+	assert.NoError(t, err)
+	require.NotNil(t, u2)
+	assert.Equal(t, u.ID, u2.ID)
+	hasAuditEntry(t, a, auditlog.MFAVerified, u.ID)
+	// challenges are single use
+	_, err = a.VerifyMFAChallenge(ctx, mt.String(), code)
+	assert.Error(t, err)
+	// force error
+	a.conn.Error = errors.New("test failure")
+	_, err = a.GrantMFAChallenge(ctx, u)
+	assert.Error(t, err)
+	a.conn.Error = nil
+}
+
+func TestAPI_MFAChallenge_Attempts(t *testing.T) {
+	t.Parallel()
+	a := loginAPI(t)
+	a.config.Lockout.Attempts = 0
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	ctx := testContext(a)
+	e, err := a.EnrollTOTP(ctx, u.ID)
+	require.NoError(t, err)
+	err = a.ConfirmTOTP(ctx, u.ID, totpCode(t, e.Secret, 0))
+	require.NoError(t, err)
+	mt, err := a.GrantMFAChallenge(ctx, u)
+	require.NoError(t, err)
+	for i := 0; i < token.MFAAttempts; i++ {
+		_, err = a.VerifyMFAChallenge(ctx, mt.String(), "000000")
+		assert.Error(t, err)
+	}
+	// the challenge is exhausted
+	code := totpCode(t, e.Secret, 1)
+	_, err = a.VerifyMFAChallenge(ctx, mt.String(), code)
+	assert.Error(t, err)
+	// a new challenge is granted
+	mt2, err := a.GrantMFAChallenge(ctx, u)
+	require.NoError(t, err)
+	assert.NotEqual(t, mt.String(), mt2.String())
+	_, err = a.VerifyMFAChallenge(ctx, mt2.String(), code)
+	assert.NoError(t, err)
+}
+
+func TestAPI_MFAChallenge_Lockout(t *testing.T) {
+	t.Parallel()
+	a := lockoutAPI(t)
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	ctx := testContext(a)
+	e, err := a.EnrollTOTP(ctx, u.ID)
+	require.NoError(t, err)
+	err = a.ConfirmTOTP(ctx, u.ID, totpCode(t, e.Secret, 0))
+	require.NoError(t, err)
+	f := store.Filters{key.UserID: u.ID.String()}
+	// the password does not clear the failed logins
+	_, err = a.Login(ctx, u.Email, "bad")
+	assert.Error(t, err)
+	_, err = a.Login(ctx, u.Email, testPass)
+	require.NoError(t, err)
+	mt, err := a.GrantMFAChallenge(ctx, u)
+	require.NoError(t, err)
+	_, err = a.VerifyMFAChallenge(ctx, mt.String(), "000000")
+	assert.Error(t, err)
+	assert.Equal(t, 2, countAuditEntries(t, a, auditlog.LoginFailed, f))
+	var count int64
+	err = a.conn.Model(&attempt.Attempt{}).Where("user_id = ?", u.ID).Count(&count).Error
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+	// verifying the challenge clears them
+	_, err = a.VerifyMFAChallenge(ctx, mt.String(), totpCode(t, e.Secret, 1))
+	require.NoError(t, err)
+	err = a.conn.Model(&attempt.Attempt{}).Where("user_id = ?", u.ID).Count(&count).Error
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	// invalid codes lock the user
+	mt, err = a.GrantMFAChallenge(ctx, u)
+	require.NoError(t, err)
+	for i := 0; i < lockoutAttempts; i++ {
+		_, err = a.VerifyMFAChallenge(ctx, mt.String(), "000000")
+		assert.Error(t, err)
+	}
+	hasAuditEntry(t, a, auditlog.Locked, u.ID)
+	_, err = a.VerifyMFAChallenge(ctx, mt.String(), totpCode(t, e.Secret, 2))
+	assert.Error(t, err)
+}
+
+func TestAPI_MFAChallenge_Limit(t *testing.T) {
+	t.Parallel()
+	a := loginAPI(t)
+	a.config.Lockout.Attempts = 0
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	ctx := testContext(a)
+	e, err := a.EnrollTOTP(ctx, u.ID)
+	require.NoError(t, err)
+	err = a.ConfirmTOTP(ctx, u.ID, totpCode(t, e.Secret, 0))
+	require.NoError(t, err)
+	var mt *token.MFAToken
+	for i := 0; i < token.MFAChallenges; i++ {
+		mt, err = a.GrantMFAChallenge(ctx, u)
+		require.NoError(t, err)
+		for j := 0; j < token.MFAAttempts; j++ {
+			_, err = a.VerifyMFAChallenge(ctx, mt.String(), "000000")
+			assert.Error(t, err)
+		}
+	}
+	// the challenge limit was reached
+	mt2, err := a.GrantMFAChallenge(ctx, u)
+	assert.ErrorIs(t, err, config.ErrRateLimitExceeded)
+	assert.Nil(t, mt2)
+	// the window has passed
+	a.config.Lockout.Window = time.Nanosecond
+	mt2, err = a.GrantMFAChallenge(ctx, u)
+	require.NoError(t, err)
+	assert.NotEqual(t, mt.String(), mt2.String())
+}
+
+func TestAPI_MFA_Events(t *testing.T) {
+	t.Parallel()
+	a := loginAPI(t)
+	var mu sync.RWMutex
+	data := map[events.Event]types.Map{}
+	a.AddListener(events.All, func(evt events.Event, msg types.Map) {
+		mu.Lock()
+		defer mu.Unlock()
+		data[evt] = msg
+	})
+	hasEvent := func(evt events.Event, uid uuid.UUID) {
+		assert.Eventually(t, func() bool {
+			mu.RLock()
+			defer mu.RUnlock()
+			return data[evt] != nil
+		}, 1*time.Second, 10*time.Millisecond)
+		mu.RLock()
+		defer mu.RUnlock()
+		assert.Equal(t, evt, data[evt][key.Event].(events.Event))
+		assert.Equal(t, testIP, data[evt][key.IPAddress].(string))
+		assert.Equal(t, uid, data[evt][key.UserID].(uuid.UUID))
+	}
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	ctx := testContext(a)
+	e, err := a.EnrollTOTP(ctx, u.ID)
+	require.NoError(t, err)
+	err = a.ConfirmTOTP(ctx, u.ID, totpCode(t, e.Secret, -1))
+	require.NoError(t, err)
+	hasEvent(events.MFAEnrolled, u.ID)
+	mt, err := a.GrantMFAChallenge(ctx, u)
+	require.NoError(t, err)
+	_, err = a.VerifyMFAChallenge(ctx, mt.String(), totpCode(t, e.Secret, 0))
+	require.NoError(t, err)
+	hasEvent(events.MFAVerified, u.ID)
+	err = a.DisableTOTP(ctx, u.ID, totpCode(t, e.Secret, 1))
+	require.NoError(t, err)
+	hasEvent(events.MFADisabled, u.ID)
+}
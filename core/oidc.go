@@ -0,0 +1,503 @@
+package core
+
+import (
+	"errors"
+	"net/url"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/audit"
+	"github.com/jrapoport/gothic/core/clients"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/core/oidc"
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/core/users"
+	"github.com/jrapoport/gothic/jwt"
+	"github.com/jrapoport/gothic/models/client"
+	"github.com/jrapoport/gothic/models/rbac"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+)
+
+// CreateClient registers a new OAuth 2.0 client with the OpenID Connect provider.
+// If the client is confidential, the client secret is returned. Only a hash of
+// the secret is stored, so the secret can not be returned again. If no scopes
+// are set, the client is allowed to request all the supported scopes.
+// NOTE: This API requires admin user permissions.
+func (a *API) CreateClient(ctx context.Context, name string, redirectURIs, scopes []string, confidential bool) (*client.Client, string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !ctx.IsAdmin() {
+		err := errors.New("admin user required")
+		return nil, "", a.logError(err)
+	}
+	if len(scopes) == 0 {
+		scopes = oidc.SupportedScopes
+	}
+	if !oidc.Supported(scopes) {
+		err := errors.New("unsupported scope")
+		return nil, "", a.logError(err)
+	}
+	var c *client.Client
+	var secret string
+	err := a.conn.Transaction(func(tx *store.Connection) (err error) {
+		_, err = a.validateAdmin(tx, ctx.AdminID(), rbac.ClientsWrite)
+		if err != nil {
+			return err
+		}
+		c, secret, err = clients.CreateClient(tx, name, redirectURIs, scopes, confidential)
+		if err != nil {
+			return err
+		}
+		return audit.LogClientCreated(ctx, tx, c)
+	})
+	if err != nil {
+		return nil, "", a.logError(err)
+	}
+	a.log.Debugf("created client %s: %s", c.ClientID, c.Name)
+	return c, secret, nil
+}
+
+// GetClients returns the registered clients.
+// NOTE: This API requires admin user permissions.
+func (a *API) GetClients(ctx context.Context) ([]*client.Client, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !ctx.IsAdmin() {
+		err := errors.New("admin user required")
+		return nil, a.logError(err)
+	}
+	var list []*client.Client
+	err := a.conn.Transaction(func(tx *store.Connection) (err error) {
+		_, err = a.validateAdmin(tx, ctx.AdminID(), rbac.ClientsRead)
+		if err != nil {
+			return err
+		}
+		list, err = clients.GetClients(tx)
+		return err
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	return list, nil
+}
+
+// GetClient returns the registered client for the client id.
+// NOTE: This API requires admin user permissions.
+func (a *API) GetClient(ctx context.Context, clientID string) (*client.Client, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !ctx.IsAdmin() {
+		err := errors.New("admin user required")
+		return nil, a.logError(err)
+	}
+	var c *client.Client
+	err := a.conn.Transaction(func(tx *store.Connection) (err error) {
+		_, err = a.validateAdmin(tx, ctx.AdminID(), rbac.ClientsRead)
+		if err != nil {
+			return err
+		}
+		c, err = clients.GetClient(tx, clientID)
+		return err
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	return c, nil
+}
+
+// DeleteClient deletes a registered client. The consents users have given the
+// client, and the codes and access tokens issued to the client are revoked.
+// NOTE: This API requires admin user permissions.
+func (a *API) DeleteClient(ctx context.Context, clientID string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !ctx.IsAdmin() {
+		err := errors.New("admin user required")
+		return a.logError(err)
+	}
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		_, err := a.validateAdmin(tx, ctx.AdminID(), rbac.ClientsWrite)
+		if err != nil {
+			return err
+		}
+		c, err := clients.GetClient(tx, clientID)
+		if err != nil {
+			return err
+		}
+		err = tokens.RevokeOAuthTokens(tx, uuid.Nil, c.ClientID)
+		if err != nil {
+			return err
+		}
+		err = clients.DeleteClient(tx, c.ClientID)
+		if err != nil {
+			return err
+		}
+		return audit.LogClientDeleted(ctx, tx, c)
+	})
+	if err != nil {
+		return a.logError(err)
+	}
+	a.log.Debugf("deleted client: %s", clientID)
+	return nil
+}
+
+// GetAuthorization validates an authorization request for the user and returns
+// the details of the request that should be shown to the user on a consent screen.
+func (a *API) GetAuthorization(ctx context.Context, userID uuid.UUID, req *oidc.AuthorizationRequest) (*oidc.Authorization, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var auth *oidc.Authorization
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		u, err := users.GetActiveUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		c, err := a.authorizationClient(tx, req)
+		if err != nil {
+			return err
+		}
+		scopes, err := checkAuthorization(c, req)
+		if err != nil {
+			return err
+		}
+		auth = &oidc.Authorization{
+			ClientID:   c.ClientID,
+			ClientName: c.Name,
+			Scopes:     scopes,
+		}
+		consent, err := clients.GetConsent(tx, u.ID, c.ClientID)
+		if err == nil {
+			auth.Consented = consent.Covers(scopes)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	return auth, nil
+}
+
+// Authorize completes an authorization request for the user. If the user approved
+// the request, their consent is recorded and an authorization code is issued to
+// the client. Authorize returns the uri to redirect the user back to the client.
+// If the request fails after the client and redirect uri have been validated, the
+// redirect uri holds the error and an oidc.Error is returned along with it.
+func (a *API) Authorize(ctx context.Context, userID uuid.UUID, req *oidc.AuthorizationRequest, approved bool) (string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	err := checkImpersonation(ctx)
+	if err != nil {
+		return "", a.logError(err)
+	}
+	var redirect bool
+	var code string
+	err = a.conn.Transaction(func(tx *store.Connection) error {
+		u, err := users.GetActiveUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		c, err := a.authorizationClient(tx, req)
+		if err != nil {
+			return err
+		}
+		// from here on errors are returned to the client
+		redirect = true
+		scopes, err := checkAuthorization(c, req)
+		if err != nil {
+			return err
+		}
+		if !approved {
+			return oidc.NewError(oidc.AccessDenied, "the user denied the request")
+		}
+		consent, err := clients.GrantConsent(tx, u.ID, c.ClientID, scopes)
+		if err != nil {
+			return err
+		}
+		err = audit.LogConsentGranted(ctx, tx, consent)
+		if err != nil {
+			return err
+		}
+		ac := token.NewAuthorizationCode(u.ID, c.ClientID, req.RedirectURI,
+			oidc.FormatScope(scopes), a.config.OIDC.Expiration)
+		ac.Nonce = req.Nonce
+		err = ac.SetChallenge(req.CodeChallenge, req.CodeChallengeMethod)
+		if err != nil {
+			return oidc.NewError(oidc.InvalidRequest, err.Error())
+		}
+		err = tokens.GrantAuthorizationCode(tx, ac)
+		if err != nil {
+			return err
+		}
+		code = ac.Token
+		return nil
+	})
+	if err != nil && !redirect {
+		return "", a.logError(err)
+	}
+	if err != nil {
+		a.logError(err)
+		var oe *oidc.Error
+		if !errors.As(err, &oe) {
+			oe = oidc.NewError(oidc.ServerError, "")
+		}
+		uri, rerr := req.Redirect(oe.Values())
+		if rerr != nil {
+			return "", a.logError(rerr)
+		}
+		return uri, oe
+	}
+	uri, err := req.Redirect(url.Values{key.Code: {code}})
+	if err != nil {
+		return "", a.logError(err)
+	}
+	a.log.Debugf("authorized client %s: %s", req.ClientID, userID)
+	return uri, nil
+}
+
+// authorizationClient returns the client of an authorization request if the
+// client exists and the redirect uri is registered for the client.
+func (a *API) authorizationClient(tx *store.Connection, req *oidc.AuthorizationRequest) (*client.Client, error) {
+	if req == nil {
+		return nil, oidc.NewError(oidc.InvalidRequest, "invalid request")
+	}
+	c, err := clients.GetClient(tx, req.ClientID)
+	if err != nil {
+		a.logError(err)
+		return nil, oidc.NewError(oidc.InvalidClient, "client not found")
+	}
+	if !c.HasRedirectURI(req.RedirectURI) {
+		return nil, oidc.NewError(oidc.InvalidRequest, "invalid redirect uri")
+	}
+	return c, nil
+}
+
+// checkAuthorization checks an authorization request for the client and returns the requested scopes.
+func checkAuthorization(c *client.Client, req *oidc.AuthorizationRequest) ([]string, error) {
+	if req.ResponseType != oidc.ResponseTypeCode {
+		return nil, oidc.NewError(oidc.UnsupportedResponseType, "")
+	}
+	scopes := req.Scopes()
+	if !client.HasAll(scopes, []string{oidc.ScopeOpenID}) {
+		return nil, oidc.NewError(oidc.InvalidScope, "openid scope required")
+	}
+	if !oidc.Supported(scopes) || !c.AllowsScopes(scopes) {
+		return nil, oidc.NewError(oidc.InvalidScope, "")
+	}
+	// public clients can not keep a secret so they must use pkce
+	if !c.Confidential() && req.CodeChallenge == "" {
+		return nil, oidc.NewError(oidc.InvalidRequest, "code challenge required")
+	}
+	return scopes, nil
+}
+
+// ExchangeAuthorizationCode exchanges an authorization code for an access token
+// and an ID token. The access token can only be used with the userinfo endpoint.
+func (a *API) ExchangeAuthorizationCode(ctx context.Context, req *oidc.TokenRequest) (*oidc.TokenResponse, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if req == nil {
+		err := oidc.NewError(oidc.InvalidRequest, "invalid request")
+		return nil, a.logError(err)
+	}
+	if req.GrantType != oidc.GrantAuthorizationCode {
+		err := oidc.NewError(oidc.UnsupportedGrantType, "")
+		return nil, a.logError(err)
+	}
+	c, err := a.authenticateClient(req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+	ac, err := a.useAuthorizationCode(c, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if ac.RedirectURI != req.RedirectURI {
+		err = oidc.NewError(oidc.InvalidGrant, "invalid redirect uri")
+		return nil, a.logError(err)
+	}
+	if !ac.VerifyChallenge(req.CodeVerifier) {
+		err = oidc.NewError(oidc.InvalidGrant, "invalid code verifier")
+		return nil, a.logError(err)
+	}
+	var res *oidc.TokenResponse
+	err = a.conn.Transaction(func(tx *store.Connection) error {
+		u, err := users.GetActiveUser(tx, ac.UserID)
+		if err != nil {
+			a.logError(err)
+			return oidc.NewError(oidc.InvalidGrant, "invalid user")
+		}
+		scopes := oidc.ParseScope(ac.Scope)
+		consent, err := clients.GetConsent(tx, u.ID, c.ClientID)
+		if err != nil || !consent.Covers(scopes) {
+			return oidc.NewError(oidc.InvalidGrant, "consent revoked")
+		}
+		exp := a.config.JWT.Expiration
+		ot, err := tokens.GrantOAuthToken(tx, u.ID, c.ClientID, ac.Scope, exp)
+		if err != nil {
+			return err
+		}
+		err = audit.LogTokenGranted(ctx, tx, ot)
+		if err != nil {
+			return err
+		}
+		idToken, err := a.idToken(u, c.ClientID, ac.Nonce, scopes)
+		if err != nil {
+			return err
+		}
+		res = &oidc.TokenResponse{
+			AccessToken: ot.Token,
+			TokenType:   oidc.TokenTypeBearer,
+			ExpiresIn:   int(exp.Seconds()),
+			IDToken:     idToken,
+			Scope:       ac.Scope,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	return res, nil
+}
+
+// authenticateClient returns the client if the client secret is valid.
+func (a *API) authenticateClient(clientID, secret string) (*client.Client, error) {
+	c, err := clients.GetClient(a.conn, clientID)
+	if err == nil {
+		err = c.Authenticate(secret)
+	}
+	if err != nil {
+		a.logError(err)
+		err = oidc.NewError(oidc.InvalidClient, "client authentication failed")
+		return nil, err
+	}
+	return c, nil
+}
+
+// useAuthorizationCode burns the authorization code issued to the client.
+// The code is burned before it is checked so it can only be tried once.
+func (a *API) useAuthorizationCode(c *client.Client, code string) (*token.AuthorizationCode, error) {
+	var ac *token.AuthorizationCode
+	err := a.conn.Transaction(func(tx *store.Connection) (err error) {
+		ac, err = tokens.GetAuthorizationCode(tx, code)
+		if err != nil {
+			return err
+		}
+		if ac.ClientID != c.ClientID {
+			return errors.New("invalid client id")
+		}
+		return tokens.UseToken(tx, ac)
+	})
+	if err != nil {
+		a.logError(err)
+		err = oidc.NewError(oidc.InvalidGrant, "invalid authorization code")
+		return nil, err
+	}
+	return ac, nil
+}
+
+// idToken returns a signed ID token for the user issued to the client.
+func (a *API) idToken(u *user.User, clientID, nonce string, scopes []string) (string, error) {
+	claims := jwt.NewIDClaims(u, clientID, nonce)
+	if claims == nil {
+		return "", errors.New("invalid user")
+	}
+	for k, v := range oidc.UserInfo(u, scopes) {
+		err := claims.Set(k, v)
+		if err != nil {
+			return "", err
+		}
+	}
+	return jwt.NewIDToken(a.config.JWT, a.config.OIDC.Issuer, claims).Bearer()
+}
+
+// GetUserInfo returns the claims for the user of an access token
+// issued by the OpenID Connect provider.
+func (a *API) GetUserInfo(ctx context.Context, accessToken string) (types.Map, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var info types.Map
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		ot, err := tokens.GetOAuthToken(tx, accessToken)
+		if err != nil {
+			return err
+		}
+		if !ot.Usable() {
+			return errors.New("invalid token")
+		}
+		u, err := users.GetActiveUser(tx, ot.UserID)
+		if err != nil {
+			return err
+		}
+		info = oidc.UserInfo(u, oidc.ParseScope(ot.Scope))
+		return nil
+	})
+	if err != nil {
+		a.logError(err)
+		err = oidc.NewError(oidc.InvalidToken, "")
+		return nil, err
+	}
+	return info, nil
+}
+
+// GetConsents returns the consents the user has given to clients.
+func (a *API) GetConsents(ctx context.Context, userID uuid.UUID) ([]*client.Consent, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var list []*client.Consent
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		u, err := users.GetUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		list, err = clients.GetConsents(tx, u.ID)
+		return err
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	return list, nil
+}
+
+// RevokeConsent revokes the consent the user has given to a client. The
+// codes and access tokens issued to the client for the user are revoked.
+func (a *API) RevokeConsent(ctx context.Context, userID uuid.UUID, clientID string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		u, err := users.GetUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		consent, err := clients.GetConsent(tx, u.ID, clientID)
+		if err != nil {
+			return err
+		}
+		err = tokens.RevokeOAuthTokens(tx, u.ID, clientID)
+		if err != nil {
+			return err
+		}
+		err = clients.RevokeConsent(tx, u.ID, clientID)
+		if err != nil {
+			return err
+		}
+		return audit.LogConsentRevoked(ctx, tx, consent)
+	})
+	if err != nil {
+		return a.logError(err)
+	}
+	a.log.Debugf("revoked consent %s: %s", clientID, userID)
+	return nil
+}
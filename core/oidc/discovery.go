@@ -0,0 +1,104 @@
+package oidc
+
+import (
+	"strings"
+
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/models/token"
+)
+
+// Endpoint paths relative to the issuer.
+const (
+	AuthorizationPath       = "/oauth/authorize"
+	TokenPath               = "/oauth/token"
+	UserInfoPath            = "/oauth/userinfo"
+	DeviceAuthorizationPath = "/oauth/device/code"
+	DevicePath              = "/oauth/device"
+	JWKSPath                = "/.well-known/jwks.json"
+)
+
+// Client authentication methods
+const (
+	ClientSecretBasic = "client_secret_basic"
+	ClientSecretPost  = "client_secret_post"
+	ClientAuthNone    = "none"
+)
+
+// Discovery is an OpenID Connect discovery document.
+type Discovery struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserInfoEndpoint                  string   `json:"userinfo_endpoint"`
+	DeviceAuthorizationEndpoint       string   `json:"device_authorization_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
+
+// NewDiscovery returns the discovery document for the provider. If a consent
+// screen is configured it is published as the authorization endpoint.
+func NewDiscovery(c *config.Config) *Discovery {
+	issuer := strings.TrimSuffix(c.OIDC.Issuer, "/")
+	authorize := c.OIDC.ConsentURL
+	if authorize == "" {
+		authorize = issuer + AuthorizationPath
+	}
+	return &Discovery{
+		Issuer:                      c.OIDC.Issuer,
+		AuthorizationEndpoint:       authorize,
+		TokenEndpoint:               issuer + TokenPath,
+		UserInfoEndpoint:            issuer + UserInfoPath,
+		DeviceAuthorizationEndpoint: issuer + DeviceAuthorizationPath,
+		JWKSURI:                     issuer + JWKSPath,
+		ScopesSupported:             SupportedScopes,
+		ResponseTypesSupported:      []string{ResponseTypeCode},
+		GrantTypesSupported: []string{
+			GrantAuthorizationCode,
+			GrantClientCredentials,
+			GrantDeviceCode,
+		},
+		SubjectTypesSupported: []string{"public"},
+		IDTokenSigningAlgValuesSupported: []string{
+			c.JWT.Algorithm,
+		},
+		TokenEndpointAuthMethodsSupported: []string{
+			ClientSecretBasic,
+			ClientSecretPost,
+			ClientAuthNone,
+		},
+		CodeChallengeMethodsSupported: []string{
+			token.ChallengeS256,
+			token.ChallengePlain,
+		},
+		ClaimsSupported: []string{
+			ClaimSubject,
+			ClaimName,
+			ClaimGivenName,
+			ClaimFamilyName,
+			ClaimPreferredUsername,
+			ClaimPicture,
+			ClaimUpdatedAt,
+			ClaimEmail,
+			ClaimEmailVerified,
+			ClaimPhoneNumber,
+			ClaimPhoneNumberVerified,
+		},
+	}
+}
+
+// VerificationURI returns the uri of the device verification page that users
+// enter a user code at. If a verification page is not configured it is the
+// device verification api.
+func VerificationURI(c *config.Config) string {
+	if c.OIDC.VerificationURL != "" {
+		return c.OIDC.VerificationURL
+	}
+	return strings.TrimSuffix(c.OIDC.Issuer, "/") + DevicePath
+}
@@ -0,0 +1,38 @@
+package oidc
+
+import (
+	"testing"
+
+	"github.com/jrapoport/gothic/test/tconf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDiscovery(t *testing.T) {
+	t.Parallel()
+	c := tconf.Config(t)
+	c.OIDC.Issuer = "https://id.example.com/"
+	d := NewDiscovery(c)
+	assert.Equal(t, c.OIDC.Issuer, d.Issuer)
+	assert.Equal(t, "https://id.example.com/oauth/authorize", d.AuthorizationEndpoint)
+	assert.Equal(t, "https://id.example.com/oauth/token", d.TokenEndpoint)
+	assert.Equal(t, "https://id.example.com/oauth/userinfo", d.UserInfoEndpoint)
+	assert.Equal(t, "https://id.example.com/oauth/device/code", d.DeviceAuthorizationEndpoint)
+	assert.Equal(t, "https://id.example.com/.well-known/jwks.json", d.JWKSURI)
+	assert.Equal(t, SupportedScopes, d.ScopesSupported)
+	assert.Equal(t, []string{ResponseTypeCode}, d.ResponseTypesSupported)
+	assert.Equal(t, []string{c.JWT.Algorithm}, d.IDTokenSigningAlgValuesSupported)
+	assert.Contains(t, d.CodeChallengeMethodsSupported, "S256")
+	assert.Contains(t, d.GrantTypesSupported, GrantDeviceCode)
+	c.OIDC.ConsentURL = "https://www.example.com/consent"
+	d = NewDiscovery(c)
+	assert.Equal(t, c.OIDC.ConsentURL, d.AuthorizationEndpoint)
+}
+
+func TestVerificationURI(t *testing.T) {
+	t.Parallel()
+	c := tconf.Config(t)
+	c.OIDC.Issuer = "https://id.example.com/"
+	assert.Equal(t, "https://id.example.com/oauth/device", VerificationURI(c))
+	c.OIDC.VerificationURL = "https://www.example.com/device"
+	assert.Equal(t, c.OIDC.VerificationURL, VerificationURI(c))
+}
@@ -0,0 +1,51 @@
+package oidc
+
+import "net/url"
+
+// ErrorCode is an OAuth 2.0 error code.
+type ErrorCode string
+
+// Error codes
+const (
+	AccessDenied            ErrorCode = "access_denied"
+	AuthorizationPending    ErrorCode = "authorization_pending"
+	ExpiredToken            ErrorCode = "expired_token"
+	InvalidClient           ErrorCode = "invalid_client"
+	InvalidGrant            ErrorCode = "invalid_grant"
+	InvalidRequest          ErrorCode = "invalid_request"
+	InvalidScope            ErrorCode = "invalid_scope"
+	InvalidToken            ErrorCode = "invalid_token"
+	ServerError             ErrorCode = "server_error"
+	SlowDown                ErrorCode = "slow_down"
+	UnauthorizedClient      ErrorCode = "unauthorized_client"
+	UnsupportedGrantType    ErrorCode = "unsupported_grant_type"
+	UnsupportedResponseType ErrorCode = "unsupported_response_type"
+)
+
+// Error is an OAuth 2.0 error response.
+type Error struct {
+	Code        ErrorCode `json:"error" form:"error"`
+	Description string    `json:"error_description,omitempty" form:"error_description"`
+}
+
+// NewError returns a new error for the code.
+func NewError(code ErrorCode, description string) *Error {
+	return &Error{Code: code, Description: description}
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	if e.Description == "" {
+		return string(e.Code)
+	}
+	return string(e.Code) + ": " + e.Description
+}
+
+// Values returns the error as url query values.
+func (e *Error) Values() url.Values {
+	v := url.Values{"error": {string(e.Code)}}
+	if e.Description != "" {
+		v.Set("error_description", e.Description)
+	}
+	return v
+}
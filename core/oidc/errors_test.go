@@ -0,0 +1,25 @@
+package oidc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError(t *testing.T) {
+	t.Parallel()
+	var err error = NewError(InvalidGrant, "")
+	assert.EqualError(t, err, "invalid_grant")
+	err = NewError(InvalidRequest, "code required")
+	assert.EqualError(t, err, "invalid_request: code required")
+	var oe *Error
+	assert.True(t, errors.As(err, &oe))
+	assert.Equal(t, InvalidRequest, oe.Code)
+	v := oe.Values()
+	assert.Equal(t, "invalid_request", v.Get("error"))
+	assert.Equal(t, "code required", v.Get("error_description"))
+	v = NewError(AccessDenied, "").Values()
+	assert.Equal(t, "access_denied", v.Get("error"))
+	assert.NotContains(t, v, "error_description")
+}
@@ -0,0 +1,51 @@
+package oidc
+
+import (
+	"github.com/jrapoport/gothic/models/client"
+)
+
+// Scopes
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+	ScopePhone   = "phone"
+)
+
+// SupportedScopes are the scopes supported by the provider.
+var SupportedScopes = []string{
+	ScopeOpenID,
+	ScopeProfile,
+	ScopeEmail,
+	ScopePhone,
+}
+
+// Response types
+const (
+	ResponseTypeCode = "code"
+)
+
+// Grant types
+const (
+	GrantAuthorizationCode = "authorization_code"
+	GrantClientCredentials = "client_credentials"
+	GrantDeviceCode        = "urn:ietf:params:oauth:grant-type:device_code"
+)
+
+// TokenTypeBearer is the type of the access tokens issued by the provider.
+const TokenTypeBearer = "Bearer"
+
+// ParseScope splits a space separated scope into a list of scopes.
+func ParseScope(scope string) []string {
+	return client.SplitList(scope)
+}
+
+// FormatScope joins a list of scopes into a space separated scope.
+func FormatScope(scopes []string) string {
+	return client.JoinList(scopes)
+}
+
+// Supported returns true if all the scopes are supported by the provider.
+func Supported(scopes []string) bool {
+	return client.HasAll(SupportedScopes, scopes)
+}
@@ -0,0 +1,23 @@
+package oidc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseScope(t *testing.T) {
+	t.Parallel()
+	assert.Empty(t, ParseScope(""))
+	scopes := ParseScope(" openid  email ")
+	assert.Equal(t, []string{ScopeOpenID, ScopeEmail}, scopes)
+	assert.Equal(t, "openid email", FormatScope(scopes))
+	assert.Equal(t, "openid email", FormatScope(append(scopes, ScopeOpenID)))
+}
+
+func TestSupported(t *testing.T) {
+	t.Parallel()
+	assert.True(t, Supported(nil))
+	assert.True(t, Supported(SupportedScopes))
+	assert.False(t, Supported([]string{ScopeOpenID, "address"}))
+}
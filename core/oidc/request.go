@@ -0,0 +1,91 @@
+package oidc
+
+import (
+	"net/url"
+
+	"github.com/jrapoport/gothic/models/types/key"
+)
+
+// AuthorizationRequest is an authorization code flow request.
+type AuthorizationRequest struct {
+	ClientID            string `json:"client_id" form:"client_id"`
+	RedirectURI         string `json:"redirect_uri" form:"redirect_uri"`
+	ResponseType        string `json:"response_type" form:"response_type"`
+	Scope               string `json:"scope" form:"scope"`
+	State               string `json:"state" form:"state"`
+	Nonce               string `json:"nonce" form:"nonce"`
+	CodeChallenge       string `json:"code_challenge" form:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method" form:"code_challenge_method"`
+}
+
+// Scopes returns the requested scopes.
+func (r AuthorizationRequest) Scopes() []string {
+	return ParseScope(r.Scope)
+}
+
+// Redirect returns the redirect uri of the request with the query
+// values and the state of the request added.
+func (r AuthorizationRequest) Redirect(v url.Values) (string, error) {
+	u, err := url.Parse(r.RedirectURI)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	for k := range v {
+		q.Set(k, v.Get(k))
+	}
+	if r.State != "" {
+		q.Set(key.State, r.State)
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// Authorization holds the details of an authorization request that
+// are shown to the user when they are asked for their consent.
+type Authorization struct {
+	ClientID   string   `json:"client_id"`
+	ClientName string   `json:"client_name"`
+	Scopes     []string `json:"scopes"`
+	// Consented is true if the user has already consented to the scopes.
+	Consented bool `json:"consented"`
+}
+
+// TokenRequest is a token endpoint request.
+type TokenRequest struct {
+	GrantType    string `json:"grant_type" form:"grant_type"`
+	Code         string `json:"code" form:"code"`
+	RedirectURI  string `json:"redirect_uri" form:"redirect_uri"`
+	ClientID     string `json:"client_id" form:"client_id"`
+	ClientSecret string `json:"client_secret" form:"client_secret"`
+	CodeVerifier string `json:"code_verifier" form:"code_verifier"`
+	Scope        string `json:"scope" form:"scope"`
+	DeviceCode   string `json:"device_code" form:"device_code"`
+}
+
+// TokenResponse is a token endpoint response.
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// DeviceAuthorizationRequest is a device authorization endpoint request.
+type DeviceAuthorizationRequest struct {
+	ClientID     string `json:"client_id" form:"client_id"`
+	ClientSecret string `json:"client_secret" form:"client_secret"`
+	Scope        string `json:"scope" form:"scope"`
+}
+
+// DeviceAuthorizationResponse is a device authorization endpoint response.
+type DeviceAuthorizationResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval,omitempty"`
+}
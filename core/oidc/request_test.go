@@ -0,0 +1,36 @@
+package oidc
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorizationRequest_Redirect(t *testing.T) {
+	t.Parallel()
+	req := AuthorizationRequest{
+		RedirectURI: "https://example.com/callback?foo=bar",
+		Scope:       "openid email",
+		State:       "state",
+	}
+	assert.Equal(t, []string{ScopeOpenID, ScopeEmail}, req.Scopes())
+	uri, err := req.Redirect(url.Values{key.Code: {"code"}})
+	require.NoError(t, err)
+	u, err := url.Parse(uri)
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", u.Host)
+	assert.Equal(t, "/callback", u.Path)
+	assert.Equal(t, "bar", u.Query().Get("foo"))
+	assert.Equal(t, "code", u.Query().Get(key.Code))
+	assert.Equal(t, "state", u.Query().Get(key.State))
+	req.State = ""
+	uri, err = req.Redirect(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/callback?foo=bar", uri)
+	req.RedirectURI = "\n"
+	_, err = req.Redirect(nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,62 @@
+package oidc
+
+import (
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/models/user"
+)
+
+// Standard claims
+const (
+	ClaimSubject             = "sub"
+	ClaimName                = "name"
+	ClaimGivenName           = "given_name"
+	ClaimFamilyName          = "family_name"
+	ClaimPreferredUsername   = "preferred_username"
+	ClaimPicture             = "picture"
+	ClaimUpdatedAt           = "updated_at"
+	ClaimEmail               = "email"
+	ClaimEmailVerified       = "email_verified"
+	ClaimPhoneNumber         = "phone_number"
+	ClaimPhoneNumberVerified = "phone_number_verified"
+)
+
+// UserInfo returns the standard claims for the user that are allowed by the scopes.
+func UserInfo(u *user.User, scopes []string) types.Map {
+	info := types.Map{
+		ClaimSubject: u.ID.String(),
+	}
+	for _, scope := range scopes {
+		switch scope {
+		case ScopeProfile:
+			setProfile(info, u)
+		case ScopeEmail:
+			info[ClaimEmail] = u.Email
+			info[ClaimEmailVerified] = u.ConfirmedAt != nil
+		case ScopePhone:
+			if u.Phone == nil {
+				break
+			}
+			info[ClaimPhoneNumber] = *u.Phone
+			info[ClaimPhoneNumberVerified] = u.PhoneConfirmedAt != nil
+		}
+	}
+	return info
+}
+
+func setProfile(info types.Map, u *user.User) {
+	info[ClaimPreferredUsername] = u.Username
+	info[ClaimUpdatedAt] = u.UpdatedAt.Unix()
+	if u.Username != "" {
+		info[ClaimName] = u.Username
+	}
+	set := func(claim, k string) {
+		if v, ok := u.Data[k].(string); ok && v != "" {
+			info[claim] = v
+		}
+	}
+	set(ClaimName, key.Name)
+	set(ClaimGivenName, key.FirstName)
+	set(ClaimFamilyName, key.LastName)
+	set(ClaimPicture, key.AvatarURL)
+}
@@ -0,0 +1,50 @@
+package oidc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserInfo(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	phone := "+15555555555"
+	u := &user.User{
+		ID:          uuid.New(),
+		Email:       "peaches@example.com",
+		Username:    "peaches",
+		Phone:       &phone,
+		ConfirmedAt: &now,
+		UpdatedAt:   now,
+		Data: types.Map{
+			key.FirstName: "Peaches",
+			key.LastName:  "LaRue",
+			key.AvatarURL: "https://example.com/avatar.png",
+		},
+	}
+	info := UserInfo(u, []string{ScopeOpenID})
+	assert.Equal(t, types.Map{ClaimSubject: u.ID.String()}, info)
+	info = UserInfo(u, SupportedScopes)
+	assert.Equal(t, types.Map{
+		ClaimSubject:             u.ID.String(),
+		ClaimName:                "peaches",
+		ClaimGivenName:           "Peaches",
+		ClaimFamilyName:          "LaRue",
+		ClaimPreferredUsername:   "peaches",
+		ClaimPicture:             "https://example.com/avatar.png",
+		ClaimUpdatedAt:           now.Unix(),
+		ClaimEmail:               u.Email,
+		ClaimEmailVerified:       true,
+		ClaimPhoneNumber:         phone,
+		ClaimPhoneNumberVerified: false,
+	}, info)
+	u.Phone = nil
+	info = UserInfo(u, []string{ScopeOpenID, ScopePhone})
+	assert.NotContains(t, info, ClaimPhoneNumber)
+}
@@ -0,0 +1,449 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/clients"
+	"github.com/jrapoport/gothic/core/oidc"
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/jwt"
+	"github.com/jrapoport/gothic/models/auditlog"
+	"github.com/jrapoport/gothic/models/client"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRedirectURI = "https://example.com/callback"
+
+func testClient(t *testing.T, a *API, confidential bool) (*client.Client, string) {
+	c, secret, err := a.CreateClient(rootContext(a), "test",
+		[]string{testRedirectURI}, nil, confidential)
+	require.NoError(t, err)
+	return c, secret
+}
+
+func testAuthorizationRequest(c *client.Client) *oidc.AuthorizationRequest {
+	return &oidc.AuthorizationRequest{
+		ClientID:     c.ClientID,
+		RedirectURI:  testRedirectURI,
+		ResponseType: oidc.ResponseTypeCode,
+		Scope:        "openid profile email",
+		State:        "state",
+		Nonce:        "nonce",
+	}
+}
+
+func authorizeCode(t *testing.T, a *API, u *user.User, req *oidc.AuthorizationRequest) string {
+	uri, err := a.Authorize(testContext(a), u.ID, req, true)
+	require.NoError(t, err)
+	ru, err := url.Parse(uri)
+	require.NoError(t, err)
+	assert.Equal(t, req.State, ru.Query().Get(key.State))
+	code := ru.Query().Get(key.Code)
+	require.NotEmpty(t, code)
+	return code
+}
+
+func oauthErrorCode(t *testing.T, err error) oidc.ErrorCode {
+	var oe *oidc.Error
+	require.True(t, errors.As(err, &oe), err)
+	return oe.Code
+}
+
+func TestAPI_CreateClient(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	uris := []string{testRedirectURI}
+	// not admin
+	_, _, err := a.CreateClient(testContext(a), "test", uris, nil, true)
+	assert.Error(t, err)
+	_, _, err = a.CreateClient(nil, "test", uris, nil, true)
+	assert.Error(t, err)
+	// bad admin
+	ctx := testContext(a)
+	ctx.SetAdminID(uuid.New())
+	_, _, err = a.CreateClient(ctx, "test", uris, nil, true)
+	assert.Error(t, err)
+	ctx = rootContext(a)
+	_, _, err = a.CreateClient(ctx, "", uris, nil, true)
+	assert.Error(t, err)
+	_, _, err = a.CreateClient(ctx, "test", nil, nil, true)
+	assert.Error(t, err)
+	_, _, err = a.CreateClient(ctx, "test", uris, []string{"address"}, true)
+	assert.Error(t, err)
+	c, secret, err := a.CreateClient(ctx, "test", uris, nil, true)
+	require.NoError(t, err)
+	assert.NotEmpty(t, secret)
+	assert.Equal(t, oidc.SupportedScopes, c.ScopeList())
+	assert.NoError(t, c.Authenticate(secret))
+	hasAuditEntry(t, a, auditlog.ClientCreated, user.SystemID)
+	scopes := []string{oidc.ScopeOpenID, oidc.ScopeEmail}
+	c, secret, err = a.CreateClient(ctx, "public", uris, scopes, false)
+	require.NoError(t, err)
+	assert.Empty(t, secret)
+	assert.False(t, c.Confidential())
+	assert.Equal(t, scopes, c.ScopeList())
+}
+
+func TestAPI_GetClients(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	c, _ := testClient(t, a, true)
+	_, err := a.GetClients(testContext(a))
+	assert.Error(t, err)
+	_, err = a.GetClient(nil, c.ClientID)
+	assert.Error(t, err)
+	ctx := rootContext(a)
+	list, err := a.GetClients(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, c.ClientID, list[0].ClientID)
+	_, err = a.GetClient(ctx, uuid.New().String())
+	assert.Error(t, err)
+	got, err := a.GetClient(ctx, c.ClientID)
+	require.NoError(t, err)
+	assert.Equal(t, c.Name, got.Name)
+}
+
+func TestAPI_DeleteClient(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	c, secret := testClient(t, a, true)
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	req := testAuthorizationRequest(c)
+	code := authorizeCode(t, a, u, req)
+	err := a.DeleteClient(testContext(a), c.ClientID)
+	assert.Error(t, err)
+	ctx := rootContext(a)
+	err = a.DeleteClient(ctx, uuid.New().String())
+	assert.Error(t, err)
+	err = a.DeleteClient(ctx, c.ClientID)
+	require.NoError(t, err)
+	hasAuditEntry(t, a, auditlog.ClientDeleted, user.SystemID)
+	_, err = a.GetClient(ctx, c.ClientID)
+	assert.Error(t, err)
+	_, err = clients.GetConsent(a.conn, u.ID, c.ClientID)
+	assert.Error(t, err)
+	_, err = tokens.GetAuthorizationCode(a.conn, code)
+	assert.Error(t, err)
+	_, err = a.ExchangeAuthorizationCode(nil, &oidc.TokenRequest{
+		GrantType:    oidc.GrantAuthorizationCode,
+		Code:         code,
+		RedirectURI:  testRedirectURI,
+		ClientID:     c.ClientID,
+		ClientSecret: secret,
+	})
+	assert.Equal(t, oidc.InvalidClient, oauthErrorCode(t, err))
+}
+
+func TestAPI_GetAuthorization(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	c, _ := testClient(t, a, true)
+	u := testUser(t, a)
+	ctx := testContext(a)
+	req := testAuthorizationRequest(c)
+	// inactive user
+	_, err := a.GetAuthorization(ctx, u.ID, req)
+	assert.Error(t, err)
+	u = confirmUser(t, a, u)
+	_, err = a.GetAuthorization(ctx, u.ID, nil)
+	assert.Error(t, err)
+	auth, err := a.GetAuthorization(nil, u.ID, req)
+	require.NoError(t, err)
+	assert.Equal(t, c.ClientID, auth.ClientID)
+	assert.Equal(t, c.Name, auth.ClientName)
+	assert.Equal(t, req.Scopes(), auth.Scopes)
+	assert.False(t, auth.Consented)
+	authorizeCode(t, a, u, req)
+	auth, err = a.GetAuthorization(ctx, u.ID, req)
+	require.NoError(t, err)
+	assert.True(t, auth.Consented)
+	req.Scope = "openid phone"
+	auth, err = a.GetAuthorization(ctx, u.ID, req)
+	require.NoError(t, err)
+	assert.False(t, auth.Consented)
+}
+
+func TestAPI_Authorize(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	c, _ := testClient(t, a, true)
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	ctx := testContext(a)
+	// errors that are not redirected
+	tests := []func(r *oidc.AuthorizationRequest){
+		func(r *oidc.AuthorizationRequest) { r.ClientID = "" },
+		func(r *oidc.AuthorizationRequest) { r.ClientID = uuid.New().String() },
+		func(r *oidc.AuthorizationRequest) { r.RedirectURI = "" },
+		func(r *oidc.AuthorizationRequest) { r.RedirectURI = "https://example.com/other" },
+	}
+	for _, test := range tests {
+		req := testAuthorizationRequest(c)
+		test(req)
+		uri, err := a.Authorize(ctx, u.ID, req, true)
+		assert.Error(t, err)
+		assert.Empty(t, uri)
+	}
+	uri, err := a.Authorize(ctx, uuid.New(), testAuthorizationRequest(c), true)
+	assert.Error(t, err)
+	assert.Empty(t, uri)
+	// errors that are redirected
+	redirectTests := []struct {
+		fn       func(r *oidc.AuthorizationRequest)
+		approved bool
+		code     oidc.ErrorCode
+	}{
+		{func(r *oidc.AuthorizationRequest) {}, false, oidc.AccessDenied},
+		{func(r *oidc.AuthorizationRequest) { r.ResponseType = "token" }, true, oidc.UnsupportedResponseType},
+		{func(r *oidc.AuthorizationRequest) { r.Scope = "email" }, true, oidc.InvalidScope},
+		{func(r *oidc.AuthorizationRequest) { r.Scope = "openid address" }, true, oidc.InvalidScope},
+		{func(r *oidc.AuthorizationRequest) {
+			r.CodeChallenge = "challenge"
+			r.CodeChallengeMethod = "bad"
+		}, true, oidc.InvalidRequest},
+	}
+	for _, test := range redirectTests {
+		req := testAuthorizationRequest(c)
+		test.fn(req)
+		uri, err = a.Authorize(ctx, u.ID, req, test.approved)
+		assert.Equal(t, test.code, oauthErrorCode(t, err))
+		ru, err := url.Parse(uri)
+		require.NoError(t, err)
+		assert.Equal(t, string(test.code), ru.Query().Get("error"))
+		assert.Equal(t, req.State, ru.Query().Get(key.State))
+		assert.Empty(t, ru.Query().Get(key.Code))
+	}
+	_, err = clients.GetConsent(a.conn, u.ID, c.ClientID)
+	assert.Error(t, err)
+	req := testAuthorizationRequest(c)
+	code := authorizeCode(t, a, u, req)
+	ac, err := tokens.GetAuthorizationCode(a.conn, code)
+	require.NoError(t, err)
+	assert.Equal(t, u.ID, ac.UserID)
+	assert.Equal(t, c.ClientID, ac.ClientID)
+	assert.Equal(t, req.Nonce, ac.Nonce)
+	assert.Equal(t, req.Scope, ac.Scope)
+	assert.Equal(t, a.config.OIDC.Expiration, ac.ExpirationDate().Sub(ac.CreatedAt).Round(1e9))
+	consent, err := clients.GetConsent(a.conn, u.ID, c.ClientID)
+	require.NoError(t, err)
+	assert.True(t, consent.Covers(req.Scopes()))
+	hasAuditEntry(t, a, auditlog.ConsentGranted, u.ID)
+	// public clients must use pkce
+	pub, _ := testClient(t, a, false)
+	req = testAuthorizationRequest(pub)
+	_, err = a.Authorize(ctx, u.ID, req, true)
+	assert.Equal(t, oidc.InvalidRequest, oauthErrorCode(t, err))
+	req.CodeChallenge = "challenge"
+	authorizeCode(t, a, u, req)
+}
+
+func TestAPI_ExchangeAuthorizationCode(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	c, secret := testClient(t, a, true)
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	ctx := testContext(a)
+	areq := testAuthorizationRequest(c)
+	code := authorizeCode(t, a, u, areq)
+	newRequest := func() *oidc.TokenRequest {
+		return &oidc.TokenRequest{
+			GrantType:    oidc.GrantAuthorizationCode,
+			Code:         code,
+			RedirectURI:  testRedirectURI,
+			ClientID:     c.ClientID,
+			ClientSecret: secret,
+		}
+	}
+	_, err := a.ExchangeAuthorizationCode(ctx, nil)
+	assert.Equal(t, oidc.InvalidRequest, oauthErrorCode(t, err))
+	tests := []struct {
+		fn   func(r *oidc.TokenRequest)
+		code oidc.ErrorCode
+	}{
+		{func(r *oidc.TokenRequest) { r.GrantType = "password" }, oidc.UnsupportedGrantType},
+		{func(r *oidc.TokenRequest) { r.ClientID = "" }, oidc.InvalidClient},
+		{func(r *oidc.TokenRequest) { r.ClientSecret = "" }, oidc.InvalidClient},
+		{func(r *oidc.TokenRequest) { r.ClientSecret = "bad" }, oidc.InvalidClient},
+		{func(r *oidc.TokenRequest) { r.Code = "" }, oidc.InvalidGrant},
+		{func(r *oidc.TokenRequest) { r.Code = "bad" }, oidc.InvalidGrant},
+	}
+	for _, test := range tests {
+		req := newRequest()
+		test.fn(req)
+		_, err = a.ExchangeAuthorizationCode(ctx, req)
+		assert.Equal(t, test.code, oauthErrorCode(t, err))
+	}
+	res, err := a.ExchangeAuthorizationCode(ctx, newRequest())
+	require.NoError(t, err)
+	assert.NotEmpty(t, res.AccessToken)
+	assert.Equal(t, oidc.TokenTypeBearer, res.TokenType)
+	assert.Equal(t, int(a.config.JWT.Expiration.Seconds()), res.ExpiresIn)
+	assert.Equal(t, areq.Scope, res.Scope)
+	hasAuditEntry(t, a, auditlog.Granted, u.ID)
+	claims, err := jwt.ParseIDClaims(a.config.JWT, a.config.OIDC.Issuer, c.ClientID, res.IDToken)
+	require.NoError(t, err)
+	assert.Equal(t, u.ID, claims.UserID())
+	assert.Equal(t, areq.Nonce, claims.Nonce())
+	assert.Equal(t, c.ClientID, claims.AuthorizedParty())
+	email, _ := claims.Get(oidc.ClaimEmail)
+	assert.Equal(t, u.Email, email)
+	_, ok := claims.Get(oidc.ClaimPhoneNumber)
+	assert.False(t, ok)
+	// ID tokens are not bearer tokens
+	_, err = jwt.ParseUserClaims(a.config.JWT, res.IDToken)
+	assert.Error(t, err)
+	// codes are single use
+	_, err = a.ExchangeAuthorizationCode(ctx, newRequest())
+	assert.Equal(t, oidc.InvalidGrant, oauthErrorCode(t, err))
+	// codes are burned when they are checked
+	code = authorizeCode(t, a, u, areq)
+	req := newRequest()
+	req.RedirectURI = "https://example.com/other"
+	_, err = a.ExchangeAuthorizationCode(ctx, req)
+	assert.Equal(t, oidc.InvalidGrant, oauthErrorCode(t, err))
+	_, err = a.ExchangeAuthorizationCode(ctx, newRequest())
+	assert.Equal(t, oidc.InvalidGrant, oauthErrorCode(t, err))
+	// codes are issued to a client
+	c2, secret2 := testClient(t, a, true)
+	code = authorizeCode(t, a, u, areq)
+	req = newRequest()
+	req.ClientID = c2.ClientID
+	req.ClientSecret = secret2
+	_, err = a.ExchangeAuthorizationCode(ctx, req)
+	assert.Equal(t, oidc.InvalidGrant, oauthErrorCode(t, err))
+	// consent revoked
+	code = authorizeCode(t, a, u, areq)
+	err = clients.RevokeConsent(a.conn, u.ID, c.ClientID)
+	require.NoError(t, err)
+	_, err = a.ExchangeAuthorizationCode(ctx, newRequest())
+	assert.Equal(t, oidc.InvalidGrant, oauthErrorCode(t, err))
+}
+
+func TestAPI_ExchangeAuthorizationCode_PKCE(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	c, _ := testClient(t, a, false)
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	ctx := testContext(a)
+	const verifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	sum := sha256.Sum256([]byte(verifier))
+	areq := testAuthorizationRequest(c)
+	areq.CodeChallenge = base64.RawURLEncoding.EncodeToString(sum[:])
+	areq.CodeChallengeMethod = token.ChallengeS256
+	newRequest := func(code, verifier string) *oidc.TokenRequest {
+		return &oidc.TokenRequest{
+			GrantType:    oidc.GrantAuthorizationCode,
+			Code:         code,
+			RedirectURI:  testRedirectURI,
+			ClientID:     c.ClientID,
+			CodeVerifier: verifier,
+		}
+	}
+	code := authorizeCode(t, a, u, areq)
+	_, err := a.ExchangeAuthorizationCode(ctx, newRequest(code, ""))
+	assert.Equal(t, oidc.InvalidGrant, oauthErrorCode(t, err))
+	code = authorizeCode(t, a, u, areq)
+	_, err = a.ExchangeAuthorizationCode(ctx, newRequest(code, "bad"))
+	assert.Equal(t, oidc.InvalidGrant, oauthErrorCode(t, err))
+	code = authorizeCode(t, a, u, areq)
+	req := newRequest(code, verifier)
+	req.ClientSecret = "secret"
+	_, err = a.ExchangeAuthorizationCode(ctx, req)
+	assert.Equal(t, oidc.InvalidClient, oauthErrorCode(t, err))
+	res, err := a.ExchangeAuthorizationCode(ctx, newRequest(code, verifier))
+	require.NoError(t, err)
+	assert.NotEmpty(t, res.AccessToken)
+	assert.NotEmpty(t, res.IDToken)
+}
+
+func TestAPI_GetUserInfo(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	c, secret := testClient(t, a, true)
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	ctx := testContext(a)
+	areq := testAuthorizationRequest(c)
+	code := authorizeCode(t, a, u, areq)
+	res, err := a.ExchangeAuthorizationCode(ctx, &oidc.TokenRequest{
+		GrantType:    oidc.GrantAuthorizationCode,
+		Code:         code,
+		RedirectURI:  testRedirectURI,
+		ClientID:     c.ClientID,
+		ClientSecret: secret,
+	})
+	require.NoError(t, err)
+	_, err = a.GetUserInfo(ctx, "")
+	assert.Equal(t, oidc.InvalidToken, oauthErrorCode(t, err))
+	_, err = a.GetUserInfo(ctx, "bad")
+	assert.Equal(t, oidc.InvalidToken, oauthErrorCode(t, err))
+	// bearer tokens are not access tokens
+	bt, err := a.GrantBearerToken(ctx, u)
+	require.NoError(t, err)
+	_, err = a.GetUserInfo(ctx, bt.String())
+	assert.Error(t, err)
+	info, err := a.GetUserInfo(nil, res.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, oidc.UserInfo(u, areq.Scopes()), info)
+	assert.Equal(t, u.Email, info[oidc.ClaimEmail])
+	// revoked
+	err = a.RevokeConsent(ctx, u.ID, c.ClientID)
+	require.NoError(t, err)
+	_, err = a.GetUserInfo(ctx, res.AccessToken)
+	assert.Equal(t, oidc.InvalidToken, oauthErrorCode(t, err))
+}
+
+func TestAPI_GetConsents(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	ctx := testContext(a)
+	list, err := a.GetConsents(ctx, u.ID)
+	require.NoError(t, err)
+	assert.Len(t, list, 0)
+	const count = 3
+	for i := 0; i < count; i++ {
+		c, _ := testClient(t, a, true)
+		authorizeCode(t, a, u, testAuthorizationRequest(c))
+	}
+	_, err = a.GetConsents(nil, uuid.New())
+	assert.Error(t, err)
+	list, err = a.GetConsents(nil, u.ID)
+	require.NoError(t, err)
+	assert.Len(t, list, count)
+}
+
+func TestAPI_RevokeConsent(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	c, _ := testClient(t, a, true)
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	ctx := testContext(a)
+	err := a.RevokeConsent(ctx, u.ID, c.ClientID)
+	assert.Error(t, err)
+	code := authorizeCode(t, a, u, testAuthorizationRequest(c))
+	err = a.RevokeConsent(ctx, uuid.New(), c.ClientID)
+	assert.Error(t, err)
+	err = a.RevokeConsent(nil, u.ID, c.ClientID)
+	require.NoError(t, err)
+	hasAuditEntry(t, a, auditlog.ConsentRevoked, u.ID)
+	_, err = clients.GetConsent(a.conn, u.ID, c.ClientID)
+	assert.Error(t, err)
+	_, err = tokens.GetAuthorizationCode(a.conn, code)
+	assert.Error(t, err)
+}
@@ -0,0 +1,370 @@
+package core
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/audit"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/core/orgs"
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/core/users"
+	"github.com/jrapoport/gothic/models/org"
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/store"
+)
+
+// CreateOrg creates a new organization. The user is the owner of the organization.
+func (a *API) CreateOrg(ctx context.Context, userID uuid.UUID, name string, data types.Map) (*org.Organization, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var o *org.Organization
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		u, err := users.GetActiveUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		o, err = orgs.CreateOrg(tx, name, data, u.ID)
+		if err != nil {
+			return err
+		}
+		return audit.LogOrgCreated(ctx, tx, u.ID, o)
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	a.log.Debugf("created organization %s: %s", o.ID, userID)
+	return o, nil
+}
+
+// GetUserOrgs returns the organizations the user is a member of.
+func (a *API) GetUserOrgs(ctx context.Context, userID uuid.UUID) ([]*org.Organization, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var list []*org.Organization
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		u, err := users.GetUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		list, err = orgs.GetUserOrgs(tx, u.ID)
+		return err
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	return list, nil
+}
+
+// GetOrg returns the organization for the id. The user must be a
+// member of the organization.
+func (a *API) GetOrg(ctx context.Context, userID, orgID uuid.UUID) (*org.Organization, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var o *org.Organization
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		_, err := a.validateMember(tx, userID, orgID, org.RoleMember)
+		if err != nil {
+			return err
+		}
+		o, err = orgs.GetOrg(tx, orgID)
+		return err
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	return o, nil
+}
+
+// UpdateOrg updates the name & data of an organization. If the name is
+// nil it is not changed. If the data is nil it is not changed.
+// NOTE: The user must be an admin of the organization.
+func (a *API) UpdateOrg(ctx context.Context, userID, orgID uuid.UUID, name *string, data types.Map) (*org.Organization, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var o *org.Organization
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		_, err := a.validateMember(tx, userID, orgID, org.RoleAdmin)
+		if err != nil {
+			return err
+		}
+		o, err = orgs.UpdateOrg(tx, orgID, name, data)
+		if err != nil {
+			return err
+		}
+		return audit.LogOrgUpdated(ctx, tx, userID, o)
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	a.log.Debugf("updated organization %s: %s", o.ID, userID)
+	return o, nil
+}
+
+// DeleteOrg deletes an organization, its members and its invites.
+// NOTE: The user must be an owner of the organization.
+func (a *API) DeleteOrg(ctx context.Context, userID, orgID uuid.UUID) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		_, err := a.validateMember(tx, userID, orgID, org.RoleOwner)
+		if err != nil {
+			return err
+		}
+		o, err := orgs.DeleteOrg(tx, orgID)
+		if err != nil {
+			return err
+		}
+		return audit.LogOrgDeleted(ctx, tx, userID, o)
+	})
+	if err != nil {
+		return a.logError(err)
+	}
+	a.log.Debugf("deleted organization %s: %s", orgID, userID)
+	return nil
+}
+
+// GetOrgMembers returns the members of the organization. The user
+// must be a member of the organization.
+func (a *API) GetOrgMembers(ctx context.Context, userID, orgID uuid.UUID) ([]*org.Member, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var list []*org.Member
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		_, err := a.validateMember(tx, userID, orgID, org.RoleMember)
+		if err != nil {
+			return err
+		}
+		list, err = orgs.GetMembers(tx, orgID)
+		return err
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	return list, nil
+}
+
+// ChangeOrgMemberRole changes the role of a member of the organization.
+// NOTE: The user must be an admin of the organization. Only an owner
+// can change the role of an owner or make another member an owner.
+func (a *API) ChangeOrgMemberRole(ctx context.Context, userID, orgID, memberID uuid.UUID, role org.Role) (*org.Member, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var m *org.Member
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		admin, err := a.validateMember(tx, userID, orgID, org.RoleAdmin)
+		if err != nil {
+			return err
+		}
+		m, err = orgs.GetMember(tx, orgID, memberID)
+		if err != nil {
+			return err
+		}
+		if (m.IsOwner() || role == org.RoleOwner) && !admin.IsOwner() {
+			return errors.New("owner required")
+		}
+		m, err = orgs.ChangeMemberRole(tx, orgID, memberID, role)
+		if err != nil {
+			return err
+		}
+		return audit.LogOrgMemberRole(ctx, tx, m)
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	a.log.Debugf("changed organization %s member role %s: %s",
+		orgID, role, memberID)
+	return m, nil
+}
+
+// RemoveOrgMember removes a member from the organization. Any member
+// can remove themselves from the organization.
+// NOTE: The user must be an admin of the organization to remove another
+// member. Only an owner can remove an owner.
+func (a *API) RemoveOrgMember(ctx context.Context, userID, orgID, memberID uuid.UUID) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		role := org.RoleAdmin
+		if userID == memberID {
+			role = org.RoleMember
+		}
+		admin, err := a.validateMember(tx, userID, orgID, role)
+		if err != nil {
+			return err
+		}
+		m, err := orgs.GetMember(tx, orgID, memberID)
+		if err != nil {
+			return err
+		}
+		if m.IsOwner() && !admin.IsOwner() {
+			return errors.New("owner required")
+		}
+		m, err = orgs.RemoveMember(tx, orgID, memberID)
+		if err != nil {
+			return err
+		}
+		return audit.LogOrgMemberRemoved(ctx, tx, m)
+	})
+	if err != nil {
+		return a.logError(err)
+	}
+	a.log.Debugf("removed organization %s member: %s", orgID, memberID)
+	return nil
+}
+
+// SendOrgInvite sends an invite mail to join the organization with the
+// role. The invite uses the invite user mail template. If mail is offline
+// the invite is created but not sent.
+// NOTE: The user must be an admin of the organization. Only an owner can
+// invite another admin.
+func (a *API) SendOrgInvite(ctx context.Context, userID, orgID uuid.UUID, toAddress string, role org.Role) (*org.Invite, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	to, err := a.ValidateEmail(toAddress)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	var from string
+	var inv *org.Invite
+	err = a.conn.Transaction(func(tx *store.Connection) error {
+		admin, err := a.validateMember(tx, userID, orgID, org.RoleAdmin)
+		if err != nil {
+			return err
+		}
+		if role >= org.RoleAdmin && !admin.IsOwner() {
+			return errors.New("owner required")
+		}
+		u, err := users.GetActiveUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		from = u.EmailAddress().String()
+		inv, err = orgs.CreateInvite(tx, orgID, u.ID, to, role, a.config.Mail.Expiration)
+		if err != nil {
+			return err
+		}
+		return audit.LogOrgInviteSent(ctx, tx, inv)
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	if a.mail == nil || a.mail.IsOffline() {
+		a.log.Warn("mail not found")
+		return inv, nil
+	}
+	referrerURL := a.config.Mail.InviteUser.ReferralURL
+	err = a.mail.SendInviteUser(from, to, inv.Token, referrerURL)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	err = orgs.InviteSent(a.conn, inv)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	a.log.Debugf("sent organization %s invite: %s", orgID, to)
+	return inv, nil
+}
+
+// AcceptOrgInvite accepts an organization invite for the user. The user
+// must have the email address the invite was sent to.
+func (a *API) AcceptOrgInvite(ctx context.Context, userID uuid.UUID, tok string) (*org.Member, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var m *org.Member
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		u, err := users.GetActiveUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		m, err = orgs.AcceptInvite(tx, tok, u.ID, u.Email)
+		if err != nil {
+			return err
+		}
+		return audit.LogOrgJoined(ctx, tx, m)
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	a.log.Debugf("joined organization %s: %s", m.OrgID, userID)
+	return m, nil
+}
+
+// SwitchOrg switches the active organization of the session for the refresh
+// token and returns a new bearer token with the organization id and role in
+// its claims. If the organization id is uuid.Nil the active organization is
+// cleared. The user must be a member of the organization.
+func (a *API) SwitchOrg(ctx context.Context, userID uuid.UUID, refreshToken string, orgID uuid.UUID) (*tokens.BearerToken, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var bt *tokens.BearerToken
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		u, err := users.GetActiveUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		if orgID != uuid.Nil {
+			_, err = a.validateMember(tx, u.ID, orgID, org.RoleMember)
+			if err != nil {
+				return err
+			}
+		}
+		rt, err := tokens.GetRefreshToken(tx, refreshToken)
+		if err != nil {
+			return err
+		}
+		if rt.UserID != u.ID {
+			return errors.New("invalid token")
+		}
+		if rt.Swapped() {
+			return tokens.ErrRefreshTokenReused
+		}
+		if !rt.Usable() {
+			return errors.New("invalid token")
+		}
+		s := newSession(ctx)
+		s.OrgID = &orgID
+		bt, err = tokens.RefreshBearerToken(tx, a.config.JWT, a.config.Refresh,
+			u, rt.String(), s)
+		if err != nil {
+			return err
+		}
+		return audit.LogOrgSwitched(ctx, tx, u.ID, orgID)
+	})
+	if errors.Is(err, tokens.ErrRefreshTokenReused) {
+		err = a.revokeReusedToken(ctx, refreshToken)
+		if err != nil {
+			return nil, a.logError(err)
+		}
+		return nil, a.logError(tokens.ErrRefreshTokenReused)
+	}
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	a.log.Debugf("switched organization %s: %s", orgID, userID)
+	return bt, nil
+}
+
+// validateMember returns the membership of the user in the organization
+// if the member has at least the role.
+func (a *API) validateMember(conn *store.Connection, userID, orgID uuid.UUID, role org.Role) (*org.Member, error) {
+	m, err := orgs.GetMember(conn, orgID, userID)
+	if err != nil {
+		return nil, errors.New("organization member required")
+	}
+	if m.Role < role {
+		return nil, errors.New("insufficient organization role")
+	}
+	return m, nil
+}
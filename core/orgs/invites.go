@@ -0,0 +1,76 @@
+package orgs
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/models/org"
+	"github.com/jrapoport/gothic/store"
+)
+
+// CreateInvite creates a new invitation to join the organization with the
+// role, sent by the user to the email address.
+func CreateInvite(conn *store.Connection, orgID, userID uuid.UUID, email string, role org.Role, exp time.Duration) (*org.Invite, error) {
+	o, err := GetOrg(conn, orgID)
+	if err != nil {
+		return nil, err
+	}
+	inv := org.NewInvite(o.ID, userID, email, role, exp)
+	err = conn.Create(inv).Error
+	if err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// GetInvite returns the usable invite for the token string if found.
+func GetInvite(conn *store.Connection, tok string) (*org.Invite, error) {
+	if tok == "" {
+		return nil, errors.New("invalid token")
+	}
+	var inv org.Invite
+	err := conn.First(&inv, "token = ?", tok).Error
+	if err != nil {
+		return nil, err
+	}
+	if !inv.Usable() {
+		return nil, errors.New("invalid token")
+	}
+	return &inv, nil
+}
+
+// InviteSent marks an invite as sent.
+func InviteSent(conn *store.Connection, inv *org.Invite) error {
+	now := time.Now().UTC()
+	inv.SentAt = &now
+	return conn.Model(inv).Update("sent_at", inv.SentAt).Error
+}
+
+// AcceptInvite uses the invite and adds the user to the organization with
+// the role of the invite. The email address of the user must match the
+// email address the invite was sent to.
+func AcceptInvite(conn *store.Connection, tok string, userID uuid.UUID, email string) (*org.Member, error) {
+	var m *org.Member
+	err := conn.Transaction(func(tx *store.Connection) error {
+		inv, err := GetInvite(tx, tok)
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(inv.Email, email) {
+			return errors.New("invalid email")
+		}
+		err = tokens.UseToken(tx, inv)
+		if err != nil {
+			return err
+		}
+		m, err = AddMember(tx, inv.OrgID, userID, inv.Role)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
@@ -0,0 +1,68 @@
+package orgs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/org"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/jrapoport/gothic/test/tutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateInvite(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	o, uid := testCreateOrg(t, conn)
+	em := tutils.RandomEmail()
+	_, err := CreateInvite(conn, uuid.New(), uid, em, org.RoleMember, 0)
+	assert.Error(t, err)
+	_, err = CreateInvite(conn, o.ID, uid, em, org.RoleOwner, 0)
+	assert.Error(t, err)
+	inv, err := CreateInvite(conn, o.ID, uid, em, org.RoleAdmin, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, o.ID, inv.OrgID)
+	assert.Equal(t, uid, inv.UserID)
+	assert.Nil(t, inv.SentAt)
+	_, err = GetInvite(conn, "")
+	assert.Error(t, err)
+	_, err = GetInvite(conn, "bad")
+	assert.Error(t, err)
+	got, err := GetInvite(conn, inv.Token)
+	require.NoError(t, err)
+	assert.Equal(t, inv.ID, got.ID)
+	err = InviteSent(conn, got)
+	require.NoError(t, err)
+	got, err = GetInvite(conn, inv.Token)
+	require.NoError(t, err)
+	assert.NotNil(t, got.SentAt)
+}
+
+func TestAcceptInvite(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	o, owner := testCreateOrg(t, conn)
+	em := tutils.RandomEmail()
+	inv, err := CreateInvite(conn, o.ID, owner, em, org.RoleAdmin, 0)
+	require.NoError(t, err)
+	uid := uuid.New()
+	_, err = AcceptInvite(conn, "bad", uid, em)
+	assert.Error(t, err)
+	_, err = AcceptInvite(conn, inv.Token, uid, tutils.RandomEmail())
+	assert.Error(t, err)
+	m, err := AcceptInvite(conn, inv.Token, uid, em)
+	require.NoError(t, err)
+	assert.Equal(t, o.ID, m.OrgID)
+	assert.Equal(t, uid, m.UserID)
+	assert.Equal(t, org.RoleAdmin, m.Role)
+	// single use
+	_, err = AcceptInvite(conn, inv.Token, uid, em)
+	assert.Error(t, err)
+	// already a member
+	inv, err = CreateInvite(conn, o.ID, owner, em, org.RoleMember, 0)
+	require.NoError(t, err)
+	_, err = AcceptInvite(conn, inv.Token, uid, em)
+	assert.Error(t, err)
+}
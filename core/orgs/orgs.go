@@ -0,0 +1,206 @@
+package orgs
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/org"
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+)
+
+// CreateOrg creates a new organization. The user is the owner of the organization.
+func CreateOrg(conn *store.Connection, name string, data types.Map, ownerID uuid.UUID) (*org.Organization, error) {
+	if ownerID == uuid.Nil || ownerID == user.SystemID || ownerID == user.SuperAdminID {
+		return nil, errors.New("invalid user id")
+	}
+	o := org.NewOrganization(name, data)
+	err := conn.Transaction(func(tx *store.Connection) error {
+		err := tx.Create(o).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(org.NewMember(o.ID, ownerID, org.RoleOwner)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// GetOrg returns the organization for the id.
+func GetOrg(conn *store.Connection, orgID uuid.UUID) (*org.Organization, error) {
+	if orgID == uuid.Nil {
+		return nil, errors.New("invalid organization id")
+	}
+	var o org.Organization
+	err := conn.First(&o, "id = ?", orgID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// GetUserOrgs returns the organizations the user is a member of.
+func GetUserOrgs(conn *store.Connection, userID uuid.UUID) ([]*org.Organization, error) {
+	var list []*org.Organization
+	err := conn.
+		Joins("JOIN org_members ON org_members.org_id = organizations.id").
+		Where("org_members.user_id = ?", userID).
+		Order("organizations.name").
+		Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// UpdateOrg updates the name & data of an organization. If the name is
+// nil it is not changed. If the data is nil it is not changed.
+func UpdateOrg(conn *store.Connection, orgID uuid.UUID, name *string, data types.Map) (*org.Organization, error) {
+	o, err := GetOrg(conn, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if name != nil {
+		o.Name = strings.TrimSpace(*name)
+	}
+	if data != nil {
+		if o.Data == nil {
+			o.Data = types.Map{}
+		}
+		for k, v := range data {
+			o.Data[k] = v
+		}
+	}
+	err = conn.Save(o).Error
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// DeleteOrg deletes an organization, its members and its invites.
+func DeleteOrg(conn *store.Connection, orgID uuid.UUID) (*org.Organization, error) {
+	o, err := GetOrg(conn, orgID)
+	if err != nil {
+		return nil, err
+	}
+	err = conn.Where("org_id = ?", o.ID).Delete(&org.Member{}).Error
+	if err != nil {
+		return nil, err
+	}
+	err = conn.Unscoped().Where("org_id = ?", o.ID).Delete(&org.Invite{}).Error
+	if err != nil {
+		return nil, err
+	}
+	err = conn.Delete(o).Error
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// GetMember returns the membership of the user in the organization.
+func GetMember(conn *store.Connection, orgID, userID uuid.UUID) (*org.Member, error) {
+	var m org.Member
+	err := conn.First(&m, "org_id = ? AND user_id = ?", orgID, userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// GetMembers returns the members of the organization.
+func GetMembers(conn *store.Connection, orgID uuid.UUID) ([]*org.Member, error) {
+	var list []*org.Member
+	err := conn.
+		Where("org_id = ?", orgID).
+		Order("role DESC, created_at").
+		Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// AddMember adds the user to the organization with the role.
+func AddMember(conn *store.Connection, orgID, userID uuid.UUID, role org.Role) (*org.Member, error) {
+	if userID == uuid.Nil || userID == user.SystemID || userID == user.SuperAdminID {
+		return nil, errors.New("invalid user id")
+	}
+	has, err := conn.Has(&org.Member{}, "org_id = ? AND user_id = ?", orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if has {
+		return nil, errors.New("already a member")
+	}
+	m := org.NewMember(orgID, userID, role)
+	err = conn.Create(m).Error
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ChangeMemberRole changes the role of a member of the organization.
+// The last owner of an organization can not be demoted.
+func ChangeMemberRole(conn *store.Connection, orgID, userID uuid.UUID, role org.Role) (*org.Member, error) {
+	if !role.Valid() {
+		return nil, errors.New("invalid role")
+	}
+	m, err := GetMember(conn, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if m.IsOwner() && role != org.RoleOwner {
+		err = lastOwner(conn, orgID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	m.Role = role
+	err = conn.Save(m).Error
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// RemoveMember removes a member from the organization.
+// The last owner of an organization can not be removed.
+func RemoveMember(conn *store.Connection, orgID, userID uuid.UUID) (*org.Member, error) {
+	m, err := GetMember(conn, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if m.IsOwner() {
+		err = lastOwner(conn, orgID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = conn.Where("org_id = ? AND user_id = ?", orgID, userID).
+		Delete(&org.Member{}).Error
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func lastOwner(conn *store.Connection, orgID uuid.UUID) error {
+	var n int64
+	err := conn.Model(&org.Member{}).
+		Where("org_id = ? AND role = ?", orgID, org.RoleOwner).
+		Count(&n).Error
+	if err != nil {
+		return err
+	}
+	if n <= 1 {
+		return errors.New("organization requires an owner")
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package orgs
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/org"
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOrg = "acme"
+
+func testCreateOrg(t *testing.T, conn *store.Connection) (*org.Organization, uuid.UUID) {
+	uid := uuid.New()
+	o, err := CreateOrg(conn, testOrg, nil, uid)
+	require.NoError(t, err)
+	return o, uid
+}
+
+func TestCreateOrg(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	_, err := CreateOrg(conn, testOrg, nil, uuid.Nil)
+	assert.Error(t, err)
+	_, err = CreateOrg(conn, testOrg, nil, user.SystemID)
+	assert.Error(t, err)
+	_, err = CreateOrg(conn, "", nil, uid)
+	assert.Error(t, err)
+	data := types.Map{"foo": "bar"}
+	o, err := CreateOrg(conn, testOrg, data, uid)
+	require.NoError(t, err)
+	assert.Equal(t, testOrg, o.Name)
+	assert.Equal(t, data, o.Data)
+	m, err := GetMember(conn, o.ID, uid)
+	require.NoError(t, err)
+	assert.Equal(t, org.RoleOwner, m.Role)
+}
+
+func TestGetOrg(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	o, uid := testCreateOrg(t, conn)
+	_, err := GetOrg(conn, uuid.Nil)
+	assert.Error(t, err)
+	_, err = GetOrg(conn, uuid.New())
+	assert.Error(t, err)
+	got, err := GetOrg(conn, o.ID)
+	require.NoError(t, err)
+	assert.Equal(t, o.ID, got.ID)
+	o2, err := CreateOrg(conn, "another", nil, uid)
+	require.NoError(t, err)
+	_, err = CreateOrg(conn, "other user", nil, uuid.New())
+	require.NoError(t, err)
+	list, err := GetUserOrgs(conn, uid)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, o.ID, list[0].ID)
+	assert.Equal(t, o2.ID, list[1].ID)
+	list, err = GetUserOrgs(conn, uuid.New())
+	require.NoError(t, err)
+	assert.Len(t, list, 0)
+}
+
+func TestUpdateOrg(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	o, _ := testCreateOrg(t, conn)
+	_, err := UpdateOrg(conn, uuid.New(), nil, nil)
+	assert.Error(t, err)
+	empty := ""
+	_, err = UpdateOrg(conn, o.ID, &empty, nil)
+	assert.Error(t, err)
+	name := "renamed"
+	o, err = UpdateOrg(conn, o.ID, &name, types.Map{"foo": "bar"})
+	require.NoError(t, err)
+	assert.Equal(t, name, o.Name)
+	o, err = UpdateOrg(conn, o.ID, nil, types.Map{"baz": "qux"})
+	require.NoError(t, err)
+	assert.Equal(t, name, o.Name)
+	assert.Equal(t, "bar", o.Data["foo"])
+	assert.Equal(t, "qux", o.Data["baz"])
+}
+
+func TestDeleteOrg(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	o, uid := testCreateOrg(t, conn)
+	_, err := CreateInvite(conn, o.ID, uid, "test@example.com", org.RoleMember, 0)
+	require.NoError(t, err)
+	_, err = DeleteOrg(conn, uuid.New())
+	assert.Error(t, err)
+	_, err = DeleteOrg(conn, o.ID)
+	require.NoError(t, err)
+	_, err = GetOrg(conn, o.ID)
+	assert.Error(t, err)
+	_, err = GetMember(conn, o.ID, uid)
+	assert.Error(t, err)
+	has, err := conn.Has(&org.Invite{}, "org_id = ?", o.ID)
+	require.NoError(t, err)
+	assert.False(t, has)
+}
+
+func TestMembers(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	o, owner := testCreateOrg(t, conn)
+	uid := uuid.New()
+	_, err := AddMember(conn, o.ID, uuid.Nil, org.RoleMember)
+	assert.Error(t, err)
+	_, err = AddMember(conn, o.ID, uid, org.InvalidRole)
+	assert.Error(t, err)
+	m, err := AddMember(conn, o.ID, uid, org.RoleMember)
+	require.NoError(t, err)
+	assert.Equal(t, org.RoleMember, m.Role)
+	_, err = AddMember(conn, o.ID, uid, org.RoleMember)
+	assert.Error(t, err)
+	list, err := GetMembers(conn, o.ID)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, owner, list[0].UserID)
+	assert.Equal(t, uid, list[1].UserID)
+	// change role
+	_, err = ChangeMemberRole(conn, o.ID, uuid.New(), org.RoleAdmin)
+	assert.Error(t, err)
+	_, err = ChangeMemberRole(conn, o.ID, uid, org.InvalidRole)
+	assert.Error(t, err)
+	m, err = ChangeMemberRole(conn, o.ID, uid, org.RoleAdmin)
+	require.NoError(t, err)
+	assert.Equal(t, org.RoleAdmin, m.Role)
+	// last owner
+	_, err = ChangeMemberRole(conn, o.ID, owner, org.RoleAdmin)
+	assert.Error(t, err)
+	_, err = RemoveMember(conn, o.ID, owner)
+	assert.Error(t, err)
+	_, err = ChangeMemberRole(conn, o.ID, uid, org.RoleOwner)
+	require.NoError(t, err)
+	m, err = ChangeMemberRole(conn, o.ID, owner, org.RoleMember)
+	require.NoError(t, err)
+	assert.Equal(t, org.RoleMember, m.Role)
+	// remove
+	_, err = RemoveMember(conn, o.ID, uuid.New())
+	assert.Error(t, err)
+	_, err = RemoveMember(conn, o.ID, owner)
+	require.NoError(t, err)
+	_, err = GetMember(conn, o.ID, owner)
+	assert.Error(t, err)
+}
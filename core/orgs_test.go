@@ -0,0 +1,304 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/jwt"
+	"github.com/jrapoport/gothic/mail/template"
+	"github.com/jrapoport/gothic/models/auditlog"
+	"github.com/jrapoport/gothic/models/org"
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconf"
+	"github.com/jrapoport/gothic/test/tutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testOrg(t *testing.T, a *API) (*org.Organization, *user.User) {
+	u := confirmUser(t, a, testUser(t, a))
+	o, err := a.CreateOrg(nil, u.ID, "acme", nil)
+	require.NoError(t, err)
+	return o, u
+}
+
+func orgMember(t *testing.T, a *API, o *org.Organization, role org.Role) *user.User {
+	u := confirmUser(t, a, testUser(t, a))
+	m := org.NewMember(o.ID, u.ID, role)
+	err := a.conn.Create(m).Error
+	require.NoError(t, err)
+	return u
+}
+
+func TestAPI_CreateOrg(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	u := confirmUser(t, a, testUser(t, a))
+	ctx := testContext(a)
+	_, err := a.CreateOrg(ctx, uuid.New(), "acme", nil)
+	assert.Error(t, err)
+	_, err = a.CreateOrg(ctx, u.ID, " ", nil)
+	assert.Error(t, err)
+	b := banUser(t, a, testUser(t, a))
+	_, err = a.CreateOrg(ctx, b.ID, "acme", nil)
+	assert.Error(t, err)
+	data := types.Map{"plan": "pro"}
+	o, err := a.CreateOrg(ctx, u.ID, "acme", data)
+	require.NoError(t, err)
+	assert.Equal(t, "acme", o.Name)
+	assert.Equal(t, data, o.Data)
+	hasAuditEntry(t, a, auditlog.OrgCreated, u.ID)
+	list, err := a.GetUserOrgs(ctx, u.ID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, o.ID, list[0].ID)
+	_, err = a.GetUserOrgs(ctx, uuid.New())
+	assert.Error(t, err)
+}
+
+func TestAPI_GetOrg(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	o, u := testOrg(t, a)
+	ctx := testContext(a)
+	_, err := a.GetOrg(ctx, u.ID, uuid.New())
+	assert.Error(t, err)
+	other := confirmUser(t, a, testUser(t, a))
+	_, err = a.GetOrg(ctx, other.ID, o.ID)
+	assert.Error(t, err)
+	got, err := a.GetOrg(ctx, u.ID, o.ID)
+	require.NoError(t, err)
+	assert.Equal(t, o.ID, got.ID)
+	mem := orgMember(t, a, o, org.RoleMember)
+	got, err = a.GetOrg(ctx, mem.ID, o.ID)
+	require.NoError(t, err)
+	assert.Equal(t, o.ID, got.ID)
+	list, err := a.GetOrgMembers(ctx, mem.ID, o.ID)
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+	_, err = a.GetOrgMembers(ctx, other.ID, o.ID)
+	assert.Error(t, err)
+}
+
+func TestAPI_UpdateOrg(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	o, u := testOrg(t, a)
+	ctx := testContext(a)
+	name := "renamed"
+	mem := orgMember(t, a, o, org.RoleMember)
+	_, err := a.UpdateOrg(ctx, mem.ID, o.ID, &name, nil)
+	assert.Error(t, err)
+	adm := orgMember(t, a, o, org.RoleAdmin)
+	o, err = a.UpdateOrg(ctx, adm.ID, o.ID, &name, types.Map{"plan": "pro"})
+	require.NoError(t, err)
+	assert.Equal(t, name, o.Name)
+	assert.Equal(t, "pro", o.Data["plan"])
+	hasAuditEntry(t, a, auditlog.OrgUpdated, adm.ID)
+	// delete
+	err = a.DeleteOrg(ctx, adm.ID, o.ID)
+	assert.Error(t, err)
+	err = a.DeleteOrg(ctx, u.ID, o.ID)
+	require.NoError(t, err)
+	hasAuditEntry(t, a, auditlog.OrgDeleted, u.ID)
+	_, err = a.GetOrg(ctx, u.ID, o.ID)
+	assert.Error(t, err)
+}
+
+func TestAPI_ChangeOrgMemberRole(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	o, owner := testOrg(t, a)
+	ctx := testContext(a)
+	mem := orgMember(t, a, o, org.RoleMember)
+	adm := orgMember(t, a, o, org.RoleAdmin)
+	// not an admin
+	_, err := a.ChangeOrgMemberRole(ctx, mem.ID, o.ID, adm.ID, org.RoleMember)
+	assert.Error(t, err)
+	// only owners can make owners
+	_, err = a.ChangeOrgMemberRole(ctx, adm.ID, o.ID, mem.ID, org.RoleOwner)
+	assert.Error(t, err)
+	// only owners can change owners
+	_, err = a.ChangeOrgMemberRole(ctx, adm.ID, o.ID, owner.ID, org.RoleMember)
+	assert.Error(t, err)
+	// last owner
+	_, err = a.ChangeOrgMemberRole(ctx, owner.ID, o.ID, owner.ID, org.RoleMember)
+	assert.Error(t, err)
+	m, err := a.ChangeOrgMemberRole(ctx, adm.ID, o.ID, mem.ID, org.RoleAdmin)
+	require.NoError(t, err)
+	assert.Equal(t, org.RoleAdmin, m.Role)
+	hasAuditEntry(t, a, auditlog.OrgMemberRole, mem.ID)
+	m, err = a.ChangeOrgMemberRole(ctx, owner.ID, o.ID, adm.ID, org.RoleOwner)
+	require.NoError(t, err)
+	assert.Equal(t, org.RoleOwner, m.Role)
+}
+
+func TestAPI_RemoveOrgMember(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	o, owner := testOrg(t, a)
+	ctx := testContext(a)
+	mem := orgMember(t, a, o, org.RoleMember)
+	adm := orgMember(t, a, o, org.RoleAdmin)
+	// not an admin
+	err := a.RemoveOrgMember(ctx, mem.ID, o.ID, adm.ID)
+	assert.Error(t, err)
+	// only owners can remove owners
+	err = a.RemoveOrgMember(ctx, adm.ID, o.ID, owner.ID)
+	assert.Error(t, err)
+	// last owner
+	err = a.RemoveOrgMember(ctx, owner.ID, o.ID, owner.ID)
+	assert.Error(t, err)
+	err = a.RemoveOrgMember(ctx, adm.ID, o.ID, mem.ID)
+	require.NoError(t, err)
+	hasAuditEntry(t, a, auditlog.OrgMemberRemoved, mem.ID)
+	// leave
+	err = a.RemoveOrgMember(ctx, adm.ID, o.ID, adm.ID)
+	require.NoError(t, err)
+	list, err := a.GetOrgMembers(ctx, owner.ID, o.ID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, owner.ID, list[0].UserID)
+}
+
+func TestAPI_SendOrgInvite(t *testing.T) {
+	a := mailAPI(t)
+	o, owner := testOrg(t, a)
+	ctx := testContext(a)
+	mem := orgMember(t, a, o, org.RoleMember)
+	adm := orgMember(t, a, o, org.RoleAdmin)
+	to := tutils.RandomEmail()
+	// bad email
+	_, err := a.SendOrgInvite(ctx, owner.ID, o.ID, "@", org.RoleMember)
+	assert.Error(t, err)
+	// not an admin
+	_, err = a.SendOrgInvite(ctx, mem.ID, o.ID, to, org.RoleMember)
+	assert.Error(t, err)
+	// only owners can invite admins
+	_, err = a.SendOrgInvite(ctx, adm.ID, o.ID, to, org.RoleAdmin)
+	assert.Error(t, err)
+	// owners can't be invited
+	_, err = a.SendOrgInvite(ctx, owner.ID, o.ID, to, org.RoleOwner)
+	assert.Error(t, err)
+	// offline
+	inv, err := a.SendOrgInvite(ctx, adm.ID, o.ID, to, org.RoleMember)
+	require.NoError(t, err)
+	assert.Nil(t, inv.SentAt)
+	hasAuditEntry(t, a, auditlog.OrgInviteSent, adm.ID)
+	// online
+	var mock *tconf.SMTPMock
+	a.config, mock = tconf.MockSMTP(t, a.config)
+	err = a.OpenMail()
+	require.NoError(t, err)
+	t.Cleanup(a.CloseMail)
+	var tok string
+	mock.AddHook(t, func(email string) {
+		tok = tconf.GetEmailToken(template.InviteUserAction, email)
+	})
+	inv, err = a.SendOrgInvite(ctx, owner.ID, o.ID, to, org.RoleAdmin)
+	require.NoError(t, err)
+	assert.NotNil(t, inv.SentAt)
+	assert.Eventually(t, func() bool {
+		return tok != ""
+	}, 1*time.Second, 10*time.Millisecond)
+	assert.Equal(t, inv.Token, tok)
+}
+
+func TestAPI_AcceptOrgInvite(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	o, owner := testOrg(t, a)
+	ctx := testContext(a)
+	u := confirmUser(t, a, testUser(t, a))
+	inv, err := a.SendOrgInvite(ctx, owner.ID, o.ID, u.Email, org.RoleAdmin)
+	require.NoError(t, err)
+	// wrong user
+	other := confirmUser(t, a, testUser(t, a))
+	_, err = a.AcceptOrgInvite(ctx, other.ID, inv.Token)
+	assert.Error(t, err)
+	_, err = a.AcceptOrgInvite(ctx, u.ID, "bad")
+	assert.Error(t, err)
+	m, err := a.AcceptOrgInvite(ctx, u.ID, inv.Token)
+	require.NoError(t, err)
+	assert.Equal(t, o.ID, m.OrgID)
+	assert.Equal(t, org.RoleAdmin, m.Role)
+	hasAuditEntry(t, a, auditlog.OrgJoined, u.ID)
+	_, err = a.AcceptOrgInvite(ctx, u.ID, inv.Token)
+	assert.Error(t, err)
+}
+
+func TestAPI_SwitchOrg(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	o, u := testOrg(t, a)
+	ctx := testContext(a)
+	bt, err := a.GrantBearerToken(ctx, u)
+	require.NoError(t, err)
+	// not a member
+	other := confirmUser(t, a, testUser(t, a))
+	_, err = a.SwitchOrg(ctx, other.ID, bt.RefreshToken.String(), o.ID)
+	assert.Error(t, err)
+	// not the user's token
+	ot, err := a.GrantBearerToken(ctx, other)
+	require.NoError(t, err)
+	_, err = a.SwitchOrg(ctx, u.ID, ot.RefreshToken.String(), o.ID)
+	assert.Error(t, err)
+	_, err = a.SwitchOrg(ctx, u.ID, "bad", o.ID)
+	assert.Error(t, err)
+	bt, err = a.SwitchOrg(ctx, u.ID, bt.RefreshToken.String(), o.ID)
+	require.NoError(t, err)
+	hasAuditEntry(t, a, auditlog.OrgSwitched, u.ID)
+	claims, err := jwt.ParseUserClaims(a.config.JWT, bt.String())
+	require.NoError(t, err)
+	assert.Equal(t, o.ID, claims.OrgID())
+	assert.Equal(t, org.RoleOwner.String(), claims.OrgRole())
+	// the active org is kept when the token is refreshed
+	bt, err = a.RefreshBearerToken(ctx, bt.RefreshToken.String())
+	require.NoError(t, err)
+	claims, err = jwt.ParseUserClaims(a.config.JWT, bt.String())
+	require.NoError(t, err)
+	assert.Equal(t, o.ID, claims.OrgID())
+	// the active org is dropped once the user is no longer a member
+	mem := orgMember(t, a, o, org.RoleOwner)
+	err = a.RemoveOrgMember(ctx, mem.ID, o.ID, u.ID)
+	require.NoError(t, err)
+	bt, err = a.RefreshBearerToken(ctx, bt.RefreshToken.String())
+	require.NoError(t, err)
+	claims, err = jwt.ParseUserClaims(a.config.JWT, bt.String())
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Nil, claims.OrgID())
+	// clear
+	o2, err := a.CreateOrg(ctx, u.ID, "another", nil)
+	require.NoError(t, err)
+	bt, err = a.SwitchOrg(ctx, u.ID, bt.RefreshToken.String(), o2.ID)
+	require.NoError(t, err)
+	bt, err = a.SwitchOrg(ctx, u.ID, bt.RefreshToken.String(), uuid.Nil)
+	require.NoError(t, err)
+	claims, err = jwt.ParseUserClaims(a.config.JWT, bt.String())
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Nil, claims.OrgID())
+	assert.Empty(t, claims.OrgRole())
+}
+
+func TestAPI_SwitchOrg_Reused(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	o, u := testOrg(t, a)
+	ctx := testContext(a)
+	bt1, err := a.GrantBearerToken(ctx, u)
+	require.NoError(t, err)
+	bt2, err := a.RefreshBearerToken(ctx, bt1.RefreshToken.String())
+	require.NoError(t, err)
+	// the rotated token is replayed
+	_, err = a.SwitchOrg(ctx, u.ID, bt1.RefreshToken.String(), o.ID)
+	assert.ErrorIs(t, err, tokens.ErrRefreshTokenReused)
+	hasAuditEntry(t, a, auditlog.TokenReused, u.ID)
+	// the successor is revoked
+	_, err = tokens.GetRefreshToken(a.conn, bt2.RefreshToken.String())
+	assert.Error(t, err)
+	_, err = a.SwitchOrg(ctx, u.ID, bt2.RefreshToken.String(), o.ID)
+	assert.Error(t, err)
+}
@@ -0,0 +1,166 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/audit"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/core/roles"
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/core/users"
+	"github.com/jrapoport/gothic/jwt"
+	"github.com/jrapoport/gothic/models/rbac"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/store"
+)
+
+// CreatePersonalToken creates a new personal access token for the user with
+// the scopes. The token is only returned when it is created. If the expiration
+// is token.NoExpiration, the token does not expire. Only an admin user, or a
+// user with permissions, can create a personal access token with the admin scope.
+func (a *API) CreatePersonalToken(ctx context.Context, userID uuid.UUID, name string, scopes []string, exp time.Duration) (*token.PersonalToken, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	err := checkImpersonation(ctx)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	name = strings.TrimSpace(name)
+	scope, err := personalScope(scopes)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	var pt *token.PersonalToken
+	err = a.conn.Transaction(func(tx *store.Connection) error {
+		u, err := users.GetActiveUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		if containsScope(scopes, token.ScopeAdmin) {
+			perms, err := roles.GetPermissions(tx, u)
+			if err != nil {
+				return err
+			}
+			if !u.IsAdmin() && len(perms) == 0 {
+				return errors.New("admin user required for admin scope")
+			}
+		}
+		pt, err = tokens.GrantPersonalToken(tx, u.ID, name, scope, exp)
+		if err != nil {
+			return err
+		}
+		return audit.LogTokenGranted(ctx, tx, pt)
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	a.log.Debugf("created personal token %s: %s", pt.TokenID, userID)
+	return pt, nil
+}
+
+// GetPersonalTokens returns the personal access tokens for the user (newest first).
+func (a *API) GetPersonalTokens(ctx context.Context, userID uuid.UUID) ([]*token.PersonalToken, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var list []*token.PersonalToken
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		u, err := users.GetUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		list, err = tokens.GetPersonalTokens(tx, u.ID)
+		return err
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	return list, nil
+}
+
+// RevokePersonalToken revokes a personal access token for the user. Once a
+// personal access token is revoked it can no longer be used.
+func (a *API) RevokePersonalToken(ctx context.Context, userID, tokenID uuid.UUID) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		u, err := users.GetUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		pt, err := tokens.RevokePersonalToken(tx, u.ID, tokenID)
+		if err != nil {
+			return err
+		}
+		return audit.LogTokenRevoked(ctx, tx, pt)
+	})
+	if err != nil {
+		return a.logError(err)
+	}
+	a.log.Debugf("revoked personal token %s: %s", tokenID, userID)
+	return nil
+}
+
+// ValidatePersonalToken returns the claims for a personal access token used
+// from the ip address. The use of the token is recorded. An error is returned
+// if the token is not usable, or its user is not active. If the token has the
+// admin scope, the claims include the permissions of the user.
+func (a *API) ValidatePersonalToken(tok, ip string) (*jwt.UserClaims, error) {
+	var claims *jwt.UserClaims
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		pt, err := tokens.GetPersonalToken(tx, tok)
+		if err != nil {
+			return err
+		}
+		u, err := users.GetActiveUser(tx, pt.UserID)
+		if err != nil {
+			return err
+		}
+		err = tokens.UsePersonalToken(tx, pt, ip)
+		if err != nil {
+			return err
+		}
+		claims = jwt.NewPersonalClaims(u, pt)
+		if u.IsAdmin() || !claims.HasScope(token.ScopeAdmin) {
+			return nil
+		}
+		perms, err := roles.GetPermissions(tx, u)
+		if err != nil {
+			return err
+		}
+		claims.SetPermissions(rbac.Strings(perms))
+		return nil
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	return claims, nil
+}
+
+// personalScope validates the scopes and returns them as a scope string.
+func personalScope(scopes []string) (string, error) {
+	if len(scopes) == 0 {
+		return "", errors.New("scope required")
+	}
+	for _, s := range scopes {
+		if !containsScope(token.PersonalScopes, s) {
+			return "", fmt.Errorf("invalid scope: %s", s)
+		}
+	}
+	return strings.Join(scopes, " "), nil
+}
+
+func containsScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
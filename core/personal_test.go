@@ -0,0 +1,162 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/auditlog"
+	"github.com/jrapoport/gothic/models/rbac"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPI_CreatePersonalToken(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	u := confirmUser(t, a, testUser(t, a))
+	ctx := testContext(a)
+	scopes := []string{token.ScopeRead}
+	// bad user
+	_, err := a.CreatePersonalToken(ctx, uuid.New(), "test", scopes, 0)
+	assert.Error(t, err)
+	// bad name
+	_, err = a.CreatePersonalToken(ctx, u.ID, " ", scopes, 0)
+	assert.Error(t, err)
+	// bad scopes
+	_, err = a.CreatePersonalToken(ctx, u.ID, "test", nil, 0)
+	assert.Error(t, err)
+	_, err = a.CreatePersonalToken(ctx, u.ID, "test", []string{"bad"}, 0)
+	assert.Error(t, err)
+	// admin scope requires an admin
+	_, err = a.CreatePersonalToken(ctx, u.ID, "test", []string{token.ScopeAdmin}, 0)
+	assert.Error(t, err)
+	// inactive user
+	b := banUser(t, a, testUser(t, a))
+	_, err = a.CreatePersonalToken(ctx, b.ID, "test", scopes, 0)
+	assert.Error(t, err)
+	pt, err := a.CreatePersonalToken(nil, u.ID, "test", scopes, time.Hour)
+	require.NoError(t, err)
+	assert.True(t, token.IsPersonalToken(pt.Secret))
+	assert.Equal(t, u.ID, pt.UserID)
+	assert.Equal(t, "test", pt.Name)
+	assert.Equal(t, token.ScopeRead, pt.Scope)
+	require.NotNil(t, pt.ExpiredAt)
+	hasAuditEntry(t, a, auditlog.Granted, u.ID)
+	// admin
+	adm := promoteUser(t, a, testUser(t, a))
+	all := []string{token.ScopeRead, token.ScopeWrite, token.ScopeAdmin}
+	pt, err = a.CreatePersonalToken(ctx, adm.ID, "admin", all, token.NoExpiration)
+	require.NoError(t, err)
+	assert.Equal(t, "read write admin", pt.Scope)
+	assert.Nil(t, pt.ExpiredAt)
+}
+
+func TestAPI_GetPersonalTokens(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	u := confirmUser(t, a, testUser(t, a))
+	ctx := testContext(a)
+	_, err := a.GetPersonalTokens(ctx, uuid.New())
+	assert.Error(t, err)
+	list, err := a.GetPersonalTokens(nil, u.ID)
+	assert.NoError(t, err)
+	assert.Len(t, list, 0)
+	scopes := []string{token.ScopeRead}
+	pt1, err := a.CreatePersonalToken(ctx, u.ID, "one", scopes, 0)
+	require.NoError(t, err)
+	pt2, err := a.CreatePersonalToken(ctx, u.ID, "two", scopes, 0)
+	require.NoError(t, err)
+	list, err = a.GetPersonalTokens(ctx, u.ID)
+	assert.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, pt2.TokenID, list[0].TokenID)
+	assert.Equal(t, pt1.TokenID, list[1].TokenID)
+	assert.Empty(t, list[0].Secret)
+}
+
+func TestAPI_RevokePersonalToken(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	u := confirmUser(t, a, testUser(t, a))
+	ctx := testContext(a)
+	pt, err := a.CreatePersonalToken(ctx, u.ID, "test", []string{token.ScopeWrite}, 0)
+	require.NoError(t, err)
+	err = a.RevokePersonalToken(ctx, uuid.New(), pt.TokenID)
+	assert.Error(t, err)
+	err = a.RevokePersonalToken(ctx, u.ID, uuid.Nil)
+	assert.Error(t, err)
+	err = a.RevokePersonalToken(ctx, u.ID, uuid.New())
+	assert.Error(t, err)
+	// another user's token
+	o := confirmUser(t, a, testUser(t, a))
+	err = a.RevokePersonalToken(ctx, o.ID, pt.TokenID)
+	assert.Error(t, err)
+	err = a.RevokePersonalToken(nil, u.ID, pt.TokenID)
+	assert.NoError(t, err)
+	hasAuditEntry(t, a, auditlog.Revoked, u.ID)
+	_, err = a.ValidatePersonalToken(pt.Secret, testIP)
+	assert.Error(t, err)
+	list, err := a.GetPersonalTokens(ctx, u.ID)
+	assert.NoError(t, err)
+	assert.Len(t, list, 0)
+}
+
+func TestAPI_ValidatePersonalToken(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	u := confirmUser(t, a, testUser(t, a))
+	ctx := testContext(a)
+	pt, err := a.CreatePersonalToken(ctx, u.ID, "test", []string{token.ScopeRead}, 0)
+	require.NoError(t, err)
+	_, err = a.ValidatePersonalToken("", testIP)
+	assert.Error(t, err)
+	_, err = a.ValidatePersonalToken(pt.Token, testIP)
+	assert.Error(t, err)
+	_, err = a.ValidatePersonalToken(token.PersonalTokenPrefix+"bad", testIP)
+	assert.Error(t, err)
+	claims, err := a.ValidatePersonalToken(pt.Secret, testIP)
+	require.NoError(t, err)
+	assert.Equal(t, u.ID, claims.UserID())
+	assert.Equal(t, pt.TokenID, claims.PersonalTokenID())
+	assert.True(t, claims.HasScope(token.ScopeRead))
+	assert.False(t, claims.HasScope(token.ScopeWrite))
+	// the use is recorded
+	list, err := a.GetPersonalTokens(ctx, u.ID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.NotNil(t, list[0].UsedAt)
+	assert.Equal(t, testIP, list[0].IPAddress)
+	// inactive user
+	banUser(t, a, u)
+	_, err = a.ValidatePersonalToken(pt.Secret, testIP)
+	assert.Error(t, err)
+}
+
+func TestAPI_ValidatePersonalToken_Permissions(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	u := confirmUser(t, a, testUser(t, a))
+	ctx := testContext(a)
+	admin := []string{token.ScopeRead, token.ScopeAdmin}
+	_, err := a.CreatePersonalToken(ctx, u.ID, "test", admin, 0)
+	assert.Error(t, err)
+	r := testRole(t, a, "support", testPermissions)
+	err = a.AssignUserRole(rootContext(a), u.ID, r.Name)
+	require.NoError(t, err)
+	pt, err := a.CreatePersonalToken(ctx, u.ID, "test", admin, 0)
+	require.NoError(t, err)
+	claims, err := a.ValidatePersonalToken(pt.Secret, testIP)
+	require.NoError(t, err)
+	assert.False(t, claims.Admin())
+	assert.Len(t, claims.Permissions(), 2)
+	assert.True(t, claims.HasPermission(rbac.UsersRead.String()))
+	assert.True(t, claims.HasPermission(rbac.SessionsRead.String()))
+	// without the admin scope the permissions are not included
+	pt, err = a.CreatePersonalToken(ctx, u.ID, "read", []string{token.ScopeRead}, 0)
+	require.NoError(t, err)
+	claims, err = a.ValidatePersonalToken(pt.Secret, testIP)
+	require.NoError(t, err)
+	assert.Empty(t, claims.Permissions())
+}
@@ -0,0 +1,226 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/audit"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/core/events"
+	"github.com/jrapoport/gothic/core/login"
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/core/users"
+	"github.com/jrapoport/gothic/core/validate"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+)
+
+// SendChangePhone sends a phone change confirmation code to the new phone number.
+func (a *API) SendChangePhone(ctx context.Context, userID uuid.UUID, phone string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	err := checkImpersonation(ctx)
+	if err != nil {
+		return a.logError(err)
+	}
+	phone, err = validate.Phone(phone)
+	if err != nil {
+		return a.logError(err)
+	}
+	if a.sms == nil {
+		a.log.Warn("sms not found")
+		return nil
+	}
+	err = a.conn.Transaction(func(tx *store.Connection) error {
+		u, err := users.GetActiveUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		if u.Phone != nil && *u.Phone == phone {
+			return errors.New("phone unchanged")
+		}
+		taken, err := users.IsPhoneTaken(tx, phone)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return fmt.Errorf("phone taken: %s", phone)
+		}
+		return a.sendPhoneCode(ctx, tx, u, token.PhoneChange, phone)
+	})
+	return a.logError(err)
+}
+
+// ConfirmChangePhone confirms a user phone change with the sms code.
+// Invalid codes are failed logins, and count towards the user lockout.
+func (a *API) ConfirmChangePhone(ctx context.Context, userID uuid.UUID, phone, code string) (*user.User, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	err := checkImpersonation(ctx)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	phone, err = validate.Phone(phone)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	if code == "" {
+		err = errors.New("code required")
+		return nil, a.logError(err)
+	}
+	u, err := users.GetActiveUser(a.conn, userID)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	err = a.checkLockout(ctx, u.Email)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	pt, err := tokens.VerifyPhoneToken(a.conn, u.ID, token.PhoneChange, phone, code)
+	if errors.Is(err, tokens.ErrInvalidCode) {
+		if lerr := a.failedLogin(ctx, u.Email); lerr != nil {
+			a.log.Error(lerr)
+		}
+	}
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	err = a.conn.Transaction(func(tx *store.Connection) (err error) {
+		taken, err := users.IsPhoneTaken(tx, phone)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return fmt.Errorf("phone taken: %s", phone)
+		}
+		err = users.ChangePhone(tx, u, phone)
+		if err != nil {
+			return err
+		}
+		err = tokens.RevokePhoneToken(tx, pt)
+		if err != nil {
+			return err
+		}
+		return audit.LogPhoneChange(ctx, tx, u.ID)
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	return u, nil
+}
+
+// SendPhoneLogin sends a login code to the user with the confirmed phone number.
+func (a *API) SendPhoneLogin(ctx context.Context, phone string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ip := ctx.IPAddress()
+	recaptcha := ctx.ReCaptcha()
+	p := a.Provider()
+	ctx.SetProvider(p)
+	a.log.Debugf("send phone login: %s (%s %s %s)", phone, p, ip, recaptcha)
+	if !a.config.SMS.Login {
+		err := errors.New("phone login disabled")
+		return a.logError(err)
+	}
+	err := a.ext.IsEnabled(p)
+	if err != nil {
+		return a.logError(err)
+	}
+	phone, err = validate.Phone(phone)
+	if err != nil {
+		return a.logError(err)
+	}
+	if a.config.Recaptcha.Login {
+		// if recaptcha is disabled this is a no-op
+		err = validate.ReCaptcha(a.config, ip, recaptcha)
+		if err != nil {
+			return a.logError(err)
+		}
+	}
+	if a.sms == nil {
+		a.log.Warn("sms not found")
+		return nil
+	}
+	err = a.conn.Transaction(func(tx *store.Connection) error {
+		u, err := users.GetUserWithPhone(tx, phone)
+		if err != nil {
+			return err
+		}
+		if !u.IsActive() {
+			return errors.New("inactive user")
+		}
+		if u.Provider != p {
+			return errors.New("invalid provider")
+		}
+		return a.sendPhoneCode(ctx, tx, u, token.PhoneLogin, phone)
+	})
+	return a.logError(err)
+}
+
+// PhoneLogin logs in the user with the confirmed phone number and sms code.
+// Invalid codes are failed logins, and count towards the user lockout.
+func (a *API) PhoneLogin(ctx context.Context, phone, code string) (*user.User, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	p := a.Provider()
+	ctx.SetProvider(p)
+	if !a.config.SMS.Login {
+		err := errors.New("phone login disabled")
+		return nil, a.logError(err)
+	}
+	err := a.ext.IsEnabled(p)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	phone, err = validate.Phone(phone)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	if code == "" {
+		err = errors.New("code required")
+		return nil, a.logError(err)
+	}
+	var email string
+	if pu, err := users.GetUserWithPhone(a.conn, phone); err == nil {
+		email = pu.Email
+	}
+	err = a.checkLockout(ctx, email)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	u, err := login.PhoneLogin(a.conn, p, phone, code)
+	if isFailedLogin(err) {
+		if lerr := a.failedLogin(ctx, email); lerr != nil {
+			a.log.Error(lerr)
+		}
+	}
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	err = a.conn.Transaction(func(tx *store.Connection) error {
+		err = clearFailedLogins(tx, u.ID)
+		if err != nil {
+			return err
+		}
+		return audit.LogLogin(ctx, tx, u.ID)
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	a.dispatchEvent(events.Login, types.Map{
+		key.Provider:  p,
+		key.IPAddress: ctx.IPAddress(),
+		key.UserID:    u.ID,
+		key.Timestamp: time.Now().UTC(),
+	})
+	return u, nil
+}
@@ -0,0 +1,345 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/core/validate"
+	"github.com/jrapoport/gothic/models/auditlog"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/models/types/provider"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+	"github.com/jrapoport/gothic/test/tconf"
+	"github.com/jrapoport/gothic/test/tutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func smsAPI(t *testing.T) (*API, string) {
+	c, path := tconf.MockSMS(t, tconf.TempDB(t))
+	c.SMS.Login = true
+	a := configuredAPI(t, c)
+	a.config.Recaptcha.Key = ""
+	return a, path
+}
+
+func resetSendLimit(t *testing.T, a *API, u *user.User) {
+	err := a.conn.Unscoped().Model(&token.PhoneToken{}).
+		Where("user_id = ?", u.ID).Update("sent_at", nil).Error
+	require.NoError(t, err)
+}
+
+func TestAPI_OpenSMS(t *testing.T) {
+	t.Parallel()
+	a, _ := smsAPI(t)
+	assert.NotNil(t, a.sms)
+	a.config.SMS.Sender = ""
+	err := a.OpenSMS()
+	assert.NoError(t, err)
+	assert.Nil(t, a.sms)
+	a.config.SMS.Sender = "bad"
+	err = a.OpenSMS()
+	assert.Error(t, err)
+	a.config.SMS.Sender = config.SMSFile
+	err = a.OpenSMS()
+	assert.NoError(t, err)
+	assert.NotNil(t, a.sms)
+}
+
+func TestAPI_SendChangePhone(t *testing.T) {
+	t.Parallel()
+	a, path := smsAPI(t)
+	ctx := testContext(a)
+	u := testUser(t, a)
+	phone := tutils.RandomPhone()
+	// bad phone
+	err := a.SendChangePhone(ctx, u.ID, "bad")
+	assert.Error(t, err)
+	// inactive user
+	err = a.SendChangePhone(ctx, u.ID, phone)
+	assert.Error(t, err)
+	u = confirmUser(t, a, u)
+	// impersonated
+	ictx := testContext(a)
+	ictx.SetImpersonatorID(uuid.New())
+	err = a.SendChangePhone(ictx, u.ID, phone)
+	assert.Error(t, err)
+	err = a.SendChangePhone(nil, u.ID, phone)
+	require.NoError(t, err)
+	code := tconf.GetSMSCode(t, path, phone)
+	assert.NotEmpty(t, code)
+	pt, err := tokens.GetPhoneToken(a.conn, u.ID, token.PhoneChange)
+	require.NoError(t, err)
+	assert.Equal(t, phone, pt.Phone)
+	assert.Equal(t, code, pt.Code)
+	assert.NotNil(t, pt.SentAt)
+	hasAuditEntry(t, a, auditlog.PhoneCodeSent, u.ID)
+	// rate limit
+	err = a.SendChangePhone(ctx, u.ID, phone)
+	assert.ErrorIs(t, err, config.ErrRateLimitExceeded)
+	// resend
+	resetSendLimit(t, a, u)
+	err = a.SendChangePhone(ctx, u.ID, phone)
+	assert.NoError(t, err)
+	assert.Equal(t, code, tconf.GetSMSCode(t, path, phone))
+	// phone taken
+	u2 := confirmUser(t, a, testUser(t, a))
+	_, err = a.ConfirmChangePhone(ctx, u.ID, phone, code)
+	require.NoError(t, err)
+	err = a.SendChangePhone(ctx, u2.ID, phone)
+	assert.Error(t, err)
+	// phone unchanged
+	err = a.SendChangePhone(ctx, u.ID, phone)
+	assert.Error(t, err)
+	// sms offline
+	a.sms = nil
+	err = a.SendChangePhone(ctx, u2.ID, tutils.RandomPhone())
+	assert.NoError(t, err)
+}
+
+func TestAPI_ConfirmChangePhone(t *testing.T) {
+	t.Parallel()
+	a, path := smsAPI(t)
+	ctx := testContext(a)
+	u := confirmUser(t, a, testUser(t, a))
+	phone := tutils.RandomPhone()
+	// no code sent
+	_, err := a.ConfirmChangePhone(ctx, u.ID, phone, "123456")
+	assert.Error(t, err)
+	err = a.SendChangePhone(ctx, u.ID, phone)
+	require.NoError(t, err)
+	code := tconf.GetSMSCode(t, path, phone)
+	require.NotEmpty(t, code)
+	// bad phone
+	_, err = a.ConfirmChangePhone(ctx, u.ID, "bad", code)
+	assert.Error(t, err)
+	// wrong phone
+	_, err = a.ConfirmChangePhone(ctx, u.ID, tutils.RandomPhone(), code)
+	assert.Error(t, err)
+	// no code
+	_, err = a.ConfirmChangePhone(ctx, u.ID, phone, "")
+	assert.Error(t, err)
+	// bad code
+	_, err = a.ConfirmChangePhone(ctx, u.ID, phone, "bad")
+	assert.Error(t, err)
+	// impersonated
+	ictx := testContext(a)
+	ictx.SetImpersonatorID(uuid.New())
+	_, err = a.ConfirmChangePhone(ictx, u.ID, phone, code)
+	assert.Error(t, err)
+	u2, err := a.ConfirmChangePhone(nil, u.ID, phone, code)
+	require.NoError(t, err)
+	assert.Equal(t, u.ID, u2.ID)
+	require.NotNil(t, u2.Phone)
+	assert.Equal(t, phone, *u2.Phone)
+	assert.True(t, u2.HasPhone())
+	hasAuditEntry(t, a, auditlog.Phone, u.ID)
+	// codes cannot be reused
+	_, err = a.ConfirmChangePhone(ctx, u.ID, phone, code)
+	assert.Error(t, err)
+	// attempts are limited
+	a.config.Lockout.Attempts = 0
+	phone = tutils.RandomPhone()
+	resetSendLimit(t, a, u)
+	err = a.SendChangePhone(ctx, u.ID, phone)
+	require.NoError(t, err)
+	code = tconf.GetSMSCode(t, path, phone)
+	for i := 0; i < token.PhoneAttempts; i++ {
+		_, err = a.ConfirmChangePhone(ctx, u.ID, phone, "bad")
+		assert.Error(t, err)
+	}
+	_, err = a.ConfirmChangePhone(ctx, u.ID, phone, code)
+	assert.Error(t, err)
+	// a reissued code is still rate limited
+	err = a.SendChangePhone(ctx, u.ID, phone)
+	assert.ErrorIs(t, err, config.ErrRateLimitExceeded)
+	// phone taken
+	resetSendLimit(t, a, u)
+	err = a.SendChangePhone(ctx, u.ID, phone)
+	require.NoError(t, err)
+	code = tconf.GetSMSCode(t, path, phone)
+	u3 := confirmUser(t, a, testUser(t, a))
+	u3.Phone = &phone
+	err = a.conn.Save(u3).Error
+	require.NoError(t, err)
+	_, err = a.ConfirmChangePhone(ctx, u.ID, phone, code)
+	assert.Error(t, err)
+}
+
+func TestAPI_SendPhoneLogin(t *testing.T) {
+	t.Parallel()
+	a, path := smsAPI(t)
+	ctx := testContext(a)
+	u := confirmUser(t, a, testUser(t, a))
+	phone := tutils.RandomPhone()
+	// unknown phone
+	err := a.SendPhoneLogin(ctx, phone)
+	assert.Error(t, err)
+	err = a.SendChangePhone(ctx, u.ID, phone)
+	require.NoError(t, err)
+	code := tconf.GetSMSCode(t, path, phone)
+	_, err = a.ConfirmChangePhone(ctx, u.ID, phone, code)
+	require.NoError(t, err)
+	// bad phone
+	err = a.SendPhoneLogin(ctx, "bad")
+	assert.Error(t, err)
+	err = a.SendPhoneLogin(nil, phone)
+	require.NoError(t, err)
+	code2 := tconf.GetSMSCode(t, path, phone)
+	assert.NotEmpty(t, code2)
+	// rate limit
+	err = a.SendPhoneLogin(ctx, phone)
+	assert.ErrorIs(t, err, config.ErrRateLimitExceeded)
+	// external user
+	resetSendLimit(t, a, u)
+	forceExtProvider(t, a, u)
+	err = a.SendPhoneLogin(ctx, phone)
+	assert.Error(t, err)
+	// phone login disabled
+	a.config.SMS.Login = false
+	err = a.SendPhoneLogin(ctx, phone)
+	assert.Error(t, err)
+	// sms offline
+	a.config.SMS.Login = true
+	a.sms = nil
+	err = a.SendPhoneLogin(ctx, phone)
+	assert.NoError(t, err)
+}
+
+func TestAPI_SendPhoneLogin_ReCaptcha(t *testing.T) {
+	t.Parallel()
+	a, _ := smsAPI(t)
+	u := confirmUser(t, a, testUser(t, a))
+	phone := tutils.RandomPhone()
+	now := time.Now().UTC()
+	u.Phone = &phone
+	u.PhoneConfirmedAt = &now
+	err := a.conn.Save(u).Error
+	require.NoError(t, err)
+	a.config.Security.Recaptcha.Login = true
+	a.config.Recaptcha.Key = validate.ReCaptchaDebugKey
+	ctx := testContext(a)
+	err = a.SendPhoneLogin(ctx, phone)
+	assert.Error(t, err)
+	ctx.SetReCaptcha("bad")
+	err = a.SendPhoneLogin(ctx, phone)
+	assert.Error(t, err)
+	ctx.SetReCaptcha(validate.ReCaptchaDebugToken)
+	err = a.SendPhoneLogin(ctx, phone)
+	assert.NoError(t, err)
+}
+
+func TestAPI_PhoneLogin(t *testing.T) {
+	t.Parallel()
+	a, path := smsAPI(t)
+	ctx := testContext(a)
+	u := confirmUser(t, a, testUser(t, a))
+	phone := tutils.RandomPhone()
+	now := time.Now().UTC()
+	u.Phone = &phone
+	u.PhoneConfirmedAt = &now
+	err := a.conn.Save(u).Error
+	require.NoError(t, err)
+	err = a.SendPhoneLogin(ctx, phone)
+	require.NoError(t, err)
+	code := tconf.GetSMSCode(t, path, phone)
+	// bad phone
+	_, err = a.PhoneLogin(ctx, "bad", code)
+	assert.Error(t, err)
+	// unknown phone
+	_, err = a.PhoneLogin(ctx, tutils.RandomPhone(), code)
+	assert.Error(t, err)
+	// no code
+	_, err = a.PhoneLogin(ctx, phone, "")
+	assert.Error(t, err)
+	// bad code
+	_, err = a.PhoneLogin(ctx, phone, "bad")
+	assert.Error(t, err)
+	lu, err := a.PhoneLogin(nil, phone, code)
+	require.NoError(t, err)
+	assert.Equal(t, u.ID, lu.ID)
+	assert.NotNil(t, lu.LoginAt)
+	hasAuditEntry(t, a, auditlog.Login, u.ID)
+	// codes cannot be reused
+	_, err = a.PhoneLogin(ctx, phone, code)
+	assert.Error(t, err)
+	// external user
+	resetSendLimit(t, a, u)
+	err = a.SendPhoneLogin(ctx, phone)
+	require.NoError(t, err)
+	code = tconf.GetSMSCode(t, path, phone)
+	u.Provider = provider.Google
+	err = a.conn.Save(u).Error
+	require.NoError(t, err)
+	_, err = a.PhoneLogin(ctx, phone, code)
+	assert.Error(t, err)
+	// phone login disabled
+	a.config.SMS.Login = false
+	_, err = a.PhoneLogin(ctx, phone, code)
+	assert.Error(t, err)
+}
+
+func TestAPI_PhoneLogin_Purpose(t *testing.T) {
+	t.Parallel()
+	a, path := smsAPI(t)
+	ctx := testContext(a)
+	u := confirmUser(t, a, testUser(t, a))
+	phone := tutils.RandomPhone()
+	now := time.Now().UTC()
+	u.Phone = &phone
+	u.PhoneConfirmedAt = &now
+	err := a.conn.Save(u).Error
+	require.NoError(t, err)
+	newPhone := tutils.RandomPhone()
+	err = a.SendChangePhone(ctx, u.ID, newPhone)
+	require.NoError(t, err)
+	changeCode := tconf.GetSMSCode(t, path, newPhone)
+	// a login code does not replace the phone change code
+	err = a.SendPhoneLogin(ctx, phone)
+	require.NoError(t, err)
+	loginCode := tconf.GetSMSCode(t, path, phone)
+	_, err = a.ConfirmChangePhone(ctx, u.ID, newPhone, loginCode)
+	assert.Error(t, err)
+	lu, err := a.PhoneLogin(ctx, phone, loginCode)
+	require.NoError(t, err)
+	assert.Equal(t, u.ID, lu.ID)
+	u2, err := a.ConfirmChangePhone(ctx, u.ID, newPhone, changeCode)
+	require.NoError(t, err)
+	assert.Equal(t, newPhone, *u2.Phone)
+}
+
+func TestAPI_PhoneLogin_Lockout(t *testing.T) {
+	t.Parallel()
+	a, path := smsAPI(t)
+	a.config.Lockout.Attempts = 3
+	ctx := testContext(a)
+	u := confirmUser(t, a, testUser(t, a))
+	phone := tutils.RandomPhone()
+	now := time.Now().UTC()
+	u.Phone = &phone
+	u.PhoneConfirmedAt = &now
+	err := a.conn.Save(u).Error
+	require.NoError(t, err)
+	err = a.SendPhoneLogin(ctx, phone)
+	require.NoError(t, err)
+	code := tconf.GetSMSCode(t, path, phone)
+	// invalid codes count towards the lockout
+	for i := 0; i < a.config.Lockout.Attempts; i++ {
+		_, err = a.PhoneLogin(ctx, phone, "bad")
+		assert.Error(t, err)
+	}
+	f := store.Filters{key.UserID: u.ID.String()}
+	assert.Equal(t, a.config.Lockout.Attempts,
+		countAuditEntries(t, a, auditlog.LoginFailed, f))
+	hasAuditEntry(t, a, auditlog.Locked, u.ID)
+	_, err = a.PhoneLogin(ctx, phone, code)
+	assert.Error(t, err)
+	lu, err := a.GetUser(u.ID)
+	require.NoError(t, err)
+	assert.True(t, lu.IsLocked())
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/audit"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/jwt"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/store"
+)
+
+// ValidateBearerToken returns an error if the bearer token for the claims has been revoked.
+func (a *API) ValidateBearerToken(claims *jwt.UserClaims) error {
+	err := a.revoked.Refresh()
+	if err != nil {
+		// the cached revocations are still checked
+		a.log.Errorf("failed to reload revoked tokens: %v", err)
+	}
+	if a.revoked.Revoked(claims) {
+		return tokens.ErrTokenRevoked
+	}
+	return nil
+}
+
+// RevokeUserToken revokes the bearer token with the token id (jti) issued to the user.
+// NOTE: This API requires admin user permissions.
+func (a *API) RevokeUserToken(ctx context.Context, userID uuid.UUID, tokenID string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !ctx.IsAdmin() {
+		err := errors.New("admin user required")
+		return a.logError(err)
+	}
+	var rt *token.RevokedToken
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		u, err := a.revocableUser(tx, ctx.AdminID(), userID)
+		if err != nil {
+			return err
+		}
+		rt, err = tokens.RevokeBearerToken(tx, a.config.JWT, u.ID, tokenID)
+		if err != nil {
+			return err
+		}
+		return audit.LogBearerRevoked(ctx, tx, rt)
+	})
+	if err != nil {
+		return a.logError(err)
+	}
+	a.revoked.Add(rt)
+	a.log.Debugf("revoked token %s: %s", tokenID, userID)
+	return nil
+}
+
+// RevokeUserTokens revokes all the bearer tokens issued to the user before
+// a timestamp. If the timestamp is zero, the tokens issued before now are revoked.
+// NOTE: This API requires admin user permissions.
+func (a *API) RevokeUserTokens(ctx context.Context, userID uuid.UUID, before time.Time) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !ctx.IsAdmin() {
+		err := errors.New("admin user required")
+		return a.logError(err)
+	}
+	if before.IsZero() {
+		before = time.Now()
+	}
+	var rt *token.RevokedToken
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		u, err := a.revocableUser(tx, ctx.AdminID(), userID)
+		if err != nil {
+			return err
+		}
+		rt, err = tokens.RevokeBearerTokens(tx, a.config.JWT, u.ID, before)
+		if err != nil {
+			return err
+		}
+		return audit.LogBearersRevoked(ctx, tx, rt)
+	})
+	if err != nil {
+		return a.logError(err)
+	}
+	a.revoked.Add(rt)
+	a.log.Debugf("revoked tokens before %s: %s", before, userID)
+	return nil
+}
+
+// revokeCurrentToken revokes the bearer token of the context (if any).
+func (a *API) revokeCurrentToken(ctx context.Context, tx *store.Connection, userID uuid.UUID) (*token.RevokedToken, error) {
+	if ctx.TokenID() == "" {
+		return nil, nil
+	}
+	return tokens.RevokeBearerToken(tx, a.config.JWT, userID, ctx.TokenID())
+}
+
+// revokeTokens revokes all the bearer tokens issued to the user before now.
+func (a *API) revokeTokens(tx *store.Connection, userID uuid.UUID) (*token.RevokedToken, error) {
+	return tokens.RevokeBearerTokens(tx, a.config.JWT, userID, time.Now())
+}
@@ -0,0 +1,154 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/jwt"
+	"github.com/jrapoport/gothic/models/auditlog"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func bearerClaims(t *testing.T, a *API, u *user.User) *jwt.UserClaims {
+	bt, err := a.GrantBearerToken(testContext(a), u)
+	require.NoError(t, err)
+	claims, err := jwt.ParseUserClaims(a.config.JWT, bt.String())
+	require.NoError(t, err)
+	require.NotEmpty(t, claims.JwtID())
+	return claims
+}
+
+func TestAPI_ValidateBearerToken(t *testing.T) {
+	t.Parallel()
+	a := loginAPI(t)
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	claims := bearerClaims(t, a, u)
+	err := a.ValidateBearerToken(nil)
+	assert.NoError(t, err)
+	err = a.ValidateBearerToken(claims)
+	assert.NoError(t, err)
+	ctx := rootContext(a)
+	err = a.RevokeUserToken(ctx, u.ID, claims.JwtID())
+	require.NoError(t, err)
+	err = a.ValidateBearerToken(claims)
+	assert.ErrorIs(t, err, tokens.ErrTokenRevoked)
+	// revocations are loaded from the db
+	d, err := tokens.LoadDenylist(a.conn)
+	require.NoError(t, err)
+	assert.True(t, d.Revoked(claims))
+}
+
+func TestAPI_RevokeUserToken(t *testing.T) {
+	t.Parallel()
+	a := loginAPI(t)
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	claims1 := bearerClaims(t, a, u)
+	claims2 := bearerClaims(t, a, u)
+	jti := claims1.JwtID()
+	// not admin
+	err := a.RevokeUserToken(testContext(a), u.ID, jti)
+	assert.Error(t, err)
+	err = a.RevokeUserToken(nil, u.ID, jti)
+	assert.Error(t, err)
+	// bad admin
+	ctx := testContext(a)
+	ctx.SetAdminID(u.ID)
+	err = a.RevokeUserToken(ctx, u.ID, jti)
+	assert.Error(t, err)
+	ctx = rootContext(a)
+	err = a.RevokeUserToken(ctx, uuid.New(), jti)
+	assert.Error(t, err)
+	err = a.RevokeUserToken(ctx, u.ID, "")
+	assert.Error(t, err)
+	err = a.RevokeUserToken(ctx, u.ID, jti)
+	assert.NoError(t, err)
+	hasAuditEntry(t, a, auditlog.BearerRevoked, u.ID)
+	assert.Error(t, a.ValidateBearerToken(claims1))
+	assert.NoError(t, a.ValidateBearerToken(claims2))
+	// admin users
+	adm := testUser(t, a)
+	adm = promoteUser(t, a, adm)
+	aclaims := bearerClaims(t, a, adm)
+	u2 := testUser(t, a)
+	u2 = promoteUser(t, a, u2)
+	actx := testContext(a)
+	actx.SetAdminID(u2.ID)
+	err = a.RevokeUserToken(actx, adm.ID, aclaims.JwtID())
+	assert.Error(t, err)
+	err = a.RevokeUserToken(ctx, adm.ID, aclaims.JwtID())
+	assert.NoError(t, err)
+	assert.Error(t, a.ValidateBearerToken(aclaims))
+}
+
+func TestAPI_RevokeUserTokens(t *testing.T) {
+	t.Parallel()
+	a := loginAPI(t)
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	claims1 := bearerClaims(t, a, u)
+	// not admin
+	err := a.RevokeUserTokens(testContext(a), u.ID, time.Time{})
+	assert.Error(t, err)
+	err = a.RevokeUserTokens(nil, u.ID, time.Time{})
+	assert.Error(t, err)
+	ctx := rootContext(a)
+	err = a.RevokeUserTokens(ctx, uuid.New(), time.Time{})
+	assert.Error(t, err)
+	// before a timestamp
+	err = a.RevokeUserTokens(ctx, u.ID, claims1.Issued().Add(-time.Second))
+	assert.NoError(t, err)
+	hasAuditEntry(t, a, auditlog.BearersRevoked, u.ID)
+	assert.NoError(t, a.ValidateBearerToken(claims1))
+	claims2 := bearerClaims(t, a, u)
+	// before now
+	err = a.RevokeUserTokens(ctx, u.ID, time.Time{})
+	assert.NoError(t, err)
+	assert.Error(t, a.ValidateBearerToken(claims1))
+	assert.Error(t, a.ValidateBearerToken(claims2))
+	claims3 := bearerClaims(t, a, u)
+	assert.NoError(t, a.ValidateBearerToken(claims3))
+}
+
+func TestAPI_RevokeTokens(t *testing.T) {
+	t.Parallel()
+	a := loginAPI(t)
+	newUser := func() (*user.User, *jwt.UserClaims) {
+		u := testUser(t, a)
+		u = confirmUser(t, a, u)
+		return u, bearerClaims(t, a, u)
+	}
+	// logout
+	u, claims := newUser()
+	other := bearerClaims(t, a, u)
+	ctx := testContext(a)
+	ctx.SetSessionID(claims.SessionID())
+	ctx.SetTokenID(claims.JwtID())
+	err := a.Logout(ctx, u.ID)
+	require.NoError(t, err)
+	assert.Error(t, a.ValidateBearerToken(claims))
+	assert.NoError(t, a.ValidateBearerToken(other))
+	// ban
+	u, claims = newUser()
+	_, err = a.BanUser(rootContext(a), u.ID)
+	require.NoError(t, err)
+	assert.Error(t, a.ValidateBearerToken(claims))
+	// role change
+	u, claims = newUser()
+	u, err = a.PromoteUser(rootContext(a), u.ID)
+	require.NoError(t, err)
+	assert.Error(t, a.ValidateBearerToken(claims))
+	assert.NoError(t, a.ValidateBearerToken(bearerClaims(t, a, u)))
+	// password change
+	u, claims = newUser()
+	_, err = a.ChangePassword(context.Background(), u.ID, testPass, "1"+testPass)
+	require.NoError(t, err)
+	assert.Error(t, a.ValidateBearerToken(claims))
+	assert.NoError(t, a.ValidateBearerToken(bearerClaims(t, a, u)))
+}
@@ -0,0 +1,329 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/audit"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/core/roles"
+	"github.com/jrapoport/gothic/core/users"
+	"github.com/jrapoport/gothic/models/rbac"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+)
+
+//////////////////////////////////
+//////////////////////////////////
+// NOTE:
+// APIs in this file require admin
+// or role permissions
+//
+//
+
+// CreateRole creates a new custom role with the permissions. An admin can
+// only grant the permissions it has.
+// NOTE: This API requires the roles:write permission.
+func (a *API) CreateRole(ctx context.Context, name, description string, perms []string) (*rbac.Role, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !ctx.IsAdmin() {
+		err := errors.New("admin user required")
+		return nil, a.logError(err)
+	}
+	var r *rbac.Role
+	err := a.conn.Transaction(func(tx *store.Connection) (err error) {
+		_, err = a.validateAdmin(tx, ctx.AdminID(), rbac.RolesWrite)
+		if err != nil {
+			return err
+		}
+		p := rbac.ToPermissions(perms)
+		err = a.validateGrant(tx, ctx.AdminID(), p)
+		if err != nil {
+			return err
+		}
+		r, err = roles.CreateRole(tx, name, description, p)
+		if err != nil {
+			return err
+		}
+		return audit.LogRoleCreated(ctx, tx, r)
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	a.log.Debugf("created role: %s", r.Name)
+	return r, nil
+}
+
+// GetRoles returns the custom roles.
+// NOTE: This API requires the roles:read permission.
+func (a *API) GetRoles(ctx context.Context) ([]*rbac.Role, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !ctx.IsAdmin() {
+		err := errors.New("admin user required")
+		return nil, a.logError(err)
+	}
+	var list []*rbac.Role
+	err := a.conn.Transaction(func(tx *store.Connection) (err error) {
+		_, err = a.validateAdmin(tx, ctx.AdminID(), rbac.RolesRead)
+		if err != nil {
+			return err
+		}
+		list, err = roles.GetRoles(tx)
+		return err
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	return list, nil
+}
+
+// GetRole returns the custom role for the name.
+// NOTE: This API requires the roles:read permission.
+func (a *API) GetRole(ctx context.Context, name string) (*rbac.Role, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !ctx.IsAdmin() {
+		err := errors.New("admin user required")
+		return nil, a.logError(err)
+	}
+	var r *rbac.Role
+	err := a.conn.Transaction(func(tx *store.Connection) (err error) {
+		_, err = a.validateAdmin(tx, ctx.AdminID(), rbac.RolesRead)
+		if err != nil {
+			return err
+		}
+		r, err = roles.GetRole(tx, name)
+		return err
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	return r, nil
+}
+
+// UpdateRole updates the description & permissions of a custom role. If the
+// description is nil it is not changed. If the permissions are nil they are
+// not changed. An admin can only grant the permissions it has.
+// NOTE: This API requires the roles:write permission.
+func (a *API) UpdateRole(ctx context.Context, name string, description *string, perms []string) (*rbac.Role, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !ctx.IsAdmin() {
+		err := errors.New("admin user required")
+		return nil, a.logError(err)
+	}
+	var r *rbac.Role
+	err := a.conn.Transaction(func(tx *store.Connection) (err error) {
+		_, err = a.validateAdmin(tx, ctx.AdminID(), rbac.RolesWrite)
+		if err != nil {
+			return err
+		}
+		var p []rbac.Permission
+		if perms != nil {
+			p = rbac.ToPermissions(perms)
+			err = a.validateGrant(tx, ctx.AdminID(), p)
+			if err != nil {
+				return err
+			}
+		}
+		r, err = roles.UpdateRole(tx, name, description, p)
+		if err != nil {
+			return err
+		}
+		return audit.LogRoleUpdated(ctx, tx, r)
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	a.log.Debugf("updated role: %s", r.Name)
+	return r, nil
+}
+
+// DeleteRole deletes a custom role. The role is removed from its users.
+// NOTE: This API requires the roles:write permission.
+func (a *API) DeleteRole(ctx context.Context, name string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !ctx.IsAdmin() {
+		err := errors.New("admin user required")
+		return a.logError(err)
+	}
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		_, err := a.validateAdmin(tx, ctx.AdminID(), rbac.RolesWrite)
+		if err != nil {
+			return err
+		}
+		r, err := roles.DeleteRole(tx, name)
+		if err != nil {
+			return err
+		}
+		return audit.LogRoleDeleted(ctx, tx, r)
+	})
+	if err != nil {
+		return a.logError(err)
+	}
+	a.log.Debugf("deleted role: %s", name)
+	return nil
+}
+
+// AssignUserRole assigns a custom role to the user. An admin can only assign
+// a role with the permissions it has.
+// NOTE: This API requires the roles:assign permission.
+func (a *API) AssignUserRole(ctx context.Context, userID uuid.UUID, name string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !ctx.IsAdmin() {
+		err := errors.New("admin user required")
+		return a.logError(err)
+	}
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		_, err := a.validateAdmin(tx, ctx.AdminID(), rbac.RolesAssign)
+		if err != nil {
+			return err
+		}
+		u, err := users.GetUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		r, err := roles.GetRole(tx, name)
+		if err != nil {
+			return err
+		}
+		err = a.validateGrant(tx, ctx.AdminID(), r.PermissionList())
+		if err != nil {
+			return err
+		}
+		r, err = roles.AssignRole(tx, u.ID, r.Name)
+		if err != nil {
+			return err
+		}
+		return audit.LogRoleAssigned(ctx, tx, u.ID, r)
+	})
+	if err != nil {
+		return a.logError(err)
+	}
+	a.log.Debugf("assigned role %s: %s", name, userID)
+	return nil
+}
+
+// UnassignUserRole removes a custom role from the user.
+// NOTE: This API requires the roles:assign permission.
+func (a *API) UnassignUserRole(ctx context.Context, userID uuid.UUID, name string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !ctx.IsAdmin() {
+		err := errors.New("admin user required")
+		return a.logError(err)
+	}
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		_, err := a.validateAdmin(tx, ctx.AdminID(), rbac.RolesAssign)
+		if err != nil {
+			return err
+		}
+		u, err := users.GetUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		r, err := roles.UnassignRole(tx, u.ID, name)
+		if err != nil {
+			return err
+		}
+		return audit.LogRoleUnassigned(ctx, tx, u.ID, r)
+	})
+	if err != nil {
+		return a.logError(err)
+	}
+	a.log.Debugf("unassigned role %s: %s", name, userID)
+	return nil
+}
+
+// GetUserRoles returns the custom roles assigned to the user.
+// NOTE: This API requires the roles:read permission.
+func (a *API) GetUserRoles(ctx context.Context, userID uuid.UUID) ([]*rbac.Role, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !ctx.IsAdmin() {
+		err := errors.New("admin user required")
+		return nil, a.logError(err)
+	}
+	var list []*rbac.Role
+	err := a.conn.Transaction(func(tx *store.Connection) (err error) {
+		_, err = a.validateAdmin(tx, ctx.AdminID(), rbac.RolesRead)
+		if err != nil {
+			return err
+		}
+		u, err := users.GetUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		list, err = roles.GetUserRoles(tx, u.ID)
+		return err
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	return list, nil
+}
+
+// GetUserPermissions returns the permissions of the user. The permissions
+// include the permissions of the built-in role of the user.
+// NOTE: This API requires the roles:read permission.
+func (a *API) GetUserPermissions(ctx context.Context, userID uuid.UUID) ([]rbac.Permission, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !ctx.IsAdmin() {
+		err := errors.New("admin user required")
+		return nil, a.logError(err)
+	}
+	var perms []rbac.Permission
+	err := a.conn.Transaction(func(tx *store.Connection) (err error) {
+		_, err = a.validateAdmin(tx, ctx.AdminID(), rbac.RolesRead)
+		if err != nil {
+			return err
+		}
+		u, err := users.GetUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		perms, err = roles.GetPermissions(tx, u)
+		return err
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	return perms, nil
+}
+
+// validateGrant validates that the admin has all the permissions it is
+// granting, so an admin can not grant itself permissions it does not have.
+func (a *API) validateGrant(tx *store.Connection, aid uuid.UUID, perms []rbac.Permission) error {
+	if aid == user.SuperAdminID {
+		return nil
+	}
+	adm, err := users.GetAuthenticatedUser(tx, aid)
+	if err != nil {
+		return err
+	}
+	has, err := roles.GetPermissions(tx, adm)
+	if err != nil {
+		return err
+	}
+	for _, p := range perms {
+		if !rbac.Has(has, p) {
+			return fmt.Errorf("permission required: %s %s", aid, p)
+		}
+	}
+	return nil
+}
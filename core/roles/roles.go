@@ -0,0 +1,166 @@
+package roles
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/rbac"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+)
+
+// CreateRole creates a new role with the permissions.
+func CreateRole(conn *store.Connection, name, description string, perms []rbac.Permission) (*rbac.Role, error) {
+	r := rbac.NewRole(name, description, perms)
+	err := conn.Create(r).Error
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetRole returns the role with the name.
+func GetRole(conn *store.Connection, name string) (*rbac.Role, error) {
+	if name == "" {
+		return nil, errors.New("invalid role name")
+	}
+	var r rbac.Role
+	err := conn.First(&r, key.Name+" = ?", name).Error
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// GetRoles returns all the roles.
+func GetRoles(conn *store.Connection) ([]*rbac.Role, error) {
+	var list []*rbac.Role
+	err := conn.Order(key.Name).Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// UpdateRole updates the description & permissions of a role. If the
+// description is nil it is not changed. If the permissions are nil they
+// are not changed.
+func UpdateRole(conn *store.Connection, name string, description *string, perms []rbac.Permission) (*rbac.Role, error) {
+	r, err := GetRole(conn, name)
+	if err != nil {
+		return nil, err
+	}
+	if description != nil {
+		r.Description = *description
+	}
+	if perms != nil {
+		r.SetPermissions(perms)
+	}
+	err = conn.Save(r).Error
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// DeleteRole deletes a role and its assignments.
+func DeleteRole(conn *store.Connection, name string) (*rbac.Role, error) {
+	r, err := GetRole(conn, name)
+	if err != nil {
+		return nil, err
+	}
+	err = conn.Where("role_id = ?", r.ID).Delete(&rbac.Assignment{}).Error
+	if err != nil {
+		return nil, err
+	}
+	err = conn.Unscoped().Delete(r).Error
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// AssignRole assigns a role to a user.
+func AssignRole(conn *store.Connection, userID uuid.UUID, name string) (*rbac.Role, error) {
+	if userID == uuid.Nil || userID == user.SystemID || userID == user.SuperAdminID {
+		return nil, errors.New("invalid user id")
+	}
+	r, err := GetRole(conn, name)
+	if err != nil {
+		return nil, err
+	}
+	var n int64
+	err = conn.Model(&rbac.Assignment{}).
+		Where("user_id = ? AND role_id = ?", userID, r.ID).
+		Count(&n).Error
+	if err != nil {
+		return nil, err
+	}
+	if n > 0 {
+		return nil, errors.New("role already assigned")
+	}
+	err = conn.Create(&rbac.Assignment{UserID: userID, RoleID: r.ID}).Error
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// UnassignRole removes a role from a user.
+func UnassignRole(conn *store.Connection, userID uuid.UUID, name string) (*rbac.Role, error) {
+	r, err := GetRole(conn, name)
+	if err != nil {
+		return nil, err
+	}
+	res := conn.Where("user_id = ? AND role_id = ?", userID, r.ID).
+		Delete(&rbac.Assignment{})
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, errors.New("role not assigned")
+	}
+	return r, nil
+}
+
+// GetUserRoles returns the roles assigned to a user.
+func GetUserRoles(conn *store.Connection, userID uuid.UUID) ([]*rbac.Role, error) {
+	var list []*rbac.Role
+	err := conn.
+		Joins("JOIN rbac_assignments ON rbac_assignments.role_id = rbac_roles.id").
+		Where("rbac_assignments.user_id = ?", userID).
+		Order("rbac_roles.name").
+		Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetPermissions returns the permissions of a user. The permissions are the
+// permissions of the built-in user role, and the roles assigned to the user.
+func GetPermissions(conn *store.Connection, u *user.User) ([]rbac.Permission, error) {
+	if u == nil {
+		return nil, errors.New("invalid user")
+	}
+	list, err := GetUserRoles(conn, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	perms := make([][]rbac.Permission, len(list)+1)
+	perms[0] = rbac.RolePermissions(u.Role)
+	for i, r := range list {
+		perms[i+1] = r.PermissionList()
+	}
+	return rbac.Merge(perms...), nil
+}
+
+// HasPermission returns true if the user has the permission.
+func HasPermission(conn *store.Connection, u *user.User, p rbac.Permission) (bool, error) {
+	perms, err := GetPermissions(conn, u)
+	if err != nil {
+		return false, err
+	}
+	return rbac.Has(perms, p), nil
+}
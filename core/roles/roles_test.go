@@ -0,0 +1,185 @@
+package roles
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/rbac"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/jrapoport/gothic/test/tutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRole = "support"
+
+var testPermissions = []rbac.Permission{rbac.UsersRead, rbac.AuditRead}
+
+func testUser(t *testing.T, conn *store.Connection, r user.Role) *user.User {
+	u := user.NewUser("test", r, tutils.RandomEmail(), "", []byte(""), nil, nil)
+	err := conn.Create(u).Error
+	require.NoError(t, err)
+	return u
+}
+
+func TestCreateRole(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	_, err := CreateRole(conn, "", "", testPermissions)
+	assert.Error(t, err)
+	_, err = CreateRole(conn, testRole, "", nil)
+	assert.Error(t, err)
+	r, err := CreateRole(conn, testRole, "support staff", testPermissions)
+	require.NoError(t, err)
+	assert.NotZero(t, r.ID)
+	assert.Equal(t, testRole, r.Name)
+	assert.Equal(t, "support staff", r.Description)
+	assert.Equal(t, []rbac.Permission{rbac.AuditRead, rbac.UsersRead}, r.PermissionList())
+	_, err = CreateRole(conn, testRole, "", testPermissions)
+	assert.Error(t, err)
+}
+
+func TestGetRole(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	r, err := CreateRole(conn, testRole, "", testPermissions)
+	require.NoError(t, err)
+	_, err = GetRole(conn, "")
+	assert.Error(t, err)
+	_, err = GetRole(conn, "bad")
+	assert.Error(t, err)
+	got, err := GetRole(conn, testRole)
+	require.NoError(t, err)
+	assert.Equal(t, r.ID, got.ID)
+	_, err = CreateRole(conn, "auditor", "", testPermissions)
+	require.NoError(t, err)
+	list, err := GetRoles(conn)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "auditor", list[0].Name)
+	assert.Equal(t, testRole, list[1].Name)
+}
+
+func TestUpdateRole(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	_, err := CreateRole(conn, testRole, "support staff", testPermissions)
+	require.NoError(t, err)
+	_, err = UpdateRole(conn, "bad", nil, nil)
+	assert.Error(t, err)
+	_, err = UpdateRole(conn, testRole, nil, []rbac.Permission{})
+	assert.Error(t, err)
+	_, err = UpdateRole(conn, testRole, nil, []rbac.Permission{"bad"})
+	assert.Error(t, err)
+	r, err := UpdateRole(conn, testRole, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "support staff", r.Description)
+	assert.Equal(t, []rbac.Permission{rbac.AuditRead, rbac.UsersRead}, r.PermissionList())
+	desc := "helpdesk"
+	r, err = UpdateRole(conn, testRole, &desc, []rbac.Permission{rbac.UsersDelete})
+	require.NoError(t, err)
+	r, err = GetRole(conn, testRole)
+	require.NoError(t, err)
+	assert.Equal(t, desc, r.Description)
+	assert.Equal(t, []rbac.Permission{rbac.UsersDelete}, r.PermissionList())
+}
+
+func TestDeleteRole(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	u := testUser(t, conn, user.RoleUser)
+	_, err := CreateRole(conn, testRole, "", testPermissions)
+	require.NoError(t, err)
+	_, err = AssignRole(conn, u.ID, testRole)
+	require.NoError(t, err)
+	_, err = DeleteRole(conn, "bad")
+	assert.Error(t, err)
+	r, err := DeleteRole(conn, testRole)
+	require.NoError(t, err)
+	assert.Equal(t, testRole, r.Name)
+	_, err = GetRole(conn, testRole)
+	assert.Error(t, err)
+	list, err := GetUserRoles(conn, u.ID)
+	require.NoError(t, err)
+	assert.Len(t, list, 0)
+	// the name can be used again
+	_, err = CreateRole(conn, testRole, "", testPermissions)
+	assert.NoError(t, err)
+}
+
+func TestAssignRole(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	u := testUser(t, conn, user.RoleUser)
+	_, err := CreateRole(conn, testRole, "", testPermissions)
+	require.NoError(t, err)
+	_, err = CreateRole(conn, "auditor", "", []rbac.Permission{rbac.AuditRead})
+	require.NoError(t, err)
+	_, err = AssignRole(conn, uuid.Nil, testRole)
+	assert.Error(t, err)
+	_, err = AssignRole(conn, user.SuperAdminID, testRole)
+	assert.Error(t, err)
+	_, err = AssignRole(conn, u.ID, "bad")
+	assert.Error(t, err)
+	_, err = AssignRole(conn, u.ID, testRole)
+	require.NoError(t, err)
+	_, err = AssignRole(conn, u.ID, testRole)
+	assert.Error(t, err)
+	_, err = AssignRole(conn, u.ID, "auditor")
+	require.NoError(t, err)
+	list, err := GetUserRoles(conn, u.ID)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "auditor", list[0].Name)
+	assert.Equal(t, testRole, list[1].Name)
+	_, err = UnassignRole(conn, u.ID, "bad")
+	assert.Error(t, err)
+	_, err = UnassignRole(conn, uuid.New(), testRole)
+	assert.Error(t, err)
+	_, err = UnassignRole(conn, u.ID, testRole)
+	require.NoError(t, err)
+	_, err = UnassignRole(conn, u.ID, testRole)
+	assert.Error(t, err)
+	list, err = GetUserRoles(conn, u.ID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "auditor", list[0].Name)
+}
+
+func TestGetPermissions(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	_, err := GetPermissions(conn, nil)
+	assert.Error(t, err)
+	u := testUser(t, conn, user.RoleUser)
+	perms, err := GetPermissions(conn, u)
+	require.NoError(t, err)
+	assert.Empty(t, perms)
+	has, err := HasPermission(conn, u, rbac.UsersRead)
+	require.NoError(t, err)
+	assert.False(t, has)
+	_, err = CreateRole(conn, testRole, "", testPermissions)
+	require.NoError(t, err)
+	_, err = CreateRole(conn, "moderator", "", []rbac.Permission{rbac.UsersDelete, rbac.UsersRead})
+	require.NoError(t, err)
+	_, err = AssignRole(conn, u.ID, testRole)
+	require.NoError(t, err)
+	_, err = AssignRole(conn, u.ID, "moderator")
+	require.NoError(t, err)
+	perms, err = GetPermissions(conn, u)
+	require.NoError(t, err)
+	assert.Equal(t, []rbac.Permission{rbac.AuditRead, rbac.UsersDelete, rbac.UsersRead}, perms)
+	has, err = HasPermission(conn, u, rbac.UsersRead)
+	require.NoError(t, err)
+	assert.True(t, has)
+	has, err = HasPermission(conn, u, rbac.RolesWrite)
+	require.NoError(t, err)
+	assert.False(t, has)
+	// admins have all the permissions
+	adm := testUser(t, conn, user.RoleAdmin)
+	perms, err = GetPermissions(conn, adm)
+	require.NoError(t, err)
+	assert.Equal(t, rbac.Permissions, perms)
+}
@@ -0,0 +1,283 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/models/auditlog"
+	"github.com/jrapoport/gothic/models/rbac"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testPermissions = []string{
+	rbac.UsersRead.String(),
+	rbac.SessionsRead.String(),
+}
+
+func adminContext(t *testing.T, a *API, u *user.User) context.Context {
+	ctx := testContext(a)
+	_, err := a.GrantBearerToken(ctx, u)
+	require.NoError(t, err)
+	ctx.SetAdminID(u.ID)
+	return ctx
+}
+
+func testRole(t *testing.T, a *API, name string, perms []string) *rbac.Role {
+	r, err := a.CreateRole(rootContext(a), name, "", perms)
+	require.NoError(t, err)
+	return r
+}
+
+func TestAPI_CreateRole(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	u := confirmUser(t, a, testUser(t, a))
+	// not admin
+	_, err := a.CreateRole(nil, "support", "", testPermissions)
+	assert.Error(t, err)
+	_, err = a.CreateRole(adminContext(t, a, u), "support", "", testPermissions)
+	assert.Error(t, err)
+	ctx := rootContext(a)
+	_, err = a.CreateRole(ctx, "", "", testPermissions)
+	assert.Error(t, err)
+	_, err = a.CreateRole(ctx, "Support", "", testPermissions)
+	assert.Error(t, err)
+	_, err = a.CreateRole(ctx, user.RoleAdmin.String(), "", testPermissions)
+	assert.Error(t, err)
+	_, err = a.CreateRole(ctx, "support", "", nil)
+	assert.Error(t, err)
+	_, err = a.CreateRole(ctx, "support", "", []string{"bad"})
+	assert.Error(t, err)
+	r, err := a.CreateRole(ctx, "support", "support staff", testPermissions)
+	require.NoError(t, err)
+	assert.Equal(t, "support", r.Name)
+	assert.Equal(t, "support staff", r.Description)
+	assert.Equal(t, []rbac.Permission{rbac.SessionsRead, rbac.UsersRead}, r.PermissionList())
+	hasAuditEntry(t, a, auditlog.RoleCreated, user.SystemID)
+	// duplicate
+	_, err = a.CreateRole(ctx, "support", "", testPermissions)
+	assert.Error(t, err)
+	// an admin can only grant the permissions it has
+	r = testRole(t, a, "manager", []string{rbac.RolesWrite.String()})
+	err = a.AssignUserRole(rootContext(a), u.ID, r.Name)
+	require.NoError(t, err)
+	ctx = adminContext(t, a, u)
+	_, err = a.CreateRole(ctx, "escalate", "", []string{rbac.UsersDelete.String()})
+	assert.Error(t, err)
+	_, err = a.CreateRole(ctx, "writer", "", []string{rbac.RolesWrite.String()})
+	assert.NoError(t, err)
+}
+
+func TestAPI_GetRoles(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	u := confirmUser(t, a, testUser(t, a))
+	_, err := a.GetRoles(nil)
+	assert.Error(t, err)
+	_, err = a.GetRoles(adminContext(t, a, u))
+	assert.Error(t, err)
+	ctx := rootContext(a)
+	list, err := a.GetRoles(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, list, 0)
+	testRole(t, a, "support", testPermissions)
+	testRole(t, a, "auditor", []string{rbac.AuditRead.String()})
+	list, err = a.GetRoles(ctx)
+	assert.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "auditor", list[0].Name)
+	assert.Equal(t, "support", list[1].Name)
+	// admin
+	adm := promoteUser(t, a, testUser(t, a))
+	list, err = a.GetRoles(adminContext(t, a, adm))
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+}
+
+func TestAPI_GetRole(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	u := confirmUser(t, a, testUser(t, a))
+	r := testRole(t, a, "support", testPermissions)
+	_, err := a.GetRole(nil, r.Name)
+	assert.Error(t, err)
+	_, err = a.GetRole(adminContext(t, a, u), r.Name)
+	assert.Error(t, err)
+	ctx := rootContext(a)
+	_, err = a.GetRole(ctx, "")
+	assert.Error(t, err)
+	_, err = a.GetRole(ctx, "missing")
+	assert.Error(t, err)
+	got, err := a.GetRole(ctx, r.Name)
+	assert.NoError(t, err)
+	assert.Equal(t, r.ID, got.ID)
+}
+
+func TestAPI_UpdateRole(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	u := confirmUser(t, a, testUser(t, a))
+	r := testRole(t, a, "support", testPermissions)
+	desc := "updated"
+	_, err := a.UpdateRole(nil, r.Name, &desc, nil)
+	assert.Error(t, err)
+	_, err = a.UpdateRole(adminContext(t, a, u), r.Name, &desc, nil)
+	assert.Error(t, err)
+	ctx := rootContext(a)
+	_, err = a.UpdateRole(ctx, "missing", &desc, nil)
+	assert.Error(t, err)
+	_, err = a.UpdateRole(ctx, r.Name, nil, []string{})
+	assert.Error(t, err)
+	_, err = a.UpdateRole(ctx, r.Name, nil, []string{"bad"})
+	assert.Error(t, err)
+	r, err = a.UpdateRole(ctx, r.Name, &desc, nil)
+	require.NoError(t, err)
+	assert.Equal(t, desc, r.Description)
+	assert.Equal(t, []rbac.Permission{rbac.SessionsRead, rbac.UsersRead}, r.PermissionList())
+	perms := []string{rbac.AuditRead.String()}
+	r, err = a.UpdateRole(ctx, r.Name, nil, perms)
+	require.NoError(t, err)
+	assert.Equal(t, desc, r.Description)
+	assert.Equal(t, []rbac.Permission{rbac.AuditRead}, r.PermissionList())
+}
+
+func TestAPI_DeleteRole(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	u := confirmUser(t, a, testUser(t, a))
+	r := testRole(t, a, "support", testPermissions)
+	ctx := rootContext(a)
+	err := a.AssignUserRole(ctx, u.ID, r.Name)
+	require.NoError(t, err)
+	err = a.DeleteRole(nil, r.Name)
+	assert.Error(t, err)
+	err = a.DeleteRole(adminContext(t, a, u), r.Name)
+	assert.Error(t, err)
+	err = a.DeleteRole(ctx, "missing")
+	assert.Error(t, err)
+	err = a.DeleteRole(ctx, r.Name)
+	assert.NoError(t, err)
+	hasAuditEntry(t, a, auditlog.RoleDeleted, user.SystemID)
+	_, err = a.GetRole(ctx, r.Name)
+	assert.Error(t, err)
+	list, err := a.GetUserRoles(ctx, u.ID)
+	assert.NoError(t, err)
+	assert.Len(t, list, 0)
+}
+
+func TestAPI_AssignUserRole(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	u := confirmUser(t, a, testUser(t, a))
+	r := testRole(t, a, "support", testPermissions)
+	err := a.AssignUserRole(nil, u.ID, r.Name)
+	assert.Error(t, err)
+	err = a.AssignUserRole(adminContext(t, a, u), u.ID, r.Name)
+	assert.Error(t, err)
+	ctx := rootContext(a)
+	err = a.AssignUserRole(ctx, uuid.New(), r.Name)
+	assert.Error(t, err)
+	err = a.AssignUserRole(ctx, u.ID, "missing")
+	assert.Error(t, err)
+	err = a.AssignUserRole(ctx, u.ID, r.Name)
+	assert.NoError(t, err)
+	hasAuditEntry(t, a, auditlog.RoleAssigned, u.ID)
+	err = a.AssignUserRole(ctx, u.ID, r.Name)
+	assert.Error(t, err)
+	list, err := a.GetUserRoles(ctx, u.ID)
+	assert.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, r.Name, list[0].Name)
+	// an admin can only assign the permissions it has
+	assigner := testRole(t, a, "assigner", []string{rbac.RolesAssign.String()})
+	err = a.AssignUserRole(ctx, u.ID, assigner.Name)
+	require.NoError(t, err)
+	o := confirmUser(t, a, testUser(t, a))
+	admCtx := adminContext(t, a, u)
+	err = a.AssignUserRole(admCtx, o.ID, r.Name)
+	assert.NoError(t, err)
+	auditor := testRole(t, a, "auditor", []string{rbac.AuditRead.String()})
+	err = a.AssignUserRole(admCtx, o.ID, auditor.Name)
+	assert.Error(t, err)
+}
+
+func TestAPI_UnassignUserRole(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	u := confirmUser(t, a, testUser(t, a))
+	r := testRole(t, a, "support", testPermissions)
+	ctx := rootContext(a)
+	err := a.AssignUserRole(ctx, u.ID, r.Name)
+	require.NoError(t, err)
+	err = a.UnassignUserRole(nil, u.ID, r.Name)
+	assert.Error(t, err)
+	err = a.UnassignUserRole(adminContext(t, a, u), u.ID, r.Name)
+	assert.Error(t, err)
+	err = a.UnassignUserRole(ctx, uuid.New(), r.Name)
+	assert.Error(t, err)
+	err = a.UnassignUserRole(ctx, u.ID, "missing")
+	assert.Error(t, err)
+	err = a.UnassignUserRole(ctx, u.ID, r.Name)
+	assert.NoError(t, err)
+	hasAuditEntry(t, a, auditlog.RoleUnassigned, u.ID)
+	err = a.UnassignUserRole(ctx, u.ID, r.Name)
+	assert.Error(t, err)
+}
+
+func TestAPI_GetUserPermissions(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	u := confirmUser(t, a, testUser(t, a))
+	ctx := rootContext(a)
+	_, err := a.GetUserPermissions(nil, u.ID)
+	assert.Error(t, err)
+	_, err = a.GetUserPermissions(ctx, uuid.New())
+	assert.Error(t, err)
+	perms, err := a.GetUserPermissions(ctx, u.ID)
+	assert.NoError(t, err)
+	assert.Len(t, perms, 0)
+	r := testRole(t, a, "support", testPermissions)
+	err = a.AssignUserRole(ctx, u.ID, r.Name)
+	require.NoError(t, err)
+	perms, err = a.GetUserPermissions(ctx, u.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []rbac.Permission{rbac.SessionsRead, rbac.UsersRead}, perms)
+	adm := promoteUser(t, a, testUser(t, a))
+	perms, err = a.GetUserPermissions(ctx, adm.ID)
+	assert.NoError(t, err)
+	assert.Len(t, perms, len(rbac.Permissions))
+}
+
+func TestAPI_ValidateAdmin_Permission(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	u := confirmUser(t, a, testUser(t, a))
+	uctx := adminContext(t, a, u)
+	ctx := rootContext(a)
+	_, err := a.ValidateAdmin(u.ID, rbac.SessionsRead)
+	assert.Error(t, err)
+	r := testRole(t, a, "support", testPermissions)
+	err = a.AssignUserRole(ctx, u.ID, r.Name)
+	require.NoError(t, err)
+	role, err := a.ValidateAdmin(u.ID, rbac.SessionsRead)
+	assert.NoError(t, err)
+	assert.Equal(t, user.RoleUser, role)
+	_, err = a.ValidateAdmin(u.ID, rbac.SessionsRevoke)
+	assert.Error(t, err)
+	// the role grants access to the admin apis
+	_, err = a.GetUserSessions(uctx, u.ID)
+	assert.NoError(t, err)
+	_, err = a.RevokeUserSessions(uctx, u.ID)
+	assert.Error(t, err)
+	adm := promoteUser(t, a, testUser(t, a))
+	adminContext(t, a, adm)
+	role, err = a.ValidateAdmin(adm.ID, rbac.SessionsRevoke)
+	assert.NoError(t, err)
+	assert.Equal(t, user.RoleAdmin, role)
+	role, err = a.ValidateAdmin(user.SuperAdminID, rbac.SessionsRevoke)
+	assert.NoError(t, err)
+	assert.Equal(t, user.RoleSuper, role)
+}
@@ -0,0 +1,226 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/audit"
+	"github.com/jrapoport/gothic/core/clients"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/core/oidc"
+	"github.com/jrapoport/gothic/core/users"
+	"github.com/jrapoport/gothic/jwt"
+	"github.com/jrapoport/gothic/models/client"
+	"github.com/jrapoport/gothic/models/rbac"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+)
+
+// CreateServiceClient creates a new service client that can use the client
+// credentials grant. The client secret is returned. Only a hash of the secret
+// is stored, so the secret can not be returned again. If the owner is not set
+// the admin is the owner of the service client.
+// NOTE: This API requires admin user permissions.
+func (a *API) CreateServiceClient(ctx context.Context, name string, scopes []string, ownerID uuid.UUID) (*client.Service, string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !ctx.IsAdmin() {
+		err := errors.New("admin user required")
+		return nil, "", a.logError(err)
+	}
+	if ownerID == uuid.Nil {
+		ownerID = ctx.AdminID()
+	}
+	var s *client.Service
+	var secret string
+	err := a.conn.Transaction(func(tx *store.Connection) (err error) {
+		_, err = a.validateAdmin(tx, ctx.AdminID(), rbac.ClientsWrite)
+		if err != nil {
+			return err
+		}
+		err = a.validateOwner(tx, ownerID)
+		if err != nil {
+			return err
+		}
+		s, secret, err = clients.CreateService(tx, name, scopes, ownerID)
+		if err != nil {
+			return err
+		}
+		return audit.LogServiceCreated(ctx, tx, s)
+	})
+	if err != nil {
+		return nil, "", a.logError(err)
+	}
+	a.log.Debugf("created service client %s: %s", s.ClientID, s.Name)
+	return s, secret, nil
+}
+
+// GetServiceClients returns the service clients.
+// NOTE: This API requires admin user permissions.
+func (a *API) GetServiceClients(ctx context.Context) ([]*client.Service, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !ctx.IsAdmin() {
+		err := errors.New("admin user required")
+		return nil, a.logError(err)
+	}
+	var list []*client.Service
+	err := a.conn.Transaction(func(tx *store.Connection) (err error) {
+		_, err = a.validateAdmin(tx, ctx.AdminID(), rbac.ClientsRead)
+		if err != nil {
+			return err
+		}
+		list, err = clients.GetServices(tx)
+		return err
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	return list, nil
+}
+
+// GetServiceClient returns the service client for the client id.
+// NOTE: This API requires admin user permissions.
+func (a *API) GetServiceClient(ctx context.Context, clientID string) (*client.Service, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !ctx.IsAdmin() {
+		err := errors.New("admin user required")
+		return nil, a.logError(err)
+	}
+	var s *client.Service
+	err := a.conn.Transaction(func(tx *store.Connection) (err error) {
+		_, err = a.validateAdmin(tx, ctx.AdminID(), rbac.ClientsRead)
+		if err != nil {
+			return err
+		}
+		s, err = clients.GetService(tx, clientID)
+		return err
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	return s, nil
+}
+
+// DeleteServiceClient deletes a service client. Tokens that were already
+// issued to the service client remain valid until they expire.
+// NOTE: This API requires admin user permissions.
+func (a *API) DeleteServiceClient(ctx context.Context, clientID string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !ctx.IsAdmin() {
+		err := errors.New("admin user required")
+		return a.logError(err)
+	}
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		_, err := a.validateAdmin(tx, ctx.AdminID(), rbac.ClientsWrite)
+		if err != nil {
+			return err
+		}
+		s, err := clients.GetService(tx, clientID)
+		if err != nil {
+			return err
+		}
+		err = clients.DeleteService(tx, s.ClientID)
+		if err != nil {
+			return err
+		}
+		return audit.LogServiceDeleted(ctx, tx, s)
+	})
+	if err != nil {
+		return a.logError(err)
+	}
+	a.log.Debugf("deleted service client: %s", clientID)
+	return nil
+}
+
+// GrantClientCredentials grants a service client a signed jwt access token
+// for the requested scopes. If no scopes are requested, the token is granted
+// all the scopes the service client is allowed. The subject of the token is
+// the client id and its subject type is jwt.SubjectService, so it can never
+// be used as a user token.
+func (a *API) GrantClientCredentials(ctx context.Context, req *oidc.TokenRequest) (*oidc.TokenResponse, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if req == nil {
+		err := oidc.NewError(oidc.InvalidRequest, "invalid request")
+		return nil, a.logError(err)
+	}
+	if req.GrantType != oidc.GrantClientCredentials {
+		err := oidc.NewError(oidc.UnsupportedGrantType, "")
+		return nil, a.logError(err)
+	}
+	var res *oidc.TokenResponse
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		s, err := a.authenticateService(tx, req.ClientID, req.ClientSecret)
+		if err != nil {
+			return err
+		}
+		scopes := oidc.ParseScope(req.Scope)
+		if len(scopes) == 0 {
+			scopes = s.ScopeList()
+		}
+		if !s.AllowsScopes(scopes) {
+			return oidc.NewError(oidc.InvalidScope, "scope not allowed")
+		}
+		tok := jwt.NewServiceToken(a.config.JWT, s, scopes)
+		bearer, err := tok.Bearer()
+		if err != nil {
+			return err
+		}
+		scope := oidc.FormatScope(scopes)
+		err = audit.LogServiceGranted(ctx, tx, s, tok.JwtID(), scope)
+		if err != nil {
+			return err
+		}
+		res = &oidc.TokenResponse{
+			AccessToken: bearer,
+			TokenType:   oidc.TokenTypeBearer,
+			ExpiresIn:   int(tok.Expiration().Seconds()),
+			Scope:       scope,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	a.log.Debugf("granted service client token: %s", req.ClientID)
+	return res, nil
+}
+
+// authenticateService returns the service client if the client secret
+// is valid and the owner of the service client is still active.
+func (a *API) authenticateService(tx *store.Connection, clientID, secret string) (*client.Service, error) {
+	s, err := clients.GetService(tx, clientID)
+	if err == nil {
+		err = s.Authenticate(secret)
+	}
+	if err == nil {
+		err = a.validateOwner(tx, s.OwnerID)
+	}
+	if err != nil {
+		a.logError(err)
+		err = oidc.NewError(oidc.InvalidClient, "client authentication failed")
+		return nil, err
+	}
+	return s, nil
+}
+
+// validateOwner returns nil if the user can own a service client.
+func (a *API) validateOwner(tx *store.Connection, ownerID uuid.UUID) error {
+	if ownerID == user.SuperAdminID {
+		return nil
+	}
+	_, err := users.GetActiveUser(tx, ownerID)
+	if err != nil {
+		return fmt.Errorf("invalid owner: %w", err)
+	}
+	return nil
+}
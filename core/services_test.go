@@ -0,0 +1,177 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/oidc"
+	"github.com/jrapoport/gothic/jwt"
+	"github.com/jrapoport/gothic/models/auditlog"
+	"github.com/jrapoport/gothic/models/client"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testServiceScopes = []string{"read", "write"}
+
+func testServiceClient(t *testing.T, a *API, ownerID uuid.UUID) (*client.Service, string) {
+	s, secret, err := a.CreateServiceClient(rootContext(a), "test",
+		testServiceScopes, ownerID)
+	require.NoError(t, err)
+	return s, secret
+}
+
+func TestAPI_CreateServiceClient(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	u := confirmUser(t, a, testUser(t, a))
+	// not admin
+	_, _, err := a.CreateServiceClient(testContext(a), "test", testServiceScopes, u.ID)
+	assert.Error(t, err)
+	_, _, err = a.CreateServiceClient(nil, "test", testServiceScopes, u.ID)
+	assert.Error(t, err)
+	// bad admin
+	ctx := testContext(a)
+	ctx.SetAdminID(uuid.New())
+	_, _, err = a.CreateServiceClient(ctx, "test", testServiceScopes, u.ID)
+	assert.Error(t, err)
+	ctx = rootContext(a)
+	_, _, err = a.CreateServiceClient(ctx, "", testServiceScopes, u.ID)
+	assert.Error(t, err)
+	_, _, err = a.CreateServiceClient(ctx, "test", []string{`bad"scope`}, u.ID)
+	assert.Error(t, err)
+	// bad owner
+	_, _, err = a.CreateServiceClient(ctx, "test", testServiceScopes, uuid.New())
+	assert.Error(t, err)
+	_, _, err = a.CreateServiceClient(ctx, "test", testServiceScopes, testUser(t, a).ID)
+	assert.Error(t, err)
+	s, secret, err := a.CreateServiceClient(ctx, "test", testServiceScopes, u.ID)
+	require.NoError(t, err)
+	assert.NotEmpty(t, secret)
+	assert.Equal(t, u.ID, s.OwnerID)
+	assert.Equal(t, testServiceScopes, s.ScopeList())
+	assert.NoError(t, s.Authenticate(secret))
+	hasAuditEntry(t, a, auditlog.ServiceCreated, u.ID)
+	// the admin is the default owner
+	s, _, err = a.CreateServiceClient(ctx, "test", nil, uuid.Nil)
+	require.NoError(t, err)
+	assert.Equal(t, user.SuperAdminID, s.OwnerID)
+	assert.Empty(t, s.ScopeList())
+}
+
+func TestAPI_GetServiceClients(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	s, _ := testServiceClient(t, a, uuid.Nil)
+	_, err := a.GetServiceClients(testContext(a))
+	assert.Error(t, err)
+	_, err = a.GetServiceClient(nil, s.ClientID)
+	assert.Error(t, err)
+	ctx := rootContext(a)
+	list, err := a.GetServiceClients(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, s.ClientID, list[0].ClientID)
+	_, err = a.GetServiceClient(ctx, uuid.New().String())
+	assert.Error(t, err)
+	got, err := a.GetServiceClient(ctx, s.ClientID)
+	require.NoError(t, err)
+	assert.Equal(t, s.Name, got.Name)
+}
+
+func TestAPI_DeleteServiceClient(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	u := confirmUser(t, a, testUser(t, a))
+	s, secret := testServiceClient(t, a, u.ID)
+	err := a.DeleteServiceClient(testContext(a), s.ClientID)
+	assert.Error(t, err)
+	err = a.DeleteServiceClient(nil, s.ClientID)
+	assert.Error(t, err)
+	ctx := rootContext(a)
+	err = a.DeleteServiceClient(ctx, uuid.New().String())
+	assert.Error(t, err)
+	err = a.DeleteServiceClient(ctx, s.ClientID)
+	require.NoError(t, err)
+	hasAuditEntry(t, a, auditlog.ServiceDeleted, u.ID)
+	_, err = a.GetServiceClient(ctx, s.ClientID)
+	assert.Error(t, err)
+	req := &oidc.TokenRequest{
+		GrantType:    oidc.GrantClientCredentials,
+		ClientID:     s.ClientID,
+		ClientSecret: secret,
+	}
+	_, err = a.GrantClientCredentials(nil, req)
+	assert.Equal(t, oidc.InvalidClient, oauthErrorCode(t, err))
+}
+
+func TestAPI_GrantClientCredentials(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	u := confirmUser(t, a, testUser(t, a))
+	s, secret := testServiceClient(t, a, u.ID)
+	_, err := a.GrantClientCredentials(nil, nil)
+	assert.Equal(t, oidc.InvalidRequest, oauthErrorCode(t, err))
+	req := &oidc.TokenRequest{
+		GrantType:    oidc.GrantAuthorizationCode,
+		ClientID:     s.ClientID,
+		ClientSecret: secret,
+	}
+	_, err = a.GrantClientCredentials(nil, req)
+	assert.Equal(t, oidc.UnsupportedGrantType, oauthErrorCode(t, err))
+	req.GrantType = oidc.GrantClientCredentials
+	// bad secret
+	req.ClientSecret = "bad"
+	_, err = a.GrantClientCredentials(nil, req)
+	assert.Equal(t, oidc.InvalidClient, oauthErrorCode(t, err))
+	req.ClientSecret = ""
+	_, err = a.GrantClientCredentials(nil, req)
+	assert.Equal(t, oidc.InvalidClient, oauthErrorCode(t, err))
+	// bad client
+	req.ClientID = uuid.New().String()
+	req.ClientSecret = secret
+	_, err = a.GrantClientCredentials(nil, req)
+	assert.Equal(t, oidc.InvalidClient, oauthErrorCode(t, err))
+	req.ClientID = s.ClientID
+	// scope not allowed
+	req.Scope = "read admin"
+	_, err = a.GrantClientCredentials(nil, req)
+	assert.Equal(t, oidc.InvalidScope, oauthErrorCode(t, err))
+	// all allowed scopes
+	req.Scope = ""
+	res, err := a.GrantClientCredentials(nil, req)
+	require.NoError(t, err)
+	assert.Equal(t, oidc.TokenTypeBearer, res.TokenType)
+	assert.Equal(t, "read write", res.Scope)
+	assert.Empty(t, res.IDToken)
+	assert.Equal(t, int(a.config.JWT.Expiration.Seconds()), res.ExpiresIn)
+	hasAuditEntry(t, a, auditlog.ServiceGranted, u.ID)
+	claims, err := jwt.ParseServiceClaims(a.config.JWT, res.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, s.ClientID, claims.ClientID())
+	assert.Equal(t, testServiceScopes, claims.Scope())
+	_, err = jwt.ParseUserClaims(a.config.JWT, res.AccessToken)
+	assert.Error(t, err)
+	// requested scopes
+	req.Scope = "read"
+	res, err = a.GrantClientCredentials(nil, req)
+	require.NoError(t, err)
+	assert.Equal(t, "read", res.Scope)
+	claims, err = jwt.ParseServiceClaims(a.config.JWT, res.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"read"}, claims.Scope())
+	// oauth clients can not use the client credentials grant
+	c, csecret := testClient(t, a, true)
+	creq := &oidc.TokenRequest{
+		GrantType:    oidc.GrantClientCredentials,
+		ClientID:     c.ClientID,
+		ClientSecret: csecret,
+	}
+	_, err = a.GrantClientCredentials(nil, creq)
+	assert.Equal(t, oidc.InvalidClient, oauthErrorCode(t, err))
+	// the owner is no longer active
+	banUser(t, a, u)
+	_, err = a.GrantClientCredentials(nil, req)
+	assert.Equal(t, oidc.InvalidClient, oauthErrorCode(t, err))
+}
@@ -0,0 +1,202 @@
+package core
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/audit"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/core/users"
+	"github.com/jrapoport/gothic/models/rbac"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+)
+
+// GetSessions returns the active sessions for the user (newest first).
+func (a *API) GetSessions(ctx context.Context, userID uuid.UUID) ([]*token.RefreshToken, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var sessions []*token.RefreshToken
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		u, err := users.GetUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		sessions, err = tokens.GetSessions(tx, u.ID)
+		return err
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	return sessions, nil
+}
+
+// RevokeSession revokes a session for the user. Once a session is
+// revoked its refresh token can no longer be used.
+func (a *API) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		u, err := users.GetUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		return a.revokeSession(ctx, tx, u.ID, sessionID)
+	})
+	if err != nil {
+		return a.logError(err)
+	}
+	a.log.Debugf("revoked session %s: %s", sessionID, userID)
+	return nil
+}
+
+// RevokeOtherSessions revokes all the sessions for the user except the current
+// session of the context, and returns the number of sessions revoked.
+func (a *API) RevokeOtherSessions(ctx context.Context, userID uuid.UUID) (int, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if ctx.SessionID() == uuid.Nil {
+		err := errors.New("current session required")
+		return 0, a.logError(err)
+	}
+	var n int
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		u, err := users.GetUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		n, err = a.revokeSessions(ctx, tx, u.ID, ctx.SessionID())
+		return err
+	})
+	if err != nil {
+		return 0, a.logError(err)
+	}
+	a.log.Debugf("revoked %d sessions: %s", n, userID)
+	return n, nil
+}
+
+// GetUserSessions returns the active sessions for a user (newest first).
+// NOTE: This API requires admin user permissions.
+func (a *API) GetUserSessions(ctx context.Context, userID uuid.UUID) ([]*token.RefreshToken, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !ctx.IsAdmin() {
+		err := errors.New("admin user required")
+		return nil, a.logError(err)
+	}
+	var sessions []*token.RefreshToken
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		_, err := a.validateAdmin(tx, ctx.AdminID(), rbac.SessionsRead)
+		if err != nil {
+			return err
+		}
+		u, err := users.GetUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		sessions, err = tokens.GetSessions(tx, u.ID)
+		return err
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	return sessions, nil
+}
+
+// RevokeUserSession revokes a session for a user.
+// NOTE: This API requires admin user permissions.
+func (a *API) RevokeUserSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !ctx.IsAdmin() {
+		err := errors.New("admin user required")
+		return a.logError(err)
+	}
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		u, err := a.revocableUser(tx, ctx.AdminID(), userID)
+		if err != nil {
+			return err
+		}
+		return a.revokeSession(ctx, tx, u.ID, sessionID)
+	})
+	if err != nil {
+		return a.logError(err)
+	}
+	a.log.Debugf("revoked session %s: %s", sessionID, userID)
+	return nil
+}
+
+// RevokeUserSessions revokes all the sessions for a user,
+// and returns the number of sessions revoked.
+// NOTE: This API requires admin user permissions.
+func (a *API) RevokeUserSessions(ctx context.Context, userID uuid.UUID) (int, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !ctx.IsAdmin() {
+		err := errors.New("admin user required")
+		return 0, a.logError(err)
+	}
+	var n int
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		u, err := a.revocableUser(tx, ctx.AdminID(), userID)
+		if err != nil {
+			return err
+		}
+		n, err = a.revokeSessions(ctx, tx, u.ID, uuid.Nil)
+		return err
+	})
+	if err != nil {
+		return 0, a.logError(err)
+	}
+	a.log.Debugf("revoked %d sessions: %s", n, userID)
+	return n, nil
+}
+
+// revocableUser returns the user if the admin is allowed to revoke their sessions & tokens.
+func (a *API) revocableUser(tx *store.Connection, adminID, userID uuid.UUID) (*user.User, error) {
+	role, err := a.validateAdmin(tx, adminID, rbac.SessionsRevoke)
+	if err != nil {
+		return nil, err
+	}
+	u, err := users.GetUser(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if u.IsAdmin() && role != user.RoleSuper {
+		err = errors.New("super admin required to revoke an admin")
+		return nil, err
+	}
+	return u, nil
+}
+
+func (a *API) revokeSession(ctx context.Context, tx *store.Connection, userID, sessionID uuid.UUID) error {
+	rt, err := tokens.GetSession(tx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+	err = tokens.RevokeSession(tx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+	return audit.LogSessionRevoked(ctx, tx, rt)
+}
+
+func (a *API) revokeSessions(ctx context.Context, tx *store.Connection, userID, except uuid.UUID) (int, error) {
+	n, err := tokens.RevokeOtherSessions(tx, userID, except)
+	if err != nil {
+		return 0, err
+	}
+	err = audit.LogSessionsRevoked(ctx, tx, userID, n)
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
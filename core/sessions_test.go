@@ -0,0 +1,219 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/models/auditlog"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func deviceContext(a *API, agent, device string) context.Context {
+	ctx := testContext(a)
+	ctx.SetUserAgent(agent)
+	ctx.SetDeviceName(device)
+	return ctx
+}
+
+func sessionUser(t *testing.T, a *API, n int) (*user.User, []*tokens.BearerToken) {
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	bts := make([]*tokens.BearerToken, n)
+	for i := 0; i < n; i++ {
+		bt, err := a.GrantBearerToken(deviceContext(a, "agent", "device"), u)
+		require.NoError(t, err)
+		bts[i] = bt
+	}
+	return u, bts
+}
+
+func TestAPI_GetSessions(t *testing.T) {
+	t.Parallel()
+	a := loginAPI(t)
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	_, err := a.GetSessions(nil, uuid.New())
+	assert.Error(t, err)
+	sessions, err := a.GetSessions(nil, u.ID)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 0)
+	ctx := deviceContext(a, "Mozilla/5.0", "laptop")
+	bt1, err := a.GrantBearerToken(ctx, u)
+	require.NoError(t, err)
+	bt2, err := a.GrantBearerToken(deviceContext(a, "curl/7.64.1", ""), u)
+	require.NoError(t, err)
+	sessions, err = a.GetSessions(ctx, u.ID)
+	assert.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, bt2.RefreshToken.SessionID, sessions[0].SessionID)
+	assert.Equal(t, "curl/7.64.1", sessions[0].UserAgent)
+	assert.Equal(t, bt1.RefreshToken.SessionID, sessions[1].SessionID)
+	assert.Equal(t, "Mozilla/5.0", sessions[1].UserAgent)
+	assert.Equal(t, "laptop", sessions[1].DeviceName)
+	assert.Equal(t, testIP, sessions[1].IPAddress)
+	// refreshed sessions are updated
+	ctx = deviceContext(a, "Mozilla/6.0", "")
+	bt1, err = a.RefreshBearerToken(ctx, bt1.RefreshToken.String())
+	require.NoError(t, err)
+	sessions, err = a.GetSessions(ctx, u.ID)
+	assert.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, bt1.RefreshToken.SessionID, sessions[1].SessionID)
+	assert.Equal(t, "Mozilla/6.0", sessions[1].UserAgent)
+	assert.Equal(t, "laptop", sessions[1].DeviceName)
+	assert.NotNil(t, sessions[1].UsedAt)
+}
+
+func TestAPI_RevokeSession(t *testing.T) {
+	t.Parallel()
+	a := loginAPI(t)
+	u, bts := sessionUser(t, a, 2)
+	ctx := testContext(a)
+	err := a.RevokeSession(ctx, uuid.New(), bts[0].RefreshToken.SessionID)
+	assert.Error(t, err)
+	err = a.RevokeSession(ctx, u.ID, uuid.Nil)
+	assert.Error(t, err)
+	err = a.RevokeSession(ctx, u.ID, uuid.New())
+	assert.Error(t, err)
+	// another user's session
+	u2, bts2 := sessionUser(t, a, 1)
+	err = a.RevokeSession(ctx, u.ID, bts2[0].RefreshToken.SessionID)
+	assert.Error(t, err)
+	err = a.RevokeSession(nil, u.ID, bts[0].RefreshToken.SessionID)
+	assert.NoError(t, err)
+	hasAuditEntry(t, a, auditlog.SessionRevoked, u.ID)
+	_, err = a.RefreshBearerToken(ctx, bts[0].RefreshToken.String())
+	assert.Error(t, err)
+	_, err = a.RefreshBearerToken(ctx, bts[1].RefreshToken.String())
+	assert.NoError(t, err)
+	_, err = a.RefreshBearerToken(ctx, bts2[0].RefreshToken.String())
+	assert.NoError(t, err)
+	sessions, err := a.GetSessions(ctx, u2.ID)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+	// already revoked
+	err = a.RevokeSession(ctx, u.ID, bts[0].RefreshToken.SessionID)
+	assert.Error(t, err)
+}
+
+func TestAPI_RevokeOtherSessions(t *testing.T) {
+	t.Parallel()
+	a := loginAPI(t)
+	u, bts := sessionUser(t, a, 3)
+	ctx := testContext(a)
+	// no current session
+	_, err := a.RevokeOtherSessions(ctx, u.ID)
+	assert.Error(t, err)
+	_, err = a.RevokeOtherSessions(nil, u.ID)
+	assert.Error(t, err)
+	cur := bts[1].RefreshToken.SessionID
+	ctx.SetSessionID(cur)
+	_, err = a.RevokeOtherSessions(ctx, uuid.New())
+	assert.Error(t, err)
+	n, err := a.RevokeOtherSessions(ctx, u.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	hasAuditEntry(t, a, auditlog.SessionsRevoked, u.ID)
+	sessions, err := a.GetSessions(ctx, u.ID)
+	assert.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, cur, sessions[0].SessionID)
+	n, err = a.RevokeOtherSessions(ctx, u.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestAPI_GetUserSessions(t *testing.T) {
+	t.Parallel()
+	a := loginAPI(t)
+	u, _ := sessionUser(t, a, 2)
+	// not admin
+	_, err := a.GetUserSessions(testContext(a), u.ID)
+	assert.Error(t, err)
+	_, err = a.GetUserSessions(nil, u.ID)
+	assert.Error(t, err)
+	// bad admin
+	ctx := testContext(a)
+	ctx.SetAdminID(u.ID)
+	_, err = a.GetUserSessions(ctx, u.ID)
+	assert.Error(t, err)
+	ctx = rootContext(a)
+	_, err = a.GetUserSessions(ctx, uuid.New())
+	assert.Error(t, err)
+	sessions, err := a.GetUserSessions(ctx, u.ID)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 2)
+}
+
+func TestAPI_RevokeUserSession(t *testing.T) {
+	t.Parallel()
+	a := loginAPI(t)
+	u, bts := sessionUser(t, a, 2)
+	sid := bts[0].RefreshToken.SessionID
+	// not admin
+	err := a.RevokeUserSession(testContext(a), u.ID, sid)
+	assert.Error(t, err)
+	err = a.RevokeUserSession(nil, u.ID, sid)
+	assert.Error(t, err)
+	ctx := rootContext(a)
+	err = a.RevokeUserSession(ctx, uuid.New(), sid)
+	assert.Error(t, err)
+	err = a.RevokeUserSession(ctx, u.ID, uuid.New())
+	assert.Error(t, err)
+	err = a.RevokeUserSession(ctx, u.ID, sid)
+	assert.NoError(t, err)
+	hasAuditEntry(t, a, auditlog.SessionRevoked, u.ID)
+	sessions, err := a.GetUserSessions(ctx, u.ID)
+	assert.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, bts[1].RefreshToken.SessionID, sessions[0].SessionID)
+	// admin users
+	adm, abts := sessionUser(t, a, 1)
+	adm = promoteUser(t, a, adm)
+	u2 := testUser(t, a)
+	u2 = promoteUser(t, a, u2)
+	actx := testContext(a)
+	actx.SetAdminID(u2.ID)
+	err = a.RevokeUserSession(actx, adm.ID, abts[0].RefreshToken.SessionID)
+	assert.Error(t, err)
+	err = a.RevokeUserSession(ctx, adm.ID, abts[0].RefreshToken.SessionID)
+	assert.NoError(t, err)
+}
+
+func TestAPI_RevokeUserSessions(t *testing.T) {
+	t.Parallel()
+	a := loginAPI(t)
+	u, bts := sessionUser(t, a, 3)
+	// not admin
+	_, err := a.RevokeUserSessions(testContext(a), u.ID)
+	assert.Error(t, err)
+	_, err = a.RevokeUserSessions(nil, u.ID)
+	assert.Error(t, err)
+	ctx := rootContext(a)
+	_, err = a.RevokeUserSessions(ctx, uuid.New())
+	assert.Error(t, err)
+	n, err := a.RevokeUserSessions(ctx, u.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	hasAuditEntry(t, a, auditlog.SessionsRevoked, u.ID)
+	for _, bt := range bts {
+		_, err = a.RefreshBearerToken(ctx, bt.RefreshToken.String())
+		assert.Error(t, err)
+	}
+	// admin users
+	adm, _ := sessionUser(t, a, 1)
+	adm = promoteUser(t, a, adm)
+	u2 := testUser(t, a)
+	u2 = promoteUser(t, a, u2)
+	actx := testContext(a)
+	actx.SetAdminID(u2.ID)
+	_, err = a.RevokeUserSessions(actx, adm.ID)
+	assert.Error(t, err)
+	n, err = a.RevokeUserSessions(ctx, adm.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
@@ -9,8 +9,9 @@ import (
 // Settings is the api settings response
 type Settings struct {
 	health.Health
-	Signup `json:"signup"`
-	Mail   `json:"mail"`
+	Signup   `json:"signup"`
+	Mail     `json:"mail"`
+	Password `json:"password"`
 }
 
 // Signup settings
@@ -35,6 +36,21 @@ type Mail struct {
 	Encryption     string `json:"encryption,omitempty"`
 }
 
+// Password settings
+type Password struct {
+	MinLength   int      `json:"min_length,omitempty"`
+	MaxLength   int      `json:"max_length,omitempty"`
+	Lowercase   bool     `json:"lowercase,omitempty"`
+	Uppercase   bool     `json:"uppercase,omitempty"`
+	Digit       bool     `json:"digit,omitempty"`
+	Symbol      bool     `json:"symbol,omitempty"`
+	MinScore    int      `json:"min_score,omitempty"`
+	BannedWords []string `json:"banned_words,omitempty"`
+	Pattern     string   `json:"pattern,omitempty"`
+	History     int      `json:"history,omitempty"`
+	MinAge      string   `json:"min_age,omitempty"`
+}
+
 // Current returns the currently configured settings.
 func Current(c *config.Config) Settings {
 	m := Mail{
@@ -55,6 +71,21 @@ func Current(c *config.Config) Settings {
 	for name := range c.Providers {
 		p.External[name] = true
 	}
+	pw := Password{
+		MinLength:   c.Password.MinLength,
+		MaxLength:   c.Password.MaxLength,
+		Lowercase:   c.Password.Lowercase,
+		Uppercase:   c.Password.Uppercase,
+		Digit:       c.Password.Digit,
+		Symbol:      c.Password.Symbol,
+		MinScore:    c.Password.MinScore,
+		BannedWords: c.Password.BannedWords,
+		Pattern:     c.Validation.PasswordRegex,
+		History:     c.Password.History,
+	}
+	if c.Password.MinAge > 0 {
+		pw.MinAge = c.Password.MinAge.String()
+	}
 	return Settings{
 		health.Check(c),
 		Signup{
@@ -63,5 +94,6 @@ func Current(c *config.Config) Settings {
 			Provider:    p,
 		},
 		m,
+		pw,
 	}
 }
@@ -2,6 +2,7 @@ package settings
 
 import (
 	"testing"
+	"time"
 
 	"github.com/jrapoport/gothic/config"
 	"github.com/jrapoport/gothic/core/health"
@@ -16,6 +17,19 @@ func TestCheck(t *testing.T) {
 	c.Mail.Host = "example.com"
 	c.Mail.Port = 25
 	c.Signup.Disabled = true
+	c.Password = config.Password{
+		MinLength:   10,
+		MaxLength:   100,
+		Lowercase:   true,
+		Uppercase:   true,
+		Digit:       true,
+		Symbol:      true,
+		MinScore:    3,
+		BannedWords: []string{"banned"},
+		History:     5,
+		MinAge:      24 * time.Hour,
+	}
+	c.Validation.PasswordRegex = "^[a-z ]+$"
 	p := config.Provider{
 		ClientKey:   "key",
 		Secret:      "secret",
@@ -36,4 +50,17 @@ func TestCheck(t *testing.T) {
 	assert.True(t, s.Provider.External[provider.GitLab])
 	assert.True(t, s.Provider.External[provider.Heroku])
 	assert.False(t, s.Provider.External[provider.BitBucket])
+	assert.Equal(t, Password{
+		MinLength:   10,
+		MaxLength:   100,
+		Lowercase:   true,
+		Uppercase:   true,
+		Digit:       true,
+		Symbol:      true,
+		MinScore:    3,
+		BannedWords: []string{"banned"},
+		Pattern:     "^[a-z ]+$",
+		History:     5,
+		MinAge:      "24h0m0s",
+	}, s.Password)
 }
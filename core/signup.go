@@ -39,7 +39,7 @@ func (a *API) Signup(ctx context.Context, email, username, pw string, data types
 		err = fmt.Errorf("email: %w", err)
 		return nil, a.logError(err)
 	}
-	err = validate.Password(a.config, pw)
+	err = a.validatePassword(pw, email, username)
 	if err != nil {
 		err = fmt.Errorf("password: %w", err)
 		return nil, a.logError(err)
@@ -96,6 +96,26 @@ func (a *API) externalSignup(ctx context.Context, conn *store.Connection,
 	return u, nil
 }
 
+// validatePassword validates a password against the password policy and
+// the breached passwords. The inputs (e.g. the email and username of the
+// user) are banned from the password.
+func (a *API) validatePassword(pw string, inputs ...string) error {
+	err := validate.Password(a.config, pw, inputs...)
+	var pe *validate.PasswordError
+	if err != nil && !errors.As(err, &pe) {
+		return err
+	}
+	breached := validate.BreachedPassword(a.breached, pw)
+	if breached == nil {
+		return err
+	}
+	if pe == nil {
+		return breached
+	}
+	pe.Violations = append(pe.Violations, validate.PasswordBreached)
+	return pe
+}
+
 func (a *API) validateUsername(conn *store.Connection, username string) (string, error) {
 	var err error
 	if username == "" && a.config.Signup.Default.Username {
@@ -1,11 +1,13 @@
 package core
 
 import (
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/config"
 	"github.com/jrapoport/gothic/core/codes"
 	"github.com/jrapoport/gothic/core/context"
 	"github.com/jrapoport/gothic/core/events"
@@ -28,6 +30,7 @@ func TestAPI_Signup(t *testing.T) {
 	t.Parallel()
 	a := createAPI(t)
 	a.config.Signup.AutoConfirm = false
+	a.config.Password = config.Password{}
 	const (
 		empty       = ""
 		badUsername = "!"
@@ -262,6 +265,37 @@ func TestAPI_Signup_Error(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestAPI_Signup_PasswordPolicy(t *testing.T) {
+	t.Parallel()
+	a := createAPI(t)
+	a.config.Signup.Default.Username = false
+	a.config.Password = config.Password{
+		MinLength: 10,
+		Digit:     true,
+		MinScore:  3,
+	}
+	ctx := testContext(a)
+	email := tutils.RandomEmail()
+	name := strings.Split(email, "@")[0]
+	_, err := a.Signup(ctx, email, "", "password", nil)
+	var pe *validate.PasswordError
+	require.ErrorAs(t, err, &pe)
+	assert.Equal(t, []validate.PasswordViolation{
+		validate.PasswordTooShort,
+		validate.PasswordMissingDigit,
+		validate.PasswordTooWeak,
+	}, pe.Violations)
+	_, err = a.Signup(ctx, email, "", "x9!Q"+name+"#z", nil)
+	require.ErrorAs(t, err, &pe)
+	assert.True(t, pe.Has(validate.PasswordBannedWord))
+	const username = "peter_parker"
+	_, err = a.Signup(ctx, email, username, "x9!QPeter_Parker#z", nil)
+	require.ErrorAs(t, err, &pe)
+	assert.True(t, pe.Has(validate.PasswordBannedWord))
+	_, err = a.Signup(ctx, email, username, "correct horse battery staple 9", nil)
+	assert.NoError(t, err)
+}
+
 func TestAPI_Signup_Disabled(t *testing.T) {
 	t.Parallel()
 	a := createAPI(t)
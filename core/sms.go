@@ -0,0 +1,55 @@
+package core
+
+import (
+	"github.com/jrapoport/gothic/core/audit"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/sms"
+	"github.com/jrapoport/gothic/store"
+)
+
+// OpenSMS opens the sms sender.
+func (a *API) OpenSMS() error {
+	var err error
+	a.sms, err = sms.NewSMSSender(a.config)
+	if err != nil {
+		return a.logError(err)
+	}
+	if a.sms == nil {
+		a.log.Warn("sms is offline")
+		return nil
+	}
+	a.log.Infof("sms open: %s", a.config.SMS.Sender)
+	return nil
+}
+
+// sendPhoneCode sends a phone code for the purpose to the phone number for the user.
+func (a *API) sendPhoneCode(ctx context.Context, tx *store.Connection, u *user.User,
+	purpose token.PhonePurpose, phone string) error {
+	last, err := tokens.GetLastPhoneTokenSent(tx, u.ID, purpose)
+	if err != nil {
+		return err
+	}
+	if last != nil {
+		err = a.config.SMS.CheckSendLimit(last.SentAt)
+		if err != nil {
+			a.log.Warnf("rate limit exceeded for user: %s", u.ID)
+			return err
+		}
+	}
+	pt, err := tokens.GrantPhoneToken(tx, u.ID, purpose, phone, a.config.SMS.Expiration)
+	if err != nil {
+		return err
+	}
+	err = a.sms.Send(pt.Phone, a.config.SMS.FormatMessage(pt.Code))
+	if err != nil {
+		return err
+	}
+	err = tokens.PhoneTokenSent(tx, pt)
+	if err != nil {
+		return err
+	}
+	return audit.LogPhoneCodeSent(ctx, tx, pt)
+}
@@ -0,0 +1,318 @@
+package core
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/core/audit"
+	"github.com/jrapoport/gothic/core/auth"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/core/tenants"
+	"github.com/jrapoport/gothic/models/rbac"
+	"github.com/jrapoport/gothic/models/tenant"
+	"github.com/jrapoport/gothic/store"
+)
+
+// tenancy holds the apis of the tenants of a multi-tenant api.
+type tenancy struct {
+	apis map[uuid.UUID]*API
+	mu   sync.Mutex
+}
+
+func newTenancy() *tenancy {
+	return &tenancy{apis: map[uuid.UUID]*API{}}
+}
+
+// Tenant returns the tenant of the api. If the api is not a tenant api nil
+// is returned.
+func (a *API) Tenant() *tenant.Tenant {
+	return a.tenant
+}
+
+// TenantsEnabled returns true if multi-tenant mode is enabled.
+func (a *API) TenantsEnabled() bool {
+	return a.tenants != nil
+}
+
+// CreateTenant creates a new tenant. If the host is not empty, requests
+// for the host resolve to the tenant.
+// NOTE: This API requires admin permissions.
+func (a *API) CreateTenant(ctx context.Context, name, host string, tc config.TenantConfig) (*tenant.Tenant, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	_, err := a.config.ForTenant(tc)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	var t *tenant.Tenant
+	err = a.conn.Transaction(func(tx *store.Connection) error {
+		err = a.validateTenants(tx, ctx.AdminID(), rbac.TenantsWrite)
+		if err != nil {
+			return err
+		}
+		t, err = tenants.CreateTenant(tx, name, host, tc)
+		if err != nil {
+			return err
+		}
+		return audit.LogTenantCreated(ctx, tx, t)
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	a.log.Debugf("created tenant %s: %s", t.ID, t.Name)
+	return t, nil
+}
+
+// GetTenants returns all the tenants.
+// NOTE: This API requires admin permissions.
+func (a *API) GetTenants(ctx context.Context) ([]*tenant.Tenant, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var list []*tenant.Tenant
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		err := a.validateTenants(tx, ctx.AdminID(), rbac.TenantsRead)
+		if err != nil {
+			return err
+		}
+		list, err = tenants.GetTenants(tx)
+		return err
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	return list, nil
+}
+
+// GetTenant returns the tenant for the id.
+// NOTE: This API requires admin permissions.
+func (a *API) GetTenant(ctx context.Context, tid uuid.UUID) (*tenant.Tenant, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var t *tenant.Tenant
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		err := a.validateTenants(tx, ctx.AdminID(), rbac.TenantsRead)
+		if err != nil {
+			return err
+		}
+		t, err = tenants.GetTenant(tx, tid)
+		return err
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	return t, nil
+}
+
+// UpdateTenant updates the host & configuration of a tenant. If the host
+// is nil it is not changed. If the configuration is nil it is not changed.
+// NOTE: This API requires admin permissions.
+func (a *API) UpdateTenant(ctx context.Context, tid uuid.UUID, host *string, tc *config.TenantConfig) (*tenant.Tenant, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if tc != nil {
+		_, err := a.config.ForTenant(*tc)
+		if err != nil {
+			return nil, a.logError(err)
+		}
+	}
+	var t *tenant.Tenant
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		err := a.validateTenants(tx, ctx.AdminID(), rbac.TenantsWrite)
+		if err != nil {
+			return err
+		}
+		t, err = tenants.UpdateTenant(tx, tid, host, tc)
+		if err != nil {
+			return err
+		}
+		return audit.LogTenantUpdated(ctx, tx, t)
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	a.closeTenantAPI(t.ID)
+	a.log.Debugf("updated tenant %s: %s", t.ID, t.Name)
+	return t, nil
+}
+
+// DeleteTenant deletes a tenant. The data of the tenant is not deleted,
+// but it can no longer be reached.
+// NOTE: This API requires admin permissions.
+func (a *API) DeleteTenant(ctx context.Context, tid uuid.UUID) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		err := a.validateTenants(tx, ctx.AdminID(), rbac.TenantsWrite)
+		if err != nil {
+			return err
+		}
+		t, err := tenants.DeleteTenant(tx, tid)
+		if err != nil {
+			return err
+		}
+		return audit.LogTenantDeleted(ctx, tx, t)
+	})
+	if err != nil {
+		return a.logError(err)
+	}
+	a.closeTenantAPI(tid)
+	a.log.Debugf("deleted tenant %s", tid)
+	return nil
+}
+
+// TenantAPI returns the api for the tenant with the id. Tenant apis
+// share the database, events & denylist of the api, but have their own
+// configuration, mail client & external providers, and their data is
+// isolated by the tenant id.
+func (a *API) TenantAPI(tid uuid.UUID) (*API, error) {
+	err := a.checkTenancy()
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	t, err := tenants.GetTenant(a.conn, tid)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	return a.tenantAPI(t)
+}
+
+// TenantAPIWithName returns the api for the tenant with the name.
+func (a *API) TenantAPIWithName(name string) (*API, error) {
+	err := a.checkTenancy()
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	t, err := tenants.GetTenantWithName(a.conn, name)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	return a.tenantAPI(t)
+}
+
+// TenantAPIWithHost returns the api for the tenant with the host.
+func (a *API) TenantAPIWithHost(host string) (*API, error) {
+	err := a.checkTenancy()
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	t, err := tenants.GetTenantWithHost(a.conn, host)
+	if err != nil {
+		return nil, err
+	}
+	return a.tenantAPI(t)
+}
+
+func (a *API) checkTenancy() error {
+	if a.tenants == nil {
+		return errors.New("tenants disabled")
+	}
+	if a.tenant != nil {
+		return errors.New("tenant api")
+	}
+	return nil
+}
+
+// tenantAPI returns the cached api for the tenant. If the tenant was
+// updated since the api was cached, the api is replaced.
+func (a *API) tenantAPI(t *tenant.Tenant) (*API, error) {
+	a.tenants.mu.Lock()
+	defer a.tenants.mu.Unlock()
+	ta, ok := a.tenants.apis[t.ID]
+	if ok && ta.tenant.UpdatedAt.Equal(t.UpdatedAt) {
+		return ta, nil
+	}
+	if ok {
+		ta.closeTenant()
+		delete(a.tenants.apis, t.ID)
+	}
+	ta, err := a.newTenantAPI(t)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	a.tenants.apis[t.ID] = ta
+	return ta, nil
+}
+
+func (a *API) newTenantAPI(t *tenant.Tenant) (*API, error) {
+	tc, err := t.TenantConfig()
+	if err != nil {
+		return nil, err
+	}
+	c, err := a.config.ForTenant(tc)
+	if err != nil {
+		return nil, err
+	}
+	ta := &API{
+		config:   c,
+		conn:     a.conn.Tenant(t.ID),
+		evt:      a.evt,
+		sms:      a.sms,
+		log:      a.log.WithName("tenant-" + t.Name),
+		breached: a.breached,
+		revoked:  a.revoked,
+		tenant:   t,
+	}
+	err = ta.OpenMail()
+	if err != nil {
+		return nil, err
+	}
+	ta.ext = auth.NewProviders()
+	err = ta.ext.LoadProviders(c)
+	if err != nil {
+		ta.CloseMail()
+		return nil, err
+	}
+	ta.startPasswordReminders()
+	ta.log.Infof("tenant loaded: %s", t.ID)
+	return ta, nil
+}
+
+func (a *API) closeTenantAPI(tid uuid.UUID) {
+	if a.tenants == nil {
+		return
+	}
+	a.tenants.mu.Lock()
+	defer a.tenants.mu.Unlock()
+	ta, ok := a.tenants.apis[tid]
+	if !ok {
+		return
+	}
+	ta.closeTenant()
+	delete(a.tenants.apis, tid)
+}
+
+func (a *API) closeTenants() {
+	if a.tenants == nil {
+		return
+	}
+	a.tenants.mu.Lock()
+	defer a.tenants.mu.Unlock()
+	for tid, ta := range a.tenants.apis {
+		ta.closeTenant()
+		delete(a.tenants.apis, tid)
+	}
+}
+
+func (a *API) closeTenant() {
+	a.stopPasswordReminders()
+	a.CloseMail()
+}
+
+// validateTenants validates that the admin has the permission. Tenants
+// can only be managed if multi-tenant mode is enabled, and tenant apis
+// cannot manage tenants.
+func (a *API) validateTenants(tx *store.Connection, aid uuid.UUID, perm rbac.Permission) error {
+	err := a.checkTenancy()
+	if err != nil {
+		return err
+	}
+	_, err = a.validateAdmin(tx, aid, perm)
+	return err
+}
@@ -0,0 +1,129 @@
+package tenants
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/models/tenant"
+	"github.com/jrapoport/gothic/store"
+)
+
+// CreateTenant creates a new tenant.
+func CreateTenant(conn *store.Connection, name, host string, tc config.TenantConfig) (*tenant.Tenant, error) {
+	t, err := tenant.NewTenant(name, host, tc)
+	if err != nil {
+		return nil, err
+	}
+	if t.Host != "" {
+		has, err := conn.Has(&tenant.Tenant{}, "host = ?", t.Host)
+		if err != nil {
+			return nil, err
+		} else if has {
+			return nil, errors.New("tenant host taken")
+		}
+	}
+	err = conn.Create(t).Error
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// GetTenant returns the tenant for the id.
+func GetTenant(conn *store.Connection, tid uuid.UUID) (*tenant.Tenant, error) {
+	if tid == uuid.Nil {
+		return nil, errors.New("invalid tenant id")
+	}
+	var t tenant.Tenant
+	err := conn.First(&t, "id = ?", tid).Error
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// GetTenantWithName returns the tenant for the name.
+func GetTenantWithName(conn *store.Connection, name string) (*tenant.Tenant, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return nil, errors.New("invalid tenant name")
+	}
+	var t tenant.Tenant
+	err := conn.First(&t, "name = ?", name).Error
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// GetTenantWithHost returns the tenant for the host.
+func GetTenantWithHost(conn *store.Connection, host string) (*tenant.Tenant, error) {
+	host = tenant.NormalizeHost(host)
+	if host == "" {
+		return nil, errors.New("invalid tenant host")
+	}
+	var t tenant.Tenant
+	err := conn.First(&t, "host = ?", host).Error
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// GetTenants returns all the tenants.
+func GetTenants(conn *store.Connection) ([]*tenant.Tenant, error) {
+	var list []*tenant.Tenant
+	err := conn.Order("name").Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// UpdateTenant updates the host & configuration of a tenant. If the host
+// is nil it is not changed. If the configuration is nil it is not changed.
+func UpdateTenant(conn *store.Connection, tid uuid.UUID, host *string, tc *config.TenantConfig) (*tenant.Tenant, error) {
+	t, err := GetTenant(conn, tid)
+	if err != nil {
+		return nil, err
+	}
+	if host != nil {
+		h := tenant.NormalizeHost(*host)
+		if h != "" && h != t.Host {
+			has, err := conn.Has(&tenant.Tenant{}, "host = ?", h)
+			if err != nil {
+				return nil, err
+			} else if has {
+				return nil, errors.New("tenant host taken")
+			}
+		}
+		t.Host = h
+	}
+	if tc != nil {
+		err = t.SetConfig(*tc)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = conn.Save(t).Error
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// DeleteTenant deletes a tenant. The data of the tenant is not deleted,
+// but it can no longer be reached.
+func DeleteTenant(conn *store.Connection, tid uuid.UUID) (*tenant.Tenant, error) {
+	t, err := GetTenant(conn, tid)
+	if err != nil {
+		return nil, err
+	}
+	err = conn.Delete(t).Error
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
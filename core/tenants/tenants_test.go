@@ -0,0 +1,125 @@
+package tenants
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/models/tenant"
+	"github.com/jrapoport/gothic/store"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testTenant = "acme"
+	testHost   = "acme.example.com"
+)
+
+func testCreateTenant(t *testing.T, conn *store.Connection) *tenant.Tenant {
+	tn, err := CreateTenant(conn, testTenant, testHost, config.TenantConfig{})
+	require.NoError(t, err)
+	return tn
+}
+
+func TestCreateTenant(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	_, err := CreateTenant(conn, "", "", config.TenantConfig{})
+	assert.Error(t, err)
+	_, err = CreateTenant(conn, "Not Valid", "", config.TenantConfig{})
+	assert.Error(t, err)
+	tc := config.TenantConfig{SiteURL: "http://acme.example.com"}
+	tn, err := CreateTenant(conn, testTenant, testHost+":8080", tc)
+	require.NoError(t, err)
+	assert.Equal(t, testTenant, tn.Name)
+	assert.Equal(t, testHost, tn.Host)
+	got, err := tn.TenantConfig()
+	require.NoError(t, err)
+	assert.Equal(t, tc, got)
+	// name taken
+	_, err = CreateTenant(conn, testTenant, "", config.TenantConfig{})
+	assert.Error(t, err)
+	// host taken
+	_, err = CreateTenant(conn, "other", testHost, config.TenantConfig{})
+	assert.Error(t, err)
+	_, err = CreateTenant(conn, "other", "", config.TenantConfig{})
+	assert.NoError(t, err)
+}
+
+func TestGetTenant(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	tn := testCreateTenant(t, conn)
+	_, err := GetTenant(conn, uuid.Nil)
+	assert.Error(t, err)
+	_, err = GetTenant(conn, uuid.New())
+	assert.Error(t, err)
+	got, err := GetTenant(conn, tn.ID)
+	require.NoError(t, err)
+	assert.Equal(t, tn.ID, got.ID)
+	_, err = GetTenantWithName(conn, "")
+	assert.Error(t, err)
+	_, err = GetTenantWithName(conn, "missing")
+	assert.Error(t, err)
+	got, err = GetTenantWithName(conn, " ACME ")
+	require.NoError(t, err)
+	assert.Equal(t, tn.ID, got.ID)
+	_, err = GetTenantWithHost(conn, "")
+	assert.Error(t, err)
+	_, err = GetTenantWithHost(conn, "missing.example.com")
+	assert.Error(t, err)
+	got, err = GetTenantWithHost(conn, "ACME.example.com:443")
+	require.NoError(t, err)
+	assert.Equal(t, tn.ID, got.ID)
+	_, err = CreateTenant(conn, "another", "", config.TenantConfig{})
+	require.NoError(t, err)
+	list, err := GetTenants(conn)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, tn.ID, list[0].ID)
+}
+
+func TestUpdateTenant(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	tn := testCreateTenant(t, conn)
+	_, err := UpdateTenant(conn, uuid.New(), nil, nil)
+	assert.Error(t, err)
+	host := "brand.example.com"
+	tc := config.TenantConfig{Name: "brand"}
+	got, err := UpdateTenant(conn, tn.ID, &host, &tc)
+	require.NoError(t, err)
+	assert.Equal(t, host, got.Host)
+	gtc, err := got.TenantConfig()
+	require.NoError(t, err)
+	assert.Equal(t, tc, gtc)
+	got, err = UpdateTenant(conn, tn.ID, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, host, got.Host)
+	other, err := CreateTenant(conn, "other", testHost, config.TenantConfig{})
+	require.NoError(t, err)
+	_, err = UpdateTenant(conn, other.ID, &host, nil)
+	assert.Error(t, err)
+	empty := ""
+	got, err = UpdateTenant(conn, other.ID, &empty, nil)
+	require.NoError(t, err)
+	assert.Empty(t, got.Host)
+}
+
+func TestDeleteTenant(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	tn := testCreateTenant(t, conn)
+	_, err := DeleteTenant(conn, uuid.New())
+	assert.Error(t, err)
+	got, err := DeleteTenant(conn, tn.ID)
+	require.NoError(t, err)
+	assert.Equal(t, tn.ID, got.ID)
+	_, err = GetTenant(conn, tn.ID)
+	assert.Error(t, err)
+	// the name can be reused
+	_, err = CreateTenant(conn, testTenant, testHost, config.TenantConfig{})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,343 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/core/audit"
+	"github.com/jrapoport/gothic/core/clients"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/core/oidc"
+	"github.com/jrapoport/gothic/core/orgs"
+	"github.com/jrapoport/gothic/models/auditlog"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/models/types/provider"
+	"github.com/jrapoport/gothic/store"
+	"github.com/jrapoport/gothic/test/tconf"
+	"github.com/jrapoport/gothic/test/tutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tenantsAPI(t *testing.T) *API {
+	c := tconf.TempDB(t)
+	c.Tenants.Enabled = true
+	a := configuredAPI(t, c)
+	a.config.Signup.Default.Username = false
+	a.config.Signup.Code = false
+	a.config.Signup.Username = false
+	a.config.Recaptcha.Key = ""
+	return a
+}
+
+func hasTenantEntry(t *testing.T, a *API, action auditlog.Action, tid uuid.UUID) {
+	f := store.Filters{
+		key.Action:   action.String(),
+		key.TenantID: tid.String(),
+	}
+	logs, err := audit.SearchEntries(a.conn, store.Ascending, f, nil)
+	require.NoError(t, err)
+	assert.Len(t, logs, 1)
+}
+
+func TestAPI_CreateTenant(t *testing.T) {
+	t.Parallel()
+	a := tenantsAPI(t)
+	ctx := rootContext(a)
+	tc := config.TenantConfig{SiteURL: "http://acme.example.com"}
+	// admin required
+	_, err := a.CreateTenant(testContext(a), "acme", "", tc)
+	assert.Error(t, err)
+	// permission required
+	u := confirmUser(t, a, testUser(t, a))
+	uctx := testContext(a)
+	uctx.SetAdminID(u.ID)
+	_, err = a.CreateTenant(uctx, "acme", "", tc)
+	assert.Error(t, err)
+	// bad config
+	_, err = a.CreateTenant(ctx, "acme", "", config.TenantConfig{SiteURL: "\n"})
+	assert.Error(t, err)
+	_, err = a.CreateTenant(ctx, "Not Valid", "", tc)
+	assert.Error(t, err)
+	tn, err := a.CreateTenant(ctx, "acme", "acme.example.com", tc)
+	require.NoError(t, err)
+	assert.Equal(t, "acme", tn.Name)
+	assert.Equal(t, "acme.example.com", tn.Host)
+	hasTenantEntry(t, a, auditlog.TenantCreated, tn.ID)
+	_, err = a.CreateTenant(ctx, "acme", "", tc)
+	assert.Error(t, err)
+	// tenants disabled
+	d := apiWithTempDB(t)
+	_, err = d.CreateTenant(rootContext(d), "acme", "", tc)
+	assert.Error(t, err)
+	// tenant apis cannot manage tenants
+	ta, err := a.TenantAPI(tn.ID)
+	require.NoError(t, err)
+	_, err = ta.CreateTenant(ctx, "other", "", tc)
+	assert.Error(t, err)
+}
+
+func TestAPI_GetTenants(t *testing.T) {
+	t.Parallel()
+	a := tenantsAPI(t)
+	ctx := rootContext(a)
+	tn, err := a.CreateTenant(ctx, "acme", "", config.TenantConfig{})
+	require.NoError(t, err)
+	_, err = a.CreateTenant(ctx, "brand", "", config.TenantConfig{})
+	require.NoError(t, err)
+	_, err = a.GetTenants(testContext(a))
+	assert.Error(t, err)
+	list, err := a.GetTenants(ctx)
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+	_, err = a.GetTenant(testContext(a), tn.ID)
+	assert.Error(t, err)
+	_, err = a.GetTenant(ctx, uuid.New())
+	assert.Error(t, err)
+	got, err := a.GetTenant(ctx, tn.ID)
+	require.NoError(t, err)
+	assert.Equal(t, tn.Name, got.Name)
+}
+
+func TestAPI_UpdateTenant(t *testing.T) {
+	t.Parallel()
+	a := tenantsAPI(t)
+	ctx := rootContext(a)
+	tn, err := a.CreateTenant(ctx, "acme", "", config.TenantConfig{})
+	require.NoError(t, err)
+	ta, err := a.TenantAPI(tn.ID)
+	require.NoError(t, err)
+	assert.Equal(t, a.config.SiteURL, ta.config.SiteURL)
+	host := "acme.example.com"
+	tc := config.TenantConfig{SiteURL: "http://acme.example.com"}
+	_, err = a.UpdateTenant(testContext(a), tn.ID, &host, &tc)
+	assert.Error(t, err)
+	bad := config.TenantConfig{SiteURL: "\n"}
+	_, err = a.UpdateTenant(ctx, tn.ID, nil, &bad)
+	assert.Error(t, err)
+	_, err = a.UpdateTenant(ctx, uuid.New(), &host, &tc)
+	assert.Error(t, err)
+	got, err := a.UpdateTenant(ctx, tn.ID, &host, &tc)
+	require.NoError(t, err)
+	assert.Equal(t, host, got.Host)
+	hasTenantEntry(t, a, auditlog.TenantUpdated, tn.ID)
+	// the tenant api is reloaded
+	ta2, err := a.TenantAPIWithHost(host)
+	require.NoError(t, err)
+	assert.NotSame(t, ta, ta2)
+	assert.Equal(t, tc.SiteURL, ta2.config.SiteURL)
+	ta3, err := a.TenantAPIWithName("acme")
+	require.NoError(t, err)
+	assert.Same(t, ta2, ta3)
+}
+
+func TestAPI_DeleteTenant(t *testing.T) {
+	t.Parallel()
+	a := tenantsAPI(t)
+	ctx := rootContext(a)
+	tn, err := a.CreateTenant(ctx, "acme", "", config.TenantConfig{})
+	require.NoError(t, err)
+	_, err = a.TenantAPI(tn.ID)
+	require.NoError(t, err)
+	err = a.DeleteTenant(testContext(a), tn.ID)
+	assert.Error(t, err)
+	err = a.DeleteTenant(ctx, uuid.New())
+	assert.Error(t, err)
+	err = a.DeleteTenant(ctx, tn.ID)
+	require.NoError(t, err)
+	hasTenantEntry(t, a, auditlog.TenantDeleted, tn.ID)
+	_, err = a.TenantAPI(tn.ID)
+	assert.Error(t, err)
+}
+
+func TestAPI_TenantAPI(t *testing.T) {
+	t.Parallel()
+	a := tenantsAPI(t)
+	ctx := rootContext(a)
+	assert.True(t, a.TenantsEnabled())
+	assert.Nil(t, a.Tenant())
+	signup := config.Signup{AutoConfirm: true}
+	tc := config.TenantConfig{
+		Name:    "acme",
+		SiteURL: "http://acme.example.com",
+		Signup:  &signup,
+		JWT:     config.TenantJWT{Secret: "acme-secret"},
+	}
+	acme, err := a.CreateTenant(ctx, "acme", "acme.example.com", tc)
+	require.NoError(t, err)
+	brand, err := a.CreateTenant(ctx, "brand", "", config.TenantConfig{})
+	require.NoError(t, err)
+	_, err = a.TenantAPI(uuid.New())
+	assert.Error(t, err)
+	_, err = a.TenantAPIWithName("missing")
+	assert.Error(t, err)
+	_, err = a.TenantAPIWithHost("missing.example.com")
+	assert.Error(t, err)
+	ta, err := a.TenantAPI(acme.ID)
+	require.NoError(t, err)
+	assert.Equal(t, acme.ID, ta.Tenant().ID)
+	assert.Equal(t, tc.SiteURL, ta.config.SiteURL)
+	assert.Equal(t, "acme-secret", ta.config.JWT.Secret)
+	assert.Equal(t, "acme", ta.config.JWT.Issuer)
+	assert.NotEqual(t, a.config.JWT.Secret, ta.config.JWT.Secret)
+	got, err := a.TenantAPIWithHost("ACME.example.com:8080")
+	require.NoError(t, err)
+	assert.Same(t, ta, got)
+	tb, err := a.TenantAPIWithName("brand")
+	require.NoError(t, err)
+	assert.Equal(t, brand.ID, tb.Tenant().ID)
+	_, err = ta.TenantAPI(brand.ID)
+	assert.Error(t, err)
+	// data is isolated by tenant
+	email := tutils.RandomEmail()
+	ua, err := ta.Signup(testContext(ta), email, "", testPass, nil)
+	require.NoError(t, err)
+	assert.Equal(t, acme.ID, ua.TenantID)
+	_, err = ta.Signup(testContext(ta), email, "", testPass, nil)
+	assert.Error(t, err)
+	ub, err := tb.Signup(testContext(tb), email, "", testPass, nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, ua.ID, ub.ID)
+	u, err := a.Signup(testContext(a), email, "", testPass, nil)
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Nil, u.TenantID)
+	got2, err := ta.GetUserWithEmail(email)
+	require.NoError(t, err)
+	assert.Equal(t, ua.ID, got2.ID)
+	_, err = ta.GetUser(ub.ID)
+	assert.Error(t, err)
+	_, err = tb.GetUser(ua.ID)
+	assert.Error(t, err)
+	_, err = a.GetUser(ua.ID)
+	assert.Error(t, err)
+	hasAuditEntry(t, ta, auditlog.Signup, ua.ID)
+	hasAuditEntry(t, tb, auditlog.Signup, ub.ID)
+	logs, err := audit.SearchEntries(tb.conn, store.Ascending, store.Filters{
+		key.UserID: ua.ID.String(),
+	}, nil)
+	require.NoError(t, err)
+	assert.Empty(t, logs)
+	// tenants disabled
+	d := apiWithTempDB(t)
+	assert.False(t, d.TenantsEnabled())
+	_, err = d.TenantAPI(acme.ID)
+	assert.Error(t, err)
+}
+
+func TestAPI_TenantAPI_AuthorizeUser(t *testing.T) {
+	a := tenantsAPI(t)
+	ctx := rootContext(a)
+	tc := config.TenantConfig{Signup: &config.Signup{AutoConfirm: true}}
+	acme, err := a.CreateTenant(ctx, "acme", "", tc)
+	require.NoError(t, err)
+	brand, err := a.CreateTenant(ctx, "brand", "", tc)
+	require.NoError(t, err)
+	ta, err := a.TenantAPI(acme.ID)
+	require.NoError(t, err)
+	tb, err := a.TenantAPI(brand.ID)
+	require.NoError(t, err)
+	_, mock := tconf.MockedProvider(t, ta.config, "")
+	p := provider.Name(mock.Name())
+	tb.config.Authorization.Providers[p] = ta.config.Authorization.Providers[p]
+	ta.ext.UseProviders(mock)
+	tb.ext.UseProviders(mock)
+	// the same external account is linked to a user in each tenant
+	tok, data := authToken(t, ta, p)
+	ua, err := ta.AuthorizeUser(context.Background(), tok, data, testVerifier)
+	require.NoError(t, err)
+	assert.Equal(t, acme.ID, ua.TenantID)
+	tok, data = authToken(t, tb, p)
+	ub, err := tb.AuthorizeUser(context.Background(), tok, data, testVerifier)
+	require.NoError(t, err)
+	assert.Equal(t, brand.ID, ub.TenantID)
+	assert.NotEqual(t, ua.ID, ub.ID)
+	// login
+	tok, data = authToken(t, ta, p)
+	u, err := ta.AuthorizeUser(context.Background(), tok, data, testVerifier)
+	require.NoError(t, err)
+	assert.Equal(t, ua.ID, u.ID)
+	tok, data = authToken(t, tb, p)
+	u, err = tb.AuthorizeUser(context.Background(), tok, data, testVerifier)
+	require.NoError(t, err)
+	assert.Equal(t, ub.ID, u.ID)
+}
+
+func TestAPI_TenantAPI_Isolation(t *testing.T) {
+	t.Parallel()
+	a := tenantsAPI(t)
+	ctx := rootContext(a)
+	acme, err := a.CreateTenant(ctx, "acme", "", config.TenantConfig{})
+	require.NoError(t, err)
+	brand, err := a.CreateTenant(ctx, "brand", "", config.TenantConfig{})
+	require.NoError(t, err)
+	ta, err := a.TenantAPI(acme.ID)
+	require.NoError(t, err)
+	tb, err := a.TenantAPI(brand.ID)
+	require.NoError(t, err)
+	ua := confirmUser(t, ta, testUser(t, ta))
+	ub := confirmUser(t, tb, testUser(t, tb))
+	// roles are unique per tenant
+	ra := testRole(t, ta, "support", testPermissions)
+	assert.Equal(t, acme.ID, ra.TenantID)
+	rb := testRole(t, tb, "support", testPermissions)
+	assert.NotEqual(t, ra.ID, rb.ID)
+	_, err = a.GetRole(rootContext(a), "support")
+	assert.Error(t, err)
+	err = tb.AssignUserRole(rootContext(tb), ua.ID, "support")
+	assert.Error(t, err)
+	err = tb.DeleteRole(rootContext(tb), "support")
+	require.NoError(t, err)
+	r, err := ta.GetRole(rootContext(ta), "support")
+	require.NoError(t, err)
+	assert.Equal(t, ra.ID, r.ID)
+	// orgs
+	oa, err := ta.CreateOrg(testContext(ta), ua.ID, "acme", nil)
+	require.NoError(t, err)
+	assert.Equal(t, acme.ID, oa.TenantID)
+	_, err = orgs.GetOrg(tb.conn, oa.ID)
+	assert.Error(t, err)
+	_, err = tb.GetOrg(testContext(tb), ub.ID, oa.ID)
+	assert.Error(t, err)
+	_, err = ta.GetOrg(testContext(ta), ua.ID, oa.ID)
+	assert.NoError(t, err)
+	// clients
+	ca, _ := testClient(t, ta, true)
+	assert.Equal(t, acme.ID, ca.TenantID)
+	_, err = tb.GetClient(rootContext(tb), ca.ClientID)
+	assert.Error(t, err)
+	list, err := tb.GetClients(rootContext(tb))
+	require.NoError(t, err)
+	assert.Empty(t, list)
+	_, err = tb.Authorize(testContext(tb), ub.ID, testAuthorizationRequest(ca), true)
+	assert.Error(t, err)
+	// consents
+	_ = authorizeCode(t, ta, ua, testAuthorizationRequest(ca))
+	consents, err := ta.GetConsents(nil, ua.ID)
+	require.NoError(t, err)
+	require.Len(t, consents, 1)
+	assert.Equal(t, acme.ID, consents[0].TenantID)
+	_, err = tb.GetConsents(nil, ua.ID)
+	assert.Error(t, err)
+	consents, err = clients.GetConsents(tb.conn, ua.ID)
+	require.NoError(t, err)
+	assert.Empty(t, consents)
+	err = tb.RevokeConsent(nil, ua.ID, ca.ClientID)
+	assert.Error(t, err)
+	// service clients
+	sa, secret := testServiceClient(t, ta, ua.ID)
+	assert.Equal(t, acme.ID, sa.TenantID)
+	_, err = tb.GetServiceClient(rootContext(tb), sa.ClientID)
+	assert.Error(t, err)
+	req := &oidc.TokenRequest{
+		GrantType:    oidc.GrantClientCredentials,
+		ClientID:     sa.ClientID,
+		ClientSecret: secret,
+	}
+	_, err = tb.GrantClientCredentials(nil, req)
+	assert.Error(t, err)
+	_, err = ta.GrantClientCredentials(nil, req)
+	assert.NoError(t, err)
+	_, _, err = tb.CreateServiceClient(rootContext(tb), "test", testServiceScopes, ua.ID)
+	assert.Error(t, err)
+}
@@ -9,22 +9,28 @@ import (
 	"github.com/jrapoport/gothic/config"
 	"github.com/jrapoport/gothic/core/audit"
 	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/core/events"
 	"github.com/jrapoport/gothic/core/tokens"
 	"github.com/jrapoport/gothic/core/users"
 	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/models/types/key"
 	"github.com/jrapoport/gothic/models/user"
 	"github.com/jrapoport/gothic/store"
 )
 
-// GrantBearerToken issues a bearer token for the user.
+// GrantBearerToken issues a bearer token for the user for a new session.
 func (a *API) GrantBearerToken(ctx context.Context, u *user.User) (*tokens.BearerToken, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
 	if u == nil || (!u.IsActive() && !u.IsRestricted()) {
 		err := errors.New("invalid user")
 		return nil, a.logError(err)
 	}
 	var bt *tokens.BearerToken
 	err := a.conn.Transaction(func(tx *store.Connection) (err error) {
-		bt, err = tokens.GrantBearerToken(a.conn, a.config.JWT, u)
+		bt, err = tokens.GrantBearerToken(a.conn, a.config.JWT, a.config.Refresh, u, newSession(ctx))
 		if err != nil {
 			return err
 		}
@@ -36,30 +42,124 @@ func (a *API) GrantBearerToken(ctx context.Context, u *user.User) (*tokens.Beare
 	return bt, nil
 }
 
-// RefreshBearerToken refreshes the bearer token for the user.
+// RefreshBearerToken refreshes the bearer token for the user. If the refresh
+// token was already swapped for a new token, the reuse is treated as a theft
+// and every refresh token for its session is revoked, unless the token is used
+// again within the reuse grace period.
 func (a *API) RefreshBearerToken(ctx context.Context, refreshToken string) (*tokens.BearerToken, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
 	var bt *tokens.BearerToken
 	err := a.conn.Transaction(func(tx *store.Connection) error {
-		rt, err := tokens.GetUsableRefreshToken(tx, refreshToken)
+		rt, err := tokens.GetRefreshToken(tx, refreshToken)
 		if err != nil {
 			return err
 		}
+		if rt.Swapped() {
+			if !a.config.Refresh.InGrace(rt.DeletedAt.Time) {
+				return tokens.ErrRefreshTokenReused
+			}
+		} else if !rt.Usable() {
+			return errors.New("invalid token")
+		}
 		u, err := users.GetUser(tx, rt.UserID)
 		if err != nil {
 			return err
 		}
-		bt, err = tokens.RefreshBearerToken(tx, a.config.JWT, u, rt.String())
+		// the password must be changed before the user can be issued new tokens
+		if !u.Provider.IsExternal() && u.NeedsPasswordChange(a.config.Password.MaxAge) {
+			return errors.New("password change required")
+		}
+		bt, err = tokens.RefreshBearerToken(tx, a.config.JWT, a.config.Refresh,
+			u, rt.String(), newSession(ctx))
 		if err != nil {
 			return err
 		}
 		return audit.LogTokenRefreshed(ctx, tx, bt)
 	})
+	if errors.Is(err, tokens.ErrRefreshTokenReused) {
+		err = a.revokeReusedToken(ctx, refreshToken)
+		if err != nil {
+			return nil, a.logError(err)
+		}
+		return nil, a.logError(tokens.ErrRefreshTokenReused)
+	}
 	if err != nil {
 		return nil, a.logError(err)
 	}
 	return bt, nil
 }
 
+// revokeReusedToken revokes the refresh token family (session) of a reused
+// refresh token, and the bearer tokens that were issued for the session.
+func (a *API) revokeReusedToken(ctx context.Context, refreshToken string) error {
+	rt, err := tokens.GetRefreshToken(a.conn, refreshToken)
+	if err != nil {
+		return err
+	}
+	a.log.Warnf("refresh token reused: %s %s", rt.UserID, rt.SessionID)
+	var revoked *token.RevokedToken
+	err = a.conn.Transaction(func(tx *store.Connection) error {
+		err = tokens.RevokeRefreshTokenFamily(tx, rt)
+		if err != nil {
+			return err
+		}
+		if rt.SessionID == uuid.Nil {
+			revoked, err = a.revokeTokens(tx, rt.UserID)
+		} else {
+			revoked, err = tokens.RevokeSessionTokens(tx, a.config.JWT,
+				rt.UserID, rt.SessionID, time.Now())
+		}
+		if err != nil {
+			return err
+		}
+		return audit.LogTokenReused(ctx, tx, rt)
+	})
+	if err != nil {
+		return err
+	}
+	a.revoked.Add(revoked)
+	a.dispatchEvent(events.TokenReused, types.Map{
+		key.Provider:  ctx.Provider(),
+		key.IPAddress: ctx.IPAddress(),
+		key.UserID:    rt.UserID,
+		key.SessionID: rt.SessionID,
+		key.Timestamp: time.Now().UTC(),
+	})
+	if !a.config.Refresh.ReuseAlert {
+		return nil
+	}
+	err = a.sendReuseAlert(ctx, rt.UserID)
+	if errors.Is(err, config.ErrRateLimitExceeded) {
+		return nil
+	}
+	return err
+}
+
+// sendReuseAlert mails the user a reset password link after the reuse of a refresh token.
+func (a *API) sendReuseAlert(ctx context.Context, userID uuid.UUID) error {
+	u, err := users.GetUser(a.conn, userID)
+	if err != nil {
+		return err
+	}
+	if u.Provider.IsExternal() {
+		return nil
+	}
+	return a.sendConfirmToken(ctx, u.ID,
+		func(u *user.User) (bool, error) {
+			return !u.IsLocked(), nil
+		},
+		func(to string, ct *token.ConfirmToken) error {
+			referrerURL := a.config.Mail.TokenReused.ReferralURL
+			return a.mail.SendTokenReused(to, ct.String(), referrerURL)
+		})
+}
+
+func newSession(ctx context.Context) token.Session {
+	return token.NewSession(ctx.UserAgent(), ctx.IPAddress(), ctx.DeviceName())
+}
+
 type (
 	checkSenderFunc func(u *user.User) (bool, error)
 	sendConfirmFunc func(to string, ct *token.ConfirmToken) error
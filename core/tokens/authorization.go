@@ -0,0 +1,30 @@
+package tokens
+
+import (
+	"errors"
+
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+)
+
+// GrantAuthorizationCode creates a new authorization code.
+func GrantAuthorizationCode(conn *store.Connection, ac *token.AuthorizationCode) error {
+	if ac == nil || ac.UserID == user.SystemID {
+		return errors.New("invalid authorization code")
+	}
+	if ac.ClientID == "" {
+		return errors.New("invalid client id")
+	}
+	return conn.Create(ac).Error
+}
+
+// GetAuthorizationCode returns the authorization code for the code string if found.
+func GetAuthorizationCode(conn *store.Connection, code string) (*token.AuthorizationCode, error) {
+	var ac token.AuthorizationCode
+	err := conn.First(&ac, "token = ?", code).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ac, nil
+}
@@ -0,0 +1,42 @@
+package tokens
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRedirect = "https://example.com/callback"
+
+func TestGrantAuthorizationCode(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	cid := uuid.New().String()
+	err := GrantAuthorizationCode(conn, nil)
+	assert.Error(t, err)
+	ac := token.NewAuthorizationCode(user.SystemID, cid, testRedirect, "openid", time.Minute)
+	err = GrantAuthorizationCode(conn, ac)
+	assert.Error(t, err)
+	ac = token.NewAuthorizationCode(uid, "", testRedirect, "openid", time.Minute)
+	err = GrantAuthorizationCode(conn, ac)
+	assert.Error(t, err)
+	ac = token.NewAuthorizationCode(uid, cid, testRedirect, "openid", time.Minute)
+	err = GrantAuthorizationCode(conn, ac)
+	require.NoError(t, err)
+	assert.True(t, ac.Usable())
+	_, err = GetAuthorizationCode(conn, "")
+	assert.Error(t, err)
+	got, err := GetAuthorizationCode(conn, ac.Token)
+	require.NoError(t, err)
+	assert.Equal(t, uid, got.UserID)
+	assert.Equal(t, cid, got.ClientID)
+	assert.Equal(t, testRedirect, got.RedirectURI)
+	assert.Equal(t, "openid", got.Scope)
+}
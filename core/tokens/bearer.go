@@ -3,10 +3,14 @@ package tokens
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/core/roles"
 	"github.com/jrapoport/gothic/jwt"
+	"github.com/jrapoport/gothic/models/org"
+	"github.com/jrapoport/gothic/models/rbac"
 	"github.com/jrapoport/gothic/models/token"
 	"github.com/jrapoport/gothic/models/user"
 	"github.com/jrapoport/gothic/store"
@@ -50,13 +54,14 @@ func (t BearerToken) Class() token.Class {
 	return Bearer
 }
 
-// GrantBearerToken grants a new bearer token
-func GrantBearerToken(conn *store.Connection, c config.JWT, u *user.User) (*BearerToken, error) {
-	return RefreshBearerToken(conn, c, u, "")
+// GrantBearerToken grants a new bearer token for a new session
+func GrantBearerToken(conn *store.Connection, c config.JWT, r config.Refresh, u *user.User, s token.Session) (*BearerToken, error) {
+	return RefreshBearerToken(conn, c, r, u, "", s)
 }
 
-// RefreshBearerToken refreshes a bearer token
-func RefreshBearerToken(conn *store.Connection, c config.JWT, u *user.User, tok string) (*BearerToken, error) {
+// RefreshBearerToken refreshes a bearer token. The session
+// of the refresh token is updated with the session.
+func RefreshBearerToken(conn *store.Connection, c config.JWT, r config.Refresh, u *user.User, tok string, s token.Session) (*BearerToken, error) {
 	if u == nil {
 		return nil, errors.New("invalid user")
 	}
@@ -66,19 +71,29 @@ func RefreshBearerToken(conn *store.Connection, c config.JWT, u *user.User, tok
 	}
 	var bt *BearerToken
 	err := conn.Transaction(func(tx *store.Connection) (err error) {
-		t := jwt.NewUserToken(c, u)
-		bt, err = NewBearerToken(t)
+		var rt *token.RefreshToken
+		if tok == "" {
+			rt, err = GrantRefreshToken(tx, r, u.ID, s)
+		} else {
+			rt, err = SwapRefreshToken(tx, r, u.ID, tok, s)
+		}
 		if err != nil {
 			return err
 		}
-		if tok == "" {
-			bt.RefreshToken, err = GrantRefreshToken(tx, bt.UserID)
-		} else {
-			bt.RefreshToken, err = SwapRefreshToken(tx, bt.UserID, tok)
+		perms, err := userPermissions(tx, u)
+		if err != nil {
+			return err
 		}
+		claims := jwt.NewSessionClaims(u, rt.SessionID, perms)
+		err = sessionOrg(tx, claims, rt)
 		if err != nil {
 			return err
 		}
+		bt, err = NewBearerToken(jwt.NewToken(c, claims))
+		if err != nil {
+			return err
+		}
+		bt.RefreshToken = rt
 		if bt.UserID != bt.RefreshToken.UserID {
 			return errors.New("mismatched user id")
 		}
@@ -89,3 +104,55 @@ func RefreshBearerToken(conn *store.Connection, c config.JWT, u *user.User, tok
 	}
 	return bt, nil
 }
+
+// GrantImpersonationToken grants a bearer token for an admin to act as the
+// user. The token does not have a session, so it cannot be refreshed, and it
+// does not carry the permissions of the user.
+func GrantImpersonationToken(c config.JWT, exp time.Duration, u *user.User, adminID uuid.UUID) (*BearerToken, error) {
+	if u == nil {
+		return nil, errors.New("invalid user")
+	}
+	if !u.IsActive() && !u.IsRestricted() {
+		err := fmt.Errorf("inactive user: %s", u.ID)
+		return nil, err
+	}
+	if adminID == uuid.Nil {
+		return nil, errors.New("invalid admin")
+	}
+	claims := jwt.NewUserClaims(u)
+	if claims == nil {
+		return nil, errors.New("invalid user")
+	}
+	claims.SetActor(adminID)
+	c.Expiration = exp
+	return NewBearerToken(jwt.NewToken(c, claims))
+}
+
+// userPermissions returns the permissions to embed in the claims of the user.
+// Admins have all the permissions, so theirs are not embedded.
+func userPermissions(conn *store.Connection, u *user.User) ([]string, error) {
+	if u.IsAdmin() {
+		return nil, nil
+	}
+	perms, err := roles.GetPermissions(conn, u)
+	if err != nil {
+		return nil, err
+	}
+	return rbac.Strings(perms), nil
+}
+
+// sessionOrg sets the active organization of the session and the role of
+// the user in the organization on the claims. If the user is no longer a
+// member of the organization, the claims do not have an active organization.
+func sessionOrg(conn *store.Connection, claims *jwt.UserClaims, rt *token.RefreshToken) error {
+	if claims == nil || rt.OrgID == nil {
+		return nil
+	}
+	var m org.Member
+	has, err := conn.Has(&m, "org_id = ? AND user_id = ?", *rt.OrgID, rt.UserID)
+	if err != nil || !has {
+		return err
+	}
+	claims.SetOrg(m.OrgID, m.Role.String())
+	return nil
+}
@@ -3,8 +3,14 @@ package tokens
 import (
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
+
+	"github.com/jrapoport/gothic/core/roles"
 	"github.com/jrapoport/gothic/jwt"
+	"github.com/jrapoport/gothic/models/rbac"
+	"github.com/jrapoport/gothic/models/token"
 	"github.com/jrapoport/gothic/models/user"
 	"github.com/jrapoport/gothic/test/tconf"
 	"github.com/jrapoport/gothic/test/tconn"
@@ -25,12 +31,12 @@ func TestGrantBearerToken(t *testing.T) {
 	t.Parallel()
 	conn, c := tconn.TempConn(t)
 	// system user
-	_, err := GrantBearerToken(conn, c.JWT, nil)
+	_, err := GrantBearerToken(conn, c.JWT, c.Refresh, nil, token.Session{})
 	assert.Error(t, err)
-	_, err = GrantBearerToken(conn, c.JWT, new(user.User))
+	_, err = GrantBearerToken(conn, c.JWT, c.Refresh, new(user.User), token.Session{})
 	assert.Error(t, err)
 	u := testUser(t, conn, c)
-	bt, err := GrantBearerToken(conn, c.JWT, u)
+	bt, err := GrantBearerToken(conn, c.JWT, c.Refresh, u, token.Session{})
 	assert.NoError(t, err)
 	require.NotNil(t, bt)
 	assert.True(t, bt.Usable())
@@ -39,20 +45,60 @@ func TestGrantBearerToken(t *testing.T) {
 	require.NotNil(t, bt.RefreshToken)
 	assert.NotEmpty(t, bt.RefreshToken.String())
 	assert.Equal(t, u.ID, bt.RefreshToken.UserID)
+	claims, err := jwt.ParseUserClaims(c.JWT, bt.String())
+	require.NoError(t, err)
+	assert.Equal(t, bt.RefreshToken.SessionID, claims.SessionID())
+	assert.Empty(t, claims.Permissions())
+	// the permissions of the user's roles are embedded
+	_, err = roles.CreateRole(conn, "support", "", []rbac.Permission{rbac.UsersRead})
+	require.NoError(t, err)
+	_, err = roles.AssignRole(conn, u.ID, "support")
+	require.NoError(t, err)
+	bt, err = GrantBearerToken(conn, c.JWT, c.Refresh, u, token.Session{})
+	require.NoError(t, err)
+	claims, err = jwt.ParseUserClaims(c.JWT, bt.String())
+	require.NoError(t, err)
+	assert.Equal(t, []string{rbac.UsersRead.String()}, claims.Permissions())
 	conn.Error = errors.New("force error")
-	_, err = GrantBearerToken(conn, c.JWT, u)
+	_, err = GrantBearerToken(conn, c.JWT, c.Refresh, u, token.Session{})
+	assert.Error(t, err)
+}
+
+func TestGrantImpersonationToken(t *testing.T) {
+	t.Parallel()
+	conn, c := tconn.TempConn(t)
+	aid := uuid.New()
+	const exp = 5 * time.Minute
+	_, err := GrantImpersonationToken(c.JWT, exp, nil, aid)
+	assert.Error(t, err)
+	_, err = GrantImpersonationToken(c.JWT, exp, new(user.User), aid)
 	assert.Error(t, err)
+	u := testUser(t, conn, c)
+	_, err = GrantImpersonationToken(c.JWT, exp, u, uuid.Nil)
+	assert.Error(t, err)
+	bt, err := GrantImpersonationToken(c.JWT, exp, u, aid)
+	require.NoError(t, err)
+	assert.Equal(t, u.ID, bt.UserID)
+	assert.Nil(t, bt.RefreshToken)
+	require.NotNil(t, bt.ExpiredAt)
+	assert.WithinDuration(t, time.Now().Add(exp), *bt.ExpiredAt, time.Minute)
+	claims, err := jwt.ParseUserClaims(c.JWT, bt.String())
+	require.NoError(t, err)
+	assert.Equal(t, u.ID, claims.UserID())
+	assert.Equal(t, aid, claims.ActorID())
+	assert.Equal(t, uuid.Nil, claims.SessionID())
+	assert.False(t, claims.Privileged())
 }
 
 func TestRefreshBearerToken(t *testing.T) {
 	t.Parallel()
 	conn, c := tconn.TempConn(t)
 	u := testUser(t, conn, c)
-	bt, err := GrantBearerToken(conn, c.JWT, u)
+	bt, err := GrantBearerToken(conn, c.JWT, c.Refresh, u, token.Session{})
 	assert.NoError(t, err)
 	require.NotNil(t, bt)
 	assert.True(t, bt.Usable())
-	bt, err = RefreshBearerToken(conn, c.JWT, u, bt.RefreshToken.String())
+	bt, err = RefreshBearerToken(conn, c.JWT, c.Refresh, u, bt.RefreshToken.String(), token.Session{})
 	assert.NoError(t, err)
 	require.NotNil(t, bt)
 	assert.True(t, bt.Usable())
@@ -63,9 +109,9 @@ func TestRefreshBearerToken(t *testing.T) {
 	assert.Equal(t, u.ID, bt.RefreshToken.UserID)
 	// mismatched user
 	u = testUser(t, conn, c)
-	_, err = RefreshBearerToken(conn, c.JWT, u, bt.RefreshToken.String())
+	_, err = RefreshBearerToken(conn, c.JWT, c.Refresh, u, bt.RefreshToken.String(), token.Session{})
 	assert.Error(t, err)
 	conn.Error = errors.New("force error")
-	_, err = RefreshBearerToken(conn, c.JWT, u, bt.RefreshToken.String())
+	_, err = RefreshBearerToken(conn, c.JWT, c.Refresh, u, bt.RefreshToken.String(), token.Session{})
 	assert.Error(t, err)
 }
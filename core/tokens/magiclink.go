@@ -0,0 +1,37 @@
+package tokens
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/store"
+)
+
+// GrantMagicLinkToken gets or creates a magic link token for the provided user.
+func GrantMagicLinkToken(conn *store.Connection, userID uuid.UUID, exp time.Duration) (*token.MagicLinkToken, error) {
+	t, err := grantToken(conn, userID, func() token.Token {
+		return token.NewMagicLinkToken(userID, exp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t.(*token.MagicLinkToken), nil
+}
+
+// GetMagicLinkToken returns the magic link token for the token string if found.
+func GetMagicLinkToken(conn *store.Connection, tok string) (*token.MagicLinkToken, error) {
+	var mt token.MagicLinkToken
+	err := conn.First(&mt, "token = ?", tok).Error
+	if err != nil {
+		return nil, err
+	}
+	return &mt, nil
+}
+
+// MagicLinkTokenSent marks a magic link token as sent.
+func MagicLinkTokenSent(conn *store.Connection, mt *token.MagicLinkToken) error {
+	now := time.Now().UTC()
+	mt.SentAt = &now
+	return conn.Model(mt).Update("sent_at", mt.SentAt).Error
+}
@@ -0,0 +1,52 @@
+package tokens
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrantMagicLinkToken(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	mt, err := GrantMagicLinkToken(conn, uid, token.NoExpiration)
+	assert.NoError(t, err)
+	require.NotNil(t, mt)
+	assert.NotEmpty(t, mt.AccessToken)
+	assert.Equal(t, uid, mt.UserID)
+	// system user id
+	_, err = GrantMagicLinkToken(conn, user.SystemID, token.NoExpiration)
+	assert.Error(t, err)
+}
+
+func TestGetMagicLinkToken(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	test, err := GrantMagicLinkToken(conn, uid, token.NoExpiration)
+	assert.NoError(t, err)
+	assert.NotNil(t, test)
+	mt, err := GetMagicLinkToken(conn, test.String())
+	assert.NoError(t, err)
+	assert.Equal(t, test.UserID, mt.UserID)
+	assert.Equal(t, test.Token, mt.Token)
+	_, err = GetMagicLinkToken(conn, "")
+	assert.Error(t, err)
+}
+
+func TestMagicLinkTokenSent(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	mt, err := GrantMagicLinkToken(conn, uid, token.NoExpiration)
+	assert.NoError(t, err)
+	err = MagicLinkTokenSent(conn, mt)
+	assert.NoError(t, err)
+	assert.NotNil(t, mt.SentAt)
+}
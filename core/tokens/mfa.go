@@ -0,0 +1,48 @@
+package tokens
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/store"
+)
+
+// GrantMFAToken gets or creates an mfa challenge token for the provided user.
+func GrantMFAToken(conn *store.Connection, userID uuid.UUID, exp time.Duration) (*token.MFAToken, error) {
+	t, err := grantToken(conn, userID, func() token.Token {
+		return token.NewMFAToken(userID, exp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t.(*token.MFAToken), nil
+}
+
+// CountMFATokens returns the number of mfa challenge tokens
+// granted to the user since the time, including used tokens.
+func CountMFATokens(conn *store.Connection, userID uuid.UUID, since time.Time) (int64, error) {
+	var count int64
+	err := conn.Unscoped().Model(&token.MFAToken{}).
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetMFAToken returns the mfa challenge token for the token string if found.
+func GetMFAToken(conn *store.Connection, tok string) (*token.MFAToken, error) {
+	var mt token.MFAToken
+	err := conn.First(&mt, "token = ?", tok).Error
+	if err != nil {
+		return nil, err
+	}
+	return &mt, nil
+}
+
+// RevokeMFAToken revokes an mfa challenge token.
+func RevokeMFAToken(conn *store.Connection, mt *token.MFAToken) error {
+	return conn.Delete(mt).Error
+}
@@ -0,0 +1,54 @@
+package tokens
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrantMFAToken(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	mt, err := GrantMFAToken(conn, uid, time.Minute)
+	assert.NoError(t, err)
+	require.NotNil(t, mt)
+	assert.NotEmpty(t, mt.AccessToken)
+	assert.Equal(t, uid, mt.UserID)
+	assert.True(t, mt.Usable())
+	// system user id
+	_, err = GrantMFAToken(conn, user.SystemID, time.Minute)
+	assert.Error(t, err)
+}
+
+func TestGetMFAToken(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	test, err := GrantMFAToken(conn, uid, time.Minute)
+	assert.NoError(t, err)
+	assert.NotNil(t, test)
+	mt, err := GetMFAToken(conn, test.String())
+	assert.NoError(t, err)
+	assert.Equal(t, test.UserID, mt.UserID)
+	assert.Equal(t, test.Token, mt.Token)
+	_, err = GetMFAToken(conn, "")
+	assert.Error(t, err)
+}
+
+func TestRevokeMFAToken(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	mt, err := GrantMFAToken(conn, uid, time.Minute)
+	require.NoError(t, err)
+	err = RevokeMFAToken(conn, mt)
+	assert.NoError(t, err)
+	_, err = GetMFAToken(conn, mt.String())
+	assert.Error(t, err)
+}
@@ -0,0 +1,60 @@
+package tokens
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+)
+
+// GrantOAuthToken creates a new access token for the client.
+func GrantOAuthToken(conn *store.Connection, userID uuid.UUID, clientID, scope string, exp time.Duration) (*token.OAuthToken, error) {
+	if userID == user.SystemID {
+		return nil, errors.New("system user")
+	}
+	if clientID == "" {
+		return nil, errors.New("invalid client id")
+	}
+	ot := token.NewOAuthToken(userID, clientID, scope, exp)
+	err := conn.Create(ot).Error
+	if err != nil {
+		return nil, err
+	}
+	return ot, nil
+}
+
+// GetOAuthToken returns the oauth token for the token string if found.
+func GetOAuthToken(conn *store.Connection, tok string) (*token.OAuthToken, error) {
+	var ot token.OAuthToken
+	err := conn.First(&ot, "token = ?", tok).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ot, nil
+}
+
+// RevokeOAuthTokens revokes the authorization codes and access tokens
+// issued to the client for the user. If the user id is nil, the codes
+// and tokens issued to the client for all users are revoked.
+func RevokeOAuthTokens(conn *store.Connection, userID uuid.UUID, clientID string) error {
+	return conn.Transaction(func(tx *store.Connection) error {
+		for _, t := range []token.Token{
+			&token.AuthorizationCode{},
+			&token.OAuthToken{},
+		} {
+			q := tx.Unscoped().Where(key.ClientID+" = ?", clientID)
+			if userID != uuid.Nil {
+				q = q.Where(key.UserID+" = ?", userID)
+			}
+			err := q.Delete(t).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,69 @@
+package tokens
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrantOAuthToken(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	cid := uuid.New().String()
+	_, err := GrantOAuthToken(conn, user.SystemID, cid, "openid", time.Hour)
+	assert.Error(t, err)
+	_, err = GrantOAuthToken(conn, uid, "", "openid", time.Hour)
+	assert.Error(t, err)
+	ot, err := GrantOAuthToken(conn, uid, cid, "openid email", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, ot.Usable())
+	_, err = GetOAuthToken(conn, "")
+	assert.Error(t, err)
+	got, err := GetOAuthToken(conn, ot.Token)
+	require.NoError(t, err)
+	assert.Equal(t, uid, got.UserID)
+	assert.Equal(t, cid, got.ClientID)
+	assert.Equal(t, "openid email", got.Scope)
+}
+
+func TestRevokeOAuthTokens(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid1 := uuid.New()
+	uid2 := uuid.New()
+	cid := uuid.New().String()
+	grant := func(uid uuid.UUID) (*token.AuthorizationCode, *token.OAuthToken) {
+		ac := token.NewAuthorizationCode(uid, cid, testRedirect, "openid", time.Minute)
+		err := GrantAuthorizationCode(conn, ac)
+		require.NoError(t, err)
+		ot, err := GrantOAuthToken(conn, uid, cid, "openid", time.Hour)
+		require.NoError(t, err)
+		return ac, ot
+	}
+	ac1, ot1 := grant(uid1)
+	ac2, ot2 := grant(uid2)
+	err := RevokeOAuthTokens(conn, uid1, cid)
+	require.NoError(t, err)
+	_, err = GetAuthorizationCode(conn, ac1.Token)
+	assert.Error(t, err)
+	_, err = GetOAuthToken(conn, ot1.Token)
+	assert.Error(t, err)
+	_, err = GetAuthorizationCode(conn, ac2.Token)
+	assert.NoError(t, err)
+	_, err = GetOAuthToken(conn, ot2.Token)
+	assert.NoError(t, err)
+	// all users
+	err = RevokeOAuthTokens(conn, uuid.Nil, cid)
+	require.NoError(t, err)
+	_, err = GetAuthorizationCode(conn, ac2.Token)
+	assert.Error(t, err)
+	_, err = GetOAuthToken(conn, ot2.Token)
+	assert.Error(t, err)
+}
@@ -0,0 +1,35 @@
+package tokens
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/store"
+)
+
+// GrantPasswordChangeToken gets or creates a password change token for the provided user.
+func GrantPasswordChangeToken(conn *store.Connection, userID uuid.UUID, exp time.Duration) (*token.PasswordChangeToken, error) {
+	t, err := grantToken(conn, userID, func() token.Token {
+		return token.NewPasswordChangeToken(userID, exp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t.(*token.PasswordChangeToken), nil
+}
+
+// GetPasswordChangeToken returns the password change token for the token string if found.
+func GetPasswordChangeToken(conn *store.Connection, tok string) (*token.PasswordChangeToken, error) {
+	var pt token.PasswordChangeToken
+	err := conn.First(&pt, "token = ?", tok).Error
+	if err != nil {
+		return nil, err
+	}
+	return &pt, nil
+}
+
+// RevokePasswordChangeToken revokes a password change token.
+func RevokePasswordChangeToken(conn *store.Connection, pt *token.PasswordChangeToken) error {
+	return conn.Delete(pt).Error
+}
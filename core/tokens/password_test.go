@@ -0,0 +1,54 @@
+package tokens
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrantPasswordChangeToken(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	pt, err := GrantPasswordChangeToken(conn, uid, time.Minute)
+	assert.NoError(t, err)
+	require.NotNil(t, pt)
+	assert.NotEmpty(t, pt.AccessToken)
+	assert.Equal(t, uid, pt.UserID)
+	assert.True(t, pt.Usable())
+	// system user id
+	_, err = GrantPasswordChangeToken(conn, user.SystemID, time.Minute)
+	assert.Error(t, err)
+}
+
+func TestGetPasswordChangeToken(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	test, err := GrantPasswordChangeToken(conn, uid, time.Minute)
+	assert.NoError(t, err)
+	assert.NotNil(t, test)
+	pt, err := GetPasswordChangeToken(conn, test.String())
+	assert.NoError(t, err)
+	assert.Equal(t, test.UserID, pt.UserID)
+	assert.Equal(t, test.Token, pt.Token)
+	_, err = GetPasswordChangeToken(conn, "")
+	assert.Error(t, err)
+}
+
+func TestRevokePasswordChangeToken(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	pt, err := GrantPasswordChangeToken(conn, uid, time.Minute)
+	require.NoError(t, err)
+	err = RevokePasswordChangeToken(conn, pt)
+	assert.NoError(t, err)
+	_, err = GetPasswordChangeToken(conn, pt.String())
+	assert.Error(t, err)
+}
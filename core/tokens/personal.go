@@ -0,0 +1,90 @@
+package tokens
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+)
+
+// GrantPersonalToken creates a new personal access token for the user.
+// The token is only set on the returned personal token.
+func GrantPersonalToken(conn *store.Connection, userID uuid.UUID, name, scope string, exp time.Duration) (*token.PersonalToken, error) {
+	if userID == user.SystemID || userID == uuid.Nil {
+		return nil, errors.New("invalid user id")
+	}
+	if name == "" {
+		return nil, errors.New("invalid token name")
+	}
+	pt := token.NewPersonalToken(userID, name, scope, exp)
+	err := conn.Create(pt).Error
+	if err != nil {
+		return nil, err
+	}
+	return pt, nil
+}
+
+// GetPersonalToken returns the usable personal token for the token string if found.
+func GetPersonalToken(conn *store.Connection, tok string) (*token.PersonalToken, error) {
+	if !token.IsPersonalToken(tok) {
+		return nil, errors.New("invalid token")
+	}
+	var pt token.PersonalToken
+	err := conn.First(&pt, "token = ?", token.HashPersonalToken(tok)).Error
+	if err != nil {
+		return nil, err
+	}
+	if !pt.Usable() {
+		return nil, errors.New("invalid token")
+	}
+	return &pt, nil
+}
+
+// GetPersonalTokens returns the usable personal tokens for a user id (newest first).
+func GetPersonalTokens(conn *store.Connection, userID uuid.UUID) ([]*token.PersonalToken, error) {
+	var list []*token.PersonalToken
+	err := conn.
+		Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").
+		Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	usable := list[:0]
+	for _, pt := range list {
+		if pt.Usable() {
+			usable = append(usable, pt)
+		}
+	}
+	return usable, nil
+}
+
+// UsePersonalToken records the use of the personal token from the ip address.
+func UsePersonalToken(conn *store.Connection, pt *token.PersonalToken, ip string) error {
+	if !pt.Usable() {
+		return errors.New("invalid token")
+	}
+	pt.UseFrom(ip)
+	return conn.Model(pt).Select("used", "used_at", "ip_address").Updates(pt).Error
+}
+
+// RevokePersonalToken revokes (deletes) the personal token for a user id with the
+// token id, and returns the revoked personal token.
+func RevokePersonalToken(conn *store.Connection, userID, tokenID uuid.UUID) (*token.PersonalToken, error) {
+	if tokenID == uuid.Nil {
+		return nil, errors.New("invalid token id")
+	}
+	var pt token.PersonalToken
+	err := conn.First(&pt, "user_id = ? AND token_id = ?", userID, tokenID).Error
+	if err != nil {
+		return nil, err
+	}
+	err = conn.Unscoped().Delete(&pt).Error
+	if err != nil {
+		return nil, err
+	}
+	return &pt, nil
+}
@@ -0,0 +1,106 @@
+package tokens
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrantPersonalToken(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	_, err := GrantPersonalToken(conn, user.SystemID, "test", token.ScopeRead, 0)
+	assert.Error(t, err)
+	_, err = GrantPersonalToken(conn, uid, "", token.ScopeRead, 0)
+	assert.Error(t, err)
+	pt, err := GrantPersonalToken(conn, uid, "test", token.ScopeRead, 0)
+	require.NoError(t, err)
+	assert.True(t, pt.Usable())
+	assert.NotEmpty(t, pt.Secret)
+	_, err = GetPersonalToken(conn, "")
+	assert.Error(t, err)
+	_, err = GetPersonalToken(conn, pt.Token)
+	assert.Error(t, err)
+	_, err = GetPersonalToken(conn, token.PersonalTokenPrefix+"bad")
+	assert.Error(t, err)
+	got, err := GetPersonalToken(conn, pt.Secret)
+	require.NoError(t, err)
+	assert.Equal(t, pt.TokenID, got.TokenID)
+	assert.Equal(t, uid, got.UserID)
+	assert.Equal(t, token.ScopeRead, got.Scope)
+	assert.Empty(t, got.Secret)
+}
+
+func TestGetPersonalToken_Expired(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	pt, err := GrantPersonalToken(conn, uuid.New(), "test", token.ScopeRead, time.Hour)
+	require.NoError(t, err)
+	past := time.Now().UTC().Add(-time.Minute)
+	err = conn.Model(pt).Update("expired_at", past).Error
+	require.NoError(t, err)
+	_, err = GetPersonalToken(conn, pt.Secret)
+	assert.Error(t, err)
+	list, err := GetPersonalTokens(conn, pt.UserID)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestUsePersonalToken(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	pt, err := GrantPersonalToken(conn, uuid.New(), "test", token.ScopeRead, 0)
+	require.NoError(t, err)
+	const ip = "127.0.0.1"
+	err = UsePersonalToken(conn, pt, ip)
+	require.NoError(t, err)
+	got, err := GetPersonalToken(conn, pt.Secret)
+	require.NoError(t, err)
+	assert.Equal(t, 1, got.Used)
+	assert.Equal(t, ip, got.IPAddress)
+	assert.False(t, got.LastUsed().IsZero())
+	err = UsePersonalToken(conn, &token.PersonalToken{}, ip)
+	assert.Error(t, err)
+}
+
+func TestGetPersonalTokens(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	const count = 3
+	for i := 0; i < count; i++ {
+		_, err := GrantPersonalToken(conn, uid, "test", token.ScopeRead, 0)
+		require.NoError(t, err)
+	}
+	_, err := GrantPersonalToken(conn, uuid.New(), "other", token.ScopeRead, 0)
+	require.NoError(t, err)
+	list, err := GetPersonalTokens(conn, uid)
+	require.NoError(t, err)
+	assert.Len(t, list, count)
+}
+
+func TestRevokePersonalToken(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	pt, err := GrantPersonalToken(conn, uid, "test", token.ScopeRead, 0)
+	require.NoError(t, err)
+	_, err = RevokePersonalToken(conn, uid, uuid.Nil)
+	assert.Error(t, err)
+	_, err = RevokePersonalToken(conn, uuid.New(), pt.TokenID)
+	assert.Error(t, err)
+	rt, err := RevokePersonalToken(conn, uid, pt.TokenID)
+	require.NoError(t, err)
+	assert.Equal(t, pt.TokenID, rt.TokenID)
+	_, err = GetPersonalToken(conn, pt.Secret)
+	assert.Error(t, err)
+	_, err = RevokePersonalToken(conn, uid, pt.TokenID)
+	assert.Error(t, err)
+}
@@ -0,0 +1,107 @@
+package tokens
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/store"
+)
+
+// ErrInvalidCode is returned when a phone code is invalid.
+var ErrInvalidCode = errors.New("invalid code")
+
+// GrantPhoneToken gets or creates a phone token for the provided user, purpose
+// and phone number. If the phone number changed the token is reissued.
+func GrantPhoneToken(conn *store.Connection, userID uuid.UUID, purpose token.PhonePurpose, phone string, exp time.Duration) (*token.PhoneToken, error) {
+	issueToken := func() token.Token {
+		return token.NewPhoneToken(userID, purpose, phone, exp)
+	}
+	grant := func(tx *store.Connection) (*token.PhoneToken, error) {
+		t, err := grantTokenWhere(tx, userID, issueToken,
+			"user_id = ? AND purpose = ?", userID, purpose)
+		if err != nil {
+			return nil, err
+		}
+		return t.(*token.PhoneToken), nil
+	}
+	var pt *token.PhoneToken
+	err := conn.Transaction(func(tx *store.Connection) (err error) {
+		pt, err = grant(tx)
+		if err != nil {
+			return err
+		}
+		if pt.Phone == phone {
+			return nil
+		}
+		err = tx.Delete(pt).Error
+		if err != nil {
+			return err
+		}
+		pt, err = grant(tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pt, nil
+}
+
+// GetPhoneToken returns the phone token for the user and purpose if found.
+func GetPhoneToken(conn *store.Connection, userID uuid.UUID, purpose token.PhonePurpose) (*token.PhoneToken, error) {
+	var pt token.PhoneToken
+	err := conn.First(&pt, "user_id = ? AND purpose = ?", userID, purpose).Error
+	if err != nil {
+		return nil, err
+	}
+	return &pt, nil
+}
+
+// GetLastPhoneTokenSent returns the last phone token that was sent to the user
+// for the purpose. Tokens that were used up or revoked are included, so that
+// reissuing a token does not reset the send limit.
+func GetLastPhoneTokenSent(conn *store.Connection, userID uuid.UUID, purpose token.PhonePurpose) (*token.PhoneToken, error) {
+	pt := new(token.PhoneToken)
+	has, err := store.HasLast(conn.Unscoped(), pt,
+		"user_id = ? AND purpose = ? AND sent_at NOT NULL", userID, purpose)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, nil
+	}
+	return pt, nil
+}
+
+// VerifyPhoneToken verifies the code for the phone token of the user. Failed
+// attempts count against the token, so it is used before the code is checked.
+func VerifyPhoneToken(conn *store.Connection, userID uuid.UUID, purpose token.PhonePurpose, phone, code string) (*token.PhoneToken, error) {
+	pt, err := GetPhoneToken(conn, userID, purpose)
+	if err != nil {
+		return nil, err
+	}
+	if pt.Phone != phone {
+		return nil, errors.New("invalid phone")
+	}
+	err = UseToken(conn, pt)
+	if err != nil {
+		return nil, err
+	}
+	if !pt.Verify(code) {
+		return nil, ErrInvalidCode
+	}
+	return pt, nil
+}
+
+// PhoneTokenSent marks a phone token as sent.
+func PhoneTokenSent(conn *store.Connection, pt *token.PhoneToken) error {
+	now := time.Now().UTC()
+	pt.SentAt = &now
+	return conn.Model(pt).Update("sent_at", pt.SentAt).Error
+}
+
+// RevokePhoneToken revokes a phone token.
+func RevokePhoneToken(conn *store.Connection, pt *token.PhoneToken) error {
+	return conn.Delete(pt).Error
+}
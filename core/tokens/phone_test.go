@@ -0,0 +1,162 @@
+package tokens
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testPhone  = "+15555550100"
+	otherPhone = "+15555550199"
+)
+
+func TestGrantPhoneToken(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	pt, err := GrantPhoneToken(conn, uid, token.PhoneChange, testPhone, time.Minute)
+	assert.NoError(t, err)
+	require.NotNil(t, pt)
+	assert.NotEmpty(t, pt.AccessToken)
+	assert.NotEmpty(t, pt.Code)
+	assert.Equal(t, uid, pt.UserID)
+	assert.Equal(t, testPhone, pt.Phone)
+	assert.True(t, pt.Usable())
+	// same phone
+	pt2, err := GrantPhoneToken(conn, uid, token.PhoneChange, testPhone, time.Minute)
+	assert.NoError(t, err)
+	require.NotNil(t, pt2)
+	assert.Equal(t, pt.Token, pt2.Token)
+	assert.Equal(t, pt.Code, pt2.Code)
+	// new phone
+	pt3, err := GrantPhoneToken(conn, uid, token.PhoneChange, otherPhone, time.Minute)
+	assert.NoError(t, err)
+	require.NotNil(t, pt3)
+	assert.NotEqual(t, pt.Token, pt3.Token)
+	assert.Equal(t, otherPhone, pt3.Phone)
+	// login tokens are separate
+	pt4, err := GrantPhoneToken(conn, uid, token.PhoneLogin, otherPhone, time.Minute)
+	assert.NoError(t, err)
+	require.NotNil(t, pt4)
+	assert.NotEqual(t, pt3.Token, pt4.Token)
+	assert.Equal(t, token.PhoneLogin, pt4.Purpose)
+	pt5, err := GetPhoneToken(conn, uid, token.PhoneChange)
+	assert.NoError(t, err)
+	assert.Equal(t, pt3.Token, pt5.Token)
+	// system user id
+	_, err = GrantPhoneToken(conn, user.SystemID, token.PhoneChange, testPhone, time.Minute)
+	assert.Error(t, err)
+}
+
+func TestGetPhoneToken(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	test, err := GrantPhoneToken(conn, uid, token.PhoneChange, testPhone, time.Minute)
+	assert.NoError(t, err)
+	assert.NotNil(t, test)
+	pt, err := GetPhoneToken(conn, uid, token.PhoneChange)
+	assert.NoError(t, err)
+	assert.Equal(t, test.UserID, pt.UserID)
+	assert.Equal(t, test.Token, pt.Token)
+	assert.Equal(t, test.Code, pt.Code)
+	_, err = GetPhoneToken(conn, uuid.New(), token.PhoneChange)
+	assert.Error(t, err)
+}
+
+func TestVerifyPhoneToken(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	_, err := VerifyPhoneToken(conn, uid, token.PhoneChange, testPhone, "")
+	assert.Error(t, err)
+	pt, err := GrantPhoneToken(conn, uid, token.PhoneChange, testPhone, time.Minute)
+	require.NoError(t, err)
+	// wrong phone
+	_, err = VerifyPhoneToken(conn, uid, token.PhoneChange, otherPhone, pt.Code)
+	assert.Error(t, err)
+	// wrong code
+	_, err = VerifyPhoneToken(conn, uid, token.PhoneChange, testPhone, "bad")
+	assert.ErrorIs(t, err, ErrInvalidCode)
+	pt2, err := VerifyPhoneToken(conn, uid, token.PhoneChange, testPhone, pt.Code)
+	assert.NoError(t, err)
+	require.NotNil(t, pt2)
+	assert.Equal(t, pt.Token, pt2.Token)
+	assert.Equal(t, 2, pt2.Used)
+	// attempts are limited
+	for i := pt2.Used; i < token.PhoneAttempts; i++ {
+		_, err = VerifyPhoneToken(conn, uid, token.PhoneChange, testPhone, "bad")
+		assert.Error(t, err)
+	}
+	_, err = VerifyPhoneToken(conn, uid, token.PhoneChange, testPhone, pt.Code)
+	assert.Error(t, err)
+}
+
+func TestPhoneTokenSent(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	pt, err := GrantPhoneToken(conn, uid, token.PhoneChange, testPhone, time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, pt.SentAt)
+	err = PhoneTokenSent(conn, pt)
+	assert.NoError(t, err)
+	pt, err = GetPhoneToken(conn, uid, token.PhoneChange)
+	require.NoError(t, err)
+	assert.NotNil(t, pt.SentAt)
+}
+
+func TestGetLastPhoneTokenSent(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	pt, err := GetLastPhoneTokenSent(conn, uid, token.PhoneLogin)
+	assert.NoError(t, err)
+	assert.Nil(t, pt)
+	pt, err = GrantPhoneToken(conn, uid, token.PhoneLogin, testPhone, time.Minute)
+	require.NoError(t, err)
+	pt2, err := GetLastPhoneTokenSent(conn, uid, token.PhoneLogin)
+	assert.NoError(t, err)
+	assert.Nil(t, pt2)
+	err = PhoneTokenSent(conn, pt)
+	require.NoError(t, err)
+	// the last send is kept when the token is used up
+	for i := 0; i < token.PhoneAttempts; i++ {
+		_, err = VerifyPhoneToken(conn, uid, token.PhoneLogin, testPhone, "bad")
+		assert.Error(t, err)
+	}
+	_, err = GetPhoneToken(conn, uid, token.PhoneLogin)
+	assert.Error(t, err)
+	pt3, err := GrantPhoneToken(conn, uid, token.PhoneLogin, testPhone, time.Minute)
+	require.NoError(t, err)
+	assert.NotEqual(t, pt.Token, pt3.Token)
+	assert.Nil(t, pt3.SentAt)
+	pt2, err = GetLastPhoneTokenSent(conn, uid, token.PhoneLogin)
+	assert.NoError(t, err)
+	require.NotNil(t, pt2)
+	assert.Equal(t, pt.Token, pt2.Token)
+	assert.NotNil(t, pt2.SentAt)
+	// purposes are separate
+	pt2, err = GetLastPhoneTokenSent(conn, uid, token.PhoneChange)
+	assert.NoError(t, err)
+	assert.Nil(t, pt2)
+}
+
+func TestRevokePhoneToken(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	pt, err := GrantPhoneToken(conn, uid, token.PhoneChange, testPhone, time.Minute)
+	require.NoError(t, err)
+	err = RevokePhoneToken(conn, pt)
+	assert.NoError(t, err)
+	_, err = GetPhoneToken(conn, uid, token.PhoneChange)
+	assert.Error(t, err)
+}
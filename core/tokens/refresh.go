@@ -2,46 +2,125 @@ package tokens
 
 import (
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/config"
 	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/user"
 	"github.com/jrapoport/gothic/store"
+	"gorm.io/gorm"
 )
 
-// GrantRefreshToken gets or creates a refresh token for the provided user.
-func GrantRefreshToken(conn *store.Connection, userID uuid.UUID) (*token.RefreshToken, error) {
-	rt, err := grantToken(conn, userID, func() token.Token {
-		return token.NewRefreshToken(userID)
-	})
+// ErrRefreshTokenReused is returned when a refresh token that has already been swapped is used.
+var ErrRefreshTokenReused = errors.New("refresh token reused")
+
+// GrantRefreshToken creates a refresh token for a new session for the provided user.
+func GrantRefreshToken(conn *store.Connection, c config.Refresh, userID uuid.UUID, s token.Session) (*token.RefreshToken, error) {
+	if userID == user.SystemID {
+		return nil, errors.New("system user")
+	}
+	s.SessionID = uuid.Nil
+	rt := token.NewSessionRefreshToken(userID, s)
+	rt.ExpiredAt = c.ExpiresAt(rt.SignedInAt)
+	err := conn.Create(rt).Error
 	if err != nil {
 		return nil, err
 	}
-	return rt.(*token.RefreshToken), nil
+	return rt, nil
 }
 
 // SwapRefreshToken swaps a refresh token for a new one, revoking the previous token.
-func SwapRefreshToken(conn *store.Connection, userID uuid.UUID, tok string) (*token.RefreshToken, error) {
-	var rt token.Token
+// The session of the previous token is carried over to the new token and updated.
+// The previous token is soft deleted so that it can be detected if it is reused.
+// A token that is used again within the reuse grace period of being swapped,
+// e.g. by a client retrying a refresh, is swapped again instead of treated as
+// reused. If the session has an org id it becomes the active organization of
+// the session, and uuid.Nil clears the active organization.
+func SwapRefreshToken(conn *store.Connection, c config.Refresh, userID uuid.UUID, tok string, s token.Session) (*token.RefreshToken, error) {
+	var rt *token.RefreshToken
 	err := conn.Transaction(func(tx *store.Connection) (err error) {
-		rt, err = GetUsableRefreshToken(tx, tok)
+		rt, err = GetRefreshToken(tx, tok)
 		if err != nil {
 			return err
 		}
-		// soft delete
-		err = tx.Delete(rt).Error
-		if err != nil {
-			return err
+		if rt.Swapped() {
+			if !c.InGrace(rt.DeletedAt.Time) {
+				return ErrRefreshTokenReused
+			}
+		} else {
+			if !rt.Usable() {
+				return errors.New("invalid token")
+			}
+			// soft delete
+			err = tx.Delete(rt).Error
+			if err != nil {
+				return err
+			}
 		}
 		if rt.IssuedTo() != userID {
 			return nil
 		}
-		rt, err = GrantRefreshToken(tx, rt.IssuedTo())
+		err = deleteSwappedTokens(tx.Where("user_id = ?", userID), c)
+		if err != nil {
+			return err
+		}
+		rt, err = swapSession(tx, c, rt, s)
 		return err
 	})
 	if err != nil {
 		return nil, err
 	}
-	return rt.(*token.RefreshToken), nil
+	return rt, nil
+}
+
+func swapSession(conn *store.Connection, c config.Refresh, prev *token.RefreshToken, s token.Session) (*token.RefreshToken, error) {
+	session := prev.Session
+	if s.UserAgent != "" {
+		session.UserAgent = s.UserAgent
+	}
+	if s.IPAddress != "" {
+		session.IPAddress = s.IPAddress
+	}
+	if s.DeviceName != "" {
+		session.DeviceName = s.DeviceName
+	}
+	if s.OrgID != nil {
+		session.OrgID = s.OrgID
+		if *s.OrgID == uuid.Nil {
+			session.OrgID = nil
+		}
+	}
+	rt := token.NewSessionRefreshToken(prev.UserID, session)
+	now := time.Now().UTC()
+	rt.UsedAt = &now
+	rt.ExpiredAt = c.ExpiresAt(rt.SignedInAt)
+	if rt.ExpiredAt != nil && !rt.ExpiredAt.After(now) {
+		return nil, errors.New("session expired")
+	}
+	err := conn.Create(rt).Error
+	if err != nil {
+		return nil, err
+	}
+	return rt, nil
+}
+
+// DeleteSwappedRefreshTokens deletes the swapped refresh tokens of the sessions
+// that have expired, since their reuse can no longer be detected. If sessions
+// do not expire, swapped tokens are deleted once they are idle.
+func DeleteSwappedRefreshTokens(conn *store.Connection, c config.Refresh) error {
+	return deleteSwappedTokens(conn.DB, c)
+}
+
+func deleteSwappedTokens(db *gorm.DB, c config.Refresh) error {
+	now := time.Now().UTC()
+	db = db.Unscoped().Where("deleted_at IS NOT NULL")
+	if c.Expiration > 0 {
+		db = db.Where("signed_in_at <= ?", now.Add(-c.Expiration))
+	} else {
+		db = db.Where("expired_at IS NOT NULL AND expired_at <= ?", now)
+	}
+	return db.Delete(&token.RefreshToken{}).Error
 }
 
 // RevokeAllRefreshTokens revokes (deletes) all refresh tokens for a user id.
@@ -51,6 +130,18 @@ func RevokeAllRefreshTokens(conn *store.Connection, userID uuid.UUID) error {
 	return revokeAll(conn, rt, userID)
 }
 
+// RevokeRefreshTokenFamily revokes (deletes) all the refresh tokens
+// for the session of a refresh token, including swapped tokens.
+func RevokeRefreshTokenFamily(conn *store.Connection, rt *token.RefreshToken) error {
+	if rt == nil {
+		return errors.New("invalid token")
+	}
+	if rt.SessionID == uuid.Nil {
+		return RevokeAllRefreshTokens(conn, rt.UserID)
+	}
+	return RevokeSession(conn, rt.UserID, rt.SessionID)
+}
+
 // HasUsableRefreshToken returns true if a usable refresh token is found.
 func HasUsableRefreshToken(conn *store.Connection, userID uuid.UUID) (bool, error) {
 	var rt token.RefreshToken
@@ -64,6 +155,20 @@ func HasUsableRefreshToken(conn *store.Connection, userID uuid.UUID) (bool, erro
 	return rt.Usable(), nil
 }
 
+// GetRefreshToken returns the refresh token for the token string if one exists,
+// including tokens that have been swapped.
+func GetRefreshToken(conn *store.Connection, tok string) (*token.RefreshToken, error) {
+	if tok == "" {
+		return nil, errors.New("invalid token")
+	}
+	rt := new(token.RefreshToken)
+	err := conn.Unscoped().First(rt, "token = ?", tok).Error
+	if err != nil {
+		return nil, err
+	}
+	return rt, nil
+}
+
 // GetUsableRefreshToken returns a usable refresh token for the token string if one exists.
 func GetUsableRefreshToken(conn *store.Connection, tok string) (*token.RefreshToken, error) {
 	rt := new(token.RefreshToken)
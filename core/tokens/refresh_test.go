@@ -2,8 +2,10 @@ package tokens
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/config"
 	"github.com/jrapoport/gothic/models/token"
 	"github.com/jrapoport/gothic/models/user"
 	"github.com/jrapoport/gothic/test/tconn"
@@ -15,20 +17,28 @@ func TestGrantRefreshToken(t *testing.T) {
 	t.Parallel()
 	conn, _ := tconn.TempConn(t)
 	uid := uuid.New()
+	s := token.NewSession("Mozilla/5.0", "127.0.0.1", "laptop")
 	testGrant := func(userID uuid.UUID) *token.RefreshToken {
-		rt, err := GrantRefreshToken(conn, userID)
+		rt, err := GrantRefreshToken(conn, config.Refresh{}, userID, s)
 		assert.NoError(t, err)
 		require.NotNil(t, rt)
 		assert.NotEmpty(t, rt.AccessToken)
 		assert.Equal(t, uid, rt.UserID)
+		assert.NotEqual(t, uuid.Nil, rt.SessionID)
+		assert.False(t, rt.SignedInAt.IsZero())
+		assert.Equal(t, s.UserAgent, rt.UserAgent)
+		assert.Equal(t, s.IPAddress, rt.IPAddress)
+		assert.Equal(t, s.DeviceName, rt.DeviceName)
 		return rt
 	}
+	// each grant is a new session
 	rt1 := testGrant(uid)
 	rt2 := testGrant(uid)
-	assert.Equal(t, rt1.ID, rt2.ID)
-	assert.Equal(t, rt1.Token, rt2.Token)
+	assert.NotEqual(t, rt1.ID, rt2.ID)
+	assert.NotEqual(t, rt1.Token, rt2.Token)
+	assert.NotEqual(t, rt1.SessionID, rt2.SessionID)
 	// system user id
-	_, err := GrantRefreshToken(conn, user.SystemID)
+	_, err := GrantRefreshToken(conn, config.Refresh{}, user.SystemID, s)
 	assert.Error(t, err)
 }
 
@@ -36,28 +46,190 @@ func TestSwapRefreshToken(t *testing.T) {
 	t.Parallel()
 	conn, _ := tconn.TempConn(t)
 	uid := uuid.New()
-	_, err := SwapRefreshToken(conn, uid, "")
+	_, err := SwapRefreshToken(conn, config.Refresh{}, uid, "", token.Session{})
 	assert.Error(t, err)
-	rt, err := GrantRefreshToken(conn, uid)
+	rt, err := GrantRefreshToken(conn, config.Refresh{}, uid, token.NewSession("agent", "127.0.0.1", "laptop"))
 	assert.NoError(t, err)
 	assert.False(t, rt.DeletedAt.Valid)
-	st, err := SwapRefreshToken(conn, uid, rt.String())
+	assert.True(t, rt.LastActive().Equal(rt.CreatedAt))
+	st, err := SwapRefreshToken(conn, config.Refresh{}, uid, rt.String(), token.NewSession("new agent", "", ""))
 	assert.NoError(t, err)
 	assert.Equal(t, uid, st.UserID)
 	_, err = GetUsableRefreshToken(conn, rt.Token)
 	assert.Error(t, err)
 	assert.NotEqual(t, rt.ID, st.ID)
 	assert.Equal(t, rt.UserID, st.UserID)
+	// the session is carried over
+	assert.Equal(t, rt.SessionID, st.SessionID)
+	assert.True(t, rt.SignedInAt.Equal(st.SignedInAt))
+	assert.Equal(t, "new agent", st.UserAgent)
+	assert.Equal(t, rt.IPAddress, st.IPAddress)
+	assert.Equal(t, rt.DeviceName, st.DeviceName)
+	assert.NotNil(t, st.UsedAt)
+	assert.True(t, st.LastActive().Equal(*st.UsedAt))
+	// the swapped token is reused
+	_, err = SwapRefreshToken(conn, config.Refresh{}, uid, rt.String(), token.Session{})
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+	// expired
+	err = conn.Model(st).Update("expired_at", time.Now().UTC().Add(-time.Minute)).Error
+	require.NoError(t, err)
+	_, err = SwapRefreshToken(conn, config.Refresh{}, uid, st.String(), token.Session{})
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrRefreshTokenReused)
+}
+
+func TestRefreshToken_Lifetime(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	c := config.Refresh{
+		Expiration: time.Hour,
+		Idle:       10 * time.Minute,
+	}
+	rt, err := GrantRefreshToken(conn, c, uid, token.Session{})
+	require.NoError(t, err)
+	require.NotNil(t, rt.ExpiredAt)
+	assert.WithinDuration(t, time.Now().Add(c.Idle), *rt.ExpiredAt, time.Second)
+	// the absolute lifetime is kept across swaps
+	signedIn := time.Now().UTC().Add(-55 * time.Minute)
+	err = conn.Model(rt).Update("signed_in_at", signedIn).Error
+	require.NoError(t, err)
+	st, err := SwapRefreshToken(conn, c, uid, rt.String(), token.Session{})
+	require.NoError(t, err)
+	require.NotNil(t, st.ExpiredAt)
+	assert.WithinDuration(t, signedIn.Add(c.Expiration), *st.ExpiredAt, time.Second)
+	st, err = GetUsableRefreshToken(conn, st.String())
+	require.NoError(t, err)
+	require.NotNil(t, st.ExpiredAt)
+	// the session expired
+	err = conn.Model(st).Update("signed_in_at", signedIn.Add(-time.Hour)).Error
+	require.NoError(t, err)
+	_, err = SwapRefreshToken(conn, c, uid, st.String(), token.Session{})
+	assert.Error(t, err)
+	// no lifetime
+	rt, err = GrantRefreshToken(conn, config.Refresh{}, uid, token.Session{})
+	require.NoError(t, err)
+	assert.Nil(t, rt.ExpiredAt)
+}
+
+func TestSwapRefreshToken_Grace(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	c := config.Refresh{Grace: time.Minute}
+	rt, err := GrantRefreshToken(conn, c, uid, token.Session{})
+	require.NoError(t, err)
+	st1, err := SwapRefreshToken(conn, c, uid, rt.String(), token.Session{})
+	require.NoError(t, err)
+	// retried within the grace period
+	st2, err := SwapRefreshToken(conn, c, uid, rt.String(), token.Session{})
+	require.NoError(t, err)
+	assert.Equal(t, st1.SessionID, st2.SessionID)
+	assert.NotEqual(t, st1.Token, st2.Token)
+	_, err = GetUsableRefreshToken(conn, st1.String())
+	assert.NoError(t, err)
+	// the grace period has passed
+	err = conn.Unscoped().Model(rt).
+		Update("deleted_at", time.Now().UTC().Add(-2*time.Minute)).Error
+	require.NoError(t, err)
+	_, err = SwapRefreshToken(conn, c, uid, rt.String(), token.Session{})
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+}
+
+func TestDeleteSwappedRefreshTokens(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	count := func() int64 {
+		var n int64
+		err := conn.Unscoped().Model(&token.RefreshToken{}).Count(&n).Error
+		require.NoError(t, err)
+		return n
+	}
+	c := config.Refresh{Expiration: time.Hour}
+	rt, err := GrantRefreshToken(conn, c, uid, token.Session{})
+	require.NoError(t, err)
+	st, err := SwapRefreshToken(conn, c, uid, rt.String(), token.Session{})
+	require.NoError(t, err)
+	err = DeleteSwappedRefreshTokens(conn, c)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count())
+	// the session expired
+	signedIn := time.Now().UTC().Add(-2 * time.Hour)
+	err = conn.Unscoped().Model(&token.RefreshToken{}).
+		Where("session_id = ?", rt.SessionID).
+		Update("signed_in_at", signedIn).Error
+	require.NoError(t, err)
+	err = DeleteSwappedRefreshTokens(conn, c)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count())
+	_, err = GetRefreshToken(conn, rt.String())
+	assert.Error(t, err)
+	// sessions do not expire
+	c = config.Refresh{}
+	_, err = SwapRefreshToken(conn, c, uid, st.String(), token.Session{})
+	require.NoError(t, err)
+	err = DeleteSwappedRefreshTokens(conn, c)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count())
+	err = conn.Unscoped().Model(st).
+		Update("expired_at", time.Now().UTC().Add(-time.Minute)).Error
+	require.NoError(t, err)
+	err = DeleteSwappedRefreshTokens(conn, c)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count())
+}
+
+func TestGetRefreshToken(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	_, err := GetRefreshToken(conn, "")
+	assert.Error(t, err)
+	_, err = GetRefreshToken(conn, "bad")
+	assert.Error(t, err)
+	rt, err := GrantRefreshToken(conn, config.Refresh{}, uid, token.Session{})
+	require.NoError(t, err)
+	tk, err := GetRefreshToken(conn, rt.String())
+	assert.NoError(t, err)
+	assert.False(t, tk.Swapped())
+	_, err = SwapRefreshToken(conn, config.Refresh{}, uid, rt.String(), token.Session{})
+	require.NoError(t, err)
+	tk, err = GetRefreshToken(conn, rt.String())
+	assert.NoError(t, err)
+	assert.True(t, tk.Swapped())
+}
+
+func TestRevokeRefreshTokenFamily(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	err := RevokeRefreshTokenFamily(conn, nil)
+	assert.Error(t, err)
+	rt, err := GrantRefreshToken(conn, config.Refresh{}, uid, token.Session{})
+	require.NoError(t, err)
+	st, err := SwapRefreshToken(conn, config.Refresh{}, uid, rt.String(), token.Session{})
+	require.NoError(t, err)
+	other, err := GrantRefreshToken(conn, config.Refresh{}, uid, token.Session{})
+	require.NoError(t, err)
+	err = RevokeRefreshTokenFamily(conn, rt)
+	assert.NoError(t, err)
+	_, err = GetRefreshToken(conn, rt.String())
+	assert.Error(t, err)
+	_, err = GetRefreshToken(conn, st.String())
+	assert.Error(t, err)
+	_, err = GetUsableRefreshToken(conn, other.String())
+	assert.NoError(t, err)
 }
 
 func TestRevokeAllRefreshTokens(t *testing.T) {
 	t.Parallel()
 	conn, _ := tconn.TempConn(t)
 	uid := uuid.New()
-	_, err := GrantRefreshToken(conn, uid)
+	_, err := GrantRefreshToken(conn, config.Refresh{}, uid, token.Session{})
 	assert.NoError(t, err)
 	for i := 0; i < 10; i++ {
-		_, err = GrantRefreshToken(conn, uid)
+		_, err = GrantRefreshToken(conn, config.Refresh{}, uid, token.Session{})
 	}
 	count := func() int {
 		var count int64
@@ -68,7 +240,7 @@ func TestRevokeAllRefreshTokens(t *testing.T) {
 		require.NoError(t, err)
 		return int(count)
 	}
-	assert.Equal(t, 1, count())
+	assert.Equal(t, 11, count())
 	err = RevokeAllRefreshTokens(conn, uid)
 	assert.NoError(t, err)
 	assert.Equal(t, 0, count())
@@ -82,7 +254,7 @@ func TestHasUsableRefreshToken(t *testing.T) {
 	assert.NoError(t, err)
 	assert.False(t, has)
 	u := testUser(t, conn, c)
-	rt, err := GrantRefreshToken(conn, u.ID)
+	rt, err := GrantRefreshToken(conn, config.Refresh{}, u.ID, token.Session{})
 	assert.NoError(t, err)
 	has, err = HasUsableRefreshToken(conn, u.ID)
 	assert.NoError(t, err)
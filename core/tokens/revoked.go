@@ -0,0 +1,233 @@
+package tokens
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/jwt"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/store"
+)
+
+// ErrTokenRevoked is returned when a bearer token has been revoked.
+var ErrTokenRevoked = errors.New("token revoked")
+
+// RevokeBearerToken revokes the bearer token with the token id (jti).
+// The revocation expires when the token would have expired.
+func RevokeBearerToken(conn *store.Connection, c config.JWT, userID uuid.UUID, tokenID string) (*token.RevokedToken, error) {
+	if tokenID == "" {
+		return nil, errors.New("invalid token id")
+	}
+	rt := token.NewRevokedToken(userID, tokenID, c.Expiration)
+	err := conn.Create(rt).Error
+	if err != nil {
+		return nil, err
+	}
+	return rt, nil
+}
+
+// RevokeBearerTokens revokes all the bearer tokens issued to the user before a
+// timestamp. The revocation expires when the last of the tokens would have expired.
+func RevokeBearerTokens(conn *store.Connection, c config.JWT, userID uuid.UUID, before time.Time) (*token.RevokedToken, error) {
+	if before.IsZero() {
+		return nil, errors.New("invalid timestamp")
+	}
+	rt := token.NewRevokedBefore(userID, before, c.Expiration)
+	err := conn.Create(rt).Error
+	if err != nil {
+		return nil, err
+	}
+	return rt, nil
+}
+
+// RevokeSessionTokens revokes all the bearer tokens issued to the user for the session
+// before a timestamp. The revocation expires when the last of the tokens would have expired.
+func RevokeSessionTokens(conn *store.Connection, c config.JWT, userID, sessionID uuid.UUID, before time.Time) (*token.RevokedToken, error) {
+	if sessionID == uuid.Nil {
+		return nil, errors.New("invalid session id")
+	}
+	if before.IsZero() {
+		return nil, errors.New("invalid timestamp")
+	}
+	rt := token.NewRevokedSession(userID, sessionID, before, c.Expiration)
+	err := conn.Create(rt).Error
+	if err != nil {
+		return nil, err
+	}
+	return rt, nil
+}
+
+// GetRevokedTokens returns the revoked tokens that have not expired.
+func GetRevokedTokens(conn *store.Connection) ([]*token.RevokedToken, error) {
+	var revoked []*token.RevokedToken
+	now := time.Now().UTC()
+	err := conn.
+		Where("expired_at IS NULL OR expired_at > ?", now).
+		Find(&revoked).Error
+	if err != nil {
+		return nil, err
+	}
+	return revoked, nil
+}
+
+// DeleteExpiredRevokedTokens deletes the revoked tokens that have expired.
+func DeleteExpiredRevokedTokens(conn *store.Connection) error {
+	now := time.Now().UTC()
+	return conn.
+		Where("expired_at IS NOT NULL AND expired_at <= ?", now).
+		Delete(&token.RevokedToken{}).Error
+}
+
+// DenylistTTL is the length of time the denylist is cached before the
+// revocations are reloaded from the db, e.g. those made by another instance.
+const DenylistTTL = 5 * time.Second
+
+// Denylist is an in-memory cache of the revoked bearer tokens. Each
+// revocation is dropped from the cache once it has expired.
+type Denylist struct {
+	mu       sync.RWMutex
+	loading  sync.Mutex
+	conn     *store.Connection
+	ttl      time.Duration
+	loadedAt time.Time
+	tokens   map[string]*token.RevokedToken
+	sessions map[uuid.UUID]*token.RevokedToken
+	users    map[uuid.UUID]*token.RevokedToken
+}
+
+// NewDenylist returns a new empty denylist.
+func NewDenylist() *Denylist {
+	return &Denylist{
+		tokens:   map[string]*token.RevokedToken{},
+		sessions: map[uuid.UUID]*token.RevokedToken{},
+		users:    map[uuid.UUID]*token.RevokedToken{},
+	}
+}
+
+// LoadDenylist deletes the expired revocations from the db and loads the
+// rest into a new denylist. The db is the source of truth, so the denylist
+// is reloaded from the db once it has been cached for DenylistTTL.
+func LoadDenylist(conn *store.Connection) (*Denylist, error) {
+	err := DeleteExpiredRevokedTokens(conn)
+	if err != nil {
+		return nil, err
+	}
+	d := NewDenylist()
+	d.conn = conn
+	d.ttl = DenylistTTL
+	err = d.Reload()
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Reload loads the revocations that have not expired from the db.
+func (d *Denylist) Reload() error {
+	if d.conn == nil {
+		return nil
+	}
+	now := time.Now()
+	revoked, err := GetRevokedTokens(d.conn)
+	if err != nil {
+		return err
+	}
+	d.Add(revoked...)
+	d.mu.Lock()
+	d.loadedAt = now
+	d.mu.Unlock()
+	return nil
+}
+
+// Refresh reloads the denylist from the db if it has been cached for longer
+// than its ttl. If the denylist is already being reloaded it returns nil.
+func (d *Denylist) Refresh() error {
+	if !d.stale() || !d.loading.TryLock() {
+		return nil
+	}
+	defer d.loading.Unlock()
+	if !d.stale() {
+		return nil
+	}
+	return d.Reload()
+}
+
+func (d *Denylist) stale() bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.conn != nil && time.Since(d.loadedAt) >= d.ttl
+}
+
+// Add adds revocations to the denylist and drops any that have expired.
+func (d *Denylist) Add(revoked ...*token.RevokedToken) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, rt := range revoked {
+		if rt == nil || rt.Expired() {
+			continue
+		}
+		if rt.TokenID != "" {
+			d.tokens[rt.TokenID] = rt
+			continue
+		}
+		if rt.SessionID != uuid.Nil {
+			addLatest(d.sessions, rt.SessionID, rt)
+			continue
+		}
+		addLatest(d.users, rt.UserID, rt)
+	}
+	d.dropExpired()
+}
+
+// addLatest adds the revocation unless there is a later one, since
+// a later revocation covers all the tokens of an earlier one.
+func addLatest(revoked map[uuid.UUID]*token.RevokedToken, id uuid.UUID, rt *token.RevokedToken) {
+	prev, ok := revoked[id]
+	if !ok || prev.RevokedAt.Before(rt.RevokedAt) {
+		revoked[id] = rt
+	}
+}
+
+func (d *Denylist) dropExpired() {
+	for id, rt := range d.tokens {
+		if rt.Expired() {
+			delete(d.tokens, id)
+		}
+	}
+	for sid, rt := range d.sessions {
+		if rt.Expired() {
+			delete(d.sessions, sid)
+		}
+	}
+	for uid, rt := range d.users {
+		if rt.Expired() {
+			delete(d.users, uid)
+		}
+	}
+}
+
+// Revoked returns true if the bearer token for the claims has been revoked.
+func (d *Denylist) Revoked(claims *jwt.UserClaims) bool {
+	if claims == nil {
+		return false
+	}
+	uid := claims.UserID()
+	sid := claims.SessionID()
+	jti := claims.JwtID()
+	issued := claims.Issued()
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if rt, ok := d.tokens[jti]; ok && rt.Revokes(uid, sid, jti, issued) {
+		return true
+	}
+	if rt, ok := d.sessions[sid]; ok && rt.Revokes(uid, sid, jti, issued) {
+		return true
+	}
+	if rt, ok := d.users[uid]; ok && rt.Revokes(uid, sid, jti, issued) {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,218 @@
+package tokens
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/jwt"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevokeBearerToken(t *testing.T) {
+	t.Parallel()
+	conn, c := tconn.TempConn(t)
+	uid := uuid.New()
+	_, err := RevokeBearerToken(conn, c.JWT, uid, "")
+	assert.Error(t, err)
+	_, err = RevokeBearerToken(conn, c.JWT, uuid.Nil, uuid.New().String())
+	assert.Error(t, err)
+	jti := uuid.New().String()
+	rt, err := RevokeBearerToken(conn, c.JWT, uid, jti)
+	require.NoError(t, err)
+	assert.Equal(t, uid, rt.UserID)
+	assert.Equal(t, jti, rt.TokenID)
+	require.NotNil(t, rt.ExpiredAt)
+	assert.Equal(t, rt.RevokedAt.Add(c.JWT.Expiration), *rt.ExpiredAt)
+	revoked, err := GetRevokedTokens(conn)
+	assert.NoError(t, err)
+	require.Len(t, revoked, 1)
+	assert.Equal(t, jti, revoked[0].TokenID)
+}
+
+func TestRevokeBearerTokens(t *testing.T) {
+	t.Parallel()
+	conn, c := tconn.TempConn(t)
+	uid := uuid.New()
+	_, err := RevokeBearerTokens(conn, c.JWT, uid, time.Time{})
+	assert.Error(t, err)
+	_, err = RevokeBearerTokens(conn, c.JWT, uuid.Nil, time.Now())
+	assert.Error(t, err)
+	before := time.Now().UTC().Truncate(time.Microsecond)
+	rt, err := RevokeBearerTokens(conn, c.JWT, uid, before)
+	require.NoError(t, err)
+	assert.Equal(t, uid, rt.UserID)
+	assert.Empty(t, rt.TokenID)
+	assert.Equal(t, before, rt.RevokedAt)
+	revoked, err := GetRevokedTokens(conn)
+	assert.NoError(t, err)
+	assert.Len(t, revoked, 1)
+}
+
+func TestRevokeSessionTokens(t *testing.T) {
+	t.Parallel()
+	conn, c := tconn.TempConn(t)
+	uid := uuid.New()
+	sid := uuid.New()
+	_, err := RevokeSessionTokens(conn, c.JWT, uid, uuid.Nil, time.Now())
+	assert.Error(t, err)
+	_, err = RevokeSessionTokens(conn, c.JWT, uid, sid, time.Time{})
+	assert.Error(t, err)
+	_, err = RevokeSessionTokens(conn, c.JWT, uuid.Nil, sid, time.Now())
+	assert.Error(t, err)
+	before := time.Now().UTC().Truncate(time.Microsecond)
+	rt, err := RevokeSessionTokens(conn, c.JWT, uid, sid, before)
+	require.NoError(t, err)
+	assert.Equal(t, uid, rt.UserID)
+	assert.Equal(t, sid, rt.SessionID)
+	assert.Empty(t, rt.TokenID)
+	assert.Equal(t, before, rt.RevokedAt)
+	revoked, err := GetRevokedTokens(conn)
+	assert.NoError(t, err)
+	require.Len(t, revoked, 1)
+	assert.Equal(t, sid, revoked[0].SessionID)
+}
+
+func TestDeleteExpiredRevokedTokens(t *testing.T) {
+	t.Parallel()
+	conn, c := tconn.TempConn(t)
+	uid := uuid.New()
+	_, err := RevokeBearerToken(conn, c.JWT, uid, uuid.New().String())
+	require.NoError(t, err)
+	// expired
+	rt := token.NewRevokedBefore(uid, time.Now().Add(-time.Hour), time.Minute)
+	err = conn.Create(rt).Error
+	require.NoError(t, err)
+	// never expires
+	rt = token.NewRevokedBefore(uid, time.Now(), token.NoExpiration)
+	err = conn.Create(rt).Error
+	require.NoError(t, err)
+	revoked, err := GetRevokedTokens(conn)
+	assert.NoError(t, err)
+	assert.Len(t, revoked, 2)
+	err = DeleteExpiredRevokedTokens(conn)
+	assert.NoError(t, err)
+	var n int64
+	err = conn.Model(&token.RevokedToken{}).Count(&n).Error
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+}
+
+func TestLoadDenylist(t *testing.T) {
+	t.Parallel()
+	conn, c := tconn.TempConn(t)
+	u := testUser(t, conn, c)
+	tok := jwt.NewUserToken(c.JWT, u)
+	b, err := tok.Bearer()
+	require.NoError(t, err)
+	claims, err := jwt.ParseUserClaims(c.JWT, b)
+	require.NoError(t, err)
+	d, err := LoadDenylist(conn)
+	require.NoError(t, err)
+	assert.False(t, d.Revoked(claims))
+	_, err = RevokeBearerToken(conn, c.JWT, u.ID, claims.JwtID())
+	require.NoError(t, err)
+	d, err = LoadDenylist(conn)
+	require.NoError(t, err)
+	assert.True(t, d.Revoked(claims))
+	conn.Error = errors.New("force error")
+	_, err = LoadDenylist(conn)
+	assert.Error(t, err)
+}
+
+func TestDenylist_Revoked(t *testing.T) {
+	t.Parallel()
+	conn, c := tconn.TempConn(t)
+	u := testUser(t, conn, c)
+	newClaims := func() *jwt.UserClaims {
+		b, err := jwt.NewUserToken(c.JWT, u).Bearer()
+		require.NoError(t, err)
+		claims, err := jwt.ParseUserClaims(c.JWT, b)
+		require.NoError(t, err)
+		return claims
+	}
+	d := NewDenylist()
+	assert.False(t, d.Revoked(nil))
+	claims1 := newClaims()
+	claims2 := newClaims()
+	assert.False(t, d.Revoked(claims1))
+	// revoke a token
+	d.Add(nil, token.NewRevokedToken(u.ID, claims1.JwtID(), c.JWT.Expiration))
+	assert.True(t, d.Revoked(claims1))
+	assert.False(t, d.Revoked(claims2))
+	// revoke the tokens issued before now
+	d.Add(token.NewRevokedBefore(u.ID, time.Now(), c.JWT.Expiration))
+	assert.True(t, d.Revoked(claims2))
+	claims3 := newClaims()
+	assert.False(t, d.Revoked(claims3))
+	// an earlier revocation does not replace a later one
+	d.Add(token.NewRevokedBefore(u.ID, time.Now().Add(-time.Hour), c.JWT.Expiration))
+	assert.True(t, d.Revoked(claims2))
+	// revoke the tokens of a session
+	sessionClaims := func(sid uuid.UUID) *jwt.UserClaims {
+		b, err := jwt.NewToken(c.JWT, jwt.NewSessionClaims(u, sid, nil)).Bearer()
+		require.NoError(t, err)
+		claims, err := jwt.ParseUserClaims(c.JWT, b)
+		require.NoError(t, err)
+		return claims
+	}
+	sid := uuid.New()
+	d = NewDenylist()
+	claims4 := sessionClaims(sid)
+	claims5 := sessionClaims(uuid.New())
+	d.Add(token.NewRevokedSession(u.ID, sid, time.Now(), c.JWT.Expiration))
+	assert.True(t, d.Revoked(claims4))
+	assert.False(t, d.Revoked(claims5))
+	assert.False(t, d.Revoked(claims3))
+	assert.Empty(t, d.users)
+	// expired revocations are dropped
+	d = NewDenylist()
+	d.Add(token.NewRevokedBefore(u.ID, time.Now().Add(-time.Hour), time.Minute))
+	d.Add(token.NewRevokedSession(u.ID, sid, time.Now().Add(-time.Hour), time.Minute))
+	assert.False(t, d.Revoked(claims1))
+	assert.Empty(t, d.users)
+	assert.Empty(t, d.sessions)
+	rt := token.NewRevokedToken(u.ID, claims1.JwtID(), time.Millisecond)
+	d.Add(rt)
+	assert.True(t, d.Revoked(claims1))
+	time.Sleep(2 * time.Millisecond)
+	assert.False(t, d.Revoked(claims1))
+	d.Add()
+	assert.Empty(t, d.tokens)
+}
+
+func TestDenylist_Refresh(t *testing.T) {
+	t.Parallel()
+	conn, c := tconn.TempConn(t)
+	u := testUser(t, conn, c)
+	b, err := jwt.NewUserToken(c.JWT, u).Bearer()
+	require.NoError(t, err)
+	claims, err := jwt.ParseUserClaims(c.JWT, b)
+	require.NoError(t, err)
+	d, err := LoadDenylist(conn)
+	require.NoError(t, err)
+	// revoked by another instance
+	_, err = RevokeBearerToken(conn, c.JWT, u.ID, claims.JwtID())
+	require.NoError(t, err)
+	err = d.Refresh()
+	assert.NoError(t, err)
+	assert.False(t, d.Revoked(claims))
+	d.ttl = 0
+	err = d.Refresh()
+	assert.NoError(t, err)
+	assert.True(t, d.Revoked(claims))
+	// the cache is kept if the reload fails
+	conn.Error = errors.New("force error")
+	err = d.Refresh()
+	assert.Error(t, err)
+	assert.True(t, d.Revoked(claims))
+	// an empty denylist does not reload
+	d = NewDenylist()
+	err = d.Refresh()
+	assert.NoError(t, err)
+	assert.False(t, d.Revoked(claims))
+}
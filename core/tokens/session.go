@@ -0,0 +1,77 @@
+package tokens
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/store"
+)
+
+// GetSessions returns the active sessions for a user id (newest first).
+func GetSessions(conn *store.Connection, userID uuid.UUID) ([]*token.RefreshToken, error) {
+	var sessions []*token.RefreshToken
+	err := conn.
+		Where("user_id = ?", userID).
+		Order("signed_in_at DESC, id DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	usable := sessions[:0]
+	for _, rt := range sessions {
+		if rt.Usable() {
+			usable = append(usable, rt)
+		}
+	}
+	return usable, nil
+}
+
+// GetSession returns the active session for a user id with the session id.
+func GetSession(conn *store.Connection, userID, sessionID uuid.UUID) (*token.RefreshToken, error) {
+	if sessionID == uuid.Nil {
+		return nil, errors.New("invalid session id")
+	}
+	rt := new(token.RefreshToken)
+	err := conn.First(rt, "user_id = ? AND session_id = ?", userID, sessionID).Error
+	if err != nil {
+		return nil, err
+	}
+	if !rt.Usable() {
+		return nil, errors.New("invalid session")
+	}
+	return rt, nil
+}
+
+// RevokeSession revokes (deletes) the refresh tokens for a session.
+func RevokeSession(conn *store.Connection, userID, sessionID uuid.UUID) error {
+	if sessionID == uuid.Nil {
+		return errors.New("invalid session id")
+	}
+	return conn.Unscoped().
+		Where("user_id = ? AND session_id = ?", userID, sessionID).
+		Delete(&token.RefreshToken{}).Error
+}
+
+// RevokeOtherSessions revokes (deletes) the refresh tokens for all the sessions
+// of a user id except the session id and returns the number of sessions revoked.
+func RevokeOtherSessions(conn *store.Connection, userID, sessionID uuid.UUID) (int, error) {
+	sessions, err := GetSessions(conn, userID)
+	if err != nil {
+		return 0, err
+	}
+	var revoked int
+	for _, rt := range sessions {
+		if rt.SessionID == sessionID {
+			continue
+		}
+		revoked++
+	}
+	err = conn.Unscoped().
+		Where("user_id = ? AND session_id <> ?", userID, sessionID).
+		Delete(&token.RefreshToken{}).Error
+	if err != nil {
+		return 0, err
+	}
+	return revoked, nil
+}
@@ -0,0 +1,104 @@
+package tokens
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSessions(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	sessions, err := GetSessions(conn, uid)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 0)
+	rt1, err := GrantRefreshToken(conn, config.Refresh{}, uid, token.NewSession("agent 1", "", ""))
+	require.NoError(t, err)
+	rt2, err := GrantRefreshToken(conn, config.Refresh{}, uid, token.NewSession("agent 2", "", ""))
+	require.NoError(t, err)
+	_, err = GrantRefreshToken(conn, config.Refresh{}, uuid.New(), token.Session{})
+	require.NoError(t, err)
+	// swapped tokens are not listed twice
+	rt1, err = SwapRefreshToken(conn, config.Refresh{}, uid, rt1.String(), token.Session{})
+	require.NoError(t, err)
+	sessions, err = GetSessions(conn, uid)
+	assert.NoError(t, err)
+	require.Len(t, sessions, 2)
+	// newest first
+	assert.Equal(t, rt2.SessionID, sessions[0].SessionID)
+	assert.Equal(t, rt1.SessionID, sessions[1].SessionID)
+	assert.Equal(t, rt1.Token, sessions[1].Token)
+}
+
+func TestGetSession(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	rt, err := GrantRefreshToken(conn, config.Refresh{}, uid, token.Session{})
+	require.NoError(t, err)
+	_, err = GetSession(conn, uid, uuid.Nil)
+	assert.Error(t, err)
+	_, err = GetSession(conn, uid, uuid.New())
+	assert.Error(t, err)
+	_, err = GetSession(conn, uuid.New(), rt.SessionID)
+	assert.Error(t, err)
+	s, err := GetSession(conn, uid, rt.SessionID)
+	assert.NoError(t, err)
+	require.NotNil(t, s)
+	assert.Equal(t, rt.ID, s.ID)
+}
+
+func TestRevokeSession(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	rt1, err := GrantRefreshToken(conn, config.Refresh{}, uid, token.Session{})
+	require.NoError(t, err)
+	rt2, err := GrantRefreshToken(conn, config.Refresh{}, uid, token.Session{})
+	require.NoError(t, err)
+	err = RevokeSession(conn, uid, uuid.Nil)
+	assert.Error(t, err)
+	// another user
+	err = RevokeSession(conn, uuid.New(), rt1.SessionID)
+	assert.NoError(t, err)
+	_, err = GetUsableRefreshToken(conn, rt1.Token)
+	assert.NoError(t, err)
+	err = RevokeSession(conn, uid, rt1.SessionID)
+	assert.NoError(t, err)
+	_, err = GetUsableRefreshToken(conn, rt1.Token)
+	assert.Error(t, err)
+	_, err = GetUsableRefreshToken(conn, rt2.Token)
+	assert.NoError(t, err)
+}
+
+func TestRevokeOtherSessions(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	cur, err := GrantRefreshToken(conn, config.Refresh{}, uid, token.Session{})
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err = GrantRefreshToken(conn, config.Refresh{}, uid, token.Session{})
+		require.NoError(t, err)
+	}
+	n, err := RevokeOtherSessions(conn, uid, cur.SessionID)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	sessions, err := GetSessions(conn, uid)
+	assert.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, cur.SessionID, sessions[0].SessionID)
+	// revoke all
+	n, err = RevokeOtherSessions(conn, uid, uuid.Nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	sessions, err = GetSessions(conn, uid)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 0)
+}
@@ -10,12 +10,18 @@ import (
 )
 
 func grantToken(conn *store.Connection, userID uuid.UUID, issueToken func() token.Token) (token.Token, error) {
+	return grantTokenWhere(conn, userID, issueToken, "user_id = ?", userID)
+}
+
+// grantTokenWhere is grantToken for users that have more than one
+// token of a class. The conditions select the token of the user.
+func grantTokenWhere(conn *store.Connection, userID uuid.UUID, issueToken func() token.Token, conds ...interface{}) (token.Token, error) {
 	if userID == user.SystemID {
 		return nil, errors.New("system user")
 	}
 	t := issueToken()
 	err := conn.Transaction(func(tx *store.Connection) error {
-		err := tx.FirstOrCreate(t, "user_id = ?", userID).Error
+		err := tx.FirstOrCreate(t, conds...).Error
 		if err != nil {
 			return err
 		}
@@ -27,7 +33,7 @@ func grantToken(conn *store.Connection, userID uuid.UUID, issueToken func() toke
 		if err != nil {
 			return err
 		}
-		t, err = grantToken(tx, userID, issueToken)
+		t, err = grantTokenWhere(tx, userID, issueToken, conds...)
 		return err
 	})
 	if err != nil {
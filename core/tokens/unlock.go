@@ -0,0 +1,42 @@
+package tokens
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/store"
+)
+
+// GrantUnlockToken gets or creates an unlock token for the provided user.
+func GrantUnlockToken(conn *store.Connection, userID uuid.UUID, exp time.Duration) (*token.UnlockToken, error) {
+	t, err := grantToken(conn, userID, func() token.Token {
+		return token.NewUnlockToken(userID, exp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t.(*token.UnlockToken), nil
+}
+
+// GetUnlockToken returns the unlock token for the token string if found.
+func GetUnlockToken(conn *store.Connection, tok string) (*token.UnlockToken, error) {
+	var ut token.UnlockToken
+	err := conn.First(&ut, "token = ?", tok).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ut, nil
+}
+
+// UnlockTokenSent marks an unlock token as sent.
+func UnlockTokenSent(conn *store.Connection, ut *token.UnlockToken) error {
+	now := time.Now().UTC()
+	ut.SentAt = &now
+	return conn.Model(ut).Update("sent_at", ut.SentAt).Error
+}
+
+// RevokeUnlockTokens revokes any unlock tokens for the user.
+func RevokeUnlockTokens(conn *store.Connection, userID uuid.UUID) error {
+	return conn.Where("user_id = ?", userID).Delete(&token.UnlockToken{}).Error
+}
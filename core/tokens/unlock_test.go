@@ -0,0 +1,67 @@
+package tokens
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrantUnlockToken(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	ut, err := GrantUnlockToken(conn, uid, token.NoExpiration)
+	assert.NoError(t, err)
+	require.NotNil(t, ut)
+	assert.NotEmpty(t, ut.AccessToken)
+	assert.Equal(t, uid, ut.UserID)
+	// system user id
+	_, err = GrantUnlockToken(conn, user.SystemID, token.NoExpiration)
+	assert.Error(t, err)
+}
+
+func TestGetUnlockToken(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	test, err := GrantUnlockToken(conn, uid, token.NoExpiration)
+	assert.NoError(t, err)
+	assert.NotNil(t, test)
+	ut, err := GetUnlockToken(conn, test.String())
+	assert.NoError(t, err)
+	assert.Equal(t, test.UserID, ut.UserID)
+	assert.Equal(t, test.Token, ut.Token)
+	_, err = GetUnlockToken(conn, "")
+	assert.Error(t, err)
+}
+
+func TestUnlockTokenSent(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	ut, err := GrantUnlockToken(conn, uid, token.NoExpiration)
+	assert.NoError(t, err)
+	err = UnlockTokenSent(conn, ut)
+	assert.NoError(t, err)
+	assert.NotNil(t, ut.SentAt)
+}
+
+func TestRevokeUnlockTokens(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	ut, err := GrantUnlockToken(conn, uid, token.NoExpiration)
+	require.NoError(t, err)
+	err = RevokeUnlockTokens(conn, uid)
+	assert.NoError(t, err)
+	_, err = GetUnlockToken(conn, ut.String())
+	assert.Error(t, err)
+	// no tokens
+	err = RevokeUnlockTokens(conn, uid)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,29 @@
+package tokens
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/store"
+)
+
+// GrantWebAuthnToken creates a webauthn ceremony token for the session.
+func GrantWebAuthnToken(conn *store.Connection, userID uuid.UUID, session []byte, exp time.Duration) (*token.WebAuthnToken, error) {
+	wt := token.NewWebAuthnToken(userID, session, exp)
+	err := conn.Create(wt).Error
+	if err != nil {
+		return nil, err
+	}
+	return wt, nil
+}
+
+// GetWebAuthnToken returns the webauthn ceremony token for the token string if found.
+func GetWebAuthnToken(conn *store.Connection, tok string) (*token.WebAuthnToken, error) {
+	var wt token.WebAuthnToken
+	err := conn.First(&wt, "token = ?", tok).Error
+	if err != nil {
+		return nil, err
+	}
+	return &wt, nil
+}
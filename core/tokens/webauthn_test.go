@@ -0,0 +1,55 @@
+package tokens
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrantWebAuthnToken(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	session := []byte("session")
+	wt, err := GrantWebAuthnToken(conn, uid, session, time.Minute)
+	assert.NoError(t, err)
+	require.NotNil(t, wt)
+	assert.NotEmpty(t, wt.AccessToken)
+	assert.Equal(t, uid, wt.UserID)
+	assert.Equal(t, session, wt.Session)
+	assert.True(t, wt.Usable())
+	// a new token is issued for every ceremony
+	wt2, err := GrantWebAuthnToken(conn, uid, session, time.Minute)
+	assert.NoError(t, err)
+	assert.NotEqual(t, wt.Token, wt2.Token)
+	// discoverable
+	wt, err = GrantWebAuthnToken(conn, uuid.Nil, session, time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, user.SystemID, wt.UserID)
+}
+
+func TestGetWebAuthnToken(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	test, err := GrantWebAuthnToken(conn, uid, []byte("session"), time.Minute)
+	assert.NoError(t, err)
+	assert.NotNil(t, test)
+	wt, err := GetWebAuthnToken(conn, test.String())
+	assert.NoError(t, err)
+	assert.Equal(t, test.UserID, wt.UserID)
+	assert.Equal(t, test.Token, wt.Token)
+	assert.Equal(t, test.Session, wt.Session)
+	// tokens are single use
+	err = UseToken(conn, wt)
+	assert.NoError(t, err)
+	_, err = GetWebAuthnToken(conn, test.String())
+	assert.Error(t, err)
+	_, err = GetWebAuthnToken(conn, "")
+	assert.Error(t, err)
+}
@@ -1,9 +1,19 @@
 package core
 
 import (
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/jrapoport/gothic/core/events"
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/jwt"
+	"github.com/jrapoport/gothic/mail/template"
+	"github.com/jrapoport/gothic/models/auditlog"
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/models/types/key"
 	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconf"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -59,3 +69,165 @@ func TestAPI_RefreshBearerToken(t *testing.T) {
 	_, err = a.GetAuthenticatedUser(u.ID)
 	assert.Error(t, err)
 }
+
+func TestAPI_RefreshBearerToken_PasswordExpired(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	a.config.Password.MaxAge = time.Hour
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	bt, err := a.GrantBearerToken(nil, u)
+	require.NoError(t, err)
+	bt, err = a.RefreshBearerToken(nil, bt.RefreshToken.String())
+	require.NoError(t, err)
+	expireUserPassword(t, a, u, 2*time.Hour)
+	_, err = a.RefreshBearerToken(nil, bt.RefreshToken.String())
+	assert.Error(t, err)
+	// passwords do not expire
+	a.config.Password.MaxAge = 0
+	_, err = a.RefreshBearerToken(nil, bt.RefreshToken.String())
+	assert.NoError(t, err)
+}
+
+func TestAPI_RefreshBearerToken_Grace(t *testing.T) {
+	t.Parallel()
+	a := apiWithTempDB(t)
+	a.config.Refresh.Grace = time.Minute
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	bt1, err := a.GrantBearerToken(nil, u)
+	require.NoError(t, err)
+	bt2, err := a.RefreshBearerToken(nil, bt1.RefreshToken.String())
+	require.NoError(t, err)
+	// the refresh is retried
+	bt3, err := a.RefreshBearerToken(nil, bt1.RefreshToken.String())
+	require.NoError(t, err)
+	assert.Equal(t, bt2.RefreshToken.SessionID, bt3.RefreshToken.SessionID)
+	assert.NotEqual(t, bt2.RefreshToken.Token, bt3.RefreshToken.Token)
+	_, err = a.RefreshBearerToken(nil, bt2.RefreshToken.String())
+	assert.NoError(t, err)
+	// the grace period has passed
+	err = a.conn.Unscoped().Model(bt1.RefreshToken).
+		Update("deleted_at", time.Now().UTC().Add(-2*time.Minute)).Error
+	require.NoError(t, err)
+	_, err = a.RefreshBearerToken(nil, bt1.RefreshToken.String())
+	assert.ErrorIs(t, err, tokens.ErrRefreshTokenReused)
+	_, err = a.RefreshBearerToken(nil, bt3.RefreshToken.String())
+	assert.Error(t, err)
+}
+
+func TestAPI_RefreshBearerToken_Reused(t *testing.T) {
+	a := loginAPI(t)
+	var mock *tconf.SMTPMock
+	a.config, mock = tconf.MockSMTP(t, a.config)
+	a.config.Mail.SpamProtection = false
+	a.config.Refresh.Grace = 0
+	err := a.OpenMail()
+	require.NoError(t, err)
+	var sent []string
+	var mu sync.Mutex
+	mock.AddHook(t, func(email string) {
+		mu.Lock()
+		defer mu.Unlock()
+		tok := tconf.GetEmailToken(template.ResetPasswordAction, email)
+		sent = append(sent, tok)
+	})
+	var evt types.Map
+	a.AddListener(events.TokenReused, func(_ events.Event, msg types.Map) {
+		mu.Lock()
+		defer mu.Unlock()
+		evt = msg
+	})
+	ctx := testContext(a)
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	bt1, err := a.GrantBearerToken(ctx, u)
+	require.NoError(t, err)
+	other, err := a.GrantBearerToken(ctx, u)
+	require.NoError(t, err)
+	bt2, err := a.RefreshBearerToken(ctx, bt1.RefreshToken.String())
+	require.NoError(t, err)
+	// the rotated token is replayed
+	_, err = a.RefreshBearerToken(ctx, bt1.RefreshToken.String())
+	assert.ErrorIs(t, err, tokens.ErrRefreshTokenReused)
+	hasAuditEntry(t, a, auditlog.TokenReused, u.ID)
+	// the whole family is revoked
+	_, err = a.RefreshBearerToken(ctx, bt2.RefreshToken.String())
+	assert.Error(t, err)
+	_, err = a.RefreshBearerToken(ctx, bt1.RefreshToken.String())
+	assert.Error(t, err)
+	// and so are the bearer tokens of the session
+	for _, bt := range []*tokens.BearerToken{bt1, bt2, other} {
+		claims, err := jwt.ParseUserClaims(a.config.JWT, bt.String())
+		require.NoError(t, err)
+		err = a.ValidateBearerToken(claims)
+		if bt == other {
+			assert.NoError(t, err)
+			continue
+		}
+		assert.ErrorIs(t, err, tokens.ErrTokenRevoked)
+	}
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return evt != nil
+	}, 1*time.Second, 10*time.Millisecond)
+	mu.Lock()
+	assert.Equal(t, u.ID, evt[key.UserID])
+	assert.Equal(t, bt1.RefreshToken.SessionID, evt[key.SessionID])
+	mu.Unlock()
+	// alerts are off by default
+	mu.Lock()
+	assert.Empty(t, sent)
+	mu.Unlock()
+	// other sessions are not revoked
+	bt3, err := a.RefreshBearerToken(ctx, other.RefreshToken.String())
+	require.NoError(t, err)
+	a.config.Refresh.ReuseAlert = true
+	_, err = a.RefreshBearerToken(ctx, bt3.RefreshToken.String())
+	require.NoError(t, err)
+	_, err = a.RefreshBearerToken(ctx, bt3.RefreshToken.String())
+	assert.ErrorIs(t, err, tokens.ErrRefreshTokenReused)
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(sent) == 1
+	}, 1*time.Second, 10*time.Millisecond)
+	sessions, err := a.GetSessions(ctx, u.ID)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+	// the alert links to reset password
+	mu.Lock()
+	tok := sent[0]
+	mu.Unlock()
+	_, err = a.ConfirmResetPassword(ctx, tok, changedPass)
+	assert.NoError(t, err)
+}
+
+func TestAPI_RefreshBearerToken_Lifetime(t *testing.T) {
+	t.Parallel()
+	a := loginAPI(t)
+	a.config.Refresh.Idle = time.Hour
+	a.config.Refresh.Expiration = 2 * time.Hour
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	bt, err := a.GrantBearerToken(nil, u)
+	require.NoError(t, err)
+	require.NotNil(t, bt.RefreshToken.ExpiredAt)
+	// idle
+	err = a.conn.Model(bt.RefreshToken).
+		Update("expired_at", time.Now().UTC().Add(-time.Minute)).Error
+	require.NoError(t, err)
+	_, err = a.RefreshBearerToken(nil, bt.RefreshToken.String())
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, tokens.ErrRefreshTokenReused)
+	// absolute
+	bt, err = a.GrantBearerToken(nil, u)
+	require.NoError(t, err)
+	signedIn := time.Now().UTC().Add(-a.config.Refresh.Expiration)
+	err = a.conn.Model(bt.RefreshToken).Update("signed_in_at", signedIn).Error
+	require.NoError(t, err)
+	_, err = a.RefreshBearerToken(nil, bt.RefreshToken.String())
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, tokens.ErrRefreshTokenReused)
+}
@@ -66,11 +66,12 @@ func (a *API) ChangePassword(ctx context.Context, userID uuid.UUID, old, pw stri
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	err := validate.Password(a.config, pw)
+	err := checkImpersonation(ctx)
 	if err != nil {
 		return nil, a.logError(err)
 	}
 	var u *user.User
+	var rt *token.RevokedToken
 	err = a.conn.Transaction(func(tx *store.Connection) (err error) {
 		u, err = users.GetUser(tx, userID)
 		if err != nil {
@@ -85,15 +86,32 @@ func (a *API) ChangePassword(ctx context.Context, userID uuid.UUID, old, pw stri
 			err = fmt.Errorf("incorrect password %w", err)
 			return err
 		}
+		err = a.validatePassword(pw, u.Email, u.Username)
+		if err != nil {
+			return err
+		}
+		err = a.checkPasswordHistory(tx, u, pw, false)
+		if err != nil {
+			return err
+		}
+		err = a.savePasswordHistory(tx, u)
+		if err != nil {
+			return err
+		}
 		err = users.ChangePassword(tx, u, pw)
 		if err != nil {
 			return err
 		}
+		rt, err = a.revokeTokens(tx, u.ID)
+		if err != nil {
+			return err
+		}
 		return audit.LogPasswordChange(ctx, tx, u.ID)
 	})
 	if err != nil {
 		return nil, a.logError(err)
 	}
+	a.revoked.Add(rt)
 	return u, nil
 }
 
@@ -121,16 +139,22 @@ func (a *API) changeRole(ctx context.Context, conn *store.Connection, u *user.Us
 	}
 	a.log.Debugf("change user to %s: %s",
 		r.String(), u.ID)
+	var rt *token.RevokedToken
 	err := conn.Transaction(func(tx *store.Connection) (err error) {
 		err = users.ChangeRole(tx, u, r)
 		if err != nil {
 			return err
 		}
+		rt, err = a.revokeTokens(tx, u.ID)
+		if err != nil {
+			return err
+		}
 		return audit.LogChangeRole(ctx, tx, u.ID, u.Role)
 	})
 	if err != nil {
 		return err
 	}
+	a.revoked.Add(rt)
 	a.log.Debugf("changed user to %s: %s",
 		r.String(), u.ID)
 	return nil
@@ -146,18 +170,36 @@ func (a *API) ConfirmResetPassword(ctx context.Context, tok string, pw string) (
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	err := validate.Password(a.config, pw)
-	if err != nil {
-		return nil, a.logError(err)
-	}
-	return a.confirmUserWithChanges(ctx, tok,
+	var rt *token.RevokedToken
+	u, err := a.confirmUserWithChanges(ctx, tok,
 		func(tx *store.Connection, ct *token.ConfirmToken, u *user.User) error {
+			err := a.validatePassword(pw, u.Email, u.Username)
+			if err != nil {
+				return err
+			}
+			err = a.checkPasswordHistory(tx, u, pw, true)
+			if err != nil {
+				return err
+			}
+			err = a.savePasswordHistory(tx, u)
+			if err != nil {
+				return err
+			}
 			err = users.ChangePassword(tx, u, pw)
 			if err != nil {
 				return err
 			}
+			rt, err = a.revokeTokens(tx, u.ID)
+			if err != nil {
+				return err
+			}
 			return audit.LogPasswordChange(ctx, tx, u.ID)
 		})
+	if err != nil {
+		return nil, err
+	}
+	a.revoked.Add(rt)
+	return u, nil
 }
 
 // ConfirmChangeEmail confirms a user email change & account (if needed).
@@ -279,6 +321,7 @@ func (a *API) BanUser(ctx context.Context, userID uuid.UUID) (*user.User, error)
 	}
 	a.log.Debugf("ban user: %s", userID)
 	var u *user.User
+	var rt *token.RevokedToken
 	err := a.conn.Transaction(func(tx *store.Connection) (err error) {
 		u, err = users.GetUser(tx, userID)
 		if err != nil {
@@ -288,11 +331,16 @@ func (a *API) BanUser(ctx context.Context, userID uuid.UUID) (*user.User, error)
 		if err != nil {
 			return err
 		}
+		rt, err = a.revokeTokens(tx, u.ID)
+		if err != nil {
+			return err
+		}
 		return audit.LogBanned(ctx, tx, userID)
 	})
 	if err != nil {
 		return nil, a.logError(err)
 	}
+	a.revoked.Add(rt)
 	a.log.Debugf("banned user: %s", userID)
 	return u, nil
 }
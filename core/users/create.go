@@ -5,11 +5,11 @@ import (
 	"fmt"
 
 	"github.com/jrapoport/gothic/core/validate"
+	"github.com/jrapoport/gothic/hasher"
 	"github.com/jrapoport/gothic/models/types"
 	"github.com/jrapoport/gothic/models/types/provider"
 	"github.com/jrapoport/gothic/models/user"
 	"github.com/jrapoport/gothic/store"
-	"github.com/jrapoport/gothic/utils"
 )
 
 // CreateUser creates a user.
@@ -41,9 +41,12 @@ func CreateUser(conn *store.Connection, p provider.Name, email, username, pw str
 }
 
 func createUser(conn *store.Connection, p provider.Name, email, username, pw string, data, sys types.Map) (*user.User, error) {
-	hashed := utils.HashPassword(pw)
+	hashed, err := hasher.Hash(pw)
+	if err != nil {
+		return nil, err
+	}
 	u := user.NewUser(p, user.RoleUser, email, username, hashed, data, sys)
-	err := conn.Create(u).Error
+	err = conn.Create(u).Error
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,118 @@
+package users
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jrapoport/gothic/hasher"
+	"github.com/jrapoport/gothic/models/history"
+	"github.com/jrapoport/gothic/models/types/provider"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+)
+
+// GetPasswordHistory returns the last n password hashes for the user (newest first).
+func GetPasswordHistory(conn *store.Connection, u *user.User, n int) ([]*history.PasswordHash, error) {
+	if u == nil {
+		return nil, errors.New("invalid user")
+	}
+	var hashes []*history.PasswordHash
+	if n <= 0 {
+		return hashes, nil
+	}
+	err := conn.
+		Where("user_id = ?", u.ID).
+		Order("created_at DESC, id DESC").
+		Limit(n).
+		Find(&hashes).Error
+	if err != nil {
+		return nil, err
+	}
+	return hashes, nil
+}
+
+// AddPasswordHistory adds the current password hash for the user to the
+// password history and prunes the history to the last n hashes. It should
+// be called before the password is changed.
+func AddPasswordHistory(conn *store.Connection, u *user.User, n int) error {
+	if u == nil {
+		return errors.New("invalid user")
+	}
+	if n <= 0 || len(u.Password) == 0 {
+		return nil
+	}
+	err := conn.Create(history.NewPasswordHash(u.ID, u.Password)).Error
+	if err != nil {
+		return err
+	}
+	hashes, err := GetPasswordHistory(conn, u, n)
+	if err != nil {
+		return err
+	}
+	keep := make([]uint, len(hashes))
+	for i, h := range hashes {
+		keep[i] = h.ID
+	}
+	return conn.
+		Where("user_id = ? AND id NOT IN ?", u.ID, keep).
+		Delete(&history.PasswordHash{}).Error
+}
+
+// PasswordReused returns true if the password matches one of the last n
+// passwords for the user, including the current password.
+func PasswordReused(conn *store.Connection, u *user.User, pw string, n int) (bool, error) {
+	if u == nil {
+		return false, errors.New("invalid user")
+	}
+	if n <= 0 {
+		return false, nil
+	}
+	if len(u.Password) > 0 && hasher.Verify(u.Password, pw) == nil {
+		return true, nil
+	}
+	hashes, err := GetPasswordHistory(conn, u, n-1)
+	if err != nil {
+		return false, err
+	}
+	for _, h := range hashes {
+		if hasher.Verify(h.Password, pw) == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// PasswordChangedAt returns the time the password for the user was last changed.
+// If the user does not have a password changed timestamp, it is the time the newest
+// hash in the history was replaced, or the time the user was created.
+func PasswordChangedAt(conn *store.Connection, u *user.User) (time.Time, error) {
+	if u == nil {
+		return time.Time{}, errors.New("invalid user")
+	}
+	if u.PasswordChangedAt != nil {
+		return *u.PasswordChangedAt, nil
+	}
+	hashes, err := GetPasswordHistory(conn, u, 1)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(hashes) == 0 {
+		return u.CreatedAt, nil
+	}
+	return hashes[0].CreatedAt, nil
+}
+
+// GetExpiringPasswordUsers returns the confirmed users for the provider whose
+// password was last changed before the time, and who have not been reminded.
+func GetExpiringPasswordUsers(conn *store.Connection, p provider.Name, before time.Time) ([]*user.User, error) {
+	var users []*user.User
+	err := conn.
+		Where("provider = ? AND status >= ?", p, user.Active).
+		Where("confirmed_at IS NOT NULL AND password_reminded_at IS NULL").
+		Where("password_changed_at <= ?", before).
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
@@ -0,0 +1,144 @@
+package users
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/models/types/provider"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordHistory(t *testing.T) {
+	t.Parallel()
+	const depth = 3
+	conn, c := tconn.TempConn(t)
+	u := testUser(t, conn, c.Provider())
+	_, err := GetPasswordHistory(conn, nil, depth)
+	assert.Error(t, err)
+	err = AddPasswordHistory(conn, nil, depth)
+	assert.Error(t, err)
+	_, err = PasswordReused(conn, nil, "password", depth)
+	assert.Error(t, err)
+	_, err = PasswordChangedAt(conn, nil)
+	assert.Error(t, err)
+	// no history
+	at, err := PasswordChangedAt(conn, u)
+	require.NoError(t, err)
+	assert.Equal(t, *u.PasswordChangedAt, at)
+	hashes, err := GetPasswordHistory(conn, u, 0)
+	require.NoError(t, err)
+	assert.Empty(t, hashes)
+	err = AddPasswordHistory(conn, u, 0)
+	require.NoError(t, err)
+	hashes, err = GetPasswordHistory(conn, u, depth)
+	require.NoError(t, err)
+	assert.Empty(t, hashes)
+	// the current password is always checked
+	reused, err := PasswordReused(conn, u, "password", 1)
+	require.NoError(t, err)
+	assert.True(t, reused)
+	reused, err = PasswordReused(conn, u, "password", 0)
+	require.NoError(t, err)
+	assert.False(t, reused)
+	// change the password: password > password1 > password2 > password3
+	for _, pw := range []string{"password1", "password2", "password3"} {
+		old := u.Password
+		err = AddPasswordHistory(conn, u, depth-1)
+		require.NoError(t, err)
+		err = ChangePassword(conn, u, pw)
+		require.NoError(t, err)
+		hashes, err = GetPasswordHistory(conn, u, 1)
+		require.NoError(t, err)
+		require.Len(t, hashes, 1)
+		assert.Equal(t, old, hashes[0].Password)
+	}
+	hashes, err = GetPasswordHistory(conn, u, 10)
+	require.NoError(t, err)
+	assert.Len(t, hashes, depth-1)
+	at, err = PasswordChangedAt(conn, u)
+	require.NoError(t, err)
+	assert.Equal(t, *u.PasswordChangedAt, at)
+	// fallback to the history
+	u.PasswordChangedAt = nil
+	at, err = PasswordChangedAt(conn, u)
+	require.NoError(t, err)
+	assert.Equal(t, hashes[0].CreatedAt, at)
+	tests := []struct {
+		pw     string
+		depth  int
+		reused bool
+	}{
+		{"password", depth, false},
+		{"password1", depth, true},
+		{"password2", depth, true},
+		{"password3", depth, true},
+		{"password4", depth, false},
+		{"password1", 2, false},
+		{"password2", 2, true},
+		{"password2", 1, false},
+		{"password3", 1, true},
+	}
+	for _, test := range tests {
+		reused, err = PasswordReused(conn, u, test.pw, test.depth)
+		require.NoError(t, err)
+		assert.Equal(t, test.reused, reused, test.pw)
+	}
+}
+
+func TestGetExpiringPasswordUsers(t *testing.T) {
+	t.Parallel()
+	conn, c := tconn.TempConn(t)
+	p := c.Provider()
+	now := time.Now().UTC()
+	confirmed := func() *user.User {
+		u := testUser(t, conn, p)
+		err := ConfirmUser(conn, u, now)
+		require.NoError(t, err)
+		return u
+	}
+	expiring := func(users []*user.User) []uuid.UUID {
+		before := time.Now().UTC().Add(time.Hour)
+		found, err := GetExpiringPasswordUsers(conn, p, before)
+		require.NoError(t, err)
+		ids := make([]uuid.UUID, 0)
+		for _, u := range found {
+			for _, want := range users {
+				if u.ID == want.ID {
+					ids = append(ids, u.ID)
+				}
+			}
+		}
+		return ids
+	}
+	// not confirmed
+	u1 := testUser(t, conn, p)
+	// never changed
+	u2 := confirmed()
+	// changed
+	u3 := confirmed()
+	err := ChangePassword(conn, u3, "password1")
+	require.NoError(t, err)
+	// changed in the future
+	u4 := confirmed()
+	future := now.Add(2 * time.Hour)
+	err = conn.Model(u4).Update(key.PasswordChangedAt, future).Error
+	require.NoError(t, err)
+	// reminded
+	u5 := confirmed()
+	err = PasswordReminded(conn, u5)
+	require.NoError(t, err)
+	test := []*user.User{u1, u2, u3, u4, u5}
+	assert.ElementsMatch(t, []uuid.UUID{u2.ID, u3.ID}, expiring(test))
+	// a password change resets the reminder
+	err = ChangePassword(conn, u5, "password1")
+	require.NoError(t, err)
+	assert.Nil(t, u5.PasswordRemindedAt)
+	assert.ElementsMatch(t, []uuid.UUID{u2.ID, u3.ID, u5.ID}, expiring(test))
+	_, err = GetExpiringPasswordUsers(conn, provider.Google, future)
+	assert.NoError(t, err)
+}
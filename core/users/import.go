@@ -0,0 +1,216 @@
+package users
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jrapoport/gothic/core/validate"
+	"github.com/jrapoport/gothic/hasher"
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/models/types/provider"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+)
+
+// Import formats
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+// ImportRecord is a user exported from another service.
+type ImportRecord struct {
+	Email     string    `json:"email"`
+	Username  string    `json:"username,omitempty"`
+	Hash      string    `json:"password_hash"`
+	Algorithm string    `json:"hash_algorithm,omitempty"`
+	Salt      string    `json:"salt,omitempty"`
+	Confirmed bool      `json:"confirmed,omitempty"`
+	Data      types.Map `json:"data,omitempty"`
+	Metadata  types.Map `json:"metadata,omitempty"`
+}
+
+// Import is a batch of users to import.
+type Import struct {
+	// Firebase holds the project hash params required
+	// to verify firebase-scrypt password hashes.
+	Firebase *hasher.FirebaseParams `json:"firebase,omitempty"`
+	Users    []ImportRecord         `json:"users"`
+}
+
+// ImportRowResult is the result of importing a single user.
+type ImportRowResult struct {
+	Row    int    `json:"row"`
+	Email  string `json:"email"`
+	UserID string `json:"user_id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ImportResult is the result of an import.
+type ImportResult struct {
+	Imported int               `json:"imported"`
+	Failed   int               `json:"failed"`
+	Results  []ImportRowResult `json:"results"`
+}
+
+// Add adds the result for a row.
+func (r *ImportResult) Add(row int, email string, u *user.User, err error) {
+	res := ImportRowResult{Row: row, Email: email}
+	if err != nil {
+		r.Failed++
+		res.Error = err.Error()
+	} else {
+		r.Imported++
+		res.UserID = u.ID.String()
+	}
+	r.Results = append(r.Results, res)
+}
+
+// ReadImport reads users to import in json or csv format. Json may be
+// either an array of records, or an object with the records under "users".
+// Csv must have a header row that uses the json names of the record fields.
+func ReadImport(r io.Reader, format string) (*Import, error) {
+	switch strings.ToLower(format) {
+	case FormatJSON, "":
+		return readJSON(r)
+	case FormatCSV:
+		return readCSV(r)
+	default:
+		return nil, fmt.Errorf("invalid import format: %s", format)
+	}
+}
+
+func readJSON(r io.Reader) (*Import, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	imp := new(Import)
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte("[")) {
+		err = json.Unmarshal(b, &imp.Users)
+	} else {
+		err = json.Unmarshal(b, imp)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return imp, nil
+}
+
+func readCSV(r io.Reader) (*Import, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) < 1 {
+		return nil, errors.New("csv header required")
+	}
+	header := rows[0]
+	imp := &Import{Users: make([]ImportRecord, 0, len(rows)-1)}
+	for i, row := range rows[1:] {
+		var rec ImportRecord
+		for n, col := range header {
+			err = rec.set(strings.TrimSpace(col), row[n])
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", i+1, err)
+			}
+		}
+		imp.Users = append(imp.Users, rec)
+	}
+	return imp, nil
+}
+
+func (rec *ImportRecord) set(col, val string) (err error) {
+	switch strings.ToLower(col) {
+	case "email":
+		rec.Email = val
+	case "username":
+		rec.Username = val
+	case "password_hash":
+		rec.Hash = val
+	case "hash_algorithm":
+		rec.Algorithm = val
+	case "salt":
+		rec.Salt = val
+	case "confirmed":
+		if val == "" {
+			return nil
+		}
+		rec.Confirmed, err = strconv.ParseBool(val)
+	case "data":
+		rec.Data, err = parseMap(val)
+	case "metadata":
+		rec.Metadata, err = parseMap(val)
+	default:
+		// ignore unknown columns so exports can be used as-is
+	}
+	if err != nil {
+		err = fmt.Errorf("%s: %w", col, err)
+	}
+	return err
+}
+
+func parseMap(val string) (types.Map, error) {
+	if val == "" {
+		return nil, nil
+	}
+	var m types.Map
+	err := json.Unmarshal([]byte(val), &m)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ImportUser creates a user with a password hash exported from another service.
+func ImportUser(conn *store.Connection, p provider.Name, rec ImportRecord, fp *hasher.FirebaseParams) (*user.User, error) {
+	email, err := validate.Email(rec.Email)
+	if err != nil {
+		err = fmt.Errorf("email: %w", err)
+		return nil, err
+	}
+	if rec.Hash == "" {
+		return nil, errors.New("password hash required")
+	}
+	hash, err := hasher.Import(hasher.Algorithm(rec.Algorithm), rec.Hash, rec.Salt, fp)
+	if err != nil {
+		err = fmt.Errorf("password hash: %w", err)
+		return nil, err
+	}
+	var u *user.User
+	err = conn.Transaction(func(tx *store.Connection) error {
+		taken, err := IsEmailTaken(tx, email)
+		if err != nil {
+			err = fmt.Errorf("email: %w", err)
+			return err
+		}
+		if taken {
+			return errors.New("email: already taken")
+		}
+		u = user.NewUser(p, user.RoleUser, email, rec.Username, hash, rec.Data, rec.Metadata)
+		if u == nil {
+			return errors.New("invalid user")
+		}
+		err = tx.Create(u).Error
+		if err != nil {
+			return err
+		}
+		if !rec.Confirmed {
+			return nil
+		}
+		return ConfirmUser(tx, u, time.Now().UTC())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
@@ -0,0 +1,151 @@
+package users
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jrapoport/gothic/hasher"
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/jrapoport/gothic/test/tutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testMD5Hash    = "$1$ab$t00rsSdYwJXPEvSlIBoBQ0"
+	testPBKDF2Hash = "pbkdf2_sha256$10000$seasalt42$lnv0/PWydD3t+BQPaeQWVQWFFhdDE+Ed38c19dlOXfQ="
+	testImportPass = "SXJAm7qJ4?3dH!aN8T3f5p!oNnpXbaRy#Gtx#8jG"
+)
+
+func TestReadImport(t *testing.T) {
+	t.Parallel()
+	const (
+		jsonArray = `[
+			{"email": "a@example.com", "password_hash": "hash", "confirmed": true},
+			{"email": "b@example.com", "username": "b", "data": {"color": "red"}}
+		]`
+		jsonObject = `{
+			"firebase": {"signer_key": "key", "salt_separator": "Bw==", "rounds": 8, "mem_cost": 14},
+			"users": [
+				{"email": "a@example.com", "password_hash": "hash", "salt": "salt", "hash_algorithm": "firebase-scrypt"}
+			]
+		}`
+		csvData = "email, password_hash, confirmed, data, ignored\n" +
+			"a@example.com,hash,true,,x\n" +
+			`b@example.com,hash,,"{""color"":""red""}",y` + "\n"
+	)
+	imp, err := ReadImport(strings.NewReader(jsonArray), FormatJSON)
+	require.NoError(t, err)
+	assert.Nil(t, imp.Firebase)
+	require.Len(t, imp.Users, 2)
+	assert.Equal(t, "a@example.com", imp.Users[0].Email)
+	assert.Equal(t, "hash", imp.Users[0].Hash)
+	assert.True(t, imp.Users[0].Confirmed)
+	assert.Equal(t, "b", imp.Users[1].Username)
+	assert.Equal(t, "red", imp.Users[1].Data.Get(key.Color))
+	imp, err = ReadImport(strings.NewReader(jsonObject), "")
+	require.NoError(t, err)
+	require.NotNil(t, imp.Firebase)
+	assert.Equal(t, "key", imp.Firebase.SignerKey)
+	assert.Equal(t, 14, imp.Firebase.MemCost)
+	require.Len(t, imp.Users, 1)
+	assert.Equal(t, "salt", imp.Users[0].Salt)
+	assert.Equal(t, string(hasher.FirebaseScrypt), imp.Users[0].Algorithm)
+	imp, err = ReadImport(strings.NewReader(csvData), "CSV")
+	require.NoError(t, err)
+	require.Len(t, imp.Users, 2)
+	assert.True(t, imp.Users[0].Confirmed)
+	assert.Nil(t, imp.Users[0].Data)
+	assert.False(t, imp.Users[1].Confirmed)
+	assert.Equal(t, "red", imp.Users[1].Data.Get(key.Color))
+	// errors
+	bad := []struct {
+		data   string
+		format string
+	}{
+		{jsonArray, "xml"},
+		{"{", FormatJSON},
+		{"[{]", FormatJSON},
+		{"", FormatCSV},
+		{"email,confirmed\na@example.com,maybe\n", FormatCSV},
+		{"email,data\na@example.com,{\n", FormatCSV},
+		{"email,data\na@example.com\n", FormatCSV},
+	}
+	for _, test := range bad {
+		_, err = ReadImport(strings.NewReader(test.data), test.format)
+		assert.Error(t, err, test.data)
+	}
+}
+
+func TestImportResult_Add(t *testing.T) {
+	t.Parallel()
+	conn, c := tconn.TempConn(t)
+	u := testUser(t, conn, c.Provider())
+	res := &ImportResult{}
+	res.Add(0, u.Email, u, nil)
+	res.Add(1, "bad", nil, errors.New("error"))
+	assert.Equal(t, 1, res.Imported)
+	assert.Equal(t, 1, res.Failed)
+	require.Len(t, res.Results, 2)
+	assert.Equal(t, u.ID.String(), res.Results[0].UserID)
+	assert.Empty(t, res.Results[0].Error)
+	assert.Empty(t, res.Results[1].UserID)
+	assert.Equal(t, "error", res.Results[1].Error)
+}
+
+func TestImportUser(t *testing.T) {
+	t.Parallel()
+	conn, c := tconn.TempConn(t)
+	p := c.Provider()
+	tests := []ImportRecord{
+		{
+			Email: tutils.RandomEmail(),
+			Hash:  testMD5Hash,
+		},
+		{
+			Email:     tutils.RandomEmail(),
+			Username:  "peaches",
+			Hash:      testPBKDF2Hash,
+			Algorithm: string(hasher.PBKDF2SHA256),
+			Confirmed: true,
+			Data:      types.Map{key.Color: "red"},
+			Metadata:  types.Map{"source": "django"},
+		},
+	}
+	for _, rec := range tests {
+		u, err := ImportUser(conn, p, rec, nil)
+		require.NoError(t, err)
+		assert.Equal(t, rec.Email, u.Email)
+		assert.Equal(t, rec.Username, u.Username)
+		assert.Equal(t, rec.Confirmed, u.IsConfirmed())
+		assert.Equal(t, rec.Hash, string(u.Password))
+		assert.True(t, u.NeedsRehash())
+		u, err = GetUser(conn, u.ID)
+		require.NoError(t, err)
+		assert.Equal(t, rec.Confirmed, u.IsConfirmed())
+		assert.Equal(t, rec.Data.Get(key.Color), u.Data.Get(key.Color))
+		assert.Equal(t, rec.Metadata.Get("source"), u.Metadata.Get("source"))
+		err = u.Authenticate("bad")
+		assert.Error(t, err)
+		err = Authenticate(conn, u, testImportPass)
+		require.NoError(t, err)
+		assert.False(t, u.NeedsRehash())
+	}
+	taken := testUser(t, conn, p)
+	bad := []ImportRecord{
+		{},
+		{Email: "bad", Hash: testMD5Hash},
+		{Email: tutils.RandomEmail()},
+		{Email: tutils.RandomEmail(), Hash: "password"},
+		{Email: tutils.RandomEmail(), Hash: testMD5Hash, Algorithm: string(hasher.Bcrypt)},
+		{Email: tutils.RandomEmail(), Hash: "hash", Algorithm: string(hasher.FirebaseScrypt)},
+		{Email: taken.Email, Hash: testMD5Hash},
+	}
+	for _, rec := range bad {
+		_, err := ImportUser(conn, p, rec, nil)
+		assert.Error(t, err)
+	}
+}
@@ -8,12 +8,12 @@ import (
 
 	"dario.cat/mergo"
 	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/hasher"
 	"github.com/jrapoport/gothic/models/token"
 	"github.com/jrapoport/gothic/models/types"
 	"github.com/jrapoport/gothic/models/types/key"
 	"github.com/jrapoport/gothic/models/user"
 	"github.com/jrapoport/gothic/store"
-	"github.com/jrapoport/gothic/utils"
 )
 
 // Update updates a user
@@ -140,6 +140,23 @@ func ChangeEmail(conn *store.Connection, u *user.User, email string) error {
 	return conn.Model(&u).Update(key.Email, u.Email).Error
 }
 
+// ChangePhone changes & confirms the phone number for a user.
+func ChangePhone(conn *store.Connection, u *user.User, phone string) error {
+	if u == nil || !u.IsActive() {
+		return errors.New("invalid user")
+	}
+	if phone == "" {
+		return errors.New("invalid phone")
+	}
+	now := time.Now().UTC()
+	u.Phone = &phone
+	u.PhoneConfirmedAt = &now
+	return conn.Model(&u).Updates(map[string]interface{}{
+		key.Phone:            u.Phone,
+		"phone_confirmed_at": u.PhoneConfirmedAt,
+	}).Error
+}
+
 // ChangePassword changes the password for a user.
 func ChangePassword(conn *store.Connection, u *user.User, pw string) error {
 	if u == nil || u.IsLocked() {
@@ -148,7 +165,62 @@ func ChangePassword(conn *store.Connection, u *user.User, pw string) error {
 	if u.Provider.IsExternal() {
 		return errors.New("invalid provider")
 	}
-	hashed := utils.HashPassword(pw)
+	hashed, err := hasher.Hash(pw)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	u.Password = hashed
+	u.PasswordChangedAt = &now
+	u.PasswordRemindedAt = nil
+	u.MustChangePassword = false
+	return conn.Model(u).Select(key.Password, key.PasswordChangedAt,
+		key.PasswordRemindedAt, key.MustChangePassword).Updates(u).Error
+}
+
+// ForcePasswordChange requires a user to change their password the next time they login.
+func ForcePasswordChange(conn *store.Connection, u *user.User) error {
+	if u == nil || u.IsLocked() {
+		return errors.New("invalid user")
+	}
+	if u.Provider.IsExternal() {
+		return errors.New("invalid provider")
+	}
+	u.MustChangePassword = true
+	return conn.Model(u).Update(key.MustChangePassword, u.MustChangePassword).Error
+}
+
+// PasswordReminded marks that a user was reminded their password will expire.
+func PasswordReminded(conn *store.Connection, u *user.User) error {
+	if u == nil {
+		return errors.New("invalid user")
+	}
+	now := time.Now().UTC()
+	u.PasswordRemindedAt = &now
+	return conn.Model(u).Update(key.PasswordRemindedAt, u.PasswordRemindedAt).Error
+}
+
+// Authenticate returns nil if the password matches. If the password
+// hash does not match the current hashing policy it is rehashed.
+func Authenticate(conn *store.Connection, u *user.User, pw string) error {
+	if u == nil {
+		return errors.New("invalid user")
+	}
+	err := u.Authenticate(pw)
+	if err != nil {
+		return err
+	}
+	if !u.NeedsRehash() {
+		return nil
+	}
+	return setPassword(conn, u, pw)
+}
+
+func setPassword(conn *store.Connection, u *user.User, pw string) error {
+	hashed, err := hasher.Hash(pw)
+	if err != nil {
+		return err
+	}
 	u.Password = hashed
 	return conn.Model(&u).Update(key.Password, u.Password).Error
 }
@@ -163,6 +235,46 @@ func LockUser(conn *store.Connection, u *user.User) error {
 	return conn.Model(u).Update(key.Status, u.Status).Error
 }
 
+// LockUserUntil temporarily locks a user until the time.
+func LockUserUntil(conn *store.Connection, u *user.User, t time.Time) error {
+	// do not step on banned users
+	if u == nil || u.IsBanned() {
+		return nil
+	}
+	t = t.UTC()
+	u.Status = user.Locked
+	u.LockedUntil = &t
+	return conn.Model(u).Select(key.Status, key.LockedUntil).Updates(u).Error
+}
+
+// UnlockUser unlocks a locked user.
+func UnlockUser(conn *store.Connection, u *user.User) error {
+	if u == nil || u.IsBanned() {
+		return errors.New("invalid user")
+	}
+	if u.Status != user.Locked {
+		return nil
+	}
+	switch {
+	case u.ConfirmedAt == nil:
+		u.Status = user.Restricted
+	case u.VerifiedAt != nil:
+		u.Status = user.Verified
+	default:
+		u.Status = user.Active
+	}
+	u.LockedUntil = nil
+	return conn.Model(u).Select(key.Status, key.LockedUntil).Updates(u).Error
+}
+
+// UnlockIfExpired unlocks a temporarily locked user if the lock has expired.
+func UnlockIfExpired(conn *store.Connection, u *user.User) error {
+	if u == nil || !u.LockExpired() {
+		return nil
+	}
+	return UnlockUser(conn, u)
+}
+
 // BanUser bans a user.
 func BanUser(conn *store.Connection, u *user.User) error {
 	// step on any other user state
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/hasher"
 	"github.com/jrapoport/gothic/models/types"
 	"github.com/jrapoport/gothic/models/types/key"
 	"github.com/jrapoport/gothic/models/types/provider"
@@ -14,6 +15,7 @@ import (
 	"github.com/jrapoport/gothic/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestUpdate(t *testing.T) {
@@ -218,15 +220,48 @@ func TestChangeEmail(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestChangePhone(t *testing.T) {
+	t.Parallel()
+	const newPhone = "+15555550100"
+	conn, c := tconn.TempConn(t)
+	u := testUser(t, conn, c.Provider())
+	err := ChangePhone(conn, u, newPhone)
+	assert.Error(t, err)
+	err = ConfirmUser(conn, u, time.Now())
+	require.NoError(t, err)
+	err = ChangePhone(conn, u, "")
+	assert.Error(t, err)
+	err = ChangePhone(conn, u, newPhone)
+	assert.NoError(t, err)
+	require.NotNil(t, u.Phone)
+	assert.Equal(t, newPhone, *u.Phone)
+	assert.True(t, u.HasPhone())
+	u, err = GetUser(conn, u.ID)
+	require.NoError(t, err)
+	assert.True(t, u.HasPhone())
+	banUser(t, conn, u)
+	err = ChangePhone(conn, u, newPhone)
+	assert.Error(t, err)
+	err = ChangePhone(conn, nil, newPhone)
+	assert.Error(t, err)
+}
+
 func TestChangePassword(t *testing.T) {
 	t.Parallel()
 	var newPassword = utils.SecureToken()
 	conn, c := tconn.TempConn(t)
 	u := testUser(t, conn, c.Provider())
+	u.MustChangePassword = true
 	err := ChangePassword(conn, u, newPassword)
 	assert.NoError(t, err)
 	err = u.Authenticate(newPassword)
 	assert.NoError(t, err)
+	assert.NotNil(t, u.PasswordChangedAt)
+	assert.False(t, u.MustChangePassword)
+	u, err = GetUser(conn, u.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, u.PasswordChangedAt)
+	assert.False(t, u.MustChangePassword)
 	u.Provider = provider.Google
 	err = ChangePassword(conn, u, newPassword)
 	assert.Error(t, err)
@@ -237,6 +272,79 @@ func TestChangePassword(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestForcePasswordChange(t *testing.T) {
+	t.Parallel()
+	conn, c := tconn.TempConn(t)
+	u := testUser(t, conn, c.Provider())
+	err := ForcePasswordChange(conn, u)
+	assert.NoError(t, err)
+	assert.True(t, u.MustChangePassword)
+	u, err = GetUser(conn, u.ID)
+	require.NoError(t, err)
+	assert.True(t, u.MustChangePassword)
+	u.Provider = provider.Google
+	err = ForcePasswordChange(conn, u)
+	assert.Error(t, err)
+	u.Provider = c.Provider()
+	banUser(t, conn, u)
+	err = ForcePasswordChange(conn, u)
+	assert.Error(t, err)
+	err = ForcePasswordChange(conn, nil)
+	assert.Error(t, err)
+}
+
+func TestPasswordReminded(t *testing.T) {
+	t.Parallel()
+	conn, c := tconn.TempConn(t)
+	u := testUser(t, conn, c.Provider())
+	err := PasswordReminded(conn, u)
+	assert.NoError(t, err)
+	assert.NotNil(t, u.PasswordRemindedAt)
+	u, err = GetUser(conn, u.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, u.PasswordRemindedAt)
+	err = PasswordReminded(conn, nil)
+	assert.Error(t, err)
+}
+
+func TestAuthenticate(t *testing.T) {
+	t.Parallel()
+	const pw = "password"
+	conn, c := tconn.TempConn(t)
+	u := testUser(t, conn, c.Provider())
+	assert.False(t, u.NeedsRehash())
+	hash := u.Password
+	err := Authenticate(conn, u, pw)
+	assert.NoError(t, err)
+	assert.Equal(t, hash, u.Password)
+	err = Authenticate(conn, u, "bad")
+	assert.Error(t, err)
+	err = Authenticate(conn, nil, pw)
+	assert.Error(t, err)
+	// rehash an outdated hash
+	legacy, err := bcrypt.GenerateFromPassword([]byte(pw), bcrypt.MinCost)
+	require.NoError(t, err)
+	err = conn.Model(u).Update(key.Password, legacy).Error
+	require.NoError(t, err)
+	u, err = GetUser(conn, u.ID)
+	require.NoError(t, err)
+	require.True(t, u.NeedsRehash())
+	err = Authenticate(conn, u, "bad")
+	assert.Error(t, err)
+	assert.Equal(t, legacy, u.Password)
+	err = Authenticate(conn, u, pw)
+	assert.NoError(t, err)
+	assert.False(t, u.NeedsRehash())
+	u, err = GetUser(conn, u.ID)
+	require.NoError(t, err)
+	assert.False(t, u.NeedsRehash())
+	alg, err := hasher.Identify(u.Password)
+	require.NoError(t, err)
+	assert.Equal(t, hasher.Default().Algorithm(), alg)
+	err = u.Authenticate(pw)
+	assert.NoError(t, err)
+}
+
 func TestLockUser(t *testing.T) {
 	t.Parallel()
 	conn, c := tconn.TempConn(t)
@@ -249,6 +357,88 @@ func TestLockUser(t *testing.T) {
 	require.True(t, u.IsLocked())
 }
 
+func TestLockUserUntil(t *testing.T) {
+	t.Parallel()
+	conn, c := tconn.TempConn(t)
+	u := testUser(t, conn, c.Provider())
+	require.False(t, u.IsLocked())
+	until := time.Now().Add(time.Hour)
+	err := LockUserUntil(conn, nil, until)
+	assert.NoError(t, err)
+	err = LockUserUntil(conn, u, until)
+	assert.NoError(t, err)
+	u, err = GetUser(conn, u.ID)
+	require.NoError(t, err)
+	assert.True(t, u.IsLocked())
+	require.NotNil(t, u.LockedUntil)
+	assert.WithinDuration(t, until, *u.LockedUntil, time.Second)
+	// ignore banned
+	err = BanUser(conn, u)
+	require.NoError(t, err)
+	err = LockUserUntil(conn, u, until)
+	assert.NoError(t, err)
+	assert.True(t, u.IsBanned())
+}
+
+func TestUnlockUser(t *testing.T) {
+	t.Parallel()
+	conn, c := tconn.TempConn(t)
+	u := testUser(t, conn, c.Provider())
+	err := UnlockUser(conn, nil)
+	assert.Error(t, err)
+	// not locked
+	err = UnlockUser(conn, u)
+	assert.NoError(t, err)
+	assert.Equal(t, user.Restricted, u.Status)
+	err = LockUserUntil(conn, u, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	err = UnlockUser(conn, u)
+	assert.NoError(t, err)
+	u, err = GetUser(conn, u.ID)
+	require.NoError(t, err)
+	assert.False(t, u.IsLocked())
+	assert.Equal(t, user.Restricted, u.Status)
+	assert.Nil(t, u.LockedUntil)
+	// confirmed
+	err = ConfirmUser(conn, u, time.Now())
+	require.NoError(t, err)
+	err = LockUser(conn, u)
+	require.NoError(t, err)
+	err = UnlockUser(conn, u)
+	assert.NoError(t, err)
+	assert.True(t, u.IsActive())
+	// banned
+	err = BanUser(conn, u)
+	require.NoError(t, err)
+	err = UnlockUser(conn, u)
+	assert.Error(t, err)
+	assert.True(t, u.IsBanned())
+}
+
+func TestUnlockIfExpired(t *testing.T) {
+	t.Parallel()
+	conn, c := tconn.TempConn(t)
+	u := testUser(t, conn, c.Provider())
+	err := UnlockIfExpired(conn, nil)
+	assert.NoError(t, err)
+	err = ConfirmUser(conn, u, time.Now())
+	require.NoError(t, err)
+	err = LockUserUntil(conn, u, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	// not expired
+	err = UnlockIfExpired(conn, u)
+	assert.NoError(t, err)
+	assert.True(t, u.IsLocked())
+	err = LockUserUntil(conn, u, time.Now().Add(-time.Second))
+	require.NoError(t, err)
+	err = UnlockIfExpired(conn, u)
+	assert.NoError(t, err)
+	assert.True(t, u.IsActive())
+	u, err = GetUser(conn, u.ID)
+	require.NoError(t, err)
+	assert.True(t, u.IsActive())
+}
+
 func TestBanUser(t *testing.T) {
 	t.Parallel()
 	conn, c := tconn.TempConn(t)
@@ -117,6 +117,27 @@ func HasUserWithEmail(conn *store.Connection, email string) (*user.User, error)
 	return u, nil
 }
 
+// GetUserWithPhone get a user with the matching confirmed phone number.
+func GetUserWithPhone(conn *store.Connection, phone string) (*user.User, error) {
+	if phone == "" {
+		return nil, errors.New("invalid phone")
+	}
+	u := new(user.User)
+	err := conn.First(u, "phone = ? AND phone_confirmed_at IS NOT NULL", phone).Error
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// IsPhoneTaken returns true if the phone number is already taken
+func IsPhoneTaken(conn *store.Connection, phone string) (bool, error) {
+	if phone == "" {
+		return false, errors.New("invalid phone")
+	}
+	return conn.Has(new(user.User), "phone = ?", phone)
+}
+
 // IsEmailTaken returns true if the email address is already taken
 func IsEmailTaken(conn *store.Connection, email string) (bool, error) {
 	if email == "" {
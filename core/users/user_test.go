@@ -6,6 +6,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/models/token"
 	"github.com/jrapoport/gothic/models/types"
 	"github.com/jrapoport/gothic/models/types/key"
 	"github.com/jrapoport/gothic/models/types/provider"
@@ -87,7 +88,7 @@ func TestGetAuthenticatedUserWithID(t *testing.T) {
 	assert.Error(t, err)
 	_, err = GetAuthenticatedUser(conn, test.ID)
 	assert.Error(t, err)
-	_, err = tokens.GrantBearerToken(conn, c.JWT, test)
+	_, err = tokens.GrantBearerToken(conn, c.JWT, c.Refresh, test, token.Session{})
 	require.NoError(t, err)
 	u, err := GetAuthenticatedUser(conn, test.ID)
 	assert.NoError(t, err)
@@ -156,6 +157,49 @@ func TestGetUserWithEmail(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestGetUserWithPhone(t *testing.T) {
+	t.Parallel()
+	var phone = "+15555550100"
+	conn, c := tconn.TempConn(t)
+	u1 := testUser(t, conn, c.Provider())
+	_, err := GetUserWithPhone(conn, phone)
+	assert.Error(t, err)
+	u1.Phone = &phone
+	err = conn.Save(u1).Error
+	require.NoError(t, err)
+	// not confirmed
+	_, err = GetUserWithPhone(conn, phone)
+	assert.Error(t, err)
+	now := time.Now().UTC()
+	u1.PhoneConfirmedAt = &now
+	err = conn.Save(u1).Error
+	require.NoError(t, err)
+	u2, err := GetUserWithPhone(conn, phone)
+	assert.NoError(t, err)
+	assert.NotNil(t, u2)
+	assert.Equal(t, u1.ID, u2.ID)
+	_, err = GetUserWithPhone(conn, "")
+	assert.Error(t, err)
+}
+
+func TestIsPhoneTaken(t *testing.T) {
+	t.Parallel()
+	var phone = "+15555550100"
+	conn, c := tconn.TempConn(t)
+	taken, err := IsPhoneTaken(conn, phone)
+	assert.NoError(t, err)
+	assert.False(t, taken)
+	u := testUser(t, conn, c.Provider())
+	u.Phone = &phone
+	err = conn.Save(u).Error
+	require.NoError(t, err)
+	taken, err = IsPhoneTaken(conn, phone)
+	assert.NoError(t, err)
+	assert.True(t, taken)
+	_, err = IsPhoneTaken(conn, "")
+	assert.Error(t, err)
+}
+
 func TestHasUserWithEmail(t *testing.T) {
 	t.Parallel()
 	conn, c := tconn.TempConn(t)
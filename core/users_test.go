@@ -8,8 +8,11 @@ import (
 	"testing"
 
 	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/breach"
+	"github.com/jrapoport/gothic/config"
 	"github.com/jrapoport/gothic/core/context"
 	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/core/validate"
 	"github.com/jrapoport/gothic/models/account"
 	"github.com/jrapoport/gothic/models/token"
 	"github.com/jrapoport/gothic/models/types"
@@ -17,6 +20,7 @@ import (
 	"github.com/jrapoport/gothic/models/types/provider"
 	"github.com/jrapoport/gothic/models/user"
 	"github.com/jrapoport/gothic/store"
+	"github.com/jrapoport/gothic/test/tconf"
 	"github.com/jrapoport/gothic/test/tutils"
 	"github.com/jrapoport/gothic/utils"
 	"github.com/stretchr/testify/assert"
@@ -48,7 +52,7 @@ func TestAPI_GetAuthenticatedUser(t *testing.T) {
 	u = confirmUser(t, a, u)
 	_, err := a.GetAuthenticatedUser(u.ID)
 	assert.Error(t, err)
-	bt, err := tokens.GrantBearerToken(a.conn, a.config.JWT, u)
+	bt, err := tokens.GrantBearerToken(a.conn, a.config.JWT, a.config.Refresh, u, token.Session{})
 	require.NoError(t, err)
 	au, err := a.GetAuthenticatedUser(u.ID)
 	assert.NoError(t, err)
@@ -464,6 +468,7 @@ func TestAPI_ConfirmPassword(t *testing.T) {
 	assert.NoError(t, err)
 	// no password validation
 	a.config.Validation.PasswordRegex = empty
+	a.config.Password = config.Password{}
 	ct, err = tokens.GrantConfirmToken(a.conn, u.ID, token.NoExpiration)
 	assert.NoError(t, err)
 	u, err = a.ConfirmResetPassword(ctx, ct.String(), empty)
@@ -685,3 +690,38 @@ func TestAPI_GetLinkedAccounts(t *testing.T) {
 	_, err = a.GetLinkedAccounts(ctx, uuid.New(), account.Any, nil)
 	assert.Error(t, err)
 }
+
+func TestAPI_BreachedPassword(t *testing.T) {
+	t.Parallel()
+	const breached = "password"
+	c := tconf.TempDB(t)
+	c.Validation.BreachedPasswords = breachedDump(t, breached)
+	a := configuredAPI(t, c)
+	a.config.Signup.Default.Username = false
+	a.config.Signup.Code = false
+	a.config.Recaptcha.Key = ""
+	ctx := testContext(a)
+	// signup
+	_, err := a.Signup(ctx, tutils.RandomEmail(), "", breached, nil)
+	assert.ErrorIs(t, err, breach.ErrBreachedPassword)
+	var pe *validate.PasswordError
+	require.ErrorAs(t, err, &pe)
+	assert.Equal(t, []validate.PasswordViolation{
+		validate.PasswordTooWeak,
+		validate.PasswordBreached,
+	}, pe.Violations)
+	u, err := a.Signup(ctx, tutils.RandomEmail(), "", testPass, nil)
+	require.NoError(t, err)
+	// change password
+	_, err = a.ChangePassword(ctx, u.ID, testPass, breached)
+	assert.ErrorIs(t, err, breach.ErrBreachedPassword)
+	// reset password
+	ct, err := tokens.GrantConfirmToken(a.conn, u.ID, token.NoExpiration)
+	require.NoError(t, err)
+	_, err = a.ConfirmResetPassword(ctx, ct.String(), breached)
+	assert.ErrorIs(t, err, breach.ErrBreachedPassword)
+	u, err = a.GetUser(u.ID)
+	require.NoError(t, err)
+	err = u.Authenticate(testPass)
+	assert.NoError(t, err)
+}
@@ -1,25 +1,156 @@
 package validate
 
 import (
-	"errors"
 	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/jrapoport/gothic/breach"
 	"github.com/jrapoport/gothic/config"
 )
 
-// Password validates a password
-func Password(c *config.Config, password string) error {
-	if c.Security.Validation.PasswordRegex == "" {
+// PasswordViolation is a machine readable reason a password was rejected.
+type PasswordViolation string
+
+// Password violations
+const (
+	PasswordRequired         PasswordViolation = "required"
+	PasswordTooShort         PasswordViolation = "too_short"
+	PasswordTooLong          PasswordViolation = "too_long"
+	PasswordMissingLowercase PasswordViolation = "missing_lowercase"
+	PasswordMissingUppercase PasswordViolation = "missing_uppercase"
+	PasswordMissingDigit     PasswordViolation = "missing_digit"
+	PasswordMissingSymbol    PasswordViolation = "missing_symbol"
+	PasswordTooWeak          PasswordViolation = "too_weak"
+	PasswordBannedWord       PasswordViolation = "banned_word"
+	PasswordPattern          PasswordViolation = "pattern"
+	PasswordBreached         PasswordViolation = "breached"
+	PasswordReused           PasswordViolation = "reused"
+	PasswordTooRecent        PasswordViolation = "too_recent"
+)
+
+// PasswordError is returned when a password violates the password policy.
+type PasswordError struct {
+	Violations []PasswordViolation `json:"violations"`
+}
+
+// Error implements the error interface.
+func (e *PasswordError) Error() string {
+	v := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		v[i] = string(violation)
+	}
+	return "invalid password: " + strings.Join(v, ", ")
+}
+
+// Is returns true if the password was breached and target is breach.ErrBreachedPassword.
+func (e *PasswordError) Is(target error) bool {
+	return target == breach.ErrBreachedPassword && e.Has(PasswordBreached)
+}
+
+// Has returns true if the error contains the violation.
+func (e *PasswordError) Has(violation PasswordViolation) bool {
+	for _, v := range e.Violations {
+		if v == violation {
+			return true
+		}
+	}
+	return false
+}
+
+// Password validates a password against the password policy. The inputs
+// (e.g. the email and username of the user) are banned from the password.
+func Password(c *config.Config, password string, inputs ...string) error {
+	policy := c.Security.Password
+	pattern := c.Security.Validation.PasswordRegex
+	if !policy.Enabled() && pattern == "" {
 		return nil
-	} else if password == "" {
-		return errors.New("password required")
 	}
-	rx, err := regexp.Compile(c.Security.Validation.PasswordRegex)
-	if err != nil {
-		return err
+	if password == "" {
+		return &PasswordError{[]PasswordViolation{PasswordRequired}}
+	}
+	var rx *regexp.Regexp
+	if pattern != "" {
+		var err error
+		rx, err = regexp.Compile(pattern)
+		if err != nil {
+			return err
+		}
+	}
+	var violations []PasswordViolation
+	n := utf8.RuneCountInString(password)
+	if policy.MinLength > 0 && n < policy.MinLength {
+		violations = append(violations, PasswordTooShort)
+	}
+	if policy.MaxLength > 0 && n > policy.MaxLength {
+		violations = append(violations, PasswordTooLong)
+	}
+	if policy.Lowercase && strings.IndexFunc(password, unicode.IsLower) < 0 {
+		violations = append(violations, PasswordMissingLowercase)
+	}
+	if policy.Uppercase && strings.IndexFunc(password, unicode.IsUpper) < 0 {
+		violations = append(violations, PasswordMissingUppercase)
 	}
-	if !rx.MatchString(password) {
-		return errors.New("invalid password")
+	if policy.Digit && strings.IndexFunc(password, unicode.IsDigit) < 0 {
+		violations = append(violations, PasswordMissingDigit)
+	}
+	if policy.Symbol && strings.IndexFunc(password, isSymbol) < 0 {
+		violations = append(violations, PasswordMissingSymbol)
+	}
+	banned := bannedWords(c, inputs)
+	if policy.Enabled() && containsWord(password, banned) {
+		violations = append(violations, PasswordBannedWord)
+	}
+	if policy.MinScore > 0 && PasswordScore(password, banned...) < policy.MinScore {
+		violations = append(violations, PasswordTooWeak)
+	}
+	if rx != nil && !rx.MatchString(password) {
+		violations = append(violations, PasswordPattern)
+	}
+	if len(violations) > 0 {
+		return &PasswordError{violations}
+	}
+	return nil
+}
+
+// BreachedPassword returns an error if the password is in the breached password filter.
+func BreachedPassword(f *breach.Filter, password string) error {
+	if f.Contains(password) {
+		return &PasswordError{[]PasswordViolation{PasswordBreached}}
 	}
 	return nil
 }
+
+func isSymbol(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+// bannedWords returns the banned words for the service and the user
+// inputs. An email input also bans the name part of the address.
+func bannedWords(c *config.Config, inputs []string) []string {
+	words := append([]string{}, c.Security.Password.BannedWords...)
+	words = append(words, c.Service.Name)
+	for _, in := range inputs {
+		words = append(words, in)
+		if i := strings.LastIndexByte(in, '@'); i > 0 {
+			words = append(words, in[:i])
+		}
+	}
+	return words
+}
+
+// containsWord returns true if the password contains one of the words.
+// Words shorter than a min match are ignored to avoid false matches.
+func containsWord(password string, words []string) bool {
+	pw := strings.ToLower(password)
+	for _, w := range words {
+		if utf8.RuneCountInString(w) < minMatch {
+			continue
+		}
+		if strings.Contains(pw, strings.ToLower(w)) {
+			return true
+		}
+	}
+	return false
+}
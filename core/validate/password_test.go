@@ -1,10 +1,14 @@
 package validate
 
 import (
+	"crypto/sha1"
 	"testing"
 
+	"github.com/jrapoport/gothic/breach"
+	"github.com/jrapoport/gothic/config"
 	"github.com/jrapoport/gothic/test/tconf"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPassword(t *testing.T) {
@@ -18,8 +22,9 @@ func TestPassword(t *testing.T) {
 	const randomPass28 = "u)}H@j!Up*}dH~9(aH$K5S{x*,@?"
 	const randomPass40 = "Pd5ajK7?XH-We@k^Jp$/Df=x{eQxXW:m'CqQhPf{"
 	const tooLongPass = randomPass40 + randomPass8
+	const regex = "^[a-zA-Z0-9[:punct:]]{8,40}$"
 	c := tconf.Config(t)
-	regex := c.Validation.PasswordRegex // default: ^[a-zA-Z0-9[:punct:]]{8,40}$
+	c.Password = config.Password{}
 	tests := []struct {
 		pw    string
 		regex string
@@ -83,3 +88,100 @@ func TestPassword(t *testing.T) {
 		test.Err(t, err, "pw: %s regex: %s", test.pw, test.regex)
 	}
 }
+
+func TestPassword_Policy(t *testing.T) {
+	t.Parallel()
+	const (
+		email    = "peter.parker@example.com"
+		username = "spidey"
+	)
+	c := tconf.Config(t)
+	c.Service.Name = "gothic"
+	c.Password = config.Password{
+		MinLength:   10,
+		MaxLength:   20,
+		Lowercase:   true,
+		Uppercase:   true,
+		Digit:       true,
+		Symbol:      true,
+		MinScore:    3,
+		BannedWords: []string{"acme"},
+	}
+	tests := []struct {
+		pw         string
+		violations []PasswordViolation
+	}{
+		{"", []PasswordViolation{PasswordRequired}},
+		{"Xk9!vQz2#mLp", nil},
+		{"Xk9!vQz", []PasswordViolation{PasswordTooShort}},
+		{"Xk9!vQz2#mLpXk9!vQz2#mLp", []PasswordViolation{PasswordTooLong}},
+		{"XK9!VQZ2#MLP", []PasswordViolation{PasswordMissingLowercase}},
+		{"xk9!vqz2#mlp", []PasswordViolation{PasswordMissingUppercase}},
+		{"Xkr!vQzw#mLp", []PasswordViolation{PasswordMissingDigit}},
+		{"Xk9rvQz2wmLp", []PasswordViolation{PasswordMissingSymbol}},
+		{"Password123!", []PasswordViolation{PasswordTooWeak}},
+		{"Xk9!Acme#mLp", []PasswordViolation{PasswordBannedWord}},
+		{"Xk9!Gothic#mLp", []PasswordViolation{PasswordBannedWord}},
+		{"Xk9!Spidey#mLp", []PasswordViolation{PasswordBannedWord}},
+		{"Peter.Parker1!", []PasswordViolation{
+			PasswordBannedWord,
+			PasswordTooWeak,
+		}},
+		{"password", []PasswordViolation{
+			PasswordTooShort,
+			PasswordMissingUppercase,
+			PasswordMissingDigit,
+			PasswordMissingSymbol,
+			PasswordTooWeak,
+		}},
+	}
+	for _, test := range tests {
+		err := Password(c, test.pw, email, username)
+		if test.violations == nil {
+			assert.NoError(t, err, test.pw)
+			continue
+		}
+		var pe *PasswordError
+		require.ErrorAs(t, err, &pe, test.pw)
+		assert.Equal(t, test.violations, pe.Violations, test.pw)
+		for _, v := range test.violations {
+			assert.True(t, pe.Has(v))
+		}
+		assert.False(t, pe.Has(PasswordBreached))
+		assert.NotErrorIs(t, err, breach.ErrBreachedPassword)
+	}
+	// unicode passphrases
+	c.Password = config.Password{MinLength: 8, MinScore: 2}
+	err := Password(c, "correct horse battery staple")
+	assert.NoError(t, err)
+	err = Password(c, "日本語のパスワードです")
+	assert.NoError(t, err)
+	// regex
+	c.Validation.PasswordRegex = "^[a-z ]+$"
+	err = Password(c, "correct horse battery staple")
+	assert.NoError(t, err)
+	err = Password(c, "Correct Horse Battery Staple")
+	var pe *PasswordError
+	require.ErrorAs(t, err, &pe)
+	assert.Equal(t, []PasswordViolation{PasswordPattern}, pe.Violations)
+	assert.EqualError(t, err, "invalid password: pattern")
+	c.Validation.PasswordRegex = "("
+	err = Password(c, "correct horse battery staple")
+	assert.Error(t, err)
+}
+
+func TestBreachedPassword(t *testing.T) {
+	t.Parallel()
+	const breached = "password"
+	err := BreachedPassword(nil, breached)
+	assert.NoError(t, err)
+	f := breach.NewFilter(1, 0)
+	f.Add(sha1.Sum([]byte(breached)))
+	err = BreachedPassword(f, breached)
+	assert.ErrorIs(t, err, breach.ErrBreachedPassword)
+	var pe *PasswordError
+	require.ErrorAs(t, err, &pe)
+	assert.Equal(t, []PasswordViolation{PasswordBreached}, pe.Violations)
+	err = BreachedPassword(f, "SXJAm7qJ4?3dH!aN8T3f5p!oNnpXbaRy#Gtx#8jG")
+	assert.NoError(t, err)
+}
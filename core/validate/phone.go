@@ -0,0 +1,23 @@
+package validate
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// e164 matches an E.164 formatted phone number.
+var e164 = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+
+// phoneReplacer strips common phone number punctuation.
+var phoneReplacer = strings.NewReplacer(
+	" ", "", "-", "", ".", "", "(", "", ")", "")
+
+// Phone validates a phone number and returns it in E.164 format.
+func Phone(phone string) (string, error) {
+	p := phoneReplacer.Replace(phone)
+	if !e164.MatchString(p) {
+		return "", fmt.Errorf("invalid phone: %s", phone)
+	}
+	return p, nil
+}
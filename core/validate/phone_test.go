@@ -0,0 +1,34 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPhone(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		phone  string
+		expect string
+		Err    assert.ErrorAssertionFunc
+	}{
+		{"", "", assert.Error},
+		{"+", "", assert.Error},
+		{"+1", "", assert.Error},
+		{"15555550100", "", assert.Error},
+		{"+05555550100", "", assert.Error},
+		{"+1555555010a", "", assert.Error},
+		{"+1234567890123456", "", assert.Error},
+		{"+15555550100", "+15555550100", assert.NoError},
+		{"+1 555 555 0100", "+15555550100", assert.NoError},
+		{"+1 (555) 555-0100", "+15555550100", assert.NoError},
+		{"+44.20.7946.0000", "+442079460000", assert.NoError},
+		{"+123456789012345", "+123456789012345", assert.NoError},
+	}
+	for _, test := range tests {
+		p, err := Phone(test.phone)
+		test.Err(t, err)
+		assert.Equal(t, test.expect, p)
+	}
+}
@@ -0,0 +1,219 @@
+package validate
+
+import (
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// minMatch is the min length of a pattern match.
+const minMatch = 3
+
+// scoreBits are the bits of entropy needed for a score of 1, 2, 3 & 4. They
+// match the zxcvbn guess thresholds of 10^3, 10^6, 10^8 & 10^10.
+var scoreBits = []float64{10, 20, 26.6, 33.2}
+
+// commonWords are common passwords and words ordered by frequency.
+var commonWords = []string{
+	"password", "123456", "qwerty", "letmein", "welcome", "admin", "login",
+	"dragon", "monkey", "master", "football", "baseball", "iloveyou",
+	"sunshine", "princess", "shadow", "superman", "trustno", "hello",
+	"freedom", "whatever", "starwars", "secret", "michael", "charlie",
+	"jordan", "jennifer", "hunter", "ashley", "killer", "pepper", "soccer",
+	"hockey", "batman", "thomas", "tigger", "summer", "winter", "spring",
+	"autumn", "flower", "orange", "banana", "cheese", "computer", "internet",
+	"access", "passw", "pass", "love", "lover", "angel", "baby", "buster",
+	"cookie", "daniel", "ginger", "harley", "jessica", "joshua", "maggie",
+	"matrix", "mickey", "mustang", "nicole", "ranger", "robert", "silver",
+	"taylor", "william", "yankees", "zxcvbn", "default", "changeme", "test",
+	"guest", "user", "root", "god", "sex", "money", "purple",
+	"blue", "red", "green", "black", "white", "apple", "happy", "family",
+	"friend", "abc", "qaz", "asdf", "zaq", "wsx", "abcdef", "passphrase",
+}
+
+// keyboardRows are adjacent keys on a qwerty keyboard.
+var keyboardRows = []string{
+	"`1234567890-=",
+	"qwertyuiop[]\\",
+	"asdfghjkl;'",
+	"zxcvbnm,./",
+}
+
+// leet maps l33t speak substitutions to the letters they replace.
+var leet = map[rune]rune{
+	'0': 'o', '1': 'i', '3': 'e', '4': 'a', '5': 's',
+	'7': 't', '8': 'b', '@': 'a', '$': 's', '!': 'i',
+}
+
+// PasswordScore estimates the strength of a password from 0 (weakest) to
+// 4 (strongest) in the same way as zxcvbn. The password is broken into
+// common words, the user inputs, repeats, sequences, and keyboard patterns
+// which are much easier to guess than random characters.
+func PasswordScore(password string, inputs ...string) int {
+	bits := passwordEntropy(password, inputs)
+	for i, b := range scoreBits {
+		if bits < b {
+			return i
+		}
+	}
+	return len(scoreBits)
+}
+
+// passwordEntropy returns the estimated bits of entropy in a password.
+func passwordEntropy(password string, inputs []string) float64 {
+	pw := []rune(password)
+	lower := make([]rune, len(pw))
+	unleet := make([]rune, len(pw))
+	for i, r := range pw {
+		r = unicode.ToLower(r)
+		lower[i] = r
+		if l, ok := leet[r]; ok {
+			r = l
+		}
+		unleet[i] = r
+	}
+	words := dictionary(inputs)
+	var bits float64
+	for i := 0; i < len(pw); {
+		n, b := bestMatch(pw[i:], lower[i:], unleet[i:], words)
+		if n == 0 {
+			n, b = 1, math.Log2(charset(pw[i]))
+		}
+		bits += b
+		i += n
+	}
+	return bits
+}
+
+// dictionary returns the ranked dictionary words. The
+// user inputs rank ahead of the common words.
+func dictionary(inputs []string) []string {
+	words := make([]string, 0, len(inputs)+len(commonWords))
+	for _, in := range inputs {
+		in = strings.ToLower(in)
+		if utf8.RuneCountInString(in) >= minMatch {
+			words = append(words, in)
+		}
+	}
+	return append(words, commonWords...)
+}
+
+// bestMatch returns the length and bits of entropy of the longest pattern
+// at the start of the password, or zero if none is found.
+func bestMatch(pw, lower, unleet []rune, words []string) (int, float64) {
+	var n int
+	var bits float64
+	match := func(l int, b float64) {
+		if l > n || (l == n && b < bits) {
+			n, bits = l, b
+		}
+	}
+	for rank, w := range words {
+		word := []rune(w)
+		if len(word) > len(lower) {
+			continue
+		}
+		b := math.Log2(float64(rank + 1))
+		if hasPrefix(lower, word) {
+			match(len(word), b+caseBits(pw[:len(word)]))
+		} else if hasPrefix(unleet, word) {
+			match(len(word), b+caseBits(pw[:len(word)])+1)
+		}
+	}
+	if l := repeatLen(lower); l >= minMatch {
+		match(l, math.Log2(charset(pw[0]))+math.Log2(float64(l)))
+	}
+	if l := sequenceLen(lower); l >= minMatch {
+		match(l, math.Log2(charset(pw[0]))+math.Log2(float64(l))+1)
+	}
+	if l := keyboardLen(lower); l >= minMatch {
+		match(l, math.Log2(float64(len(keyboardRows)*10))+math.Log2(float64(l)))
+	}
+	return n, bits
+}
+
+func hasPrefix(s, prefix []rune) bool {
+	for i, r := range prefix {
+		if s[i] != r {
+			return false
+		}
+	}
+	return true
+}
+
+// caseBits returns the extra bits of entropy from capitalizing a word.
+func caseBits(word []rune) float64 {
+	var upper int
+	for _, r := range word {
+		if unicode.IsUpper(r) {
+			upper++
+		}
+	}
+	if upper == 0 || upper == len(word) {
+		return 0
+	}
+	if upper == 1 && unicode.IsUpper(word[0]) {
+		return 1
+	}
+	return float64(len(word))
+}
+
+// repeatLen returns the length of the run of a repeated character.
+func repeatLen(s []rune) int {
+	n := 1
+	for n < len(s) && s[n] == s[0] {
+		n++
+	}
+	return n
+}
+
+// sequenceLen returns the length of an ascending or descending sequence.
+func sequenceLen(s []rune) int {
+	if len(s) < 2 {
+		return len(s)
+	}
+	d := s[1] - s[0]
+	if d != 1 && d != -1 {
+		return 1
+	}
+	n := 2
+	for n < len(s) && s[n]-s[n-1] == d {
+		n++
+	}
+	return n
+}
+
+// keyboardLen returns the length of a run of adjacent keys.
+func keyboardLen(s []rune) int {
+	var best int
+	for _, row := range keyboardRows {
+		keys := []rune(row)
+		for i := range keys {
+			n := 0
+			for n < len(s) && i+n < len(keys) && s[n] == keys[i+n] {
+				n++
+			}
+			if n > best {
+				best = n
+			}
+		}
+	}
+	return best
+}
+
+// charset returns the size of the character set of r.
+func charset(r rune) float64 {
+	switch {
+	case r < unicode.MaxASCII && unicode.IsLower(r):
+		return 26
+	case r < unicode.MaxASCII && unicode.IsUpper(r):
+		return 26
+	case r < unicode.MaxASCII && unicode.IsDigit(r):
+		return 10
+	case r < unicode.MaxASCII:
+		return 33
+	default:
+		return 100
+	}
+}
@@ -0,0 +1,36 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordScore(t *testing.T) {
+	t.Parallel()
+	const email = "peter.parker@example.com"
+	tests := []struct {
+		pw    string
+		score int
+	}{
+		{"", 0},
+		{"password", 0},
+		{"p@ssw0rd", 0},
+		{"Password", 0},
+		{"qwertyuiop", 0},
+		{"aaaaaaaaaa", 0},
+		{"abcdefgh", 0},
+		{"0123456789", 0},
+		{"peter.parker@example.com", 0},
+		{"Password1!", 1},
+		{"Tr0ub4dour&3", 4},
+		{"7r/M3Z&F", 4},
+		{"correct horse battery staple", 4},
+		{"日本語のパスワードです", 4},
+	}
+	for _, test := range tests {
+		score := PasswordScore(test.pw, email)
+		assert.Equal(t, test.score, score, test.pw)
+	}
+	assert.Greater(t, PasswordScore("peterparker"), PasswordScore("peterparker", "peterparker"))
+}
@@ -0,0 +1,271 @@
+package core
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/audit"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/core/credentials"
+	"github.com/jrapoport/gothic/core/events"
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/core/users"
+	"github.com/jrapoport/gothic/models/credential"
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+)
+
+// BeginWebAuthnRegistration begins a webauthn (passkey) registration for the user.
+func (a *API) BeginWebAuthnRegistration(ctx context.Context, userID uuid.UUID) (*credentials.Ceremony, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	err := checkImpersonation(ctx)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	wa, err := a.webAuthn()
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	var c *credentials.Ceremony
+	err = a.conn.Transaction(func(tx *store.Connection) error {
+		u, err := credentials.GetUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		c, err = credentials.BeginRegistration(tx, wa, u, a.config.WebAuthn.Expiration)
+		return err
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	a.log.Debugf("webauthn registration started: %s", userID)
+	return c, nil
+}
+
+// FinishWebAuthnRegistration finishes a webauthn (passkey) registration and
+// saves the new credential with name. response is the json encoded response
+// returned by the client for the ceremony with token tok.
+func (a *API) FinishWebAuthnRegistration(ctx context.Context, userID uuid.UUID,
+	tok, name string, response []byte) (*credential.Credential, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	err := checkImpersonation(ctx)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	if tok == "" {
+		err := errors.New("token required")
+		return nil, a.logError(err)
+	}
+	wa, err := a.webAuthn()
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	wt, err := tokens.GetWebAuthnToken(a.conn, tok)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	// failed attempts burn the ceremony, so the
+	// token is used outside the registration transaction.
+	session, err := credentials.UseCeremony(a.conn, wt)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	var c *credential.Credential
+	err = a.conn.Transaction(func(tx *store.Connection) error {
+		u, err := credentials.GetUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		c, err = credentials.FinishRegistration(tx, wa, u, wt, session, name, response)
+		if err != nil {
+			return err
+		}
+		return audit.LogWebAuthnAdded(ctx, tx, u.ID, c.CredentialID)
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	a.dispatchEvent(events.WebAuthnAdded, types.Map{
+		key.Provider:  ctx.Provider(),
+		key.IPAddress: ctx.IPAddress(),
+		key.UserID:    userID,
+		key.ID:        c.CredentialID,
+		key.Timestamp: time.Now().UTC(),
+	})
+	return c, nil
+}
+
+// BeginWebAuthnLogin begins a webauthn (passkey) login. If email is
+// empty, a discoverable login is started and the user will be
+// identified by the passkey they choose.
+func (a *API) BeginWebAuthnLogin(ctx context.Context, email string) (*credentials.Ceremony, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	p := a.Provider()
+	ctx.SetProvider(p)
+	err := a.ext.IsEnabled(p)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	wa, err := a.webAuthn()
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	var c *credentials.Ceremony
+	err = a.conn.Transaction(func(tx *store.Connection) error {
+		var wu *credentials.User
+		if email != "" {
+			u, err := users.GetUserWithEmail(tx, email)
+			if err != nil {
+				return err
+			}
+			wu, err = credentials.GetUser(tx, u.ID)
+			if err != nil {
+				return err
+			}
+		}
+		c, err = credentials.BeginLogin(tx, wa, wu, a.config.WebAuthn.Expiration)
+		return err
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	return c, nil
+}
+
+// FinishWebAuthnLogin finishes a webauthn (passkey) login and returns the
+// user. response is the json encoded response returned by the client for
+// the ceremony with token tok.
+func (a *API) FinishWebAuthnLogin(ctx context.Context, tok string, response []byte) (*user.User, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	p := a.Provider()
+	ctx.SetProvider(p)
+	if tok == "" {
+		err := errors.New("token required")
+		return nil, a.logError(err)
+	}
+	wa, err := a.webAuthn()
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	wt, err := tokens.GetWebAuthnToken(a.conn, tok)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	// failed attempts burn the ceremony, so the
+	// token is used outside the login transaction.
+	session, err := credentials.UseCeremony(a.conn, wt)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	var u *user.User
+	err = a.conn.Transaction(func(tx *store.Connection) (err error) {
+		u, err = credentials.FinishLogin(tx, wa, wt, session, response)
+		if err != nil {
+			return err
+		}
+		return audit.LogLogin(ctx, tx, u.ID)
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	a.dispatchEvent(events.Login, types.Map{
+		key.Provider:  p,
+		key.IPAddress: ctx.IPAddress(),
+		key.UserID:    u.ID,
+		key.Timestamp: time.Now().UTC(),
+	})
+	return u, nil
+}
+
+// GetWebAuthnCredentials returns the webauthn credentials for the user.
+func (a *API) GetWebAuthnCredentials(userID uuid.UUID) ([]*credential.Credential, error) {
+	u, err := credentials.GetUser(a.conn, userID)
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	return u.Credentials, nil
+}
+
+// RenameWebAuthnCredential changes the name of a webauthn credential.
+func (a *API) RenameWebAuthnCredential(ctx context.Context, userID uuid.UUID,
+	credentialID, name string) (*credential.Credential, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var c *credential.Credential
+	err := a.conn.Transaction(func(tx *store.Connection) error {
+		u, err := users.GetActiveUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		c, err = credentials.RenameCredential(tx, u.ID, credentialID, name)
+		return err
+	})
+	if err != nil {
+		return nil, a.logError(err)
+	}
+	return c, nil
+}
+
+// DeleteWebAuthnCredential deletes a webauthn credential.
+func (a *API) DeleteWebAuthnCredential(ctx context.Context, userID uuid.UUID, credentialID string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	err := checkImpersonation(ctx)
+	if err != nil {
+		return a.logError(err)
+	}
+	err = a.conn.Transaction(func(tx *store.Connection) error {
+		u, err := users.GetActiveUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		err = credentials.DeleteCredential(tx, u.ID, credentialID)
+		if err != nil {
+			return err
+		}
+		return audit.LogWebAuthnRemoved(ctx, tx, u.ID, credentialID)
+	})
+	if err != nil {
+		return a.logError(err)
+	}
+	a.dispatchEvent(events.WebAuthnRemoved, types.Map{
+		key.Provider:  ctx.Provider(),
+		key.IPAddress: ctx.IPAddress(),
+		key.UserID:    userID,
+		key.ID:        credentialID,
+		key.Timestamp: time.Now().UTC(),
+	})
+	return nil
+}
+
+func (a *API) webAuthn() (*webauthn.WebAuthn, error) {
+	c := a.config.WebAuthn
+	timeout := webauthn.TimeoutConfig{
+		Enforce:    true,
+		Timeout:    c.Expiration,
+		TimeoutUVD: c.Expiration,
+	}
+	return webauthn.New(&webauthn.Config{
+		RPID:          c.RPID,
+		RPDisplayName: c.RPName,
+		RPOrigins:     c.Origins,
+		Timeouts: webauthn.TimeoutsConfig{
+			Login:        timeout,
+			Registration: timeout,
+		},
+	})
+}
@@ -0,0 +1,229 @@
+package core
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/descope/virtualwebauthn"
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/events"
+	"github.com/jrapoport/gothic/models/auditlog"
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testAuthenticator struct {
+	virtualwebauthn.Authenticator
+	rp   virtualwebauthn.RelyingParty
+	cred virtualwebauthn.Credential
+}
+
+func newTestAuthenticator(a *API) *testAuthenticator {
+	return &testAuthenticator{
+		Authenticator: virtualwebauthn.NewAuthenticator(),
+		rp: virtualwebauthn.RelyingParty{
+			ID:     a.config.WebAuthn.RPID,
+			Name:   a.config.WebAuthn.RPName,
+			Origin: a.config.WebAuthn.Origins[0],
+		},
+		cred: virtualwebauthn.NewCredential(virtualwebauthn.KeyTypeEC2),
+	}
+}
+
+func (ta *testAuthenticator) attest(t *testing.T, options []byte) []byte {
+	opts, err := virtualwebauthn.ParseAttestationOptions(string(options))
+	require.NoError(t, err)
+	res := virtualwebauthn.CreateAttestationResponse(ta.rp, ta.Authenticator, ta.cred, *opts)
+	ta.Options.UserHandle = []byte(opts.UserID)
+	return []byte(res)
+}
+
+func (ta *testAuthenticator) assert(t *testing.T, options []byte) []byte {
+	opts, err := virtualwebauthn.ParseAssertionOptions(string(options))
+	require.NoError(t, err)
+	res := virtualwebauthn.CreateAssertionResponse(ta.rp, ta.Authenticator, ta.cred, *opts)
+	return []byte(res)
+}
+
+func registerWebAuthn(t *testing.T, a *API, uid uuid.UUID) (*testAuthenticator, string) {
+	ctx := testContext(a)
+	ta := newTestAuthenticator(a)
+	c, err := a.BeginWebAuthnRegistration(ctx, uid)
+	require.NoError(t, err)
+	cred, err := a.FinishWebAuthnRegistration(ctx, uid, c.Token, "passkey", ta.attest(t, c.Options))
+	require.NoError(t, err)
+	return ta, cred.CredentialID
+}
+
+func TestAPI_WebAuthnRegistration(t *testing.T) {
+	t.Parallel()
+	a := loginAPI(t)
+	u := testUser(t, a)
+	ctx := testContext(a)
+	// inactive user
+	_, err := a.BeginWebAuthnRegistration(ctx, u.ID)
+	assert.Error(t, err)
+	u = confirmUser(t, a, u)
+	ta := newTestAuthenticator(a)
+	c, err := a.BeginWebAuthnRegistration(nil, u.ID)
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	res := ta.attest(t, c.Options)
+	tests := []struct {
+		uid uuid.UUID
+		tok string
+		res []byte
+	}{
+		{u.ID, "", res},
+		{u.ID, "bad", res},
+		{uuid.New(), c.Token, res},
+	}
+	for _, test := range tests {
+		_, err = a.FinishWebAuthnRegistration(ctx, test.uid, test.tok, "", test.res)
+		assert.Error(t, err)
+	}
+	c, err = a.BeginWebAuthnRegistration(ctx, u.ID)
+	require.NoError(t, err)
+	cred, err := a.FinishWebAuthnRegistration(nil, u.ID, c.Token, "passkey", ta.attest(t, c.Options))
+	require.NoError(t, err)
+	require.NotNil(t, cred)
+	assert.Equal(t, u.ID, cred.UserID)
+	assert.Equal(t, "passkey", cred.Name)
+	hasAuditEntry(t, a, auditlog.WebAuthnAdded, u.ID)
+	// bad user
+	_, err = a.BeginWebAuthnRegistration(ctx, uuid.New())
+	assert.Error(t, err)
+	// misconfigured
+	a.config.WebAuthn.RPID = ""
+	_, err = a.BeginWebAuthnRegistration(ctx, u.ID)
+	assert.Error(t, err)
+}
+
+func TestAPI_WebAuthnLogin(t *testing.T) {
+	t.Parallel()
+	a := loginAPI(t)
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	ctx := testContext(a)
+	// no credentials
+	_, err := a.BeginWebAuthnLogin(ctx, u.Email)
+	assert.Error(t, err)
+	ta, _ := registerWebAuthn(t, a, u.ID)
+	// unknown user
+	_, err = a.BeginWebAuthnLogin(ctx, "bad@example.com")
+	assert.Error(t, err)
+	for _, email := range []string{u.Email, ""} {
+		c, err := a.BeginWebAuthnLogin(nil, email)
+		require.NoError(t, err)
+		require.NotNil(t, c)
+		res := ta.assert(t, c.Options)
+		_, err = a.FinishWebAuthnLogin(ctx, "", res)
+		assert.Error(t, err)
+		_, err = a.FinishWebAuthnLogin(ctx, "bad", res)
+		assert.Error(t, err)
+		u2, err := a.FinishWebAuthnLogin(nil, c.Token, res)
+		assert.NoError(t, err)
+		require.NotNil(t, u2)
+		assert.Equal(t, u.ID, u2.ID)
+		// ceremonies are single use
+		_, err = a.FinishWebAuthnLogin(ctx, c.Token, res)
+		assert.Error(t, err)
+	}
+	// failed attempts burn the ceremony
+	c, err := a.BeginWebAuthnLogin(ctx, u.Email)
+	require.NoError(t, err)
+	_, err = a.FinishWebAuthnLogin(ctx, c.Token, []byte("{}"))
+	assert.Error(t, err)
+	_, err = a.FinishWebAuthnLogin(ctx, c.Token, ta.assert(t, c.Options))
+	assert.Error(t, err)
+	// banned user
+	banUser(t, a, u)
+	c, err = a.BeginWebAuthnLogin(ctx, "")
+	require.NoError(t, err)
+	_, err = a.FinishWebAuthnLogin(ctx, c.Token, ta.assert(t, c.Options))
+	assert.Error(t, err)
+}
+
+func TestAPI_WebAuthnCredentials(t *testing.T) {
+	t.Parallel()
+	a := loginAPI(t)
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	ctx := testContext(a)
+	creds, err := a.GetWebAuthnCredentials(u.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, creds)
+	_, id := registerWebAuthn(t, a, u.ID)
+	creds, err = a.GetWebAuthnCredentials(u.ID)
+	assert.NoError(t, err)
+	require.Len(t, creds, 1)
+	assert.Equal(t, id, creds[0].CredentialID)
+	_, err = a.GetWebAuthnCredentials(uuid.New())
+	assert.Error(t, err)
+	// rename
+	const name = "security key"
+	cred, err := a.RenameWebAuthnCredential(nil, u.ID, id, name)
+	assert.NoError(t, err)
+	assert.Equal(t, name, cred.Name)
+	_, err = a.RenameWebAuthnCredential(ctx, uuid.New(), id, name)
+	assert.Error(t, err)
+	_, err = a.RenameWebAuthnCredential(ctx, u.ID, "bad", name)
+	assert.Error(t, err)
+	// delete
+	err = a.DeleteWebAuthnCredential(ctx, uuid.New(), id)
+	assert.Error(t, err)
+	err = a.DeleteWebAuthnCredential(ctx, u.ID, "bad")
+	assert.Error(t, err)
+	err = a.DeleteWebAuthnCredential(nil, u.ID, id)
+	assert.NoError(t, err)
+	hasAuditEntry(t, a, auditlog.WebAuthnRemoved, u.ID)
+	creds, err = a.GetWebAuthnCredentials(u.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, creds)
+	// force error
+	a.conn.Error = errors.New("test failure")
+	err = a.DeleteWebAuthnCredential(ctx, u.ID, id)
+	assert.Error(t, err)
+	a.conn.Error = nil
+}
+
+func TestAPI_WebAuthn_Events(t *testing.T) {
+	t.Parallel()
+	a := loginAPI(t)
+	var mu sync.RWMutex
+	data := map[events.Event]types.Map{}
+	a.AddListener(events.All, func(evt events.Event, msg types.Map) {
+		mu.Lock()
+		defer mu.Unlock()
+		data[evt] = msg
+	})
+	hasEvent := func(evt events.Event, uid uuid.UUID) {
+		assert.Eventually(t, func() bool {
+			mu.RLock()
+			defer mu.RUnlock()
+			return data[evt] != nil
+		}, 1*time.Second, 10*time.Millisecond)
+		mu.RLock()
+		defer mu.RUnlock()
+		assert.Equal(t, evt, data[evt][key.Event].(events.Event))
+		assert.Equal(t, testIP, data[evt][key.IPAddress].(string))
+		assert.Equal(t, uid, data[evt][key.UserID].(uuid.UUID))
+	}
+	u := testUser(t, a)
+	u = confirmUser(t, a, u)
+	ctx := testContext(a)
+	ta, id := registerWebAuthn(t, a, u.ID)
+	hasEvent(events.WebAuthnAdded, u.ID)
+	c, err := a.BeginWebAuthnLogin(ctx, "")
+	require.NoError(t, err)
+	_, err = a.FinishWebAuthnLogin(ctx, c.Token, ta.assert(t, c.Options))
+	require.NoError(t, err)
+	hasEvent(events.Login, u.ID)
+	err = a.DeleteWebAuthnCredential(ctx, u.ID, id)
+	require.NoError(t, err)
+	hasEvent(events.WebAuthnRemoved, u.ID)
+}
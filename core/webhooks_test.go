@@ -34,6 +34,7 @@ func testHook(t *testing.T) *API {
 	c.Signup.AutoConfirm = false
 	c.Validation.UsernameRegex = ""
 	c.Validation.PasswordRegex = ""
+	c.Password = config.Password{}
 	c.Webhook.Secret = webhookSecret
 	c.Webhook.Issuer = webhookIssuer
 	c.Webhook.URL = hookURL
@@ -0,0 +1,116 @@
+package hasher
+
+import (
+	"crypto/subtle"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// argon2id defaults (see: OWASP Password Storage Cheat Sheet)
+const (
+	Argon2Memory      uint32 = 19 * 1024
+	Argon2Iterations  uint32 = 2
+	Argon2Parallelism uint8  = 1
+	argon2KeyLength   uint32 = 32
+)
+
+type argon2Hasher struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+}
+
+var _ Hasher = (*argon2Hasher)(nil)
+
+// NewArgon2id returns a new argon2id Hasher. Zero values use the defaults.
+func NewArgon2id(memory, iterations uint32, parallelism uint8) Hasher {
+	if memory == 0 {
+		memory = Argon2Memory
+	}
+	if iterations == 0 {
+		iterations = Argon2Iterations
+	}
+	if parallelism == 0 {
+		parallelism = Argon2Parallelism
+	}
+	return &argon2Hasher{memory, iterations, parallelism}
+}
+
+// Algorithm returns Argon2id.
+func (argon2Hasher) Algorithm() Algorithm {
+	return Argon2id
+}
+
+// Hash returns the PHC formatted argon2id hash of the password.
+func (h argon2Hasher) Hash(password string) ([]byte, error) {
+	salt, err := newSalt()
+	if err != nil {
+		return nil, err
+	}
+	key := argon2.IDKey([]byte(password), salt,
+		h.iterations, h.memory, h.parallelism, argon2KeyLength)
+	p := &phc{
+		id:      string(Argon2id),
+		version: argon2.Version,
+		params: map[string]int{
+			"m": int(h.memory),
+			"t": int(h.iterations),
+			"p": int(h.parallelism),
+		},
+		salt: salt,
+		hash: key,
+	}
+	return []byte(p.format("m", "t", "p")), nil
+}
+
+// Verify returns nil if the password matches the argon2id hash.
+func (argon2Hasher) Verify(hash []byte, password string) error {
+	p, m, t, par, err := parseArgon2(hash)
+	if err != nil {
+		return err
+	}
+	key := argon2.IDKey([]byte(password), p.salt,
+		uint32(t), uint32(m), uint8(par), uint32(len(p.hash)))
+	if subtle.ConstantTimeCompare(key, p.hash) != 1 {
+		return ErrMismatchedHash
+	}
+	return nil
+}
+
+// NeedsRehash returns true if the hash is not an argon2id
+// hash with the same parameters as the hasher.
+func (h argon2Hasher) NeedsRehash(hash []byte) bool {
+	p, m, t, par, err := parseArgon2(hash)
+	if err != nil {
+		return true
+	}
+	return uint32(m) != h.memory ||
+		uint32(t) != h.iterations ||
+		uint8(par) != h.parallelism ||
+		uint32(len(p.hash)) != argon2KeyLength
+}
+
+func parseArgon2(hash []byte) (p *phc, m, t, par int, err error) {
+	p, err = parsePHC(string(hash))
+	if err != nil {
+		return
+	}
+	if p.id != string(Argon2id) || p.version != argon2.Version {
+		err = ErrInvalidHash
+		return
+	}
+	if m, err = p.param("m"); err != nil {
+		return
+	}
+	if t, err = p.param("t"); err != nil {
+		return
+	}
+	if par, err = p.param("p"); err != nil {
+		return
+	}
+	if m <= 0 || t <= 0 || par <= 0 || par > 255 ||
+		len(p.salt) == 0 || len(p.hash) == 0 {
+		err = ErrInvalidHash
+	}
+	return
+}
@@ -0,0 +1,58 @@
+package hasher
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArgon2id(t *testing.T) {
+	t.Parallel()
+	h := NewArgon2id(0, 0, 0)
+	assert.Equal(t, Argon2id, h.Algorithm())
+	assert.Equal(t, &argon2Hasher{
+		Argon2Memory,
+		Argon2Iterations,
+		Argon2Parallelism,
+	}, h)
+	h = NewArgon2id(1024, 1, 2)
+	hash, err := h.Hash(testPass)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(hash), "$argon2id$v=19$m=1024,t=1,p=2$"))
+	hash2, err := h.Hash(testPass)
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, hash2)
+	err = h.Verify(hash, testPass)
+	assert.NoError(t, err)
+	err = h.Verify(hash, "bad")
+	assert.ErrorIs(t, err, ErrMismatchedHash)
+	assert.False(t, h.NeedsRehash(hash))
+	// params changed
+	assert.True(t, NewArgon2id(2048, 1, 2).NeedsRehash(hash))
+	assert.True(t, NewArgon2id(1024, 2, 2).NeedsRehash(hash))
+	assert.True(t, NewArgon2id(1024, 1, 1).NeedsRehash(hash))
+	// algorithm changed
+	sc, err := NewScrypt(1<<10, 0, 0)
+	require.NoError(t, err)
+	scHash, err := sc.Hash(testPass)
+	require.NoError(t, err)
+	assert.True(t, h.NeedsRehash(scHash))
+	err = h.Verify(scHash, testPass)
+	assert.ErrorIs(t, err, ErrInvalidHash)
+	// bad hashes
+	bad := []string{
+		"$argon2id$v=18$m=1024,t=1,p=2$c2FsdA$aGFzaA",
+		"$argon2id$v=19$t=1,p=2$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=1024,p=2$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=1024,t=1$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=1024,t=1,p=256$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=1024,t=1,p=2$c2FsdA",
+	}
+	for _, b := range bad {
+		err = h.Verify([]byte(b), testPass)
+		assert.ErrorIs(t, err, ErrInvalidHash, b)
+		assert.True(t, h.NeedsRehash([]byte(b)))
+	}
+}
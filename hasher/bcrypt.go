@@ -0,0 +1,70 @@
+package hasher
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// BcryptCost is the default bcrypt cost.
+const BcryptCost = bcrypt.DefaultCost
+
+const bcryptHashLength = 60
+
+type bcryptHasher struct {
+	cost int
+}
+
+var _ Hasher = (*bcryptHasher)(nil)
+
+// NewBcrypt returns a new bcrypt Hasher. A zero cost uses the default.
+func NewBcrypt(cost int) (Hasher, error) {
+	if cost == 0 {
+		cost = BcryptCost
+	}
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("invalid bcrypt cost: %d", cost)
+	}
+	return &bcryptHasher{cost}, nil
+}
+
+// Algorithm returns Bcrypt.
+func (bcryptHasher) Algorithm() Algorithm {
+	return Bcrypt
+}
+
+// Hash returns the bcrypt hash of the password. Bcrypt hashes
+// use their own modular crypt format, e.g. $2a$10$...
+func (h bcryptHasher) Hash(password string) ([]byte, error) {
+	return bcrypt.GenerateFromPassword([]byte(password), h.cost)
+}
+
+// Verify returns nil if the password matches the bcrypt hash.
+func (bcryptHasher) Verify(hash []byte, password string) error {
+	err := bcrypt.CompareHashAndPassword(hash, []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrMismatchedHash
+	}
+	return err
+}
+
+// NeedsRehash returns true if the hash is not a bcrypt hash
+// with the same cost as the hasher.
+func (h bcryptHasher) NeedsRehash(hash []byte) bool {
+	if !isBcrypt(hash) {
+		return true
+	}
+	cost, err := bcrypt.Cost(hash)
+	return err != nil || cost != h.cost
+}
+
+func isBcrypt(hash []byte) bool {
+	for _, prefix := range []string{"$2a$", "$2b$", "$2x$", "$2y$"} {
+		if bytes.HasPrefix(hash, []byte(prefix)) {
+			return true
+		}
+	}
+	return false
+}
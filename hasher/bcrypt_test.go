@@ -0,0 +1,46 @@
+package hasher
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestBcrypt(t *testing.T) {
+	t.Parallel()
+	h, err := NewBcrypt(0)
+	require.NoError(t, err)
+	assert.Equal(t, Bcrypt, h.Algorithm())
+	assert.Equal(t, &bcryptHasher{BcryptCost}, h)
+	_, err = NewBcrypt(bcrypt.MinCost - 1)
+	assert.Error(t, err)
+	_, err = NewBcrypt(bcrypt.MaxCost + 1)
+	assert.Error(t, err)
+	h, err = NewBcrypt(bcrypt.MinCost)
+	require.NoError(t, err)
+	hash, err := h.Hash(testPass)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(hash), "$2a$04$"))
+	err = h.Verify(hash, testPass)
+	assert.NoError(t, err)
+	err = h.Verify(hash, "bad")
+	assert.ErrorIs(t, err, ErrMismatchedHash)
+	assert.False(t, h.NeedsRehash(hash))
+	// cost changed
+	h2, err := NewBcrypt(bcrypt.MinCost + 1)
+	require.NoError(t, err)
+	assert.True(t, h2.NeedsRehash(hash))
+	// algorithm changed
+	a2Hash, err := NewArgon2id(1024, 1, 1).Hash(testPass)
+	require.NoError(t, err)
+	assert.True(t, h.NeedsRehash(a2Hash))
+	err = h.Verify(a2Hash, testPass)
+	assert.Error(t, err)
+	assert.True(t, h.NeedsRehash([]byte("$2a$bad")))
+	// too long
+	_, err = h.Hash(strings.Repeat("a", 73))
+	assert.Error(t, err)
+}
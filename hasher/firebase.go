@@ -0,0 +1,148 @@
+package hasher
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// FirebaseParams are the project wide password hash parameters
+// found in the Firebase console (Authentication > Users > Password
+// hash parameters). The keys are standard base64 encoded.
+type FirebaseParams struct {
+	SignerKey     string `json:"signer_key" yaml:"signer_key" mapstructure:"signer_key"`
+	SaltSeparator string `json:"salt_separator" yaml:"salt_separator" mapstructure:"salt_separator"`
+	Rounds        int    `json:"rounds"`
+	MemCost       int    `json:"mem_cost" yaml:"mem_cost" mapstructure:"mem_cost"`
+}
+
+const firebaseKeyLength = 32
+
+// Encode returns the firebase-scrypt hash for the standard base64
+// encoded password hash and salt exported from Firebase. The hash
+// is stored in a PHC like format that includes the project params:
+//
+//	$firebase-scrypt$ln=<mem_cost>,r=<rounds>$<salt>$<separator>$<signer key>$<hash>
+func (fp FirebaseParams) Encode(hash, salt string) ([]byte, error) {
+	if fp.MemCost < 1 || fp.MemCost > 31 || fp.Rounds < 1 {
+		return nil, errors.New("invalid firebase mem cost or rounds")
+	}
+	decode := func(name, s string) ([]byte, error) {
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid firebase %s: %w", name, err)
+		}
+		return b, nil
+	}
+	key, err := decode("signer key", fp.SignerKey)
+	if err != nil {
+		return nil, err
+	}
+	sep, err := decode("salt separator", fp.SaltSeparator)
+	if err != nil {
+		return nil, err
+	}
+	s, err := decode("salt", salt)
+	if err != nil {
+		return nil, err
+	}
+	h, err := decode("hash", hash)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) == 0 || len(s) == 0 || len(h) == 0 {
+		return nil, ErrInvalidHash
+	}
+	enc := fmt.Sprintf("$%s$ln=%d,r=%d$%s$%s$%s$%s",
+		FirebaseScrypt, fp.MemCost, fp.Rounds,
+		b64.EncodeToString(s), b64.EncodeToString(sep),
+		b64.EncodeToString(key), b64.EncodeToString(h))
+	return []byte(enc), nil
+}
+
+type firebaseHash struct {
+	memCost int
+	rounds  int
+	salt    []byte
+	sep     []byte
+	key     []byte
+	hash    []byte
+}
+
+func parseFirebase(hash []byte) (*firebaseHash, error) {
+	parts := strings.Split(string(hash), "$")
+	if len(parts) != 7 || parts[0] != "" || parts[1] != string(FirebaseScrypt) {
+		return nil, ErrInvalidHash
+	}
+	fh := new(firebaseHash)
+	for _, kv := range strings.Split(parts[2], ",") {
+		k, v, _ := strings.Cut(kv, "=")
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, ErrInvalidHash
+		}
+		switch k {
+		case "ln":
+			fh.memCost = i
+		case "r":
+			fh.rounds = i
+		default:
+			return nil, ErrInvalidHash
+		}
+	}
+	if fh.memCost < 1 || fh.memCost > 31 || fh.rounds < 1 {
+		return nil, ErrInvalidHash
+	}
+	fields := []*[]byte{&fh.salt, &fh.sep, &fh.key, &fh.hash}
+	for i, f := range fields {
+		b, err := b64.DecodeString(parts[3+i])
+		if err != nil {
+			return nil, ErrInvalidHash
+		}
+		*f = b
+	}
+	if len(fh.salt) == 0 || len(fh.key) == 0 || len(fh.hash) == 0 {
+		return nil, ErrInvalidHash
+	}
+	return fh, nil
+}
+
+// verifyFirebase returns nil if the password matches the firebase-scrypt hash.
+// see: https://github.com/firebase/scrypt
+func verifyFirebase(hash []byte, password string) error {
+	fh, err := parseFirebase(hash)
+	if err != nil {
+		return err
+	}
+	sum, err := firebaseKey([]byte(password), fh)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(sum, fh.hash) != 1 {
+		return ErrMismatchedHash
+	}
+	return nil
+}
+
+func firebaseKey(password []byte, fh *firebaseHash) ([]byte, error) {
+	salt := append(append([]byte{}, fh.salt...), fh.sep...)
+	dk, err := scrypt.Key(password, salt, 1<<fh.memCost, fh.rounds, 1, firebaseKeyLength)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(dk)
+	if err != nil {
+		return nil, err
+	}
+	sum := make([]byte, len(fh.key))
+	iv := make([]byte, aes.BlockSize)
+	cipher.NewCTR(block, iv).XORKeyStream(sum, fh.key)
+	return sum, nil
+}
@@ -0,0 +1,83 @@
+package hasher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// see: https://github.com/firebase/scrypt
+var testFirebase = FirebaseParams{
+	SignerKey:     "jxspr8Ki0RYycVU8zykbdLGjFQ3McFUH0uiiTvC8pVMXAn210wjLNmdZJzxUECKbm0QsEmYUSDzZvpjeJ9WmXA==",
+	SaltSeparator: "Bw==",
+	Rounds:        8,
+	MemCost:       14,
+}
+
+const (
+	testFirebasePass = "user1password"
+	testFirebaseSalt = "42xEC+ixf3L2lw=="
+	testFirebaseHash = "lSrfV15cpx95/sZS2W9c9Kp6i/LVgQNDNC/qzrCnh1SAyZvqmZqAjTdn3aoItz+VHjoZilo78198JAdRuid5lQ=="
+)
+
+func TestFirebaseParams_Encode(t *testing.T) {
+	t.Parallel()
+	hash, err := testFirebase.Encode(testFirebaseHash, testFirebaseSalt)
+	require.NoError(t, err)
+	assert.Regexp(t, `^\$firebase-scrypt\$ln=14,r=8\$`, string(hash))
+	assert.LessOrEqual(t, len(hash), 255)
+	fh, err := parseFirebase(hash)
+	require.NoError(t, err)
+	assert.Equal(t, 14, fh.memCost)
+	assert.Equal(t, 8, fh.rounds)
+	err = verifyFirebase(hash, testFirebasePass)
+	assert.NoError(t, err)
+	err = verifyFirebase(hash, "bad")
+	assert.ErrorIs(t, err, ErrMismatchedHash)
+	// bad params
+	tests := []struct {
+		fp   FirebaseParams
+		hash string
+		salt string
+	}{
+		{FirebaseParams{}, testFirebaseHash, testFirebaseSalt},
+		{FirebaseParams{
+			SignerKey:     "!",
+			SaltSeparator: testFirebase.SaltSeparator,
+			Rounds:        8,
+			MemCost:       14,
+		}, testFirebaseHash, testFirebaseSalt},
+		{FirebaseParams{
+			SignerKey:     testFirebase.SignerKey,
+			SaltSeparator: "!",
+			Rounds:        8,
+			MemCost:       14,
+		}, testFirebaseHash, testFirebaseSalt},
+		{FirebaseParams{
+			SignerKey: "",
+			Rounds:    8,
+			MemCost:   14,
+		}, testFirebaseHash, testFirebaseSalt},
+		{testFirebase, "!", testFirebaseSalt},
+		{testFirebase, testFirebaseHash, "!"},
+		{testFirebase, testFirebaseHash, ""},
+	}
+	for _, test := range tests {
+		_, err = test.fp.Encode(test.hash, test.salt)
+		assert.Error(t, err)
+	}
+	bad := []string{
+		"",
+		"$firebase-scrypt$ln=14,r=8$salt",
+		"$firebase-scrypt$ln=a,r=8$c2FsdA$Bw$a2V5$aGFzaA",
+		"$firebase-scrypt$ln=14,r=0$c2FsdA$Bw$a2V5$aGFzaA",
+		"$firebase-scrypt$ln=14,r=8,p=1$c2FsdA$Bw$a2V5$aGFzaA",
+		"$firebase-scrypt$ln=14,r=8$!$Bw$a2V5$aGFzaA",
+		"$firebase-scrypt$ln=14,r=8$$Bw$a2V5$aGFzaA",
+	}
+	for _, b := range bad {
+		err = verifyFirebase([]byte(b), testFirebasePass)
+		assert.ErrorIs(t, err, ErrInvalidHash, b)
+	}
+}
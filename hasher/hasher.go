@@ -0,0 +1,205 @@
+package hasher
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/jrapoport/gothic/config"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Algorithm is a password hashing algorithm.
+type Algorithm string
+
+// Supported algorithms
+const (
+	Argon2id Algorithm = "argon2id"
+	Bcrypt   Algorithm = "bcrypt"
+	Scrypt   Algorithm = "scrypt"
+)
+
+// Imported algorithms. Passwords hashed with these algorithms by other
+// services can be verified, but are never used to hash new passwords.
+const (
+	FirebaseScrypt Algorithm = "firebase-scrypt"
+	MD5Crypt       Algorithm = "md5-crypt"
+	PBKDF2SHA256   Algorithm = "pbkdf2-sha256"
+)
+
+var (
+	// ErrMismatchedHash is returned when a password does not match its hash.
+	ErrMismatchedHash = errors.New("hashed password does not match")
+	// ErrInvalidHash is returned when a hash cannot be parsed.
+	ErrInvalidHash = errors.New("invalid hash")
+	// ErrUnknownAlgorithm is returned for unsupported algorithms.
+	ErrUnknownAlgorithm = errors.New("unknown algorithm")
+)
+
+// Hasher hashes and verifies passwords.
+type Hasher interface {
+	// Algorithm returns the hashing algorithm.
+	Algorithm() Algorithm
+	// Hash returns the PHC formatted hash of the password.
+	Hash(password string) ([]byte, error)
+	// Verify returns nil if the password matches the hash.
+	Verify(hash []byte, password string) error
+	// NeedsRehash returns true if the hash was not created by the
+	// hasher's algorithm with the hasher's current parameters.
+	NeedsRehash(hash []byte) bool
+}
+
+// New returns a new Hasher for the configuration.
+func New(c config.Hash) (Hasher, error) {
+	switch Algorithm(strings.ToLower(c.Algorithm)) {
+	case Argon2id, "":
+		a := c.Argon2
+		return NewArgon2id(a.Memory, a.Iterations, a.Parallelism), nil
+	case Bcrypt:
+		return NewBcrypt(c.Bcrypt.Cost)
+	case Scrypt:
+		s := c.Scrypt
+		return NewScrypt(s.N, s.R, s.P)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAlgorithm, c.Algorithm)
+	}
+}
+
+var (
+	std Hasher = NewArgon2id(0, 0, 0)
+	mu  sync.RWMutex
+)
+
+// Use sets the hasher used to hash new passwords.
+func Use(h Hasher) {
+	if h == nil {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	std = h
+}
+
+// Default returns the hasher used to hash new passwords.
+func Default() Hasher {
+	mu.RLock()
+	defer mu.RUnlock()
+	return std
+}
+
+// Hash hashes the password with the default hasher.
+func Hash(password string) ([]byte, error) {
+	return Default().Hash(password)
+}
+
+// Verify returns nil if the password matches the hash. The algorithm
+// and its parameters are read from the hash, so passwords hashed with
+// any supported algorithm can be verified.
+func Verify(hash []byte, password string) error {
+	alg, err := Identify(hash)
+	if err != nil {
+		return err
+	}
+	switch alg {
+	case Argon2id:
+		return argon2Hasher{}.Verify(hash, password)
+	case Bcrypt:
+		return bcryptHasher{}.Verify(hash, password)
+	case Scrypt:
+		return scryptHasher{}.Verify(hash, password)
+	case FirebaseScrypt:
+		return verifyFirebase(hash, password)
+	case MD5Crypt:
+		return verifyMD5Crypt(hash, password)
+	case PBKDF2SHA256:
+		return verifyPBKDF2(hash, password)
+	}
+	return ErrUnknownAlgorithm
+}
+
+// NeedsRehash returns true if the hash does not match the default hasher.
+func NeedsRehash(hash []byte) bool {
+	return Default().NeedsRehash(hash)
+}
+
+// Identify returns the algorithm used to create the hash.
+func Identify(hash []byte) (Algorithm, error) {
+	switch {
+	case isBcrypt(hash):
+		return Bcrypt, nil
+	case bytes.HasPrefix(hash, []byte(md5CryptMagic)):
+		return MD5Crypt, nil
+	case bytes.HasPrefix(hash, []byte(djangoPBKDF2Prefix)):
+		return PBKDF2SHA256, nil
+	case bytes.HasPrefix(hash, []byte("$"+FirebaseScrypt+"$")):
+		return FirebaseScrypt, nil
+	}
+	p, err := parsePHC(string(hash))
+	if err != nil {
+		return "", err
+	}
+	switch alg := Algorithm(p.id); alg {
+	case Argon2id, Scrypt:
+		return alg, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnknownAlgorithm, p.id)
+}
+
+// Import returns a password hash exported from another service in a
+// format that can be stored and verified. If alg is empty it is identified
+// from the hash. Firebase hashes are standard base64 encoded and require the
+// salt and project params. Other hashes must be in their standard format.
+func Import(alg Algorithm, hash, salt string, fp *FirebaseParams) ([]byte, error) {
+	alg = Algorithm(strings.ToLower(string(alg)))
+	if alg == FirebaseScrypt {
+		if fp == nil {
+			return nil, errors.New("firebase params required")
+		}
+		return fp.Encode(hash, salt)
+	}
+	id, err := Identify([]byte(hash))
+	if err != nil {
+		return nil, err
+	}
+	if alg != "" && alg != id {
+		return nil, fmt.Errorf("%w: expected %s hash", ErrInvalidHash, alg)
+	}
+	// parse the hash so malformed hashes are rejected
+	// now and not when the user next tries to login.
+	err = parse(id, []byte(hash))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(hash), nil
+}
+
+// parse returns an error if the hash cannot be parsed.
+func parse(alg Algorithm, hash []byte) (err error) {
+	switch alg {
+	case Argon2id:
+		_, _, _, _, err = parseArgon2(hash)
+	case Bcrypt:
+		_, err = bcrypt.Cost(hash)
+		if err != nil || len(hash) != bcryptHashLength {
+			err = ErrInvalidHash
+		}
+	case Scrypt:
+		_, _, _, _, err = parseScrypt(hash)
+	case FirebaseScrypt:
+		_, err = parseFirebase(hash)
+	case MD5Crypt:
+		salt, ok := parseMD5Crypt(hash)
+		if !ok || len(hash) != len(md5CryptMagic)+len(salt)+md5CryptLength {
+			err = ErrInvalidHash
+		}
+	case PBKDF2SHA256:
+		if _, _, _, ok := parsePBKDF2(hash); !ok {
+			err = ErrInvalidHash
+		}
+	default:
+		err = ErrUnknownAlgorithm
+	}
+	return
+}
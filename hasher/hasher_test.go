@@ -0,0 +1,168 @@
+package hasher
+
+import (
+	"testing"
+
+	"github.com/jrapoport/gothic/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const testPass = "SXJAm7qJ4?3dH!aN8T3f5p!oNnpXbaRy#Gtx#8jG"
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		c   config.Hash
+		alg Algorithm
+		Err assert.ErrorAssertionFunc
+	}{
+		{config.Hash{}, Argon2id, assert.NoError},
+		{config.Hash{Algorithm: "argon2id"}, Argon2id, assert.NoError},
+		{config.Hash{Algorithm: "ARGON2ID"}, Argon2id, assert.NoError},
+		{config.Hash{Algorithm: "bcrypt"}, Bcrypt, assert.NoError},
+		{config.Hash{Algorithm: "scrypt"}, Scrypt, assert.NoError},
+		{config.Hash{
+			Algorithm: "bcrypt",
+			Bcrypt:    config.Bcrypt{Cost: 100},
+		}, "", assert.Error},
+		{config.Hash{
+			Algorithm: "scrypt",
+			Scrypt:    config.Scrypt{N: 100},
+		}, "", assert.Error},
+		{config.Hash{Algorithm: "md5"}, "", assert.Error},
+	}
+	for _, test := range tests {
+		h, err := New(test.c)
+		test.Err(t, err)
+		if err != nil {
+			continue
+		}
+		assert.Equal(t, test.alg, h.Algorithm())
+	}
+}
+
+func TestDefault(t *testing.T) {
+	h := Default()
+	require.NotNil(t, h)
+	assert.Equal(t, Argon2id, h.Algorithm())
+	hash, err := Hash(testPass)
+	require.NoError(t, err)
+	assert.False(t, NeedsRehash(hash))
+	bc, err := NewBcrypt(bcrypt.MinCost)
+	require.NoError(t, err)
+	Use(bc)
+	defer Use(h)
+	Use(nil)
+	assert.Equal(t, Bcrypt, Default().Algorithm())
+	assert.True(t, NeedsRehash(hash))
+	hash2, err := Hash(testPass)
+	require.NoError(t, err)
+	assert.False(t, NeedsRehash(hash2))
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+	bc, err := NewBcrypt(bcrypt.MinCost)
+	require.NoError(t, err)
+	sc, err := NewScrypt(1<<10, 0, 0)
+	require.NoError(t, err)
+	hashers := []Hasher{
+		NewArgon2id(1024, 1, 1),
+		bc,
+		sc,
+	}
+	for _, h := range hashers {
+		hash, err := h.Hash(testPass)
+		require.NoError(t, err)
+		alg, err := Identify(hash)
+		require.NoError(t, err)
+		assert.Equal(t, h.Algorithm(), alg)
+		err = Verify(hash, testPass)
+		assert.NoError(t, err)
+		err = Verify(hash, "bad")
+		assert.ErrorIs(t, err, ErrMismatchedHash)
+	}
+	// legacy bcrypt hash
+	legacy, err := bcrypt.GenerateFromPassword([]byte(testPass), bcrypt.MinCost)
+	require.NoError(t, err)
+	err = Verify(legacy, testPass)
+	assert.NoError(t, err)
+	// bad hashes
+	bad := []string{
+		"",
+		"password",
+		"$md5$salt$hash",
+		"$argon2id$v=19$m=1024,t=1,p=1",
+	}
+	for _, hash := range bad {
+		err = Verify([]byte(hash), testPass)
+		assert.Error(t, err)
+	}
+}
+
+func TestImport(t *testing.T) {
+	t.Parallel()
+	legacy, err := bcrypt.GenerateFromPassword([]byte(testPass), bcrypt.MinCost)
+	require.NoError(t, err)
+	sc, err := NewScrypt(1<<10, 0, 0)
+	require.NoError(t, err)
+	scHash, err := sc.Hash(testPass)
+	require.NoError(t, err)
+	a2Hash, err := NewArgon2id(1024, 1, 1).Hash(testPass)
+	require.NoError(t, err)
+	fbHash, err := testFirebase.Encode(testFirebaseHash, testFirebaseSalt)
+	require.NoError(t, err)
+	tests := []struct {
+		alg  Algorithm
+		hash string
+		pw   string
+	}{
+		{"", string(legacy), testPass},
+		{Bcrypt, string(legacy), testPass},
+		{Argon2id, string(a2Hash), testPass},
+		{Scrypt, string(scHash), testPass},
+		{"", "$1$ab$t00rsSdYwJXPEvSlIBoBQ0", testPass},
+		{MD5Crypt, "$1$ab$t00rsSdYwJXPEvSlIBoBQ0", testPass},
+		{"", "pbkdf2_sha256$10000$seasalt42$lnv0/PWydD3t+BQPaeQWVQWFFhdDE+Ed38c19dlOXfQ=", testPass},
+		{"PBKDF2-SHA256", "pbkdf2_sha256$10000$seasalt42$lnv0/PWydD3t+BQPaeQWVQWFFhdDE+Ed38c19dlOXfQ=", testPass},
+		{"", string(fbHash), testFirebasePass},
+	}
+	for _, test := range tests {
+		hash, err := Import(test.alg, test.hash, "", nil)
+		require.NoError(t, err, test.hash)
+		assert.Equal(t, test.hash, string(hash))
+		err = Verify(hash, test.pw)
+		assert.NoError(t, err)
+		assert.True(t, NewArgon2id(2048, 1, 1).NeedsRehash(hash))
+	}
+	// firebase
+	hash, err := Import(FirebaseScrypt, testFirebaseHash, testFirebaseSalt, &testFirebase)
+	require.NoError(t, err)
+	assert.Equal(t, fbHash, hash)
+	err = Verify(hash, testFirebasePass)
+	assert.NoError(t, err)
+	_, err = Import(FirebaseScrypt, testFirebaseHash, testFirebaseSalt, nil)
+	assert.Error(t, err)
+	// errors
+	bad := []struct {
+		alg  Algorithm
+		hash string
+	}{
+		{"", ""},
+		{"", "password"},
+		{"", "$md5$salt$hash"},
+		{Bcrypt, "$1$ab$t00rsSdYwJXPEvSlIBoBQ0"},
+		{"", "$2a$10$bad"},
+		{"", "$1$ab$t00rsSdYw"},
+		{"", "pbkdf2_sha256$10000$seasalt42"},
+		{"", "$argon2id$v=19$m=1024,t=1,p=1"},
+		{"", "$scrypt$ln=10,r=8,p=1"},
+		{"", "$firebase-scrypt$ln=14,r=8$c2FsdA"},
+	}
+	for _, test := range bad {
+		_, err = Import(test.alg, test.hash, "", nil)
+		assert.Error(t, err, test.hash)
+	}
+}
@@ -0,0 +1,107 @@
+package hasher
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/subtle"
+)
+
+const (
+	md5CryptMagic   = "$1$"
+	md5CryptSaltMax = 8
+	md5CryptRounds  = 1000
+	md5CryptLength  = 23 // '$' + 22 encoded bytes
+	cryptAlphabet   = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+// verifyMD5Crypt returns nil if the password matches the MD5-crypt hash.
+func verifyMD5Crypt(hash []byte, password string) error {
+	salt, ok := parseMD5Crypt(hash)
+	if !ok {
+		return ErrInvalidHash
+	}
+	sum := md5Crypt([]byte(password), salt)
+	if subtle.ConstantTimeCompare(sum, hash) != 1 {
+		return ErrMismatchedHash
+	}
+	return nil
+}
+
+func parseMD5Crypt(hash []byte) ([]byte, bool) {
+	if !bytes.HasPrefix(hash, []byte(md5CryptMagic)) {
+		return nil, false
+	}
+	rest := hash[len(md5CryptMagic):]
+	i := bytes.IndexByte(rest, '$')
+	if i < 0 || i > md5CryptSaltMax {
+		return nil, false
+	}
+	return rest[:i], true
+}
+
+// md5Crypt is the FreeBSD MD5-crypt algorithm.
+// see: https://svnweb.freebsd.org/base/head/lib/libcrypt/crypt-md5.c
+func md5Crypt(password, salt []byte) []byte {
+	d := md5.New()
+	d.Write(password)
+	d.Write([]byte(md5CryptMagic))
+	d.Write(salt)
+	alt := md5.New()
+	alt.Write(password)
+	alt.Write(salt)
+	alt.Write(password)
+	mix := alt.Sum(nil)
+	for i := len(password); i > 0; i -= md5.Size {
+		n := i
+		if n > md5.Size {
+			n = md5.Size
+		}
+		d.Write(mix[:n])
+	}
+	for i := len(password); i > 0; i >>= 1 {
+		if i&1 != 0 {
+			d.Write([]byte{0})
+		} else {
+			d.Write(password[:1])
+		}
+	}
+	sum := d.Sum(nil)
+	for i := 0; i < md5CryptRounds; i++ {
+		r := md5.New()
+		if i&1 != 0 {
+			r.Write(password)
+		} else {
+			r.Write(sum)
+		}
+		if i%3 != 0 {
+			r.Write(salt)
+		}
+		if i%7 != 0 {
+			r.Write(password)
+		}
+		if i&1 != 0 {
+			r.Write(sum)
+		} else {
+			r.Write(password)
+		}
+		sum = r.Sum(nil)
+	}
+	out := make([]byte, 0, len(md5CryptMagic)+len(salt)+md5CryptLength)
+	out = append(out, md5CryptMagic...)
+	out = append(out, salt...)
+	out = append(out, '$')
+	encode := func(a, b, c byte, n int) {
+		v := uint(a)<<16 | uint(b)<<8 | uint(c)
+		for ; n > 0; n-- {
+			out = append(out, cryptAlphabet[v&0x3f])
+			v >>= 6
+		}
+	}
+	encode(sum[0], sum[6], sum[12], 4)
+	encode(sum[1], sum[7], sum[13], 4)
+	encode(sum[2], sum[8], sum[14], 4)
+	encode(sum[3], sum[9], sum[15], 4)
+	encode(sum[4], sum[10], sum[5], 4)
+	encode(0, 0, sum[11], 2)
+	return out
+}
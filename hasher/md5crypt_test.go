@@ -0,0 +1,35 @@
+package hasher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMD5Crypt(t *testing.T) {
+	t.Parallel()
+	// generated with: openssl passwd -1 -salt [SALT] [PASSWORD]
+	tests := []struct {
+		hash string
+		pw   string
+	}{
+		{"$1$saltsalt$qjXMvbEw8oaL.CzflDtaK/", "password"},
+		{"$1$ab$t00rsSdYwJXPEvSlIBoBQ0", testPass},
+	}
+	for _, test := range tests {
+		err := verifyMD5Crypt([]byte(test.hash), test.pw)
+		assert.NoError(t, err)
+		err = verifyMD5Crypt([]byte(test.hash), "bad")
+		assert.ErrorIs(t, err, ErrMismatchedHash)
+	}
+	bad := []string{
+		"",
+		"$2a$saltsalt$qjXMvbEw8oaL.CzflDtaK/",
+		"$1$saltsalt",
+		"$1$saltsaltsalt$qjXMvbEw8oaL.CzflDtaK/",
+	}
+	for _, b := range bad {
+		err := verifyMD5Crypt([]byte(b), "password")
+		assert.ErrorIs(t, err, ErrInvalidHash, b)
+	}
+}
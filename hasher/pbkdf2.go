@@ -0,0 +1,49 @@
+package hasher
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// djangoPBKDF2Prefix is the prefix of a Django pbkdf2_sha256 hash:
+//
+//	pbkdf2_sha256$<iterations>$<salt>$<base64 hash>
+const djangoPBKDF2Prefix = "pbkdf2_sha256$"
+
+// verifyPBKDF2 returns nil if the password matches the Django PBKDF2-SHA256 hash.
+func verifyPBKDF2(hash []byte, password string) error {
+	iter, salt, key, ok := parsePBKDF2(hash)
+	if !ok {
+		return ErrInvalidHash
+	}
+	sum := pbkdf2.Key([]byte(password), salt, iter, len(key), sha256.New)
+	if subtle.ConstantTimeCompare(sum, key) != 1 {
+		return ErrMismatchedHash
+	}
+	return nil
+}
+
+func parsePBKDF2(hash []byte) (iter int, salt, key []byte, ok bool) {
+	if !bytes.HasPrefix(hash, []byte(djangoPBKDF2Prefix)) {
+		return
+	}
+	parts := strings.Split(string(hash), "$")
+	if len(parts) != 4 {
+		return
+	}
+	iter, err := strconv.Atoi(parts[1])
+	if err != nil || iter <= 0 || parts[2] == "" {
+		return
+	}
+	key, err = base64.StdEncoding.DecodeString(parts[3])
+	if err != nil || len(key) == 0 {
+		return
+	}
+	return iter, []byte(parts[2]), key, true
+}
@@ -0,0 +1,30 @@
+package hasher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPBKDF2(t *testing.T) {
+	t.Parallel()
+	// generated with: python3 hashlib.pbkdf2_hmac("sha256", ...)
+	const hash = "pbkdf2_sha256$10000$seasalt42$lnv0/PWydD3t+BQPaeQWVQWFFhdDE+Ed38c19dlOXfQ="
+	err := verifyPBKDF2([]byte(hash), testPass)
+	assert.NoError(t, err)
+	err = verifyPBKDF2([]byte(hash), "bad")
+	assert.ErrorIs(t, err, ErrMismatchedHash)
+	bad := []string{
+		"",
+		"pbkdf2_sha1$10000$seasalt42$lnv0/PWydD3t+BQPaeQWVQWFFhdDE+Ed38c19dlOXfQ=",
+		"pbkdf2_sha256$10000$seasalt42",
+		"pbkdf2_sha256$a$seasalt42$lnv0/PWydD3t+BQPaeQWVQWFFhdDE+Ed38c19dlOXfQ=",
+		"pbkdf2_sha256$0$seasalt42$lnv0/PWydD3t+BQPaeQWVQWFFhdDE+Ed38c19dlOXfQ=",
+		"pbkdf2_sha256$10000$$lnv0/PWydD3t+BQPaeQWVQWFFhdDE+Ed38c19dlOXfQ=",
+		"pbkdf2_sha256$10000$seasalt42$!",
+	}
+	for _, b := range bad {
+		err = verifyPBKDF2([]byte(b), testPass)
+		assert.ErrorIs(t, err, ErrInvalidHash, b)
+	}
+}
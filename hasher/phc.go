@@ -0,0 +1,117 @@
+package hasher
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// phc is a hash in the PHC string format:
+//
+//	$<id>[$v=<version>][$<param>=<value>(,<param>=<value>)*][$<salt>[$<hash>]]
+//
+// see: https://github.com/P-H-C/phc-string-format/blob/master/phc-sf-spec.md
+type phc struct {
+	id      string
+	version int
+	params  map[string]int
+	salt    []byte
+	hash    []byte
+}
+
+var b64 = base64.RawStdEncoding
+
+func parsePHC(s string) (*phc, error) {
+	parts := strings.Split(s, "$")
+	if len(parts) < 2 || parts[0] != "" || parts[1] == "" {
+		return nil, ErrInvalidHash
+	}
+	p := &phc{id: parts[1], params: map[string]int{}}
+	parts = parts[2:]
+	if len(parts) > 0 && strings.HasPrefix(parts[0], "v=") {
+		v, err := strconv.Atoi(strings.TrimPrefix(parts[0], "v="))
+		if err != nil {
+			return nil, ErrInvalidHash
+		}
+		p.version = v
+		parts = parts[1:]
+	}
+	if len(parts) > 0 && strings.Contains(parts[0], "=") {
+		for _, kv := range strings.Split(parts[0], ",") {
+			k, v, ok := strings.Cut(kv, "=")
+			if !ok || k == "" {
+				return nil, ErrInvalidHash
+			}
+			i, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, ErrInvalidHash
+			}
+			p.params[k] = i
+		}
+		parts = parts[1:]
+	}
+	if len(parts) > 2 {
+		return nil, ErrInvalidHash
+	}
+	var err error
+	if len(parts) > 0 {
+		p.salt, err = b64.DecodeString(parts[0])
+		if err != nil {
+			return nil, ErrInvalidHash
+		}
+	}
+	if len(parts) > 1 {
+		p.hash, err = b64.DecodeString(parts[1])
+		if err != nil {
+			return nil, ErrInvalidHash
+		}
+	}
+	return p, nil
+}
+
+// param returns the named parameter or an error if it is missing.
+func (p *phc) param(name string) (int, error) {
+	v, ok := p.params[name]
+	if !ok {
+		return 0, fmt.Errorf("%w: missing %s", ErrInvalidHash, name)
+	}
+	return v, nil
+}
+
+// format returns the PHC string. params are written in the
+// order given, as the order is significant for some algorithms.
+func (p *phc) format(order ...string) string {
+	var sb strings.Builder
+	sb.WriteString("$" + p.id)
+	if p.version > 0 {
+		sb.WriteString("$v=" + strconv.Itoa(p.version))
+	}
+	if len(order) > 0 {
+		kv := make([]string, len(order))
+		for i, k := range order {
+			kv[i] = k + "=" + strconv.Itoa(p.params[k])
+		}
+		sb.WriteString("$" + strings.Join(kv, ","))
+	}
+	if p.salt != nil {
+		sb.WriteString("$" + b64.EncodeToString(p.salt))
+		if p.hash != nil {
+			sb.WriteString("$" + b64.EncodeToString(p.hash))
+		}
+	}
+	return sb.String()
+}
+
+const saltLength = 16
+
+func newSalt() ([]byte, error) {
+	salt := make([]byte, saltLength)
+	_, err := io.ReadFull(rand.Reader, salt)
+	if err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
@@ -0,0 +1,59 @@
+package hasher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPHC(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		s       string
+		id      string
+		version int
+		params  map[string]int
+		order   []string
+		salt    string
+		hash    string
+	}{
+		{"$test", "test", 0, map[string]int{}, nil, "", ""},
+		{"$test$v=1", "test", 1, map[string]int{}, nil, "", ""},
+		{"$test$a=1,b=2", "test", 0,
+			map[string]int{"a": 1, "b": 2}, []string{"a", "b"}, "", ""},
+		{"$test$v=1$a=1$c2FsdA", "test", 1,
+			map[string]int{"a": 1}, []string{"a"}, "salt", ""},
+		{"$test$v=1$a=1$c2FsdA$aGFzaA", "test", 1,
+			map[string]int{"a": 1}, []string{"a"}, "salt", "hash"},
+	}
+	for _, test := range tests {
+		p, err := parsePHC(test.s)
+		require.NoError(t, err)
+		assert.Equal(t, test.id, p.id)
+		assert.Equal(t, test.version, p.version)
+		assert.Equal(t, test.params, p.params)
+		assert.Equal(t, test.salt, string(p.salt))
+		assert.Equal(t, test.hash, string(p.hash))
+		assert.Equal(t, test.s, p.format(test.order...))
+	}
+	bad := []string{
+		"",
+		"test",
+		"$",
+		"$test$v=a",
+		"$test$a=b",
+		"$test$=1",
+		"$test$salt!",
+		"$test$c2FsdA$hash!",
+		"$test$c2FsdA$aGFzaA$extra",
+	}
+	for _, s := range bad {
+		_, err := parsePHC(s)
+		assert.ErrorIs(t, err, ErrInvalidHash, s)
+	}
+	p, err := parsePHC("$test$a=1")
+	require.NoError(t, err)
+	_, err = p.param("b")
+	assert.ErrorIs(t, err, ErrInvalidHash)
+}
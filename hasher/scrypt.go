@@ -0,0 +1,129 @@
+package hasher
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"math/bits"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// scrypt defaults
+const (
+	ScryptN         = 1 << 15
+	ScryptR         = 8
+	ScryptP         = 1
+	scryptKeyLength = 32
+)
+
+type scryptHasher struct {
+	n int
+	r int
+	p int
+}
+
+var _ Hasher = (*scryptHasher)(nil)
+
+// NewScrypt returns a new scrypt Hasher. Zero values use the defaults.
+func NewScrypt(n, r, p int) (Hasher, error) {
+	if n == 0 {
+		n = ScryptN
+	}
+	if r == 0 {
+		r = ScryptR
+	}
+	if p == 0 {
+		p = ScryptP
+	}
+	if n <= 1 || n&(n-1) != 0 {
+		return nil, fmt.Errorf("invalid scrypt n: %d (must be a power of 2)", n)
+	}
+	if r < 1 || p < 1 || uint64(r)*uint64(p) >= 1<<30 {
+		return nil, fmt.Errorf("invalid scrypt parameters: r=%d p=%d", r, p)
+	}
+	return &scryptHasher{n, r, p}, nil
+}
+
+// Algorithm returns Scrypt.
+func (scryptHasher) Algorithm() Algorithm {
+	return Scrypt
+}
+
+// Hash returns the PHC formatted scrypt hash of the password. The
+// cost is written as ln, the base 2 logarithm of n.
+func (h scryptHasher) Hash(password string) ([]byte, error) {
+	salt, err := newSalt()
+	if err != nil {
+		return nil, err
+	}
+	key, err := scrypt.Key([]byte(password), salt, h.n, h.r, h.p, scryptKeyLength)
+	if err != nil {
+		return nil, err
+	}
+	p := &phc{
+		id: string(Scrypt),
+		params: map[string]int{
+			"ln": bits.TrailingZeros(uint(h.n)),
+			"r":  h.r,
+			"p":  h.p,
+		},
+		salt: salt,
+		hash: key,
+	}
+	return []byte(p.format("ln", "r", "p")), nil
+}
+
+// Verify returns nil if the password matches the scrypt hash.
+func (scryptHasher) Verify(hash []byte, password string) error {
+	p, n, r, par, err := parseScrypt(hash)
+	if err != nil {
+		return err
+	}
+	key, err := scrypt.Key([]byte(password), p.salt, n, r, par, len(p.hash))
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(key, p.hash) != 1 {
+		return ErrMismatchedHash
+	}
+	return nil
+}
+
+// NeedsRehash returns true if the hash is not a scrypt hash
+// with the same parameters as the hasher.
+func (h scryptHasher) NeedsRehash(hash []byte) bool {
+	p, n, r, par, err := parseScrypt(hash)
+	if err != nil {
+		return true
+	}
+	return n != h.n || r != h.r || par != h.p ||
+		len(p.hash) != scryptKeyLength
+}
+
+func parseScrypt(hash []byte) (p *phc, n, r, par int, err error) {
+	p, err = parsePHC(string(hash))
+	if err != nil {
+		return
+	}
+	if p.id != string(Scrypt) {
+		err = ErrInvalidHash
+		return
+	}
+	var ln int
+	if ln, err = p.param("ln"); err != nil {
+		return
+	}
+	if r, err = p.param("r"); err != nil {
+		return
+	}
+	if par, err = p.param("p"); err != nil {
+		return
+	}
+	if ln < 1 || ln > 62 || r < 1 || par < 1 ||
+		len(p.salt) == 0 || len(p.hash) == 0 {
+		err = ErrInvalidHash
+		return
+	}
+	n = 1 << ln
+	return
+}
@@ -0,0 +1,60 @@
+package hasher
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScrypt(t *testing.T) {
+	t.Parallel()
+	h, err := NewScrypt(0, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, Scrypt, h.Algorithm())
+	assert.Equal(t, &scryptHasher{ScryptN, ScryptR, ScryptP}, h)
+	_, err = NewScrypt(1, 0, 0)
+	assert.Error(t, err)
+	_, err = NewScrypt(1000, 0, 0)
+	assert.Error(t, err)
+	_, err = NewScrypt(0, -1, 0)
+	assert.Error(t, err)
+	_, err = NewScrypt(0, 1<<15, 1<<15)
+	assert.Error(t, err)
+	h, err = NewScrypt(1<<10, 4, 2)
+	require.NoError(t, err)
+	hash, err := h.Hash(testPass)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(hash), "$scrypt$ln=10,r=4,p=2$"))
+	err = h.Verify(hash, testPass)
+	assert.NoError(t, err)
+	err = h.Verify(hash, "bad")
+	assert.ErrorIs(t, err, ErrMismatchedHash)
+	assert.False(t, h.NeedsRehash(hash))
+	// params changed
+	for _, p := range [][3]int{{1 << 11, 4, 2}, {1 << 10, 8, 2}, {1 << 10, 4, 1}} {
+		h2, err := NewScrypt(p[0], p[1], p[2])
+		require.NoError(t, err)
+		assert.True(t, h2.NeedsRehash(hash))
+	}
+	// algorithm changed
+	a2Hash, err := NewArgon2id(1024, 1, 1).Hash(testPass)
+	require.NoError(t, err)
+	assert.True(t, h.NeedsRehash(a2Hash))
+	err = h.Verify(a2Hash, testPass)
+	assert.ErrorIs(t, err, ErrInvalidHash)
+	// bad hashes
+	bad := []string{
+		"$scrypt$r=4,p=2$c2FsdA$aGFzaA",
+		"$scrypt$ln=10,p=2$c2FsdA$aGFzaA",
+		"$scrypt$ln=10,r=4$c2FsdA$aGFzaA",
+		"$scrypt$ln=0,r=4,p=2$c2FsdA$aGFzaA",
+		"$scrypt$ln=10,r=4,p=2$c2FsdA",
+	}
+	for _, b := range bad {
+		err = h.Verify([]byte(b), testPass)
+		assert.ErrorIs(t, err, ErrInvalidHash, b)
+		assert.True(t, h.NeedsRehash([]byte(b)))
+	}
+}
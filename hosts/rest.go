@@ -6,7 +6,9 @@ import (
 	"github.com/jrapoport/gothic/hosts/rest/account"
 	"github.com/jrapoport/gothic/hosts/rest/admin"
 	"github.com/jrapoport/gothic/hosts/rest/health"
+	"github.com/jrapoport/gothic/hosts/rest/oauth"
 	"github.com/jrapoport/gothic/hosts/rest/user"
+	"github.com/jrapoport/gothic/hosts/rest/wellknown"
 )
 
 const restName = "rest"
@@ -16,8 +18,10 @@ func NewRESTHost(a *core.API, address string) core.Hosted {
 	return rest.NewHost(a, restName, address,
 		[]rest.RegisterServer{
 			health.RegisterServer,
+			wellknown.RegisterServer,
 			admin.RegisterServer,
 			account.RegisterServer,
+			oauth.RegisterServer,
 			user.RegisterServer,
 		})
 }
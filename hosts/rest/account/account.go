@@ -9,6 +9,7 @@ import (
 	"github.com/jrapoport/gothic/hosts/rest/account/login"
 	"github.com/jrapoport/gothic/hosts/rest/account/password"
 	"github.com/jrapoport/gothic/hosts/rest/account/signup"
+	"github.com/jrapoport/gothic/hosts/rest/account/unlock"
 )
 
 // Account endpoint
@@ -42,5 +43,6 @@ func (s *accountServer) addRoutes(r *rest.Router) {
 		login.RegisterServer(&http.Server{Handler: rt}, s.Clone())
 		password.RegisterServer(&http.Server{Handler: rt}, s.Clone())
 		signup.RegisterServer(&http.Server{Handler: rt}, s.Clone())
+		unlock.RegisterServer(&http.Server{Handler: rt}, s.Clone())
 	})
 }
@@ -4,7 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/jrapoport/gothic/core/auth"
 	"github.com/jrapoport/gothic/hosts/rest"
 	"github.com/jrapoport/gothic/models/types/key"
 	"github.com/jrapoport/gothic/models/types/provider"
@@ -22,10 +24,24 @@ const (
 	Callback = "/callback"
 )
 
+// BindingCookie holds the secret that binds an auth url to the browser that
+// requested it when the client does not use a PKCE code challenge.
+const BindingCookie = "gothic_auth"
+
+// bindingExpiration is the max age of the binding cookie.
+const bindingExpiration = 60 * time.Minute
+
+// URLRequest is an auth url request
+type URLRequest struct {
+	CodeChallenge   string `json:"code_challenge" form:"code_challenge"`
+	ChallengeMethod string `json:"code_challenge_method" form:"code_challenge_method"`
+}
+
 // Request is an auth server request
 type Request struct {
-	State string `json:"state" form:"state"`
-	Token string `json:"token" form:"token"`
+	State        string `json:"state" form:"state"`
+	Token        string `json:"token" form:"token"`
+	CodeVerifier string `json:"code_verifier" form:"code_verifier"`
 }
 
 type authServer struct {
@@ -64,14 +80,31 @@ func (s *authServer) GetAuthorizationURL(w http.ResponseWriter, r *http.Request)
 		s.ResponseCode(w, http.StatusBadRequest, err)
 		return
 	}
-	s.Debugf("get authorization url for %s: (%v)", p)
+	req := new(URLRequest)
+	err := rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	s.Debugf("get authorization url for %s: (%v)", p, req)
 	ctx := rest.FromRequest(r)
 	ctx.SetProvider(p)
-	au, err := s.API.GetAuthorizationURL(ctx, p)
+	b := auth.Binding{
+		CodeChallenge:   req.CodeChallenge,
+		ChallengeMethod: req.ChallengeMethod,
+	}
+	// without a code challenge the auth url is bound to the browser
+	if b.CodeChallenge == "" {
+		b.Secret = utils.SecureToken()
+	}
+	au, err := s.API.GetAuthorizationURL(ctx, p, b)
 	if err != nil {
 		s.ResponseError(w, err)
 		return
 	}
+	if b.Secret != "" {
+		setBindingCookie(w, b.Secret)
+	}
 	s.Debugf("got authorization url: %s", au)
 	http.Redirect(w, r, au, http.StatusFound)
 }
@@ -89,12 +122,19 @@ func (s *authServer) AuthorizeUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	data := utils.URLValuesToMap(r.Form, false)
+	// the code verifier is for us, not the provider
+	delete(data, key.CodeVerifier)
+	v := auth.Verifier{CodeVerifier: req.CodeVerifier}
+	if c, err := r.Cookie(BindingCookie); err == nil {
+		v.Secret = c.Value
+	}
 	ctx := rest.FromRequest(r)
-	u, err := s.API.AuthorizeUser(ctx, req.State, data)
+	u, err := s.API.AuthorizeUser(ctx, req.State, data, v)
 	if err != nil {
 		s.AuthError(w, err)
 		return
 	}
+	clearBindingCookie(w)
 	bt, err := s.GrantBearerToken(ctx, u)
 	if err != nil {
 		s.AuthError(w, err)
@@ -108,3 +148,29 @@ func (s *authServer) AuthorizeUser(w http.ResponseWriter, r *http.Request) {
 	s.Debugf("authorized user: %v", res)
 	s.AuthResponse(w, r, bt.String(), res)
 }
+
+func setBindingCookie(w http.ResponseWriter, secret string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     BindingCookie,
+		Value:    secret,
+		Path:     rest.Root,
+		Expires:  time.Now().UTC().Add(bindingExpiration),
+		MaxAge:   int(bindingExpiration / time.Second),
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func clearBindingCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     BindingCookie,
+		Value:    "",
+		Path:     rest.Root,
+		Expires:  time.Unix(0, 0),
+		MaxAge:   -1,
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
@@ -1,7 +1,8 @@
 package auth_test
 
 import (
-	"fmt"
+	"crypto/sha256"
+	"encoding/base64"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -12,6 +13,7 @@ import (
 	"github.com/jrapoport/gothic/core/tokens"
 	"github.com/jrapoport/gothic/hosts/rest"
 	"github.com/jrapoport/gothic/hosts/rest/account/auth"
+	"github.com/jrapoport/gothic/models/token"
 	"github.com/jrapoport/gothic/models/types/key"
 	"github.com/jrapoport/gothic/models/types/provider"
 	"github.com/jrapoport/gothic/test/tconf"
@@ -34,13 +36,42 @@ func getToken(t *testing.T, authURL string) string {
 }
 
 func DoProviderURLRequest(t *testing.T, web *httptest.Server, p provider.Name) string {
+	authURL, _ := DoBoundURLRequest(t, web, p, nil)
+	return authURL
+}
+
+// DoBoundURLRequest returns the auth url and the binding cookie if one was set.
+func DoBoundURLRequest(t *testing.T, web *httptest.Server, p provider.Name, v url.Values) (string, *http.Cookie) {
 	rec := httptest.NewRecorder()
 	handler := http.HandlerFunc(web.Config.Handler.ServeHTTP)
-	req := thttp.Request(t, http.MethodGet, providerPath(p),
-		"", nil, nil)
+	path := providerPath(p)
+	if v != nil {
+		path += "?" + v.Encode()
+	}
+	req := thttp.Request(t, http.MethodGet, path, "", nil, nil)
 	handler.ServeHTTP(rec, req)
 	require.Equal(t, http.StatusFound, rec.Code)
-	return rec.Header().Get("location")
+	var cookie *http.Cookie
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == auth.BindingCookie {
+			cookie = c
+		}
+	}
+	return rec.Header().Get("location"), cookie
+}
+
+// DoCallbackRequest posts the auth url to the callback with the cookie.
+// Auth errors are hidden, so a failed request has an empty response.
+func DoCallbackRequest(t *testing.T, web *httptest.Server, authURL string, cookie *http.Cookie) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler := http.HandlerFunc(web.Config.Handler.ServeHTTP)
+	authURL = strings.Replace(authURL, web.URL, "", 1)
+	req := thttp.Request(t, http.MethodPost, authURL, "", nil, nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	handler.ServeHTTP(rec, req)
+	return rec
 }
 
 func TestAuthServer_GetAuthorizationURL(t *testing.T) {
@@ -82,12 +113,32 @@ func TestAuthServer_AuthorizeUser(t *testing.T) {
 	assert.HTTPError(t, web.Config.Handler.ServeHTTP, http.MethodPost, callbackURL, nil)
 	_, mock := tconf.MockedProvider(t, srv.Config(), callbackURL)
 	srv.Providers().UseProviders(mock)
-	urlReq := DoProviderURLRequest(t, web, provider.Name(mock.Name()))
-	urlReq = strings.Replace(urlReq, web.URL, "", 1)
+	p := provider.Name(mock.Name())
+	urlReq, cookie := DoBoundURLRequest(t, web, p, nil)
+	require.NotNil(t, cookie)
+	assert.True(t, cookie.HttpOnly)
+	assert.True(t, cookie.Secure)
+	assert.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
 	urlReq += "&test-value=expected"
-	res, err := thttp.DoRequest(t, web, http.MethodPost, urlReq, nil, nil)
-	assert.NoError(t, err, res)
-	fmt.Println(res)
+	// no binding cookie
+	rec := DoCallbackRequest(t, web, urlReq, nil)
+	assert.Empty(t, rec.Body.String())
+	// another browser
+	_, other := DoBoundURLRequest(t, web, p, nil)
+	rec = DoCallbackRequest(t, web, urlReq, other)
+	assert.Empty(t, rec.Body.String())
+	rec = DoCallbackRequest(t, web, urlReq, cookie)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	res := rec.Body.String()
+	// the binding cookie is cleared
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, auth.BindingCookie, cookies[0].Name)
+	assert.Less(t, cookies[0].MaxAge, 0)
+	assert.Equal(t, cookie.Path, cookies[0].Path)
+	assert.True(t, cookies[0].HttpOnly)
+	assert.True(t, cookies[0].Secure)
+	assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
 	tr, claims := tsrv.UnmarshalUserResponse(t, srv.Config().JWT, res)
 	assert.EqualValues(t, tokens.Bearer, tr.Token.Type)
 	uid, err := uuid.Parse(claims.Subject())
@@ -99,3 +150,42 @@ func TestAuthServer_AuthorizeUser(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, u.ID, au.ID)
 }
+
+func TestAuthServer_AuthorizeUser_PKCE(t *testing.T) {
+	t.Parallel()
+	const verifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	srv, web, _ := tsrv.RESTHost(t, []rest.RegisterServer{
+		auth.RegisterServer,
+	}, false)
+	var callbackURL = web.URL + auth.Auth + auth.Callback
+	_, mock := tconf.MockedProvider(t, srv.Config(), callbackURL)
+	srv.Providers().UseProviders(mock)
+	p := provider.Name(mock.Name())
+	sum := sha256.Sum256([]byte(verifier))
+	v := url.Values{
+		key.CodeChallenge:   {base64.RawURLEncoding.EncodeToString(sum[:])},
+		key.ChallengeMethod: {token.ChallengeS256},
+	}
+	// bad challenge method
+	rec := httptest.NewRecorder()
+	path := providerPath(p) + "?" + url.Values{
+		key.CodeChallenge:   {verifier},
+		key.ChallengeMethod: {"bad"},
+	}.Encode()
+	req := thttp.Request(t, http.MethodGet, path, "", nil, nil)
+	web.Config.Handler.ServeHTTP(rec, req)
+	assert.NotEqual(t, http.StatusFound, rec.Code)
+	// no cookie is set with a code challenge
+	urlReq, cookie := DoBoundURLRequest(t, web, p, v)
+	assert.Nil(t, cookie)
+	// no code verifier
+	rec = DoCallbackRequest(t, web, urlReq, nil)
+	assert.Empty(t, rec.Body.String())
+	// bad code verifier
+	rec = DoCallbackRequest(t, web, urlReq+"&"+key.CodeVerifier+"=bad", nil)
+	assert.Empty(t, rec.Body.String())
+	rec = DoCallbackRequest(t, web, urlReq+"&"+key.CodeVerifier+"="+verifier, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	tr, _ := tsrv.UnmarshalUserResponse(t, srv.Config().JWT, rec.Body.String())
+	assert.EqualValues(t, tokens.Bearer, tr.Token.Type)
+}
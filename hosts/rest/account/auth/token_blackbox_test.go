@@ -24,6 +24,7 @@ func TestAuthServer_RefreshBearerToken(t *testing.T) {
 		auth.RegisterServer,
 	}, false)
 	srv.Config().Signup.AutoConfirm = true
+	srv.Config().Refresh.Grace = 0
 	u, _ := tcore.TestUser(t, srv.API, "", false)
 	bt, err := srv.GrantBearerToken(context.Background(), u)
 	require.NoError(t, err)
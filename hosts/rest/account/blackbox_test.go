@@ -97,7 +97,7 @@ func TestAccountServer(t *testing.T) {
 	assert.NoError(t, err)
 	require.NotEmpty(t, res)
 	// we were logged in
-	_, claims := tsrv.UnmarshalTokenResponse(t, c.JWT, res)
+	_, claims := tsrv.UnmarshalUserResponse(t, c.JWT, res)
 	assert.Equal(t, u.ID.String(), claims.Subject())
 	// check user again
 	u, err = srv.GetUserWithEmail(em)
@@ -131,7 +131,7 @@ func TestAccountServer(t *testing.T) {
 	res, err = thttp.DoRequest(t, web, http.MethodPost, route, nil, pr)
 	assert.NoError(t, err)
 	// check that we were logged again
-	_, claims = tsrv.UnmarshalTokenResponse(t, c.JWT, res)
+	_, claims = tsrv.UnmarshalUserResponse(t, c.JWT, res)
 	assert.Equal(t, u.ID.String(), claims.Subject())
 	// login with the >new< password
 	route = account.Account + login.Login
@@ -67,13 +67,8 @@ func (s *confirmServer) ConfirmUser(w http.ResponseWriter, r *http.Request) {
 		s.ResponseError(w, err)
 		return
 	}
-	bt, err := s.GrantBearerToken(ctx, u)
-	if err != nil {
-		s.AuthError(w, err)
-		return
-	}
-	s.Debugf("confirmed user: %s", bt.UserID)
-	s.AuthResponse(w, r, bt.Token, bt)
+	s.Debugf("confirmed user: %s", u.ID)
+	s.Authorize(w, r, ctx, u)
 }
 
 // SendConfirmUser resends a confirmation email to a user.
@@ -4,18 +4,24 @@ import (
 	"net/http"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/jrapoport/gothic/core/context"
 	"github.com/jrapoport/gothic/core/tokens"
 	"github.com/jrapoport/gothic/core/validate"
 	"github.com/jrapoport/gothic/hosts/rest"
 	"github.com/jrapoport/gothic/hosts/rest/account/login"
+	"github.com/jrapoport/gothic/mail/template"
+	"github.com/jrapoport/gothic/models/token"
 	"github.com/jrapoport/gothic/models/types/key"
 	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconf"
+	"github.com/jrapoport/gothic/test/tcore"
 	"github.com/jrapoport/gothic/test/thttp"
 	"github.com/jrapoport/gothic/test/tsrv"
 	"github.com/jrapoport/gothic/test/tutils"
 	"github.com/jrapoport/gothic/utils"
+	"github.com/segmentio/encoding/json"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -115,3 +121,309 @@ func TestLoginServer_Login_Recaptcha(t *testing.T) {
 	_, err = thttp.DoRequest(t, web, http.MethodPost, login.Login, v, nil)
 	assert.NoError(t, err)
 }
+
+func TestLoginServer_Login_Lockout(t *testing.T) {
+	t.Parallel()
+	srv, web, _ := tsrv.RESTHost(t, []rest.RegisterServer{
+		login.RegisterServer,
+	}, false)
+	srv.Config().Lockout.Delay = time.Minute
+	u := testUser(t, srv)
+	req := &testRequest{
+		Email:    u.Email,
+		Password: "bad",
+	}
+	_, err := thttp.DoRequest(t, web, http.MethodPost, login.Login, nil, req)
+	assert.EqualError(t, err, thttp.FmtError(http.StatusUnauthorized).Error())
+	// too early
+	req.Password = testPass
+	_, err = thttp.DoRequest(t, web, http.MethodPost, login.Login, nil, req)
+	assert.EqualError(t, err, thttp.FmtError(http.StatusTooEarly).Error())
+}
+
+func TestLoginServer_Login_MFA(t *testing.T) {
+	t.Parallel()
+	srv, web, _ := tsrv.RESTHost(t, []rest.RegisterServer{
+		login.RegisterServer,
+	}, false)
+	srv.Config().Signup.AutoConfirm = true
+	u, _ := tcore.TestUser(t, srv.API, testPass, false)
+	secret := tcore.EnrollTOTP(t, srv.API, u)
+	req := &testRequest{
+		Email:    u.Email,
+		Password: testPass,
+	}
+	res, err := thttp.DoRequest(t, web, http.MethodPost, login.Login, nil, req)
+	require.NoError(t, err)
+	var ur rest.UserResponse
+	err = json.Unmarshal([]byte(res), &ur)
+	require.NoError(t, err)
+	assert.Nil(t, ur.Token)
+	require.NotNil(t, ur.MFA)
+	assert.EqualValues(t, token.MFA, ur.MFA.Type)
+	assert.NotEmpty(t, ur.MFA.Token)
+	assert.NotNil(t, ur.MFA.ExpiresAt)
+	// invalid req
+	_, err = thttp.DoRequest(t, web, http.MethodPost, login.MFA, nil, []byte("\n"))
+	assert.Error(t, err)
+	// bad token
+	mreq := &login.MFARequest{
+		Token: "bad",
+		Code:  tcore.TOTPCode(t, secret, 1),
+	}
+	_, err = thttp.DoRequest(t, web, http.MethodPost, login.MFA, nil, mreq)
+	assert.Error(t, err)
+	// bad code
+	mreq = &login.MFARequest{
+		Token: ur.MFA.Token,
+		Code:  "bad",
+	}
+	_, err = thttp.DoRequest(t, web, http.MethodPost, login.MFA, nil, mreq)
+	assert.Error(t, err)
+	// verified
+	mreq = &login.MFARequest{
+		Token: ur.MFA.Token,
+		Code:  tcore.TOTPCode(t, secret, 1),
+	}
+	res, err = thttp.DoRequest(t, web, http.MethodPost, login.MFA, nil, mreq)
+	assert.NoError(t, err)
+	ur2, claims := tsrv.UnmarshalUserResponse(t, srv.Config().JWT, res)
+	assert.EqualValues(t, tokens.Bearer, ur2.Token.Type)
+	assert.Equal(t, u.ID.String(), claims.Subject())
+	assert.Nil(t, ur2.MFA)
+	// challenges cannot be reused
+	_, err = thttp.DoRequest(t, web, http.MethodPost, login.MFA, nil, mreq)
+	assert.Error(t, err)
+}
+
+func TestLoginServer_Login_PasswordChange(t *testing.T) {
+	t.Parallel()
+	srv, web, _ := tsrv.RESTHost(t, []rest.RegisterServer{
+		login.RegisterServer,
+	}, false)
+	srv.Config().Signup.AutoConfirm = true
+	u, _ := tcore.TestUser(t, srv.API, testPass, false)
+	actx := context.Background()
+	actx.SetAdminID(user.SuperAdminID)
+	_, err := srv.ForcePasswordChange(actx, u.ID)
+	require.NoError(t, err)
+	req := &testRequest{
+		Email:    u.Email,
+		Password: testPass,
+	}
+	res, err := thttp.DoRequest(t, web, http.MethodPost, login.Login, nil, req)
+	require.NoError(t, err)
+	var ur rest.UserResponse
+	err = json.Unmarshal([]byte(res), &ur)
+	require.NoError(t, err)
+	assert.Nil(t, ur.Token)
+	require.NotNil(t, ur.Password)
+	assert.NotEmpty(t, ur.Password.Token)
+	assert.NotNil(t, ur.Password.ExpiresAt)
+	// invalid req
+	_, err = thttp.DoRequest(t, web, http.MethodPost, login.Password, nil, []byte("\n"))
+	assert.Error(t, err)
+	// bad token
+	preq := &login.PasswordRequest{
+		Token:    "bad",
+		Password: "q8Ld!3xPz#w9Vk2m",
+	}
+	_, err = thttp.DoRequest(t, web, http.MethodPost, login.Password, nil, preq)
+	assert.EqualError(t, err, thttp.FmtError(http.StatusUnauthorized).Error())
+	// bad password
+	preq = &login.PasswordRequest{
+		Token:    ur.Password.Token,
+		Password: "weak",
+	}
+	_, err = thttp.DoRequest(t, web, http.MethodPost, login.Password, nil, preq)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"violations":["too_short","too_weak"]`)
+	// changed
+	preq.Password = "q8Ld!3xPz#w9Vk2m"
+	res, err = thttp.DoRequest(t, web, http.MethodPost, login.Password, nil, preq)
+	assert.NoError(t, err)
+	ur2, claims := tsrv.UnmarshalUserResponse(t, srv.Config().JWT, res)
+	assert.EqualValues(t, tokens.Bearer, ur2.Token.Type)
+	assert.Equal(t, u.ID.String(), claims.Subject())
+	assert.Nil(t, ur2.Password)
+	// tokens cannot be reused
+	_, err = thttp.DoRequest(t, web, http.MethodPost, login.Password, nil, preq)
+	assert.Error(t, err)
+	// expired passwords
+	srv.Config().Password.MaxAge = time.Nanosecond
+	req.Password = preq.Password
+	res, err = thttp.DoRequest(t, web, http.MethodPost, login.Login, nil, req)
+	require.NoError(t, err)
+	ur = rest.UserResponse{}
+	err = json.Unmarshal([]byte(res), &ur)
+	require.NoError(t, err)
+	assert.Nil(t, ur.Token)
+	assert.NotNil(t, ur.Password)
+}
+
+func TestLoginServer_WebAuthn(t *testing.T) {
+	t.Parallel()
+	srv, web, _ := tsrv.RESTHost(t, []rest.RegisterServer{
+		login.RegisterServer,
+	}, false)
+	srv.Config().Signup.AutoConfirm = true
+	u, _ := tcore.TestUser(t, srv.API, testPass, false)
+	// invalid req
+	_, err := thttp.DoRequest(t, web, http.MethodPost, login.WebAuthn, nil, []byte("\n"))
+	assert.Error(t, err)
+	// no credentials
+	req := &login.WebAuthnRequest{Email: u.Email}
+	_, err = thttp.DoRequest(t, web, http.MethodPost, login.WebAuthn, nil, req)
+	assert.Error(t, err)
+	auth := tcore.RegisterWebAuthn(t, srv.API, srv.Config(), u)
+	tests := []struct {
+		name  string
+		email string
+	}{
+		{"email", u.Email},
+		{"discoverable", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req = &login.WebAuthnRequest{Email: test.email}
+			res, err := thttp.DoRequest(t, web, http.MethodPost, login.WebAuthn, nil, req)
+			require.NoError(t, err)
+			var wr rest.WebAuthnResponse
+			err = json.Unmarshal([]byte(res), &wr)
+			require.NoError(t, err)
+			assert.NotEmpty(t, wr.Token)
+			assert.NotNil(t, wr.ExpiresAt)
+			// invalid req
+			_, err = thttp.DoRequest(t, web, http.MethodPost, login.WebAuthnFinish, nil, []byte("\n"))
+			assert.Error(t, err)
+			// bad token
+			freq := &login.WebAuthnFinishRequest{
+				Token:    "bad",
+				Response: auth.AssertionResponse(t, wr.Options),
+			}
+			_, err = thttp.DoRequest(t, web, http.MethodPost, login.WebAuthnFinish, nil, freq)
+			assert.Error(t, err)
+			// logged in
+			freq.Token = wr.Token
+			res, err = thttp.DoRequest(t, web, http.MethodPost, login.WebAuthnFinish, nil, freq)
+			require.NoError(t, err)
+			ur, claims := tsrv.UnmarshalUserResponse(t, srv.Config().JWT, res)
+			assert.EqualValues(t, tokens.Bearer, ur.Token.Type)
+			assert.Equal(t, u.ID.String(), claims.Subject())
+			// ceremonies cannot be reused
+			_, err = thttp.DoRequest(t, web, http.MethodPost, login.WebAuthnFinish, nil, freq)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoginServer_MagicLink(t *testing.T) {
+	t.Parallel()
+	srv, web, smtp := tsrv.RESTHost(t, []rest.RegisterServer{
+		login.RegisterServer,
+	}, true)
+	srv.Config().Signup.AutoConfirm = true
+	srv.Config().Mail.SendLimit = 0
+	u, _ := tcore.TestUser(t, srv.API, testPass, false)
+	// invalid req
+	_, err := thttp.DoRequest(t, web, http.MethodPost, login.MagicLink, nil, []byte("\n"))
+	assert.Error(t, err)
+	// empty email
+	req := new(login.MagicLinkRequest)
+	_, err = thttp.DoRequest(t, web, http.MethodPost, login.MagicLink, nil, req)
+	assert.Error(t, err)
+	// not found
+	req = &login.MagicLinkRequest{Email: "i-dont-exist@example.com"}
+	_, err = thttp.DoRequest(t, web, http.MethodPost, login.MagicLink, nil, req)
+	assert.NoError(t, err)
+	var tok string
+	smtp.AddHook(t, func(email string) {
+		tok = tconf.GetEmailToken(template.MagicLinkAction, email)
+	})
+	req = &login.MagicLinkRequest{Email: u.Email}
+	_, err = thttp.DoRequest(t, web, http.MethodPost, login.MagicLink, nil, req)
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return tok != ""
+	}, 1*time.Second, 10*time.Millisecond)
+	// rate limit
+	srv.Config().Mail.SendLimit = 5 * time.Minute
+	_, err = thttp.DoRequest(t, web, http.MethodPost, login.MagicLink, nil, req)
+	assert.Error(t, err)
+	// invalid req
+	_, err = thttp.DoRequest(t, web, http.MethodPost, login.MagicConfirm, nil, []byte("\n"))
+	assert.Error(t, err)
+	// empty token
+	req = new(login.MagicLinkRequest)
+	_, err = thttp.DoRequest(t, web, http.MethodPost, login.MagicConfirm, nil, req)
+	assert.Error(t, err)
+	// bad token
+	req = &login.MagicLinkRequest{Token: "bad"}
+	_, err = thttp.DoRequest(t, web, http.MethodPost, login.MagicConfirm, nil, req)
+	assert.Error(t, err)
+	// logged in
+	req = &login.MagicLinkRequest{Token: tok}
+	res, err := thttp.DoRequest(t, web, http.MethodPost, login.MagicConfirm, nil, req)
+	require.NoError(t, err)
+	ur, claims := tsrv.UnmarshalUserResponse(t, srv.Config().JWT, res)
+	assert.EqualValues(t, tokens.Bearer, ur.Token.Type)
+	assert.Equal(t, u.ID.String(), claims.Subject())
+	// links cannot be reused
+	_, err = thttp.DoRequest(t, web, http.MethodPost, login.MagicConfirm, nil, req)
+	assert.Error(t, err)
+}
+
+func TestLoginServer_Phone(t *testing.T) {
+	t.Parallel()
+	srv, web, _ := tsrv.RESTHost(t, []rest.RegisterServer{
+		login.RegisterServer,
+	}, false)
+	srv.Config().Signup.AutoConfirm = true
+	srv.Config().SMS.Login = true
+	srv.Config().SMS.SendLimit = 0
+	path := tcore.MockSMS(t, srv.API, srv.Config())
+	u, _ := tcore.TestUser(t, srv.API, testPass, false)
+	phone := tcore.ConfirmPhone(t, srv.API, path, u)
+	// invalid req
+	_, err := thttp.DoRequest(t, web, http.MethodPost, login.Phone, nil, []byte("\n"))
+	assert.Error(t, err)
+	// empty phone
+	req := new(login.PhoneRequest)
+	_, err = thttp.DoRequest(t, web, http.MethodPost, login.Phone, nil, req)
+	assert.Error(t, err)
+	// not found
+	req = &login.PhoneRequest{Phone: tutils.RandomPhone()}
+	_, err = thttp.DoRequest(t, web, http.MethodPost, login.Phone, nil, req)
+	assert.NoError(t, err)
+	req = &login.PhoneRequest{Phone: phone}
+	_, err = thttp.DoRequest(t, web, http.MethodPost, login.Phone, nil, req)
+	assert.NoError(t, err)
+	code := tconf.GetSMSCode(t, path, phone)
+	require.NotEmpty(t, code)
+	// rate limit
+	srv.Config().SMS.SendLimit = 5 * time.Minute
+	_, err = thttp.DoRequest(t, web, http.MethodPost, login.Phone, nil, req)
+	assert.Error(t, err)
+	// invalid req
+	_, err = thttp.DoRequest(t, web, http.MethodPost, login.PhoneConfirm, nil, []byte("\n"))
+	assert.Error(t, err)
+	// empty code
+	req = &login.PhoneRequest{Phone: phone}
+	_, err = thttp.DoRequest(t, web, http.MethodPost, login.PhoneConfirm, nil, req)
+	assert.Error(t, err)
+	// bad code
+	req = &login.PhoneRequest{Phone: phone, Code: "bad"}
+	_, err = thttp.DoRequest(t, web, http.MethodPost, login.PhoneConfirm, nil, req)
+	assert.Error(t, err)
+	// logged in
+	req = &login.PhoneRequest{Phone: phone, Code: code}
+	res, err := thttp.DoRequest(t, web, http.MethodPost, login.PhoneConfirm, nil, req)
+	require.NoError(t, err)
+	ur, claims := tsrv.UnmarshalUserResponse(t, srv.Config().JWT, res)
+	assert.EqualValues(t, tokens.Bearer, ur.Token.Type)
+	assert.Equal(t, u.ID.String(), claims.Subject())
+	assert.Equal(t, phone, ur.Phone)
+	// codes cannot be reused
+	_, err = thttp.DoRequest(t, web, http.MethodPost, login.PhoneConfirm, nil, req)
+	assert.Error(t, err)
+}
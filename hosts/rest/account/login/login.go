@@ -1,15 +1,26 @@
 package login
 
 import (
+	"errors"
 	"net/http"
 
+	"github.com/jrapoport/gothic/config"
 	"github.com/jrapoport/gothic/hosts/rest"
+	"github.com/segmentio/encoding/json"
 )
 
 // Login endpoints
 const (
-	Login  = "/login"
-	Logout = "/logout"
+	Login          = "/login"
+	Logout         = "/logout"
+	MFA            = "/login/mfa"
+	Password       = "/login/password"
+	WebAuthn       = "/login/webauthn"
+	WebAuthnFinish = "/login/webauthn/finish"
+	MagicLink      = "/login/magic"
+	MagicConfirm   = "/login/magic/confirm"
+	Phone          = "/login/phone"
+	PhoneConfirm   = "/login/phone/confirm"
 )
 
 // Request is an login server request
@@ -18,6 +29,42 @@ type Request struct {
 	Password string `json:"password" form:"password"`
 }
 
+// MFARequest is an mfa challenge request
+type MFARequest struct {
+	Token string `json:"token" form:"token"`
+	Code  string `json:"code" form:"code"`
+}
+
+// PasswordRequest is an expired password change request
+type PasswordRequest struct {
+	Token    string `json:"token" form:"token"`
+	Password string `json:"password" form:"password"`
+}
+
+// MagicLinkRequest is a magic link login request
+type MagicLinkRequest struct {
+	Email string `json:"email" form:"email"`
+	Token string `json:"token" form:"token"`
+}
+
+// PhoneRequest is a phone & sms code login request
+type PhoneRequest struct {
+	Phone string `json:"phone" form:"phone"`
+	Code  string `json:"code" form:"code"`
+}
+
+// WebAuthnRequest is a webauthn login request. If the email
+// is empty a discoverable (passkey) login is started.
+type WebAuthnRequest struct {
+	Email string `json:"email" form:"email"`
+}
+
+// WebAuthnFinishRequest is a webauthn login assertion.
+type WebAuthnFinishRequest struct {
+	Token    string          `json:"token" form:"token"`
+	Response json.RawMessage `json:"response" form:"-"`
+}
+
 type loginServer struct {
 	*rest.Server
 }
@@ -40,7 +87,15 @@ func register(s *http.Server, srv *loginServer) {
 
 func (s *loginServer) addRoutes(r *rest.Router) {
 	r.Post(Login, s.Login)
-	r.Authenticated().Get(Logout, s.Logout)
+	r.Post(MFA, s.VerifyMFA)
+	r.Post(Password, s.ChangePassword)
+	r.Post(WebAuthn, s.BeginWebAuthn)
+	r.Post(WebAuthnFinish, s.FinishWebAuthn)
+	r.Post(MagicLink, s.SendMagicLink)
+	r.Post(MagicConfirm, s.ConfirmMagicLink)
+	r.Post(Phone, s.SendPhoneLogin)
+	r.Post(PhoneConfirm, s.PhoneLogin)
+	r.Authenticated().Session().Get(Logout, s.Logout)
 }
 
 func (s *loginServer) Login(w http.ResponseWriter, r *http.Request) {
@@ -53,6 +108,52 @@ func (s *loginServer) Login(w http.ResponseWriter, r *http.Request) {
 	s.Debugf("login user: %v (%v)", req)
 	ctx := rest.FromRequest(r)
 	u, err := s.API.Login(ctx, req.Email, req.Password)
+	if errors.Is(err, config.ErrRateLimitExceeded) {
+		s.ResponseCode(w, http.StatusTooEarly, err)
+		return
+	}
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	s.Authorize(w, r, ctx, u)
+}
+
+func (s *loginServer) VerifyMFA(w http.ResponseWriter, r *http.Request) {
+	req := new(MFARequest)
+	err := rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	ctx := rest.FromRequest(r)
+	u, err := s.API.VerifyMFAChallenge(ctx, req.Token, req.Code)
+	if errors.Is(err, config.ErrRateLimitExceeded) {
+		s.ResponseCode(w, http.StatusTooEarly, err)
+		return
+	}
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	s.Debugf("verified mfa for user: %s", u.ID)
+	res := rest.NewUserResponse(u)
+	if s.Config().MaskEmails {
+		res.MaskEmail()
+	}
+	s.GrantBearer(w, r, ctx, u, res)
+}
+
+func (s *loginServer) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	req := new(PasswordRequest)
+	err := rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	s.Debugf("change expired password: %s", req.Token)
+	ctx := rest.FromRequest(r)
+	u, err := s.API.ChangeExpiredPassword(ctx, req.Token, req.Password)
 	if err != nil {
 		s.ResponseCode(w, http.StatusUnauthorized, err)
 		return
@@ -61,12 +162,137 @@ func (s *loginServer) Login(w http.ResponseWriter, r *http.Request) {
 	if s.Config().MaskEmails {
 		res.MaskEmail()
 	}
-	bt, err := s.GrantBearerToken(ctx, u)
+	s.GrantBearer(w, r, ctx, u, res)
+}
+
+func (s *loginServer) BeginWebAuthn(w http.ResponseWriter, r *http.Request) {
+	req := new(WebAuthnRequest)
+	err := rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	s.Debugf("begin webauthn login: %v", req)
+	ctx := rest.FromRequest(r)
+	c, err := s.API.BeginWebAuthnLogin(ctx, req.Email)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	s.Response(w, rest.NewWebAuthnResponse(c))
+}
+
+func (s *loginServer) FinishWebAuthn(w http.ResponseWriter, r *http.Request) {
+	req := new(WebAuthnFinishRequest)
+	err := rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	s.Debugf("finish webauthn login: %s", req.Token)
+	ctx := rest.FromRequest(r)
+	u, err := s.API.FinishWebAuthnLogin(ctx, req.Token, req.Response)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	res := rest.NewUserResponse(u)
+	if s.Config().MaskEmails {
+		res.MaskEmail()
+	}
+	s.GrantBearer(w, r, ctx, u, res)
+}
+
+func (s *loginServer) SendMagicLink(w http.ResponseWriter, r *http.Request) {
+	req := new(MagicLinkRequest)
+	err := rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Email == "" {
+		err = errors.New("email not found")
+		s.ResponseCode(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	s.Debugf("send magic link: %s", req.Email)
+	ctx := rest.FromRequest(r)
+	err = s.API.SendMagicLink(ctx, req.Email)
+	if errors.Is(err, config.ErrRateLimitExceeded) {
+		s.ResponseCode(w, http.StatusTooEarly, err)
+		return
+	}
+	// log any error but hide it so we don't leak information
+	s.AuthError(w, err)
+}
+
+func (s *loginServer) ConfirmMagicLink(w http.ResponseWriter, r *http.Request) {
+	req := new(MagicLinkRequest)
+	err := rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Token == "" {
+		err = errors.New("token not found")
+		s.ResponseCode(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	s.Debugf("confirm magic link: %s", req.Token)
+	ctx := rest.FromRequest(r)
+	u, err := s.API.ConfirmMagicLink(ctx, req.Token)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	s.Authorize(w, r, ctx, u)
+}
+
+func (s *loginServer) SendPhoneLogin(w http.ResponseWriter, r *http.Request) {
+	req := new(PhoneRequest)
+	err := rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Phone == "" {
+		err = errors.New("phone not found")
+		s.ResponseCode(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	s.Debugf("send phone login: %s", req.Phone)
+	ctx := rest.FromRequest(r)
+	err = s.API.SendPhoneLogin(ctx, req.Phone)
+	if errors.Is(err, config.ErrRateLimitExceeded) {
+		s.ResponseCode(w, http.StatusTooEarly, err)
+		return
+	}
+	// log any error but hide it so we don't leak information
+	s.AuthError(w, err)
+}
+
+func (s *loginServer) PhoneLogin(w http.ResponseWriter, r *http.Request) {
+	req := new(PhoneRequest)
+	err := rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Phone == "" || req.Code == "" {
+		err = errors.New("phone or code not found")
+		s.ResponseCode(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	s.Debugf("phone login: %s", req.Phone)
+	ctx := rest.FromRequest(r)
+	u, err := s.API.PhoneLogin(ctx, req.Phone, req.Code)
+	if errors.Is(err, config.ErrRateLimitExceeded) {
+		s.ResponseCode(w, http.StatusTooEarly, err)
+		return
+	}
 	if err != nil {
 		s.ResponseCode(w, http.StatusUnauthorized, err)
 		return
 	}
-	res.Token = rest.NewBearerResponse(bt)
-	s.Debugf("logged in user: %s", bt.UserID)
-	s.AuthResponse(w, r, bt.String(), res)
+	s.Authorize(w, r, ctx, u)
 }
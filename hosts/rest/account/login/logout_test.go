@@ -7,6 +7,7 @@ import (
 	"github.com/jrapoport/gothic/core/context"
 	"github.com/jrapoport/gothic/core/tokens"
 	"github.com/jrapoport/gothic/hosts/rest"
+	"github.com/jrapoport/gothic/models/token"
 	"github.com/jrapoport/gothic/models/user"
 	"github.com/jrapoport/gothic/test/thttp"
 	"github.com/jrapoport/gothic/test/tsrv"
@@ -56,6 +57,13 @@ func TestLoginServer_Logout(t *testing.T) {
 	assert.NoError(t, err)
 	ur, _ := tsrv.UnmarshalUserResponse(t, srv.Config().JWT, res)
 	assert.EqualValues(t, tokens.Bearer, ur.Token.Type)
+	// personal tokens can not logout
+	pt, err := srv.CreatePersonalToken(nil, u.ID, "test", []string{token.ScopeWrite}, 0)
+	require.NoError(t, err)
+	_, err = thttp.DoAuthRequest(t, web, http.MethodGet, Logout, pt.Secret, nil, nil)
+	assert.Error(t, err)
+	_, err = srv.GetAuthenticatedUser(u.ID)
+	assert.NoError(t, err)
 	_, err = thttp.DoAuthRequest(t, web, http.MethodGet, Logout, ur.Token.Access, nil, nil)
 	assert.NoError(t, err)
 	_, err = srv.GetAuthenticatedUser(u.ID)
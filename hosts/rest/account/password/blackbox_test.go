@@ -12,6 +12,7 @@ import (
 	"github.com/jrapoport/gothic/mail/template"
 	"github.com/jrapoport/gothic/models/user"
 	"github.com/jrapoport/gothic/test/tconf"
+	"github.com/jrapoport/gothic/test/tcore"
 	"github.com/jrapoport/gothic/test/thttp"
 	"github.com/jrapoport/gothic/test/tsrv"
 	"github.com/jrapoport/gothic/test/tutils"
@@ -164,10 +165,44 @@ func TestPasswordServer_ConfirmResetPassword(t *testing.T) {
 	u, err = srv.GetUser(u.ID)
 	assert.NoError(t, err)
 	assert.True(t, u.IsConfirmed())
-	_, claims := tsrv.UnmarshalTokenResponse(t, srv.Config().JWT, res)
+	_, claims := tsrv.UnmarshalUserResponse(t, srv.Config().JWT, res)
 	assert.Equal(t, u.ID.String(), claims.Subject())
 	u, err = srv.GetUser(u.ID)
 	assert.NoError(t, err)
 	err = u.Authenticate(newPass)
 	assert.NoError(t, err)
 }
+
+func TestPasswordServer_ConfirmResetPassword_MFA(t *testing.T) {
+	t.Parallel()
+	const newPass = "sxjAm7QJ4?3dH!aN8T3F5P!oNnpXbaRy#gtx#8jG"
+	srv, web, smtp := testServer(t)
+	srv.Config().Signup.AutoConfirm = true
+	u, _ := tcore.TestUser(t, srv.API, "", false)
+	_ = tcore.EnrollTOTP(t, srv.API, u)
+	var tok string
+	act := template.ResetPasswordAction
+	smtp.AddHook(t, func(email string) {
+		tok = tconf.GetEmailToken(act, email)
+	})
+	req := &password.Request{
+		Email: u.Email,
+	}
+	_, err := thttp.DoRequest(t, web, http.MethodPost, reset, nil, req)
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return tok != ""
+	}, 1*time.Second, 10*time.Millisecond)
+	// a reset password requires the second factor
+	req = &password.Request{
+		Password: newPass,
+		Token:    tok,
+	}
+	res, err := thttp.DoRequest(t, web, http.MethodPost, confirm, nil, req)
+	require.NoError(t, err)
+	ur, claims := tsrv.UnmarshalUserResponse(t, srv.Config().JWT, res)
+	assert.Nil(t, claims)
+	assert.Nil(t, ur.Token)
+	require.NotNil(t, ur.MFA)
+	assert.NotEmpty(t, ur.MFA.Token)
+}
@@ -104,11 +104,6 @@ func (s *passwordServer) ConfirmResetPassword(w http.ResponseWriter, r *http.Req
 		s.AuthError(w, err)
 		return
 	}
-	bt, err := s.GrantBearerToken(ctx, u)
-	if err != nil {
-		s.AuthError(w, err)
-		return
-	}
-	s.Debugf("password changed: %s", bt.UserID)
-	s.AuthResponse(w, r, bt.Token, bt)
+	s.Debugf("password changed: %s", u.ID)
+	s.Authorize(w, r, ctx, u)
 }
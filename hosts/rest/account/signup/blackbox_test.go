@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"testing"
 
+	"github.com/jrapoport/gothic/config"
 	"github.com/jrapoport/gothic/core/context"
 	"github.com/jrapoport/gothic/core/validate"
 	"github.com/jrapoport/gothic/hosts/rest"
@@ -223,6 +224,7 @@ func TestSignupServer_Signup_Password(t *testing.T) {
 	assert.Error(t, err)
 	// blank password ok
 	srv.Config().Validation.PasswordRegex = ""
+	srv.Config().Password = config.Password{}
 	_, err = thttp.DoRequest(t, web, http.MethodPost, signup.Signup, nil, v)
 	assert.NoError(t, err)
 	// custom password
@@ -234,4 +236,16 @@ func TestSignupServer_Signup_Password(t *testing.T) {
 	v.Set(key.Password, "password")
 	_, err = thttp.DoRequest(t, web, http.MethodPost, signup.Signup, nil, v)
 	assert.NoError(t, err)
+	// password policy
+	srv.Config().Validation.PasswordRegex = ""
+	srv.Config().Password = config.Password{
+		MinLength: 10,
+		Digit:     true,
+	}
+	v, _ = testCase(t, srv, web)
+	v.Set(key.Password, "password")
+	_, err = thttp.DoRequest(t, web, http.MethodPost, signup.Signup, nil, v)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), http.StatusText(http.StatusUnprocessableEntity))
+	assert.Contains(t, err.Error(), `"violations":["too_short","missing_digit"]`)
 }
@@ -0,0 +1,78 @@
+package unlock_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jrapoport/gothic/hosts/rest"
+	"github.com/jrapoport/gothic/hosts/rest/account/unlock"
+	"github.com/jrapoport/gothic/mail/template"
+	"github.com/jrapoport/gothic/test/tconf"
+	"github.com/jrapoport/gothic/test/tcore"
+	"github.com/jrapoport/gothic/test/thttp"
+	"github.com/jrapoport/gothic/test/tsrv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const unlockRt = unlock.Unlock + rest.Root
+
+func testServer(t *testing.T) (*rest.Host, *httptest.Server, *tconf.SMTPMock) {
+	srv, web, smtp := tsrv.RESTHost(t, []rest.RegisterServer{
+		unlock.RegisterServer,
+	}, true)
+	c := srv.Config()
+	c.Signup.AutoConfirm = true
+	c.Lockout.Attempts = 1
+	return srv, web, smtp
+}
+
+func TestUnlockServer_ConfirmUnlock(t *testing.T) {
+	t.Parallel()
+	srv, web, smtp := testServer(t)
+	// invalid req
+	_, err := thttp.DoRequest(t, web, http.MethodPost, unlockRt, nil, []byte("\n"))
+	assert.Error(t, err)
+	// empty token
+	req := new(unlock.Request)
+	_, err = thttp.DoRequest(t, web, http.MethodPost, unlockRt, nil, req)
+	assert.Error(t, err)
+	// bad token
+	req = &unlock.Request{
+		Token: "bad",
+	}
+	_, err = thttp.DoRequest(t, web, http.MethodPost, unlockRt, nil, req)
+	assert.Error(t, err)
+	var tok string
+	var mu sync.Mutex
+	smtp.AddHook(t, func(email string) {
+		mu.Lock()
+		defer mu.Unlock()
+		tok = tconf.GetEmailToken(template.UnlockUserAction, email)
+	})
+	u, _ := tcore.TestUser(t, srv.API, "", false)
+	_, err = srv.API.Login(nil, u.Email, "bad")
+	assert.Error(t, err)
+	u, err = srv.GetUser(u.ID)
+	require.NoError(t, err)
+	require.True(t, u.IsLocked())
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return tok != ""
+	}, 1*time.Second, 10*time.Millisecond)
+	req = &unlock.Request{
+		Token: tok,
+	}
+	_, err = thttp.DoRequest(t, web, http.MethodPost, unlockRt, nil, req)
+	assert.NoError(t, err)
+	u, err = srv.GetUser(u.ID)
+	assert.NoError(t, err)
+	assert.False(t, u.IsLocked())
+	// used token
+	_, err = thttp.DoRequest(t, web, http.MethodPost, unlockRt, nil, req)
+	assert.Error(t, err)
+}
@@ -0,0 +1,69 @@
+package unlock
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jrapoport/gothic/hosts/rest"
+)
+
+// Unlock endpoints
+const (
+	Unlock = "/unlock"
+	User   = rest.Root
+)
+
+// Request is an unlock server request
+type Request struct {
+	Token string `json:"token" form:"token"`
+}
+
+type unlockServer struct {
+	*rest.Server
+}
+
+func newUnlockServer(srv *rest.Server) *unlockServer {
+	srv.Logger = srv.WithName("unlock")
+	return &unlockServer{srv}
+}
+
+// RegisterServer registers an unlock server.
+func RegisterServer(s *http.Server, srv *rest.Server) {
+	register(s, newUnlockServer(srv))
+}
+
+func register(s *http.Server, srv *unlockServer) {
+	if r, ok := s.Handler.(*rest.Router); ok {
+		srv.addRoutes(r)
+	}
+}
+
+func (s *unlockServer) addRoutes(r *rest.Router) {
+	r.Route(Unlock, func(rt *rest.Router) {
+		rt.Post(User, s.ConfirmUnlock)
+	})
+}
+
+// ConfirmUnlock unlocks a locked user with a token.
+func (s *unlockServer) ConfirmUnlock(w http.ResponseWriter, r *http.Request) {
+	req := new(Request)
+	err := rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Token == "" {
+		err = errors.New("token required")
+		s.ResponseCode(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	s.Debugf("unlock user: %v", req)
+	ctx := rest.FromRequest(r)
+	err = s.API.ConfirmUnlock(ctx, req.Token)
+	if err != nil {
+		s.ResponseError(w, err)
+		return
+	}
+	s.Debugf("unlocked user")
+	s.Response(w, nil)
+}
@@ -5,10 +5,15 @@ import (
 
 	"github.com/jrapoport/gothic/hosts/rest"
 	"github.com/jrapoport/gothic/hosts/rest/admin/audit"
+	"github.com/jrapoport/gothic/hosts/rest/admin/clients"
 	"github.com/jrapoport/gothic/hosts/rest/admin/codes"
+	"github.com/jrapoport/gothic/hosts/rest/admin/roles"
+	"github.com/jrapoport/gothic/hosts/rest/admin/services"
 	"github.com/jrapoport/gothic/hosts/rest/admin/settings"
+	"github.com/jrapoport/gothic/hosts/rest/admin/tenants"
 	"github.com/jrapoport/gothic/hosts/rest/admin/users"
 	"github.com/jrapoport/gothic/hosts/rest/modules/invite"
+	"github.com/jrapoport/gothic/models/rbac"
 )
 
 // Admin is the admin endpoint.
@@ -35,11 +40,15 @@ func register(s *http.Server, srv *adminServer) {
 }
 
 func (s *adminServer) addRoutes(r *rest.Router) {
-	r.Authenticated().Admin().Route(Admin, func(rt *rest.Router) {
-		audit.RegisterServer(&http.Server{Handler: rt}, s.Clone())
-		invite.RegisterServer(&http.Server{Handler: rt}, s.Clone())
-		settings.RegisterServer(&http.Server{Handler: rt}, s.Clone())
+	r.Authenticated().Confirmed().Route(Admin, func(rt *rest.Router) {
+		audit.RegisterServer(&http.Server{Handler: rt.Permission(rbac.AuditRead)}, s.Clone())
+		clients.RegisterServer(&http.Server{Handler: rt}, s.Clone())
+		invite.RegisterServer(&http.Server{Handler: rt.Permission(rbac.CodesCreate)}, s.Clone())
+		roles.RegisterServer(&http.Server{Handler: rt}, s.Clone())
+		services.RegisterServer(&http.Server{Handler: rt}, s.Clone())
+		settings.RegisterServer(&http.Server{Handler: rt.Permission(rbac.SettingsRead)}, s.Clone())
 		codes.RegisterServer(&http.Server{Handler: rt}, s.Clone())
+		tenants.RegisterServer(&http.Server{Handler: rt}, s.Clone())
 		users.RegisterServer(&http.Server{Handler: rt}, s.Clone())
 	})
 }
@@ -0,0 +1,146 @@
+package clients
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jrapoport/gothic/hosts/rest"
+	"github.com/jrapoport/gothic/models/rbac"
+	"github.com/jrapoport/gothic/models/types/key"
+)
+
+// Clients endpoint
+const (
+	Clients = "/clients"
+	Create  = rest.Root
+	List    = rest.Root
+	Read    = "/{" + key.ClientID + "}"
+	Delete  = Read
+)
+
+// Request is a create client request.
+type Request struct {
+	Name         string   `json:"name" form:"name"`
+	RedirectURIs []string `json:"redirect_uris" form:"redirect_uris"`
+	Scopes       []string `json:"scopes" form:"scopes"`
+	Confidential bool     `json:"confidential" form:"confidential"`
+}
+
+type clientsServer struct {
+	*rest.Server
+}
+
+func newClientsServer(srv *rest.Server) *clientsServer {
+	srv.Logger = srv.WithName("clients")
+	return &clientsServer{srv}
+}
+
+// RegisterServer registers a new clients server.
+func RegisterServer(s *http.Server, srv *rest.Server) {
+	register(s, newClientsServer(srv))
+}
+
+func register(s *http.Server, srv *clientsServer) {
+	if r, ok := s.Handler.(*rest.Router); ok {
+		srv.addRoutes(r)
+	}
+}
+
+func (s *clientsServer) addRoutes(r *rest.Router) {
+	r.Authenticated().Route(Clients, func(rt *rest.Router) {
+		rt.Permission(rbac.ClientsWrite).Post(Create, s.CreateClient)
+		rt.Permission(rbac.ClientsRead).Get(List, s.ListClients)
+		rt.Permission(rbac.ClientsRead).Get(Read, s.GetClient)
+		rt.Permission(rbac.ClientsWrite).Delete(Delete, s.DeleteClient)
+	})
+}
+
+// CreateClient registers a new oauth client. The client secret
+// is only returned when the client is created.
+func (s *clientsServer) CreateClient(w http.ResponseWriter, r *http.Request) {
+	req := new(Request)
+	err := rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	_, err = s.ValidateAdmin(r, rbac.ClientsWrite)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	ctx := rest.FromRequest(r)
+	s.Debugf("create client %s: %s", req.Name, ctx.AdminID())
+	c, secret, err := s.API.CreateClient(ctx, req.Name,
+		req.RedirectURIs, req.Scopes, req.Confidential)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	res := rest.NewClientResponse(c)
+	res.ClientSecret = secret
+	s.Response(w, res)
+}
+
+// ListClients lists the registered oauth clients.
+func (s *clientsServer) ListClients(w http.ResponseWriter, r *http.Request) {
+	_, err := s.ValidateAdmin(r, rbac.ClientsRead)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	ctx := rest.FromRequest(r)
+	s.Debugf("list clients: %s", ctx.AdminID())
+	list, err := s.API.GetClients(ctx)
+	if err != nil {
+		s.ResponseError(w, err)
+		return
+	}
+	s.Response(w, rest.NewClientsResponse(list))
+}
+
+// GetClient returns a registered oauth client.
+func (s *clientsServer) GetClient(w http.ResponseWriter, r *http.Request) {
+	clientID := rest.URLParam(r, key.ClientID)
+	if clientID == "" {
+		err := errors.New("invalid client id")
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	_, err := s.ValidateAdmin(r, rbac.ClientsRead)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	ctx := rest.FromRequest(r)
+	s.Debugf("get client: %s", clientID)
+	c, err := s.API.GetClient(ctx, clientID)
+	if err != nil {
+		s.ResponseCode(w, http.StatusNotFound, err)
+		return
+	}
+	s.Response(w, rest.NewClientResponse(c))
+}
+
+// DeleteClient deletes a registered oauth client.
+func (s *clientsServer) DeleteClient(w http.ResponseWriter, r *http.Request) {
+	clientID := rest.URLParam(r, key.ClientID)
+	if clientID == "" {
+		err := errors.New("invalid client id")
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	_, err := s.ValidateAdmin(r, rbac.ClientsWrite)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	ctx := rest.FromRequest(r)
+	s.Debugf("delete client: %s", clientID)
+	err = s.API.DeleteClient(ctx, clientID)
+	if err != nil {
+		s.ResponseCode(w, http.StatusNotFound, err)
+		return
+	}
+	s.Response(w, nil)
+}
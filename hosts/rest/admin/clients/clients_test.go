@@ -0,0 +1,187 @@
+package clients
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/oidc"
+	"github.com/jrapoport/gothic/hosts/rest"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/test/tcore"
+	"github.com/jrapoport/gothic/test/thttp"
+	"github.com/jrapoport/gothic/test/tsrv"
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const redirectURI = "https://example.com/callback"
+
+func testServer(t *testing.T) (*clientsServer, string) {
+	s, _ := tsrv.RESTServer(t, false)
+	srv := newClientsServer(s)
+	srv.Config().Signup.AutoConfirm = true
+	_, tok := tcore.TestUser(t, srv.API, "", true)
+	return srv, tok
+}
+
+func clientRequest(t *testing.T, srv *clientsServer, method, clientID, tok string, body interface{}) *http.Request {
+	uri := Clients + rest.Root + clientID
+	r := thttp.Request(t, method, uri, tok, nil, body)
+	if tok != "" {
+		var err error
+		r, err = rest.ParseClaims(r, srv.Config().JWT, tok)
+		require.NoError(t, err)
+	}
+	ctx := chi.NewRouteContext()
+	ctx.URLParams = chi.RouteParams{
+		Keys:   []string{key.ClientID},
+		Values: []string{clientID},
+	}
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, ctx))
+}
+
+func createClient(t *testing.T, srv *clientsServer, tok string, req *Request) *httptest.ResponseRecorder {
+	r := clientRequest(t, srv, http.MethodPost, "", tok, req)
+	w := httptest.NewRecorder()
+	srv.CreateClient(w, r)
+	return w
+}
+
+func TestClientsServer_CreateClient(t *testing.T) {
+	t.Parallel()
+	srv, tok := testServer(t)
+	j := srv.Config().JWT
+	req := &Request{
+		Name:         "test",
+		RedirectURIs: []string{redirectURI},
+		Confidential: true,
+	}
+	// no admin id
+	res := createClient(t, srv, "", req)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// admin not found
+	res = createClient(t, srv, thttp.UserToken(t, j, true, true), req)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// bad request
+	res = createClient(t, srv, tok, &Request{Name: "test"})
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = createClient(t, srv, tok, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+	var cr rest.ClientResponse
+	err := json.Unmarshal(res.Body.Bytes(), &cr)
+	require.NoError(t, err)
+	assert.NotEmpty(t, cr.ClientID)
+	assert.NotEmpty(t, cr.ClientSecret)
+	assert.Equal(t, req.Name, cr.Name)
+	assert.Equal(t, req.RedirectURIs, cr.RedirectURIs)
+	assert.Equal(t, oidc.SupportedScopes, cr.Scopes)
+	assert.True(t, cr.Confidential)
+	// public client
+	req.Confidential = false
+	req.Scopes = []string{oidc.ScopeOpenID}
+	res = createClient(t, srv, tok, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+	err = json.Unmarshal(res.Body.Bytes(), &cr)
+	require.NoError(t, err)
+	assert.False(t, cr.Confidential)
+	assert.Equal(t, req.Scopes, cr.Scopes)
+}
+
+func TestClientsServer_ListClients(t *testing.T) {
+	t.Parallel()
+	srv, tok := testServer(t)
+	listClients := func(tok string) *httptest.ResponseRecorder {
+		r := clientRequest(t, srv, http.MethodGet, "", tok, nil)
+		w := httptest.NewRecorder()
+		srv.ListClients(w, r)
+		return w
+	}
+	res := listClients("")
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	const count = 3
+	for i := 0; i < count; i++ {
+		res = createClient(t, srv, tok, &Request{
+			Name:         "test",
+			RedirectURIs: []string{redirectURI},
+		})
+		require.Equal(t, http.StatusOK, res.Code)
+	}
+	res = listClients(tok)
+	assert.Equal(t, http.StatusOK, res.Code)
+	var list []*rest.ClientResponse
+	err := json.Unmarshal(res.Body.Bytes(), &list)
+	require.NoError(t, err)
+	assert.Len(t, list, count)
+	for _, c := range list {
+		assert.Empty(t, c.ClientSecret)
+	}
+}
+
+func TestClientsServer_GetClient(t *testing.T) {
+	t.Parallel()
+	srv, tok := testServer(t)
+	res := createClient(t, srv, tok, &Request{
+		Name:         "test",
+		RedirectURIs: []string{redirectURI},
+		Confidential: true,
+	})
+	require.Equal(t, http.StatusOK, res.Code)
+	var cr rest.ClientResponse
+	err := json.Unmarshal(res.Body.Bytes(), &cr)
+	require.NoError(t, err)
+	getClient := func(clientID, tok string) *httptest.ResponseRecorder {
+		r := clientRequest(t, srv, http.MethodGet, clientID, tok, nil)
+		w := httptest.NewRecorder()
+		srv.GetClient(w, r)
+		return w
+	}
+	res = getClient("", tok)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = getClient(cr.ClientID, "")
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = getClient(uuid.New().String(), tok)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = getClient(cr.ClientID, tok)
+	assert.Equal(t, http.StatusOK, res.Code)
+	var got rest.ClientResponse
+	err = json.Unmarshal(res.Body.Bytes(), &got)
+	require.NoError(t, err)
+	assert.Equal(t, cr.ClientID, got.ClientID)
+	assert.Empty(t, got.ClientSecret)
+	assert.True(t, got.Confidential)
+}
+
+func TestClientsServer_DeleteClient(t *testing.T) {
+	t.Parallel()
+	srv, tok := testServer(t)
+	res := createClient(t, srv, tok, &Request{
+		Name:         "test",
+		RedirectURIs: []string{redirectURI},
+	})
+	require.Equal(t, http.StatusOK, res.Code)
+	var cr rest.ClientResponse
+	err := json.Unmarshal(res.Body.Bytes(), &cr)
+	require.NoError(t, err)
+	deleteClient := func(clientID, tok string) *httptest.ResponseRecorder {
+		r := clientRequest(t, srv, http.MethodDelete, clientID, tok, nil)
+		w := httptest.NewRecorder()
+		srv.DeleteClient(w, r)
+		return w
+	}
+	res = deleteClient("", tok)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = deleteClient(cr.ClientID, "")
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = deleteClient(uuid.New().String(), tok)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = deleteClient(cr.ClientID, tok)
+	assert.Equal(t, http.StatusOK, res.Code)
+	// client is gone
+	res = deleteClient(cr.ClientID, tok)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/jrapoport/gothic/hosts/rest"
 	"github.com/jrapoport/gothic/models/code"
+	"github.com/jrapoport/gothic/models/rbac"
 	"github.com/jrapoport/gothic/models/token"
 	"github.com/jrapoport/gothic/models/types/key"
 )
@@ -41,10 +42,10 @@ func register(s *http.Server, srv *codesServer) {
 }
 
 func (s *codesServer) addRoutes(r *rest.Router) {
-	r.Authenticated().Admin().Route(Codes, func(rt *rest.Router) {
-		rt.Post(Create, s.CreateSignupCodes)
-		rt.Get(Read, s.CheckSignupCode)
-		rt.Delete(Delete, s.DeleteSignupCode)
+	r.Authenticated().Route(Codes, func(rt *rest.Router) {
+		rt.Permission(rbac.CodesCreate).Post(Create, s.CreateSignupCodes)
+		rt.Permission(rbac.CodesRead).Get(Read, s.CheckSignupCode)
+		rt.Permission(rbac.CodesDelete).Delete(Delete, s.DeleteSignupCode)
 	})
 }
 
@@ -61,7 +62,7 @@ func (s *codesServer) CreateSignupCodes(w http.ResponseWriter, r *http.Request)
 		s.ResponseCode(w, http.StatusUnprocessableEntity, err)
 		return
 	}
-	_, err = s.ValidateAdmin(r)
+	_, err = s.ValidateAdmin(r, rbac.CodesCreate)
 	if err != nil {
 		s.ResponseCode(w, http.StatusUnauthorized, err)
 		return
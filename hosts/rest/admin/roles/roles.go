@@ -0,0 +1,179 @@
+package roles
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jrapoport/gothic/hosts/rest"
+	"github.com/jrapoport/gothic/models/rbac"
+	"github.com/jrapoport/gothic/models/types/key"
+)
+
+// Roles endpoint
+const (
+	Roles  = "/roles"
+	Create = rest.Root
+	List   = rest.Root
+	Read   = "/{" + key.Role + "}"
+	Update = Read
+	Delete = Read
+)
+
+// Request is a create custom role request.
+type Request struct {
+	Name        string   `json:"name" form:"name"`
+	Description string   `json:"description" form:"description"`
+	Permissions []string `json:"permissions" form:"permissions"`
+}
+
+// UpdateRequest is an update custom role request. If the description
+// or permissions are not set, they are not changed.
+type UpdateRequest struct {
+	Description *string  `json:"description" form:"description"`
+	Permissions []string `json:"permissions" form:"permissions"`
+}
+
+type rolesServer struct {
+	*rest.Server
+}
+
+func newRolesServer(srv *rest.Server) *rolesServer {
+	srv.Logger = srv.WithName("roles")
+	return &rolesServer{srv}
+}
+
+// RegisterServer registers a new roles server.
+func RegisterServer(s *http.Server, srv *rest.Server) {
+	register(s, newRolesServer(srv))
+}
+
+func register(s *http.Server, srv *rolesServer) {
+	if r, ok := s.Handler.(*rest.Router); ok {
+		srv.addRoutes(r)
+	}
+}
+
+func (s *rolesServer) addRoutes(r *rest.Router) {
+	r.Authenticated().Route(Roles, func(rt *rest.Router) {
+		rt.Permission(rbac.RolesWrite).Post(Create, s.CreateRole)
+		rt.Permission(rbac.RolesRead).Get(List, s.ListRoles)
+		rt.Permission(rbac.RolesRead).Get(Read, s.GetRole)
+		rt.Permission(rbac.RolesWrite).Put(Update, s.UpdateRole)
+		rt.Permission(rbac.RolesWrite).Delete(Delete, s.DeleteRole)
+	})
+}
+
+// CreateRole creates a new custom role.
+func (s *rolesServer) CreateRole(w http.ResponseWriter, r *http.Request) {
+	req := new(Request)
+	err := rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	_, err = s.ValidateAdmin(r, rbac.RolesWrite)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	ctx := rest.FromRequest(r)
+	s.Debugf("create role %s: %s", req.Name, ctx.AdminID())
+	role, err := s.API.CreateRole(ctx, req.Name, req.Description, req.Permissions)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	s.Response(w, rest.NewRoleResponse(role))
+}
+
+// ListRoles lists the custom roles.
+func (s *rolesServer) ListRoles(w http.ResponseWriter, r *http.Request) {
+	_, err := s.ValidateAdmin(r, rbac.RolesRead)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	ctx := rest.FromRequest(r)
+	s.Debugf("list roles: %s", ctx.AdminID())
+	list, err := s.API.GetRoles(ctx)
+	if err != nil {
+		s.ResponseError(w, err)
+		return
+	}
+	s.Response(w, rest.NewRolesResponse(list))
+}
+
+// GetRole returns a custom role.
+func (s *rolesServer) GetRole(w http.ResponseWriter, r *http.Request) {
+	name := rest.URLParam(r, key.Role)
+	if name == "" {
+		err := errors.New("invalid role")
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	_, err := s.ValidateAdmin(r, rbac.RolesRead)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	ctx := rest.FromRequest(r)
+	s.Debugf("get role: %s", name)
+	role, err := s.API.GetRole(ctx, name)
+	if err != nil {
+		s.ResponseCode(w, http.StatusNotFound, err)
+		return
+	}
+	s.Response(w, rest.NewRoleResponse(role))
+}
+
+// UpdateRole updates a custom role.
+func (s *rolesServer) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	name := rest.URLParam(r, key.Role)
+	if name == "" {
+		err := errors.New("invalid role")
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	req := new(UpdateRequest)
+	err := rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	_, err = s.ValidateAdmin(r, rbac.RolesWrite)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	ctx := rest.FromRequest(r)
+	s.Debugf("update role: %s", name)
+	role, err := s.API.UpdateRole(ctx, name, req.Description, req.Permissions)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	s.Response(w, rest.NewRoleResponse(role))
+}
+
+// DeleteRole deletes a custom role.
+func (s *rolesServer) DeleteRole(w http.ResponseWriter, r *http.Request) {
+	name := rest.URLParam(r, key.Role)
+	if name == "" {
+		err := errors.New("invalid role")
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	_, err := s.ValidateAdmin(r, rbac.RolesWrite)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	ctx := rest.FromRequest(r)
+	s.Debugf("delete role: %s", name)
+	err = s.API.DeleteRole(ctx, name)
+	if err != nil {
+		s.ResponseCode(w, http.StatusNotFound, err)
+		return
+	}
+	s.Response(w, nil)
+}
@@ -0,0 +1,215 @@
+package roles
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jrapoport/gothic/hosts/rest"
+	"github.com/jrapoport/gothic/models/rbac"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/test/tcore"
+	"github.com/jrapoport/gothic/test/thttp"
+	"github.com/jrapoport/gothic/test/tsrv"
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testPermissions = []string{
+	rbac.UsersRead.String(),
+	rbac.SessionsRead.String(),
+}
+
+func testServer(t *testing.T) (*rolesServer, string) {
+	s, _ := tsrv.RESTServer(t, false)
+	srv := newRolesServer(s)
+	srv.Config().Signup.AutoConfirm = true
+	_, tok := tcore.TestUser(t, srv.API, "", true)
+	return srv, tok
+}
+
+func roleRequest(t *testing.T, srv *rolesServer, method, name, tok string, body interface{}) *http.Request {
+	uri := Roles + rest.Root + name
+	r := thttp.Request(t, method, uri, tok, nil, body)
+	if tok != "" {
+		var err error
+		r, err = rest.ParseClaims(r, srv.Config().JWT, tok)
+		require.NoError(t, err)
+	}
+	ctx := chi.NewRouteContext()
+	ctx.URLParams = chi.RouteParams{
+		Keys:   []string{key.Role},
+		Values: []string{name},
+	}
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, ctx))
+}
+
+func createRole(t *testing.T, srv *rolesServer, tok string, req *Request) *httptest.ResponseRecorder {
+	r := roleRequest(t, srv, http.MethodPost, "", tok, req)
+	w := httptest.NewRecorder()
+	srv.CreateRole(w, r)
+	return w
+}
+
+func roleResponse(t *testing.T, res *httptest.ResponseRecorder) *rest.RoleResponse {
+	var rr rest.RoleResponse
+	err := json.Unmarshal(res.Body.Bytes(), &rr)
+	require.NoError(t, err)
+	return &rr
+}
+
+func TestRolesServer_CreateRole(t *testing.T) {
+	t.Parallel()
+	srv, tok := testServer(t)
+	j := srv.Config().JWT
+	req := &Request{
+		Name:        "support",
+		Description: "support staff",
+		Permissions: testPermissions,
+	}
+	// no admin id
+	res := createRole(t, srv, "", req)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// admin not found
+	res = createRole(t, srv, thttp.UserToken(t, j, true, true), req)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// bad request
+	res = createRole(t, srv, tok, &Request{Permissions: testPermissions})
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = createRole(t, srv, tok, &Request{Name: "support", Permissions: []string{"bad"}})
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = createRole(t, srv, tok, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+	rr := roleResponse(t, res)
+	assert.Equal(t, req.Name, rr.Name)
+	assert.Equal(t, req.Description, rr.Description)
+	assert.Equal(t, []string{rbac.SessionsRead.String(), rbac.UsersRead.String()}, rr.Permissions)
+	// a role user with the permission
+	_, rt := tcore.TestRoleUser(t, srv.API, rbac.RolesWrite)
+	res = createRole(t, srv, rt, &Request{
+		Name:        "writer",
+		Permissions: []string{rbac.RolesWrite.String()},
+	})
+	assert.Equal(t, http.StatusOK, res.Code)
+	// a role user can not grant permissions it does not have
+	res = createRole(t, srv, rt, &Request{
+		Name:        "escalate",
+		Permissions: []string{rbac.UsersDelete.String()},
+	})
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// a role user without the permission
+	_, rt = tcore.TestRoleUser(t, srv.API, rbac.RolesRead)
+	res = createRole(t, srv, rt, &Request{
+		Name:        "reader",
+		Permissions: []string{rbac.RolesRead.String()},
+	})
+	assert.NotEqual(t, http.StatusOK, res.Code)
+}
+
+func TestRolesServer_ListRoles(t *testing.T) {
+	t.Parallel()
+	srv, tok := testServer(t)
+	listRoles := func(tok string) *httptest.ResponseRecorder {
+		r := roleRequest(t, srv, http.MethodGet, "", tok, nil)
+		w := httptest.NewRecorder()
+		srv.ListRoles(w, r)
+		return w
+	}
+	res := listRoles("")
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	for _, name := range []string{"support", "auditor"} {
+		res = createRole(t, srv, tok, &Request{Name: name, Permissions: testPermissions})
+		require.Equal(t, http.StatusOK, res.Code)
+	}
+	res = listRoles(tok)
+	assert.Equal(t, http.StatusOK, res.Code)
+	var list []*rest.RoleResponse
+	err := json.Unmarshal(res.Body.Bytes(), &list)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "auditor", list[0].Name)
+	assert.Equal(t, "support", list[1].Name)
+}
+
+func TestRolesServer_GetRole(t *testing.T) {
+	t.Parallel()
+	srv, tok := testServer(t)
+	res := createRole(t, srv, tok, &Request{Name: "support", Permissions: testPermissions})
+	require.Equal(t, http.StatusOK, res.Code)
+	getRole := func(name, tok string) *httptest.ResponseRecorder {
+		r := roleRequest(t, srv, http.MethodGet, name, tok, nil)
+		w := httptest.NewRecorder()
+		srv.GetRole(w, r)
+		return w
+	}
+	res = getRole("", tok)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = getRole("support", "")
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = getRole("missing", tok)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = getRole("support", tok)
+	assert.Equal(t, http.StatusOK, res.Code)
+	rr := roleResponse(t, res)
+	assert.Equal(t, "support", rr.Name)
+}
+
+func TestRolesServer_UpdateRole(t *testing.T) {
+	t.Parallel()
+	srv, tok := testServer(t)
+	res := createRole(t, srv, tok, &Request{Name: "support", Permissions: testPermissions})
+	require.Equal(t, http.StatusOK, res.Code)
+	updateRole := func(name, tok string, req *UpdateRequest) *httptest.ResponseRecorder {
+		r := roleRequest(t, srv, http.MethodPut, name, tok, req)
+		w := httptest.NewRecorder()
+		srv.UpdateRole(w, r)
+		return w
+	}
+	desc := "updated"
+	res = updateRole("", tok, &UpdateRequest{Description: &desc})
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = updateRole("support", "", &UpdateRequest{Description: &desc})
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = updateRole("missing", tok, &UpdateRequest{Description: &desc})
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = updateRole("support", tok, &UpdateRequest{Permissions: []string{"bad"}})
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = updateRole("support", tok, &UpdateRequest{Description: &desc})
+	assert.Equal(t, http.StatusOK, res.Code)
+	rr := roleResponse(t, res)
+	assert.Equal(t, desc, rr.Description)
+	assert.Len(t, rr.Permissions, 2)
+	perms := []string{rbac.AuditRead.String()}
+	res = updateRole("support", tok, &UpdateRequest{Permissions: perms})
+	assert.Equal(t, http.StatusOK, res.Code)
+	rr = roleResponse(t, res)
+	assert.Equal(t, desc, rr.Description)
+	assert.Equal(t, perms, rr.Permissions)
+}
+
+func TestRolesServer_DeleteRole(t *testing.T) {
+	t.Parallel()
+	srv, tok := testServer(t)
+	res := createRole(t, srv, tok, &Request{Name: "support", Permissions: testPermissions})
+	require.Equal(t, http.StatusOK, res.Code)
+	deleteRole := func(name, tok string) *httptest.ResponseRecorder {
+		r := roleRequest(t, srv, http.MethodDelete, name, tok, nil)
+		w := httptest.NewRecorder()
+		srv.DeleteRole(w, r)
+		return w
+	}
+	res = deleteRole("", tok)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = deleteRole("support", "")
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = deleteRole("missing", tok)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = deleteRole("support", tok)
+	assert.Equal(t, http.StatusOK, res.Code)
+	// role is gone
+	res = deleteRole("support", tok)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+}
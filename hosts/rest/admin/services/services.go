@@ -0,0 +1,145 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/hosts/rest"
+	"github.com/jrapoport/gothic/models/rbac"
+	"github.com/jrapoport/gothic/models/types/key"
+)
+
+// Services endpoint
+const (
+	Services = "/services"
+	Create   = rest.Root
+	List     = rest.Root
+	Read     = "/{" + key.ClientID + "}"
+	Delete   = Read
+)
+
+// Request is a create service client request.
+type Request struct {
+	Name    string    `json:"name" form:"name"`
+	Scopes  []string  `json:"scopes" form:"scopes"`
+	OwnerID uuid.UUID `json:"owner_id" form:"owner_id"`
+}
+
+type servicesServer struct {
+	*rest.Server
+}
+
+func newServicesServer(srv *rest.Server) *servicesServer {
+	srv.Logger = srv.WithName("services")
+	return &servicesServer{srv}
+}
+
+// RegisterServer registers a new services server.
+func RegisterServer(s *http.Server, srv *rest.Server) {
+	register(s, newServicesServer(srv))
+}
+
+func register(s *http.Server, srv *servicesServer) {
+	if r, ok := s.Handler.(*rest.Router); ok {
+		srv.addRoutes(r)
+	}
+}
+
+func (s *servicesServer) addRoutes(r *rest.Router) {
+	r.Authenticated().Route(Services, func(rt *rest.Router) {
+		rt.Permission(rbac.ClientsWrite).Post(Create, s.CreateService)
+		rt.Permission(rbac.ClientsRead).Get(List, s.ListServices)
+		rt.Permission(rbac.ClientsRead).Get(Read, s.GetService)
+		rt.Permission(rbac.ClientsWrite).Delete(Delete, s.DeleteService)
+	})
+}
+
+// CreateService creates a new service client. The client secret
+// is only returned when the service client is created.
+func (s *servicesServer) CreateService(w http.ResponseWriter, r *http.Request) {
+	req := new(Request)
+	err := rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	_, err = s.ValidateAdmin(r, rbac.ClientsWrite)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	ctx := rest.FromRequest(r)
+	s.Debugf("create service %s: %s", req.Name, ctx.AdminID())
+	svc, secret, err := s.API.CreateServiceClient(ctx, req.Name, req.Scopes, req.OwnerID)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	res := rest.NewServiceResponse(svc)
+	res.ClientSecret = secret
+	s.Response(w, res)
+}
+
+// ListServices lists the service clients.
+func (s *servicesServer) ListServices(w http.ResponseWriter, r *http.Request) {
+	_, err := s.ValidateAdmin(r, rbac.ClientsRead)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	ctx := rest.FromRequest(r)
+	s.Debugf("list services: %s", ctx.AdminID())
+	list, err := s.API.GetServiceClients(ctx)
+	if err != nil {
+		s.ResponseError(w, err)
+		return
+	}
+	s.Response(w, rest.NewServicesResponse(list))
+}
+
+// GetService returns a service client.
+func (s *servicesServer) GetService(w http.ResponseWriter, r *http.Request) {
+	clientID := rest.URLParam(r, key.ClientID)
+	if clientID == "" {
+		err := errors.New("invalid client id")
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	_, err := s.ValidateAdmin(r, rbac.ClientsRead)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	ctx := rest.FromRequest(r)
+	s.Debugf("get service: %s", clientID)
+	svc, err := s.API.GetServiceClient(ctx, clientID)
+	if err != nil {
+		s.ResponseCode(w, http.StatusNotFound, err)
+		return
+	}
+	s.Response(w, rest.NewServiceResponse(svc))
+}
+
+// DeleteService deletes a service client.
+func (s *servicesServer) DeleteService(w http.ResponseWriter, r *http.Request) {
+	clientID := rest.URLParam(r, key.ClientID)
+	if clientID == "" {
+		err := errors.New("invalid client id")
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	_, err := s.ValidateAdmin(r, rbac.ClientsWrite)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	ctx := rest.FromRequest(r)
+	s.Debugf("delete service: %s", clientID)
+	err = s.API.DeleteServiceClient(ctx, clientID)
+	if err != nil {
+		s.ResponseCode(w, http.StatusNotFound, err)
+		return
+	}
+	s.Response(w, nil)
+}
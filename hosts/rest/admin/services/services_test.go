@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/hosts/rest"
+	"github.com/jrapoport/gothic/jwt"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/test/tcore"
+	"github.com/jrapoport/gothic/test/thttp"
+	"github.com/jrapoport/gothic/test/tsrv"
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testScopes = []string{"read", "write"}
+
+func testServer(t *testing.T) (*servicesServer, string) {
+	s, _ := tsrv.RESTServer(t, false)
+	srv := newServicesServer(s)
+	srv.Config().Signup.AutoConfirm = true
+	_, tok := tcore.TestUser(t, srv.API, "", true)
+	return srv, tok
+}
+
+func serviceRequest(t *testing.T, srv *servicesServer, method, clientID, tok string, body interface{}) *http.Request {
+	uri := Services + rest.Root + clientID
+	r := thttp.Request(t, method, uri, tok, nil, body)
+	if tok != "" {
+		var err error
+		r, err = rest.ParseClaims(r, srv.Config().JWT, tok)
+		require.NoError(t, err)
+	}
+	ctx := chi.NewRouteContext()
+	ctx.URLParams = chi.RouteParams{
+		Keys:   []string{key.ClientID},
+		Values: []string{clientID},
+	}
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, ctx))
+}
+
+func createService(t *testing.T, srv *servicesServer, tok string, req *Request) *httptest.ResponseRecorder {
+	r := serviceRequest(t, srv, http.MethodPost, "", tok, req)
+	w := httptest.NewRecorder()
+	srv.CreateService(w, r)
+	return w
+}
+
+func TestServicesServer_CreateService(t *testing.T) {
+	t.Parallel()
+	srv, tok := testServer(t)
+	j := srv.Config().JWT
+	req := &Request{
+		Name:   "test",
+		Scopes: testScopes,
+	}
+	// no admin id
+	res := createService(t, srv, "", req)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// admin not found
+	res = createService(t, srv, thttp.UserToken(t, j, true, true), req)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// bad request
+	res = createService(t, srv, tok, &Request{Scopes: testScopes})
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = createService(t, srv, tok, &Request{Name: "test", OwnerID: uuid.New()})
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = createService(t, srv, tok, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+	var sr rest.ServiceResponse
+	err := json.Unmarshal(res.Body.Bytes(), &sr)
+	require.NoError(t, err)
+	assert.NotEmpty(t, sr.ClientID)
+	assert.NotEmpty(t, sr.ClientSecret)
+	assert.Equal(t, req.Name, sr.Name)
+	assert.Equal(t, req.Scopes, sr.Scopes)
+	// the admin is the owner
+	claims, err := jwt.ParseUserClaims(j, tok)
+	require.NoError(t, err)
+	assert.Equal(t, claims.UserID(), sr.OwnerID)
+}
+
+func TestServicesServer_ListServices(t *testing.T) {
+	t.Parallel()
+	srv, tok := testServer(t)
+	listServices := func(tok string) *httptest.ResponseRecorder {
+		r := serviceRequest(t, srv, http.MethodGet, "", tok, nil)
+		w := httptest.NewRecorder()
+		srv.ListServices(w, r)
+		return w
+	}
+	res := listServices("")
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	const count = 3
+	for i := 0; i < count; i++ {
+		res = createService(t, srv, tok, &Request{Name: "test", Scopes: testScopes})
+		require.Equal(t, http.StatusOK, res.Code)
+	}
+	res = listServices(tok)
+	assert.Equal(t, http.StatusOK, res.Code)
+	var list []*rest.ServiceResponse
+	err := json.Unmarshal(res.Body.Bytes(), &list)
+	require.NoError(t, err)
+	assert.Len(t, list, count)
+	for _, s := range list {
+		assert.Empty(t, s.ClientSecret)
+	}
+}
+
+func TestServicesServer_GetService(t *testing.T) {
+	t.Parallel()
+	srv, tok := testServer(t)
+	res := createService(t, srv, tok, &Request{Name: "test", Scopes: testScopes})
+	require.Equal(t, http.StatusOK, res.Code)
+	var sr rest.ServiceResponse
+	err := json.Unmarshal(res.Body.Bytes(), &sr)
+	require.NoError(t, err)
+	getService := func(clientID, tok string) *httptest.ResponseRecorder {
+		r := serviceRequest(t, srv, http.MethodGet, clientID, tok, nil)
+		w := httptest.NewRecorder()
+		srv.GetService(w, r)
+		return w
+	}
+	res = getService("", tok)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = getService(sr.ClientID, "")
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = getService(uuid.New().String(), tok)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = getService(sr.ClientID, tok)
+	assert.Equal(t, http.StatusOK, res.Code)
+	var got rest.ServiceResponse
+	err = json.Unmarshal(res.Body.Bytes(), &got)
+	require.NoError(t, err)
+	assert.Equal(t, sr.ClientID, got.ClientID)
+	assert.Empty(t, got.ClientSecret)
+	assert.Equal(t, testScopes, got.Scopes)
+}
+
+func TestServicesServer_DeleteService(t *testing.T) {
+	t.Parallel()
+	srv, tok := testServer(t)
+	res := createService(t, srv, tok, &Request{Name: "test", Scopes: testScopes})
+	require.Equal(t, http.StatusOK, res.Code)
+	var sr rest.ServiceResponse
+	err := json.Unmarshal(res.Body.Bytes(), &sr)
+	require.NoError(t, err)
+	deleteService := func(clientID, tok string) *httptest.ResponseRecorder {
+		r := serviceRequest(t, srv, http.MethodDelete, clientID, tok, nil)
+		w := httptest.NewRecorder()
+		srv.DeleteService(w, r)
+		return w
+	}
+	res = deleteService("", tok)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = deleteService(sr.ClientID, "")
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = deleteService(uuid.New().String(), tok)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = deleteService(sr.ClientID, tok)
+	assert.Equal(t, http.StatusOK, res.Code)
+	// service is gone
+	res = deleteService(sr.ClientID, tok)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+}
@@ -0,0 +1,161 @@
+package tenants
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/hosts/rest"
+	"github.com/jrapoport/gothic/models/rbac"
+	"github.com/jrapoport/gothic/models/types/key"
+)
+
+// Tenants endpoint
+const (
+	Tenants = "/tenants"
+	Create  = rest.Root
+	List    = rest.Root
+	Read    = "/{" + key.TenantID + "}"
+	Update  = Read
+	Delete  = Read
+)
+
+// Request is a create tenant request.
+type Request struct {
+	Name   string              `json:"name" form:"name"`
+	Host   string              `json:"host" form:"host"`
+	Config config.TenantConfig `json:"config" form:"config"`
+}
+
+// UpdateRequest is an update tenant request. Fields that are
+// nil are not changed.
+type UpdateRequest struct {
+	Host   *string              `json:"host" form:"host"`
+	Config *config.TenantConfig `json:"config" form:"config"`
+}
+
+type tenantsServer struct {
+	*rest.Server
+}
+
+func newTenantsServer(srv *rest.Server) *tenantsServer {
+	srv.Logger = srv.WithName("tenants")
+	return &tenantsServer{srv}
+}
+
+// RegisterServer registers a new tenants server.
+func RegisterServer(s *http.Server, srv *rest.Server) {
+	register(s, newTenantsServer(srv))
+}
+
+func register(s *http.Server, srv *tenantsServer) {
+	if r, ok := s.Handler.(*rest.Router); ok {
+		srv.addRoutes(r)
+	}
+}
+
+func (s *tenantsServer) addRoutes(r *rest.Router) {
+	r.Authenticated().Route(Tenants, func(rt *rest.Router) {
+		rt.Permission(rbac.TenantsWrite).Post(Create, s.CreateTenant)
+		rt.Permission(rbac.TenantsRead).Get(List, s.ListTenants)
+		rt.Permission(rbac.TenantsRead).Get(Read, s.GetTenant)
+		rt.Permission(rbac.TenantsWrite).Put(Update, s.UpdateTenant)
+		rt.Permission(rbac.TenantsWrite).Delete(Delete, s.DeleteTenant)
+	})
+}
+
+// CreateTenant creates a new tenant.
+func (s *tenantsServer) CreateTenant(w http.ResponseWriter, r *http.Request) {
+	req := new(Request)
+	err := rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	ctx := rest.FromRequest(r)
+	s.Debugf("create tenant %s: %s", req.Name, ctx.AdminID())
+	t, err := s.API.CreateTenant(ctx, req.Name, req.Host, req.Config)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	s.Response(w, rest.NewTenantResponse(t))
+}
+
+// ListTenants lists the tenants.
+func (s *tenantsServer) ListTenants(w http.ResponseWriter, r *http.Request) {
+	ctx := rest.FromRequest(r)
+	s.Debugf("list tenants: %s", ctx.AdminID())
+	list, err := s.API.GetTenants(ctx)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	s.Response(w, rest.NewTenantsResponse(list))
+}
+
+// GetTenant returns a tenant.
+func (s *tenantsServer) GetTenant(w http.ResponseWriter, r *http.Request) {
+	tid, err := tenantID(r)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	ctx := rest.FromRequest(r)
+	s.Debugf("get tenant: %s", tid)
+	t, err := s.API.GetTenant(ctx, tid)
+	if err != nil {
+		s.ResponseCode(w, http.StatusNotFound, err)
+		return
+	}
+	s.Response(w, rest.NewTenantResponse(t))
+}
+
+// UpdateTenant updates the host & configuration of a tenant.
+func (s *tenantsServer) UpdateTenant(w http.ResponseWriter, r *http.Request) {
+	tid, err := tenantID(r)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	req := new(UpdateRequest)
+	err = rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	ctx := rest.FromRequest(r)
+	s.Debugf("update tenant: %s", tid)
+	t, err := s.API.UpdateTenant(ctx, tid, req.Host, req.Config)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	s.Response(w, rest.NewTenantResponse(t))
+}
+
+// DeleteTenant deletes a tenant.
+func (s *tenantsServer) DeleteTenant(w http.ResponseWriter, r *http.Request) {
+	tid, err := tenantID(r)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	ctx := rest.FromRequest(r)
+	s.Debugf("delete tenant: %s", tid)
+	err = s.API.DeleteTenant(ctx, tid)
+	if err != nil {
+		s.ResponseCode(w, http.StatusNotFound, err)
+		return
+	}
+	s.Response(w, nil)
+}
+
+func tenantID(r *http.Request) (uuid.UUID, error) {
+	tid, err := uuid.Parse(rest.URLParam(r, key.TenantID))
+	if err != nil {
+		return uuid.Nil, errors.New("invalid tenant id")
+	}
+	return tid, nil
+}
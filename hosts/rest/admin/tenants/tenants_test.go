@@ -0,0 +1,196 @@
+package tenants
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/hosts/rest"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/test/tcore"
+	"github.com/jrapoport/gothic/test/thttp"
+	"github.com/jrapoport/gothic/test/tsrv"
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testHost = "acme.example.com"
+
+func testServer(t *testing.T) (*tenantsServer, string, string) {
+	s, _ := tsrv.RESTServer(t, false)
+	srv := newTenantsServer(s)
+	c := srv.Config()
+	c.Tenants.Enabled = true
+	err := srv.API.LoadConfig(c)
+	require.NoError(t, err)
+	_, adm := tcore.TestUser(t, srv.API, "", true)
+	_, tok := tcore.TestUser(t, srv.API, "", false)
+	return srv, adm, tok
+}
+
+func tenantRequest(t *testing.T, srv *tenantsServer, method, tid, tok string, body interface{}) *http.Request {
+	uri := Tenants + rest.Root + tid
+	r := thttp.Request(t, method, uri, tok, nil, body)
+	if tok != "" {
+		var err error
+		r, err = rest.ParseClaims(r, srv.Config().JWT, tok)
+		require.NoError(t, err)
+	}
+	ctx := chi.NewRouteContext()
+	ctx.URLParams = chi.RouteParams{
+		Keys:   []string{key.TenantID},
+		Values: []string{tid},
+	}
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, ctx))
+}
+
+func createTenant(t *testing.T, srv *tenantsServer, tok string, req *Request) *httptest.ResponseRecorder {
+	r := tenantRequest(t, srv, http.MethodPost, "", tok, req)
+	w := httptest.NewRecorder()
+	srv.CreateTenant(w, r)
+	return w
+}
+
+func testTenant(t *testing.T, srv *tenantsServer, tok string) *rest.TenantResponse {
+	res := createTenant(t, srv, tok, &Request{Name: "acme", Host: testHost})
+	require.Equal(t, http.StatusOK, res.Code)
+	var tr rest.TenantResponse
+	err := json.Unmarshal(res.Body.Bytes(), &tr)
+	require.NoError(t, err)
+	return &tr
+}
+
+func TestTenantsServer_CreateTenant(t *testing.T) {
+	t.Parallel()
+	srv, adm, tok := testServer(t)
+	req := &Request{
+		Name:   "acme",
+		Host:   testHost,
+		Config: config.TenantConfig{SiteURL: "http://acme.example.com"},
+	}
+	// no admin id
+	res := createTenant(t, srv, "", req)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// not admin
+	res = createTenant(t, srv, tok, req)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// bad request
+	res = createTenant(t, srv, adm, &Request{Name: "Not Valid"})
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = createTenant(t, srv, adm, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+	var tr rest.TenantResponse
+	err := json.Unmarshal(res.Body.Bytes(), &tr)
+	require.NoError(t, err)
+	assert.NotEmpty(t, tr.TenantID)
+	assert.Equal(t, req.Name, tr.Name)
+	assert.Equal(t, req.Host, tr.Host)
+	// name taken
+	res = createTenant(t, srv, adm, req)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+}
+
+func TestTenantsServer_ListTenants(t *testing.T) {
+	t.Parallel()
+	srv, adm, tok := testServer(t)
+	listTenants := func(tok string) *httptest.ResponseRecorder {
+		r := tenantRequest(t, srv, http.MethodGet, "", tok, nil)
+		w := httptest.NewRecorder()
+		srv.ListTenants(w, r)
+		return w
+	}
+	res := listTenants(tok)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	for _, name := range []string{"acme", "brand", "corp"} {
+		res = createTenant(t, srv, adm, &Request{Name: name})
+		require.Equal(t, http.StatusOK, res.Code)
+	}
+	res = listTenants(adm)
+	assert.Equal(t, http.StatusOK, res.Code)
+	var list []*rest.TenantResponse
+	err := json.Unmarshal(res.Body.Bytes(), &list)
+	require.NoError(t, err)
+	assert.Len(t, list, 3)
+}
+
+func TestTenantsServer_GetTenant(t *testing.T) {
+	t.Parallel()
+	srv, adm, tok := testServer(t)
+	tr := testTenant(t, srv, adm)
+	getTenant := func(tid, tok string) *httptest.ResponseRecorder {
+		r := tenantRequest(t, srv, http.MethodGet, tid, tok, nil)
+		w := httptest.NewRecorder()
+		srv.GetTenant(w, r)
+		return w
+	}
+	res := getTenant("", adm)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = getTenant(tr.TenantID, tok)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = getTenant(uuid.New().String(), adm)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = getTenant(tr.TenantID, adm)
+	assert.Equal(t, http.StatusOK, res.Code)
+	var got rest.TenantResponse
+	err := json.Unmarshal(res.Body.Bytes(), &got)
+	require.NoError(t, err)
+	assert.Equal(t, tr.TenantID, got.TenantID)
+	assert.Equal(t, tr.Name, got.Name)
+}
+
+func TestTenantsServer_UpdateTenant(t *testing.T) {
+	t.Parallel()
+	srv, adm, tok := testServer(t)
+	tr := testTenant(t, srv, adm)
+	updateTenant := func(tid, tok string, req *UpdateRequest) *httptest.ResponseRecorder {
+		r := tenantRequest(t, srv, http.MethodPut, tid, tok, req)
+		w := httptest.NewRecorder()
+		srv.UpdateTenant(w, r)
+		return w
+	}
+	host := "brand.example.com"
+	req := &UpdateRequest{Host: &host}
+	res := updateTenant("", adm, req)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = updateTenant(tr.TenantID, tok, req)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = updateTenant(uuid.New().String(), adm, req)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	bad := &UpdateRequest{Config: &config.TenantConfig{SiteURL: "\n"}}
+	res = updateTenant(tr.TenantID, adm, bad)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = updateTenant(tr.TenantID, adm, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+	var got rest.TenantResponse
+	err := json.Unmarshal(res.Body.Bytes(), &got)
+	require.NoError(t, err)
+	assert.Equal(t, host, got.Host)
+}
+
+func TestTenantsServer_DeleteTenant(t *testing.T) {
+	t.Parallel()
+	srv, adm, tok := testServer(t)
+	tr := testTenant(t, srv, adm)
+	deleteTenant := func(tid, tok string) *httptest.ResponseRecorder {
+		r := tenantRequest(t, srv, http.MethodDelete, tid, tok, nil)
+		w := httptest.NewRecorder()
+		srv.DeleteTenant(w, r)
+		return w
+	}
+	res := deleteTenant("", adm)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = deleteTenant(tr.TenantID, tok)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = deleteTenant(uuid.New().String(), adm)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = deleteTenant(tr.TenantID, adm)
+	assert.Equal(t, http.StatusOK, res.Code)
+	// tenant is gone
+	res = deleteTenant(tr.TenantID, adm)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+}
@@ -0,0 +1,116 @@
+package users
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jrapoport/gothic/core/users"
+	"github.com/jrapoport/gothic/hasher"
+	"github.com/jrapoport/gothic/hosts/rest"
+	"github.com/jrapoport/gothic/models/rbac"
+)
+
+// Import form keys
+const (
+	importFile          = "file"
+	importFormat        = "format"
+	importSignerKey     = "firebase_signer_key"
+	importSaltSeparator = "firebase_salt_separator"
+	importRounds        = "firebase_rounds"
+	importMemCost       = "firebase_mem_cost"
+)
+
+const (
+	csvContent       = "text/csv"
+	multipartContent = "multipart/form-data"
+	// maxImportMemory is the max memory used to parse a multipart import.
+	maxImportMemory = 32 << 20
+)
+
+// AdminImportUsers imports users with password hashes exported from another service.
+// The users can be posted as json, csv (text/csv), or as a multipart file upload.
+func (s *usersServer) AdminImportUsers(w http.ResponseWriter, r *http.Request) {
+	_, err := s.ValidateAdmin(r, rbac.UsersCreate)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	imp, err := readImport(r)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	s.Debugf("import users: %d", len(imp.Users))
+	ctx := rest.FromRequest(r)
+	res, err := s.API.ImportUsers(ctx, imp)
+	if err != nil {
+		s.ResponseError(w, err)
+		return
+	}
+	s.Debugf("imported users: %d (failed: %d)", res.Imported, res.Failed)
+	s.Response(w, res)
+}
+
+func readImport(r *http.Request) (*users.Import, error) {
+	var body io.Reader = r.Body
+	var ext string
+	ct, _, _ := mime.ParseMediaType(r.Header.Get(rest.ContentType))
+	if ct == multipartContent {
+		err := r.ParseMultipartForm(maxImportMemory)
+		if err != nil {
+			return nil, err
+		}
+		f, fh, err := r.FormFile(importFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", importFile, err)
+		}
+		defer f.Close()
+		body = f
+		ext = strings.TrimPrefix(filepath.Ext(fh.Filename), ".")
+	}
+	format := r.FormValue(importFormat)
+	if ct == csvContent {
+		format = users.FormatCSV
+	} else if format == "" {
+		format = ext
+	}
+	imp, err := users.ReadImport(body, format)
+	if err != nil {
+		return nil, err
+	}
+	if imp.Firebase != nil {
+		return imp, nil
+	}
+	imp.Firebase, err = firebaseParams(r)
+	if err != nil {
+		return nil, err
+	}
+	return imp, nil
+}
+
+// firebaseParams returns the firebase hash params from the request form.
+func firebaseParams(r *http.Request) (*hasher.FirebaseParams, error) {
+	signerKey := r.FormValue(importSignerKey)
+	if signerKey == "" {
+		return nil, nil
+	}
+	fp := &hasher.FirebaseParams{
+		SignerKey:     signerKey,
+		SaltSeparator: r.FormValue(importSaltSeparator),
+	}
+	var err error
+	fp.Rounds, err = strconv.Atoi(r.FormValue(importRounds))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", importRounds, err)
+	}
+	fp.MemCost, err = strconv.Atoi(r.FormValue(importMemCost))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", importMemCost, err)
+	}
+	return fp, nil
+}
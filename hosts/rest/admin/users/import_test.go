@@ -0,0 +1,146 @@
+package users
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/users"
+	"github.com/jrapoport/gothic/hosts/rest"
+	"github.com/jrapoport/gothic/test/thttp"
+	"github.com/jrapoport/gothic/test/tsrv"
+	"github.com/jrapoport/gothic/test/tutils"
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testMD5Hash      = "$1$ab$t00rsSdYwJXPEvSlIBoBQ0"
+	testFirebaseHash = "lSrfV15cpx95/sZS2W9c9Kp6i/LVgQNDNC/qzrCnh1SAyZvqmZqAjTdn3aoItz+VHjoZilo78198JAdRuid5lQ=="
+	testFirebaseSalt = "42xEC+ixf3L2lw=="
+	testFirebaseKey  = "jxspr8Ki0RYycVU8zykbdLGjFQ3McFUH0uiiTvC8pVMXAn210wjLNmdZJzxUECKbm0QsEmYUSDzZvpjeJ9WmXA=="
+)
+
+func importResult(t *testing.T, res string) *users.ImportResult {
+	r := new(users.ImportResult)
+	err := json.Unmarshal([]byte(res), r)
+	require.NoError(t, err)
+	return r
+}
+
+func TestUserServer_AdminImportUsers(t *testing.T) {
+	t.Parallel()
+	s, _ := tsrv.RESTServer(t, false)
+	srv := newUserServer(s)
+	srv.Config().MaskEmails = false
+	importUsers := func(tok, uri, ct string, body io.Reader) *httptest.ResponseRecorder {
+		r := thttp.Request(t, http.MethodPost, uri, tok, nil, nil)
+		r.Body = io.NopCloser(body)
+		r.Header.Set(rest.ContentType, ct)
+		if tok != "" {
+			var err error
+			r, err = rest.ParseClaims(r, srv.Config().JWT, tok)
+			require.NoError(t, err)
+		}
+		w := httptest.NewRecorder()
+		srv.AdminImportUsers(w, r)
+		return w
+	}
+	imp := users.Import{
+		Users: []users.ImportRecord{
+			{Email: tutils.RandomEmail(), Hash: testMD5Hash, Confirmed: true},
+			{Email: "bad", Hash: testMD5Hash},
+		},
+	}
+	b, err := json.Marshal(imp)
+	require.NoError(t, err)
+	uri := Users + Import
+	// no admin id
+	res := importUsers("", uri, rest.JSONContent, bytes.NewReader(b))
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// not admin
+	_, tok := testUser(t, srv, false)
+	res = importUsers(tok, uri, rest.JSONContent, bytes.NewReader(b))
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// json
+	_, tok = testUser(t, srv, true)
+	res = importUsers(tok, uri, rest.JSONContent, bytes.NewReader(b))
+	require.Equal(t, http.StatusOK, res.Code)
+	ir := importResult(t, res.Body.String())
+	assert.Equal(t, 1, ir.Imported)
+	assert.Equal(t, 1, ir.Failed)
+	require.Len(t, ir.Results, 2)
+	uid, err := uuid.Parse(ir.Results[0].UserID)
+	require.NoError(t, err)
+	u, err := srv.API.GetUser(uid)
+	require.NoError(t, err)
+	assert.Equal(t, imp.Users[0].Email, u.Email)
+	assert.Equal(t, testMD5Hash, string(u.Password))
+	assert.Empty(t, ir.Results[1].UserID)
+	assert.NotEmpty(t, ir.Results[1].Error)
+	// csv with firebase params
+	csvData := "email,password_hash,salt,hash_algorithm\n" +
+		tutils.RandomEmail() + "," + testFirebaseHash + "," + testFirebaseSalt + ",firebase-scrypt\n"
+	v := url.Values{}
+	v.Set(importSignerKey, testFirebaseKey)
+	v.Set(importSaltSeparator, "Bw==")
+	v.Set(importRounds, "8")
+	v.Set(importMemCost, "14")
+	csvURI := uri + "?" + v.Encode()
+	res = importUsers(tok, csvURI, csvContent, strings.NewReader(csvData))
+	require.Equal(t, http.StatusOK, res.Code)
+	ir = importResult(t, res.Body.String())
+	assert.Equal(t, 1, ir.Imported)
+	assert.Equal(t, 0, ir.Failed)
+	// csv without firebase params
+	res = importUsers(tok, uri, csvContent, strings.NewReader(csvData))
+	require.Equal(t, http.StatusOK, res.Code)
+	ir = importResult(t, res.Body.String())
+	assert.Equal(t, 0, ir.Imported)
+	assert.Equal(t, 1, ir.Failed)
+	// bad firebase params
+	res = importUsers(tok, uri+"?firebase_signer_key=key&firebase_rounds=a",
+		csvContent, strings.NewReader(csvData))
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+	res = importUsers(tok, uri+"?firebase_signer_key=key&firebase_rounds=8&firebase_mem_cost=a",
+		csvContent, strings.NewReader(csvData))
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+	// multipart csv file
+	upload := func(field, name, data string) (string, io.Reader) {
+		buf := new(bytes.Buffer)
+		mw := multipart.NewWriter(buf)
+		fw, err := mw.CreateFormFile(field, name)
+		require.NoError(t, err)
+		_, err = fw.Write([]byte(data))
+		require.NoError(t, err)
+		err = mw.Close()
+		require.NoError(t, err)
+		return mw.FormDataContentType(), buf
+	}
+	csvData = "email,password_hash,confirmed\n" + tutils.RandomEmail() + "," + testMD5Hash + ",true\n"
+	ct, body := upload("file", "users.csv", csvData)
+	res = importUsers(tok, uri, ct, body)
+	require.Equal(t, http.StatusOK, res.Code)
+	ir = importResult(t, res.Body.String())
+	assert.Equal(t, 1, ir.Imported)
+	// missing file
+	ct, body = upload("bad", "users.csv", csvData)
+	res = importUsers(tok, uri, ct, body)
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+	// bad multipart
+	res = importUsers(tok, uri, "multipart/form-data; boundary=x", strings.NewReader("bad"))
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+	// bad body
+	res = importUsers(tok, uri, rest.JSONContent, strings.NewReader("{"))
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+	// no users
+	res = importUsers(tok, uri, rest.JSONContent, strings.NewReader("[]"))
+	assert.NotEqual(t, http.StatusOK, res.Code)
+}
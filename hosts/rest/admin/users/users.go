@@ -1,34 +1,49 @@
 package users
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jrapoport/gothic/hosts/rest"
+	"github.com/jrapoport/gothic/models/rbac"
 	"github.com/jrapoport/gothic/models/types"
 	"github.com/jrapoport/gothic/models/types/key"
 )
 
 // Users endpoint
 const (
-	Users    = "/users"
-	Search   = rest.Root
-	UserID   = "/{" + key.UserID + "}" // select a user
-	Create   = rest.Root
-	Read     = rest.Root
-	Update   = rest.Root
-	Delete   = rest.Root
-	Promote  = "/promote"
-	Metadata = "/metadata"
+	Users       = "/users"
+	Search      = rest.Root
+	UserID      = "/{" + key.UserID + "}" // select a user
+	Create      = rest.Root
+	Import      = "/import"
+	Read        = rest.Root
+	Update      = rest.Root
+	Delete      = rest.Root
+	Promote     = "/promote"
+	Unlock      = "/unlock"
+	Force       = "/force-reset"
+	Impersonate = "/impersonate"
+	Metadata    = "/metadata"
+	Sessions    = "/sessions"
+	Session     = "/{" + key.SessionID + "}" // select a session
+	Tokens      = "/tokens"
+	Token       = "/{" + key.TokenID + "}" // select a token (jti)
+	Roles       = "/roles"
+	Role        = "/{" + key.Role + "}" // select a custom role
 )
 
 // Request is an user server request
 type Request struct {
-	Username    string    `json:"username,omitempty" form:"username"`
-	Data        types.Map `json:"data,omitempty" form:"data"`
-	Metadata    types.Map `json:"metadata,omitempty" form:"metadata"`
-	Password    string    `json:"password,omitempty" form:"password"`
-	OldPassword string    `json:"new_password,omitempty" form:"new_password"`
+	Username    string     `json:"username,omitempty" form:"username"`
+	Data        types.Map  `json:"data,omitempty" form:"data"`
+	Metadata    types.Map  `json:"metadata,omitempty" form:"metadata"`
+	Password    string     `json:"password,omitempty" form:"password"`
+	OldPassword string     `json:"new_password,omitempty" form:"new_password"`
+	Before      *time.Time `json:"before,omitempty" form:"before"`
+	Role        string     `json:"role,omitempty" form:"role"`
 }
 
 type usersServer struct {
@@ -52,15 +67,33 @@ func register(s *http.Server, srv *usersServer) {
 }
 
 func (s *usersServer) addRoutes(r *rest.Router) {
-	r.Authenticated().Admin().Route(Users, func(rt *rest.Router) {
-		rt.Get(Search, s.SearchUsers)
-		rt.Post(Create, s.AdminCreateUser)
+	r.Authenticated().Route(Users, func(rt *rest.Router) {
+		rt.Permission(rbac.UsersRead).Get(Search, s.SearchUsers)
+		rt.Permission(rbac.UsersCreate).Post(Create, s.AdminCreateUser)
+		rt.Permission(rbac.UsersCreate).Post(Import, s.AdminImportUsers)
 		rt.Route(UserID, func(uid *rest.Router) {
-			uid.Get(Read, s.GetUser)
-			uid.Put(Update, s.AdminUpdateUser)
-			uid.Delete(Delete, s.AdminDeleteUser)
-			uid.Post(Promote, s.AdminPromoteUser)
-			uid.Post(Metadata, s.AdminUpdateUserMetadata)
+			uid.Permission(rbac.UsersRead).Get(Read, s.GetUser)
+			uid.Permission(rbac.UsersWrite).Put(Update, s.AdminUpdateUser)
+			uid.Permission(rbac.UsersDelete).Delete(Delete, s.AdminDeleteUser)
+			uid.Permission(rbac.RolesAssign).Post(Promote, s.AdminPromoteUser)
+			uid.Permission(rbac.UsersWrite).Post(Unlock, s.AdminUnlockUser)
+			uid.Permission(rbac.UsersWrite).Post(Force, s.AdminForcePasswordChange)
+			uid.Permission(rbac.UsersWrite).Post(Metadata, s.AdminUpdateUserMetadata)
+			uid.Permission(rbac.UsersImpersonate).Post(Impersonate, s.AdminImpersonateUser)
+			uid.Route(Sessions, func(sid *rest.Router) {
+				sid.Permission(rbac.SessionsRead).Get(rest.Root, s.AdminListSessions)
+				sid.Permission(rbac.SessionsRevoke).Delete(rest.Root, s.AdminRevokeSessions)
+				sid.Permission(rbac.SessionsRevoke).Delete(Session, s.AdminRevokeSession)
+			})
+			uid.Route(Tokens, func(tid *rest.Router) {
+				tid.Permission(rbac.SessionsRevoke).Delete(rest.Root, s.AdminRevokeTokens)
+				tid.Permission(rbac.SessionsRevoke).Delete(Token, s.AdminRevokeToken)
+			})
+			uid.Route(Roles, func(rid *rest.Router) {
+				rid.Permission(rbac.RolesRead).Get(rest.Root, s.AdminListRoles)
+				rid.Permission(rbac.RolesAssign).Post(rest.Root, s.AdminAssignRole)
+				rid.Permission(rbac.RolesAssign).Delete(Role, s.AdminUnassignRole)
+			})
 		})
 	})
 }
@@ -73,7 +106,7 @@ func (s *usersServer) GetUser(w http.ResponseWriter, r *http.Request) {
 		s.ResponseCode(w, http.StatusBadRequest, err)
 		return
 	}
-	_, err = s.ValidateAdmin(r)
+	_, err = s.ValidateAdmin(r, rbac.UsersRead)
 	if err != nil {
 		s.ResponseCode(w, http.StatusUnauthorized, err)
 		return
@@ -103,7 +136,7 @@ func (s *usersServer) AdminUpdateUser(w http.ResponseWriter, r *http.Request) {
 		s.ResponseCode(w, http.StatusUnprocessableEntity, err)
 		return
 	}
-	_, err = s.ValidateAdmin(r)
+	_, err = s.ValidateAdmin(r, rbac.UsersWrite)
 	if err != nil {
 		s.ResponseCode(w, http.StatusUnauthorized, err)
 		return
@@ -128,7 +161,7 @@ func (s *usersServer) AdminDeleteUser(w http.ResponseWriter, r *http.Request) {
 		s.ResponseCode(w, http.StatusBadRequest, err)
 		return
 	}
-	_, err = s.ValidateAdmin(r)
+	_, err = s.ValidateAdmin(r, rbac.UsersDelete)
 	if err != nil {
 		s.ResponseCode(w, http.StatusUnauthorized, err)
 		return
@@ -153,7 +186,7 @@ func (s *usersServer) AdminPromoteUser(w http.ResponseWriter, r *http.Request) {
 		s.ResponseCode(w, http.StatusBadRequest, err)
 		return
 	}
-	_, err = s.ValidateAdmin(r)
+	_, err = s.ValidateAdmin(r, rbac.RolesAssign)
 	if err != nil {
 		s.ResponseCode(w, http.StatusUnauthorized, err)
 		return
@@ -170,6 +203,81 @@ func (s *usersServer) AdminPromoteUser(w http.ResponseWriter, r *http.Request) {
 	s.Response(w, res)
 }
 
+// AdminUnlockUser unlocks a locked user.
+func (s *usersServer) AdminUnlockUser(w http.ResponseWriter, r *http.Request) {
+	userID := rest.URLParam(r, key.UserID)
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	_, err = s.ValidateAdmin(r, rbac.UsersWrite)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	ctx := rest.FromRequest(r)
+	s.Debugf("unlock user %s", uid.String())
+	u, err := s.API.UnlockUser(ctx, uid)
+	if err != nil {
+		s.ResponseError(w, err)
+		return
+	}
+	res := rest.NewUserResponse(u)
+	s.Debugf("unlocked user %s: %v", uid, res)
+	s.Response(w, res)
+}
+
+// AdminForcePasswordChange requires a user to change their password the next time they login.
+func (s *usersServer) AdminForcePasswordChange(w http.ResponseWriter, r *http.Request) {
+	userID := rest.URLParam(r, key.UserID)
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	_, err = s.ValidateAdmin(r, rbac.UsersWrite)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	ctx := rest.FromRequest(r)
+	s.Debugf("force password change %s", uid.String())
+	u, err := s.API.ForcePasswordChange(ctx, uid)
+	if err != nil {
+		s.ResponseError(w, err)
+		return
+	}
+	res := rest.NewUserResponse(u)
+	s.Debugf("forced password change %s: %v", uid, res)
+	s.Response(w, res)
+}
+
+// AdminImpersonateUser grants the admin a short-lived bearer token to act as a user.
+func (s *usersServer) AdminImpersonateUser(w http.ResponseWriter, r *http.Request) {
+	userID := rest.URLParam(r, key.UserID)
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	_, err = s.ValidateAdmin(r, rbac.UsersImpersonate)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	ctx := rest.FromRequest(r)
+	s.Debugf("impersonate user %s", uid.String())
+	bt, err := s.API.ImpersonateUser(ctx, uid)
+	if err != nil {
+		s.ResponseCode(w, http.StatusForbidden, err)
+		return
+	}
+	res := rest.NewBearerResponse(bt)
+	s.Debugf("impersonating user %s", uid)
+	s.Response(w, res)
+}
+
 // AdminUpdateUserMetadata updates a user's metadata.
 func (s *usersServer) AdminUpdateUserMetadata(w http.ResponseWriter, r *http.Request) {
 	userID := rest.URLParam(r, key.UserID)
@@ -184,7 +292,7 @@ func (s *usersServer) AdminUpdateUserMetadata(w http.ResponseWriter, r *http.Req
 		s.ResponseCode(w, http.StatusUnprocessableEntity, err)
 		return
 	}
-	_, err = s.ValidateAdmin(r)
+	_, err = s.ValidateAdmin(r, rbac.UsersWrite)
 	if err != nil {
 		s.ResponseCode(w, http.StatusUnauthorized, err)
 		return
@@ -199,3 +307,240 @@ func (s *usersServer) AdminUpdateUserMetadata(w http.ResponseWriter, r *http.Req
 	s.Debugf("updated user metadata %s: %v", uid, u.Metadata)
 	s.Response(w, u.Metadata)
 }
+
+// AdminListSessions lists the active sessions for a user.
+func (s *usersServer) AdminListSessions(w http.ResponseWriter, r *http.Request) {
+	userID := rest.URLParam(r, key.UserID)
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	_, err = s.ValidateAdmin(r, rbac.SessionsRead)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	ctx := rest.FromRequest(r)
+	s.Debugf("list sessions %s", uid)
+	sessions, err := s.API.GetUserSessions(ctx, uid)
+	if err != nil {
+		s.ResponseError(w, err)
+		return
+	}
+	res := rest.NewSessionsResponse(sessions, uuid.Nil)
+	s.Response(w, res)
+}
+
+// AdminRevokeSession revokes a session for a user.
+func (s *usersServer) AdminRevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID := rest.URLParam(r, key.UserID)
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	sessionID := rest.URLParam(r, key.SessionID)
+	sid, err := uuid.Parse(sessionID)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	_, err = s.ValidateAdmin(r, rbac.SessionsRevoke)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	ctx := rest.FromRequest(r)
+	s.Debugf("revoke session %s: %s", sid, uid)
+	err = s.API.RevokeUserSession(ctx, uid, sid)
+	if err != nil {
+		s.ResponseError(w, err)
+		return
+	}
+	s.Response(w, nil)
+}
+
+// AdminRevokeSessions revokes all the sessions for a user.
+func (s *usersServer) AdminRevokeSessions(w http.ResponseWriter, r *http.Request) {
+	userID := rest.URLParam(r, key.UserID)
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	_, err = s.ValidateAdmin(r, rbac.SessionsRevoke)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	ctx := rest.FromRequest(r)
+	s.Debugf("revoke sessions %s", uid)
+	n, err := s.API.RevokeUserSessions(ctx, uid)
+	if err != nil {
+		s.ResponseError(w, err)
+		return
+	}
+	res := &rest.RevokedSessionsResponse{Revoked: n}
+	s.Debugf("revoked %d sessions %s", n, uid)
+	s.Response(w, res)
+}
+
+// AdminRevokeToken revokes a bearer token issued to a user.
+func (s *usersServer) AdminRevokeToken(w http.ResponseWriter, r *http.Request) {
+	userID := rest.URLParam(r, key.UserID)
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	jti := rest.URLParam(r, key.TokenID)
+	if jti == "" {
+		err = errors.New("token id required")
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	_, err = s.ValidateAdmin(r, rbac.SessionsRevoke)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	ctx := rest.FromRequest(r)
+	s.Debugf("revoke token %s: %s", jti, uid)
+	err = s.API.RevokeUserToken(ctx, uid, jti)
+	if err != nil {
+		s.ResponseError(w, err)
+		return
+	}
+	s.Response(w, nil)
+}
+
+// AdminRevokeTokens revokes all the bearer tokens issued to a user before
+// a timestamp. If the timestamp is not set, the tokens issued before now are revoked.
+func (s *usersServer) AdminRevokeTokens(w http.ResponseWriter, r *http.Request) {
+	userID := rest.URLParam(r, key.UserID)
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	req := new(Request)
+	err = rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	_, err = s.ValidateAdmin(r, rbac.SessionsRevoke)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	var before time.Time
+	if req.Before != nil {
+		before = *req.Before
+	}
+	ctx := rest.FromRequest(r)
+	s.Debugf("revoke tokens before %s: %s", before, uid)
+	err = s.API.RevokeUserTokens(ctx, uid, before)
+	if err != nil {
+		s.ResponseError(w, err)
+		return
+	}
+	s.Response(w, nil)
+}
+
+// AdminListRoles lists the custom roles assigned to a user,
+// and the permissions they grant the user.
+func (s *usersServer) AdminListRoles(w http.ResponseWriter, r *http.Request) {
+	userID := rest.URLParam(r, key.UserID)
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	_, err = s.ValidateAdmin(r, rbac.RolesRead)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	ctx := rest.FromRequest(r)
+	s.Debugf("list roles %s", uid)
+	list, err := s.API.GetUserRoles(ctx, uid)
+	if err != nil {
+		s.ResponseError(w, err)
+		return
+	}
+	perms, err := s.API.GetUserPermissions(ctx, uid)
+	if err != nil {
+		s.ResponseError(w, err)
+		return
+	}
+	res := &rest.UserRolesResponse{
+		Roles:       rest.NewRolesResponse(list),
+		Permissions: rbac.Strings(perms),
+	}
+	s.Response(w, res)
+}
+
+// AdminAssignRole assigns a custom role to a user.
+func (s *usersServer) AdminAssignRole(w http.ResponseWriter, r *http.Request) {
+	userID := rest.URLParam(r, key.UserID)
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	req := new(Request)
+	err = rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	if req.Role == "" {
+		err = errors.New("role required")
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	_, err = s.ValidateAdmin(r, rbac.RolesAssign)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	ctx := rest.FromRequest(r)
+	s.Debugf("assign role %s: %s", req.Role, uid)
+	err = s.API.AssignUserRole(ctx, uid, req.Role)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	s.Response(w, nil)
+}
+
+// AdminUnassignRole removes a custom role from a user.
+func (s *usersServer) AdminUnassignRole(w http.ResponseWriter, r *http.Request) {
+	userID := rest.URLParam(r, key.UserID)
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	role := rest.URLParam(r, key.Role)
+	if role == "" {
+		err = errors.New("role required")
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	_, err = s.ValidateAdmin(r, rbac.RolesAssign)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	ctx := rest.FromRequest(r)
+	s.Debugf("unassign role %s: %s", role, uid)
+	err = s.API.UnassignUserRole(ctx, uid, role)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	s.Response(w, nil)
+}
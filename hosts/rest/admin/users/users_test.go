@@ -3,13 +3,18 @@ package users
 import (
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 
 	"dario.cat/mergo"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/core/users"
 	"github.com/jrapoport/gothic/hosts/rest"
+	"github.com/jrapoport/gothic/jwt"
+	"github.com/jrapoport/gothic/models/rbac"
 	"github.com/jrapoport/gothic/models/types"
 	"github.com/jrapoport/gothic/models/types/key"
 	"github.com/jrapoport/gothic/models/user"
@@ -329,6 +334,190 @@ func TestUserServer_PromoteUser(t *testing.T) {
 	assert.NotEqual(t, http.StatusOK, res.Code)
 }
 
+func TestUserServer_UnlockUser(t *testing.T) {
+	t.Parallel()
+	s, _ := tsrv.RESTServer(t, false)
+	srv := newUserServer(s)
+	srv.Config().Signup.AutoConfirm = true
+	srv.Config().MaskEmails = false
+	j := srv.Config().JWT
+	u, _ := testUser(t, srv, false)
+	uri := Users + rest.Root + u.ID.String() + Unlock
+	unlockUser := func(tok string, useCtx bool, testID uuid.UUID) *httptest.ResponseRecorder {
+		r := thttp.Request(t, http.MethodPost, uri, tok, nil, nil)
+		if tok != "" {
+			var err error
+			r, err = rest.ParseClaims(r, srv.Config().JWT, tok)
+			require.NoError(t, err)
+		}
+		uid := u.ID
+		if testID != uuid.Nil {
+			uid = testID
+		}
+		if useCtx {
+			ctx := chi.NewRouteContext()
+			ctx.URLParams = chi.RouteParams{
+				Keys:   []string{key.UserID},
+				Values: []string{uid.String()},
+			}
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, ctx))
+		}
+		w := httptest.NewRecorder()
+		srv.AdminUnlockUser(w, r)
+		return w
+	}
+	// no user id slug
+	tok := thttp.UserToken(t, j, false, false)
+	res := unlockUser(tok, false, uuid.Nil)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// no admin id
+	res = unlockUser("", true, uuid.Nil)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// not admin
+	_, tok = testUser(t, srv, false)
+	res = unlockUser(tok, true, uuid.Nil)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// not locked
+	_, tok = testUser(t, srv, true)
+	res = unlockUser(tok, true, uuid.Nil)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// locked
+	conn := tconn.Conn(t, srv.Config())
+	err := users.LockUser(conn, u)
+	require.NoError(t, err)
+	res = unlockUser(tok, true, uuid.Nil)
+	assert.Equal(t, http.StatusOK, res.Code)
+	ur := userResponse(t, res.Body.String())
+	assert.Equal(t, u.ID.String(), ur.UserID)
+	u, err = srv.API.GetUser(u.ID)
+	require.NoError(t, err)
+	assert.False(t, u.IsLocked())
+	// user not found
+	res = unlockUser(tok, true, uuid.New())
+	assert.NotEqual(t, http.StatusOK, res.Code)
+}
+
+func TestUserServer_ForcePasswordChange(t *testing.T) {
+	t.Parallel()
+	s, _ := tsrv.RESTServer(t, false)
+	srv := newUserServer(s)
+	srv.Config().Signup.AutoConfirm = true
+	srv.Config().MaskEmails = false
+	j := srv.Config().JWT
+	u, _ := testUser(t, srv, false)
+	uri := Users + rest.Root + u.ID.String() + Force
+	forceChange := func(tok string, useCtx bool, testID uuid.UUID) *httptest.ResponseRecorder {
+		r := thttp.Request(t, http.MethodPost, uri, tok, nil, nil)
+		if tok != "" {
+			var err error
+			r, err = rest.ParseClaims(r, srv.Config().JWT, tok)
+			require.NoError(t, err)
+		}
+		uid := u.ID
+		if testID != uuid.Nil {
+			uid = testID
+		}
+		if useCtx {
+			ctx := chi.NewRouteContext()
+			ctx.URLParams = chi.RouteParams{
+				Keys:   []string{key.UserID},
+				Values: []string{uid.String()},
+			}
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, ctx))
+		}
+		w := httptest.NewRecorder()
+		srv.AdminForcePasswordChange(w, r)
+		return w
+	}
+	// no user id slug
+	tok := thttp.UserToken(t, j, false, false)
+	res := forceChange(tok, false, uuid.Nil)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// no admin id
+	res = forceChange("", true, uuid.Nil)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// not admin
+	_, tok = testUser(t, srv, false)
+	res = forceChange(tok, true, uuid.Nil)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// forced
+	_, tok = testUser(t, srv, true)
+	res = forceChange(tok, true, uuid.Nil)
+	assert.Equal(t, http.StatusOK, res.Code)
+	ur := userResponse(t, res.Body.String())
+	assert.Equal(t, u.ID.String(), ur.UserID)
+	u, err := srv.API.GetUser(u.ID)
+	require.NoError(t, err)
+	assert.True(t, u.MustChangePassword)
+	// user not found
+	res = forceChange(tok, true, uuid.New())
+	assert.NotEqual(t, http.StatusOK, res.Code)
+}
+
+func TestUserServer_ImpersonateUser(t *testing.T) {
+	t.Parallel()
+	s, _ := tsrv.RESTServer(t, false)
+	srv := newUserServer(s)
+	srv.Config().Signup.AutoConfirm = true
+	j := srv.Config().JWT
+	u, _ := testUser(t, srv, false)
+	uri := Users + rest.Root + u.ID.String() + Impersonate
+	impersonate := func(tok string, useCtx bool, testID uuid.UUID) *httptest.ResponseRecorder {
+		r := thttp.Request(t, http.MethodPost, uri, tok, nil, nil)
+		if tok != "" {
+			var err error
+			r, err = rest.ParseClaims(r, srv.Config().JWT, tok)
+			require.NoError(t, err)
+		}
+		uid := u.ID
+		if testID != uuid.Nil {
+			uid = testID
+		}
+		if useCtx {
+			ctx := chi.NewRouteContext()
+			ctx.URLParams = chi.RouteParams{
+				Keys:   []string{key.UserID},
+				Values: []string{uid.String()},
+			}
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, ctx))
+		}
+		w := httptest.NewRecorder()
+		srv.AdminImpersonateUser(w, r)
+		return w
+	}
+	// no user id slug
+	tok := thttp.UserToken(t, j, false, false)
+	res := impersonate(tok, false, uuid.Nil)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// no admin id
+	res = impersonate("", true, uuid.Nil)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// not admin
+	_, tok = testUser(t, srv, false)
+	res = impersonate(tok, true, uuid.Nil)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// impersonated
+	adm, tok := testUser(t, srv, true)
+	res = impersonate(tok, true, uuid.Nil)
+	assert.Equal(t, http.StatusOK, res.Code)
+	var br rest.BearerResponse
+	err := json.Unmarshal(res.Body.Bytes(), &br)
+	require.NoError(t, err)
+	assert.Empty(t, br.Refresh)
+	assert.NotNil(t, br.ExpiresAt)
+	claims, err := jwt.ParseUserClaims(j, br.Access)
+	require.NoError(t, err)
+	assert.Equal(t, u.ID, claims.UserID())
+	assert.Equal(t, adm.ID, claims.ActorID())
+	// admins cannot be impersonated
+	adm2, _ := testUser(t, srv, true)
+	res = impersonate(tok, true, adm2.ID)
+	assert.Equal(t, http.StatusForbidden, res.Code)
+	// user not found
+	res = impersonate(tok, true, uuid.New())
+	assert.NotEqual(t, http.StatusOK, res.Code)
+}
+
 func TestUserServer_AdminUpdateUserMetadata(t *testing.T) {
 	t.Parallel()
 	s, _ := tsrv.RESTServer(t, false)
@@ -399,3 +588,246 @@ func TestUserServer_AdminUpdateUserMetadata(t *testing.T) {
 	}
 	assert.Equal(t, test, meta)
 }
+
+func TestUserServer_AdminSessions(t *testing.T) {
+	t.Parallel()
+	s, _ := tsrv.RESTServer(t, false)
+	srv := newUserServer(s)
+	srv.Config().Signup.AutoConfirm = true
+	j := srv.Config().JWT
+	u, _ := testUser(t, srv, false)
+	_, err := srv.API.GrantBearerToken(nil, u)
+	require.NoError(t, err)
+	uri := Users + rest.Root + u.ID.String() + Sessions
+	doSessions := func(method, tok string, handler http.HandlerFunc, params map[string]string) *httptest.ResponseRecorder {
+		r := thttp.Request(t, method, uri, tok, nil, nil)
+		if tok != "" {
+			r, err = rest.ParseClaims(r, srv.Config().JWT, tok)
+			require.NoError(t, err)
+		}
+		if params != nil {
+			ctx := chi.NewRouteContext()
+			for k, v := range params {
+				ctx.URLParams.Add(k, v)
+			}
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, ctx))
+		}
+		w := httptest.NewRecorder()
+		handler(w, r)
+		return w
+	}
+	uidParam := map[string]string{key.UserID: u.ID.String()}
+	// no user id slug
+	tok := thttp.UserToken(t, j, false, false)
+	res := doSessions(http.MethodGet, tok, srv.AdminListSessions, nil)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = doSessions(http.MethodDelete, tok, srv.AdminRevokeSessions, nil)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = doSessions(http.MethodDelete, tok, srv.AdminRevokeSession, nil)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// no session id slug
+	res = doSessions(http.MethodDelete, tok, srv.AdminRevokeSession, uidParam)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// no admin id
+	res = doSessions(http.MethodGet, "", srv.AdminListSessions, uidParam)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = doSessions(http.MethodDelete, "", srv.AdminRevokeSessions, uidParam)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// not admin
+	_, tok = testUser(t, srv, false)
+	res = doSessions(http.MethodGet, tok, srv.AdminListSessions, uidParam)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// list
+	_, tok = testUser(t, srv, true)
+	res = doSessions(http.MethodGet, tok, srv.AdminListSessions, uidParam)
+	require.Equal(t, http.StatusOK, res.Code)
+	var list []*rest.SessionResponse
+	err = json.Unmarshal(res.Body.Bytes(), &list)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	// user not found
+	res = doSessions(http.MethodGet, tok, srv.AdminListSessions,
+		map[string]string{key.UserID: uuid.New().String()})
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// revoke one
+	params := map[string]string{
+		key.UserID:    u.ID.String(),
+		key.SessionID: uuid.New().String(),
+	}
+	res = doSessions(http.MethodDelete, tok, srv.AdminRevokeSession, params)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	params[key.SessionID] = list[0].SessionID
+	res = doSessions(http.MethodDelete, tok, srv.AdminRevokeSession, params)
+	assert.Equal(t, http.StatusOK, res.Code)
+	// revoke all
+	res = doSessions(http.MethodDelete, tok, srv.AdminRevokeSessions, uidParam)
+	require.Equal(t, http.StatusOK, res.Code)
+	var rr rest.RevokedSessionsResponse
+	err = json.Unmarshal(res.Body.Bytes(), &rr)
+	require.NoError(t, err)
+	assert.Equal(t, 1, rr.Revoked)
+	sessions, err := srv.API.GetSessions(nil, u.ID)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 0)
+}
+
+func TestUserServer_AdminTokens(t *testing.T) {
+	t.Parallel()
+	s, _ := tsrv.RESTServer(t, false)
+	srv := newUserServer(s)
+	srv.Config().Signup.AutoConfirm = true
+	j := srv.Config().JWT
+	u, utok := testUser(t, srv, false)
+	claims, err := jwt.ParseUserClaims(j, utok)
+	require.NoError(t, err)
+	uri := Users + rest.Root + u.ID.String() + Tokens
+	doTokens := func(tok string, handler http.HandlerFunc, params map[string]string, v url.Values) *httptest.ResponseRecorder {
+		r := thttp.Request(t, http.MethodDelete, uri, tok, v, nil)
+		if tok != "" {
+			r, err = rest.ParseClaims(r, srv.Config().JWT, tok)
+			require.NoError(t, err)
+		}
+		if params != nil {
+			ctx := chi.NewRouteContext()
+			for k, v := range params {
+				ctx.URLParams.Add(k, v)
+			}
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, ctx))
+		}
+		w := httptest.NewRecorder()
+		handler(w, r)
+		return w
+	}
+	uidParam := map[string]string{key.UserID: u.ID.String()}
+	// no user id slug
+	tok := thttp.UserToken(t, j, false, false)
+	res := doTokens(tok, srv.AdminRevokeTokens, nil, nil)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = doTokens(tok, srv.AdminRevokeToken, nil, nil)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// no token id slug
+	res = doTokens(tok, srv.AdminRevokeToken, uidParam, nil)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// no admin id
+	res = doTokens("", srv.AdminRevokeTokens, uidParam, nil)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	params := map[string]string{
+		key.UserID:  u.ID.String(),
+		key.TokenID: claims.JwtID(),
+	}
+	res = doTokens("", srv.AdminRevokeToken, params, nil)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// not admin
+	_, tok = testUser(t, srv, false)
+	res = doTokens(tok, srv.AdminRevokeToken, params, nil)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// revoke one
+	_, tok = testUser(t, srv, true)
+	res = doTokens(tok, srv.AdminRevokeToken, map[string]string{
+		key.UserID:  uuid.New().String(),
+		key.TokenID: claims.JwtID(),
+	}, nil)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = doTokens(tok, srv.AdminRevokeToken, params, nil)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Error(t, srv.API.ValidateBearerToken(claims))
+	// revoke before
+	bt, err := srv.API.GrantBearerToken(nil, u)
+	require.NoError(t, err)
+	claims, err = jwt.ParseUserClaims(j, bt.String())
+	require.NoError(t, err)
+	before := url.Values{"before": []string{"bad"}}
+	res = doTokens(tok, srv.AdminRevokeTokens, uidParam, before)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	before.Set("before", claims.Issued().Add(-time.Hour).Format(time.RFC3339))
+	res = doTokens(tok, srv.AdminRevokeTokens, uidParam, before)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.NoError(t, srv.API.ValidateBearerToken(claims))
+	// revoke all
+	res = doTokens(tok, srv.AdminRevokeTokens, uidParam, nil)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Error(t, srv.API.ValidateBearerToken(claims))
+}
+
+func TestUserServer_AdminRoles(t *testing.T) {
+	t.Parallel()
+	s, _ := tsrv.RESTServer(t, false)
+	srv := newUserServer(s)
+	srv.Config().Signup.AutoConfirm = true
+	u, _ := testUser(t, srv, false)
+	_, tok := testUser(t, srv, true)
+	rctx := context.Background()
+	rctx.SetAdminID(user.SuperAdminID)
+	perms := []string{rbac.UsersRead.String()}
+	_, err := srv.API.CreateRole(rctx, "support", "", perms)
+	require.NoError(t, err)
+	uri := Users + rest.Root + u.ID.String() + Roles
+	doRoles := func(method, tok string, handler http.HandlerFunc, params map[string]string, body interface{}) *httptest.ResponseRecorder {
+		r := thttp.Request(t, method, uri, tok, nil, body)
+		if tok != "" {
+			r, err = rest.ParseClaims(r, srv.Config().JWT, tok)
+			require.NoError(t, err)
+		}
+		if params != nil {
+			ctx := chi.NewRouteContext()
+			for k, v := range params {
+				ctx.URLParams.Add(k, v)
+			}
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, ctx))
+		}
+		w := httptest.NewRecorder()
+		handler(w, r)
+		return w
+	}
+	listRoles := func() *rest.UserRolesResponse {
+		res := doRoles(http.MethodGet, tok, srv.AdminListRoles,
+			map[string]string{key.UserID: u.ID.String()}, nil)
+		require.Equal(t, http.StatusOK, res.Code)
+		var ur rest.UserRolesResponse
+		err = json.Unmarshal(res.Body.Bytes(), &ur)
+		require.NoError(t, err)
+		return &ur
+	}
+	uidParam := map[string]string{key.UserID: u.ID.String()}
+	req := &Request{Role: "support"}
+	// no user id slug
+	res := doRoles(http.MethodPost, tok, srv.AdminAssignRole, nil, req)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// no role
+	res = doRoles(http.MethodPost, tok, srv.AdminAssignRole, uidParam, &Request{})
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// no admin id
+	res = doRoles(http.MethodPost, "", srv.AdminAssignRole, uidParam, req)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// not admin
+	_, utok := testUser(t, srv, false)
+	res = doRoles(http.MethodPost, utok, srv.AdminAssignRole, uidParam, req)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	// bad role
+	res = doRoles(http.MethodPost, tok, srv.AdminAssignRole, uidParam, &Request{Role: "missing"})
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	ur := listRoles()
+	assert.Len(t, ur.Roles, 0)
+	assert.Len(t, ur.Permissions, 0)
+	res = doRoles(http.MethodPost, tok, srv.AdminAssignRole, uidParam, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+	ur = listRoles()
+	require.Len(t, ur.Roles, 1)
+	assert.Equal(t, "support", ur.Roles[0].Name)
+	assert.Equal(t, perms, ur.Permissions)
+	// unassign
+	roleParam := map[string]string{
+		key.UserID: u.ID.String(),
+		key.Role:   "support",
+	}
+	res = doRoles(http.MethodDelete, tok, srv.AdminUnassignRole, uidParam, nil)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = doRoles(http.MethodDelete, utok, srv.AdminUnassignRole, roleParam, nil)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	res = doRoles(http.MethodDelete, tok, srv.AdminUnassignRole, roleParam, nil)
+	assert.Equal(t, http.StatusOK, res.Code)
+	res = doRoles(http.MethodDelete, tok, srv.AdminUnassignRole, roleParam, nil)
+	assert.NotEqual(t, http.StatusOK, res.Code)
+	ur = listRoles()
+	assert.Len(t, ur.Roles, 0)
+}
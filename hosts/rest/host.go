@@ -26,17 +26,15 @@ var _ core.Hosted = (*Host)(nil)
 func NewHost(a *core.API, name string, address string, reg []RegisterServer) *Host {
 	h := core.NewHost(a, name, address)
 	h.Logger = h.Log().WithName("http")
-	rt := NewRouter(h.Config())
-	//rt.UseLogger(h.Logger)
+	var handler http.Handler = newRouter(a, &h.Server, reg)
+	if a.TenantsEnabled() {
+		handler = newTenantHandler(h, handler, reg)
+	}
 	server := &http.Server{
-		Handler: rt,
+		Handler: handler,
 	}
 	log := httpLogger(h.Config().Level)
 	server.ErrorLog = http_logrus.AsHttpLogger(log)
-	for _, r := range reg {
-		srv := NewServer(h.Server.Clone())
-		r(server, srv)
-	}
 	return &Host{h, server}
 }
 
@@ -2,6 +2,7 @@ package rest
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -9,7 +10,10 @@ import (
 	"github.com/go-chi/cors"
 	"github.com/go-chi/httptracer"
 	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/core/context"
 	"github.com/jrapoport/gothic/jwt"
+	"github.com/jrapoport/gothic/models/rbac"
+	"github.com/jrapoport/gothic/models/token"
 	"github.com/opentracing/opentracing-go"
 )
 
@@ -72,8 +76,18 @@ func Tracer(h http.Handler) http.Handler {
 	return fn(h)
 }
 
+// RevocationCheck returns an error if the jwt token for the claims has been revoked.
+type RevocationCheck func(claims *jwt.UserClaims) error
+
+// PersonalTokenCheck returns the claims for a personal access token used from
+// the ip address, or an error if the personal access token is not valid.
+type PersonalTokenCheck func(tok, ip string) (*jwt.UserClaims, error)
+
 // Authenticator creates a new JWT handler with passed options.
-func Authenticator(jc config.JWT) func(next http.Handler) http.Handler {
+// If the revocation check is not nil, revoked tokens are rejected.
+// If the personal token check is not nil, personal access tokens are
+// accepted and the requests they make are limited to their scopes.
+func Authenticator(jc config.JWT, revoked RevocationCheck, personal PersonalTokenCheck) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// did we already do this?
@@ -90,12 +104,77 @@ func Authenticator(jc config.JWT) func(next http.Handler) http.Handler {
 				ResponseCode(w, http.StatusUnauthorized, nil)
 				return
 			}
+			if personal != nil && token.IsPersonalToken(tok) {
+				claims, err := personal(tok, ClientIP(r))
+				if err != nil {
+					l.Error(err)
+					ResponseCode(w, http.StatusUnauthorized, nil)
+					return
+				}
+				if err = personalScope(r, claims); err != nil {
+					l.Error(err)
+					ResponseCode(w, http.StatusForbidden, nil)
+					return
+				}
+				next.ServeHTTP(w, WithClaims(r, claims))
+				return
+			}
 			r, err := ParseClaims(r, jc, tok)
 			if err != nil {
 				l.Error(err)
 				ResponseCode(w, http.StatusUnauthorized, nil)
 				return
 			}
+			if revoked != nil {
+				claims, _ := GetUserClaims(r)
+				if err = revoked(claims); err != nil {
+					l.Error(err)
+					ResponseCode(w, http.StatusUnauthorized, nil)
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// personalScope returns an error if the scopes of a personal access token do
+// not allow the request. The read scope only allows requests that do not
+// change anything, the write scope allows all requests. Routes that manage
+// sessions or tokens also reject personal access tokens (see SessionUser).
+func personalScope(r *http.Request, claims *jwt.UserClaims) error {
+	if claims.HasScope(token.ScopeWrite) {
+		return nil
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		if claims.HasScope(token.ScopeRead) {
+			return nil
+		}
+	}
+	return errors.New("insufficient token scope")
+}
+
+// ImpersonationLog records a request made while impersonating a user.
+type ImpersonationLog func(ctx context.Context, method, path string) error
+
+// Impersonation creates a new JWT handler that records the requests made
+// while an admin is impersonating a user. If the request cannot be recorded
+// it is not allowed.
+func Impersonation(log ImpersonationLog) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, err := GetUserClaims(r)
+			if log == nil || err != nil || !claims.Impersonated() {
+				next.ServeHTTP(w, r)
+				return
+			}
+			err = log(FromRequest(r), r.Method, r.URL.Path)
+			if err != nil {
+				GetLogger(r).Error(err)
+				ResponseCode(w, http.StatusInternalServerError, nil)
+				return
+			}
 			next.ServeHTTP(w, r)
 		})
 	}
@@ -104,6 +183,9 @@ func Authenticator(jc config.JWT) func(next http.Handler) http.Handler {
 // AdminUser creates a new JWT handler that checks for admin permissions.
 func AdminUser(next http.Handler) http.Handler {
 	return claimCheck(func(claims *jwt.UserClaims) error {
+		if claims.SubjectType() != jwt.SubjectUser {
+			return errors.New("user token required")
+		}
 		if !claims.Confirmed() {
 			return errors.New("user not confirmed")
 		}
@@ -114,9 +196,43 @@ func AdminUser(next http.Handler) http.Handler {
 	})(next)
 }
 
+// Permission creates a new JWT handler that checks the user has all
+// the permissions. Admin users have all the permissions.
+func Permission(perms ...rbac.Permission) func(next http.Handler) http.Handler {
+	return claimCheck(func(claims *jwt.UserClaims) error {
+		if claims.SubjectType() != jwt.SubjectUser {
+			return errors.New("user token required")
+		}
+		if !claims.Confirmed() {
+			return errors.New("user not confirmed")
+		}
+		for _, p := range perms {
+			if !claims.HasPermission(p.String()) {
+				return fmt.Errorf("permission required: %s", p)
+			}
+		}
+		return nil
+	})
+}
+
+// SessionUser creates a new JWT handler that checks the request was not made
+// with a personal access token. Personal access tokens cannot manage the
+// sessions or tokens of the user, whatever their scopes.
+func SessionUser(next http.Handler) http.Handler {
+	return claimCheck(func(claims *jwt.UserClaims) error {
+		if claims.PersonalToken() {
+			return errors.New("personal token not allowed")
+		}
+		return nil
+	})(next)
+}
+
 // ConfirmedUser creates a new JWT handler that checks for user confirmation.
 func ConfirmedUser(next http.Handler) http.Handler {
 	return claimCheck(func(claims *jwt.UserClaims) error {
+		if claims.SubjectType() != jwt.SubjectUser {
+			return errors.New("user token required")
+		}
 		if !claims.Confirmed() {
 			return errors.New("user not confirmed")
 		}
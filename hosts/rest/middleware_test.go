@@ -1,12 +1,23 @@
 package rest
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/jwt"
+	"github.com/jrapoport/gothic/models/client"
+	"github.com/jrapoport/gothic/models/rbac"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/user"
 	"github.com/jrapoport/gothic/test/tconf"
 	"github.com/jrapoport/gothic/test/thttp"
+	"github.com/jrapoport/gothic/test/tutils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -50,4 +61,200 @@ func TestProtect(t *testing.T) {
 	r.Admin().Confirmed().Get("/confirmed-admin", resOk)
 	_, err = thttp.DoAuthRequest(t, s, http.MethodGet, "/confirmed-admin", tok, nil, nil)
 	assert.NoError(t, err)
+	// service tokens are not user tokens
+	svc := client.NewService("test", nil, uuid.New(), nil)
+	tok, err = jwt.NewServiceToken(j, svc, nil).Bearer()
+	assert.NoError(t, err)
+	for _, path := range []string{"/", "/confirmed", "/admin"} {
+		_, err = thttp.DoAuthRequest(t, s, http.MethodGet, path, tok, nil, nil)
+		assert.Error(t, err)
+	}
+}
+
+func TestProtect_Permission(t *testing.T) {
+	t.Parallel()
+	c := tconf.Config(t)
+	r := NewRouter(c)
+	s := httptest.NewServer(r)
+	j := c.JWT
+	r = r.Authenticate(j)
+	resOk := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+	r.Permission(rbac.UsersRead).Get("/users", resOk)
+	r.Permission(rbac.UsersRead, rbac.UsersWrite).Get("/users/write", resOk)
+	permToken := func(confirmed bool, perms ...rbac.Permission) string {
+		claims := jwt.NewUserClaims(&user.User{})
+		claims.StandardClaims = *jwt.NewStandardClaims(uuid.New().String())
+		_ = claims.Set(jwt.ConfirmedKey, confirmed)
+		claims.SetPermissions(rbac.Strings(perms))
+		tok, err := jwt.NewToken(j, claims).Bearer()
+		assert.NoError(t, err)
+		return tok
+	}
+	// no permissions
+	tok := thttp.UserToken(t, j, true, false)
+	_, err := thttp.DoAuthRequest(t, s, http.MethodGet, "/users", tok, nil, nil)
+	assert.Error(t, err)
+	// not confirmed
+	tok = permToken(false, rbac.UsersRead)
+	_, err = thttp.DoAuthRequest(t, s, http.MethodGet, "/users", tok, nil, nil)
+	assert.Error(t, err)
+	tok = permToken(true, rbac.UsersRead)
+	_, err = thttp.DoAuthRequest(t, s, http.MethodGet, "/users", tok, nil, nil)
+	assert.NoError(t, err)
+	// all the permissions are required
+	_, err = thttp.DoAuthRequest(t, s, http.MethodGet, "/users/write", tok, nil, nil)
+	assert.Error(t, err)
+	tok = permToken(true, rbac.UsersRead, rbac.UsersWrite)
+	_, err = thttp.DoAuthRequest(t, s, http.MethodGet, "/users/write", tok, nil, nil)
+	assert.NoError(t, err)
+	// admins have all the permissions
+	tok = thttp.UserToken(t, j, true, true)
+	_, err = thttp.DoAuthRequest(t, s, http.MethodGet, "/users/write", tok, nil, nil)
+	assert.NoError(t, err)
+}
+
+func TestProtect_Revoked(t *testing.T) {
+	t.Parallel()
+	c := tconf.Config(t)
+	r := NewRouter(c)
+	s := httptest.NewServer(r)
+	j := c.JWT
+	revoked := thttp.UserToken(t, j, false, false)
+	claims, err := jwt.ParseUserClaims(j, revoked)
+	assert.NoError(t, err)
+	r.UseRevocation(func(uc *jwt.UserClaims) error {
+		if uc.JwtID() == claims.JwtID() {
+			return errors.New("revoked")
+		}
+		return nil
+	})
+	r.Route("/revoke", func(rt *Router) {
+		rt.Authenticated().Get(Root, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+	})
+	_, err = thttp.DoAuthRequest(t, s, http.MethodGet, "/revoke", revoked, nil, nil)
+	assert.Error(t, err)
+	tok := thttp.UserToken(t, j, false, false)
+	_, err = thttp.DoAuthRequest(t, s, http.MethodGet, "/revoke", tok, nil, nil)
+	assert.NoError(t, err)
+}
+
+func TestProtect_PersonalToken(t *testing.T) {
+	t.Parallel()
+	c := tconf.Config(t)
+	r := NewRouter(c)
+	s := httptest.NewServer(r)
+	u := &user.User{
+		ID:          uuid.New(),
+		Role:        user.RoleAdmin,
+		Status:      user.Active,
+		Email:       tutils.RandomEmail(),
+		ConfirmedAt: &time.Time{},
+	}
+	u.CreatedAt = time.Now()
+	tokens := map[string]*token.PersonalToken{}
+	grant := func(scopes ...string) string {
+		pt := token.NewPersonalToken(u.ID, "test", strings.Join(scopes, " "), 0)
+		pt.CreatedAt = time.Now()
+		tokens[pt.Secret] = pt
+		return pt.Secret
+	}
+	// personal tokens are not jwt tokens, so they are never revoked here
+	r.UseRevocation(func(uc *jwt.UserClaims) error {
+		return errors.New("revoked")
+	})
+	r.UsePersonalTokens(func(tok, ip string) (*jwt.UserClaims, error) {
+		assert.Equal(t, "127.0.0.1", ip)
+		pt, ok := tokens[tok]
+		if !ok {
+			return nil, errors.New("invalid token")
+		}
+		return jwt.NewPersonalClaims(u, pt), nil
+	})
+	resOk := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+	r.Route("/pat", func(rt *Router) {
+		rt.Authenticated().Get(Root, resOk)
+		rt.Authenticated().Post(Root, resOk)
+		rt.Authenticated().Admin().Get("/admin", resOk)
+		rt.Authenticated().Session().Get("/session", resOk)
+	})
+	// unknown personal token
+	_, err := thttp.DoAuthRequest(t, s, http.MethodGet, "/pat", token.PersonalTokenPrefix+"bad", nil, nil)
+	assert.Error(t, err)
+	// read
+	tok := grant(token.ScopeRead)
+	_, err = thttp.DoAuthRequest(t, s, http.MethodGet, "/pat", tok, nil, nil)
+	assert.NoError(t, err)
+	_, err = thttp.DoAuthRequest(t, s, http.MethodPost, "/pat", tok, nil, nil)
+	assert.Error(t, err)
+	_, err = thttp.DoAuthRequest(t, s, http.MethodGet, "/pat/admin", tok, nil, nil)
+	assert.Error(t, err)
+	_, err = thttp.DoAuthRequest(t, s, http.MethodGet, "/pat/session", tok, nil, nil)
+	assert.Error(t, err)
+	// write
+	tok = grant(token.ScopeWrite)
+	_, err = thttp.DoAuthRequest(t, s, http.MethodGet, "/pat", tok, nil, nil)
+	assert.NoError(t, err)
+	_, err = thttp.DoAuthRequest(t, s, http.MethodPost, "/pat", tok, nil, nil)
+	assert.NoError(t, err)
+	_, err = thttp.DoAuthRequest(t, s, http.MethodGet, "/pat/admin", tok, nil, nil)
+	assert.Error(t, err)
+	_, err = thttp.DoAuthRequest(t, s, http.MethodGet, "/pat/session", tok, nil, nil)
+	assert.Error(t, err)
+	// admin
+	tok = grant(token.ScopeAdmin)
+	_, err = thttp.DoAuthRequest(t, s, http.MethodGet, "/pat/admin", tok, nil, nil)
+	assert.Error(t, err)
+	tok = grant(token.ScopeRead, token.ScopeAdmin)
+	_, err = thttp.DoAuthRequest(t, s, http.MethodGet, "/pat/admin", tok, nil, nil)
+	assert.NoError(t, err)
+}
+
+func TestProtect_Impersonation(t *testing.T) {
+	t.Parallel()
+	c := tconf.Config(t)
+	r := NewRouter(c)
+	s := httptest.NewServer(r)
+	j := c.JWT
+	uid := uuid.New()
+	aid := uuid.New()
+	var logged []string
+	r.UseImpersonationLog(func(ctx context.Context, method, path string) error {
+		assert.Equal(t, uid, ctx.UserID())
+		assert.Equal(t, aid, ctx.ImpersonatorID())
+		assert.Equal(t, uuid.Nil, ctx.AdminID())
+		logged = append(logged, method+" "+path)
+		if path == "/imp/fail" {
+			return errors.New("log failed")
+		}
+		return nil
+	})
+	resOk := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+	r.Route("/imp", func(rt *Router) {
+		rt.Authenticated().Get(Root, resOk)
+		rt.Authenticated().Get("/fail", resOk)
+	})
+	// not impersonated
+	tok := thttp.UserToken(t, j, true, false)
+	_, err := thttp.DoAuthRequest(t, s, http.MethodGet, "/imp", tok, nil, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, logged)
+	// impersonated
+	claims := jwt.NewUserClaims(&user.User{ID: uid})
+	claims.SetActor(aid)
+	tok, err = jwt.NewToken(j, claims).Bearer()
+	assert.NoError(t, err)
+	_, err = thttp.DoAuthRequest(t, s, http.MethodGet, "/imp", tok, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"GET /imp"}, logged)
+	// requests that are not logged are not allowed
+	_, err = thttp.DoAuthRequest(t, s, http.MethodGet, "/imp/fail", tok, nil, nil)
+	assert.Error(t, err)
 }
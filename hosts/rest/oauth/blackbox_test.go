@@ -0,0 +1,224 @@
+package oauth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/core/oidc"
+	"github.com/jrapoport/gothic/hosts/rest"
+	"github.com/jrapoport/gothic/hosts/rest/oauth"
+	"github.com/jrapoport/gothic/jwt"
+	"github.com/jrapoport/gothic/models/client"
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tcore"
+	"github.com/jrapoport/gothic/test/thttp"
+	"github.com/jrapoport/gothic/test/tsrv"
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const redirectURI = "https://example.com/callback"
+
+func testServer(t *testing.T) (*rest.Host, *httptest.Server, *client.Client, string) {
+	srv, web, _ := tsrv.RESTHost(t, []rest.RegisterServer{
+		oauth.RegisterServer,
+	}, false)
+	srv.Config().Signup.AutoConfirm = true
+	ctx := context.Background()
+	ctx.SetAdminID(user.SuperAdminID)
+	c, secret, err := srv.API.CreateClient(ctx, "test",
+		[]string{redirectURI}, nil, true)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		web.Close()
+	})
+	return srv, web, c, secret
+}
+
+func authorizationRequest(c *client.Client) url.Values {
+	return url.Values{
+		key.ClientID:    {c.ClientID},
+		key.RedirectURI: {redirectURI},
+		"response_type": {oidc.ResponseTypeCode},
+		key.Scope:       {"openid email"},
+		key.State:       {"state"},
+		"nonce":         {"nonce"},
+	}
+}
+
+func TestOAuthServer_AuthorizationCodeFlow(t *testing.T) {
+	t.Parallel()
+	srv, web, c, secret := testServer(t)
+	u, tok := tcore.TestUser(t, srv.API, "", false)
+	uri := oauth.Endpoint + oauth.Authorize
+	v := authorizationRequest(c)
+	// not authorized
+	_, err := thttp.DoRequest(t, web, http.MethodGet, uri, v, nil)
+	assert.Error(t, err)
+	// the consent screen
+	res, err := thttp.DoAuthRequest(t, web, http.MethodGet, uri, tok, v, nil)
+	require.NoError(t, err)
+	var auth oidc.Authorization
+	err = json.Unmarshal([]byte(res), &auth)
+	require.NoError(t, err)
+	assert.Equal(t, c.Name, auth.ClientName)
+	assert.Equal(t, []string{oidc.ScopeOpenID, oidc.ScopeEmail}, auth.Scopes)
+	assert.False(t, auth.Consented)
+	// bad client
+	bad := authorizationRequest(c)
+	bad.Set(key.ClientID, "bad")
+	_, err = thttp.DoAuthRequest(t, web, http.MethodGet, uri, tok, bad, nil)
+	assert.Error(t, err)
+	// denied
+	res, err = thttp.DoAuthRequest(t, web, http.MethodPost, uri, tok, nil, types.Map{
+		key.ClientID:    c.ClientID,
+		key.RedirectURI: redirectURI,
+		"response_type": oidc.ResponseTypeCode,
+		key.Scope:       "openid email",
+		key.State:       "state",
+		"approved":      false,
+	})
+	require.NoError(t, err)
+	var ar oauth.AuthorizeResponse
+	err = json.Unmarshal([]byte(res), &ar)
+	require.NoError(t, err)
+	ru, err := url.Parse(ar.RedirectTo)
+	require.NoError(t, err)
+	assert.Equal(t, string(oidc.AccessDenied), ru.Query().Get("error"))
+	// approved
+	res, err = thttp.DoAuthRequest(t, web, http.MethodPost, uri, tok, nil, types.Map{
+		key.ClientID:    c.ClientID,
+		key.RedirectURI: redirectURI,
+		"response_type": oidc.ResponseTypeCode,
+		key.Scope:       "openid email",
+		key.State:       "state",
+		"nonce":         "nonce",
+		"approved":      true,
+	})
+	require.NoError(t, err)
+	err = json.Unmarshal([]byte(res), &ar)
+	require.NoError(t, err)
+	ru, err = url.Parse(ar.RedirectTo)
+	require.NoError(t, err)
+	assert.Equal(t, "state", ru.Query().Get(key.State))
+	code := ru.Query().Get(key.Code)
+	require.NotEmpty(t, code)
+	// exchange the code
+	form := url.Values{
+		"grant_type":    {oidc.GrantAuthorizationCode},
+		key.Code:        {code},
+		key.RedirectURI: {redirectURI},
+		key.ClientID:    {c.ClientID},
+		"client_secret": {secret},
+	}
+	res, err = thttp.DoRequest(t, web, http.MethodPost, oauth.Endpoint+oauth.Token, form, form)
+	require.NoError(t, err)
+	var tr oidc.TokenResponse
+	err = json.Unmarshal([]byte(res), &tr)
+	require.NoError(t, err)
+	assert.Equal(t, oidc.TokenTypeBearer, tr.TokenType)
+	assert.Equal(t, "openid email", tr.Scope)
+	j := srv.Config().JWT
+	claims, err := jwt.ParseIDClaims(j, srv.Config().OIDC.Issuer, c.ClientID, tr.IDToken)
+	require.NoError(t, err)
+	assert.Equal(t, u.ID, claims.UserID())
+	assert.Equal(t, "nonce", claims.Nonce())
+	// the code is single use
+	_, err = thttp.DoRequest(t, web, http.MethodPost, oauth.Endpoint+oauth.Token, form, form)
+	assert.Error(t, err)
+	// the userinfo endpoint
+	res, err = thttp.DoAuthRequest(t, web, http.MethodGet, oauth.Endpoint+oauth.UserInfo, tr.AccessToken, nil, nil)
+	require.NoError(t, err)
+	var info types.Map
+	err = json.Unmarshal([]byte(res), &info)
+	require.NoError(t, err)
+	assert.Equal(t, u.ID.String(), info[oidc.ClaimSubject])
+	assert.Equal(t, u.Email, info[oidc.ClaimEmail])
+	// bearer tokens are not access tokens
+	_, err = thttp.DoAuthRequest(t, web, http.MethodGet, oauth.Endpoint+oauth.UserInfo, tok, nil, nil)
+	assert.Error(t, err)
+	// ID tokens are not bearer tokens
+	_, err = thttp.DoAuthRequest(t, web, http.MethodGet, uri, tr.IDToken, v, nil)
+	assert.Error(t, err)
+	// consent was recorded
+	res, err = thttp.DoAuthRequest(t, web, http.MethodGet, uri, tok, v, nil)
+	require.NoError(t, err)
+	err = json.Unmarshal([]byte(res), &auth)
+	require.NoError(t, err)
+	assert.True(t, auth.Consented)
+}
+
+func TestOAuthServer_DeviceFlow(t *testing.T) {
+	t.Parallel()
+	srv, web, c, secret := testServer(t)
+	srv.Config().OIDC.DeviceInterval = 0
+	u, tok := tcore.TestUser(t, srv.API, "", false)
+	// the device asks for a user code
+	form := url.Values{
+		key.ClientID:    {c.ClientID},
+		"client_secret": {secret},
+		key.Scope:       {"openid profile"},
+	}
+	uri := oauth.Endpoint + oauth.DeviceAuthorization
+	res, err := thttp.DoRequest(t, web, http.MethodPost, uri, form, form)
+	require.NoError(t, err)
+	var dr oidc.DeviceAuthorizationResponse
+	err = json.Unmarshal([]byte(res), &dr)
+	require.NoError(t, err)
+	assert.NotEmpty(t, dr.DeviceCode)
+	assert.NotEmpty(t, dr.UserCode)
+	assert.Equal(t, oidc.VerificationURI(srv.Config()), dr.VerificationURI)
+	// the device polls
+	poll := url.Values{
+		"grant_type":    {oidc.GrantDeviceCode},
+		"device_code":   {dr.DeviceCode},
+		key.ClientID:    {c.ClientID},
+		"client_secret": {secret},
+	}
+	tokenURI := oauth.Endpoint + oauth.Token
+	_, err = thttp.DoRequest(t, web, http.MethodPost, tokenURI, poll, poll)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), string(oidc.AuthorizationPending))
+	// the user enters the user code
+	uri = oauth.Endpoint + oauth.Device
+	v := url.Values{key.UserCode: {dr.UserCode}}
+	_, err = thttp.DoRequest(t, web, http.MethodGet, uri, v, nil)
+	assert.Error(t, err)
+	res, err = thttp.DoAuthRequest(t, web, http.MethodGet, uri, tok, v, nil)
+	require.NoError(t, err)
+	var auth oidc.Authorization
+	err = json.Unmarshal([]byte(res), &auth)
+	require.NoError(t, err)
+	assert.Equal(t, c.Name, auth.ClientName)
+	assert.Equal(t, []string{oidc.ScopeOpenID, oidc.ScopeProfile}, auth.Scopes)
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, uri, tok, nil, types.Map{
+		key.UserCode: "BCDF-GHJK",
+		"approved":   true,
+	})
+	assert.Error(t, err)
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, uri, tok, nil, types.Map{
+		key.UserCode: dr.UserCode,
+		"approved":   true,
+	})
+	require.NoError(t, err)
+	// the device gets a bearer token
+	res, err = thttp.DoRequest(t, web, http.MethodPost, tokenURI, poll, poll)
+	require.NoError(t, err)
+	var tr oidc.TokenResponse
+	err = json.Unmarshal([]byte(res), &tr)
+	require.NoError(t, err)
+	assert.Equal(t, oidc.TokenTypeBearer, tr.TokenType)
+	assert.NotEmpty(t, tr.RefreshToken)
+	claims, err := jwt.ParseUserClaims(srv.Config().JWT, tr.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, u.ID, claims.UserID())
+	// the device code is single use
+	_, err = thttp.DoRequest(t, web, http.MethodPost, tokenURI, poll, poll)
+	assert.Error(t, err)
+}
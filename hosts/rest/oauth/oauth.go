@@ -0,0 +1,272 @@
+package oauth
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/jrapoport/gothic/core/oidc"
+	"github.com/jrapoport/gothic/hosts/rest"
+	"github.com/segmentio/encoding/json"
+)
+
+const (
+	// Endpoint is the oauth endpoint.
+	Endpoint = "/oauth"
+	// Authorize is the authorization endpoint.
+	Authorize = "/authorize"
+	// Token is the token endpoint.
+	Token = "/token"
+	// UserInfo is the userinfo endpoint.
+	UserInfo = "/userinfo"
+	// DeviceAuthorization is the device authorization endpoint.
+	DeviceAuthorization = "/device/code"
+	// Device is the device verification endpoint.
+	Device = "/device"
+)
+
+// AuthorizeRequest is the request to complete an authorization request.
+type AuthorizeRequest struct {
+	oidc.AuthorizationRequest
+	Approved bool `json:"approved" form:"approved"`
+}
+
+// AuthorizeResponse is the response to an authorization request.
+type AuthorizeResponse struct {
+	RedirectTo string `json:"redirect_to"`
+}
+
+// DeviceRequest is the request to complete a device authorization request.
+type DeviceRequest struct {
+	UserCode string `json:"user_code" form:"user_code"`
+	Approved bool   `json:"approved" form:"approved"`
+}
+
+type oauthServer struct {
+	*rest.Server
+}
+
+func newOAuthServer(srv *rest.Server) *oauthServer {
+	srv.Logger = srv.WithName("oauth")
+	return &oauthServer{srv}
+}
+
+// RegisterServer registers a new oauth server.
+func RegisterServer(s *http.Server, srv *rest.Server) {
+	register(s, newOAuthServer(srv))
+}
+
+func register(s *http.Server, srv *oauthServer) {
+	if r, ok := s.Handler.(*rest.Router); ok {
+		srv.addRoutes(r)
+	}
+}
+
+func (s *oauthServer) addRoutes(r *rest.Router) {
+	r.Route(Endpoint, func(rt *rest.Router) {
+		rt.Authenticated().Confirmed().Get(Authorize, s.GetAuthorization)
+		rt.Authenticated().Confirmed().Post(Authorize, s.Authorize)
+		rt.RateLimit().Post(Token, s.Token)
+		rt.RateLimit().Post(DeviceAuthorization, s.AuthorizeDevice)
+		rt.Authenticated().Confirmed().Get(Device, s.GetDeviceAuthorization)
+		rt.Authenticated().Confirmed().Post(Device, s.ApproveDevice)
+		rt.Get(UserInfo, s.GetUserInfo)
+		rt.Post(UserInfo, s.GetUserInfo)
+	})
+}
+
+// GetAuthorization returns the details of an authorization request for the consent screen.
+func (s *oauthServer) GetAuthorization(w http.ResponseWriter, r *http.Request) {
+	uid, err := rest.GetUserID(r)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	req := new(oidc.AuthorizationRequest)
+	err = rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ErrorResponse(w, oidc.NewError(oidc.InvalidRequest, ""))
+		return
+	}
+	s.Debugf("get authorization %s: %s", req.ClientID, uid)
+	ctx := rest.FromRequest(r)
+	auth, err := s.API.GetAuthorization(ctx, uid, req)
+	if err != nil {
+		s.ErrorResponse(w, err)
+		return
+	}
+	s.Response(w, auth)
+}
+
+// Authorize completes an authorization request with the consent of the user.
+// The response holds the uri to redirect the user back to the client.
+func (s *oauthServer) Authorize(w http.ResponseWriter, r *http.Request) {
+	uid, err := rest.GetUserID(r)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	req := new(AuthorizeRequest)
+	err = rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ErrorResponse(w, oidc.NewError(oidc.InvalidRequest, ""))
+		return
+	}
+	s.Debugf("authorize %s: %s (%t)", req.ClientID, uid, req.Approved)
+	ctx := rest.FromRequest(r)
+	uri, err := s.API.Authorize(ctx, uid, &req.AuthorizationRequest, req.Approved)
+	if uri == "" {
+		s.ErrorResponse(w, err)
+		return
+	}
+	// errors after the redirect uri is validated are returned to the client
+	s.Response(w, &AuthorizeResponse{RedirectTo: uri})
+}
+
+// AuthorizeDevice starts a device authorization request for a client.
+// Clients can authenticate with http basic auth or with the request body.
+func (s *oauthServer) AuthorizeDevice(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	req := new(oidc.DeviceAuthorizationRequest)
+	err := rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ErrorResponse(w, oidc.NewError(oidc.InvalidRequest, ""))
+		return
+	}
+	if id, secret, ok := r.BasicAuth(); ok {
+		req.ClientID, _ = url.QueryUnescape(id)
+		req.ClientSecret, _ = url.QueryUnescape(secret)
+	}
+	s.Debugf("authorize device: %s", req.ClientID)
+	ctx := rest.FromRequest(r)
+	res, err := s.API.AuthorizeDevice(ctx, req)
+	if err != nil {
+		s.ErrorResponse(w, err)
+		return
+	}
+	s.Response(w, res)
+}
+
+// GetDeviceAuthorization returns the details of a device authorization request for the verification page.
+func (s *oauthServer) GetDeviceAuthorization(w http.ResponseWriter, r *http.Request) {
+	uid, err := rest.GetUserID(r)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	req := new(DeviceRequest)
+	err = rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ErrorResponse(w, oidc.NewError(oidc.InvalidRequest, ""))
+		return
+	}
+	s.Debugf("get device authorization %s: %s", req.UserCode, uid)
+	ctx := rest.FromRequest(r)
+	auth, err := s.API.GetDeviceAuthorization(ctx, uid, req.UserCode)
+	if err != nil {
+		s.ErrorResponse(w, err)
+		return
+	}
+	s.Response(w, auth)
+}
+
+// ApproveDevice completes a device authorization request with the approval of the user.
+func (s *oauthServer) ApproveDevice(w http.ResponseWriter, r *http.Request) {
+	uid, err := rest.GetUserID(r)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	req := new(DeviceRequest)
+	err = rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ErrorResponse(w, oidc.NewError(oidc.InvalidRequest, ""))
+		return
+	}
+	s.Debugf("approve device %s: %s (%t)", req.UserCode, uid, req.Approved)
+	ctx := rest.FromRequest(r)
+	err = s.API.ApproveDevice(ctx, uid, req.UserCode, req.Approved)
+	if err != nil {
+		s.ErrorResponse(w, err)
+		return
+	}
+	s.Response(w, nil)
+}
+
+// Token exchanges an authorization code for an access token and an ID token,
+// grants a service client an access token with its client credentials, or
+// exchanges an approved device code for a bearer token for the user.
+// Clients can authenticate with http basic auth or with the request body.
+func (s *oauthServer) Token(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	req := new(oidc.TokenRequest)
+	err := rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ErrorResponse(w, oidc.NewError(oidc.InvalidRequest, ""))
+		return
+	}
+	if id, secret, ok := r.BasicAuth(); ok {
+		req.ClientID, _ = url.QueryUnescape(id)
+		req.ClientSecret, _ = url.QueryUnescape(secret)
+	}
+	s.Debugf("token %s: %s", req.GrantType, req.ClientID)
+	ctx := rest.FromRequest(r)
+	var res *oidc.TokenResponse
+	switch req.GrantType {
+	case oidc.GrantClientCredentials:
+		res, err = s.API.GrantClientCredentials(ctx, req)
+	case oidc.GrantDeviceCode:
+		res, err = s.API.GrantDeviceCode(ctx, req)
+	default:
+		res, err = s.API.ExchangeAuthorizationCode(ctx, req)
+	}
+	if err != nil {
+		s.ErrorResponse(w, err)
+		return
+	}
+	s.Response(w, res)
+}
+
+// GetUserInfo returns the claims for the user of an access token.
+func (s *oauthServer) GetUserInfo(w http.ResponseWriter, r *http.Request) {
+	tok := rest.RequestToken(r)
+	if tok == "" {
+		s.ErrorResponse(w, oidc.NewError(oidc.InvalidToken, ""))
+		return
+	}
+	ctx := rest.FromRequest(r)
+	info, err := s.API.GetUserInfo(ctx, tok)
+	if err != nil {
+		s.ErrorResponse(w, err)
+		return
+	}
+	s.Response(w, info)
+}
+
+// ErrorResponse logs an error and writes it as an oauth error response.
+func (s *oauthServer) ErrorResponse(w http.ResponseWriter, err error) {
+	s.Error(err)
+	var oe *oidc.Error
+	if !errors.As(err, &oe) {
+		oe = oidc.NewError(oidc.ServerError, "")
+	}
+	code := http.StatusBadRequest
+	switch oe.Code {
+	case oidc.InvalidClient:
+		code = http.StatusUnauthorized
+	case oidc.InvalidToken:
+		w.Header().Set("WWW-Authenticate", rest.BearerScheme+` error="invalid_token"`)
+		code = http.StatusUnauthorized
+	case oidc.ServerError:
+		code = http.StatusInternalServerError
+	}
+	b, err := json.Marshal(oe)
+	if err != nil {
+		rest.ResponseCode(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set(rest.ContentType, rest.JSONContent)
+	w.WriteHeader(code)
+	_, _ = w.Write(b)
+}
@@ -0,0 +1,164 @@
+package oauth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/core/oidc"
+	"github.com/jrapoport/gothic/jwt"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/thttp"
+	"github.com/jrapoport/gothic/test/tsrv"
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEndpoints(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, oidc.AuthorizationPath, Endpoint+Authorize)
+	assert.Equal(t, oidc.TokenPath, Endpoint+Token)
+	assert.Equal(t, oidc.UserInfoPath, Endpoint+UserInfo)
+	assert.Equal(t, oidc.DeviceAuthorizationPath, Endpoint+DeviceAuthorization)
+	assert.Equal(t, oidc.DevicePath, Endpoint+Device)
+}
+
+func TestOAuthServer_ErrorResponse(t *testing.T) {
+	t.Parallel()
+	s, _ := tsrv.RESTServer(t, false)
+	srv := newOAuthServer(s)
+	tests := []struct {
+		err  error
+		code int
+		oe   oidc.ErrorCode
+	}{
+		{oidc.NewError(oidc.InvalidGrant, "bad code"), http.StatusBadRequest, oidc.InvalidGrant},
+		{oidc.NewError(oidc.InvalidClient, ""), http.StatusUnauthorized, oidc.InvalidClient},
+		{oidc.NewError(oidc.InvalidToken, ""), http.StatusUnauthorized, oidc.InvalidToken},
+		{errors.New("db error"), http.StatusInternalServerError, oidc.ServerError},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		srv.ErrorResponse(w, test.err)
+		assert.Equal(t, test.code, w.Code)
+		var oe oidc.Error
+		err := json.Unmarshal(w.Body.Bytes(), &oe)
+		require.NoError(t, err)
+		assert.Equal(t, test.oe, oe.Code)
+		if test.oe == oidc.InvalidToken {
+			assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+		}
+	}
+}
+
+func TestOAuthServer_Token(t *testing.T) {
+	t.Parallel()
+	s, _ := tsrv.RESTServer(t, false)
+	srv := newOAuthServer(s)
+	ctx := context.Background()
+	ctx.SetAdminID(user.SuperAdminID)
+	c, secret, err := srv.API.CreateClient(ctx, "test",
+		[]string{"https://example.com/callback"}, nil, true)
+	require.NoError(t, err)
+	token := func(id, secret string, v url.Values) *httptest.ResponseRecorder {
+		r := thttp.Request(t, http.MethodPost, Endpoint+Token, "", v, v)
+		if id != "" {
+			r.SetBasicAuth(url.QueryEscape(id), url.QueryEscape(secret))
+		}
+		w := httptest.NewRecorder()
+		srv.Token(w, r)
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+		return w
+	}
+	v := url.Values{
+		"grant_type":   {oidc.GrantAuthorizationCode},
+		"code":         {"bad"},
+		"redirect_uri": {"https://example.com/callback"},
+	}
+	// client_secret_basic
+	w := token(c.ClientID, "bad", v)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = token(c.ClientID, secret, v)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var oe oidc.Error
+	err = json.Unmarshal(w.Body.Bytes(), &oe)
+	require.NoError(t, err)
+	assert.Equal(t, oidc.InvalidGrant, oe.Code)
+	// client_secret_post
+	v.Set("client_id", c.ClientID)
+	v.Set("client_secret", secret)
+	w = token("", "", v)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	v.Set("grant_type", "password")
+	w = token("", "", v)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	err = json.Unmarshal(w.Body.Bytes(), &oe)
+	require.NoError(t, err)
+	assert.Equal(t, oidc.UnsupportedGrantType, oe.Code)
+}
+
+func TestOAuthServer_Token_ClientCredentials(t *testing.T) {
+	t.Parallel()
+	s, _ := tsrv.RESTServer(t, false)
+	srv := newOAuthServer(s)
+	ctx := context.Background()
+	ctx.SetAdminID(user.SuperAdminID)
+	svc, secret, err := srv.API.CreateServiceClient(ctx, "test",
+		[]string{"read", "write"}, uuid.Nil)
+	require.NoError(t, err)
+	token := func(id, secret string, v url.Values) *httptest.ResponseRecorder {
+		r := thttp.Request(t, http.MethodPost, Endpoint+Token, "", v, v)
+		if id != "" {
+			r.SetBasicAuth(url.QueryEscape(id), url.QueryEscape(secret))
+		}
+		w := httptest.NewRecorder()
+		srv.Token(w, r)
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+		return w
+	}
+	v := url.Values{
+		"grant_type": {oidc.GrantClientCredentials},
+		"scope":      {"read"},
+	}
+	w := token(svc.ClientID, "bad", v)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = token(svc.ClientID, secret, v)
+	require.Equal(t, http.StatusOK, w.Code)
+	var res oidc.TokenResponse
+	err = json.Unmarshal(w.Body.Bytes(), &res)
+	require.NoError(t, err)
+	assert.Equal(t, oidc.TokenTypeBearer, res.TokenType)
+	assert.Equal(t, "read", res.Scope)
+	claims, err := jwt.ParseServiceClaims(srv.Config().JWT, res.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, svc.ClientID, claims.ClientID())
+	// client_secret_post
+	v.Set("client_id", svc.ClientID)
+	v.Set("client_secret", secret)
+	v.Set("scope", "admin")
+	w = token("", "", v)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var oe oidc.Error
+	err = json.Unmarshal(w.Body.Bytes(), &oe)
+	require.NoError(t, err)
+	assert.Equal(t, oidc.InvalidScope, oe.Code)
+}
+
+func TestOAuthServer_GetUserInfo(t *testing.T) {
+	t.Parallel()
+	s, _ := tsrv.RESTServer(t, false)
+	srv := newOAuthServer(s)
+	r := thttp.Request(t, http.MethodGet, Endpoint+UserInfo, "", nil, nil)
+	w := httptest.NewRecorder()
+	srv.GetUserInfo(w, r)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	r = thttp.Request(t, http.MethodGet, Endpoint+UserInfo, "bad", nil, nil)
+	w = httptest.NewRecorder()
+	srv.GetUserInfo(w, r)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
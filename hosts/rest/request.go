@@ -5,6 +5,7 @@ import (
 	"errors"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/url"
 	"strings"
@@ -30,6 +31,7 @@ const (
 	ContentType     = "Content-Type"
 	UseCookieHeader = "X-Use-Cookie"
 	ForwardedProto  = "X-Forwarded-Proto"
+	DeviceName      = "X-Device-Name"
 )
 
 const (
@@ -51,12 +53,23 @@ type Request struct {
 	Sort      string        `json:"sort"  form:"sort"`
 }
 
+// ClientIP returns the ip address of the client without the port.
+func ClientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
+
 // FromRequest adds the authenticated request context to a context
 func FromRequest(r *http.Request) context.Context {
 	req := &Request{}
 	err := UnmarshalRequest(r, req)
 	ctx := context.WithContext(r.Context())
-	ctx.SetIPAddress(r.RemoteAddr)
+	ctx.SetIPAddress(ClientIP(r))
+	ctx.SetUserAgent(r.UserAgent())
+	ctx.SetDeviceName(r.Header.Get(DeviceName))
 	ctx.SetCode(req.Code)
 	ctx.SetProvider(req.Provider)
 	ctx.SetReCaptcha(req.ReCaptcha)
@@ -70,7 +83,13 @@ func FromRequest(r *http.Request) context.Context {
 		return ctx
 	}
 	ctx.SetProvider(c.Provider())
-	if c.Admin() {
+	ctx.SetSessionID(c.SessionID())
+	ctx.SetTokenID(c.JwtID())
+	ctx.SetOrgID(c.OrgID())
+	if c.Impersonated() {
+		ctx.SetUserID(c.UserID())
+		ctx.SetImpersonatorID(c.ActorID())
+	} else if c.Privileged() {
 		ctx.SetAdminID(c.UserID())
 	} else {
 		ctx.SetUserID(c.UserID())
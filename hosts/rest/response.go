@@ -3,31 +3,47 @@ package rest
 import (
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/credentials"
 	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/core/validate"
+	"github.com/jrapoport/gothic/models/client"
+	"github.com/jrapoport/gothic/models/org"
+	"github.com/jrapoport/gothic/models/rbac"
+	"github.com/jrapoport/gothic/models/tenant"
+	"github.com/jrapoport/gothic/models/token"
 	"github.com/jrapoport/gothic/models/types"
 	"github.com/jrapoport/gothic/models/user"
 	"github.com/jrapoport/gothic/utils"
+	"github.com/segmentio/encoding/json"
 )
 
 // UserResponse contains an http user response.
 type UserResponse struct {
-	UserID   string          `json:"user_id"`
-	Role     string          `json:"role"`
-	Email    string          `json:"email,omitempty"`
-	Username string          `json:"username,omitempty"`
-	Data     types.Map       `json:"data"`
-	Token    *BearerResponse `json:"token,omitempty"`
+	UserID   string                  `json:"user_id"`
+	Role     string                  `json:"role"`
+	Email    string                  `json:"email,omitempty"`
+	Phone    string                  `json:"phone,omitempty"`
+	Username string                  `json:"username,omitempty"`
+	Data     types.Map               `json:"data"`
+	Token    *BearerResponse         `json:"token,omitempty"`
+	MFA      *MFAResponse            `json:"mfa,omitempty"`
+	Password *PasswordChangeResponse `json:"password,omitempty"`
 }
 
 // NewUserResponse returns a UserResponse for the supplied user.
 func NewUserResponse(u *user.User) *UserResponse {
-	return &UserResponse{
+	res := &UserResponse{
 		UserID:   u.ID.String(),
 		Role:     u.Role.String(),
 		Email:    u.Email,
 		Username: u.Username,
 		Data:     u.Data,
 	}
+	if u.HasPhone() {
+		res.Phone = *u.Phone
+	}
+	return res
 }
 
 // MaskEmail masks the email field of the user response
@@ -46,10 +62,381 @@ type BearerResponse struct {
 
 // NewBearerResponse returns a BearerResponse from a BearerToken
 func NewBearerResponse(bt *tokens.BearerToken) *BearerResponse {
-	return &BearerResponse{
+	res := &BearerResponse{
 		Type:      bt.Class().String(),
 		Access:    bt.String(),
-		Refresh:   bt.RefreshToken.String(),
 		ExpiresAt: bt.ExpiredAt,
 	}
+	// impersonation tokens cannot be refreshed
+	if bt.RefreshToken != nil {
+		res.Refresh = bt.RefreshToken.String()
+	}
+	return res
+}
+
+// MFAResponse is an mfa challenge response.
+type MFAResponse struct {
+	Type      string     `json:"type"`
+	Token     string     `json:"token"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// NewMFAResponse returns an MFAResponse from an MFAToken
+func NewMFAResponse(mt *token.MFAToken) *MFAResponse {
+	return &MFAResponse{
+		Type:      mt.Class().String(),
+		Token:     mt.String(),
+		ExpiresAt: mt.ExpiredAt,
+	}
+}
+
+// PasswordChangeResponse is a password change required response.
+type PasswordChangeResponse struct {
+	Token     string     `json:"token"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// NewPasswordChangeResponse returns a PasswordChangeResponse from a PasswordChangeToken
+func NewPasswordChangeResponse(pt *token.PasswordChangeToken) *PasswordChangeResponse {
+	return &PasswordChangeResponse{
+		Token:     pt.String(),
+		ExpiresAt: pt.ExpiredAt,
+	}
+}
+
+// WebAuthnResponse is a webauthn ceremony response.
+type WebAuthnResponse struct {
+	Token     string          `json:"token"`
+	Options   json.RawMessage `json:"options"`
+	ExpiresAt *time.Time      `json:"expires_at,omitempty"`
+}
+
+// NewWebAuthnResponse returns a WebAuthnResponse for a webauthn ceremony.
+func NewWebAuthnResponse(c *credentials.Ceremony) *WebAuthnResponse {
+	return &WebAuthnResponse{
+		Token:     c.Token,
+		Options:   c.Options,
+		ExpiresAt: &c.ExpiresAt,
+	}
+}
+
+// SessionResponse is a device session response.
+type SessionResponse struct {
+	SessionID  string    `json:"session_id"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	IPAddress  string    `json:"ip_address,omitempty"`
+	DeviceName string    `json:"device_name,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	Current    bool      `json:"current"`
+}
+
+// NewSessionResponse returns a SessionResponse for the session of a refresh
+// token. If the session is the current session, current is true.
+func NewSessionResponse(rt *token.RefreshToken, current uuid.UUID) *SessionResponse {
+	return &SessionResponse{
+		SessionID:  rt.SessionID.String(),
+		UserAgent:  rt.UserAgent,
+		IPAddress:  rt.IPAddress,
+		DeviceName: rt.DeviceName,
+		CreatedAt:  rt.SignedInAt,
+		LastUsedAt: rt.LastActive(),
+		Current:    current != uuid.Nil && rt.SessionID == current,
+	}
+}
+
+// NewSessionsResponse returns a list of SessionResponse for the sessions.
+func NewSessionsResponse(sessions []*token.RefreshToken, current uuid.UUID) []*SessionResponse {
+	res := make([]*SessionResponse, len(sessions))
+	for i, rt := range sessions {
+		res[i] = NewSessionResponse(rt, current)
+	}
+	return res
+}
+
+// RevokedSessionsResponse is the response for revoked sessions.
+type RevokedSessionsResponse struct {
+	Revoked int `json:"revoked"`
+}
+
+// PersonalTokenResponse is a personal access token response.
+type PersonalTokenResponse struct {
+	TokenID    string     `json:"token_id"`
+	Token      string     `json:"token,omitempty"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	IPAddress  string     `json:"ip_address,omitempty"`
+}
+
+// NewPersonalTokenResponse returns a PersonalTokenResponse for the personal
+// token. The token is only included when the personal token is created.
+func NewPersonalTokenResponse(pt *token.PersonalToken) *PersonalTokenResponse {
+	return &PersonalTokenResponse{
+		TokenID:    pt.TokenID.String(),
+		Token:      pt.Secret,
+		Name:       pt.Name,
+		Scopes:     pt.Scopes(),
+		CreatedAt:  pt.CreatedAt,
+		ExpiresAt:  pt.ExpiredAt,
+		LastUsedAt: pt.UsedAt,
+		IPAddress:  pt.IPAddress,
+	}
+}
+
+// NewPersonalTokensResponse returns a list of PersonalTokenResponse for the personal tokens.
+func NewPersonalTokensResponse(list []*token.PersonalToken) []*PersonalTokenResponse {
+	res := make([]*PersonalTokenResponse, len(list))
+	for i, pt := range list {
+		res[i] = NewPersonalTokenResponse(pt)
+	}
+	return res
+}
+
+// ClientResponse is an oauth client response.
+type ClientResponse struct {
+	ClientID     string    `json:"client_id"`
+	ClientSecret string    `json:"client_secret,omitempty"`
+	Name         string    `json:"name"`
+	RedirectURIs []string  `json:"redirect_uris"`
+	Scopes       []string  `json:"scopes"`
+	Confidential bool      `json:"confidential"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// NewClientResponse returns a ClientResponse for the client.
+func NewClientResponse(c *client.Client) *ClientResponse {
+	return &ClientResponse{
+		ClientID:     c.ClientID,
+		Name:         c.Name,
+		RedirectURIs: c.RedirectURIList(),
+		Scopes:       c.ScopeList(),
+		Confidential: c.Confidential(),
+		CreatedAt:    c.CreatedAt,
+	}
+}
+
+// NewClientsResponse returns a list of ClientResponse for the clients.
+func NewClientsResponse(clients []*client.Client) []*ClientResponse {
+	res := make([]*ClientResponse, len(clients))
+	for i, c := range clients {
+		res[i] = NewClientResponse(c)
+	}
+	return res
+}
+
+// ServiceResponse is a service client response.
+type ServiceResponse struct {
+	ClientID     string    `json:"client_id"`
+	ClientSecret string    `json:"client_secret,omitempty"`
+	Name         string    `json:"name"`
+	Scopes       []string  `json:"scopes"`
+	OwnerID      uuid.UUID `json:"owner_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// NewServiceResponse returns a ServiceResponse for the service client.
+func NewServiceResponse(s *client.Service) *ServiceResponse {
+	return &ServiceResponse{
+		ClientID:  s.ClientID,
+		Name:      s.Name,
+		Scopes:    s.ScopeList(),
+		OwnerID:   s.OwnerID,
+		CreatedAt: s.CreatedAt,
+	}
+}
+
+// NewServicesResponse returns a list of ServiceResponse for the service clients.
+func NewServicesResponse(services []*client.Service) []*ServiceResponse {
+	res := make([]*ServiceResponse, len(services))
+	for i, s := range services {
+		res[i] = NewServiceResponse(s)
+	}
+	return res
+}
+
+// RoleResponse is a custom role response.
+type RoleResponse struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// NewRoleResponse returns a RoleResponse for the custom role.
+func NewRoleResponse(r *rbac.Role) *RoleResponse {
+	return &RoleResponse{
+		Name:        r.Name,
+		Description: r.Description,
+		Permissions: rbac.Strings(r.PermissionList()),
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+	}
+}
+
+// NewRolesResponse returns a list of RoleResponse for the custom roles.
+func NewRolesResponse(roles []*rbac.Role) []*RoleResponse {
+	res := make([]*RoleResponse, len(roles))
+	for i, r := range roles {
+		res[i] = NewRoleResponse(r)
+	}
+	return res
+}
+
+// UserRolesResponse is the response for the custom roles assigned to a
+// user, and the permissions they grant the user.
+type UserRolesResponse struct {
+	Roles       []*RoleResponse `json:"roles"`
+	Permissions []string        `json:"permissions"`
+}
+
+// ConsentResponse is the response for the consent a user has given a client.
+type ConsentResponse struct {
+	ClientID  string    `json:"client_id"`
+	Scopes    []string  `json:"scopes"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewConsentsResponse returns a list of ConsentResponse for the consents.
+func NewConsentsResponse(consents []*client.Consent) []*ConsentResponse {
+	res := make([]*ConsentResponse, len(consents))
+	for i, c := range consents {
+		res[i] = &ConsentResponse{
+			ClientID:  c.ClientID,
+			Scopes:    c.ScopeList(),
+			CreatedAt: c.CreatedAt,
+			UpdatedAt: c.UpdatedAt,
+		}
+	}
+	return res
+}
+
+// OrgResponse is an organization response.
+type OrgResponse struct {
+	OrgID     string    `json:"org_id"`
+	Name      string    `json:"name"`
+	Data      types.Map `json:"data,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewOrgResponse returns an OrgResponse for the organization.
+func NewOrgResponse(o *org.Organization) *OrgResponse {
+	return &OrgResponse{
+		OrgID:     o.ID.String(),
+		Name:      o.Name,
+		Data:      o.Data,
+		CreatedAt: o.CreatedAt,
+		UpdatedAt: o.UpdatedAt,
+	}
+}
+
+// NewOrgsResponse returns a list of OrgResponse for the organizations.
+func NewOrgsResponse(orgs []*org.Organization) []*OrgResponse {
+	res := make([]*OrgResponse, len(orgs))
+	for i, o := range orgs {
+		res[i] = NewOrgResponse(o)
+	}
+	return res
+}
+
+// MemberResponse is an organization member response.
+type MemberResponse struct {
+	OrgID     string    `json:"org_id"`
+	UserID    string    `json:"user_id"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewMemberResponse returns a MemberResponse for the organization member.
+func NewMemberResponse(m *org.Member) *MemberResponse {
+	return &MemberResponse{
+		OrgID:     m.OrgID.String(),
+		UserID:    m.UserID.String(),
+		Role:      m.Role.String(),
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+// NewMembersResponse returns a list of MemberResponse for the organization members.
+func NewMembersResponse(members []*org.Member) []*MemberResponse {
+	res := make([]*MemberResponse, len(members))
+	for i, m := range members {
+		res[i] = NewMemberResponse(m)
+	}
+	return res
+}
+
+// OrgInviteResponse is an organization invite response.
+type OrgInviteResponse struct {
+	OrgID     string     `json:"org_id"`
+	Email     string     `json:"email"`
+	Role      string     `json:"role"`
+	Token     string     `json:"token,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	SentAt    *time.Time `json:"sent_at,omitempty"`
+}
+
+// NewOrgInviteResponse returns an OrgInviteResponse for the organization
+// invite. The token is only included if the invite mail was not sent.
+func NewOrgInviteResponse(inv *org.Invite) *OrgInviteResponse {
+	res := &OrgInviteResponse{
+		OrgID:     inv.OrgID.String(),
+		Email:     inv.Email,
+		Role:      inv.Role.String(),
+		ExpiresAt: inv.ExpiredAt,
+		SentAt:    inv.SentAt,
+	}
+	if inv.SentAt == nil {
+		res.Token = inv.Token
+	}
+	return res
+}
+
+// TenantResponse is a tenant response.
+type TenantResponse struct {
+	TenantID  string    `json:"tenant_id"`
+	Name      string    `json:"name"`
+	Host      string    `json:"host,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewTenantResponse returns a TenantResponse for the tenant.
+func NewTenantResponse(t *tenant.Tenant) *TenantResponse {
+	return &TenantResponse{
+		TenantID:  t.ID.String(),
+		Name:      t.Name,
+		Host:      t.Host,
+		CreatedAt: t.CreatedAt,
+		UpdatedAt: t.UpdatedAt,
+	}
+}
+
+// NewTenantsResponse returns a list of TenantResponse for the tenants.
+func NewTenantsResponse(tenants []*tenant.Tenant) []*TenantResponse {
+	res := make([]*TenantResponse, len(tenants))
+	for i, t := range tenants {
+		res[i] = NewTenantResponse(t)
+	}
+	return res
+}
+
+// PasswordErrorResponse is the response for a password that
+// violates the password policy.
+type PasswordErrorResponse struct {
+	Error      string                       `json:"error"`
+	Violations []validate.PasswordViolation `json:"violations"`
+}
+
+// NewPasswordErrorResponse returns a PasswordErrorResponse for a PasswordError.
+func NewPasswordErrorResponse(pe *validate.PasswordError) *PasswordErrorResponse {
+	return &PasswordErrorResponse{
+		Error:      "invalid password",
+		Violations: pe.Violations,
+	}
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/httprate"
 	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/models/rbac"
 )
 
 // Root is the base route
@@ -14,8 +15,11 @@ const Root = "/"
 
 // Router an http router
 type Router struct {
-	chi    chi.Router
-	config *config.Config
+	chi      chi.Router
+	config   *config.Config
+	revoked  RevocationCheck
+	personal PersonalTokenCheck
+	imp      ImpersonationLog
 }
 
 // NewRouter returns a new configured router.
@@ -48,7 +52,7 @@ func (r *Router) Mount(pattern string, h http.Handler) {
 // Route mounts a sub-Router along a `pattern`` string.
 func (r *Router) Route(pattern string, fn func(*Router)) {
 	r.chi.Route(pattern, func(cr chi.Router) {
-		fn(&Router{chi: cr, config: r.config})
+		fn(&Router{chi: cr, config: r.config, revoked: r.revoked, personal: r.personal, imp: r.imp})
 	})
 }
 
@@ -82,7 +86,7 @@ func (r *Router) Delete(pattern string, fn http.HandlerFunc) {
 // With adds inline middlewares for an endpoint handler.
 func (r *Router) With(middlewares ...func(http.Handler) http.Handler) *Router {
 	cr := r.chi.With(middlewares...)
-	return &Router{chi: cr, config: r.config}
+	return &Router{chi: cr, config: r.config, revoked: r.revoked, personal: r.personal, imp: r.imp}
 }
 
 // Use appends one or more middlewares onto the Router stack.
@@ -95,6 +99,24 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	r.chi.ServeHTTP(w, req)
 }
 
+// UseRevocation sets the check used to reject revoked
+// jwt tokens for authenticated endpoint handlers.
+func (r *Router) UseRevocation(check RevocationCheck) {
+	r.revoked = check
+}
+
+// UsePersonalTokens sets the check used to accept personal
+// access tokens for authenticated endpoint handlers.
+func (r *Router) UsePersonalTokens(check PersonalTokenCheck) {
+	r.personal = check
+}
+
+// UseImpersonationLog sets the log used to record the requests
+// made while impersonating a user for authenticated endpoint handlers.
+func (r *Router) UseImpersonationLog(log ImpersonationLog) {
+	r.imp = log
+}
+
 /* unused for now
 // UseLogger sets the logger to use for the Router stack.
 func (r *Router) UseLogger(log log.Logger) {
@@ -120,13 +142,13 @@ func (r *Router) Authenticated() *Router {
 	if r.config == nil {
 		return r
 	}
-	return r.With(Authenticator(r.config.JWT))
+	return r.With(Authenticator(r.config.JWT, r.revoked, r.personal), Impersonation(r.imp))
 }
 
 // Authenticate adds inline middlewares to enforce
 // jwt for endpoint handlers with the config.
 func (r *Router) Authenticate(c config.JWT) *Router {
-	return r.With(Authenticator(c))
+	return r.With(Authenticator(c, r.revoked, r.personal), Impersonation(r.imp))
 }
 
 // Admin adds inline middlewares to enforce
@@ -135,12 +157,24 @@ func (r *Router) Admin() *Router {
 	return r.With(AdminUser)
 }
 
+// Permission adds inline middlewares to enforce
+// user permissions for endpoint handlers
+func (r *Router) Permission(perms ...rbac.Permission) *Router {
+	return r.With(Permission(perms...))
+}
+
 // Confirmed adds inline middlewares to enforce
 // confirmed accounts permissions for endpoint handlers
 func (r *Router) Confirmed() *Router {
 	return r.With(ConfirmedUser)
 }
 
+// Session adds inline middlewares to reject personal
+// access tokens for endpoint handlers.
+func (r *Router) Session() *Router {
+	return r.With(SessionUser)
+}
+
 // URLParam returns the url parameter from a http.Request object.
 func URLParam(r *http.Request, key string) string {
 	return chi.URLParam(r, key)
@@ -1,10 +1,15 @@
 package rest
 
 import (
+	"errors"
 	"net/http"
 
+	"github.com/jrapoport/gothic/config"
 	"github.com/jrapoport/gothic/core"
+	"github.com/jrapoport/gothic/core/context"
 	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/core/validate"
+	"github.com/jrapoport/gothic/models/rbac"
 	"github.com/jrapoport/gothic/models/user"
 	"github.com/jrapoport/gothic/store"
 	"github.com/segmentio/encoding/json"
@@ -26,12 +31,13 @@ func (s *Server) Clone() *Server {
 }
 
 // ValidateAdmin re-checks that a token belongs to an active admin user
-func (s *Server) ValidateAdmin(r *http.Request) (user.Role, error) {
+// with the permission.
+func (s *Server) ValidateAdmin(r *http.Request, perm rbac.Permission) (user.Role, error) {
 	aid, err := GetUserID(r)
 	if err != nil {
 		return user.InvalidRole, err
 	}
-	role, err := s.API.ValidateAdmin(aid)
+	role, err := s.API.ValidateAdmin(aid, perm)
 	if err != nil {
 		return user.InvalidRole, err
 	}
@@ -71,10 +77,16 @@ func (s *Server) Response(w http.ResponseWriter, v interface{}) {
 }
 
 // ResponseCode logs an error and the writes an sanitized standard response.
+// Password policy violations are returned as StatusUnprocessableEntity.
 func (s *Server) ResponseCode(w http.ResponseWriter, code int, err error) {
 	if err != nil {
 		s.Error(err)
 	}
+	var pe *validate.PasswordError
+	if errors.As(err, &pe) {
+		PasswordErrorCode(w, pe)
+		return
+	}
 	ResponseCode(w, code, err)
 }
 
@@ -99,6 +111,57 @@ func (s *Server) AuthError(w http.ResponseWriter, err error) {
 	s.ResponseCode(w, http.StatusOK, err)
 }
 
+// Authorize returns an mfa challenge if the user has enabled
+// two-factor authentication, otherwise it grants a bearer token.
+func (s *Server) Authorize(w http.ResponseWriter, r *http.Request,
+	ctx context.Context, u *user.User) {
+	res := NewUserResponse(u)
+	if s.Config().MaskEmails {
+		res.MaskEmail()
+	}
+	mt, err := s.GrantMFAChallenge(ctx, u)
+	if errors.Is(err, config.ErrRateLimitExceeded) {
+		s.ResponseCode(w, http.StatusTooEarly, err)
+		return
+	}
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	if mt != nil {
+		res.MFA = NewMFAResponse(mt)
+		s.Debugf("mfa challenge for user: %s", mt.UserID)
+		s.Response(w, res)
+		return
+	}
+	s.GrantBearer(w, r, ctx, u, res)
+}
+
+// GrantBearer returns a password change token if the user must change
+// their password, otherwise it grants a bearer token.
+func (s *Server) GrantBearer(w http.ResponseWriter, r *http.Request,
+	ctx context.Context, u *user.User, res *UserResponse) {
+	pt, err := s.GrantPasswordChange(ctx, u)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	if pt != nil {
+		res.Password = NewPasswordChangeResponse(pt)
+		s.Debugf("password change for user: %s", pt.UserID)
+		s.Response(w, res)
+		return
+	}
+	bt, err := s.GrantBearerToken(ctx, u)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	res.Token = NewBearerResponse(bt)
+	s.Debugf("logged in user: %s", bt.UserID)
+	s.AuthResponse(w, r, bt.String(), res)
+}
+
 // PagedResponse will return a pages response.
 func (s *Server) PagedResponse(w http.ResponseWriter, r *http.Request,
 	v interface{}, page *store.Pagination) {
@@ -106,6 +169,19 @@ func (s *Server) PagedResponse(w http.ResponseWriter, r *http.Request,
 	s.Response(w, v)
 }
 
+// PasswordErrorCode writes the password policy violations as a
+// StatusUnprocessableEntity response.
+func PasswordErrorCode(w http.ResponseWriter, pe *validate.PasswordError) {
+	b, err := json.Marshal(NewPasswordErrorResponse(pe))
+	if err != nil {
+		ResponseCode(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set(ContentType, JSONContent)
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	_, _ = w.Write(b)
+}
+
 // ResponseCode writes a standard http response
 func ResponseCode(w http.ResponseWriter, code int, err error) {
 	if code == http.StatusOK {
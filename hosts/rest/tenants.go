@@ -0,0 +1,125 @@
+package rest
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/core"
+)
+
+type tenantRouter struct {
+	api *core.API
+	rt  *Router
+}
+
+// tenantHandler routes a request to the router of the tenant it resolves
+// to. Requests that do not resolve to a tenant are routed to the base
+// router. The routers of the tenants are created lazily and replaced when
+// the api of the tenant is reloaded.
+type tenantHandler struct {
+	api     *core.API
+	name    string
+	config  config.Tenants
+	base    http.Handler
+	reg     []RegisterServer
+	routers map[uuid.UUID]tenantRouter
+	mu      sync.Mutex
+}
+
+func newTenantHandler(h *core.Host, base http.Handler, reg []RegisterServer) *tenantHandler {
+	return &tenantHandler{
+		api:     h.API,
+		name:    h.Name(),
+		config:  h.Config().Tenants,
+		base:    base,
+		reg:     reg,
+		routers: map[uuid.UUID]tenantRouter{},
+	}
+}
+
+// ServeHTTP resolves the tenant for the request. The tenant is resolved
+// from the tenant header, then the tenant path prefix, and finally the host
+// of the request. An unknown tenant in the header or path is not found.
+func (th *tenantHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ta, r, err := th.resolve(r)
+	if err != nil {
+		ResponseCode(w, http.StatusNotFound, nil)
+		return
+	}
+	if ta == nil {
+		th.base.ServeHTTP(w, r)
+		return
+	}
+	th.router(ta).ServeHTTP(w, r)
+}
+
+func (th *tenantHandler) resolve(r *http.Request) (*core.API, *http.Request, error) {
+	if id := r.Header.Get(th.config.Header); id != "" {
+		ta, err := th.tenantAPI(id)
+		return ta, r, err
+	}
+	prefix := th.config.PathPrefix + Root
+	if strings.HasPrefix(r.URL.Path, prefix) {
+		name := strings.TrimPrefix(r.URL.Path, prefix)
+		path := Root
+		if i := strings.Index(name, Root); i >= 0 {
+			name, path = name[:i], name[i:]
+		}
+		ta, err := th.tenantAPI(name)
+		if err != nil {
+			return nil, r, err
+		}
+		r2 := r.Clone(r.Context())
+		r2.URL.Path = path
+		r2.URL.RawPath = ""
+		return ta, r2, nil
+	}
+	ta, err := th.api.TenantAPIWithHost(r.Host)
+	if err != nil {
+		// unknown hosts belong to the default tenant
+		return nil, r, nil
+	}
+	return ta, r, nil
+}
+
+func (th *tenantHandler) tenantAPI(id string) (*core.API, error) {
+	tid, err := uuid.Parse(id)
+	if err == nil {
+		return th.api.TenantAPI(tid)
+	}
+	return th.api.TenantAPIWithName(id)
+}
+
+func (th *tenantHandler) router(ta *core.API) *Router {
+	th.mu.Lock()
+	defer th.mu.Unlock()
+	tid := ta.Tenant().ID
+	tr, ok := th.routers[tid]
+	if ok && tr.api == ta {
+		return tr.rt
+	}
+	s := core.NewServer(ta, th.name)
+	tr = tenantRouter{ta, newRouter(ta, s, th.reg)}
+	th.routers[tid] = tr
+	return tr.rt
+}
+
+// newRouter creates a new router for the api and registers the servers.
+func newRouter(a *core.API, s *core.Server, reg []RegisterServer) *Router {
+	rt := NewRouter(s.Config())
+	rt.UseRevocation(a.ValidateBearerToken)
+	rt.UsePersonalTokens(a.ValidatePersonalToken)
+	rt.UseImpersonationLog(a.LogImpersonation)
+	//rt.UseLogger(s.Logger)
+	server := &http.Server{
+		Handler: rt,
+	}
+	for _, r := range reg {
+		srv := NewServer(s.Clone())
+		r(server, srv)
+	}
+	return rt
+}
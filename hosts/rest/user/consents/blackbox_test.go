@@ -0,0 +1,97 @@
+package consents_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/core/oidc"
+	"github.com/jrapoport/gothic/hosts/rest"
+	"github.com/jrapoport/gothic/hosts/rest/user/consents"
+	"github.com/jrapoport/gothic/models/client"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tcore"
+	"github.com/jrapoport/gothic/test/thttp"
+	"github.com/jrapoport/gothic/test/tsrv"
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const redirectURI = "https://example.com/callback"
+
+func testServer(t *testing.T) (*rest.Host, *httptest.Server) {
+	srv, web, _ := tsrv.RESTHost(t, []rest.RegisterServer{
+		consents.RegisterServer,
+	}, false)
+	c := srv.Config()
+	c.Signup.AutoConfirm = true
+	t.Cleanup(func() {
+		web.Close()
+	})
+	return srv, web
+}
+
+func grantConsent(t *testing.T, srv *rest.Host, u *user.User) *client.Client {
+	ctx := context.Background()
+	ctx.SetAdminID(user.SuperAdminID)
+	c, _, err := srv.API.CreateClient(ctx, "test", []string{redirectURI}, nil, true)
+	require.NoError(t, err)
+	_, err = srv.API.Authorize(context.Background(), u.ID, &oidc.AuthorizationRequest{
+		ClientID:     c.ClientID,
+		RedirectURI:  redirectURI,
+		ResponseType: oidc.ResponseTypeCode,
+		Scope:        "openid email",
+	}, true)
+	require.NoError(t, err)
+	return c
+}
+
+func listConsents(t *testing.T, web *httptest.Server, tok string) []*rest.ConsentResponse {
+	res, err := thttp.DoAuthRequest(t, web, http.MethodGet, consents.Consents, tok, nil, nil)
+	require.NoError(t, err)
+	var list []*rest.ConsentResponse
+	err = json.Unmarshal([]byte(res), &list)
+	require.NoError(t, err)
+	return list
+}
+
+func TestConsentsServer_ListConsents(t *testing.T) {
+	t.Parallel()
+	srv, web := testServer(t)
+	j := srv.Config().JWT
+	u, bt := tcore.TestUser(t, srv.API, "", false)
+	// not authorized
+	_, err := thttp.DoRequest(t, web, http.MethodGet, consents.Consents, nil, nil)
+	assert.Error(t, err)
+	// user not found
+	bad := thttp.UserToken(t, j, true, false)
+	_, err = thttp.DoAuthRequest(t, web, http.MethodGet, consents.Consents, bad, nil, nil)
+	assert.Error(t, err)
+	list := listConsents(t, web, bt)
+	assert.Len(t, list, 0)
+	c := grantConsent(t, srv, u)
+	list = listConsents(t, web, bt)
+	require.Len(t, list, 1)
+	assert.Equal(t, c.ClientID, list[0].ClientID)
+	assert.Equal(t, []string{oidc.ScopeOpenID, oidc.ScopeEmail}, list[0].Scopes)
+	assert.False(t, list[0].CreatedAt.IsZero())
+}
+
+func TestConsentsServer_RevokeConsent(t *testing.T) {
+	t.Parallel()
+	srv, web := testServer(t)
+	u, bt := tcore.TestUser(t, srv.API, "", false)
+	c := grantConsent(t, srv, u)
+	// consent not found
+	_, err := thttp.DoAuthRequest(t, web, http.MethodDelete,
+		consents.Consents+"/"+uuid.New().String(), bt, nil, nil)
+	assert.Error(t, err)
+	_, err = thttp.DoAuthRequest(t, web, http.MethodDelete,
+		consents.Consents+"/"+c.ClientID, bt, nil, nil)
+	assert.NoError(t, err)
+	list := listConsents(t, web, bt)
+	assert.Len(t, list, 0)
+}
@@ -0,0 +1,77 @@
+package consents
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jrapoport/gothic/hosts/rest"
+	"github.com/jrapoport/gothic/models/types/key"
+)
+
+const (
+	// Consents is the oauth consents endpoint.
+	Consents = "/consents"
+	// ClientID selects the consent for a client.
+	ClientID = "/{" + key.ClientID + "}"
+)
+
+type consentsServer struct {
+	*rest.Server
+}
+
+func newConsentsServer(srv *rest.Server) *consentsServer {
+	srv.Logger = srv.WithName("consents")
+	return &consentsServer{srv}
+}
+
+// RegisterServer registers a new consents server.
+func RegisterServer(s *http.Server, srv *rest.Server) {
+	register(s, newConsentsServer(srv))
+}
+
+func register(s *http.Server, srv *consentsServer) {
+	if r, ok := s.Handler.(*rest.Router); ok {
+		srv.addRoutes(r)
+	}
+}
+
+func (s *consentsServer) addRoutes(r *rest.Router) {
+	r.Authenticated().Confirmed().Route(Consents, func(rt *rest.Router) {
+		rt.Get(rest.Root, s.ListConsents)
+		rt.Delete(ClientID, s.RevokeConsent)
+	})
+}
+
+// ListConsents lists the consents the user has given to clients.
+func (s *consentsServer) ListConsents(w http.ResponseWriter, r *http.Request) {
+	// we can safely ignore this error since this route is
+	// protected we've already checked for a valid user id
+	uid, _ := rest.GetUserID(r)
+	s.Debugf("list consents: %s", uid)
+	ctx := rest.FromRequest(r)
+	consents, err := s.API.GetConsents(ctx, uid)
+	if err != nil {
+		s.ResponseError(w, err)
+		return
+	}
+	s.Response(w, rest.NewConsentsResponse(consents))
+}
+
+// RevokeConsent revokes the consent the user has given to a client.
+func (s *consentsServer) RevokeConsent(w http.ResponseWriter, r *http.Request) {
+	uid, _ := rest.GetUserID(r)
+	clientID := rest.URLParam(r, key.ClientID)
+	if clientID == "" {
+		err := errors.New("invalid client id")
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	s.Debugf("revoke consent: %s %s", uid, clientID)
+	ctx := rest.FromRequest(r)
+	err := s.API.RevokeConsent(ctx, uid, clientID)
+	if err != nil {
+		s.ResponseCode(w, http.StatusNotFound, err)
+		return
+	}
+	s.Response(w, nil)
+}
@@ -0,0 +1,94 @@
+package mfa_test
+
+import (
+	"bytes"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jrapoport/gothic/hosts/rest"
+	"github.com/jrapoport/gothic/hosts/rest/user/mfa"
+	"github.com/jrapoport/gothic/test/tcore"
+	"github.com/jrapoport/gothic/test/thttp"
+	"github.com/jrapoport/gothic/test/tsrv"
+	"github.com/pquerna/otp"
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	totp    = mfa.MFA + mfa.TOTP
+	confirm = totp + mfa.Confirm
+)
+
+func testServer(t *testing.T) (*rest.Host, *httptest.Server) {
+	srv, web, _ := tsrv.RESTHost(t, []rest.RegisterServer{
+		mfa.RegisterServer,
+	}, false)
+	c := srv.Config()
+	c.Signup.AutoConfirm = true
+	t.Cleanup(func() {
+		web.Close()
+	})
+	return srv, web
+}
+
+func TestMFAServer_TOTP(t *testing.T) {
+	t.Parallel()
+	srv, web := testServer(t)
+	j := srv.Config().JWT
+	u, bt := tcore.TestUser(t, srv.API, "", false)
+	// not authorized
+	_, err := thttp.DoRequest(t, web, http.MethodPost, totp, nil, nil)
+	assert.Error(t, err)
+	// user not confirmed
+	bad := thttp.UserToken(t, j, false, false)
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, totp, bad, nil, nil)
+	assert.Error(t, err)
+	// user not found
+	bad = thttp.UserToken(t, j, true, false)
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, totp, bad, nil, nil)
+	assert.Error(t, err)
+	// enroll
+	res, err := thttp.DoAuthRequest(t, web, http.MethodPost, totp, bt, nil, nil)
+	require.NoError(t, err)
+	var tr mfa.TOTPResponse
+	err = json.Unmarshal([]byte(res), &tr)
+	require.NoError(t, err)
+	k, err := otp.NewKeyFromURL(tr.URL)
+	require.NoError(t, err)
+	assert.Equal(t, u.Email, k.AccountName())
+	assert.Equal(t, tr.Secret, k.Secret())
+	_, err = png.Decode(bytes.NewReader(tr.QRCode))
+	assert.NoError(t, err)
+	// invalid req
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, confirm, bt, nil, []byte("\n"))
+	assert.Error(t, err)
+	// bad code
+	req := &mfa.Request{Code: "bad"}
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, confirm, bt, nil, req)
+	assert.Error(t, err)
+	// confirm
+	req = &mfa.Request{Code: tcore.TOTPCode(t, tr.Secret, 0)}
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, confirm, bt, nil, req)
+	assert.NoError(t, err)
+	// already enrolled
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, totp, bt, nil, nil)
+	assert.Error(t, err)
+	// invalid req
+	_, err = thttp.DoAuthRequest(t, web, http.MethodDelete, totp, bt, nil, []byte("\n"))
+	assert.Error(t, err)
+	// bad code
+	req = &mfa.Request{Code: "bad"}
+	_, err = thttp.DoAuthRequest(t, web, http.MethodDelete, totp, bt, nil, req)
+	assert.Error(t, err)
+	// disable
+	req = &mfa.Request{Code: tcore.TOTPCode(t, tr.Secret, 1)}
+	_, err = thttp.DoAuthRequest(t, web, http.MethodDelete, totp, bt, nil, req)
+	assert.NoError(t, err)
+	mt, err := srv.GrantMFAChallenge(nil, u)
+	assert.NoError(t, err)
+	assert.Nil(t, mt)
+}
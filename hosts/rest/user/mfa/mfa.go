@@ -0,0 +1,116 @@
+package mfa
+
+import (
+	"net/http"
+
+	"github.com/jrapoport/gothic/hosts/rest"
+)
+
+const (
+	// MFA is the multi-factor authentication endpoint.
+	MFA = "/mfa"
+	// TOTP enrolls or disables totp.
+	TOTP = "/totp"
+	// Confirm confirms a totp enrollment.
+	Confirm = "/confirm"
+)
+
+// Request is an mfa server request
+type Request struct {
+	Code string `json:"code" form:"code"`
+}
+
+// TOTPResponse is a totp enrollment response.
+type TOTPResponse struct {
+	Secret string `json:"secret"`
+	URL    string `json:"url"`
+	QRCode []byte `json:"qr_code"`
+}
+
+type mfaServer struct {
+	*rest.Server
+}
+
+func newMFAServer(srv *rest.Server) *mfaServer {
+	srv.Logger = srv.WithName("mfa")
+	return &mfaServer{srv}
+}
+
+// RegisterServer registers a new mfa server.
+func RegisterServer(s *http.Server, srv *rest.Server) {
+	register(s, newMFAServer(srv))
+}
+
+func register(s *http.Server, srv *mfaServer) {
+	if r, ok := s.Handler.(*rest.Router); ok {
+		srv.addRoutes(r)
+	}
+}
+
+func (s *mfaServer) addRoutes(r *rest.Router) {
+	r.Authenticated().Confirmed().Route(MFA, func(rt *rest.Router) {
+		rt.Route(TOTP, func(tr *rest.Router) {
+			tr.Post(rest.Root, s.EnrollTOTP)
+			tr.Post(Confirm, s.ConfirmTOTP)
+			tr.Delete(rest.Root, s.DisableTOTP)
+		})
+	})
+}
+
+// EnrollTOTP begins a totp enrollment.
+func (s *mfaServer) EnrollTOTP(w http.ResponseWriter, r *http.Request) {
+	// we can safely ignore this error since this route is
+	// protected we've already checked for a valid user id
+	uid, _ := rest.GetUserID(r)
+	s.Debugf("enroll totp: %s", uid)
+	ctx := rest.FromRequest(r)
+	e, err := s.API.EnrollTOTP(ctx, uid)
+	if err != nil {
+		s.ResponseError(w, err)
+		return
+	}
+	res := &TOTPResponse{
+		Secret: e.Secret,
+		URL:    e.URL,
+		QRCode: e.QRCode,
+	}
+	s.Response(w, res)
+}
+
+// ConfirmTOTP confirms a totp enrollment.
+func (s *mfaServer) ConfirmTOTP(w http.ResponseWriter, r *http.Request) {
+	uid, _ := rest.GetUserID(r)
+	req := new(Request)
+	err := rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	s.Debugf("confirm totp: %s", uid)
+	ctx := rest.FromRequest(r)
+	err = s.API.ConfirmTOTP(ctx, uid, req.Code)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	s.Response(w, nil)
+}
+
+// DisableTOTP disables totp.
+func (s *mfaServer) DisableTOTP(w http.ResponseWriter, r *http.Request) {
+	uid, _ := rest.GetUserID(r)
+	req := new(Request)
+	err := rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	s.Debugf("disable totp: %s", uid)
+	ctx := rest.FromRequest(r)
+	err = s.API.DisableTOTP(ctx, uid, req.Code)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	s.Response(w, nil)
+}
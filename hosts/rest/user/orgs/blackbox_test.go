@@ -0,0 +1,276 @@
+package orgs_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/hosts/rest"
+	"github.com/jrapoport/gothic/hosts/rest/user/orgs"
+	"github.com/jrapoport/gothic/jwt"
+	"github.com/jrapoport/gothic/models/org"
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tcore"
+	"github.com/jrapoport/gothic/test/thttp"
+	"github.com/jrapoport/gothic/test/tsrv"
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testServer(t *testing.T) (*rest.Host, *httptest.Server) {
+	srv, web, _ := tsrv.RESTHost(t, []rest.RegisterServer{
+		orgs.RegisterServer,
+	}, false)
+	c := srv.Config()
+	c.Signup.AutoConfirm = true
+	t.Cleanup(func() {
+		web.Close()
+	})
+	return srv, web
+}
+
+func orgPath(oid string, path string) string {
+	return orgs.Orgs + "/" + oid + path
+}
+
+func memberPath(oid, uid string) string {
+	return orgPath(oid, "/members/"+uid)
+}
+
+func createOrg(t *testing.T, web *httptest.Server, tok, name string) *rest.OrgResponse {
+	req := &orgs.Request{Name: &name, Data: types.Map{"plan": "pro"}}
+	res, err := thttp.DoAuthRequest(t, web, http.MethodPost, orgs.Orgs, tok, nil, req)
+	require.NoError(t, err)
+	var or rest.OrgResponse
+	err = json.Unmarshal([]byte(res), &or)
+	require.NoError(t, err)
+	return &or
+}
+
+func sendInvite(t *testing.T, web *httptest.Server, tok, oid string, req *orgs.InviteRequest) (*rest.OrgInviteResponse, error) {
+	res, err := thttp.DoAuthRequest(t, web, http.MethodPost, orgPath(oid, "/invites"), tok, nil, req)
+	if err != nil {
+		return nil, err
+	}
+	var ir rest.OrgInviteResponse
+	err = json.Unmarshal([]byte(res), &ir)
+	require.NoError(t, err)
+	return &ir, nil
+}
+
+func joinOrg(t *testing.T, web *httptest.Server, owner, oid string, u *user.User, tok string, role org.Role) {
+	ir, err := sendInvite(t, web, owner, oid, &orgs.InviteRequest{
+		Email: u.Email,
+		Role:  role.String(),
+	})
+	require.NoError(t, err)
+	req := &orgs.AcceptRequest{Token: ir.Token}
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, orgs.Orgs+orgs.Accept, tok, nil, req)
+	require.NoError(t, err)
+}
+
+func TestOrgsServer_CreateOrg(t *testing.T) {
+	t.Parallel()
+	srv, web := testServer(t)
+	_, bt := tcore.TestUser(t, srv.API, "", false)
+	// not authorized
+	_, err := thttp.DoRequest(t, web, http.MethodPost, orgs.Orgs, nil, nil)
+	assert.Error(t, err)
+	// no name
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, orgs.Orgs, bt, nil, &orgs.Request{})
+	assert.Error(t, err)
+	empty := " "
+	req := &orgs.Request{Name: &empty}
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, orgs.Orgs, bt, nil, req)
+	assert.Error(t, err)
+	or := createOrg(t, web, bt, "acme")
+	assert.NotEmpty(t, or.OrgID)
+	assert.Equal(t, "acme", or.Name)
+	assert.Equal(t, "pro", or.Data["plan"])
+	_ = createOrg(t, web, bt, "another")
+	res, err := thttp.DoAuthRequest(t, web, http.MethodGet, orgs.Orgs, bt, nil, nil)
+	require.NoError(t, err)
+	var list []*rest.OrgResponse
+	err = json.Unmarshal([]byte(res), &list)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, or.OrgID, list[0].OrgID)
+}
+
+func TestOrgsServer_Org(t *testing.T) {
+	t.Parallel()
+	srv, web := testServer(t)
+	_, bt := tcore.TestUser(t, srv.API, "", false)
+	or := createOrg(t, web, bt, "acme")
+	// bad org id
+	_, err := thttp.DoAuthRequest(t, web, http.MethodGet, orgPath("bad", ""), bt, nil, nil)
+	assert.Error(t, err)
+	// not a member
+	_, other := tcore.TestUser(t, srv.API, "", false)
+	_, err = thttp.DoAuthRequest(t, web, http.MethodGet, orgPath(or.OrgID, ""), other, nil, nil)
+	assert.Error(t, err)
+	res, err := thttp.DoAuthRequest(t, web, http.MethodGet, orgPath(or.OrgID, ""), bt, nil, nil)
+	require.NoError(t, err)
+	var got rest.OrgResponse
+	err = json.Unmarshal([]byte(res), &got)
+	require.NoError(t, err)
+	assert.Equal(t, or.OrgID, got.OrgID)
+	// update
+	name := "renamed"
+	req := &orgs.Request{Name: &name}
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPut, orgPath(or.OrgID, ""), other, nil, req)
+	assert.Error(t, err)
+	res, err = thttp.DoAuthRequest(t, web, http.MethodPut, orgPath(or.OrgID, ""), bt, nil, req)
+	require.NoError(t, err)
+	err = json.Unmarshal([]byte(res), &got)
+	require.NoError(t, err)
+	assert.Equal(t, name, got.Name)
+	assert.Equal(t, "pro", got.Data["plan"])
+	// delete
+	_, err = thttp.DoAuthRequest(t, web, http.MethodDelete, orgPath(or.OrgID, ""), other, nil, nil)
+	assert.Error(t, err)
+	_, err = thttp.DoAuthRequest(t, web, http.MethodDelete, orgPath(or.OrgID, ""), bt, nil, nil)
+	require.NoError(t, err)
+	_, err = thttp.DoAuthRequest(t, web, http.MethodGet, orgPath(or.OrgID, ""), bt, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestOrgsServer_Invites(t *testing.T) {
+	t.Parallel()
+	srv, web := testServer(t)
+	_, bt := tcore.TestUser(t, srv.API, "", false)
+	or := createOrg(t, web, bt, "acme")
+	u, tok := tcore.TestUser(t, srv.API, "", false)
+	// bad requests
+	tests := []*orgs.InviteRequest{
+		{Email: ""},
+		{Email: "@"},
+		{Email: u.Email, Role: "bad"},
+		{Email: u.Email, Role: org.RoleOwner.String()},
+	}
+	for _, test := range tests {
+		_, err := sendInvite(t, web, bt, or.OrgID, test)
+		assert.Error(t, err)
+	}
+	// not a member
+	_, err := sendInvite(t, web, tok, or.OrgID, &orgs.InviteRequest{Email: u.Email})
+	assert.Error(t, err)
+	ir, err := sendInvite(t, web, bt, or.OrgID, &orgs.InviteRequest{Email: u.Email})
+	require.NoError(t, err)
+	assert.Equal(t, or.OrgID, ir.OrgID)
+	assert.Equal(t, u.Email, ir.Email)
+	assert.Equal(t, org.RoleMember.String(), ir.Role)
+	// mail is offline so the token is returned
+	assert.NotEmpty(t, ir.Token)
+	accept := orgs.Orgs + orgs.Accept
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, accept, tok, nil, &orgs.AcceptRequest{})
+	assert.Error(t, err)
+	// wrong user
+	_, other := tcore.TestUser(t, srv.API, "", false)
+	req := &orgs.AcceptRequest{Token: ir.Token}
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, accept, other, nil, req)
+	assert.Error(t, err)
+	res, err := thttp.DoAuthRequest(t, web, http.MethodPost, accept, tok, nil, req)
+	require.NoError(t, err)
+	var mr rest.MemberResponse
+	err = json.Unmarshal([]byte(res), &mr)
+	require.NoError(t, err)
+	assert.Equal(t, or.OrgID, mr.OrgID)
+	assert.Equal(t, u.ID.String(), mr.UserID)
+	assert.Equal(t, org.RoleMember.String(), mr.Role)
+	// used
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, accept, tok, nil, req)
+	assert.Error(t, err)
+}
+
+func TestOrgsServer_Members(t *testing.T) {
+	t.Parallel()
+	srv, web := testServer(t)
+	owner, bt := tcore.TestUser(t, srv.API, "", false)
+	or := createOrg(t, web, bt, "acme")
+	u, tok := tcore.TestUser(t, srv.API, "", false)
+	joinOrg(t, web, bt, or.OrgID, u, tok, org.RoleMember)
+	res, err := thttp.DoAuthRequest(t, web, http.MethodGet, orgPath(or.OrgID, "/members"), tok, nil, nil)
+	require.NoError(t, err)
+	var list []*rest.MemberResponse
+	err = json.Unmarshal([]byte(res), &list)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, owner.ID.String(), list[0].UserID)
+	assert.Equal(t, org.RoleOwner.String(), list[0].Role)
+	assert.Equal(t, u.ID.String(), list[1].UserID)
+	// change role
+	path := memberPath(or.OrgID, u.ID.String())
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPut, memberPath(or.OrgID, "bad"), bt, nil, nil)
+	assert.Error(t, err)
+	req := &orgs.MemberRequest{Role: "bad"}
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPut, path, bt, nil, req)
+	assert.Error(t, err)
+	req = &orgs.MemberRequest{Role: org.RoleAdmin.String()}
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPut, path, tok, nil, req)
+	assert.Error(t, err)
+	res, err = thttp.DoAuthRequest(t, web, http.MethodPut, path, bt, nil, req)
+	require.NoError(t, err)
+	var mr rest.MemberResponse
+	err = json.Unmarshal([]byte(res), &mr)
+	require.NoError(t, err)
+	assert.Equal(t, org.RoleAdmin.String(), mr.Role)
+	// remove
+	ownerPath := memberPath(or.OrgID, owner.ID.String())
+	_, err = thttp.DoAuthRequest(t, web, http.MethodDelete, ownerPath, tok, nil, nil)
+	assert.Error(t, err)
+	_, err = thttp.DoAuthRequest(t, web, http.MethodDelete, ownerPath, bt, nil, nil)
+	assert.Error(t, err)
+	_, err = thttp.DoAuthRequest(t, web, http.MethodDelete, path, bt, nil, nil)
+	require.NoError(t, err)
+	_, err = thttp.DoAuthRequest(t, web, http.MethodGet, orgPath(or.OrgID, "/members"), tok, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestOrgsServer_SwitchOrg(t *testing.T) {
+	t.Parallel()
+	srv, web := testServer(t)
+	j := srv.Config().JWT
+	u, _ := tcore.TestUser(t, srv.API, "", false)
+	bt, err := srv.API.GrantBearerToken(context.Background(), u)
+	require.NoError(t, err)
+	tok := bt.String()
+	or := createOrg(t, web, tok, "acme")
+	path := orgs.Orgs + orgs.Switch
+	// no refresh token
+	req := &orgs.SwitchRequest{OrgID: or.OrgID}
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, path, tok, nil, req)
+	assert.Error(t, err)
+	// bad org id
+	req = &orgs.SwitchRequest{OrgID: "bad", Token: bt.RefreshToken.String()}
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, path, tok, nil, req)
+	assert.Error(t, err)
+	// not a member
+	req = &orgs.SwitchRequest{OrgID: uuid.New().String(), Token: bt.RefreshToken.String()}
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, path, tok, nil, req)
+	assert.Error(t, err)
+	req = &orgs.SwitchRequest{OrgID: or.OrgID, Token: bt.RefreshToken.String()}
+	res, err := thttp.DoAuthRequest(t, web, http.MethodPost, path, tok, nil, req)
+	require.NoError(t, err)
+	var br rest.BearerResponse
+	err = json.Unmarshal([]byte(res), &br)
+	require.NoError(t, err)
+	claims, err := jwt.ParseUserClaims(j, br.Access)
+	require.NoError(t, err)
+	assert.Equal(t, or.OrgID, claims.OrgID().String())
+	assert.Equal(t, org.RoleOwner.String(), claims.OrgRole())
+	// clear
+	req = &orgs.SwitchRequest{Token: br.Refresh}
+	res, err = thttp.DoAuthRequest(t, web, http.MethodPost, path, br.Access, nil, req)
+	require.NoError(t, err)
+	err = json.Unmarshal([]byte(res), &br)
+	require.NoError(t, err)
+	claims, err = jwt.ParseUserClaims(j, br.Access)
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Nil, claims.OrgID())
+	assert.Empty(t, claims.OrgRole())
+}
@@ -0,0 +1,374 @@
+package orgs
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/hosts/rest"
+	"github.com/jrapoport/gothic/models/org"
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/models/types/key"
+)
+
+const (
+	// Orgs is the organizations endpoint.
+	Orgs = "/orgs"
+	// OrgID selects an organization.
+	OrgID = "/{" + key.OrgID + "}"
+	// Members is the organization members endpoint.
+	Members = OrgID + "/members"
+	// MemberID selects an organization member.
+	MemberID = Members + "/{" + key.UserID + "}"
+	// Invites is the organization invites endpoint.
+	Invites = OrgID + "/invites"
+	// Accept accepts an organization invite.
+	Accept = "/accept"
+	// Switch switches the active organization.
+	Switch = "/switch"
+)
+
+// Request is an organization request.
+type Request struct {
+	Name *string   `json:"name" form:"name"`
+	Data types.Map `json:"data" form:"data"`
+}
+
+// MemberRequest is an organization member request.
+type MemberRequest struct {
+	Role string `json:"role" form:"role"`
+}
+
+// InviteRequest is an organization invite request.
+type InviteRequest struct {
+	Email string `json:"email" form:"email"`
+	Role  string `json:"role" form:"role"`
+}
+
+// AcceptRequest is an accept organization invite request.
+type AcceptRequest struct {
+	Token string `json:"token" form:"token"`
+}
+
+// SwitchRequest is a switch organization request. If the org
+// id is empty the active organization is cleared.
+type SwitchRequest struct {
+	OrgID string `json:"org_id" form:"org_id"`
+	Token string `json:"token" form:"token"`
+}
+
+type orgsServer struct {
+	*rest.Server
+}
+
+func newOrgsServer(srv *rest.Server) *orgsServer {
+	srv.Logger = srv.WithName("orgs")
+	return &orgsServer{srv}
+}
+
+// RegisterServer registers a new organizations server.
+func RegisterServer(s *http.Server, srv *rest.Server) {
+	register(s, newOrgsServer(srv))
+}
+
+func register(s *http.Server, srv *orgsServer) {
+	if r, ok := s.Handler.(*rest.Router); ok {
+		srv.addRoutes(r)
+	}
+}
+
+func (s *orgsServer) addRoutes(r *rest.Router) {
+	r.Authenticated().Confirmed().Route(Orgs, func(rt *rest.Router) {
+		rt.Post(rest.Root, s.CreateOrg)
+		rt.Get(rest.Root, s.ListOrgs)
+		rt.Post(Accept, s.AcceptInvite)
+		rt.Post(Switch, s.SwitchOrg)
+		rt.Get(OrgID, s.GetOrg)
+		rt.Put(OrgID, s.UpdateOrg)
+		rt.Delete(OrgID, s.DeleteOrg)
+		rt.Get(Members, s.ListMembers)
+		rt.Put(MemberID, s.ChangeMemberRole)
+		rt.Delete(MemberID, s.RemoveMember)
+		rt.Post(Invites, s.SendInvite)
+	})
+}
+
+// CreateOrg creates an organization.
+func (s *orgsServer) CreateOrg(w http.ResponseWriter, r *http.Request) {
+	// we can safely ignore this error since this route is
+	// protected we've already checked for a valid user id
+	uid, _ := rest.GetUserID(r)
+	req := &Request{}
+	err := rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Name == nil {
+		err = errors.New("name required")
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	s.Debugf("create organization: %s %s", uid, *req.Name)
+	ctx := rest.FromRequest(r)
+	o, err := s.API.CreateOrg(ctx, uid, *req.Name, req.Data)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	res := rest.NewOrgResponse(o)
+	s.Response(w, res)
+}
+
+// ListOrgs lists the organizations of the user.
+func (s *orgsServer) ListOrgs(w http.ResponseWriter, r *http.Request) {
+	uid, _ := rest.GetUserID(r)
+	s.Debugf("list organizations: %s", uid)
+	ctx := rest.FromRequest(r)
+	list, err := s.API.GetUserOrgs(ctx, uid)
+	if err != nil {
+		s.ResponseError(w, err)
+		return
+	}
+	res := rest.NewOrgsResponse(list)
+	s.Response(w, res)
+}
+
+// GetOrg gets an organization.
+func (s *orgsServer) GetOrg(w http.ResponseWriter, r *http.Request) {
+	uid, _ := rest.GetUserID(r)
+	oid, err := orgID(r)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	s.Debugf("get organization: %s %s", uid, oid)
+	ctx := rest.FromRequest(r)
+	o, err := s.API.GetOrg(ctx, uid, oid)
+	if err != nil {
+		s.ResponseCode(w, http.StatusNotFound, err)
+		return
+	}
+	res := rest.NewOrgResponse(o)
+	s.Response(w, res)
+}
+
+// UpdateOrg updates an organization.
+func (s *orgsServer) UpdateOrg(w http.ResponseWriter, r *http.Request) {
+	uid, _ := rest.GetUserID(r)
+	oid, err := orgID(r)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	req := &Request{}
+	err = rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	s.Debugf("update organization: %s %s", uid, oid)
+	ctx := rest.FromRequest(r)
+	o, err := s.API.UpdateOrg(ctx, uid, oid, req.Name, req.Data)
+	if err != nil {
+		s.ResponseCode(w, http.StatusForbidden, err)
+		return
+	}
+	res := rest.NewOrgResponse(o)
+	s.Response(w, res)
+}
+
+// DeleteOrg deletes an organization.
+func (s *orgsServer) DeleteOrg(w http.ResponseWriter, r *http.Request) {
+	uid, _ := rest.GetUserID(r)
+	oid, err := orgID(r)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	s.Debugf("delete organization: %s %s", uid, oid)
+	ctx := rest.FromRequest(r)
+	err = s.API.DeleteOrg(ctx, uid, oid)
+	if err != nil {
+		s.ResponseCode(w, http.StatusForbidden, err)
+		return
+	}
+	s.Response(w, nil)
+}
+
+// ListMembers lists the members of an organization.
+func (s *orgsServer) ListMembers(w http.ResponseWriter, r *http.Request) {
+	uid, _ := rest.GetUserID(r)
+	oid, err := orgID(r)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	s.Debugf("list organization members: %s %s", uid, oid)
+	ctx := rest.FromRequest(r)
+	list, err := s.API.GetOrgMembers(ctx, uid, oid)
+	if err != nil {
+		s.ResponseCode(w, http.StatusNotFound, err)
+		return
+	}
+	res := rest.NewMembersResponse(list)
+	s.Response(w, res)
+}
+
+// ChangeMemberRole changes the role of an organization member.
+func (s *orgsServer) ChangeMemberRole(w http.ResponseWriter, r *http.Request) {
+	uid, _ := rest.GetUserID(r)
+	oid, mid, err := memberID(r)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	req := &MemberRequest{}
+	err = rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	role := org.ToRole(req.Role)
+	if !role.Valid() {
+		err = errors.New("invalid role")
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	s.Debugf("change organization member role: %s %s %s %s",
+		uid, oid, mid, role)
+	ctx := rest.FromRequest(r)
+	m, err := s.API.ChangeOrgMemberRole(ctx, uid, oid, mid, role)
+	if err != nil {
+		s.ResponseCode(w, http.StatusForbidden, err)
+		return
+	}
+	res := rest.NewMemberResponse(m)
+	s.Response(w, res)
+}
+
+// RemoveMember removes a member from an organization.
+func (s *orgsServer) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	uid, _ := rest.GetUserID(r)
+	oid, mid, err := memberID(r)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	s.Debugf("remove organization member: %s %s %s", uid, oid, mid)
+	ctx := rest.FromRequest(r)
+	err = s.API.RemoveOrgMember(ctx, uid, oid, mid)
+	if err != nil {
+		s.ResponseCode(w, http.StatusForbidden, err)
+		return
+	}
+	s.Response(w, nil)
+}
+
+// SendInvite sends an invite to join an organization.
+func (s *orgsServer) SendInvite(w http.ResponseWriter, r *http.Request) {
+	uid, _ := rest.GetUserID(r)
+	oid, err := orgID(r)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	req := &InviteRequest{}
+	err = rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	role := org.RoleMember
+	if req.Role != "" {
+		role = org.ToRole(req.Role)
+	}
+	if !role.Valid() {
+		err = errors.New("invalid role")
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	s.Debugf("send organization invite: %s %s %s", uid, oid, req.Email)
+	ctx := rest.FromRequest(r)
+	inv, err := s.API.SendOrgInvite(ctx, uid, oid, req.Email, role)
+	if err != nil {
+		s.ResponseCode(w, http.StatusForbidden, err)
+		return
+	}
+	res := rest.NewOrgInviteResponse(inv)
+	s.Response(w, res)
+}
+
+// AcceptInvite accepts an organization invite.
+func (s *orgsServer) AcceptInvite(w http.ResponseWriter, r *http.Request) {
+	uid, _ := rest.GetUserID(r)
+	req := &AcceptRequest{}
+	err := rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Token == "" {
+		err = errors.New("invite token required")
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	s.Debugf("accept organization invite: %s", uid)
+	ctx := rest.FromRequest(r)
+	m, err := s.API.AcceptOrgInvite(ctx, uid, req.Token)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	res := rest.NewMemberResponse(m)
+	s.Response(w, res)
+}
+
+// SwitchOrg switches the active organization of the session and
+// returns a new bearer token for the organization.
+func (s *orgsServer) SwitchOrg(w http.ResponseWriter, r *http.Request) {
+	uid, _ := rest.GetUserID(r)
+	req := &SwitchRequest{}
+	err := rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Token == "" {
+		err = errors.New("refresh token not found")
+		s.ResponseCode(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	oid := uuid.Nil
+	if req.OrgID != "" {
+		oid, err = uuid.Parse(req.OrgID)
+		if err != nil {
+			s.ResponseCode(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	s.Debugf("switch organization: %s %s", uid, oid)
+	ctx := rest.FromRequest(r)
+	bt, err := s.API.SwitchOrg(ctx, uid, req.Token, oid)
+	if err != nil {
+		s.ResponseCode(w, http.StatusForbidden, err)
+		return
+	}
+	s.AuthResponse(w, r, bt.Token, bt)
+}
+
+func orgID(r *http.Request) (uuid.UUID, error) {
+	return uuid.Parse(rest.URLParam(r, key.OrgID))
+}
+
+func memberID(r *http.Request) (uuid.UUID, uuid.UUID, error) {
+	oid, err := orgID(r)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	mid, err := uuid.Parse(rest.URLParam(r, key.UserID))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	return oid, mid, nil
+}
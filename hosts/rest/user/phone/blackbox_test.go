@@ -0,0 +1,133 @@
+package phone_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/hosts/rest"
+	"github.com/jrapoport/gothic/hosts/rest/user/phone"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconf"
+	"github.com/jrapoport/gothic/test/tcore"
+	"github.com/jrapoport/gothic/test/thttp"
+	"github.com/jrapoport/gothic/test/tsrv"
+	"github.com/jrapoport/gothic/test/tutils"
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	change  = phone.Phone + phone.Change
+	confirm = phone.Phone + phone.Confirm
+)
+
+func testServer(t *testing.T) (*rest.Host, *httptest.Server, string) {
+	srv, web, _ := tsrv.RESTHost(t, []rest.RegisterServer{
+		phone.RegisterServer,
+	}, false)
+	c := srv.Config()
+	c.Signup.AutoConfirm = true
+	c.SMS.SendLimit = 0
+	path := tcore.MockSMS(t, srv.API, c)
+	t.Cleanup(func() {
+		web.Close()
+	})
+	return srv, web, path
+}
+
+func impersonate(t *testing.T, srv *rest.Host, u *user.User) string {
+	ctx := context.Background()
+	ctx.SetAdminID(user.SuperAdminID)
+	bt, err := srv.API.ImpersonateUser(ctx, u.ID)
+	require.NoError(t, err)
+	return bt.String()
+}
+
+func TestPhoneServer_SendChangePhone(t *testing.T) {
+	t.Parallel()
+	srv, web, path := testServer(t)
+	j := srv.Config().JWT
+	u, bt := tcore.TestUser(t, srv.API, "", false)
+	newPhone := tutils.RandomPhone()
+	// not authorized
+	_, err := thttp.DoRequest(t, web, http.MethodPost, change, nil, nil)
+	assert.Error(t, err)
+	// user not confirmed
+	bad := thttp.UserToken(t, j, false, false)
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, change, bad, nil, nil)
+	assert.Error(t, err)
+	// invalid req
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, change, bt, nil, []byte("\n"))
+	assert.Error(t, err)
+	// empty phone
+	req := new(phone.Request)
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, change, bt, nil, req)
+	assert.Error(t, err)
+	// bad phone
+	req = &phone.Request{Phone: "bad"}
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, change, bt, nil, req)
+	assert.Error(t, err)
+	req = &phone.Request{Phone: newPhone}
+	// impersonated
+	imp := impersonate(t, srv, u)
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, change, imp, nil, req)
+	assert.Error(t, err)
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, change, bt, nil, req)
+	assert.NoError(t, err)
+	code := tconf.GetSMSCode(t, path, newPhone)
+	assert.NotEmpty(t, code)
+	// rate limit
+	srv.Config().SMS.SendLimit = 5 * time.Minute
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, change, bt, nil, req)
+	assert.Error(t, err)
+}
+
+func TestPhoneServer_ConfirmChangePhone(t *testing.T) {
+	t.Parallel()
+	srv, web, path := testServer(t)
+	u, bt := tcore.TestUser(t, srv.API, "", false)
+	newPhone := tutils.RandomPhone()
+	// invalid req
+	_, err := thttp.DoAuthRequest(t, web, http.MethodPost, confirm, bt, nil, []byte("\n"))
+	assert.Error(t, err)
+	// empty code
+	req := &phone.Request{Phone: newPhone}
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, confirm, bt, nil, req)
+	assert.Error(t, err)
+	// no code sent
+	req = &phone.Request{Phone: newPhone, Code: "123456"}
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, confirm, bt, nil, req)
+	assert.Error(t, err)
+	req = &phone.Request{Phone: newPhone}
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, change, bt, nil, req)
+	require.NoError(t, err)
+	code := tconf.GetSMSCode(t, path, newPhone)
+	require.NotEmpty(t, code)
+	// bad code
+	req = &phone.Request{Phone: newPhone, Code: "bad"}
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, confirm, bt, nil, req)
+	assert.Error(t, err)
+	// impersonated
+	req = &phone.Request{Phone: newPhone, Code: code}
+	imp := impersonate(t, srv, u)
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, confirm, imp, nil, req)
+	assert.Error(t, err)
+	// confirmed
+	res, err := thttp.DoAuthRequest(t, web, http.MethodPost, confirm, bt, nil, req)
+	require.NoError(t, err)
+	var ur rest.UserResponse
+	err = json.Unmarshal([]byte(res), &ur)
+	require.NoError(t, err)
+	assert.Equal(t, u.ID.String(), ur.UserID)
+	assert.Equal(t, newPhone, ur.Phone)
+	updated, err := srv.GetUser(u.ID)
+	require.NoError(t, err)
+	assert.True(t, updated.HasPhone())
+	// codes cannot be reused
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, confirm, bt, nil, req)
+	assert.Error(t, err)
+}
@@ -0,0 +1,112 @@
+package phone
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/hosts/rest"
+)
+
+const (
+	// Phone for the phone changes.
+	Phone = "/phone"
+	// Change is the phone change endpoint.
+	Change = "/change"
+	// Confirm a phone change.
+	Confirm = "/confirm"
+)
+
+// Request is a phone server request
+type Request struct {
+	Phone string `json:"phone" form:"phone"`
+	Code  string `json:"code" form:"code"`
+}
+
+type phoneServer struct {
+	*rest.Server
+}
+
+func newPhoneServer(srv *rest.Server) *phoneServer {
+	srv.Logger = srv.WithName("phone")
+	return &phoneServer{srv}
+}
+
+// RegisterServer registers a new phone server.
+func RegisterServer(s *http.Server, srv *rest.Server) {
+	register(s, newPhoneServer(srv))
+}
+
+func register(s *http.Server, srv *phoneServer) {
+	if r, ok := s.Handler.(*rest.Router); ok {
+		srv.addRoutes(r)
+	}
+}
+
+func (s *phoneServer) addRoutes(r *rest.Router) {
+	r.Authenticated().Confirmed().Route(Phone, func(rt *rest.Router) {
+		rt.Post(Change, s.SendChangePhone)
+		rt.Post(Confirm, s.ConfirmChangePhone)
+	})
+}
+
+// SendChangePhone sends a confirmation code to a new phone number.
+func (s *phoneServer) SendChangePhone(w http.ResponseWriter, r *http.Request) {
+	// we can safely ignore this error since this route is
+	// protected we've already checked for a valid user id
+	uid, _ := rest.GetUserID(r)
+	req := new(Request)
+	err := rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Phone == "" {
+		err = errors.New("phone not found")
+		s.ResponseCode(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	s.Debugf("send change phone: %s %s", uid, req.Phone)
+	ctx := rest.FromRequest(r)
+	err = s.API.SendChangePhone(ctx, uid, req.Phone)
+	if errors.Is(err, config.ErrRateLimitExceeded) {
+		s.ResponseCode(w, http.StatusTooEarly, err)
+		return
+	} else if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	s.Response(w, nil)
+}
+
+// ConfirmChangePhone confirms a phone change with the sms code.
+func (s *phoneServer) ConfirmChangePhone(w http.ResponseWriter, r *http.Request) {
+	uid, _ := rest.GetUserID(r)
+	req := new(Request)
+	err := rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Phone == "" || req.Code == "" {
+		err = errors.New("phone or code not found")
+		s.ResponseCode(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	s.Debugf("confirm change phone: %s %s", uid, req.Phone)
+	ctx := rest.FromRequest(r)
+	u, err := s.API.ConfirmChangePhone(ctx, uid, req.Phone, req.Code)
+	if errors.Is(err, config.ErrRateLimitExceeded) {
+		s.ResponseCode(w, http.StatusTooEarly, err)
+		return
+	}
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	res := rest.NewUserResponse(u)
+	if s.Config().MaskEmails {
+		res.MaskEmail()
+	}
+	s.Response(w, res)
+}
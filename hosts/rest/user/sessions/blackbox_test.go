@@ -0,0 +1,117 @@
+package sessions_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/hosts/rest"
+	"github.com/jrapoport/gothic/hosts/rest/user/sessions"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tcore"
+	"github.com/jrapoport/gothic/test/thttp"
+	"github.com/jrapoport/gothic/test/tsrv"
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testServer(t *testing.T) (*rest.Host, *httptest.Server) {
+	srv, web, _ := tsrv.RESTHost(t, []rest.RegisterServer{
+		sessions.RegisterServer,
+	}, false)
+	c := srv.Config()
+	c.Signup.AutoConfirm = true
+	t.Cleanup(func() {
+		web.Close()
+	})
+	return srv, web
+}
+
+func grantSession(t *testing.T, srv *rest.Host, u *user.User, agent string) string {
+	ctx := context.Background()
+	ctx.SetUserAgent(agent)
+	bt, err := srv.API.GrantBearerToken(ctx, u)
+	require.NoError(t, err)
+	return bt.String()
+}
+
+func listSessions(t *testing.T, web *httptest.Server, tok string) []*rest.SessionResponse {
+	res, err := thttp.DoAuthRequest(t, web, http.MethodGet, sessions.Sessions, tok, nil, nil)
+	require.NoError(t, err)
+	var list []*rest.SessionResponse
+	err = json.Unmarshal([]byte(res), &list)
+	require.NoError(t, err)
+	return list
+}
+
+func TestSessionsServer_ListSessions(t *testing.T) {
+	t.Parallel()
+	srv, web := testServer(t)
+	j := srv.Config().JWT
+	u, bt := tcore.TestUser(t, srv.API, "", false)
+	// not authorized
+	_, err := thttp.DoRequest(t, web, http.MethodGet, sessions.Sessions, nil, nil)
+	assert.Error(t, err)
+	// user not found
+	bad := thttp.UserToken(t, j, true, false)
+	_, err = thttp.DoAuthRequest(t, web, http.MethodGet, sessions.Sessions, bad, nil, nil)
+	assert.Error(t, err)
+	_ = grantSession(t, srv, u, "curl/7.64.1")
+	list := listSessions(t, web, bt)
+	require.Len(t, list, 2)
+	assert.Equal(t, "curl/7.64.1", list[0].UserAgent)
+	assert.False(t, list[0].Current)
+	assert.True(t, list[1].Current)
+	assert.False(t, list[1].CreatedAt.IsZero())
+	assert.False(t, list[1].LastUsedAt.IsZero())
+}
+
+func TestSessionsServer_RevokeSession(t *testing.T) {
+	t.Parallel()
+	srv, web := testServer(t)
+	u, bt := tcore.TestUser(t, srv.API, "", false)
+	bt2 := grantSession(t, srv, u, "curl/7.64.1")
+	list := listSessions(t, web, bt)
+	require.Len(t, list, 2)
+	sid := list[0].SessionID
+	// bad session id
+	_, err := thttp.DoAuthRequest(t, web, http.MethodDelete,
+		sessions.Sessions+"/bad", bt, nil, nil)
+	assert.Error(t, err)
+	// session not found
+	_, err = thttp.DoAuthRequest(t, web, http.MethodDelete,
+		sessions.Sessions+"/"+uuid.New().String(), bt, nil, nil)
+	assert.Error(t, err)
+	_, err = thttp.DoAuthRequest(t, web, http.MethodDelete,
+		sessions.Sessions+"/"+sid, bt, nil, nil)
+	assert.NoError(t, err)
+	list = listSessions(t, web, bt)
+	require.Len(t, list, 1)
+	assert.True(t, list[0].Current)
+	// the bearer token is valid until it expires
+	_ = listSessions(t, web, bt2)
+}
+
+func TestSessionsServer_RevokeOtherSessions(t *testing.T) {
+	t.Parallel()
+	srv, web := testServer(t)
+	u, bt := tcore.TestUser(t, srv.API, "", false)
+	_ = grantSession(t, srv, u, "agent 1")
+	_ = grantSession(t, srv, u, "agent 2")
+	res, err := thttp.DoAuthRequest(t, web, http.MethodDelete, sessions.Sessions, bt, nil, nil)
+	require.NoError(t, err)
+	var rr rest.RevokedSessionsResponse
+	err = json.Unmarshal([]byte(res), &rr)
+	require.NoError(t, err)
+	assert.Equal(t, 2, rr.Revoked)
+	list := listSessions(t, web, bt)
+	require.Len(t, list, 1)
+	assert.True(t, list[0].Current)
+	// no current session
+	tok := thttp.UserToken(t, srv.Config().JWT, true, false)
+	_, err = thttp.DoAuthRequest(t, web, http.MethodDelete, sessions.Sessions, tok, nil, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,93 @@
+package sessions
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/hosts/rest"
+	"github.com/jrapoport/gothic/models/types/key"
+)
+
+const (
+	// Sessions is the device sessions endpoint.
+	Sessions = "/sessions"
+	// SessionID selects a session.
+	SessionID = "/{" + key.SessionID + "}"
+)
+
+type sessionsServer struct {
+	*rest.Server
+}
+
+func newSessionsServer(srv *rest.Server) *sessionsServer {
+	srv.Logger = srv.WithName("sessions")
+	return &sessionsServer{srv}
+}
+
+// RegisterServer registers a new sessions server.
+func RegisterServer(s *http.Server, srv *rest.Server) {
+	register(s, newSessionsServer(srv))
+}
+
+func register(s *http.Server, srv *sessionsServer) {
+	if r, ok := s.Handler.(*rest.Router); ok {
+		srv.addRoutes(r)
+	}
+}
+
+func (s *sessionsServer) addRoutes(r *rest.Router) {
+	r.Authenticated().Confirmed().Session().Route(Sessions, func(rt *rest.Router) {
+		rt.Get(rest.Root, s.ListSessions)
+		rt.Delete(rest.Root, s.RevokeOtherSessions)
+		rt.Delete(SessionID, s.RevokeSession)
+	})
+}
+
+// ListSessions lists the active sessions.
+func (s *sessionsServer) ListSessions(w http.ResponseWriter, r *http.Request) {
+	// we can safely ignore this error since this route is
+	// protected we've already checked for a valid user id
+	uid, _ := rest.GetUserID(r)
+	s.Debugf("list sessions: %s", uid)
+	ctx := rest.FromRequest(r)
+	sessions, err := s.API.GetSessions(ctx, uid)
+	if err != nil {
+		s.ResponseError(w, err)
+		return
+	}
+	res := rest.NewSessionsResponse(sessions, ctx.SessionID())
+	s.Response(w, res)
+}
+
+// RevokeSession revokes a session.
+func (s *sessionsServer) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	uid, _ := rest.GetUserID(r)
+	sessionID := rest.URLParam(r, key.SessionID)
+	sid, err := uuid.Parse(sessionID)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	s.Debugf("revoke session: %s %s", uid, sid)
+	ctx := rest.FromRequest(r)
+	err = s.API.RevokeSession(ctx, uid, sid)
+	if err != nil {
+		s.ResponseCode(w, http.StatusNotFound, err)
+		return
+	}
+	s.Response(w, nil)
+}
+
+// RevokeOtherSessions revokes all the sessions except the current session.
+func (s *sessionsServer) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	uid, _ := rest.GetUserID(r)
+	s.Debugf("revoke other sessions: %s", uid)
+	ctx := rest.FromRequest(r)
+	n, err := s.API.RevokeOtherSessions(ctx, uid)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	res := &rest.RevokedSessionsResponse{Revoked: n}
+	s.Response(w, res)
+}
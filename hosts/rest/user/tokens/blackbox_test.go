@@ -0,0 +1,182 @@
+package tokens_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/hosts/rest"
+	"github.com/jrapoport/gothic/hosts/rest/user/tokens"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/test/tcore"
+	"github.com/jrapoport/gothic/test/thttp"
+	"github.com/jrapoport/gothic/test/tsrv"
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const ping = "/ping"
+
+// registerPing registers an authenticated endpoint that personal tokens can use.
+func registerPing(s *http.Server, _ *rest.Server) {
+	if r, ok := s.Handler.(*rest.Router); ok {
+		r.Authenticated().Get(ping, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+	}
+}
+
+func testServer(t *testing.T) (*rest.Host, *httptest.Server) {
+	srv, web, _ := tsrv.RESTHost(t, []rest.RegisterServer{
+		tokens.RegisterServer,
+		registerPing,
+	}, false)
+	c := srv.Config()
+	c.Signup.AutoConfirm = true
+	t.Cleanup(func() {
+		web.Close()
+	})
+	return srv, web
+}
+
+func createToken(t *testing.T, web *httptest.Server, tok string, req *tokens.Request) (*rest.PersonalTokenResponse, error) {
+	res, err := thttp.DoAuthRequest(t, web, http.MethodPost, tokens.Tokens, tok, nil, req)
+	if err != nil {
+		return nil, err
+	}
+	var pr rest.PersonalTokenResponse
+	err = json.Unmarshal([]byte(res), &pr)
+	require.NoError(t, err)
+	return &pr, nil
+}
+
+func listTokens(t *testing.T, web *httptest.Server, tok string) []*rest.PersonalTokenResponse {
+	res, err := thttp.DoAuthRequest(t, web, http.MethodGet, tokens.Tokens, tok, nil, nil)
+	require.NoError(t, err)
+	var list []*rest.PersonalTokenResponse
+	err = json.Unmarshal([]byte(res), &list)
+	require.NoError(t, err)
+	return list
+}
+
+func TestTokensServer_CreateToken(t *testing.T) {
+	t.Parallel()
+	srv, web := testServer(t)
+	_, bt := tcore.TestUser(t, srv.API, "", false)
+	// not authorized
+	_, err := thttp.DoRequest(t, web, http.MethodPost, tokens.Tokens, nil, nil)
+	assert.Error(t, err)
+	tests := []*tokens.Request{
+		{Name: "", Scopes: []string{token.ScopeRead}},
+		{Name: "test"},
+		{Name: "test", Scopes: []string{"bad"}},
+		{Name: "test", Scopes: []string{token.ScopeAdmin}},
+		{Name: "test", Scopes: []string{token.ScopeRead}, ExpiresIn: -1},
+	}
+	for _, test := range tests {
+		_, err = createToken(t, web, bt, test)
+		assert.Error(t, err)
+	}
+	req := &tokens.Request{
+		Name:      "test",
+		Scopes:    []string{token.ScopeRead, token.ScopeWrite},
+		ExpiresIn: 3600,
+	}
+	pr, err := createToken(t, web, bt, req)
+	require.NoError(t, err)
+	assert.NotEmpty(t, pr.TokenID)
+	assert.True(t, token.IsPersonalToken(pr.Token))
+	assert.Equal(t, req.Name, pr.Name)
+	assert.Equal(t, req.Scopes, pr.Scopes)
+	assert.False(t, pr.CreatedAt.IsZero())
+	require.NotNil(t, pr.ExpiresAt)
+	assert.Nil(t, pr.LastUsedAt)
+	// personal tokens can not create personal tokens
+	_, err = createToken(t, web, pr.Token, req)
+	assert.Error(t, err)
+	// admin
+	_, bt = tcore.TestUser(t, srv.API, "", true)
+	req = &tokens.Request{
+		Name:   "admin",
+		Scopes: []string{token.ScopeAdmin},
+	}
+	pr, err = createToken(t, web, bt, req)
+	require.NoError(t, err)
+	assert.Nil(t, pr.ExpiresAt)
+}
+
+func TestTokensServer_ListTokens(t *testing.T) {
+	t.Parallel()
+	srv, web := testServer(t)
+	_, bt := tcore.TestUser(t, srv.API, "", false)
+	list := listTokens(t, web, bt)
+	assert.Len(t, list, 0)
+	read, err := createToken(t, web, bt, &tokens.Request{
+		Name:   "read",
+		Scopes: []string{token.ScopeRead},
+	})
+	require.NoError(t, err)
+	write, err := createToken(t, web, bt, &tokens.Request{
+		Name:   "write",
+		Scopes: []string{token.ScopeWrite},
+	})
+	require.NoError(t, err)
+	// personal tokens can not list personal tokens
+	_, err = thttp.DoAuthRequest(t, web, http.MethodGet, tokens.Tokens, read.Token, nil, nil)
+	assert.Error(t, err)
+	_, err = thttp.DoAuthRequest(t, web, http.MethodGet, ping, read.Token, nil, nil)
+	require.NoError(t, err)
+	list = listTokens(t, web, bt)
+	require.Len(t, list, 2)
+	assert.Equal(t, write.TokenID, list[0].TokenID)
+	assert.Empty(t, list[0].Token)
+	assert.Nil(t, list[0].LastUsedAt)
+	assert.Equal(t, read.TokenID, list[1].TokenID)
+	assert.Empty(t, list[1].Token)
+	require.NotNil(t, list[1].LastUsedAt)
+	assert.Equal(t, "127.0.0.1", list[1].IPAddress)
+}
+
+func TestTokensServer_RevokeToken(t *testing.T) {
+	t.Parallel()
+	srv, web := testServer(t)
+	_, bt := tcore.TestUser(t, srv.API, "", false)
+	read, err := createToken(t, web, bt, &tokens.Request{
+		Name:   "read",
+		Scopes: []string{token.ScopeRead},
+	})
+	require.NoError(t, err)
+	write, err := createToken(t, web, bt, &tokens.Request{
+		Name:   "write",
+		Scopes: []string{token.ScopeWrite},
+	})
+	require.NoError(t, err)
+	// bad token id
+	_, err = thttp.DoAuthRequest(t, web, http.MethodDelete,
+		tokens.Tokens+"/bad", bt, nil, nil)
+	assert.Error(t, err)
+	// token not found
+	_, err = thttp.DoAuthRequest(t, web, http.MethodDelete,
+		tokens.Tokens+"/"+uuid.New().String(), bt, nil, nil)
+	assert.Error(t, err)
+	// personal tokens can not revoke personal tokens
+	_, err = thttp.DoAuthRequest(t, web, http.MethodDelete,
+		tokens.Tokens+"/"+write.TokenID, read.Token, nil, nil)
+	assert.Error(t, err)
+	_, err = thttp.DoAuthRequest(t, web, http.MethodDelete,
+		tokens.Tokens+"/"+read.TokenID, write.Token, nil, nil)
+	assert.Error(t, err)
+	_, err = thttp.DoAuthRequest(t, web, http.MethodGet, ping, read.Token, nil, nil)
+	require.NoError(t, err)
+	_, err = thttp.DoAuthRequest(t, web, http.MethodDelete,
+		tokens.Tokens+"/"+read.TokenID, bt, nil, nil)
+	assert.NoError(t, err)
+	list := listTokens(t, web, bt)
+	require.Len(t, list, 1)
+	assert.Equal(t, write.TokenID, list[0].TokenID)
+	// revoked tokens can not be used
+	_, err = thttp.DoAuthRequest(t, web, http.MethodGet, ping, read.Token, nil, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,125 @@
+package tokens
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/hosts/rest"
+	"github.com/jrapoport/gothic/models/types/key"
+)
+
+const (
+	// Tokens is the personal access tokens endpoint.
+	Tokens = "/tokens"
+	// TokenID selects a personal access token.
+	TokenID = "/{" + key.TokenID + "}"
+)
+
+// Request is a personal access token request. If expires in is
+// not set, the personal access token does not expire.
+type Request struct {
+	Name      string   `json:"name" form:"name"`
+	Scopes    []string `json:"scopes" form:"scopes"`
+	ExpiresIn int      `json:"expires_in" form:"expires_in"`
+}
+
+type tokensServer struct {
+	*rest.Server
+}
+
+func newTokensServer(srv *rest.Server) *tokensServer {
+	srv.Logger = srv.WithName("tokens")
+	return &tokensServer{srv}
+}
+
+// RegisterServer registers a new personal access tokens server.
+func RegisterServer(s *http.Server, srv *rest.Server) {
+	register(s, newTokensServer(srv))
+}
+
+func register(s *http.Server, srv *tokensServer) {
+	if r, ok := s.Handler.(*rest.Router); ok {
+		srv.addRoutes(r)
+	}
+}
+
+func (s *tokensServer) addRoutes(r *rest.Router) {
+	r.Authenticated().Confirmed().Session().Route(Tokens, func(rt *rest.Router) {
+		rt.Post(rest.Root, s.CreateToken)
+		rt.Get(rest.Root, s.ListTokens)
+		rt.Delete(TokenID, s.RevokeToken)
+	})
+}
+
+// CreateToken creates a personal access token.
+func (s *tokensServer) CreateToken(w http.ResponseWriter, r *http.Request) {
+	claims, err := rest.GetUserClaims(r)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	if claims.PersonalToken() {
+		err = errors.New("personal token not allowed")
+		s.ResponseCode(w, http.StatusForbidden, err)
+		return
+	}
+	req := &Request{}
+	err = rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.ExpiresIn < 0 {
+		err = errors.New("invalid expiration")
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	exp := time.Duration(req.ExpiresIn) * time.Second
+	uid := claims.UserID()
+	s.Debugf("create personal token: %s %s", uid, req.Name)
+	ctx := rest.FromRequest(r)
+	pt, err := s.API.CreatePersonalToken(ctx, uid, req.Name, req.Scopes, exp)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	res := rest.NewPersonalTokenResponse(pt)
+	s.Response(w, res)
+}
+
+// ListTokens lists the personal access tokens.
+func (s *tokensServer) ListTokens(w http.ResponseWriter, r *http.Request) {
+	// we can safely ignore this error since this route is
+	// protected we've already checked for a valid user id
+	uid, _ := rest.GetUserID(r)
+	s.Debugf("list personal tokens: %s", uid)
+	ctx := rest.FromRequest(r)
+	list, err := s.API.GetPersonalTokens(ctx, uid)
+	if err != nil {
+		s.ResponseError(w, err)
+		return
+	}
+	res := rest.NewPersonalTokensResponse(list)
+	s.Response(w, res)
+}
+
+// RevokeToken revokes a personal access token.
+func (s *tokensServer) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	uid, _ := rest.GetUserID(r)
+	tokenID := rest.URLParam(r, key.TokenID)
+	tid, err := uuid.Parse(tokenID)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	s.Debugf("revoke personal token: %s %s", uid, tid)
+	ctx := rest.FromRequest(r)
+	err = s.API.RevokePersonalToken(ctx, uid, tid)
+	if err != nil {
+		s.ResponseCode(w, http.StatusNotFound, err)
+		return
+	}
+	s.Response(w, nil)
+}
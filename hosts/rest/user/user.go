@@ -6,7 +6,14 @@ import (
 	"github.com/jrapoport/gothic/hosts/rest"
 	"github.com/jrapoport/gothic/hosts/rest/modules/invite"
 	"github.com/jrapoport/gothic/hosts/rest/user/confirm"
+	"github.com/jrapoport/gothic/hosts/rest/user/consents"
 	"github.com/jrapoport/gothic/hosts/rest/user/email"
+	"github.com/jrapoport/gothic/hosts/rest/user/mfa"
+	"github.com/jrapoport/gothic/hosts/rest/user/orgs"
+	"github.com/jrapoport/gothic/hosts/rest/user/phone"
+	"github.com/jrapoport/gothic/hosts/rest/user/sessions"
+	"github.com/jrapoport/gothic/hosts/rest/user/tokens"
+	"github.com/jrapoport/gothic/hosts/rest/user/webauthn"
 	"github.com/jrapoport/gothic/models/types"
 )
 
@@ -51,7 +58,14 @@ func (s *userServer) addRoutes(r *rest.Router) {
 		rt.Put(rest.Root, s.UpdateUser)
 		rt.Put(Password, s.ChangePassword)
 		confirm.RegisterServer(&http.Server{Handler: rt}, s.Clone())
+		consents.RegisterServer(&http.Server{Handler: rt}, s.Clone())
 		email.RegisterServer(&http.Server{Handler: rt}, s.Clone())
+		mfa.RegisterServer(&http.Server{Handler: rt}, s.Clone())
+		orgs.RegisterServer(&http.Server{Handler: rt}, s.Clone())
+		phone.RegisterServer(&http.Server{Handler: rt}, s.Clone())
+		sessions.RegisterServer(&http.Server{Handler: rt}, s.Clone())
+		tokens.RegisterServer(&http.Server{Handler: rt}, s.Clone())
+		webauthn.RegisterServer(&http.Server{Handler: rt}, s.Clone())
 		invite.RegisterServer(&http.Server{Handler: rt}, s.Clone())
 	})
 }
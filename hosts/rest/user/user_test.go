@@ -181,6 +181,7 @@ func TestUserServer_ChangePassword(t *testing.T) {
 	res = changePassword(tok, req)
 	assert.NotEqual(t, http.StatusOK, res.Code)
 	// success
+	srv.Config().Password.History = 2
 	req.Password = testPass
 	req.NewPassword = newPassword
 	res = changePassword(tok, req)
@@ -191,6 +192,13 @@ func TestUserServer_ChangePassword(t *testing.T) {
 	assert.NoError(t, err)
 	err = u.Authenticate(newPassword)
 	assert.NoError(t, err)
+	// reused password
+	req.Password = newPassword
+	req.NewPassword = testPass
+	res = changePassword(tok, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, res.Code)
+	assert.JSONEq(t, `{"error":"invalid password","violations":["reused"]}`,
+		res.Body.String())
 }
 
 func TestRequestErrors(t *testing.T) {
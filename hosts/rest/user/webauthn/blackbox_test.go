@@ -0,0 +1,157 @@
+package webauthn_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jrapoport/gothic/hosts/rest"
+	"github.com/jrapoport/gothic/hosts/rest/user/webauthn"
+	"github.com/jrapoport/gothic/test/tcore"
+	"github.com/jrapoport/gothic/test/thttp"
+	"github.com/jrapoport/gothic/test/tsrv"
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	register = webauthn.WebAuthn + webauthn.Register
+	finish   = register + webauthn.Finish
+)
+
+func testServer(t *testing.T) (*rest.Host, *httptest.Server) {
+	srv, web, _ := tsrv.RESTHost(t, []rest.RegisterServer{
+		webauthn.RegisterServer,
+	}, false)
+	c := srv.Config()
+	c.Signup.AutoConfirm = true
+	t.Cleanup(func() {
+		web.Close()
+	})
+	return srv, web
+}
+
+func TestWebAuthnServer_Register(t *testing.T) {
+	t.Parallel()
+	srv, web := testServer(t)
+	j := srv.Config().JWT
+	u, bt := tcore.TestUser(t, srv.API, "", false)
+	// not authorized
+	_, err := thttp.DoRequest(t, web, http.MethodPost, register, nil, nil)
+	assert.Error(t, err)
+	// user not confirmed
+	bad := thttp.UserToken(t, j, false, false)
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, register, bad, nil, nil)
+	assert.Error(t, err)
+	// user not found
+	bad = thttp.UserToken(t, j, true, false)
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, register, bad, nil, nil)
+	assert.Error(t, err)
+	// begin
+	res, err := thttp.DoAuthRequest(t, web, http.MethodPost, register, bt, nil, nil)
+	require.NoError(t, err)
+	var wr rest.WebAuthnResponse
+	err = json.Unmarshal([]byte(res), &wr)
+	require.NoError(t, err)
+	assert.NotEmpty(t, wr.Token)
+	assert.NotEmpty(t, wr.Options)
+	assert.NotNil(t, wr.ExpiresAt)
+	auth := tcore.NewAuthenticator(srv.Config())
+	att := auth.AttestationResponse(t, wr.Options)
+	// invalid req
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, finish, bt, nil, []byte("\n"))
+	assert.Error(t, err)
+	// bad token
+	req := &webauthn.FinishRequest{
+		Token:    "bad",
+		Name:     "passkey",
+		Response: att,
+	}
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, finish, bt, nil, req)
+	assert.Error(t, err)
+	// bad response
+	req = &webauthn.FinishRequest{
+		Token:    wr.Token,
+		Name:     "passkey",
+		Response: []byte(`{}`),
+	}
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, finish, bt, nil, req)
+	assert.Error(t, err)
+	// failed attempts burn the ceremony
+	req.Response = att
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, finish, bt, nil, req)
+	assert.Error(t, err)
+	// finish
+	res, err = thttp.DoAuthRequest(t, web, http.MethodPost, register, bt, nil, nil)
+	require.NoError(t, err)
+	err = json.Unmarshal([]byte(res), &wr)
+	require.NoError(t, err)
+	req.Token = wr.Token
+	req.Response = auth.AttestationResponse(t, wr.Options)
+	res, err = thttp.DoAuthRequest(t, web, http.MethodPost, finish, bt, nil, req)
+	require.NoError(t, err)
+	var cr webauthn.CredentialResponse
+	err = json.Unmarshal([]byte(res), &cr)
+	require.NoError(t, err)
+	assert.NotEmpty(t, cr.CredentialID)
+	assert.Equal(t, "passkey", cr.Name)
+	creds, err := srv.GetWebAuthnCredentials(u.ID)
+	require.NoError(t, err)
+	require.Len(t, creds, 1)
+	assert.Equal(t, creds[0].CredentialID, cr.CredentialID)
+	// ceremonies cannot be reused
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPost, finish, bt, nil, req)
+	assert.Error(t, err)
+}
+
+func TestWebAuthnServer_Credentials(t *testing.T) {
+	t.Parallel()
+	srv, web := testServer(t)
+	u, bt := tcore.TestUser(t, srv.API, "", false)
+	// empty list
+	res, err := thttp.DoAuthRequest(t, web, http.MethodGet, webauthn.WebAuthn, bt, nil, nil)
+	require.NoError(t, err)
+	var list []webauthn.CredentialResponse
+	err = json.Unmarshal([]byte(res), &list)
+	require.NoError(t, err)
+	assert.Len(t, list, 0)
+	tcore.RegisterWebAuthn(t, srv.API, srv.Config(), u)
+	// list
+	res, err = thttp.DoAuthRequest(t, web, http.MethodGet, webauthn.WebAuthn, bt, nil, nil)
+	require.NoError(t, err)
+	err = json.Unmarshal([]byte(res), &list)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	cid := list[0].CredentialID
+	// invalid req
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPut, webauthn.WebAuthn, bt, nil, []byte("\n"))
+	assert.Error(t, err)
+	// not found
+	req := &webauthn.Request{CredentialID: "bad", Name: "renamed"}
+	_, err = thttp.DoAuthRequest(t, web, http.MethodPut, webauthn.WebAuthn, bt, nil, req)
+	assert.Error(t, err)
+	// rename
+	req = &webauthn.Request{CredentialID: cid, Name: "renamed"}
+	res, err = thttp.DoAuthRequest(t, web, http.MethodPut, webauthn.WebAuthn, bt, nil, req)
+	require.NoError(t, err)
+	var cr webauthn.CredentialResponse
+	err = json.Unmarshal([]byte(res), &cr)
+	require.NoError(t, err)
+	assert.Equal(t, cid, cr.CredentialID)
+	assert.Equal(t, "renamed", cr.Name)
+	// invalid req
+	_, err = thttp.DoAuthRequest(t, web, http.MethodDelete, webauthn.WebAuthn, bt, nil, []byte("\n"))
+	assert.Error(t, err)
+	// not found
+	req = &webauthn.Request{CredentialID: "bad"}
+	_, err = thttp.DoAuthRequest(t, web, http.MethodDelete, webauthn.WebAuthn, bt, nil, req)
+	assert.Error(t, err)
+	// delete
+	req = &webauthn.Request{CredentialID: cid}
+	_, err = thttp.DoAuthRequest(t, web, http.MethodDelete, webauthn.WebAuthn, bt, nil, req)
+	assert.NoError(t, err)
+	creds, err := srv.GetWebAuthnCredentials(u.ID)
+	require.NoError(t, err)
+	assert.Len(t, creds, 0)
+}
@@ -0,0 +1,179 @@
+package webauthn
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/jrapoport/gothic/hosts/rest"
+	"github.com/jrapoport/gothic/models/credential"
+	"github.com/segmentio/encoding/json"
+)
+
+const (
+	// WebAuthn is the webauthn credentials endpoint.
+	WebAuthn = "/webauthn"
+	// Register begins a webauthn registration.
+	Register = "/register"
+	// Finish finishes a webauthn registration.
+	Finish = "/finish"
+)
+
+// Request is a webauthn server request
+type Request struct {
+	CredentialID string `json:"credential_id" form:"credential_id"`
+	Name         string `json:"name" form:"name"`
+}
+
+// FinishRequest is a webauthn registration attestation.
+type FinishRequest struct {
+	Token    string          `json:"token" form:"token"`
+	Name     string          `json:"name" form:"name"`
+	Response json.RawMessage `json:"response" form:"-"`
+}
+
+// CredentialResponse is a webauthn credential response.
+type CredentialResponse struct {
+	CredentialID   string     `json:"credential_id"`
+	Name           string     `json:"name"`
+	Transports     []string   `json:"transports,omitempty"`
+	SignCount      uint32     `json:"sign_count"`
+	BackupEligible bool       `json:"backup_eligible"`
+	BackupState    bool       `json:"backup_state"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastUsedAt     *time.Time `json:"last_used_at,omitempty"`
+}
+
+// NewCredentialResponse returns a CredentialResponse for the credential.
+func NewCredentialResponse(c *credential.Credential) *CredentialResponse {
+	return &CredentialResponse{
+		CredentialID:   c.CredentialID,
+		Name:           c.Name,
+		Transports:     c.TransportList(),
+		SignCount:      c.SignCount,
+		BackupEligible: c.BackupEligible,
+		BackupState:    c.BackupState,
+		CreatedAt:      c.CreatedAt,
+		LastUsedAt:     c.LastUsedAt,
+	}
+}
+
+type webauthnServer struct {
+	*rest.Server
+}
+
+func newWebAuthnServer(srv *rest.Server) *webauthnServer {
+	srv.Logger = srv.WithName("webauthn")
+	return &webauthnServer{srv}
+}
+
+// RegisterServer registers a new webauthn server.
+func RegisterServer(s *http.Server, srv *rest.Server) {
+	register(s, newWebAuthnServer(srv))
+}
+
+func register(s *http.Server, srv *webauthnServer) {
+	if r, ok := s.Handler.(*rest.Router); ok {
+		srv.addRoutes(r)
+	}
+}
+
+func (s *webauthnServer) addRoutes(r *rest.Router) {
+	r.Authenticated().Confirmed().Route(WebAuthn, func(rt *rest.Router) {
+		rt.Get(rest.Root, s.ListCredentials)
+		rt.Put(rest.Root, s.RenameCredential)
+		rt.Delete(rest.Root, s.DeleteCredential)
+		rt.Route(Register, func(tr *rest.Router) {
+			tr.Post(rest.Root, s.BeginRegistration)
+			tr.Post(Finish, s.FinishRegistration)
+		})
+	})
+}
+
+// BeginRegistration begins a webauthn registration.
+func (s *webauthnServer) BeginRegistration(w http.ResponseWriter, r *http.Request) {
+	// we can safely ignore this error since this route is
+	// protected we've already checked for a valid user id
+	uid, _ := rest.GetUserID(r)
+	s.Debugf("begin webauthn registration: %s", uid)
+	ctx := rest.FromRequest(r)
+	c, err := s.API.BeginWebAuthnRegistration(ctx, uid)
+	if err != nil {
+		s.ResponseError(w, err)
+		return
+	}
+	s.Response(w, rest.NewWebAuthnResponse(c))
+}
+
+// FinishRegistration finishes a webauthn registration.
+func (s *webauthnServer) FinishRegistration(w http.ResponseWriter, r *http.Request) {
+	uid, _ := rest.GetUserID(r)
+	req := new(FinishRequest)
+	err := rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	s.Debugf("finish webauthn registration: %s", uid)
+	ctx := rest.FromRequest(r)
+	c, err := s.API.FinishWebAuthnRegistration(ctx, uid,
+		req.Token, req.Name, req.Response)
+	if err != nil {
+		s.ResponseCode(w, http.StatusUnauthorized, err)
+		return
+	}
+	s.Response(w, NewCredentialResponse(c))
+}
+
+// ListCredentials lists the webauthn credentials for a user.
+func (s *webauthnServer) ListCredentials(w http.ResponseWriter, r *http.Request) {
+	uid, _ := rest.GetUserID(r)
+	s.Debugf("list webauthn credentials: %s", uid)
+	creds, err := s.API.GetWebAuthnCredentials(uid)
+	if err != nil {
+		s.ResponseError(w, err)
+		return
+	}
+	res := make([]*CredentialResponse, len(creds))
+	for i, c := range creds {
+		res[i] = NewCredentialResponse(c)
+	}
+	s.Response(w, res)
+}
+
+// RenameCredential renames a webauthn credential.
+func (s *webauthnServer) RenameCredential(w http.ResponseWriter, r *http.Request) {
+	uid, _ := rest.GetUserID(r)
+	req := new(Request)
+	err := rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	s.Debugf("rename webauthn credential: %s %s", uid, req.CredentialID)
+	ctx := rest.FromRequest(r)
+	c, err := s.API.RenameWebAuthnCredential(ctx, uid, req.CredentialID, req.Name)
+	if err != nil {
+		s.ResponseCode(w, http.StatusNotFound, err)
+		return
+	}
+	s.Response(w, NewCredentialResponse(c))
+}
+
+// DeleteCredential deletes a webauthn credential.
+func (s *webauthnServer) DeleteCredential(w http.ResponseWriter, r *http.Request) {
+	uid, _ := rest.GetUserID(r)
+	req := new(Request)
+	err := rest.UnmarshalRequest(r, req)
+	if err != nil {
+		s.ResponseCode(w, http.StatusBadRequest, err)
+		return
+	}
+	s.Debugf("delete webauthn credential: %s %s", uid, req.CredentialID)
+	ctx := rest.FromRequest(r)
+	err = s.API.DeleteWebAuthnCredential(ctx, uid, req.CredentialID)
+	if err != nil {
+		s.ResponseCode(w, http.StatusNotFound, err)
+		return
+	}
+	s.Response(w, nil)
+}
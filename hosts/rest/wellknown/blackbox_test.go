@@ -0,0 +1,48 @@
+package wellknown_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/jrapoport/gothic/core/oidc"
+	"github.com/jrapoport/gothic/hosts/rest"
+	"github.com/jrapoport/gothic/hosts/rest/wellknown"
+	"github.com/jrapoport/gothic/test/tconf"
+	"github.com/jrapoport/gothic/test/thttp"
+	"github.com/jrapoport/gothic/test/tsrv"
+	"github.com/lestrrat-go/jwx/jwk"
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWellKnownServer_GetJSONWebKeys(t *testing.T) {
+	t.Parallel()
+	srv, web, _ := tsrv.RESTHost(t, []rest.RegisterServer{
+		wellknown.RegisterServer,
+	}, false)
+	tconf.RSAKeys(t, srv.Config())
+	uri := wellknown.Endpoint + wellknown.JWKS
+	res, err := thttp.DoRequest(t, web, http.MethodGet, uri, nil, nil)
+	require.NoError(t, err)
+	set, err := jwk.Parse([]byte(res))
+	require.NoError(t, err)
+	require.Equal(t, 1, set.Len())
+	_, ok := set.LookupKeyID(tconf.RSAKeyID)
+	assert.True(t, ok)
+}
+
+func TestWellKnownServer_GetOpenIDConfiguration(t *testing.T) {
+	t.Parallel()
+	srv, web, _ := tsrv.RESTHost(t, []rest.RegisterServer{
+		wellknown.RegisterServer,
+	}, false)
+	uri := wellknown.Endpoint + wellknown.OpenIDConfiguration
+	res, err := thttp.DoRequest(t, web, http.MethodGet, uri, nil, nil)
+	require.NoError(t, err)
+	var d oidc.Discovery
+	err = json.Unmarshal([]byte(res), &d)
+	require.NoError(t, err)
+	assert.Equal(t, srv.Config().OIDC.Issuer, d.Issuer)
+	assert.Equal(t, []string{oidc.ResponseTypeCode}, d.ResponseTypesSupported)
+}
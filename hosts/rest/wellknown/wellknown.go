@@ -0,0 +1,59 @@
+package wellknown
+
+import (
+	"net/http"
+
+	"github.com/jrapoport/gothic/core/oidc"
+	"github.com/jrapoport/gothic/hosts/rest"
+)
+
+const (
+	// Endpoint is the well-known endpoint.
+	Endpoint = "/.well-known"
+	// JWKS is the JSON Web Key Set endpoint.
+	JWKS = "/jwks.json"
+	// OpenIDConfiguration is the OpenID Connect discovery endpoint.
+	OpenIDConfiguration = "/openid-configuration"
+)
+
+type wellKnownServer struct {
+	*rest.Server
+}
+
+func newWellKnownServer(srv *rest.Server) *wellKnownServer {
+	srv.Logger = srv.WithName("well-known")
+	return &wellKnownServer{srv}
+}
+
+// RegisterServer registers a new well-known server.
+func RegisterServer(s *http.Server, srv *rest.Server) {
+	register(s, newWellKnownServer(srv))
+}
+
+func register(s *http.Server, srv *wellKnownServer) {
+	if r, ok := s.Handler.(*rest.Router); ok {
+		srv.addRoutes(r)
+	}
+}
+
+func (s *wellKnownServer) addRoutes(r *rest.Router) {
+	r.Route(Endpoint, func(rt *rest.Router) {
+		rt.Get(JWKS, s.GetJSONWebKeys)
+		rt.Get(OpenIDConfiguration, s.GetOpenIDConfiguration)
+	})
+}
+
+// GetJSONWebKeys returns the public keys used to verify bearer tokens.
+func (s *wellKnownServer) GetJSONWebKeys(w http.ResponseWriter, _ *http.Request) {
+	set, err := s.API.PublicKeys()
+	if err != nil {
+		s.ResponseError(w, err)
+		return
+	}
+	s.Response(w, set)
+}
+
+// GetOpenIDConfiguration returns the OpenID Connect discovery document.
+func (s *wellKnownServer) GetOpenIDConfiguration(w http.ResponseWriter, _ *http.Request) {
+	s.Response(w, oidc.NewDiscovery(s.Config()))
+}
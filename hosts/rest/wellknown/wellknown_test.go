@@ -0,0 +1,58 @@
+package wellknown
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jrapoport/gothic/core/oidc"
+	"github.com/jrapoport/gothic/test/tconf"
+	"github.com/jrapoport/gothic/test/tsrv"
+	"github.com/lestrrat-go/jwx/jwk"
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWellKnownServer_GetJSONWebKeys(t *testing.T) {
+	t.Parallel()
+	s, _ := tsrv.RESTServer(t, false)
+	srv := newWellKnownServer(s)
+	uri := Endpoint + JWKS
+	// secrets are never published
+	r := httptest.NewRequest(http.MethodGet, uri, nil)
+	w := httptest.NewRecorder()
+	srv.GetJSONWebKeys(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"keys":[]}`, w.Body.String())
+	tconf.RSAKeys(t, srv.Config())
+	r = httptest.NewRequest(http.MethodGet, uri, nil)
+	w = httptest.NewRecorder()
+	srv.GetJSONWebKeys(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	set, err := jwk.Parse(w.Body.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, 1, set.Len())
+	key, ok := set.LookupKeyID(tconf.RSAKeyID)
+	require.True(t, ok)
+	assert.Equal(t, "RS256", key.Algorithm())
+	_, isPrivate := key.(jwk.RSAPrivateKey)
+	assert.False(t, isPrivate)
+}
+
+func TestWellKnownServer_GetOpenIDConfiguration(t *testing.T) {
+	t.Parallel()
+	s, _ := tsrv.RESTServer(t, false)
+	srv := newWellKnownServer(s)
+	c := srv.Config()
+	r := httptest.NewRequest(http.MethodGet, Endpoint+OpenIDConfiguration, nil)
+	w := httptest.NewRecorder()
+	srv.GetOpenIDConfiguration(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var d oidc.Discovery
+	err := json.Unmarshal(w.Body.Bytes(), &d)
+	require.NoError(t, err)
+	assert.Equal(t, c.OIDC.Issuer, d.Issuer)
+	assert.Equal(t, c.OIDC.Issuer+Endpoint+JWKS, d.JWKSURI)
+	assert.Equal(t, oidc.SupportedScopes, d.ScopesSupported)
+}
@@ -7,12 +7,15 @@ import (
 	"testing"
 	"time"
 
+	"github.com/jrapoport/gothic/config"
 	"github.com/jrapoport/gothic/core"
+	"github.com/jrapoport/gothic/core/context"
 	"github.com/jrapoport/gothic/core/tokens"
 	"github.com/jrapoport/gothic/hosts/rest"
 	"github.com/jrapoport/gothic/hosts/rest/account"
 	"github.com/jrapoport/gothic/hosts/rest/account/login"
 	"github.com/jrapoport/gothic/hosts/rest/user"
+	guser "github.com/jrapoport/gothic/models/user"
 	"github.com/jrapoport/gothic/test/tconf"
 	"github.com/jrapoport/gothic/test/tcore"
 	"github.com/jrapoport/gothic/test/thttp"
@@ -37,7 +40,7 @@ func TestRESTHost(t *testing.T) {
 		return h.Online()
 	}, 1*time.Second, 10*time.Millisecond)
 	// create a test user
-	const pass = "1234567890asdfghjkl"
+	const pass = "q8Ld!3xPz#w9Vk2m"
 	test, _ := tcore.TestUser(t, a, pass, false)
 	// unauthenticated call
 	loginURI := func() string {
@@ -79,3 +82,89 @@ func TestRESTHost(t *testing.T) {
 		return !h.Online()
 	}, 1*time.Second, 10*time.Millisecond)
 }
+
+func TestRESTHost_Tenants(t *testing.T) {
+	t.Parallel()
+	c := tconf.TempDB(t)
+	c.Signup.AutoConfirm = true
+	c.Security.MaskEmails = false
+	c.Tenants.Enabled = true
+	a, err := core.NewAPI(c)
+	require.NoError(t, err)
+	h := NewRESTHost(a, "127.0.0.1:0")
+	require.NotNil(t, h)
+	err = h.ListenAndServe()
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return h.Online()
+	}, 1*time.Second, 10*time.Millisecond)
+	t.Cleanup(func() {
+		err = h.Shutdown()
+		assert.NoError(t, err)
+	})
+	ctx := context.Background()
+	ctx.SetAdminID(guser.SuperAdminID)
+	const acmeHost = "acme.example.com"
+	tn, err := a.CreateTenant(ctx, "acme", acmeHost, config.TenantConfig{
+		JWT: config.TenantJWT{Secret: "acme-secret"},
+	})
+	require.NoError(t, err)
+	ta, err := a.TenantAPI(tn.ID)
+	require.NoError(t, err)
+	const pass = "q8Ld!3xPz#w9Vk2m"
+	test, _ := tcore.TestUser(t, ta, pass, false)
+	b, err := json.Marshal(&login.Request{
+		Email:    test.Email,
+		Password: pass,
+	})
+	require.NoError(t, err)
+	doLogin := func(path string, header http.Header, host string) (int, string) {
+		uri := "http://" + h.Address() + path + account.Account + login.Login
+		req, err := http.NewRequest(http.MethodPost, uri, bytes.NewBuffer(b))
+		require.NoError(t, err)
+		req.Header = header
+		req.Header.Set(rest.ContentType, rest.JSONContent)
+		if host != "" {
+			req.Host = host
+		}
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		body, err := ioutil.ReadAll(res.Body)
+		require.NoError(t, err)
+		err = res.Body.Close()
+		require.NoError(t, err)
+		return res.StatusCode, string(body)
+	}
+	// default tenant
+	code, _ := doLogin("", http.Header{}, "")
+	assert.NotEqual(t, http.StatusOK, code)
+	// unknown tenant
+	hdr := http.Header{}
+	hdr.Set(c.Tenants.Header, "missing")
+	code, _ = doLogin("", hdr, "")
+	assert.Equal(t, http.StatusNotFound, code)
+	code, _ = doLogin(c.Tenants.PathPrefix+"/missing", http.Header{}, "")
+	assert.Equal(t, http.StatusNotFound, code)
+	tc := core.NewServer(ta, "test").Config().JWT
+	tests := []struct {
+		path   string
+		header string
+		host   string
+	}{
+		{"", "acme", ""},
+		{"", tn.ID.String(), ""},
+		{c.Tenants.PathPrefix + "/acme", "", ""},
+		{"", "", acmeHost},
+	}
+	for _, test2 := range tests {
+		hdr = http.Header{}
+		if test2.header != "" {
+			hdr.Set(c.Tenants.Header, test2.header)
+		}
+		code, body := doLogin(test2.path, hdr, test2.host)
+		require.Equal(t, http.StatusOK, code)
+		ur, claims := tsrv.UnmarshalUserResponse(t, tc, body)
+		assert.EqualValues(t, tokens.Bearer, ur.Token.Type)
+		assert.Equal(t, test.ID.String(), claims.Subject())
+	}
+}
@@ -43,7 +43,7 @@ func (s *server) SendConfirmUser(ctx context.Context,
 }
 
 func (s *server) ConfirmUser(ctx context.Context,
-	req *account.ConfirmUserRequest) (*api.BearerResponse, error) {
+	req *account.ConfirmUserRequest) (*api.UserResponse, error) {
 	if req == nil {
 		err := errors.New("request not found")
 		return nil, s.RPCError(codes.InvalidArgument, err)
@@ -58,11 +58,6 @@ func (s *server) ConfirmUser(ctx context.Context,
 	if err != nil {
 		return nil, s.RPCError(codes.PermissionDenied, err)
 	}
-	bt, err := s.GrantBearerToken(rtx, u)
-	if err != nil {
-		return nil, s.RPCError(codes.PermissionDenied, err)
-	}
-	s.Debugf("password changed: %s", bt.UserID)
-	res := rpc.NewBearerResponse(bt)
-	return res, nil
+	s.Debugf("confirmed user: %s", u.ID)
+	return s.authorize(rtx, u)
 }
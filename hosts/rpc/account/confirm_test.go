@@ -122,7 +122,7 @@ func TestAccountServer_ConfirmUser(t *testing.T) {
 	u, err = srv.GetUser(u.ID)
 	assert.NoError(t, err)
 	assert.True(t, u.IsConfirmed())
-	claims, err := jwt.ParseUserClaims(srv.Config().JWT, res.Access)
+	claims, err := jwt.ParseUserClaims(srv.Config().JWT, res.Token.Access)
 	assert.NoError(t, err)
 	require.NotNil(t, claims)
 	assert.Equal(t, u.ID.String(), claims.Subject())
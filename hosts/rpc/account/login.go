@@ -6,7 +6,10 @@ import (
 
 	"github.com/jrapoport/gothic/api/grpc/rpc"
 	"github.com/jrapoport/gothic/api/grpc/rpc/account"
+	"github.com/jrapoport/gothic/config"
+	core_ctx "github.com/jrapoport/gothic/core/context"
 	"github.com/jrapoport/gothic/hosts/rpc"
+	"github.com/jrapoport/gothic/models/user"
 	"google.golang.org/grpc/codes"
 )
 
@@ -20,9 +23,18 @@ func (s *server) Login(ctx context.Context,
 	rtx.SetProvider(s.Provider())
 	s.Debugf("login user: %v (%v)", req, rtx)
 	u, err := s.API.Login(rtx, req.Email, req.Password)
+	if errors.Is(err, config.ErrRateLimitExceeded) {
+		return nil, s.RPCError(codes.DeadlineExceeded, err)
+	}
 	if err != nil {
 		return nil, s.RPCError(codes.PermissionDenied, err)
 	}
+	return s.authorize(rtx, u)
+}
+
+// authorize returns an mfa challenge if the user has enabled
+// two-factor authentication, otherwise it grants a bearer token.
+func (s *server) authorize(rtx core_ctx.Context, u *user.User) (*api.UserResponse, error) {
 	res, err := rpc.NewUserResponse(u)
 	if err != nil {
 		return nil, s.RPCError(codes.Internal, err)
@@ -30,6 +42,83 @@ func (s *server) Login(ctx context.Context,
 	if s.Config().MaskEmails {
 		res.MaskEmail()
 	}
+	mt, err := s.GrantMFAChallenge(rtx, u)
+	if errors.Is(err, config.ErrRateLimitExceeded) {
+		return nil, s.RPCError(codes.DeadlineExceeded, err)
+	}
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	if mt != nil {
+		res.Mfa = rpc.NewMFAResponse(mt)
+		s.Debugf("mfa challenge for user: %s", mt.UserID)
+		return (*api.UserResponse)(res), nil
+	}
+	return s.grantBearer(rtx, u, res)
+}
+
+func (s *server) VerifyMFA(ctx context.Context,
+	req *account.VerifyMFARequest) (*api.UserResponse, error) {
+	if req == nil {
+		err := errors.New("request not found")
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx := rpc.RequestContext(ctx)
+	rtx.SetProvider(s.Provider())
+	u, err := s.API.VerifyMFAChallenge(rtx, req.GetToken(), req.GetCode())
+	if errors.Is(err, config.ErrRateLimitExceeded) {
+		return nil, s.RPCError(codes.DeadlineExceeded, err)
+	}
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	s.Debugf("verified mfa for user: %s", u.ID)
+	res, err := rpc.NewUserResponse(u)
+	if err != nil {
+		return nil, s.RPCError(codes.Internal, err)
+	}
+	if s.Config().MaskEmails {
+		res.MaskEmail()
+	}
+	return s.grantBearer(rtx, u, res)
+}
+
+func (s *server) ChangeExpiredPassword(ctx context.Context,
+	req *account.ChangeExpiredPasswordRequest) (*api.UserResponse, error) {
+	if req == nil {
+		err := errors.New("request not found")
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx := rpc.RequestContext(ctx)
+	rtx.SetProvider(s.Provider())
+	s.Debugf("change expired password: %v", rtx)
+	u, err := s.API.ChangeExpiredPassword(rtx, req.GetToken(), req.GetPassword())
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	res, err := rpc.NewUserResponse(u)
+	if err != nil {
+		return nil, s.RPCError(codes.Internal, err)
+	}
+	if s.Config().MaskEmails {
+		res.MaskEmail()
+	}
+	return s.grantBearer(rtx, u, res)
+}
+
+// grantBearer returns a password change token if the user must change
+// their password, otherwise it grants a bearer token.
+func (s *server) grantBearer(rtx core_ctx.Context,
+	u *user.User, res *rpc.UserResponse) (*api.UserResponse, error) {
+	pt, err := s.GrantPasswordChange(rtx, u)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	if pt != nil {
+		res.Password = rpc.NewPasswordChangeResponse(pt)
+		s.Debugf("password change for user: %s", pt.UserID)
+		return (*api.UserResponse)(res), nil
+	}
 	bt, err := s.GrantBearerToken(rtx, u)
 	if err != nil {
 		return nil, s.RPCError(codes.PermissionDenied, err)
@@ -3,13 +3,21 @@ package account
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/jrapoport/gothic/api/grpc/rpc/account"
+	"github.com/jrapoport/gothic/config"
+	core_ctx "github.com/jrapoport/gothic/core/context"
 	"github.com/jrapoport/gothic/core/tokens"
 	"github.com/jrapoport/gothic/jwt"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tcore"
 	"github.com/jrapoport/gothic/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestAccountServer_Login(t *testing.T) {
@@ -57,3 +65,132 @@ func TestAccountServer_Login(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, u.ID, au.ID)
 }
+
+func TestAccountServer_Login_Lockout(t *testing.T) {
+	t.Parallel()
+	srv := testServer(t)
+	srv.Config().Signup.AutoConfirm = true
+	srv.Config().Lockout.Delay = time.Minute
+	u, _ := tcore.TestUser(t, srv.API, testPass, false)
+	ctx := context.Background()
+	req := &account.LoginRequest{
+		Email:    u.Email,
+		Password: "bad",
+	}
+	_, err := srv.Login(ctx, req)
+	assert.Error(t, err)
+	// too early
+	req.Password = testPass
+	_, err = srv.Login(ctx, req)
+	test := srv.RPCError(codes.DeadlineExceeded,
+		config.ErrRateLimitExceeded)
+	require.NotNil(t, test)
+	assert.EqualError(t, err, test.Error())
+}
+
+func TestAccountServer_Login_MFA(t *testing.T) {
+	t.Parallel()
+	srv := testServer(t)
+	srv.Config().Signup.AutoConfirm = true
+	u, _ := tcore.TestUser(t, srv.API, testPass, false)
+	secret := tcore.EnrollTOTP(t, srv.API, u)
+	ctx := context.Background()
+	res, err := srv.Login(ctx, &account.LoginRequest{
+		Email:    u.Email,
+		Password: testPass,
+	})
+	require.NoError(t, err)
+	assert.Nil(t, res.Token)
+	require.NotNil(t, res.Mfa)
+	assert.EqualValues(t, token.MFA, res.Mfa.Type)
+	assert.NotEmpty(t, res.Mfa.Token)
+	assert.NotNil(t, res.Mfa.ExpiresAt)
+	// invalid req
+	_, err = srv.VerifyMFA(ctx, nil)
+	assert.Error(t, err)
+	// bad token
+	_, err = srv.VerifyMFA(ctx, &account.VerifyMFARequest{
+		Token: "bad",
+		Code:  tcore.TOTPCode(t, secret, 1),
+	})
+	assert.Error(t, err)
+	// bad code
+	_, err = srv.VerifyMFA(ctx, &account.VerifyMFARequest{
+		Token: res.Mfa.Token,
+		Code:  "bad",
+	})
+	assert.Error(t, err)
+	req := &account.VerifyMFARequest{
+		Token: res.Mfa.Token,
+		Code:  tcore.TOTPCode(t, secret, 1),
+	}
+	res, err = srv.VerifyMFA(ctx, req)
+	require.NoError(t, err)
+	assert.Nil(t, res.Mfa)
+	claims, err := jwt.ParseUserClaims(srv.Config().JWT, res.Token.Access)
+	require.NoError(t, err)
+	assert.EqualValues(t, tokens.Bearer, res.Token.Type)
+	assert.Equal(t, u.ID.String(), claims.Subject())
+	// challenges cannot be reused
+	_, err = srv.VerifyMFA(ctx, req)
+	assert.Error(t, err)
+}
+
+func TestAccountServer_Login_PasswordChange(t *testing.T) {
+	t.Parallel()
+	const newPass = "q8Ld!3xPz#w9Vk2m"
+	srv := testServer(t)
+	srv.Config().Signup.AutoConfirm = true
+	u, _ := tcore.TestUser(t, srv.API, testPass, false)
+	actx := core_ctx.Background()
+	actx.SetAdminID(user.SuperAdminID)
+	_, err := srv.ForcePasswordChange(actx, u.ID)
+	require.NoError(t, err)
+	ctx := context.Background()
+	req := &account.LoginRequest{
+		Email:    u.Email,
+		Password: testPass,
+	}
+	res, err := srv.Login(ctx, req)
+	require.NoError(t, err)
+	assert.Nil(t, res.Token)
+	require.NotNil(t, res.Password)
+	assert.NotEmpty(t, res.Password.Token)
+	assert.NotNil(t, res.Password.ExpiresAt)
+	// invalid req
+	_, err = srv.ChangeExpiredPassword(ctx, nil)
+	assert.Error(t, err)
+	// bad token
+	_, err = srv.ChangeExpiredPassword(ctx, &account.ChangeExpiredPasswordRequest{
+		Token:    "bad",
+		Password: newPass,
+	})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	// bad password
+	_, err = srv.ChangeExpiredPassword(ctx, &account.ChangeExpiredPasswordRequest{
+		Token:    res.Password.Token,
+		Password: "weak",
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	preq := &account.ChangeExpiredPasswordRequest{
+		Token:    res.Password.Token,
+		Password: newPass,
+	}
+	res, err = srv.ChangeExpiredPassword(ctx, preq)
+	require.NoError(t, err)
+	assert.Nil(t, res.Password)
+	claims, err := jwt.ParseUserClaims(srv.Config().JWT, res.Token.Access)
+	require.NoError(t, err)
+	assert.EqualValues(t, tokens.Bearer, res.Token.Type)
+	assert.Equal(t, u.ID.String(), claims.Subject())
+	// tokens cannot be reused
+	_, err = srv.ChangeExpiredPassword(ctx, preq)
+	assert.Error(t, err)
+	// expired passwords
+	srv.Config().Password.MaxAge = time.Nanosecond
+	req.Password = newPass
+	res, err = srv.Login(ctx, req)
+	require.NoError(t, err)
+	assert.Nil(t, res.Token)
+	assert.NotNil(t, res.Password)
+}
@@ -0,0 +1,56 @@
+package account
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jrapoport/gothic/api/grpc/rpc"
+	"github.com/jrapoport/gothic/api/grpc/rpc/account"
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/hosts/rpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func (s *server) SendMagicLink(ctx context.Context,
+	req *account.MagicLinkRequest) (*emptypb.Empty, error) {
+	if req == nil {
+		err := errors.New("request not found")
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	if req.Email == "" {
+		err := errors.New("email not found")
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx := rpc.RequestContext(ctx)
+	rtx.SetProvider(s.Provider())
+	s.Debugf("send magic link: %s", req.Email)
+	err := s.API.SendMagicLink(rtx, req.Email)
+	if errors.Is(err, config.ErrRateLimitExceeded) {
+		return nil, s.RPCError(codes.DeadlineExceeded, err)
+	}
+	if err != nil {
+		return nil, s.RPCError(codes.Internal, err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *server) ConfirmMagicLink(ctx context.Context,
+	req *account.ConfirmMagicLinkRequest) (*api.UserResponse, error) {
+	if req == nil {
+		err := errors.New("request not found")
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	if req.Token == "" {
+		err := errors.New("token not found")
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx := rpc.RequestContext(ctx)
+	rtx.SetProvider(s.Provider())
+	s.Debugf("confirm magic link: %s", req.Token)
+	u, err := s.API.ConfirmMagicLink(rtx, req.Token)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	return s.authorize(rtx, u)
+}
@@ -0,0 +1,83 @@
+package account
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jrapoport/gothic/api/grpc/rpc/account"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/jwt"
+	"github.com/jrapoport/gothic/mail/template"
+	"github.com/jrapoport/gothic/test/tconf"
+	"github.com/jrapoport/gothic/test/tsrv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAccountServer_MagicLink(t *testing.T) {
+	t.Parallel()
+	s, smtp := tsrv.RPCServer(t, true)
+	srv := newServer(s)
+	srv.Config().Signup.AutoConfirm = true
+	srv.Config().Mail.SendLimit = 0
+	ctx := context.Background()
+	// invalid req
+	_, err := srv.SendMagicLink(ctx, nil)
+	assert.Error(t, err)
+	_, err = srv.ConfirmMagicLink(ctx, nil)
+	assert.Error(t, err)
+	// empty email
+	req := &account.MagicLinkRequest{}
+	_, err = srv.SendMagicLink(ctx, req)
+	assert.Error(t, err)
+	// not found
+	req.Email = "i-dont-exist@example.com"
+	_, err = srv.SendMagicLink(ctx, req)
+	assert.Error(t, err)
+	var tok string
+	var mu sync.Mutex
+	smtp.AddHook(t, func(email string) {
+		mu.Lock()
+		defer mu.Unlock()
+		tok = tconf.GetEmailToken(template.MagicLinkAction, email)
+	})
+	u := testUser(t, srv)
+	req.Email = u.Email
+	_, err = srv.SendMagicLink(ctx, req)
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return tok != ""
+	}, 1*time.Second, 10*time.Millisecond)
+	// rate limit
+	srv.Config().Mail.SendLimit = 5 * time.Minute
+	_, err = srv.SendMagicLink(ctx, req)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	// empty token
+	creq := &account.ConfirmMagicLinkRequest{}
+	_, err = srv.ConfirmMagicLink(ctx, creq)
+	assert.Error(t, err)
+	// bad token
+	creq.Token = "bad"
+	_, err = srv.ConfirmMagicLink(ctx, creq)
+	assert.Error(t, err)
+	// logged in
+	mu.Lock()
+	creq.Token = tok
+	mu.Unlock()
+	res, err := srv.ConfirmMagicLink(ctx, creq)
+	require.NoError(t, err)
+	require.NotNil(t, res.Token)
+	assert.EqualValues(t, tokens.Bearer, res.Token.Type)
+	claims, err := jwt.ParseUserClaims(srv.Config().JWT, res.Token.Access)
+	require.NoError(t, err)
+	assert.Equal(t, u.ID.String(), claims.Subject())
+	// links cannot be reused
+	_, err = srv.ConfirmMagicLink(ctx, creq)
+	assert.Error(t, err)
+}
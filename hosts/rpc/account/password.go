@@ -44,7 +44,7 @@ func (s *server) SendResetPassword(ctx context.Context,
 }
 
 func (s *server) ConfirmResetPassword(ctx context.Context,
-	req *account.ConfirmPasswordRequest) (*api.BearerResponse, error) {
+	req *account.ConfirmPasswordRequest) (*api.UserResponse, error) {
 	if req == nil {
 		err := errors.New("request not found")
 		return nil, s.RPCError(codes.InvalidArgument, err)
@@ -63,11 +63,6 @@ func (s *server) ConfirmResetPassword(ctx context.Context,
 	if err != nil {
 		return nil, s.RPCError(codes.PermissionDenied, err)
 	}
-	bt, err := s.GrantBearerToken(rtx, u)
-	if err != nil {
-		return nil, s.RPCError(codes.PermissionDenied, err)
-	}
-	s.Debugf("password changed: %s", bt.UserID)
-	res := rpc.NewBearerResponse(bt)
-	return res, nil
+	s.Debugf("password changed: %s", u.ID)
+	return s.authorize(rtx, u)
 }
@@ -11,6 +11,7 @@ import (
 	"github.com/jrapoport/gothic/jwt"
 	"github.com/jrapoport/gothic/mail/template"
 	"github.com/jrapoport/gothic/test/tconf"
+	"github.com/jrapoport/gothic/test/tcore"
 	"github.com/jrapoport/gothic/test/tsrv"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -144,7 +145,7 @@ func TestAccountServer_ConfirmResetPassword(t *testing.T) {
 	u, err = srv.GetUser(u.ID)
 	assert.NoError(t, err)
 	assert.True(t, u.IsConfirmed())
-	claims, err := jwt.ParseUserClaims(srv.Config().JWT, res.Access)
+	claims, err := jwt.ParseUserClaims(srv.Config().JWT, res.Token.Access)
 	assert.NoError(t, err)
 	require.NotNil(t, claims)
 	assert.Equal(t, u.ID.String(), claims.Subject())
@@ -153,3 +154,43 @@ func TestAccountServer_ConfirmResetPassword(t *testing.T) {
 	err = u.Authenticate(newPass)
 	assert.NoError(t, err)
 }
+
+func TestAccountServer_ConfirmResetPassword_MFA(t *testing.T) {
+	t.Parallel()
+	const newPass = "sxjAm7QJ4?3dH!aN8T3F5P!oNnpXbaRy#gtx#8jG"
+	s, smtp := tsrv.RPCServer(t, true)
+	srv := newServer(s)
+	srv.Config().Signup.AutoConfirm = true
+	ctx := context.Background()
+	u, _ := tcore.TestUser(t, srv.API, testPass, false)
+	secret := tcore.EnrollTOTP(t, srv.API, u)
+	var tok string
+	act := template.ResetPasswordAction
+	smtp.AddHook(t, func(email string) {
+		tok = tconf.GetEmailToken(act, email)
+	})
+	_, err := srv.SendResetPassword(ctx, &account.ResetPasswordRequest{
+		Email: u.Email,
+	})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return tok != ""
+	}, 1*time.Second, 10*time.Millisecond)
+	// a reset password requires the second factor
+	res, err := srv.ConfirmResetPassword(ctx, &account.ConfirmPasswordRequest{
+		Token:    tok,
+		Password: newPass,
+	})
+	require.NoError(t, err)
+	assert.Nil(t, res.Token)
+	require.NotNil(t, res.Mfa)
+	res, err = srv.VerifyMFA(ctx, &account.VerifyMFARequest{
+		Token: res.Mfa.Token,
+		Code:  tcore.TOTPCode(t, secret, 1),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, res.Token)
+	claims, err := jwt.ParseUserClaims(srv.Config().JWT, res.Token.Access)
+	require.NoError(t, err)
+	assert.Equal(t, u.ID.String(), claims.Subject())
+}
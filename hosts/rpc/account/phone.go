@@ -0,0 +1,59 @@
+package account
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jrapoport/gothic/api/grpc/rpc"
+	"github.com/jrapoport/gothic/api/grpc/rpc/account"
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/hosts/rpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func (s *server) SendPhoneLogin(ctx context.Context,
+	req *account.PhoneLoginRequest) (*emptypb.Empty, error) {
+	if req == nil {
+		err := errors.New("request not found")
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	if req.Phone == "" {
+		err := errors.New("phone not found")
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx := rpc.RequestContext(ctx)
+	rtx.SetProvider(s.Provider())
+	s.Debugf("send phone login: %s", req.Phone)
+	err := s.API.SendPhoneLogin(rtx, req.Phone)
+	if errors.Is(err, config.ErrRateLimitExceeded) {
+		return nil, s.RPCError(codes.DeadlineExceeded, err)
+	}
+	if err != nil {
+		return nil, s.RPCError(codes.Internal, err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *server) PhoneLogin(ctx context.Context,
+	req *account.ConfirmPhoneLoginRequest) (*api.UserResponse, error) {
+	if req == nil {
+		err := errors.New("request not found")
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	if req.Phone == "" || req.Code == "" {
+		err := errors.New("phone or code not found")
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx := rpc.RequestContext(ctx)
+	rtx.SetProvider(s.Provider())
+	s.Debugf("phone login: %s", req.Phone)
+	u, err := s.API.PhoneLogin(rtx, req.Phone, req.Code)
+	if errors.Is(err, config.ErrRateLimitExceeded) {
+		return nil, s.RPCError(codes.DeadlineExceeded, err)
+	}
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	return s.authorize(rtx, u)
+}
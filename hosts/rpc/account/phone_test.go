@@ -0,0 +1,77 @@
+package account
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jrapoport/gothic/api/grpc/rpc/account"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/jwt"
+	"github.com/jrapoport/gothic/test/tconf"
+	"github.com/jrapoport/gothic/test/tcore"
+	"github.com/jrapoport/gothic/test/tutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAccountServer_PhoneLogin(t *testing.T) {
+	t.Parallel()
+	srv := testServer(t)
+	srv.Config().Signup.AutoConfirm = true
+	path := tcore.MockSMS(t, srv.API, srv.Config())
+	srv.Config().SMS.SendLimit = 0
+	ctx := context.Background()
+	// invalid req
+	_, err := srv.SendPhoneLogin(ctx, nil)
+	assert.Error(t, err)
+	_, err = srv.PhoneLogin(ctx, nil)
+	assert.Error(t, err)
+	// empty phone
+	req := &account.PhoneLoginRequest{}
+	_, err = srv.SendPhoneLogin(ctx, req)
+	assert.Error(t, err)
+	u := testUser(t, srv)
+	phone := tcore.ConfirmPhone(t, srv.API, path, u)
+	req.Phone = phone
+	// phone login disabled
+	_, err = srv.SendPhoneLogin(ctx, req)
+	assert.Error(t, err)
+	srv.Config().SMS.Login = true
+	// not found
+	req.Phone = tutils.RandomPhone()
+	_, err = srv.SendPhoneLogin(ctx, req)
+	assert.Error(t, err)
+	req.Phone = phone
+	_, err = srv.SendPhoneLogin(ctx, req)
+	require.NoError(t, err)
+	code := tconf.GetSMSCode(t, path, phone)
+	require.NotEmpty(t, code)
+	// rate limit
+	srv.Config().SMS.SendLimit = 5 * time.Minute
+	_, err = srv.SendPhoneLogin(ctx, req)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	// empty code
+	creq := &account.ConfirmPhoneLoginRequest{Phone: phone}
+	_, err = srv.PhoneLogin(ctx, creq)
+	assert.Error(t, err)
+	// bad code
+	creq.Code = "bad"
+	_, err = srv.PhoneLogin(ctx, creq)
+	assert.Error(t, err)
+	// logged in
+	creq.Code = code
+	res, err := srv.PhoneLogin(ctx, creq)
+	require.NoError(t, err)
+	require.NotNil(t, res.Token)
+	assert.EqualValues(t, tokens.Bearer, res.Token.Type)
+	assert.Equal(t, phone, res.Phone)
+	claims, err := jwt.ParseUserClaims(srv.Config().JWT, res.Token.Access)
+	require.NoError(t, err)
+	assert.Equal(t, u.ID.String(), claims.Subject())
+	// codes cannot be reused
+	_, err = srv.PhoneLogin(ctx, creq)
+	assert.Error(t, err)
+}
@@ -25,13 +25,13 @@ func newServer(srv *rpc.Server) *server {
 }
 
 // RegisterServer registers a new admin server.
-func RegisterServer(s *grpc.Server, srv *rpc.Server) {
+func RegisterServer(s grpc.ServiceRegistrar, srv *rpc.Server) {
 	account.RegisterAccountServer(s, newServer(srv))
 }
 
 func (s *server) AuthFuncOverride(ctx context.Context, _ string) (context.Context, error) {
 	// we purposely ignore the error here so we'll parse a token
 	// if passed in on the call, but not require it to be there.
-	ctx, _ = rpc.Authenticate(ctx, s.Config().JWT)
+	ctx, _ = rpc.Authenticate(ctx, s.Config().JWT, s.API.ValidateBearerToken, nil)
 	return ctx, nil
 }
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/jrapoport/gothic/api/grpc/rpc/account"
+	"github.com/jrapoport/gothic/config"
 	"github.com/jrapoport/gothic/core/context"
 	"github.com/jrapoport/gothic/core/validate"
 	"github.com/jrapoport/gothic/hosts/rpc"
@@ -14,6 +15,9 @@ import (
 	"github.com/jrapoport/gothic/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
@@ -196,6 +200,7 @@ func TestAccountServer_Signup_Password(t *testing.T) {
 	_, err = srv.Signup(ctx, req)
 	assert.Error(t, err)
 	srv.Config().Validation.PasswordRegex = ""
+	srv.Config().Password = config.Password{}
 	// blank  ok
 	req, _ = testCase(t)
 	req.Password = ""
@@ -210,4 +215,25 @@ func TestAccountServer_Signup_Password(t *testing.T) {
 	req.Password = "password"
 	_, err = srv.Signup(ctx, req)
 	assert.NoError(t, err)
+	// password policy
+	srv.Config().Validation.PasswordRegex = ""
+	srv.Config().Password = config.Password{
+		MinLength: 10,
+		Digit:     true,
+	}
+	req, _ = testCase(t)
+	req.Password = "password"
+	_, err = srv.Signup(ctx, req)
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	require.Len(t, st.Details(), 1)
+	br, ok := st.Details()[0].(*errdetails.BadRequest)
+	require.True(t, ok)
+	var violations []string
+	for _, fv := range br.GetFieldViolations() {
+		assert.Equal(t, "password", fv.GetField())
+		violations = append(violations, fv.GetDescription())
+	}
+	assert.Equal(t, []string{"too_short", "missing_digit"}, violations)
 }
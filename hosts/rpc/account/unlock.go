@@ -0,0 +1,31 @@
+package account
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jrapoport/gothic/api/grpc/rpc/account"
+	"github.com/jrapoport/gothic/hosts/rpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func (s *server) ConfirmUnlock(ctx context.Context,
+	req *account.ConfirmUnlockRequest) (*emptypb.Empty, error) {
+	if req == nil {
+		err := errors.New("request not found")
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	if req.Token == "" {
+		err := errors.New("token not found")
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	s.Debugf("unlock user: %v", req)
+	rtx := rpc.RequestContext(ctx)
+	err := s.API.ConfirmUnlock(rtx, req.Token)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	s.Debugf("unlocked user")
+	return &emptypb.Empty{}, nil
+}
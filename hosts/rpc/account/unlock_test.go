@@ -0,0 +1,60 @@
+package account
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jrapoport/gothic/api/grpc/rpc/account"
+	"github.com/jrapoport/gothic/mail/template"
+	"github.com/jrapoport/gothic/test/tconf"
+	"github.com/jrapoport/gothic/test/tsrv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountServer_ConfirmUnlock(t *testing.T) {
+	t.Parallel()
+	s, smtp := tsrv.RPCServer(t, true)
+	srv := newServer(s)
+	srv.Config().Signup.AutoConfirm = true
+	srv.Config().Lockout.Attempts = 1
+	ctx := context.Background()
+	// invalid req
+	_, err := srv.ConfirmUnlock(ctx, nil)
+	assert.Error(t, err)
+	// empty token
+	req := &account.ConfirmUnlockRequest{}
+	_, err = srv.ConfirmUnlock(ctx, req)
+	assert.Error(t, err)
+	// bad token
+	req.Token = "bad"
+	_, err = srv.ConfirmUnlock(ctx, req)
+	assert.Error(t, err)
+	// success
+	var tok string
+	var mu sync.Mutex
+	smtp.AddHook(t, func(email string) {
+		mu.Lock()
+		defer mu.Unlock()
+		tok = tconf.GetEmailToken(template.UnlockUserAction, email)
+	})
+	u := testUser(t, srv)
+	_, err = srv.API.Login(nil, u.Email, "bad")
+	assert.Error(t, err)
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return tok != ""
+	}, 1*time.Second, 10*time.Millisecond)
+	req.Token = tok
+	_, err = srv.ConfirmUnlock(ctx, req)
+	assert.NoError(t, err)
+	u, err = srv.GetUser(u.ID)
+	require.NoError(t, err)
+	assert.False(t, u.IsLocked())
+	// used token
+	_, err = srv.ConfirmUnlock(ctx, req)
+	assert.Error(t, err)
+}
@@ -0,0 +1,58 @@
+package account
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jrapoport/gothic/api/grpc/rpc"
+	"github.com/jrapoport/gothic/api/grpc/rpc/account"
+	"github.com/jrapoport/gothic/hosts/rpc"
+	"google.golang.org/grpc/codes"
+)
+
+func (s *server) BeginWebAuthnLogin(ctx context.Context,
+	req *account.BeginWebAuthnLoginRequest) (*api.WebAuthnResponse, error) {
+	if req == nil {
+		err := errors.New("request not found")
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx := rpc.RequestContext(ctx)
+	rtx.SetProvider(s.Provider())
+	s.Debugf("begin webauthn login: %v (%v)", req, rtx)
+	c, err := s.API.BeginWebAuthnLogin(rtx, req.GetEmail())
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	return rpc.NewWebAuthnResponse(c), nil
+}
+
+func (s *server) FinishWebAuthnLogin(ctx context.Context,
+	req *account.FinishWebAuthnLoginRequest) (*api.BearerResponse, error) {
+	if req == nil {
+		err := errors.New("request not found")
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx := rpc.RequestContext(ctx)
+	rtx.SetProvider(s.Provider())
+	s.Debugf("finish webauthn login: %v", rtx)
+	u, err := s.API.FinishWebAuthnLogin(rtx, req.GetToken(), []byte(req.GetResponse()))
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	// a bearer response cannot hold a password change token,
+	// so the user must login with their password to change it.
+	pt, err := s.GrantPasswordChange(rtx, u)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	if pt != nil {
+		err = errors.New("password change required")
+		return nil, s.RPCError(codes.FailedPrecondition, err)
+	}
+	bt, err := s.GrantBearerToken(rtx, u)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	s.Debugf("webauthn login: %s", u.ID)
+	return rpc.NewBearerResponse(bt), nil
+}
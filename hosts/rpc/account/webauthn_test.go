@@ -0,0 +1,60 @@
+package account
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jrapoport/gothic/api/grpc/rpc/account"
+	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/jwt"
+	"github.com/jrapoport/gothic/test/tcore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountServer_WebAuthnLogin(t *testing.T) {
+	t.Parallel()
+	srv := testServer(t)
+	srv.Config().Signup.AutoConfirm = true
+	u, _ := tcore.TestUser(t, srv.API, testPass, false)
+	ctx := context.Background()
+	// invalid req
+	_, err := srv.BeginWebAuthnLogin(ctx, nil)
+	assert.Error(t, err)
+	_, err = srv.FinishWebAuthnLogin(ctx, nil)
+	assert.Error(t, err)
+	// no credentials
+	_, err = srv.BeginWebAuthnLogin(ctx, &account.BeginWebAuthnLoginRequest{
+		Email: u.Email,
+	})
+	assert.Error(t, err)
+	auth := tcore.RegisterWebAuthn(t, srv.API, srv.Config(), u)
+	for _, email := range []string{u.Email, ""} {
+		res, err := srv.BeginWebAuthnLogin(ctx, &account.BeginWebAuthnLoginRequest{
+			Email: email,
+		})
+		require.NoError(t, err)
+		assert.NotEmpty(t, res.GetToken())
+		assert.NotNil(t, res.GetExpiresAt())
+		assertion := auth.AssertionResponse(t, []byte(res.GetOptions()))
+		// bad token
+		_, err = srv.FinishWebAuthnLogin(ctx, &account.FinishWebAuthnLoginRequest{
+			Token:    "bad",
+			Response: string(assertion),
+		})
+		assert.Error(t, err)
+		req := &account.FinishWebAuthnLoginRequest{
+			Token:    res.GetToken(),
+			Response: string(assertion),
+		}
+		bt, err := srv.FinishWebAuthnLogin(ctx, req)
+		require.NoError(t, err)
+		assert.EqualValues(t, tokens.Bearer, bt.Type)
+		claims, err := jwt.ParseUserClaims(srv.Config().JWT, bt.Access)
+		require.NoError(t, err)
+		assert.Equal(t, u.ID.String(), claims.Subject())
+		// ceremonies cannot be reused
+		_, err = srv.FinishWebAuthnLogin(ctx, req)
+		assert.Error(t, err)
+	}
+}
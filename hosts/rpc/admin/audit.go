@@ -7,6 +7,7 @@ import (
 	"github.com/jrapoport/gothic/api/grpc/rpc/admin"
 	"github.com/jrapoport/gothic/hosts/rpc"
 	"github.com/jrapoport/gothic/models/auditlog"
+	"github.com/jrapoport/gothic/models/rbac"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/protobuf/types/known/structpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -17,7 +18,7 @@ func (s *server) SearchAuditLogs(ctx context.Context,
 	if req == nil {
 		return nil, s.RPCError(codes.InvalidArgument, nil)
 	}
-	rtx, err := s.adminRequestContext(ctx)
+	rtx, err := s.adminRequestContext(ctx, rbac.AuditRead)
 	if err != nil {
 		return nil, s.RPCError(codes.PermissionDenied, err)
 	}
@@ -0,0 +1,84 @@
+package admin
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jrapoport/gothic/api/grpc/rpc/admin"
+	"github.com/jrapoport/gothic/models/client"
+	"github.com/jrapoport/gothic/models/rbac"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *server) CreateClient(ctx context.Context,
+	req *admin.CreateClientRequest) (*admin.ClientResponse, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	rtx, err := s.adminRequestContext(ctx, rbac.ClientsWrite)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	c, secret, err := s.API.CreateClient(rtx, req.GetName(),
+		req.GetRedirectUris(), req.GetScopes(), req.GetConfidential())
+	if err != nil {
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	s.Debugf("created client %s: %s", c.ClientID, c.Name)
+	res := newClientResponse(c)
+	res.ClientSecret = secret
+	return res, nil
+}
+
+func (s *server) ListClients(ctx context.Context,
+	_ *emptypb.Empty) (*admin.ClientsResponse, error) {
+	rtx, err := s.adminRequestContext(ctx, rbac.ClientsRead)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	clients, err := s.API.GetClients(rtx)
+	if err != nil {
+		return nil, s.RPCError(codes.Internal, err)
+	}
+	res := &admin.ClientsResponse{
+		Clients: make([]*admin.ClientResponse, len(clients)),
+	}
+	for i, c := range clients {
+		res.Clients[i] = newClientResponse(c)
+	}
+	return res, nil
+}
+
+func (s *server) DeleteClient(ctx context.Context,
+	req *admin.DeleteClientRequest) (*emptypb.Empty, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	if req.GetClientId() == "" {
+		err := errors.New("client id required")
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx, err := s.adminRequestContext(ctx, rbac.ClientsWrite)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	err = s.API.DeleteClient(rtx, req.GetClientId())
+	if err != nil {
+		return nil, s.RPCError(codes.NotFound, err)
+	}
+	s.Debugf("deleted client %s", req.GetClientId())
+	return &emptypb.Empty{}, nil
+}
+
+func newClientResponse(c *client.Client) *admin.ClientResponse {
+	return &admin.ClientResponse{
+		ClientId:     c.ClientID,
+		Name:         c.Name,
+		RedirectUris: c.RedirectURIList(),
+		Scopes:       c.ScopeList(),
+		Confidential: c.Confidential(),
+		CreatedAt:    timestamppb.New(c.CreatedAt),
+	}
+}
@@ -0,0 +1,68 @@
+package admin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jrapoport/gothic/api/grpc/rpc/admin"
+	"github.com/jrapoport/gothic/core/oidc"
+	"github.com/jrapoport/gothic/hosts/rpc"
+	"github.com/jrapoport/gothic/test/tsrv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestAdminServer_Clients(t *testing.T) {
+	t.Parallel()
+	s, _ := tsrv.RPCServer(t, false)
+	srv := newAdminServer(s)
+	ctx := rootContext(srv.Config())
+	// nil request
+	_, err := srv.CreateClient(ctx, nil)
+	assert.Error(t, err)
+	_, err = srv.DeleteClient(ctx, nil)
+	assert.Error(t, err)
+	// bad root password
+	bad := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(rpc.RootPassword, "bad"))
+	req := &admin.CreateClientRequest{
+		Name:         "test",
+		RedirectUris: []string{"https://client.example.com/callback"},
+		Confidential: true,
+	}
+	_, err = srv.CreateClient(bad, req)
+	assert.Error(t, err)
+	_, err = srv.ListClients(bad, &emptypb.Empty{})
+	assert.Error(t, err)
+	// bad redirect uri
+	_, err = srv.CreateClient(ctx, &admin.CreateClientRequest{Name: "test"})
+	assert.Error(t, err)
+	res, err := srv.CreateClient(ctx, req)
+	require.NoError(t, err)
+	assert.NotEmpty(t, res.GetClientId())
+	assert.NotEmpty(t, res.GetClientSecret())
+	assert.True(t, res.GetConfidential())
+	assert.Equal(t, oidc.SupportedScopes, res.GetScopes())
+	list, err := srv.ListClients(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	require.Len(t, list.GetClients(), 1)
+	assert.Equal(t, res.GetClientId(), list.GetClients()[0].GetClientId())
+	assert.Empty(t, list.GetClients()[0].GetClientSecret())
+	// bad client id
+	dreq := &admin.DeleteClientRequest{}
+	_, err = srv.DeleteClient(ctx, dreq)
+	assert.Error(t, err)
+	dreq.ClientId = "bad"
+	_, err = srv.DeleteClient(ctx, dreq)
+	assert.Error(t, err)
+	dreq.ClientId = res.GetClientId()
+	_, err = srv.DeleteClient(bad, dreq)
+	assert.Error(t, err)
+	_, err = srv.DeleteClient(ctx, dreq)
+	assert.NoError(t, err)
+	list, err = srv.ListClients(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	assert.Empty(t, list.GetClients())
+}
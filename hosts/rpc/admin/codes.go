@@ -6,6 +6,7 @@ import (
 
 	"github.com/jrapoport/gothic/api/grpc/rpc/admin"
 	"github.com/jrapoport/gothic/models/code"
+	"github.com/jrapoport/gothic/models/rbac"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/emptypb"
@@ -17,7 +18,7 @@ func (s *server) CreateSignupCodes(ctx context.Context,
 	if req == nil {
 		return nil, s.RPCError(codes.InvalidArgument, nil)
 	}
-	rtx, err := s.adminRequestContext(ctx)
+	rtx, err := s.adminRequestContext(ctx, rbac.CodesCreate)
 	if err != nil {
 		return nil, s.RPCError(codes.PermissionDenied, err)
 	}
@@ -43,7 +44,7 @@ func (s *server) CheckSignupCode(ctx context.Context,
 	if req == nil {
 		return nil, s.RPCError(codes.InvalidArgument, nil)
 	}
-	_, err := s.adminRequestContext(ctx)
+	_, err := s.adminRequestContext(ctx, rbac.CodesRead)
 	if err != nil {
 		return nil, s.RPCError(codes.PermissionDenied, err)
 	}
@@ -76,7 +77,7 @@ func (s *server) DeleteSignupCode(ctx context.Context, req *admin.DeleteSignupCo
 	if req == nil {
 		return nil, s.RPCError(codes.InvalidArgument, nil)
 	}
-	_, err := s.adminRequestContext(ctx)
+	_, err := s.adminRequestContext(ctx, rbac.CodesDelete)
 	if err != nil {
 		return nil, s.RPCError(codes.PermissionDenied, err)
 	}
@@ -0,0 +1,95 @@
+package admin
+
+import (
+	"errors"
+	"io"
+
+	"github.com/jrapoport/gothic/api/grpc/rpc/admin"
+	"github.com/jrapoport/gothic/core/users"
+	"github.com/jrapoport/gothic/hasher"
+	"github.com/jrapoport/gothic/models/rbac"
+	"google.golang.org/grpc/codes"
+)
+
+func (s *server) ImportUsers(stream admin.Admin_ImportUsersServer) error {
+	rtx, err := s.adminRequestContext(stream.Context(), rbac.UsersCreate)
+	if err != nil {
+		return s.RPCError(codes.PermissionDenied, err)
+	}
+	imp := &users.Import{}
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return s.RPCError(codes.Internal, err)
+		}
+		switch req.GetRequest().(type) {
+		case *admin.ImportUsersRequest_Options:
+			imp.Firebase = firebaseParams(req.GetOptions().GetFirebase())
+		case *admin.ImportUsersRequest_User:
+			imp.Users = append(imp.Users, importRecord(req.GetUser()))
+		default:
+			err = errors.New("invalid import request")
+			return s.RPCError(codes.InvalidArgument, err)
+		}
+	}
+	if len(imp.Users) <= 0 {
+		err = errors.New("users required")
+		return s.RPCError(codes.InvalidArgument, err)
+	}
+	res, err := s.API.ImportUsers(rtx, imp)
+	if err != nil {
+		return s.RPCError(codes.Internal, err)
+	}
+	s.Debugf("imported users: %d (failed: %d)", res.Imported, res.Failed)
+	return stream.SendAndClose(importResponse(res))
+}
+
+func firebaseParams(fb *admin.FirebaseScrypt) *hasher.FirebaseParams {
+	if fb == nil {
+		return nil
+	}
+	return &hasher.FirebaseParams{
+		SignerKey:     fb.GetSignerKey(),
+		SaltSeparator: fb.GetSaltSeparator(),
+		Rounds:        int(fb.GetRounds()),
+		MemCost:       int(fb.GetMemCost()),
+	}
+}
+
+func importRecord(u *admin.ImportUser) users.ImportRecord {
+	rec := users.ImportRecord{
+		Email:     u.GetEmail(),
+		Username:  u.GetUsername(),
+		Hash:      u.GetPasswordHash(),
+		Algorithm: u.GetHashAlgorithm(),
+		Salt:      u.GetSalt(),
+		Confirmed: u.GetConfirmed(),
+	}
+	if u.GetData() != nil {
+		rec.Data = u.GetData().AsMap()
+	}
+	if u.GetMetadata() != nil {
+		rec.Metadata = u.GetMetadata().AsMap()
+	}
+	return rec
+}
+
+func importResponse(res *users.ImportResult) *admin.ImportUsersResponse {
+	r := &admin.ImportUsersResponse{
+		Imported: int64(res.Imported),
+		Failed:   int64(res.Failed),
+		Results:  make([]*admin.ImportUserResult, len(res.Results)),
+	}
+	for i, row := range res.Results {
+		r.Results[i] = &admin.ImportUserResult{
+			Row:    int64(row.Row),
+			Email:  row.Email,
+			UserId: row.UserID,
+			Error:  row.Error,
+		}
+	}
+	return r
+}
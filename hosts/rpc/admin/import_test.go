@@ -0,0 +1,127 @@
+package admin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	rpc_admin "github.com/jrapoport/gothic/api/grpc/rpc/admin"
+	core_ctx "github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/hosts/rpc"
+	"github.com/jrapoport/gothic/test/tsrv"
+	"github.com/jrapoport/gothic/test/tutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestAdminServer_ImportUsers(t *testing.T) {
+	t.Parallel()
+	const (
+		md5Hash      = "$1$ab$t00rsSdYwJXPEvSlIBoBQ0"
+		md5Pass      = "SXJAm7qJ4?3dH!aN8T3f5p!oNnpXbaRy#Gtx#8jG"
+		firebaseHash = "lSrfV15cpx95/sZS2W9c9Kp6i/LVgQNDNC/qzrCnh1SAyZvqmZqAjTdn3aoItz+VHjoZilo78198JAdRuid5lQ=="
+		firebaseSalt = "42xEC+ixf3L2lw=="
+		firebasePass = "user1password"
+	)
+	srv, _ := tsrv.RPCHost(t, []rpc.RegisterServer{
+		RegisterServer,
+	})
+	client := tsrv.RPCClient(t, srv.Address(), func(cc grpc.ClientConnInterface) interface{} {
+		return rpc_admin.NewAdminClient(cc)
+	}).(rpc_admin.AdminClient)
+	importUsers := func(ctx context.Context, reqs ...*rpc_admin.ImportUsersRequest) (*rpc_admin.ImportUsersResponse, error) {
+		stream, err := client.ImportUsers(ctx)
+		require.NoError(t, err)
+		for _, req := range reqs {
+			err = stream.Send(req)
+			require.NoError(t, err)
+		}
+		return stream.CloseAndRecv()
+	}
+	userRequest := func(u *rpc_admin.ImportUser) *rpc_admin.ImportUsersRequest {
+		return &rpc_admin.ImportUsersRequest{
+			Request: &rpc_admin.ImportUsersRequest_User{User: u},
+		}
+	}
+	data, err := structpb.NewStruct(map[string]interface{}{
+		"color": "red",
+	})
+	require.NoError(t, err)
+	reqs := []*rpc_admin.ImportUsersRequest{
+		{
+			Request: &rpc_admin.ImportUsersRequest_Options{
+				Options: &rpc_admin.ImportOptions{
+					Firebase: &rpc_admin.FirebaseScrypt{
+						SignerKey:     "jxspr8Ki0RYycVU8zykbdLGjFQ3McFUH0uiiTvC8pVMXAn210wjLNmdZJzxUECKbm0QsEmYUSDzZvpjeJ9WmXA==",
+						SaltSeparator: "Bw==",
+						Rounds:        8,
+						MemCost:       14,
+					},
+				},
+			},
+		},
+		userRequest(&rpc_admin.ImportUser{
+			Email:        tutils.RandomEmail(),
+			PasswordHash: md5Hash,
+			Confirmed:    true,
+			Data:         data,
+		}),
+		userRequest(&rpc_admin.ImportUser{
+			Email:         tutils.RandomEmail(),
+			PasswordHash:  firebaseHash,
+			HashAlgorithm: "firebase-scrypt",
+			Salt:          firebaseSalt,
+			Confirmed:     true,
+		}),
+		userRequest(&rpc_admin.ImportUser{
+			Email:        "bad",
+			PasswordHash: md5Hash,
+		}),
+	}
+	// no root password
+	_, err = importUsers(context.Background(), reqs...)
+	assert.Error(t, err)
+	ctx := metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs(rpc.RootPassword, srv.Config().RootPassword))
+	// no users
+	_, err = importUsers(ctx)
+	assert.Error(t, err)
+	_, err = importUsers(ctx, reqs[0])
+	assert.Error(t, err)
+	// empty request
+	_, err = importUsers(ctx, &rpc_admin.ImportUsersRequest{})
+	assert.Error(t, err)
+	res, err := importUsers(ctx, reqs...)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, res.GetImported())
+	assert.EqualValues(t, 1, res.GetFailed())
+	results := res.GetResults()
+	require.Len(t, results, 3)
+	assert.EqualValues(t, 3, results[2].GetRow())
+	assert.Equal(t, "bad", results[2].GetEmail())
+	assert.Empty(t, results[2].GetUserId())
+	assert.NotEmpty(t, results[2].GetError())
+	passwords := []string{md5Pass, firebasePass}
+	for i, r := range results[:2] {
+		assert.EqualValues(t, i+1, r.GetRow())
+		assert.Empty(t, r.GetError())
+		uid, err := uuid.Parse(r.GetUserId())
+		require.NoError(t, err)
+		u, err := srv.GetUser(uid)
+		require.NoError(t, err)
+		assert.Equal(t, r.GetEmail(), u.Email)
+		assert.True(t, u.IsConfirmed())
+		assert.True(t, u.NeedsRehash())
+		if i == 0 {
+			assert.Equal(t, "red", u.Data.Get("color"))
+		}
+		_, err = srv.Login(core_ctx.Background(), r.GetEmail(), passwords[i])
+		require.NoError(t, err)
+		u, err = srv.GetUser(uid)
+		require.NoError(t, err)
+		assert.False(t, u.NeedsRehash())
+	}
+}
@@ -0,0 +1,184 @@
+package admin
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jrapoport/gothic/api/grpc/rpc/admin"
+	"github.com/jrapoport/gothic/models/rbac"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *server) CreateRole(ctx context.Context,
+	req *admin.CreateRoleRequest) (*admin.RoleResponse, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	rtx, err := s.adminRequestContext(ctx, rbac.RolesWrite)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	r, err := s.API.CreateRole(rtx, req.GetName(), req.GetDescription(), req.GetPermissions())
+	if err != nil {
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	s.Debugf("created role %s", r.Name)
+	return newRoleResponse(r), nil
+}
+
+func (s *server) ListRoles(ctx context.Context,
+	_ *emptypb.Empty) (*admin.RolesResponse, error) {
+	rtx, err := s.adminRequestContext(ctx, rbac.RolesRead)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	list, err := s.API.GetRoles(rtx)
+	if err != nil {
+		return nil, s.RPCError(codes.Internal, err)
+	}
+	return &admin.RolesResponse{Roles: newRolesResponse(list)}, nil
+}
+
+func (s *server) UpdateRole(ctx context.Context,
+	req *admin.UpdateRoleRequest) (*admin.RoleResponse, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	if req.GetName() == "" {
+		err := errors.New("role required")
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx, err := s.adminRequestContext(ctx, rbac.RolesWrite)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	// if the permissions are not set, they are not changed
+	var perms []string
+	if len(req.GetPermissions()) > 0 {
+		perms = req.GetPermissions()
+	}
+	r, err := s.API.UpdateRole(rtx, req.GetName(), req.Description, perms)
+	if err != nil {
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	s.Debugf("updated role %s", r.Name)
+	return newRoleResponse(r), nil
+}
+
+func (s *server) DeleteRole(ctx context.Context,
+	req *admin.RoleRequest) (*emptypb.Empty, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	if req.GetName() == "" {
+		err := errors.New("role required")
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx, err := s.adminRequestContext(ctx, rbac.RolesWrite)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	err = s.API.DeleteRole(rtx, req.GetName())
+	if err != nil {
+		return nil, s.RPCError(codes.NotFound, err)
+	}
+	s.Debugf("deleted role %s", req.GetName())
+	return &emptypb.Empty{}, nil
+}
+
+func (s *server) ListUserRoles(ctx context.Context,
+	req *admin.UserRolesRequest) (*admin.UserRolesResponse, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	uid, err := parseID("user", req.GetUserId())
+	if err != nil {
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx, err := s.adminRequestContext(ctx, rbac.RolesRead)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	list, err := s.API.GetUserRoles(rtx, uid)
+	if err != nil {
+		return nil, s.RPCError(codes.NotFound, err)
+	}
+	perms, err := s.API.GetUserPermissions(rtx, uid)
+	if err != nil {
+		return nil, s.RPCError(codes.NotFound, err)
+	}
+	return &admin.UserRolesResponse{
+		Roles:       newRolesResponse(list),
+		Permissions: rbac.Strings(perms),
+	}, nil
+}
+
+func (s *server) AssignUserRole(ctx context.Context,
+	req *admin.UserRoleRequest) (*emptypb.Empty, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	uid, err := parseID("user", req.GetUserId())
+	if err != nil {
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	if req.GetRole() == "" {
+		err = errors.New("role required")
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx, err := s.adminRequestContext(ctx, rbac.RolesAssign)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	err = s.API.AssignUserRole(rtx, uid, req.GetRole())
+	if err != nil {
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	s.Debugf("assigned role %s: %s", req.GetRole(), uid)
+	return &emptypb.Empty{}, nil
+}
+
+func (s *server) UnassignUserRole(ctx context.Context,
+	req *admin.UserRoleRequest) (*emptypb.Empty, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	uid, err := parseID("user", req.GetUserId())
+	if err != nil {
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	if req.GetRole() == "" {
+		err = errors.New("role required")
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx, err := s.adminRequestContext(ctx, rbac.RolesAssign)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	err = s.API.UnassignUserRole(rtx, uid, req.GetRole())
+	if err != nil {
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	s.Debugf("unassigned role %s: %s", req.GetRole(), uid)
+	return &emptypb.Empty{}, nil
+}
+
+func newRoleResponse(r *rbac.Role) *admin.RoleResponse {
+	return &admin.RoleResponse{
+		Name:        r.Name,
+		Description: r.Description,
+		Permissions: rbac.Strings(r.PermissionList()),
+		CreatedAt:   timestamppb.New(r.CreatedAt),
+		UpdatedAt:   timestamppb.New(r.UpdatedAt),
+	}
+}
+
+func newRolesResponse(list []*rbac.Role) []*admin.RoleResponse {
+	res := make([]*admin.RoleResponse, len(list))
+	for i, r := range list {
+		res[i] = newRoleResponse(r)
+	}
+	return res
+}
@@ -0,0 +1,158 @@
+package admin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/api/grpc/rpc/admin"
+	"github.com/jrapoport/gothic/hosts/rpc"
+	"github.com/jrapoport/gothic/models/rbac"
+	"github.com/jrapoport/gothic/test/tcore"
+	"github.com/jrapoport/gothic/test/tsrv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestAdminServer_Roles(t *testing.T) {
+	t.Parallel()
+	s, _ := tsrv.RPCServer(t, false)
+	srv := newAdminServer(s)
+	srv.Config().Signup.AutoConfirm = true
+	ctx := rootContext(srv.Config())
+	// nil request
+	_, err := srv.CreateRole(ctx, nil)
+	assert.Error(t, err)
+	_, err = srv.UpdateRole(ctx, nil)
+	assert.Error(t, err)
+	_, err = srv.DeleteRole(ctx, nil)
+	assert.Error(t, err)
+	// bad root password
+	bad := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(rpc.RootPassword, "bad"))
+	req := &admin.CreateRoleRequest{
+		Name:        "support",
+		Description: "support staff",
+		Permissions: []string{rbac.UsersRead.String()},
+	}
+	_, err = srv.CreateRole(bad, req)
+	assert.Error(t, err)
+	_, err = srv.ListRoles(bad, &emptypb.Empty{})
+	assert.Error(t, err)
+	// bad request
+	_, err = srv.CreateRole(ctx, &admin.CreateRoleRequest{Name: "support"})
+	assert.Error(t, err)
+	res, err := srv.CreateRole(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, req.GetName(), res.GetName())
+	assert.Equal(t, req.GetDescription(), res.GetDescription())
+	assert.Equal(t, req.GetPermissions(), res.GetPermissions())
+	list, err := srv.ListRoles(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	require.Len(t, list.GetRoles(), 1)
+	// update
+	_, err = srv.UpdateRole(ctx, &admin.UpdateRoleRequest{})
+	assert.Error(t, err)
+	_, err = srv.UpdateRole(ctx, &admin.UpdateRoleRequest{
+		Name:        "support",
+		Permissions: []string{"bad"},
+	})
+	assert.Error(t, err)
+	desc := "updated"
+	res, err = srv.UpdateRole(ctx, &admin.UpdateRoleRequest{
+		Name:        "support",
+		Description: &desc,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, desc, res.GetDescription())
+	assert.Equal(t, req.GetPermissions(), res.GetPermissions())
+	perms := []string{rbac.SessionsRead.String(), rbac.UsersRead.String()}
+	res, err = srv.UpdateRole(ctx, &admin.UpdateRoleRequest{
+		Name:        "support",
+		Permissions: perms,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, desc, res.GetDescription())
+	assert.Equal(t, perms, res.GetPermissions())
+	// delete
+	_, err = srv.DeleteRole(ctx, &admin.RoleRequest{})
+	assert.Error(t, err)
+	_, err = srv.DeleteRole(bad, &admin.RoleRequest{Name: "support"})
+	assert.Error(t, err)
+	_, err = srv.DeleteRole(ctx, &admin.RoleRequest{Name: "missing"})
+	assert.Error(t, err)
+	_, err = srv.DeleteRole(ctx, &admin.RoleRequest{Name: "support"})
+	assert.NoError(t, err)
+	list, err = srv.ListRoles(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	assert.Len(t, list.GetRoles(), 0)
+}
+
+func TestAdminServer_UserRoles(t *testing.T) {
+	t.Parallel()
+	s, _ := tsrv.RPCServer(t, false)
+	srv := newAdminServer(s)
+	srv.Config().Signup.AutoConfirm = true
+	ctx := rootContext(srv.Config())
+	u, _ := tcore.TestUser(t, srv.API, "", false)
+	perms := []string{rbac.SessionsRead.String()}
+	_, err := srv.CreateRole(ctx, &admin.CreateRoleRequest{
+		Name:        "support",
+		Permissions: perms,
+	})
+	require.NoError(t, err)
+	// nil request
+	_, err = srv.ListUserRoles(ctx, nil)
+	assert.Error(t, err)
+	_, err = srv.AssignUserRole(ctx, nil)
+	assert.Error(t, err)
+	_, err = srv.UnassignUserRole(ctx, nil)
+	assert.Error(t, err)
+	// bad request
+	req := &admin.UserRoleRequest{UserId: "bad", Role: "support"}
+	_, err = srv.AssignUserRole(ctx, req)
+	assert.Error(t, err)
+	req.UserId = uuid.New().String()
+	_, err = srv.AssignUserRole(ctx, req)
+	assert.Error(t, err)
+	req.UserId = u.ID.String()
+	req.Role = ""
+	_, err = srv.AssignUserRole(ctx, req)
+	assert.Error(t, err)
+	req.Role = "missing"
+	_, err = srv.AssignUserRole(ctx, req)
+	assert.Error(t, err)
+	// not admin
+	_, tok := tcore.TestUser(t, srv.API, "", false)
+	uctx := tsrv.RPCAuthContext(t, srv.Config(), tok)
+	req.Role = "support"
+	_, err = srv.AssignUserRole(uctx, req)
+	assert.Error(t, err)
+	_, err = srv.AssignUserRole(ctx, req)
+	assert.NoError(t, err)
+	lreq := &admin.UserRolesRequest{UserId: u.ID.String()}
+	res, err := srv.ListUserRoles(ctx, lreq)
+	require.NoError(t, err)
+	require.Len(t, res.GetRoles(), 1)
+	assert.Equal(t, "support", res.GetRoles()[0].GetName())
+	assert.Equal(t, perms, res.GetPermissions())
+	// a role user with the permission
+	_, tok = tcore.TestRoleUser(t, srv.API, rbac.SessionsRead)
+	rctx := tsrv.RPCAuthContext(t, srv.Config(), tok)
+	_, err = srv.ListUserSessions(rctx, &admin.UserSessionsRequest{UserId: u.ID.String()})
+	assert.NoError(t, err)
+	_, err = srv.RevokeUserSessions(rctx, &admin.UserSessionsRequest{UserId: u.ID.String()})
+	assert.Error(t, err)
+	// unassign
+	_, err = srv.UnassignUserRole(uctx, req)
+	assert.Error(t, err)
+	_, err = srv.UnassignUserRole(ctx, req)
+	assert.NoError(t, err)
+	_, err = srv.UnassignUserRole(ctx, req)
+	assert.Error(t, err)
+	res, err = srv.ListUserRoles(ctx, lreq)
+	require.NoError(t, err)
+	assert.Len(t, res.GetRoles(), 0)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/jrapoport/gothic/api/grpc/rpc/admin"
 	core_ctx "github.com/jrapoport/gothic/core/context"
 	"github.com/jrapoport/gothic/hosts/rpc"
+	"github.com/jrapoport/gothic/models/rbac"
 	"github.com/jrapoport/gothic/models/user"
 	"google.golang.org/grpc"
 )
@@ -23,7 +24,7 @@ func newAdminServer(srv *rpc.Server) *server {
 }
 
 // RegisterServer registers a new admin server.
-func RegisterServer(s *grpc.Server, srv *rpc.Server) {
+func RegisterServer(s grpc.ServiceRegistrar, srv *rpc.Server) {
 	admin.RegisterAdminServer(s, newAdminServer(srv))
 }
 
@@ -49,9 +50,9 @@ func adminRoleFromContext(ctx context.Context) user.Role {
 	return role
 }
 
-func (s *server) adminRequestContext(ctx context.Context) (core_ctx.Context, error) {
+func (s *server) adminRequestContext(ctx context.Context, perm rbac.Permission) (core_ctx.Context, error) {
 	ctx = s.rootContext(ctx)
-	role, err := s.Server.ValidateAdmin(ctx)
+	role, err := s.Server.ValidateAdmin(ctx, perm)
 	if err != nil {
 		return nil, err
 	}
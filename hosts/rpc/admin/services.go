@@ -0,0 +1,91 @@
+package admin
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/api/grpc/rpc/admin"
+	"github.com/jrapoport/gothic/models/client"
+	"github.com/jrapoport/gothic/models/rbac"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *server) CreateServiceClient(ctx context.Context,
+	req *admin.CreateServiceClientRequest) (*admin.ServiceClientResponse, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	oid := uuid.Nil
+	if req.GetOwnerId() != "" {
+		var err error
+		oid, err = parseID("owner", req.GetOwnerId())
+		if err != nil {
+			return nil, s.RPCError(codes.InvalidArgument, err)
+		}
+	}
+	rtx, err := s.adminRequestContext(ctx, rbac.ClientsWrite)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	svc, secret, err := s.API.CreateServiceClient(rtx, req.GetName(), req.GetScopes(), oid)
+	if err != nil {
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	s.Debugf("created service client %s: %s", svc.ClientID, svc.Name)
+	res := newServiceClientResponse(svc)
+	res.ClientSecret = secret
+	return res, nil
+}
+
+func (s *server) ListServiceClients(ctx context.Context,
+	_ *emptypb.Empty) (*admin.ServiceClientsResponse, error) {
+	rtx, err := s.adminRequestContext(ctx, rbac.ClientsRead)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	services, err := s.API.GetServiceClients(rtx)
+	if err != nil {
+		return nil, s.RPCError(codes.Internal, err)
+	}
+	res := &admin.ServiceClientsResponse{
+		Clients: make([]*admin.ServiceClientResponse, len(services)),
+	}
+	for i, svc := range services {
+		res.Clients[i] = newServiceClientResponse(svc)
+	}
+	return res, nil
+}
+
+func (s *server) DeleteServiceClient(ctx context.Context,
+	req *admin.DeleteClientRequest) (*emptypb.Empty, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	if req.GetClientId() == "" {
+		err := errors.New("client id required")
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx, err := s.adminRequestContext(ctx, rbac.ClientsWrite)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	err = s.API.DeleteServiceClient(rtx, req.GetClientId())
+	if err != nil {
+		return nil, s.RPCError(codes.NotFound, err)
+	}
+	s.Debugf("deleted service client %s", req.GetClientId())
+	return &emptypb.Empty{}, nil
+}
+
+func newServiceClientResponse(svc *client.Service) *admin.ServiceClientResponse {
+	return &admin.ServiceClientResponse{
+		ClientId:  svc.ClientID,
+		Name:      svc.Name,
+		Scopes:    svc.ScopeList(),
+		OwnerId:   svc.OwnerID.String(),
+		CreatedAt: timestamppb.New(svc.CreatedAt),
+	}
+}
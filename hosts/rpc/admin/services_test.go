@@ -0,0 +1,80 @@
+package admin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/api/grpc/rpc/admin"
+	"github.com/jrapoport/gothic/hosts/rpc"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tcore"
+	"github.com/jrapoport/gothic/test/tsrv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestAdminServer_ServiceClients(t *testing.T) {
+	t.Parallel()
+	s, _ := tsrv.RPCServer(t, false)
+	srv := newAdminServer(s)
+	srv.Config().Signup.AutoConfirm = true
+	ctx := rootContext(srv.Config())
+	u, _ := tcore.TestUser(t, srv.API, "", false)
+	// nil request
+	_, err := srv.CreateServiceClient(ctx, nil)
+	assert.Error(t, err)
+	_, err = srv.DeleteServiceClient(ctx, nil)
+	assert.Error(t, err)
+	// bad root password
+	bad := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(rpc.RootPassword, "bad"))
+	req := &admin.CreateServiceClientRequest{
+		Name:   "test",
+		Scopes: []string{"read", "write"},
+	}
+	_, err = srv.CreateServiceClient(bad, req)
+	assert.Error(t, err)
+	_, err = srv.ListServiceClients(bad, &emptypb.Empty{})
+	assert.Error(t, err)
+	// bad owner
+	req.OwnerId = "bad"
+	_, err = srv.CreateServiceClient(ctx, req)
+	assert.Error(t, err)
+	req.OwnerId = uuid.New().String()
+	_, err = srv.CreateServiceClient(ctx, req)
+	assert.Error(t, err)
+	req.OwnerId = u.ID.String()
+	res, err := srv.CreateServiceClient(ctx, req)
+	require.NoError(t, err)
+	assert.NotEmpty(t, res.GetClientId())
+	assert.NotEmpty(t, res.GetClientSecret())
+	assert.Equal(t, req.GetScopes(), res.GetScopes())
+	assert.Equal(t, u.ID.String(), res.GetOwnerId())
+	// root is the default owner
+	req.OwnerId = ""
+	res2, err := srv.CreateServiceClient(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, user.SuperAdminID.String(), res2.GetOwnerId())
+	list, err := srv.ListServiceClients(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	require.Len(t, list.GetClients(), 2)
+	assert.Empty(t, list.GetClients()[0].GetClientSecret())
+	// bad client id
+	dreq := &admin.DeleteClientRequest{}
+	_, err = srv.DeleteServiceClient(ctx, dreq)
+	assert.Error(t, err)
+	dreq.ClientId = "bad"
+	_, err = srv.DeleteServiceClient(ctx, dreq)
+	assert.Error(t, err)
+	dreq.ClientId = res.GetClientId()
+	_, err = srv.DeleteServiceClient(bad, dreq)
+	assert.Error(t, err)
+	_, err = srv.DeleteServiceClient(ctx, dreq)
+	assert.NoError(t, err)
+	list, err = srv.ListServiceClients(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	assert.Len(t, list.GetClients(), 1)
+}
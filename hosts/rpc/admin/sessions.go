@@ -0,0 +1,105 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/api/grpc/rpc/admin"
+	"github.com/jrapoport/gothic/models/rbac"
+	"github.com/jrapoport/gothic/models/token"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *server) ListUserSessions(ctx context.Context,
+	req *admin.UserSessionsRequest) (*admin.UserSessionsResponse, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	uid, err := parseID("user", req.GetUserId())
+	if err != nil {
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx, err := s.adminRequestContext(ctx, rbac.SessionsRead)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	sessions, err := s.API.GetUserSessions(rtx, uid)
+	if err != nil {
+		return nil, s.RPCError(codes.NotFound, err)
+	}
+	res := &admin.UserSessionsResponse{
+		Sessions: make([]*admin.UserSession, len(sessions)),
+	}
+	for i, rt := range sessions {
+		res.Sessions[i] = newUserSession(rt)
+	}
+	return res, nil
+}
+
+func (s *server) RevokeUserSession(ctx context.Context,
+	req *admin.RevokeUserSessionRequest) (*emptypb.Empty, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	uid, err := parseID("user", req.GetUserId())
+	if err != nil {
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	sid, err := parseID("session", req.GetSessionId())
+	if err != nil {
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx, err := s.adminRequestContext(ctx, rbac.SessionsRevoke)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	err = s.API.RevokeUserSession(rtx, uid, sid)
+	if err != nil {
+		return nil, s.RPCError(codes.NotFound, err)
+	}
+	s.Debugf("revoked session %s: %s", sid, uid)
+	return &emptypb.Empty{}, nil
+}
+
+func (s *server) RevokeUserSessions(ctx context.Context,
+	req *admin.UserSessionsRequest) (*admin.RevokeUserSessionsResponse, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	uid, err := parseID("user", req.GetUserId())
+	if err != nil {
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx, err := s.adminRequestContext(ctx, rbac.SessionsRevoke)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	n, err := s.API.RevokeUserSessions(rtx, uid)
+	if err != nil {
+		return nil, s.RPCError(codes.Internal, err)
+	}
+	s.Debugf("revoked %d sessions: %s", n, uid)
+	return &admin.RevokeUserSessionsResponse{Revoked: int32(n)}, nil
+}
+
+func parseID(name, id string) (uuid.UUID, error) {
+	uid, err := uuid.Parse(id)
+	if err != nil || uid == uuid.Nil {
+		return uuid.Nil, fmt.Errorf("invalid %s id '%s': %w", name, id, err)
+	}
+	return uid, nil
+}
+
+func newUserSession(rt *token.RefreshToken) *admin.UserSession {
+	return &admin.UserSession{
+		SessionId:  rt.SessionID.String(),
+		UserAgent:  rt.UserAgent,
+		IpAddress:  rt.IPAddress,
+		DeviceName: rt.DeviceName,
+		CreatedAt:  timestamppb.New(rt.SignedInAt),
+		LastUsedAt: timestamppb.New(rt.LastActive()),
+	}
+}
@@ -0,0 +1,76 @@
+package admin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/api/grpc/rpc/admin"
+	core_ctx "github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/hosts/rpc"
+	"github.com/jrapoport/gothic/test/tcore"
+	"github.com/jrapoport/gothic/test/tsrv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestAdminServer_UserSessions(t *testing.T) {
+	t.Parallel()
+	s, _ := tsrv.RPCServer(t, false)
+	srv := newAdminServer(s)
+	ctx := rootContext(srv.Config())
+	u, _ := tcore.TestUser(t, srv.API, "", false)
+	_, err := srv.API.GrantBearerToken(core_ctx.Background(), u)
+	require.NoError(t, err)
+	// nil request
+	_, err = srv.ListUserSessions(ctx, nil)
+	assert.Error(t, err)
+	_, err = srv.RevokeUserSession(ctx, nil)
+	assert.Error(t, err)
+	_, err = srv.RevokeUserSessions(ctx, nil)
+	assert.Error(t, err)
+	// bad user id
+	req := &admin.UserSessionsRequest{UserId: "bad"}
+	_, err = srv.ListUserSessions(ctx, req)
+	assert.Error(t, err)
+	_, err = srv.RevokeUserSessions(ctx, req)
+	assert.Error(t, err)
+	req.UserId = uuid.New().String()
+	_, err = srv.ListUserSessions(ctx, req)
+	assert.Error(t, err)
+	// bad root password
+	req.UserId = u.ID.String()
+	bad := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(rpc.RootPassword, "bad"))
+	_, err = srv.ListUserSessions(bad, req)
+	assert.Error(t, err)
+	res, err := srv.ListUserSessions(ctx, req)
+	require.NoError(t, err)
+	require.Len(t, res.GetSessions(), 2)
+	sid := res.GetSessions()[0].GetSessionId()
+	assert.NotNil(t, res.GetSessions()[0].GetCreatedAt())
+	// bad session id
+	rreq := &admin.RevokeUserSessionRequest{UserId: u.ID.String(), SessionId: "bad"}
+	_, err = srv.RevokeUserSession(ctx, rreq)
+	assert.Error(t, err)
+	rreq.SessionId = uuid.New().String()
+	_, err = srv.RevokeUserSession(ctx, rreq)
+	assert.Error(t, err)
+	rreq.SessionId = sid
+	_, err = srv.RevokeUserSession(bad, rreq)
+	assert.Error(t, err)
+	_, err = srv.RevokeUserSession(ctx, rreq)
+	assert.NoError(t, err)
+	res, err = srv.ListUserSessions(ctx, req)
+	require.NoError(t, err)
+	assert.Len(t, res.GetSessions(), 1)
+	_, err = srv.RevokeUserSessions(bad, req)
+	assert.Error(t, err)
+	rev, err := srv.RevokeUserSessions(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), rev.GetRevoked())
+	res, err = srv.ListUserSessions(ctx, req)
+	require.NoError(t, err)
+	assert.Empty(t, res.GetSessions())
+}
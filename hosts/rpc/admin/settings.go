@@ -4,13 +4,14 @@ import (
 	"context"
 
 	"github.com/jrapoport/gothic/api/grpc/rpc/admin"
+	"github.com/jrapoport/gothic/models/rbac"
 	"github.com/segmentio/encoding/json"
 	"google.golang.org/grpc/codes"
 )
 
 // Settings returns the settings for a server.
 func (s *server) Settings(ctx context.Context, _ *admin.SettingsRequest) (*admin.SettingsResponse, error) {
-	_, err := s.adminRequestContext(ctx)
+	_, err := s.adminRequestContext(ctx, rbac.SettingsRead)
 	if err != nil {
 		return nil, s.RPCError(codes.PermissionDenied, err)
 	}
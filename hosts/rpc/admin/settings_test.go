@@ -23,7 +23,7 @@ func TestAdminServer_Settings(t *testing.T) {
 	jpb := &protojson.MarshalOptions{
 		EmitUnpopulated: false,
 		Indent:          "",
-		UseProtoNames:   false,
+		UseProtoNames:   true,
 		UseEnumNumbers:  false,
 	}
 	str, err := jpb.Marshal(res)
@@ -0,0 +1,150 @@
+package admin
+
+import (
+	"context"
+
+	"github.com/jrapoport/gothic/api/grpc/rpc/admin"
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/models/rbac"
+	"github.com/jrapoport/gothic/models/tenant"
+	"github.com/segmentio/encoding/json"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *server) CreateTenant(ctx context.Context,
+	req *admin.CreateTenantRequest) (*admin.TenantResponse, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	tc, err := tenantConfig(req.GetConfig())
+	if err != nil {
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx, err := s.adminRequestContext(ctx, rbac.TenantsWrite)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	t, err := s.API.CreateTenant(rtx, req.GetName(), req.GetHost(), tc)
+	if err != nil {
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	s.Debugf("created tenant %s: %s", t.ID, t.Name)
+	return newTenantResponse(t), nil
+}
+
+func (s *server) ListTenants(ctx context.Context,
+	_ *emptypb.Empty) (*admin.TenantsResponse, error) {
+	rtx, err := s.adminRequestContext(ctx, rbac.TenantsRead)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	list, err := s.API.GetTenants(rtx)
+	if err != nil {
+		return nil, s.RPCError(codes.Internal, err)
+	}
+	res := &admin.TenantsResponse{
+		Tenants: make([]*admin.TenantResponse, len(list)),
+	}
+	for i, t := range list {
+		res.Tenants[i] = newTenantResponse(t)
+	}
+	return res, nil
+}
+
+func (s *server) GetTenant(ctx context.Context,
+	req *admin.TenantRequest) (*admin.TenantResponse, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	tid, err := parseID("tenant", req.GetTenantId())
+	if err != nil {
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx, err := s.adminRequestContext(ctx, rbac.TenantsRead)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	t, err := s.API.GetTenant(rtx, tid)
+	if err != nil {
+		return nil, s.RPCError(codes.NotFound, err)
+	}
+	return newTenantResponse(t), nil
+}
+
+func (s *server) UpdateTenant(ctx context.Context,
+	req *admin.UpdateTenantRequest) (*admin.TenantResponse, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	tid, err := parseID("tenant", req.GetTenantId())
+	if err != nil {
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	// if the config is not set, it is not changed
+	var tc *config.TenantConfig
+	if req.Config != nil {
+		c, err := tenantConfig(req.GetConfig())
+		if err != nil {
+			return nil, s.RPCError(codes.InvalidArgument, err)
+		}
+		tc = &c
+	}
+	rtx, err := s.adminRequestContext(ctx, rbac.TenantsWrite)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	t, err := s.API.UpdateTenant(rtx, tid, req.Host, tc)
+	if err != nil {
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	s.Debugf("updated tenant %s: %s", t.ID, t.Name)
+	return newTenantResponse(t), nil
+}
+
+func (s *server) DeleteTenant(ctx context.Context,
+	req *admin.TenantRequest) (*emptypb.Empty, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	tid, err := parseID("tenant", req.GetTenantId())
+	if err != nil {
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx, err := s.adminRequestContext(ctx, rbac.TenantsWrite)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	err = s.API.DeleteTenant(rtx, tid)
+	if err != nil {
+		return nil, s.RPCError(codes.NotFound, err)
+	}
+	s.Debugf("deleted tenant %s", tid)
+	return &emptypb.Empty{}, nil
+}
+
+// tenantConfig converts the config struct to a tenant configuration.
+func tenantConfig(cfg *structpb.Struct) (config.TenantConfig, error) {
+	var tc config.TenantConfig
+	if cfg == nil {
+		return tc, nil
+	}
+	b, err := json.Marshal(cfg.AsMap())
+	if err != nil {
+		return tc, err
+	}
+	err = json.Unmarshal(b, &tc)
+	return tc, err
+}
+
+func newTenantResponse(t *tenant.Tenant) *admin.TenantResponse {
+	return &admin.TenantResponse{
+		TenantId:  t.ID.String(),
+		Name:      t.Name,
+		Host:      t.Host,
+		CreatedAt: timestamppb.New(t.CreatedAt),
+		UpdatedAt: timestamppb.New(t.UpdatedAt),
+	}
+}
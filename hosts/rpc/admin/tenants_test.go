@@ -0,0 +1,100 @@
+package admin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/api/grpc/rpc/admin"
+	"github.com/jrapoport/gothic/hosts/rpc"
+	"github.com/jrapoport/gothic/test/tsrv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestAdminServer_Tenants(t *testing.T) {
+	t.Parallel()
+	s, _ := tsrv.RPCServer(t, false)
+	srv := newAdminServer(s)
+	c := srv.Config()
+	c.Tenants.Enabled = true
+	err := srv.API.LoadConfig(c)
+	require.NoError(t, err)
+	ctx := rootContext(c)
+	// nil request
+	_, err = srv.CreateTenant(ctx, nil)
+	assert.Error(t, err)
+	_, err = srv.GetTenant(ctx, nil)
+	assert.Error(t, err)
+	_, err = srv.UpdateTenant(ctx, nil)
+	assert.Error(t, err)
+	_, err = srv.DeleteTenant(ctx, nil)
+	assert.Error(t, err)
+	// bad root password
+	bad := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(rpc.RootPassword, "bad"))
+	cfg, err := structpb.NewStruct(map[string]interface{}{
+		"site_url": "http://acme.example.com",
+	})
+	require.NoError(t, err)
+	req := &admin.CreateTenantRequest{
+		Name:   "acme",
+		Host:   "acme.example.com",
+		Config: cfg,
+	}
+	_, err = srv.CreateTenant(bad, req)
+	assert.Error(t, err)
+	_, err = srv.ListTenants(bad, &emptypb.Empty{})
+	assert.Error(t, err)
+	// bad request
+	_, err = srv.CreateTenant(ctx, &admin.CreateTenantRequest{Name: "Not Valid"})
+	assert.Error(t, err)
+	res, err := srv.CreateTenant(ctx, req)
+	require.NoError(t, err)
+	assert.NotEmpty(t, res.GetTenantId())
+	assert.Equal(t, req.GetName(), res.GetName())
+	assert.Equal(t, req.GetHost(), res.GetHost())
+	list, err := srv.ListTenants(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	require.Len(t, list.GetTenants(), 1)
+	// get
+	_, err = srv.GetTenant(ctx, &admin.TenantRequest{})
+	assert.Error(t, err)
+	_, err = srv.GetTenant(ctx, &admin.TenantRequest{TenantId: uuid.New().String()})
+	assert.Error(t, err)
+	got, err := srv.GetTenant(ctx, &admin.TenantRequest{TenantId: res.GetTenantId()})
+	require.NoError(t, err)
+	assert.Equal(t, res.GetName(), got.GetName())
+	// update
+	_, err = srv.UpdateTenant(ctx, &admin.UpdateTenantRequest{})
+	assert.Error(t, err)
+	badCfg, err := structpb.NewStruct(map[string]interface{}{
+		"site_url": "\n",
+	})
+	require.NoError(t, err)
+	_, err = srv.UpdateTenant(ctx, &admin.UpdateTenantRequest{
+		TenantId: res.GetTenantId(),
+		Config:   badCfg,
+	})
+	assert.Error(t, err)
+	host := "brand.example.com"
+	got, err = srv.UpdateTenant(ctx, &admin.UpdateTenantRequest{
+		TenantId: res.GetTenantId(),
+		Host:     &host,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, host, got.GetHost())
+	// delete
+	_, err = srv.DeleteTenant(ctx, &admin.TenantRequest{})
+	assert.Error(t, err)
+	_, err = srv.DeleteTenant(ctx, &admin.TenantRequest{TenantId: uuid.New().String()})
+	assert.Error(t, err)
+	_, err = srv.DeleteTenant(ctx, &admin.TenantRequest{TenantId: res.GetTenantId()})
+	require.NoError(t, err)
+	list, err = srv.ListTenants(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	assert.Empty(t, list.GetTenants())
+}
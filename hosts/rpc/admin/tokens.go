@@ -0,0 +1,63 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jrapoport/gothic/api/grpc/rpc/admin"
+	"github.com/jrapoport/gothic/models/rbac"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func (s *server) RevokeUserToken(ctx context.Context,
+	req *admin.RevokeUserTokenRequest) (*emptypb.Empty, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	uid, err := parseID("user", req.GetUserId())
+	if err != nil {
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	jti := req.GetTokenId()
+	if jti == "" {
+		err = errors.New("token id required")
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx, err := s.adminRequestContext(ctx, rbac.SessionsRevoke)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	err = s.API.RevokeUserToken(rtx, uid, jti)
+	if err != nil {
+		return nil, s.RPCError(codes.NotFound, err)
+	}
+	s.Debugf("revoked token %s: %s", jti, uid)
+	return &emptypb.Empty{}, nil
+}
+
+func (s *server) RevokeUserTokens(ctx context.Context,
+	req *admin.RevokeUserTokensRequest) (*emptypb.Empty, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	uid, err := parseID("user", req.GetUserId())
+	if err != nil {
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	var before time.Time
+	if req.GetBefore() != nil {
+		before = req.GetBefore().AsTime()
+	}
+	rtx, err := s.adminRequestContext(ctx, rbac.SessionsRevoke)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	err = s.API.RevokeUserTokens(rtx, uid, before)
+	if err != nil {
+		return nil, s.RPCError(codes.NotFound, err)
+	}
+	s.Debugf("revoked tokens before %s: %s", before, uid)
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,78 @@
+package admin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/api/grpc/rpc/admin"
+	core_ctx "github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/hosts/rpc"
+	"github.com/jrapoport/gothic/jwt"
+	"github.com/jrapoport/gothic/test/tcore"
+	"github.com/jrapoport/gothic/test/tsrv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestAdminServer_RevokeUserTokens(t *testing.T) {
+	t.Parallel()
+	s, _ := tsrv.RPCServer(t, false)
+	srv := newAdminServer(s)
+	ctx := rootContext(srv.Config())
+	u, tok := tcore.TestUser(t, srv.API, "", false)
+	claims, err := jwt.ParseUserClaims(srv.Config().JWT, tok)
+	require.NoError(t, err)
+	// nil request
+	_, err = srv.RevokeUserToken(ctx, nil)
+	assert.Error(t, err)
+	_, err = srv.RevokeUserTokens(ctx, nil)
+	assert.Error(t, err)
+	// bad user id
+	req := &admin.RevokeUserTokenRequest{UserId: "bad", TokenId: claims.JwtID()}
+	_, err = srv.RevokeUserToken(ctx, req)
+	assert.Error(t, err)
+	req.UserId = uuid.New().String()
+	_, err = srv.RevokeUserToken(ctx, req)
+	assert.Error(t, err)
+	treq := &admin.RevokeUserTokensRequest{UserId: "bad"}
+	_, err = srv.RevokeUserTokens(ctx, treq)
+	assert.Error(t, err)
+	treq.UserId = uuid.New().String()
+	_, err = srv.RevokeUserTokens(ctx, treq)
+	assert.Error(t, err)
+	// no token id
+	req.UserId = u.ID.String()
+	req.TokenId = ""
+	_, err = srv.RevokeUserToken(ctx, req)
+	assert.Error(t, err)
+	// bad root password
+	req.TokenId = claims.JwtID()
+	bad := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(rpc.RootPassword, "bad"))
+	_, err = srv.RevokeUserToken(bad, req)
+	assert.Error(t, err)
+	_, err = srv.RevokeUserToken(ctx, req)
+	assert.NoError(t, err)
+	assert.Error(t, srv.API.ValidateBearerToken(claims))
+	// revoke before
+	bt, err := srv.API.GrantBearerToken(core_ctx.Background(), u)
+	require.NoError(t, err)
+	claims, err = jwt.ParseUserClaims(srv.Config().JWT, bt.String())
+	require.NoError(t, err)
+	treq.UserId = u.ID.String()
+	treq.Before = timestamppb.New(claims.Issued().Add(-time.Hour))
+	_, err = srv.RevokeUserTokens(bad, treq)
+	assert.Error(t, err)
+	_, err = srv.RevokeUserTokens(ctx, treq)
+	assert.NoError(t, err)
+	assert.NoError(t, srv.API.ValidateBearerToken(claims))
+	// revoke all
+	treq.Before = nil
+	_, err = srv.RevokeUserTokens(ctx, treq)
+	assert.NoError(t, err)
+	assert.Error(t, srv.API.ValidateBearerToken(claims))
+}
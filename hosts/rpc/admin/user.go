@@ -4,12 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"google.golang.org/protobuf/types/known/structpb"
 
 	"github.com/google/uuid"
+	api "github.com/jrapoport/gothic/api/grpc/rpc"
 	"github.com/jrapoport/gothic/api/grpc/rpc/admin"
+	"github.com/jrapoport/gothic/hosts/rpc"
+	"github.com/jrapoport/gothic/models/rbac"
 	"github.com/jrapoport/gothic/models/user"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 func (s *server) CreateUser(ctx context.Context, req *admin.CreateUserRequest) (*admin.CreateUserResponse, error) {
@@ -25,7 +28,7 @@ func (s *server) CreateUser(ctx context.Context, req *admin.CreateUserRequest) (
 		err := errors.New("email required")
 		return nil, s.RPCError(codes.InvalidArgument, err)
 	}
-	rtx, err := s.adminRequestContext(ctx)
+	rtx, err := s.adminRequestContext(ctx, rbac.UsersCreate)
 	if err != nil {
 		return nil, s.RPCError(codes.PermissionDenied, err)
 	}
@@ -60,7 +63,7 @@ func (s *server) ChangeUserRole(ctx context.Context, req *admin.ChangeUserRoleRe
 		err := errors.New("invalid user role")
 		return nil, s.RPCError(codes.InvalidArgument, err)
 	}
-	rtx, err := s.adminRequestContext(ctx)
+	rtx, err := s.adminRequestContext(ctx, rbac.RolesAssign)
 	if err != nil {
 		return nil, s.RPCError(codes.PermissionDenied, err)
 	}
@@ -107,7 +110,7 @@ func (s *server) UpdateUserMetadata(ctx context.Context, req *admin.UpdateUserMe
 		err := errors.New("user id or email is required")
 		return nil, s.RPCError(codes.InvalidArgument, err)
 	}
-	rtx, err := s.adminRequestContext(ctx)
+	rtx, err := s.adminRequestContext(ctx, rbac.UsersWrite)
 	if err != nil {
 		return nil, s.RPCError(codes.PermissionDenied, err)
 	}
@@ -154,7 +157,7 @@ func (s *server) DeleteUser(ctx context.Context, req *admin.DeleteUserRequest) (
 		err := errors.New("user id or email is required")
 		return nil, s.RPCError(codes.InvalidArgument, err)
 	}
-	rtx, err := s.adminRequestContext(ctx)
+	rtx, err := s.adminRequestContext(ctx, rbac.UsersDelete)
 	if err != nil {
 		return nil, s.RPCError(codes.PermissionDenied, err)
 	}
@@ -186,3 +189,109 @@ func (s *server) DeleteUser(ctx context.Context, req *admin.DeleteUserRequest) (
 	}
 	return res, nil
 }
+
+func (s *server) UnlockUser(ctx context.Context, req *admin.UnlockUserRequest) (*admin.UnlockUserResponse, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	if req.GetUserId() == "" && req.GetEmail() == "" {
+		err := errors.New("user id or email is required")
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx, err := s.adminRequestContext(ctx, rbac.UsersWrite)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	var uid uuid.UUID
+	switch req.GetUser().(type) {
+	case *admin.UnlockUserRequest_UserId:
+		userID := req.GetUserId()
+		id, err := uuid.Parse(userID)
+		if err != nil || id == uuid.Nil {
+			err = fmt.Errorf("invalid user id '%s': %w", userID, err)
+			return nil, s.RPCError(codes.InvalidArgument, err)
+		}
+		uid = id
+	case *admin.UnlockUserRequest_Email:
+		email := req.GetEmail()
+		u, err := s.API.GetUserWithEmail(email)
+		if err != nil {
+			err = fmt.Errorf("user not found '%s': %w", email, err)
+			return nil, s.RPCError(codes.InvalidArgument, err)
+		}
+		uid = u.ID
+	}
+	u, err := s.API.UnlockUser(rtx, uid)
+	if err != nil {
+		return nil, s.RPCError(codes.Internal, err)
+	}
+	res := &admin.UnlockUserResponse{
+		UserId: u.ID.String(),
+	}
+	s.Debugf("unlocked user %s", uid)
+	return res, nil
+}
+
+func (s *server) ForcePasswordChange(ctx context.Context,
+	req *admin.ForcePasswordChangeRequest) (*admin.ForcePasswordChangeResponse, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	if req.GetUserId() == "" && req.GetEmail() == "" {
+		err := errors.New("user id or email is required")
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx, err := s.adminRequestContext(ctx, rbac.UsersWrite)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	var uid uuid.UUID
+	switch req.GetUser().(type) {
+	case *admin.ForcePasswordChangeRequest_UserId:
+		userID := req.GetUserId()
+		id, err := uuid.Parse(userID)
+		if err != nil || id == uuid.Nil {
+			err = fmt.Errorf("invalid user id '%s': %w", userID, err)
+			return nil, s.RPCError(codes.InvalidArgument, err)
+		}
+		uid = id
+	case *admin.ForcePasswordChangeRequest_Email:
+		email := req.GetEmail()
+		u, err := s.API.GetUserWithEmail(email)
+		if err != nil {
+			err = fmt.Errorf("user not found '%s': %w", email, err)
+			return nil, s.RPCError(codes.InvalidArgument, err)
+		}
+		uid = u.ID
+	}
+	u, err := s.API.ForcePasswordChange(rtx, uid)
+	if err != nil {
+		return nil, s.RPCError(codes.Internal, err)
+	}
+	res := &admin.ForcePasswordChangeResponse{
+		UserId: u.ID.String(),
+	}
+	s.Debugf("forced password change %s", uid)
+	return res, nil
+}
+
+func (s *server) ImpersonateUser(ctx context.Context,
+	req *admin.ImpersonateUserRequest) (*api.BearerResponse, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	uid, err := parseID("user", req.GetUserId())
+	if err != nil {
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx, err := s.adminRequestContext(ctx, rbac.UsersImpersonate)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	bt, err := s.API.ImpersonateUser(rtx, uid)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	s.Debugf("impersonating user %s", uid)
+	return rpc.NewBearerResponse(bt), nil
+}
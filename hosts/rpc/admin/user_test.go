@@ -7,16 +7,21 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jrapoport/gothic/api/grpc/rpc/admin"
+	"github.com/jrapoport/gothic/core/users"
 	"github.com/jrapoport/gothic/hosts/rpc"
+	"github.com/jrapoport/gothic/jwt"
 	"github.com/jrapoport/gothic/models/types"
 	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconn"
 	"github.com/jrapoport/gothic/test/tcore"
 	"github.com/jrapoport/gothic/test/tsrv"
 	"github.com/jrapoport/gothic/test/tutils"
 	"github.com/jrapoport/gothic/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
@@ -304,3 +309,149 @@ func TestAdminServer_DeleteUser(t *testing.T) {
 	_, err = srv.API.Signup(nil, u.Email, "", testPass, nil)
 	assert.NoError(t, err)
 }
+
+func TestAdminServer_UnlockUser(t *testing.T) {
+	t.Parallel()
+	s, _ := tsrv.RPCServer(t, false)
+	srv := newAdminServer(s)
+	conn := tconn.Conn(t, srv.Config())
+	ctx := rootContext(srv.Config())
+	// nil request
+	_, err := srv.UnlockUser(ctx, nil)
+	assert.Error(t, err)
+	// no params
+	req := &admin.UnlockUserRequest{}
+	_, err = srv.UnlockUser(ctx, req)
+	assert.Error(t, err)
+	// bad root password
+	req.User = &admin.UnlockUserRequest_UserId{UserId: uuid.Nil.String()}
+	bad := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(rpc.RootPassword, "bad"))
+	_, err = srv.UnlockUser(bad, req)
+	assert.Error(t, err)
+	// nil user id
+	_, err = srv.UnlockUser(ctx, req)
+	assert.Error(t, err)
+	// bad user id
+	req.User = &admin.UnlockUserRequest_UserId{UserId: uuid.New().String()}
+	_, err = srv.UnlockUser(ctx, req)
+	assert.Error(t, err)
+	// bad email
+	req.User = &admin.UnlockUserRequest_Email{Email: tutils.RandomEmail()}
+	_, err = srv.UnlockUser(ctx, req)
+	assert.Error(t, err)
+	// not locked
+	u, _ := tcore.TestUser(t, srv.API, "", false)
+	req.User = &admin.UnlockUserRequest_UserId{UserId: u.ID.String()}
+	_, err = srv.UnlockUser(ctx, req)
+	assert.Error(t, err)
+	// success id
+	err = users.LockUser(conn, u)
+	require.NoError(t, err)
+	res, err := srv.UnlockUser(ctx, req)
+	assert.NoError(t, err)
+	require.NotNil(t, res)
+	assert.Equal(t, u.ID.String(), res.GetUserId())
+	u, err = srv.GetUser(u.ID)
+	require.NoError(t, err)
+	assert.False(t, u.IsLocked())
+	// success email
+	err = users.LockUser(conn, u)
+	require.NoError(t, err)
+	req.User = &admin.UnlockUserRequest_Email{Email: u.Email}
+	res, err = srv.UnlockUser(ctx, req)
+	assert.NoError(t, err)
+	require.NotNil(t, res)
+	assert.Equal(t, u.ID.String(), res.GetUserId())
+	u, err = srv.GetUser(u.ID)
+	require.NoError(t, err)
+	assert.False(t, u.IsLocked())
+}
+
+func TestAdminServer_ForcePasswordChange(t *testing.T) {
+	t.Parallel()
+	s, _ := tsrv.RPCServer(t, false)
+	srv := newAdminServer(s)
+	ctx := rootContext(srv.Config())
+	// nil request
+	_, err := srv.ForcePasswordChange(ctx, nil)
+	assert.Error(t, err)
+	// no params
+	req := &admin.ForcePasswordChangeRequest{}
+	_, err = srv.ForcePasswordChange(ctx, req)
+	assert.Error(t, err)
+	// bad root password
+	req.User = &admin.ForcePasswordChangeRequest_UserId{UserId: uuid.Nil.String()}
+	bad := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(rpc.RootPassword, "bad"))
+	_, err = srv.ForcePasswordChange(bad, req)
+	assert.Error(t, err)
+	// nil user id
+	_, err = srv.ForcePasswordChange(ctx, req)
+	assert.Error(t, err)
+	// bad user id
+	req.User = &admin.ForcePasswordChangeRequest_UserId{UserId: uuid.New().String()}
+	_, err = srv.ForcePasswordChange(ctx, req)
+	assert.Error(t, err)
+	// bad email
+	req.User = &admin.ForcePasswordChangeRequest_Email{Email: tutils.RandomEmail()}
+	_, err = srv.ForcePasswordChange(ctx, req)
+	assert.Error(t, err)
+	// success id
+	u, _ := tcore.TestUser(t, srv.API, "", false)
+	req.User = &admin.ForcePasswordChangeRequest_UserId{UserId: u.ID.String()}
+	res, err := srv.ForcePasswordChange(ctx, req)
+	assert.NoError(t, err)
+	require.NotNil(t, res)
+	assert.Equal(t, u.ID.String(), res.GetUserId())
+	u, err = srv.GetUser(u.ID)
+	require.NoError(t, err)
+	assert.True(t, u.MustChangePassword)
+	// success email
+	u2, _ := tcore.TestUser(t, srv.API, "", false)
+	req.User = &admin.ForcePasswordChangeRequest_Email{Email: u2.Email}
+	res, err = srv.ForcePasswordChange(ctx, req)
+	assert.NoError(t, err)
+	require.NotNil(t, res)
+	assert.Equal(t, u2.ID.String(), res.GetUserId())
+}
+
+func TestAdminServer_ImpersonateUser(t *testing.T) {
+	t.Parallel()
+	s, _ := tsrv.RPCServer(t, false)
+	srv := newAdminServer(s)
+	ctx := rootContext(srv.Config())
+	// nil request
+	_, err := srv.ImpersonateUser(ctx, nil)
+	assert.Error(t, err)
+	// no user id
+	req := &admin.ImpersonateUserRequest{}
+	_, err = srv.ImpersonateUser(ctx, req)
+	assert.Error(t, err)
+	// bad root password
+	u, _ := tcore.TestUser(t, srv.API, "", false)
+	req.UserId = u.ID.String()
+	bad := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(rpc.RootPassword, "bad"))
+	_, err = srv.ImpersonateUser(bad, req)
+	assert.Error(t, err)
+	// user not found
+	_, err = srv.ImpersonateUser(ctx, &admin.ImpersonateUserRequest{
+		UserId: uuid.New().String(),
+	})
+	assert.Error(t, err)
+	// admins cannot be impersonated
+	adm, _ := tcore.TestUser(t, srv.API, "", true)
+	_, err = srv.ImpersonateUser(ctx, &admin.ImpersonateUserRequest{
+		UserId: adm.ID.String(),
+	})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	res, err := srv.ImpersonateUser(ctx, req)
+	require.NoError(t, err)
+	assert.Empty(t, res.GetRefresh())
+	assert.NotNil(t, res.GetExpiresAt())
+	claims, err := jwt.ParseUserClaims(srv.Config().JWT, res.GetAccess())
+	require.NoError(t, err)
+	assert.Equal(t, u.ID, claims.UserID())
+	assert.Equal(t, user.SuperAdminID, claims.ActorID())
+}
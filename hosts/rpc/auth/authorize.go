@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jrapoport/gothic/api/grpc/rpc"
+	"github.com/jrapoport/gothic/api/grpc/rpc/auth"
+	core "github.com/jrapoport/gothic/core/auth"
+	"github.com/jrapoport/gothic/hosts/rpc"
+	"github.com/jrapoport/gothic/models/types/provider"
+	"google.golang.org/grpc/codes"
+)
+
+// GetAuthorizationURL returns an auth url for a provider. gRPC clients
+// can't hold a cookie, so the auth url is bound to the client with a
+// PKCE code challenge.
+func (s *server) GetAuthorizationURL(ctx context.Context,
+	req *auth.AuthorizationURLRequest) (*auth.AuthorizationURLResponse, error) {
+	if req == nil {
+		err := errors.New("request not found")
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	p := provider.Name(req.GetProvider())
+	if !p.IsExternal() {
+		err := fmt.Errorf("invalid provider: %s", p)
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	if req.GetCodeChallenge() == "" {
+		err := errors.New("code challenge required")
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	s.Debugf("get authorization url for %s: %v", p, req)
+	rtx := rpc.RequestContext(ctx)
+	rtx.SetProvider(p)
+	au, err := s.API.GetAuthorizationURL(rtx, p, core.Binding{
+		CodeChallenge:   req.GetCodeChallenge(),
+		ChallengeMethod: req.GetCodeChallengeMethod(),
+	})
+	if err != nil {
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	s.Debugf("got authorization url: %s", au)
+	return &auth.AuthorizationURLResponse{Url: au}, nil
+}
+
+// AuthorizeUser authorizes a user with the state & data returned by a
+// provider, and the PKCE code verifier for the auth url.
+func (s *server) AuthorizeUser(ctx context.Context,
+	req *auth.AuthorizeUserRequest) (*api.UserResponse, error) {
+	if req == nil {
+		err := errors.New("request not found")
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	if req.GetState() == "" {
+		err := errors.New("state not found")
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx := rpc.RequestContext(ctx)
+	data := req.GetData().AsMap()
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	u, err := s.API.AuthorizeUser(rtx, req.GetState(), data, core.Verifier{
+		CodeVerifier: req.GetCodeVerifier(),
+	})
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	res, err := rpc.NewUserResponse(u)
+	if err != nil {
+		return nil, s.RPCError(codes.Internal, err)
+	}
+	if s.Config().MaskEmails {
+		res.MaskEmail()
+	}
+	bt, err := s.GrantBearerToken(rtx, u)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	res.Token = rpc.NewBearerResponse(bt)
+	s.Debugf("authorized user: %s", u.ID)
+	return (*api.UserResponse)(res), nil
+}
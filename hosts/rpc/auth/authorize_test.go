@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"testing"
+
+	"github.com/jrapoport/gothic/api/grpc/rpc/auth"
+	"github.com/jrapoport/gothic/jwt"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/types/key"
+	"github.com/jrapoport/gothic/models/types/provider"
+	"github.com/jrapoport/gothic/test/tconf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestAuthServer_AuthorizeUser(t *testing.T) {
+	t.Parallel()
+	const verifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	srv := testServer(t)
+	_, mock := tconf.MockedProvider(t, srv.Config(), "")
+	srv.Providers().UseProviders(mock)
+	p := provider.Name(mock.Name())
+	ctx := context.Background()
+	sum := sha256.Sum256([]byte(verifier))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+	// invalid req
+	_, err := srv.GetAuthorizationURL(ctx, nil)
+	assert.Error(t, err)
+	// bad provider
+	_, err = srv.GetAuthorizationURL(ctx, &auth.AuthorizationURLRequest{
+		Provider:      "bad",
+		CodeChallenge: challenge,
+	})
+	assert.Error(t, err)
+	// no code challenge
+	_, err = srv.GetAuthorizationURL(ctx, &auth.AuthorizationURLRequest{
+		Provider: p.String(),
+	})
+	assert.Error(t, err)
+	// bad code challenge method
+	_, err = srv.GetAuthorizationURL(ctx, &auth.AuthorizationURLRequest{
+		Provider:            p.String(),
+		CodeChallenge:       challenge,
+		CodeChallengeMethod: "bad",
+	})
+	assert.Error(t, err)
+	res, err := srv.GetAuthorizationURL(ctx, &auth.AuthorizationURLRequest{
+		Provider:            p.String(),
+		CodeChallenge:       challenge,
+		CodeChallengeMethod: token.ChallengeS256,
+	})
+	require.NoError(t, err)
+	au, err := url.Parse(res.Url)
+	require.NoError(t, err)
+	state := au.Query().Get(key.State)
+	require.NotEmpty(t, state)
+	data, err := structpb.NewStruct(map[string]interface{}{
+		key.Role: au.Query().Get(key.Role),
+	})
+	require.NoError(t, err)
+	// invalid req
+	_, err = srv.AuthorizeUser(ctx, nil)
+	assert.Error(t, err)
+	// no state
+	_, err = srv.AuthorizeUser(ctx, &auth.AuthorizeUserRequest{
+		CodeVerifier: verifier,
+		Data:         data,
+	})
+	assert.Error(t, err)
+	// no code verifier
+	_, err = srv.AuthorizeUser(ctx, &auth.AuthorizeUserRequest{
+		State: state,
+		Data:  data,
+	})
+	assert.Error(t, err)
+	// bad code verifier
+	_, err = srv.AuthorizeUser(ctx, &auth.AuthorizeUserRequest{
+		State:        state,
+		CodeVerifier: challenge,
+		Data:         data,
+	})
+	assert.Error(t, err)
+	req := &auth.AuthorizeUserRequest{
+		State:        state,
+		CodeVerifier: verifier,
+		Data:         data,
+	}
+	ur, err := srv.AuthorizeUser(ctx, req)
+	require.NoError(t, err)
+	require.NotNil(t, ur.Token)
+	claims, err := jwt.ParseUserClaims(srv.Config().JWT, ur.Token.Access)
+	require.NoError(t, err)
+	assert.Equal(t, ur.UserId, claims.Subject())
+	assert.Equal(t, p, claims.Provider())
+	// the state is single use
+	_, err = srv.AuthorizeUser(ctx, req)
+	assert.Error(t, err)
+}
@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/jrapoport/gothic/api/grpc/rpc/auth"
+	"github.com/segmentio/encoding/json"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func (s *server) GetJSONWebKeys(_ context.Context,
+	_ *emptypb.Empty) (*auth.JSONWebKeySetResponse, error) {
+	set, err := s.API.PublicKeys()
+	if err != nil {
+		return nil, s.RPCError(codes.Internal, err)
+	}
+	b, err := json.Marshal(set)
+	if err != nil {
+		return nil, s.RPCError(codes.Internal, err)
+	}
+	var jwks struct {
+		Keys []map[string]interface{} `json:"keys"`
+	}
+	err = json.Unmarshal(b, &jwks)
+	if err != nil {
+		return nil, s.RPCError(codes.Internal, err)
+	}
+	res := &auth.JSONWebKeySetResponse{
+		Keys: make([]*structpb.Struct, len(jwks.Keys)),
+	}
+	for i, key := range jwks.Keys {
+		res.Keys[i], err = structpb.NewStruct(key)
+		if err != nil {
+			return nil, s.RPCError(codes.Internal, err)
+		}
+	}
+	return res, nil
+}
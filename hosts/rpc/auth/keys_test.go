@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/test/tconf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestAuthServer_GetJSONWebKeys(t *testing.T) {
+	t.Parallel()
+	srv := testServer(t)
+	ctx := context.Background()
+	// secrets are never published
+	res, err := srv.GetJSONWebKeys(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	assert.Empty(t, res.Keys)
+	tconf.RSAKeys(t, srv.Config())
+	res, err = srv.GetJSONWebKeys(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	require.Len(t, res.Keys, 1)
+	key := res.Keys[0].AsMap()
+	assert.Equal(t, tconf.RSAKeyID, key["kid"])
+	assert.Equal(t, "RS256", key["alg"])
+	assert.Equal(t, "RSA", key["kty"])
+	assert.Equal(t, "sig", key["use"])
+	assert.NotEmpty(t, key["n"])
+	assert.NotContains(t, key, "d")
+}
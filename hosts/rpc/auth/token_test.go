@@ -15,6 +15,7 @@ import (
 func TestAuthServer_RefreshBearerToken(t *testing.T) {
 	t.Parallel()
 	srv := testServer(t)
+	srv.Config().Refresh.Grace = 0
 	ctx := context.Background()
 	// invalid req
 	_, err := srv.RefreshBearerToken(ctx, nil)
@@ -22,7 +22,7 @@ func newHealthServer(srv *rpc.Server) *healthServer {
 }
 
 // RegisterServer registers a new health server.
-func RegisterServer(s *grpc.Server, e *rpc.Server) {
+func RegisterServer(s grpc.ServiceRegistrar, e *rpc.Server) {
 	healthpb.RegisterHealthServer(s, newHealthServer(e))
 }
 
@@ -14,7 +14,7 @@ import (
 )
 
 // RegisterServer is the function prototype for registering an rpc server.
-type RegisterServer func(s *grpc.Server, srv *Server)
+type RegisterServer func(s grpc.ServiceRegistrar, srv *Server)
 
 // Host represents a gRPC host.
 type Host struct {
@@ -31,6 +31,15 @@ func Authentication() grpc.ServerOption {
 	return authOption{}
 }
 
+func hasAuthentication(opt []grpc.ServerOption) bool {
+	for _, o := range opt {
+		if _, ok := o.(authOption); ok {
+			return true
+		}
+	}
+	return false
+}
+
 // NewHost creates a new Host.
 func NewHost(a *core.API, name string, address string, reg []RegisterServer, opt ...grpc.ServerOption) *Host {
 	h := core.NewHost(a, name, address)
@@ -59,7 +68,12 @@ func NewHost(a *core.API, name string, address string, reg []RegisterServer, opt
 		unary = append(unary, grpc_opentracing.UnaryServerInterceptor())
 		stream = append(stream, grpc_opentracing.StreamServerInterceptor())
 	}
-
+	if a.TenantsEnabled() {
+		// tenant calls are dispatched before the host authenticates them
+		td := newTenantDispatch(h, reg, hasAuthentication(opt))
+		unary = append(unary, td.UnaryServerInterceptor())
+		stream = append(stream, td.StreamServerInterceptor())
+	}
 	for _, o := range opt {
 		switch o.(type) {
 		case authOption:
@@ -67,7 +81,8 @@ func NewHost(a *core.API, name string, address string, reg []RegisterServer, opt
 			//if s.Config().IsDebug() {
 			//	break
 			//}
-			auth := NewAuthenticator(h.Config().JWT, h.Log())
+			auth := NewAuthenticator(h.Config().JWT, a.ValidateBearerToken, a.ValidatePersonalToken, h.Log())
+			auth.UseImpersonationLog(a.LogImpersonation)
 			unary = append(unary, auth.UnaryServerInterceptor())
 			stream = append(stream, auth.StreamServerInterceptor())
 		}
@@ -3,12 +3,15 @@ package rpc
 import (
 	"context"
 	"errors"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/grpc-ecosystem/go-grpc-middleware/auth"
 	"github.com/jrapoport/gothic/config"
+	core_ctx "github.com/jrapoport/gothic/core/context"
 	"github.com/jrapoport/gothic/jwt"
 	"github.com/jrapoport/gothic/log"
+	"github.com/jrapoport/gothic/models/token"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -20,16 +23,37 @@ const (
 	BearerScheme  = "bearer"
 )
 
+// RevocationCheck returns an error if the jwt token for the claims has been revoked.
+type RevocationCheck func(claims *jwt.UserClaims) error
+
+// PersonalTokenCheck returns the claims for a personal access token used from
+// the ip address, or an error if the personal access token is not valid.
+type PersonalTokenCheck func(tok, ip string) (*jwt.UserClaims, error)
+
+// ImpersonationLog records a call made while impersonating a user.
+type ImpersonationLog func(ctx core_ctx.Context, method, path string) error
+
 // Authenticator is a jwt authenticator
 type Authenticator struct {
-	c   config.JWT
-	log log.Logger
+	c        config.JWT
+	revoked  RevocationCheck
+	personal PersonalTokenCheck
+	imp      ImpersonationLog
+	log      log.Logger
 }
 
-// NewAuthenticator returns a new jwt authenticator
-func NewAuthenticator(c config.JWT, log log.Logger) *Authenticator {
+// NewAuthenticator returns a new jwt authenticator. If the revocation check
+// is not nil, revoked tokens are rejected. If the personal token check is not
+// nil, personal access tokens are accepted.
+func NewAuthenticator(c config.JWT, revoked RevocationCheck, personal PersonalTokenCheck, log log.Logger) *Authenticator {
 	log = log.WithName("grpc-jwt")
-	return &Authenticator{c, log}
+	return &Authenticator{c: c, revoked: revoked, personal: personal, log: log}
+}
+
+// UseImpersonationLog sets the log used to record
+// the calls made while impersonating a user.
+func (a *Authenticator) UseImpersonationLog(log ImpersonationLog) {
+	a.imp = log
 }
 
 // UnaryServerInterceptor returns a unary interceptor for jwt authentication.
@@ -43,27 +67,102 @@ func (a *Authenticator) StreamServerInterceptor() grpc.StreamServerInterceptor {
 }
 
 func (a *Authenticator) authFunc(ctx context.Context) (context.Context, error) {
-	return Authenticate(ctx, a.c)
+	ctx, err := Authenticate(ctx, a.c, a.revoked, a.personal)
+	if err != nil {
+		return ctx, err
+	}
+	return ctx, a.impersonation(ctx)
 }
 
-// Authenticate parses the jwt claims and authenticates a grpc request
-func Authenticate(ctx context.Context, c config.JWT) (context.Context, error) {
+// impersonation records the calls made while impersonating
+// a user. If the call cannot be recorded it is not allowed.
+func (a *Authenticator) impersonation(ctx context.Context) error {
+	uc, err := GetUserClaims(ctx)
+	if a.imp == nil || err != nil || !uc.Impersonated() {
+		return nil
+	}
+	method, _ := grpc.Method(ctx)
+	err = a.imp(RequestContext(ctx), method, "")
+	if err != nil {
+		a.log.Error(err)
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+// Authenticate parses the jwt claims and authenticates a grpc request.
+// If the revocation check is not nil, revoked tokens are rejected. If
+// the personal token check is not nil, personal access tokens are
+// accepted and the calls they make are limited to their scopes.
+func Authenticate(ctx context.Context, c config.JWT, revoked RevocationCheck, personal PersonalTokenCheck) (context.Context, error) {
 	claims := GetClaims(ctx)
 	if claims != nil {
 		return ctx, nil
 	}
-	token, err := grpc_auth.AuthFromMD(ctx, BearerScheme)
+	tok, err := grpc_auth.AuthFromMD(ctx, BearerScheme)
 	if err != nil {
 		return ctx, err
 	}
-	claims, err = parseToken(c, token)
+	if personal != nil && token.IsPersonalToken(tok) {
+		return authenticatePersonal(ctx, tok, personal)
+	}
+	uc, err := parseToken(c, tok)
 	if err != nil {
 		return ctx, status.Error(codes.Unauthenticated, err.Error())
 	}
-	return WithClaims(ctx, claims), nil
+	if revoked != nil {
+		err = revoked(uc)
+		if err != nil {
+			return ctx, status.Error(codes.Unauthenticated, err.Error())
+		}
+	}
+	return WithClaims(ctx, uc), nil
+}
+
+func authenticatePersonal(ctx context.Context, tok string, personal PersonalTokenCheck) (context.Context, error) {
+	uc, err := personal(tok, GetRemoteIP(ctx))
+	if err != nil {
+		return ctx, status.Error(codes.Unauthenticated, err.Error())
+	}
+	method, _ := grpc.Method(ctx)
+	err = personalScope(method, uc)
+	if err != nil {
+		return ctx, status.Error(codes.PermissionDenied, err.Error())
+	}
+	return WithClaims(ctx, uc), nil
+}
+
+// sessionMethods are the methods that manage the sessions or tokens of
+// the user. Personal access tokens cannot call them, whatever their scopes.
+var sessionMethods = map[string]bool{
+	"ListSessions":        true,
+	"RevokeSession":       true,
+	"RevokeOtherSessions": true,
+	"CreatePersonalToken": true,
+	"ListPersonalTokens":  true,
+	"RevokePersonalToken": true,
+}
+
+// personalScope returns an error if the scopes of a personal access token
+// do not allow the call. The read scope only allows calls that do not change
+// anything (Get, List & Search methods), the write scope allows all calls.
+func personalScope(method string, claims *jwt.UserClaims) error {
+	name := method[strings.LastIndex(method, "/")+1:]
+	if sessionMethods[name] {
+		return errors.New("personal token not allowed")
+	}
+	if claims.HasScope(token.ScopeWrite) {
+		return nil
+	}
+	for _, prefix := range []string{"Get", "List", "Search"} {
+		if strings.HasPrefix(name, prefix) && claims.HasScope(token.ScopeRead) {
+			return nil
+		}
+	}
+	return errors.New("insufficient token scope")
 }
 
-func parseToken(c config.JWT, tok string) (jwt.Claims, error) {
+func parseToken(c config.JWT, tok string) (*jwt.UserClaims, error) {
 	claims, err := jwt.ParseUserClaims(c, tok)
 	if err != nil {
 		return nil, err
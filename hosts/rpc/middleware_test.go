@@ -0,0 +1,150 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	core_ctx "github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/jwt"
+	"github.com/jrapoport/gothic/log"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconf"
+	"github.com/jrapoport/gothic/test/tutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type methodStream struct {
+	grpc.ServerTransportStream
+	method string
+}
+
+func (s methodStream) Method() string {
+	return s.method
+}
+
+func methodContext(method, tok string) context.Context {
+	md := metadata.Pairs(Authorization, BearerScheme+" "+tok)
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+	return grpc.NewContextWithServerTransportStream(ctx, methodStream{method: method})
+}
+
+func TestAuthenticate_PersonalToken(t *testing.T) {
+	t.Parallel()
+	c := tconf.Config(t)
+	u := &user.User{
+		ID:     uuid.New(),
+		Role:   user.RoleAdmin,
+		Status: user.Active,
+		Email:  tutils.RandomEmail(),
+	}
+	u.CreatedAt = time.Now()
+	tokens := map[string]*token.PersonalToken{}
+	grant := func(scope string) string {
+		pt := token.NewPersonalToken(u.ID, "test", scope, 0)
+		tokens[pt.Secret] = pt
+		return pt.Secret
+	}
+	revoked := func(*jwt.UserClaims) error {
+		return errors.New("revoked")
+	}
+	personal := func(tok, _ string) (*jwt.UserClaims, error) {
+		pt, ok := tokens[tok]
+		if !ok {
+			return nil, errors.New("invalid token")
+		}
+		return jwt.NewPersonalClaims(u, pt), nil
+	}
+	const (
+		getUser    = "/gothic.api.User/GetUser"
+		updateUser = "/gothic.api.User/UpdateUser"
+	)
+	tests := []struct {
+		scope  string
+		method string
+		code   codes.Code
+	}{
+		{token.ScopeRead, getUser, codes.OK},
+		{token.ScopeRead, "/gothic.api.User/ListSessions", codes.PermissionDenied},
+		{token.ScopeWrite, "/gothic.api.User/RevokeOtherSessions", codes.PermissionDenied},
+		{token.ScopeWrite, "/gothic.api.User/CreatePersonalToken", codes.PermissionDenied},
+		{token.ScopeWrite, "/gothic.api.User/RevokePersonalToken", codes.PermissionDenied},
+		{token.ScopeRead, updateUser, codes.PermissionDenied},
+		{token.ScopeWrite, getUser, codes.OK},
+		{token.ScopeWrite, updateUser, codes.OK},
+		{token.ScopeAdmin, getUser, codes.PermissionDenied},
+	}
+	for _, test := range tests {
+		tok := grant(test.scope)
+		ctx, err := Authenticate(methodContext(test.method, tok), c.JWT, revoked, personal)
+		assert.Equal(t, test.code, status.Code(err))
+		if test.code != codes.OK {
+			continue
+		}
+		uc, err := GetUserClaims(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, u.ID, uc.UserID())
+		assert.True(t, uc.PersonalToken())
+		assert.False(t, uc.Admin())
+	}
+	// unknown personal token
+	ctx := methodContext(getUser, token.PersonalTokenPrefix+"bad")
+	_, err := Authenticate(ctx, c.JWT, revoked, personal)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	// personal tokens are not accepted without a check
+	ctx = methodContext(getUser, grant(token.ScopeWrite))
+	_, err = Authenticate(ctx, c.JWT, nil, nil)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	// the admin scope makes admin claims
+	ctx = methodContext(getUser, grant(token.ScopeRead+" "+token.ScopeAdmin))
+	ctx, err = Authenticate(ctx, c.JWT, revoked, personal)
+	require.NoError(t, err)
+	assert.True(t, RequestContext(ctx).IsAdmin())
+}
+
+func TestAuthenticator_Impersonation(t *testing.T) {
+	t.Parallel()
+	c := tconf.Config(t)
+	uid := uuid.New()
+	aid := uuid.New()
+	const getUser = "/gothic.api.User/GetUser"
+	var logged []string
+	fail := false
+	auth := NewAuthenticator(c.JWT, nil, nil, log.New())
+	auth.UseImpersonationLog(func(ctx core_ctx.Context, method, _ string) error {
+		assert.Equal(t, uid, ctx.UserID())
+		assert.Equal(t, aid, ctx.ImpersonatorID())
+		assert.False(t, ctx.IsAdmin())
+		if fail {
+			return errors.New("log failed")
+		}
+		logged = append(logged, method)
+		return nil
+	})
+	// not impersonated
+	claims := jwt.NewUserClaims(&user.User{ID: uid})
+	tok, err := jwt.NewToken(c.JWT, claims).Bearer()
+	require.NoError(t, err)
+	_, err = auth.authFunc(methodContext(getUser, tok))
+	assert.NoError(t, err)
+	assert.Empty(t, logged)
+	// impersonated
+	claims.SetActor(aid)
+	tok, err = jwt.NewToken(c.JWT, claims).Bearer()
+	require.NoError(t, err)
+	_, err = auth.authFunc(methodContext(getUser, tok))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{getUser}, logged)
+	// calls that are not logged are not allowed
+	fail = true
+	_, err = auth.authFunc(methodContext(getUser, tok))
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
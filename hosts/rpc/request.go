@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"strings"
 
 	"github.com/google/uuid"
@@ -19,6 +20,8 @@ const (
 	ForwardedFor   = "X-Forwarded-For"
 	ReCaptchaToken = "X-ReCaptcha-Token"
 	RootPassword   = "X-Root-Password"
+	UserAgent      = "User-Agent"
+	DeviceName     = "X-Device-Name"
 )
 
 // RequestContext adds the request context to a context
@@ -27,7 +30,12 @@ func RequestContext(ctx context.Context) core_ctx.Context {
 	rtx.SetIPAddress(GetRemoteIP(rtx))
 	if c, err := GetUserClaims(rtx); err == nil {
 		rtx.SetProvider(c.Provider())
-		if c.Admin() {
+		rtx.SetSessionID(c.SessionID())
+		rtx.SetTokenID(c.JwtID())
+		rtx.SetOrgID(c.OrgID())
+		if c.Impersonated() {
+			rtx.SetImpersonatorID(c.ActorID())
+		} else if c.Privileged() {
 			rtx.SetAdminID(c.UserID())
 		}
 		rtx.SetUserID(c.UserID())
@@ -39,6 +47,8 @@ func RequestContext(ctx context.Context) core_ctx.Context {
 	if v := getMetadata(md, ReCaptchaToken); v != "" {
 		rtx.SetReCaptcha(v)
 	}
+	rtx.SetUserAgent(getMetadata(md, UserAgent))
+	rtx.SetDeviceName(getMetadata(md, DeviceName))
 	return rtx
 }
 
@@ -61,7 +71,12 @@ func GetRemoteIP(ctx context.Context) string {
 	if !ok {
 		return ""
 	}
-	return pr.Addr.String()
+	addr := pr.Addr.String()
+	ip, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return ip
 }
 
 func realIP(ctx context.Context) string {
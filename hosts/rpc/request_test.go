@@ -2,10 +2,12 @@ package rpc
 
 import (
 	"context"
+	"net"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 )
 
 func TestRequestContext(t *testing.T) {
@@ -16,9 +18,19 @@ func TestRequestContext(t *testing.T) {
 	ctx = metadata.NewIncomingContext(ctx, metadata.New(map[string]string{
 		ForwardedFor:   "127.0.0.1, 198.168.1.1",
 		ReCaptchaToken: "1234",
+		UserAgent:      "grpc-go/1.36.0",
+		DeviceName:     "laptop",
 	}))
 	rtx = RequestContext(ctx)
 	assert.NotNil(t, rtx)
 	assert.Equal(t, "127.0.0.1", rtx.IPAddress())
 	assert.Equal(t, "1234", rtx.ReCaptcha())
+	assert.Equal(t, "grpc-go/1.36.0", rtx.UserAgent())
+	assert.Equal(t, "laptop", rtx.DeviceName())
+	// the peer address without the port
+	ctx = peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 5432},
+	})
+	rtx = RequestContext(ctx)
+	assert.Equal(t, "10.0.0.1", rtx.IPAddress())
 }
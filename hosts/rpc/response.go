@@ -2,7 +2,9 @@ package rpc
 
 import (
 	"github.com/jrapoport/gothic/api/grpc/rpc"
+	"github.com/jrapoport/gothic/core/credentials"
 	"github.com/jrapoport/gothic/core/tokens"
+	"github.com/jrapoport/gothic/models/token"
 	"github.com/jrapoport/gothic/models/user"
 	"github.com/jrapoport/gothic/utils"
 	"google.golang.org/protobuf/types/known/structpb"
@@ -18,13 +20,17 @@ func NewUserResponse(u *user.User) (*UserResponse, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &UserResponse{
+	res := &UserResponse{
 		UserId:   u.ID.String(),
 		Role:     u.Role.String(),
 		Email:    u.Email,
 		Username: u.Username,
 		Data:     data,
-	}, nil
+	}
+	if u.HasPhone() {
+		res.Phone = *u.Phone
+	}
+	return res, nil
 }
 
 // MaskEmail masks Personally Identifiable Information (PII) from the user response
@@ -35,12 +41,50 @@ func (r *UserResponse) MaskEmail() {
 // NewBearerResponse returns a BearerResponse from a BearerToken
 func NewBearerResponse(bt *tokens.BearerToken) *api.BearerResponse {
 	res := &api.BearerResponse{
-		Type:    bt.Class().String(),
-		Access:  bt.String(),
-		Refresh: bt.RefreshToken.String(),
+		Type:   bt.Class().String(),
+		Access: bt.String(),
+	}
+	// impersonation tokens cannot be refreshed
+	if bt.RefreshToken != nil {
+		res.Refresh = bt.RefreshToken.String()
 	}
 	if bt.ExpiredAt != nil {
 		res.ExpiresAt = timestamppb.New(*bt.ExpiredAt)
 	}
 	return res
 }
+
+// NewWebAuthnResponse returns a WebAuthnResponse from a Ceremony
+func NewWebAuthnResponse(c *credentials.Ceremony) *api.WebAuthnResponse {
+	res := &api.WebAuthnResponse{
+		Token:   c.Token,
+		Options: string(c.Options),
+	}
+	if !c.ExpiresAt.IsZero() {
+		res.ExpiresAt = timestamppb.New(c.ExpiresAt)
+	}
+	return res
+}
+
+// NewMFAResponse returns an MFAResponse from an MFAToken
+func NewMFAResponse(mt *token.MFAToken) *api.MFAResponse {
+	res := &api.MFAResponse{
+		Type:  mt.Class().String(),
+		Token: mt.String(),
+	}
+	if mt.ExpiredAt != nil {
+		res.ExpiresAt = timestamppb.New(*mt.ExpiredAt)
+	}
+	return res
+}
+
+// NewPasswordChangeResponse returns a PasswordChangeResponse from a PasswordChangeToken
+func NewPasswordChangeResponse(pt *token.PasswordChangeToken) *api.PasswordChangeResponse {
+	res := &api.PasswordChangeResponse{
+		Token: pt.String(),
+	}
+	if pt.ExpiredAt != nil {
+		res.ExpiresAt = timestamppb.New(*pt.ExpiredAt)
+	}
+	return res
+}
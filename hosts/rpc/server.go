@@ -5,7 +5,10 @@ import (
 	"errors"
 
 	"github.com/jrapoport/gothic/core"
+	"github.com/jrapoport/gothic/core/validate"
+	"github.com/jrapoport/gothic/models/rbac"
 	"github.com/jrapoport/gothic/models/user"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -21,25 +24,33 @@ func NewServer(s *core.Server) *Server {
 }
 
 // ValidateAdmin re-checks that a token belongs to an active admin user
-func (s *Server) ValidateAdmin(ctx context.Context) (user.Role, error) {
+// with the permission.
+func (s *Server) ValidateAdmin(ctx context.Context, perm rbac.Permission) (user.Role, error) {
 	rtx := RequestContext(ctx)
 	aid := rtx.AdminID()
 	if !rtx.IsAdmin() || aid == user.SystemID {
 		err := errors.New("admin user id not found")
 		return user.InvalidRole, err
 	}
-	role, err := s.API.ValidateAdmin(aid)
+	role, err := s.API.ValidateAdmin(aid, perm)
 	if err != nil {
 		return user.InvalidRole, err
 	}
 	return role, nil
 }
 
-// RPCError wraps an rpc error code.
+// RPCError wraps an rpc error code. Password policy violations are returned
+// as codes.InvalidArgument with the violations as bad request details.
 func (s *Server) RPCError(c codes.Code, err error) error {
 	if c == codes.OK {
 		return nil
 	}
+	var pe *validate.PasswordError
+	if errors.As(err, &pe) {
+		err = PasswordError(pe)
+		s.Error(err)
+		return err
+	}
 	msg := statusText(c)
 	if err != nil {
 		msg = err.Error()
@@ -49,6 +60,25 @@ func (s *Server) RPCError(c codes.Code, err error) error {
 	return err
 }
 
+// PasswordError returns an InvalidArgument rpc error for the password
+// policy violations. Each violation is a password field violation.
+func PasswordError(pe *validate.PasswordError) error {
+	st := status.New(codes.InvalidArgument, "invalid password")
+	br := &errdetails.BadRequest{}
+	for _, v := range pe.Violations {
+		br.FieldViolations = append(br.FieldViolations,
+			&errdetails.BadRequest_FieldViolation{
+				Field:       "password",
+				Description: string(v),
+			})
+	}
+	ds, err := st.WithDetails(br)
+	if err != nil {
+		return st.Err()
+	}
+	return ds.Err()
+}
+
 /* This remains unused so commenting it out for now
 // RPCErrorf wraps an rpc error code formatted according to a format specifier.
 func (s *Server) RPCErrorf(c codes.Code, format string, a ...interface{}) error {
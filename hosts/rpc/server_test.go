@@ -2,12 +2,17 @@ package rpc
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"testing"
 
+	"github.com/jrapoport/gothic/core/validate"
 	"github.com/jrapoport/gothic/test/tcore"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestServer_RPCError(t *testing.T) {
@@ -45,3 +50,26 @@ func TestServer_RPCError(t *testing.T) {
 	assert.True(t, strings.HasSuffix(err.Error(), "test"))
 	assert.Equal(t, "ok", statusText(codes.OK))
 }
+
+func TestServer_RPCError_Password(t *testing.T) {
+	s, _ := tcore.Server(t, false)
+	srv := NewServer(s)
+	pe := &validate.PasswordError{Violations: []validate.PasswordViolation{
+		validate.PasswordTooShort,
+		validate.PasswordMissingDigit,
+	}}
+	err := srv.RPCError(codes.PermissionDenied, fmt.Errorf("password: %w", pe))
+	require.Error(t, err)
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	require.Len(t, st.Details(), 1)
+	br, ok := st.Details()[0].(*errdetails.BadRequest)
+	require.True(t, ok)
+	require.Len(t, br.GetFieldViolations(), 2)
+	for i, v := range pe.Violations {
+		fv := br.GetFieldViolations()[i]
+		assert.Equal(t, "password", fv.GetField())
+		assert.Equal(t, string(v), fv.GetDescription())
+	}
+}
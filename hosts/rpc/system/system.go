@@ -19,6 +19,6 @@ func newSystemServer(srv *rpc.Server) *systemServer {
 }
 
 // RegisterServer registers a new admin server.
-func RegisterServer(s *grpc.Server, srv *rpc.Server) {
+func RegisterServer(s grpc.ServiceRegistrar, srv *rpc.Server) {
 	system.RegisterSystemServer(s, newSystemServer(srv))
 }
@@ -0,0 +1,176 @@
+package rpc
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/core"
+	"github.com/jrapoport/gothic/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Authority is the metadata key for the host of a request.
+const Authority = ":authority"
+
+type tenantMethod struct {
+	srv    interface{}
+	unary  *grpc.MethodDesc
+	stream *grpc.StreamDesc
+}
+
+// tenantServices holds the services registered for a tenant api.
+type tenantServices struct {
+	api     *core.API
+	methods map[string]tenantMethod
+	unary   grpc.UnaryServerInterceptor
+	stream  grpc.StreamServerInterceptor
+}
+
+var _ grpc.ServiceRegistrar = (*tenantServices)(nil)
+
+// RegisterService registers the methods of a tenant service.
+func (ts *tenantServices) RegisterService(sd *grpc.ServiceDesc, srv interface{}) {
+	for i := range sd.Methods {
+		md := &sd.Methods[i]
+		name := "/" + sd.ServiceName + "/" + md.MethodName
+		ts.methods[name] = tenantMethod{srv: srv, unary: md}
+	}
+	for i := range sd.Streams {
+		sd2 := &sd.Streams[i]
+		name := "/" + sd.ServiceName + "/" + sd2.StreamName
+		ts.methods[name] = tenantMethod{srv: srv, stream: sd2}
+	}
+}
+
+// tenantDispatch routes a call to the services of the tenant it resolves
+// to. Calls that do not resolve to a tenant are handled by the host. The
+// services of the tenants are registered lazily and replaced when the api
+// of the tenant is reloaded.
+type tenantDispatch struct {
+	api      *core.API
+	name     string
+	config   config.Tenants
+	reg      []RegisterServer
+	auth     bool
+	log      log.Logger
+	services map[uuid.UUID]*tenantServices
+	mu       sync.Mutex
+}
+
+func newTenantDispatch(h *core.Host, reg []RegisterServer, auth bool) *tenantDispatch {
+	return &tenantDispatch{
+		api:      h.API,
+		name:     h.Name(),
+		config:   h.Config().Tenants,
+		reg:      reg,
+		auth:     auth,
+		log:      h.Log(),
+		services: map[uuid.UUID]*tenantServices{},
+	}
+}
+
+// UnaryServerInterceptor returns a unary interceptor that dispatches
+// calls to the services of their tenant.
+func (td *tenantDispatch) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ts, err := td.resolve(ctx)
+		if err != nil {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		if ts == nil {
+			return handler(ctx, req)
+		}
+		m, ok := ts.methods[info.FullMethod]
+		if !ok || m.unary == nil {
+			return nil, status.Errorf(codes.Unimplemented, "unknown method %s", info.FullMethod)
+		}
+		dec := func(v interface{}) error {
+			proto.Merge(v.(proto.Message), req.(proto.Message))
+			return nil
+		}
+		return m.unary.Handler(m.srv, ctx, dec, ts.unary)
+	}
+}
+
+// StreamServerInterceptor returns a stream interceptor that dispatches
+// calls to the services of their tenant.
+func (td *tenantDispatch) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ts, err := td.resolve(ss.Context())
+		if err != nil {
+			return status.Error(codes.NotFound, err.Error())
+		}
+		if ts == nil {
+			return handler(srv, ss)
+		}
+		m, ok := ts.methods[info.FullMethod]
+		if !ok || m.stream == nil {
+			return status.Errorf(codes.Unimplemented, "unknown method %s", info.FullMethod)
+		}
+		if ts.stream == nil {
+			return m.stream.Handler(m.srv, ss)
+		}
+		return ts.stream(m.srv, ss, info, m.stream.Handler)
+	}
+}
+
+// resolve resolves the tenant for the call. The tenant is resolved from the
+// tenant metadata, and then the authority of the call. An unknown tenant in
+// the metadata is not found.
+func (td *tenantDispatch) resolve(ctx context.Context) (*tenantServices, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if id := getMetadata(md, td.config.Header); id != "" {
+		ta, err := td.tenantAPI(id)
+		if err != nil {
+			return nil, err
+		}
+		return td.tenantServices(ta), nil
+	}
+	ta, err := td.api.TenantAPIWithHost(getMetadata(md, Authority))
+	if err != nil {
+		// unknown hosts belong to the default tenant
+		return nil, nil
+	}
+	return td.tenantServices(ta), nil
+}
+
+func (td *tenantDispatch) tenantAPI(id string) (*core.API, error) {
+	tid, err := uuid.Parse(id)
+	if err == nil {
+		return td.api.TenantAPI(tid)
+	}
+	return td.api.TenantAPIWithName(id)
+}
+
+func (td *tenantDispatch) tenantServices(ta *core.API) *tenantServices {
+	td.mu.Lock()
+	defer td.mu.Unlock()
+	tid := ta.Tenant().ID
+	ts, ok := td.services[tid]
+	if ok && ts.api == ta {
+		return ts
+	}
+	s := core.NewServer(ta, td.name)
+	ts = &tenantServices{
+		api:     ta,
+		methods: map[string]tenantMethod{},
+	}
+	if td.auth {
+		auth := NewAuthenticator(s.Config().JWT, ta.ValidateBearerToken, ta.ValidatePersonalToken, td.log)
+		auth.UseImpersonationLog(ta.LogImpersonation)
+		ts.unary = auth.UnaryServerInterceptor()
+		ts.stream = auth.StreamServerInterceptor()
+	}
+	for _, r := range td.reg {
+		srv := NewServer(s.Clone())
+		r(ts, srv)
+	}
+	td.services[tid] = ts
+	return ts
+}
@@ -0,0 +1,63 @@
+package user
+
+import (
+	"context"
+
+	"github.com/jrapoport/gothic/api/grpc/rpc/user"
+	"github.com/jrapoport/gothic/hosts/rpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func (s *userServer) EnrollTOTP(ctx context.Context, _ *emptypb.Empty) (*user.EnrollTOTPResponse, error) {
+	uid, err := rpc.GetUserID(ctx)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	rtx := rpc.RequestContext(ctx)
+	s.Debugf("enroll totp: %s", uid)
+	e, err := s.API.EnrollTOTP(rtx, uid)
+	if err != nil {
+		return nil, s.RPCError(codes.FailedPrecondition, err)
+	}
+	res := &user.EnrollTOTPResponse{
+		Secret: e.Secret,
+		Url:    e.URL,
+		QrCode: e.QRCode,
+	}
+	return res, nil
+}
+
+func (s *userServer) ConfirmTOTP(ctx context.Context, req *user.TOTPRequest) (*emptypb.Empty, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	uid, err := rpc.GetUserID(ctx)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	rtx := rpc.RequestContext(ctx)
+	s.Debugf("confirm totp: %s", uid)
+	err = s.API.ConfirmTOTP(rtx, uid, req.GetCode())
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *userServer) DisableTOTP(ctx context.Context, req *user.TOTPRequest) (*emptypb.Empty, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	uid, err := rpc.GetUserID(ctx)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	rtx := rpc.RequestContext(ctx)
+	s.Debugf("disable totp: %s", uid)
+	err = s.API.DisableTOTP(rtx, uid, req.GetCode())
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	return &emptypb.Empty{}, nil
+}
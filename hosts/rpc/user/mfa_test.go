@@ -0,0 +1,59 @@
+package user
+
+import (
+	"testing"
+
+	"github.com/jrapoport/gothic/api/grpc/rpc/user"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/test/tcore"
+	"github.com/jrapoport/gothic/test/tsrv"
+	"github.com/pquerna/otp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestUserServer_TOTP(t *testing.T) {
+	t.Parallel()
+	srv := testServer(t)
+	ctx := context.Background()
+	// no id
+	_, err := srv.EnrollTOTP(ctx, &emptypb.Empty{})
+	assert.Error(t, err)
+	_, err = srv.ConfirmTOTP(ctx, &user.TOTPRequest{})
+	assert.Error(t, err)
+	_, err = srv.DisableTOTP(ctx, &user.TOTPRequest{})
+	assert.Error(t, err)
+	u, tok := tcore.TestUser(t, srv.API, "", false)
+	ctx = tsrv.RPCAuthContext(t, srv.Config(), tok)
+	// invalid req
+	_, err = srv.ConfirmTOTP(ctx, nil)
+	assert.Error(t, err)
+	_, err = srv.DisableTOTP(ctx, nil)
+	assert.Error(t, err)
+	res, err := srv.EnrollTOTP(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	k, err := otp.NewKeyFromURL(res.GetUrl())
+	require.NoError(t, err)
+	assert.Equal(t, u.Email, k.AccountName())
+	assert.Equal(t, res.GetSecret(), k.Secret())
+	assert.NotEmpty(t, res.GetQrCode())
+	// bad code
+	_, err = srv.ConfirmTOTP(ctx, &user.TOTPRequest{Code: "bad"})
+	assert.Error(t, err)
+	code := tcore.TOTPCode(t, res.GetSecret(), 0)
+	_, err = srv.ConfirmTOTP(ctx, &user.TOTPRequest{Code: code})
+	assert.NoError(t, err)
+	// already enrolled
+	_, err = srv.EnrollTOTP(ctx, &emptypb.Empty{})
+	assert.Error(t, err)
+	// bad code
+	_, err = srv.DisableTOTP(ctx, &user.TOTPRequest{Code: "bad"})
+	assert.Error(t, err)
+	code = tcore.TOTPCode(t, res.GetSecret(), 1)
+	_, err = srv.DisableTOTP(ctx, &user.TOTPRequest{Code: code})
+	assert.NoError(t, err)
+	mt, err := srv.GrantMFAChallenge(nil, u)
+	assert.NoError(t, err)
+	assert.Nil(t, mt)
+}
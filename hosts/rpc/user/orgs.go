@@ -0,0 +1,342 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	api "github.com/jrapoport/gothic/api/grpc/rpc"
+	"github.com/jrapoport/gothic/api/grpc/rpc/user"
+	"github.com/jrapoport/gothic/hosts/rpc"
+	"github.com/jrapoport/gothic/models/org"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *userServer) CreateOrg(ctx context.Context,
+	req *user.CreateOrgRequest) (*user.Org, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	uid, err := rpc.GetUserID(ctx)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	rtx := rpc.RequestContext(ctx)
+	s.Debugf("create organization: %s %s", uid, req.GetName())
+	o, err := s.API.CreateOrg(rtx, uid, req.GetName(), req.GetData().AsMap())
+	if err != nil {
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	return newOrgResponse(o)
+}
+
+func (s *userServer) ListOrgs(ctx context.Context,
+	_ *emptypb.Empty) (*user.OrgsResponse, error) {
+	uid, err := rpc.GetUserID(ctx)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	rtx := rpc.RequestContext(ctx)
+	s.Debugf("list organizations: %s", uid)
+	list, err := s.API.GetUserOrgs(rtx, uid)
+	if err != nil {
+		return nil, s.RPCError(codes.NotFound, err)
+	}
+	res := &user.OrgsResponse{
+		Orgs: make([]*user.Org, len(list)),
+	}
+	for i, o := range list {
+		res.Orgs[i], err = newOrgResponse(o)
+		if err != nil {
+			return nil, s.RPCError(codes.Internal, err)
+		}
+	}
+	return res, nil
+}
+
+func (s *userServer) GetOrg(ctx context.Context,
+	req *user.OrgRequest) (*user.Org, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	uid, err := rpc.GetUserID(ctx)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	oid, err := parseOrgID(req.GetOrgId())
+	if err != nil {
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx := rpc.RequestContext(ctx)
+	s.Debugf("get organization: %s %s", uid, oid)
+	o, err := s.API.GetOrg(rtx, uid, oid)
+	if err != nil {
+		return nil, s.RPCError(codes.NotFound, err)
+	}
+	return newOrgResponse(o)
+}
+
+func (s *userServer) UpdateOrg(ctx context.Context,
+	req *user.UpdateOrgRequest) (*user.Org, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	uid, err := rpc.GetUserID(ctx)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	oid, err := parseOrgID(req.GetOrgId())
+	if err != nil {
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx := rpc.RequestContext(ctx)
+	s.Debugf("update organization: %s %s", uid, oid)
+	o, err := s.API.UpdateOrg(rtx, uid, oid, req.Name, req.GetData().AsMap())
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	return newOrgResponse(o)
+}
+
+func (s *userServer) DeleteOrg(ctx context.Context,
+	req *user.OrgRequest) (*emptypb.Empty, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	uid, err := rpc.GetUserID(ctx)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	oid, err := parseOrgID(req.GetOrgId())
+	if err != nil {
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx := rpc.RequestContext(ctx)
+	s.Debugf("delete organization: %s %s", uid, oid)
+	err = s.API.DeleteOrg(rtx, uid, oid)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *userServer) ListOrgMembers(ctx context.Context,
+	req *user.OrgRequest) (*user.OrgMembersResponse, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	uid, err := rpc.GetUserID(ctx)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	oid, err := parseOrgID(req.GetOrgId())
+	if err != nil {
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx := rpc.RequestContext(ctx)
+	s.Debugf("list organization members: %s %s", uid, oid)
+	list, err := s.API.GetOrgMembers(rtx, uid, oid)
+	if err != nil {
+		return nil, s.RPCError(codes.NotFound, err)
+	}
+	res := &user.OrgMembersResponse{
+		Members: make([]*user.OrgMember, len(list)),
+	}
+	for i, m := range list {
+		res.Members[i] = newOrgMemberResponse(m)
+	}
+	return res, nil
+}
+
+func (s *userServer) ChangeOrgMemberRole(ctx context.Context,
+	req *user.OrgMemberRoleRequest) (*user.OrgMember, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	uid, err := rpc.GetUserID(ctx)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	oid, mid, err := parseMemberID(req.GetOrgId(), req.GetUserId())
+	if err != nil {
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	role := org.ToRole(req.GetRole())
+	if !role.Valid() {
+		err = fmt.Errorf("invalid role: %s", req.GetRole())
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx := rpc.RequestContext(ctx)
+	s.Debugf("change organization member role: %s %s %s %s",
+		uid, oid, mid, role)
+	m, err := s.API.ChangeOrgMemberRole(rtx, uid, oid, mid, role)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	return newOrgMemberResponse(m), nil
+}
+
+func (s *userServer) RemoveOrgMember(ctx context.Context,
+	req *user.OrgMemberRequest) (*emptypb.Empty, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	uid, err := rpc.GetUserID(ctx)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	oid, mid, err := parseMemberID(req.GetOrgId(), req.GetUserId())
+	if err != nil {
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx := rpc.RequestContext(ctx)
+	s.Debugf("remove organization member: %s %s %s", uid, oid, mid)
+	err = s.API.RemoveOrgMember(rtx, uid, oid, mid)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *userServer) SendOrgInvite(ctx context.Context,
+	req *user.OrgInviteRequest) (*user.OrgInvite, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	uid, err := rpc.GetUserID(ctx)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	oid, err := parseOrgID(req.GetOrgId())
+	if err != nil {
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	role := org.RoleMember
+	if req.GetRole() != "" {
+		role = org.ToRole(req.GetRole())
+	}
+	if !role.Valid() {
+		err = fmt.Errorf("invalid role: %s", req.GetRole())
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx := rpc.RequestContext(ctx)
+	s.Debugf("send organization invite: %s %s %s", uid, oid, req.GetEmail())
+	inv, err := s.API.SendOrgInvite(rtx, uid, oid, req.GetEmail(), role)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	res := &user.OrgInvite{
+		OrgId: inv.OrgID.String(),
+		Email: inv.Email,
+		Role:  inv.Role.String(),
+	}
+	if inv.ExpiredAt != nil {
+		res.ExpiresAt = timestamppb.New(*inv.ExpiredAt)
+	}
+	if inv.SentAt != nil {
+		res.SentAt = timestamppb.New(*inv.SentAt)
+	} else {
+		// the invite mail was not sent
+		res.Token = inv.Token
+	}
+	return res, nil
+}
+
+func (s *userServer) AcceptOrgInvite(ctx context.Context,
+	req *user.AcceptOrgInviteRequest) (*user.OrgMember, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	uid, err := rpc.GetUserID(ctx)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	if req.GetToken() == "" {
+		err = errors.New("invite token required")
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx := rpc.RequestContext(ctx)
+	s.Debugf("accept organization invite: %s", uid)
+	m, err := s.API.AcceptOrgInvite(rtx, uid, req.GetToken())
+	if err != nil {
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	return newOrgMemberResponse(m), nil
+}
+
+func (s *userServer) SwitchOrg(ctx context.Context,
+	req *user.SwitchOrgRequest) (*api.BearerResponse, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	uid, err := rpc.GetUserID(ctx)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	if req.GetToken() == "" {
+		err = errors.New("refresh token not found")
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	oid := uuid.Nil
+	if req.GetOrgId() != "" {
+		oid, err = parseOrgID(req.GetOrgId())
+		if err != nil {
+			return nil, s.RPCError(codes.InvalidArgument, err)
+		}
+	}
+	rtx := rpc.RequestContext(ctx)
+	s.Debugf("switch organization: %s %s", uid, oid)
+	bt, err := s.API.SwitchOrg(rtx, uid, req.GetToken(), oid)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	return rpc.NewBearerResponse(bt), nil
+}
+
+func parseOrgID(id string) (uuid.UUID, error) {
+	oid, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid org id '%s': %w", id, err)
+	}
+	return oid, nil
+}
+
+func parseMemberID(orgID, userID string) (uuid.UUID, uuid.UUID, error) {
+	oid, err := parseOrgID(orgID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	mid, err := uuid.Parse(userID)
+	if err != nil {
+		err = fmt.Errorf("invalid user id '%s': %w", userID, err)
+		return uuid.Nil, uuid.Nil, err
+	}
+	return oid, mid, nil
+}
+
+func newOrgResponse(o *org.Organization) (*user.Org, error) {
+	data, err := structpb.NewStruct(o.Data)
+	if err != nil {
+		return nil, err
+	}
+	return &user.Org{
+		OrgId:     o.ID.String(),
+		Name:      o.Name,
+		Data:      data,
+		CreatedAt: timestamppb.New(o.CreatedAt),
+		UpdatedAt: timestamppb.New(o.UpdatedAt),
+	}, nil
+}
+
+func newOrgMemberResponse(m *org.Member) *user.OrgMember {
+	return &user.OrgMember{
+		OrgId:     m.OrgID.String(),
+		UserId:    m.UserID.String(),
+		Role:      m.Role.String(),
+		CreatedAt: timestamppb.New(m.CreatedAt),
+	}
+}
@@ -0,0 +1,199 @@
+package user
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/api/grpc/rpc/user"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/jwt"
+	"github.com/jrapoport/gothic/models/org"
+	"github.com/jrapoport/gothic/test/tcore"
+	"github.com/jrapoport/gothic/test/tsrv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestUserServer_Orgs(t *testing.T) {
+	t.Parallel()
+	srv := testServer(t)
+	ctx := context.Background()
+	// no id
+	_, err := srv.CreateOrg(ctx, &user.CreateOrgRequest{})
+	assert.Error(t, err)
+	_, err = srv.ListOrgs(ctx, &emptypb.Empty{})
+	assert.Error(t, err)
+	_, tok := tcore.TestUser(t, srv.API, "", false)
+	ctx = tsrv.RPCAuthContext(t, srv.Config(), tok)
+	_, err = srv.CreateOrg(ctx, nil)
+	assert.Error(t, err)
+	_, err = srv.CreateOrg(ctx, &user.CreateOrgRequest{Name: " "})
+	assert.Error(t, err)
+	data, err := structpb.NewStruct(map[string]interface{}{"plan": "pro"})
+	require.NoError(t, err)
+	o, err := srv.CreateOrg(ctx, &user.CreateOrgRequest{
+		Name: "acme",
+		Data: data,
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, o.GetOrgId())
+	assert.Equal(t, "acme", o.GetName())
+	assert.Equal(t, "pro", o.GetData().AsMap()["plan"])
+	list, err := srv.ListOrgs(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	require.Len(t, list.GetOrgs(), 1)
+	assert.Equal(t, o.GetOrgId(), list.GetOrgs()[0].GetOrgId())
+	// get
+	_, err = srv.GetOrg(ctx, &user.OrgRequest{OrgId: "bad"})
+	assert.Error(t, err)
+	_, err = srv.GetOrg(ctx, &user.OrgRequest{OrgId: uuid.New().String()})
+	assert.Error(t, err)
+	got, err := srv.GetOrg(ctx, &user.OrgRequest{OrgId: o.GetOrgId()})
+	require.NoError(t, err)
+	assert.Equal(t, o.GetOrgId(), got.GetOrgId())
+	// update
+	name := "renamed"
+	_, err = srv.UpdateOrg(ctx, &user.UpdateOrgRequest{OrgId: "bad", Name: &name})
+	assert.Error(t, err)
+	got, err = srv.UpdateOrg(ctx, &user.UpdateOrgRequest{
+		OrgId: o.GetOrgId(),
+		Name:  &name,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, name, got.GetName())
+	assert.Equal(t, "pro", got.GetData().AsMap()["plan"])
+	// delete
+	_, other := tcore.TestUser(t, srv.API, "", false)
+	otx := tsrv.RPCAuthContext(t, srv.Config(), other)
+	_, err = srv.DeleteOrg(otx, &user.OrgRequest{OrgId: o.GetOrgId()})
+	assert.Error(t, err)
+	_, err = srv.DeleteOrg(ctx, &user.OrgRequest{OrgId: o.GetOrgId()})
+	require.NoError(t, err)
+	_, err = srv.GetOrg(ctx, &user.OrgRequest{OrgId: o.GetOrgId()})
+	assert.Error(t, err)
+}
+
+func TestUserServer_OrgMembers(t *testing.T) {
+	t.Parallel()
+	srv := testServer(t)
+	owner, tok := tcore.TestUser(t, srv.API, "", false)
+	ctx := tsrv.RPCAuthContext(t, srv.Config(), tok)
+	o, err := srv.CreateOrg(ctx, &user.CreateOrgRequest{Name: "acme"})
+	require.NoError(t, err)
+	oid := o.GetOrgId()
+	u, utok := tcore.TestUser(t, srv.API, "", false)
+	utx := tsrv.RPCAuthContext(t, srv.Config(), utok)
+	// invite
+	tests := []*user.OrgInviteRequest{
+		nil,
+		{OrgId: "bad", Email: u.Email},
+		{OrgId: oid, Email: "@"},
+		{OrgId: oid, Email: u.Email, Role: "bad"},
+		{OrgId: oid, Email: u.Email, Role: org.RoleOwner.String()},
+	}
+	for _, test := range tests {
+		_, err = srv.SendOrgInvite(ctx, test)
+		assert.Error(t, err)
+	}
+	_, err = srv.SendOrgInvite(utx, &user.OrgInviteRequest{OrgId: oid, Email: u.Email})
+	assert.Error(t, err)
+	inv, err := srv.SendOrgInvite(ctx, &user.OrgInviteRequest{OrgId: oid, Email: u.Email})
+	require.NoError(t, err)
+	assert.Equal(t, org.RoleMember.String(), inv.GetRole())
+	assert.NotEmpty(t, inv.GetToken())
+	assert.Nil(t, inv.GetSentAt())
+	// accept
+	_, err = srv.AcceptOrgInvite(utx, nil)
+	assert.Error(t, err)
+	_, err = srv.AcceptOrgInvite(utx, &user.AcceptOrgInviteRequest{})
+	assert.Error(t, err)
+	_, err = srv.AcceptOrgInvite(ctx, &user.AcceptOrgInviteRequest{Token: inv.GetToken()})
+	assert.Error(t, err)
+	m, err := srv.AcceptOrgInvite(utx, &user.AcceptOrgInviteRequest{Token: inv.GetToken()})
+	require.NoError(t, err)
+	assert.Equal(t, oid, m.GetOrgId())
+	assert.Equal(t, u.ID.String(), m.GetUserId())
+	// list
+	_, err = srv.ListOrgMembers(utx, &user.OrgRequest{OrgId: "bad"})
+	assert.Error(t, err)
+	members, err := srv.ListOrgMembers(utx, &user.OrgRequest{OrgId: oid})
+	require.NoError(t, err)
+	require.Len(t, members.GetMembers(), 2)
+	assert.Equal(t, owner.ID.String(), members.GetMembers()[0].GetUserId())
+	// change role
+	req := &user.OrgMemberRoleRequest{
+		OrgId:  oid,
+		UserId: u.ID.String(),
+		Role:   org.RoleAdmin.String(),
+	}
+	_, err = srv.ChangeOrgMemberRole(utx, req)
+	assert.Error(t, err)
+	_, err = srv.ChangeOrgMemberRole(ctx, &user.OrgMemberRoleRequest{
+		OrgId:  oid,
+		UserId: "bad",
+		Role:   org.RoleAdmin.String(),
+	})
+	assert.Error(t, err)
+	_, err = srv.ChangeOrgMemberRole(ctx, &user.OrgMemberRoleRequest{
+		OrgId:  oid,
+		UserId: u.ID.String(),
+		Role:   "bad",
+	})
+	assert.Error(t, err)
+	m, err = srv.ChangeOrgMemberRole(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, org.RoleAdmin.String(), m.GetRole())
+	// remove
+	_, err = srv.RemoveOrgMember(utx, &user.OrgMemberRequest{
+		OrgId:  oid,
+		UserId: owner.ID.String(),
+	})
+	assert.Error(t, err)
+	_, err = srv.RemoveOrgMember(ctx, &user.OrgMemberRequest{
+		OrgId:  oid,
+		UserId: u.ID.String(),
+	})
+	require.NoError(t, err)
+	_, err = srv.ListOrgMembers(utx, &user.OrgRequest{OrgId: oid})
+	assert.Error(t, err)
+}
+
+func TestUserServer_SwitchOrg(t *testing.T) {
+	t.Parallel()
+	srv := testServer(t)
+	u, _ := tcore.TestUser(t, srv.API, "", false)
+	bt, err := srv.API.GrantBearerToken(context.Background(), u)
+	require.NoError(t, err)
+	ctx := tsrv.RPCAuthContext(t, srv.Config(), bt.String())
+	o, err := srv.CreateOrg(ctx, &user.CreateOrgRequest{Name: "acme"})
+	require.NoError(t, err)
+	tests := []*user.SwitchOrgRequest{
+		nil,
+		{OrgId: o.GetOrgId()},
+		{OrgId: "bad", Token: bt.RefreshToken.String()},
+		{OrgId: uuid.New().String(), Token: bt.RefreshToken.String()},
+	}
+	for _, test := range tests {
+		_, err = srv.SwitchOrg(ctx, test)
+		assert.Error(t, err)
+	}
+	res, err := srv.SwitchOrg(ctx, &user.SwitchOrgRequest{
+		OrgId: o.GetOrgId(),
+		Token: bt.RefreshToken.String(),
+	})
+	require.NoError(t, err)
+	claims, err := jwt.ParseUserClaims(srv.Config().JWT, res.GetAccess())
+	require.NoError(t, err)
+	assert.Equal(t, o.GetOrgId(), claims.OrgID().String())
+	assert.Equal(t, org.RoleOwner.String(), claims.OrgRole())
+	// clear
+	res, err = srv.SwitchOrg(ctx, &user.SwitchOrgRequest{
+		Token: res.GetRefresh(),
+	})
+	require.NoError(t, err)
+	claims, err = jwt.ParseUserClaims(srv.Config().JWT, res.GetAccess())
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Nil, claims.OrgID())
+}
@@ -0,0 +1,68 @@
+package user
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jrapoport/gothic/api/grpc/rpc"
+	"github.com/jrapoport/gothic/api/grpc/rpc/user"
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/hosts/rpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func (s *userServer) SendChangePhone(ctx context.Context, req *user.ChangePhoneRequest) (*emptypb.Empty, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	uid, err := rpc.GetUserID(ctx)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	if req.GetPhone() == "" {
+		err = errors.New("phone not found")
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx := rpc.RequestContext(ctx)
+	s.Debugf("send change phone: %s %s", uid, req.GetPhone())
+	err = s.API.SendChangePhone(rtx, uid, req.GetPhone())
+	if errors.Is(err, config.ErrRateLimitExceeded) {
+		return nil, s.RPCError(codes.DeadlineExceeded, err)
+	}
+	if err != nil {
+		return nil, s.RPCError(codes.FailedPrecondition, err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *userServer) ConfirmChangePhone(ctx context.Context, req *user.ConfirmChangePhoneRequest) (*api.UserResponse, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	uid, err := rpc.GetUserID(ctx)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	if req.GetPhone() == "" || req.GetCode() == "" {
+		err = errors.New("phone or code not found")
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx := rpc.RequestContext(ctx)
+	s.Debugf("confirm change phone: %s %s", uid, req.GetPhone())
+	u, err := s.API.ConfirmChangePhone(rtx, uid, req.GetPhone(), req.GetCode())
+	if errors.Is(err, config.ErrRateLimitExceeded) {
+		return nil, s.RPCError(codes.DeadlineExceeded, err)
+	}
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	res, err := rpc.NewUserResponse(u)
+	if err != nil {
+		return nil, s.RPCError(codes.Internal, err)
+	}
+	if s.Config().MaskEmails {
+		res.MaskEmail()
+	}
+	return (*api.UserResponse)(res), nil
+}
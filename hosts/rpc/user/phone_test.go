@@ -0,0 +1,82 @@
+package user
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jrapoport/gothic/api/grpc/rpc/user"
+	"github.com/jrapoport/gothic/core/context"
+	guser "github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconf"
+	"github.com/jrapoport/gothic/test/tcore"
+	"github.com/jrapoport/gothic/test/tsrv"
+	"github.com/jrapoport/gothic/test/tutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUserServer_ChangePhone(t *testing.T) {
+	t.Parallel()
+	srv := testServer(t)
+	srv.Config().MaskEmails = false
+	path := tcore.MockSMS(t, srv.API, srv.Config())
+	srv.Config().SMS.SendLimit = 0
+	ctx := context.Background()
+	// no id
+	_, err := srv.SendChangePhone(ctx, &user.ChangePhoneRequest{})
+	assert.Error(t, err)
+	_, err = srv.ConfirmChangePhone(ctx, &user.ConfirmChangePhoneRequest{})
+	assert.Error(t, err)
+	u, tok := tcore.TestUser(t, srv.API, "", false)
+	ctx = tsrv.RPCAuthContext(t, srv.Config(), tok)
+	// invalid req
+	_, err = srv.SendChangePhone(ctx, nil)
+	assert.Error(t, err)
+	_, err = srv.ConfirmChangePhone(ctx, nil)
+	assert.Error(t, err)
+	// empty phone
+	_, err = srv.SendChangePhone(ctx, &user.ChangePhoneRequest{})
+	assert.Error(t, err)
+	// bad phone
+	_, err = srv.SendChangePhone(ctx, &user.ChangePhoneRequest{Phone: "bad"})
+	assert.Error(t, err)
+	phone := tutils.RandomPhone()
+	req := &user.ChangePhoneRequest{Phone: phone}
+	// impersonated
+	actx := context.Background()
+	actx.SetAdminID(guser.SuperAdminID)
+	imp, err := srv.API.ImpersonateUser(actx, u.ID)
+	require.NoError(t, err)
+	ictx := tsrv.RPCAuthContext(t, srv.Config(), imp.String())
+	_, err = srv.SendChangePhone(ictx, req)
+	assert.Error(t, err)
+	_, err = srv.SendChangePhone(ctx, req)
+	require.NoError(t, err)
+	code := tconf.GetSMSCode(t, path, phone)
+	require.NotEmpty(t, code)
+	// rate limit
+	srv.Config().SMS.SendLimit = 5 * time.Minute
+	_, err = srv.SendChangePhone(ctx, req)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	// empty code
+	creq := &user.ConfirmChangePhoneRequest{Phone: phone}
+	_, err = srv.ConfirmChangePhone(ctx, creq)
+	assert.Error(t, err)
+	// bad code
+	creq.Code = "bad"
+	_, err = srv.ConfirmChangePhone(ctx, creq)
+	assert.Error(t, err)
+	creq.Code = code
+	_, err = srv.ConfirmChangePhone(ictx, creq)
+	assert.Error(t, err)
+	res, err := srv.ConfirmChangePhone(ctx, creq)
+	require.NoError(t, err)
+	assert.Equal(t, u.ID.String(), res.UserId)
+	assert.Equal(t, u.Email, res.Email)
+	assert.Equal(t, phone, res.Phone)
+	// codes cannot be reused
+	_, err = srv.ConfirmChangePhone(ctx, creq)
+	assert.Error(t, err)
+}
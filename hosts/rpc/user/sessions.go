@@ -0,0 +1,85 @@
+package user
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/api/grpc/rpc/user"
+	"github.com/jrapoport/gothic/hosts/rpc"
+	"github.com/jrapoport/gothic/models/token"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *userServer) ListSessions(ctx context.Context,
+	_ *emptypb.Empty) (*user.SessionsResponse, error) {
+	uid, err := rpc.GetUserID(ctx)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	rtx := rpc.RequestContext(ctx)
+	s.Debugf("list sessions: %s", uid)
+	sessions, err := s.API.GetSessions(rtx, uid)
+	if err != nil {
+		return nil, s.RPCError(codes.NotFound, err)
+	}
+	res := &user.SessionsResponse{
+		Sessions: make([]*user.Session, len(sessions)),
+	}
+	for i, rt := range sessions {
+		res.Sessions[i] = newSessionResponse(rt, rtx.SessionID())
+	}
+	return res, nil
+}
+
+func (s *userServer) RevokeSession(ctx context.Context,
+	req *user.SessionRequest) (*emptypb.Empty, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	uid, err := rpc.GetUserID(ctx)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	sid, err := uuid.Parse(req.GetSessionId())
+	if err != nil {
+		err = fmt.Errorf("invalid session id '%s': %w", req.GetSessionId(), err)
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx := rpc.RequestContext(ctx)
+	s.Debugf("revoke session: %s %s", uid, sid)
+	err = s.API.RevokeSession(rtx, uid, sid)
+	if err != nil {
+		return nil, s.RPCError(codes.NotFound, err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *userServer) RevokeOtherSessions(ctx context.Context,
+	_ *emptypb.Empty) (*user.RevokeSessionsResponse, error) {
+	uid, err := rpc.GetUserID(ctx)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	rtx := rpc.RequestContext(ctx)
+	s.Debugf("revoke other sessions: %s", uid)
+	n, err := s.API.RevokeOtherSessions(rtx, uid)
+	if err != nil {
+		return nil, s.RPCError(codes.FailedPrecondition, err)
+	}
+	return &user.RevokeSessionsResponse{Revoked: int32(n)}, nil
+}
+
+func newSessionResponse(rt *token.RefreshToken, current uuid.UUID) *user.Session {
+	return &user.Session{
+		SessionId:  rt.SessionID.String(),
+		UserAgent:  rt.UserAgent,
+		IpAddress:  rt.IPAddress,
+		DeviceName: rt.DeviceName,
+		CreatedAt:  timestamppb.New(rt.SignedInAt),
+		LastUsedAt: timestamppb.New(rt.LastActive()),
+		Current:    current != uuid.Nil && rt.SessionID == current,
+	}
+}
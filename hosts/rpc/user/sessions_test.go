@@ -0,0 +1,70 @@
+package user
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/api/grpc/rpc/user"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/jwt"
+	"github.com/jrapoport/gothic/test/tcore"
+	"github.com/jrapoport/gothic/test/tsrv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestUserServer_Sessions(t *testing.T) {
+	t.Parallel()
+	srv := testServer(t)
+	ctx := context.Background()
+	// no id
+	_, err := srv.ListSessions(ctx, &emptypb.Empty{})
+	assert.Error(t, err)
+	_, err = srv.RevokeSession(ctx, &user.SessionRequest{})
+	assert.Error(t, err)
+	_, err = srv.RevokeOtherSessions(ctx, &emptypb.Empty{})
+	assert.Error(t, err)
+	u, tok := tcore.TestUser(t, srv.API, "", false)
+	ctx = tsrv.RPCAuthContext(t, srv.Config(), tok)
+	claims, err := jwt.ParseUserClaims(srv.Config().JWT, tok)
+	require.NoError(t, err)
+	current := claims.SessionID()
+	for i := 0; i < 2; i++ {
+		_, err = srv.API.GrantBearerToken(context.Background(), u)
+		require.NoError(t, err)
+	}
+	res, err := srv.ListSessions(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	require.Len(t, res.GetSessions(), 3)
+	var other string
+	for _, s := range res.GetSessions() {
+		assert.NotNil(t, s.GetCreatedAt())
+		assert.NotNil(t, s.GetLastUsedAt())
+		if s.GetSessionId() == current.String() {
+			assert.True(t, s.GetCurrent())
+			continue
+		}
+		assert.False(t, s.GetCurrent())
+		other = s.GetSessionId()
+	}
+	// invalid req
+	_, err = srv.RevokeSession(ctx, nil)
+	assert.Error(t, err)
+	_, err = srv.RevokeSession(ctx, &user.SessionRequest{SessionId: "bad"})
+	assert.Error(t, err)
+	_, err = srv.RevokeSession(ctx, &user.SessionRequest{SessionId: uuid.New().String()})
+	assert.Error(t, err)
+	_, err = srv.RevokeSession(ctx, &user.SessionRequest{SessionId: other})
+	assert.NoError(t, err)
+	res, err = srv.ListSessions(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	assert.Len(t, res.GetSessions(), 2)
+	rev, err := srv.RevokeOtherSessions(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), rev.GetRevoked())
+	res, err = srv.ListSessions(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	require.Len(t, res.GetSessions(), 1)
+	assert.Equal(t, current.String(), res.GetSessions()[0].GetSessionId())
+}
@@ -0,0 +1,110 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/api/grpc/rpc/user"
+	"github.com/jrapoport/gothic/hosts/rpc"
+	"github.com/jrapoport/gothic/models/token"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *userServer) CreatePersonalToken(ctx context.Context,
+	req *user.CreatePersonalTokenRequest) (*user.PersonalToken, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	claims, err := rpc.GetUserClaims(ctx)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	uid := claims.UserID()
+	if uid == uuid.Nil {
+		err = errors.New("invalid user id")
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	if claims.PersonalToken() {
+		err = errors.New("personal token not allowed")
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	if req.GetExpiresIn() < 0 {
+		err = errors.New("invalid expiration")
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	exp := time.Duration(req.GetExpiresIn()) * time.Second
+	rtx := rpc.RequestContext(ctx)
+	s.Debugf("create personal token: %s %s", uid, req.GetName())
+	pt, err := s.API.CreatePersonalToken(rtx, uid, req.GetName(), req.GetScopes(), exp)
+	if err != nil {
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	return newPersonalTokenResponse(pt), nil
+}
+
+func (s *userServer) ListPersonalTokens(ctx context.Context,
+	_ *emptypb.Empty) (*user.PersonalTokensResponse, error) {
+	uid, err := rpc.GetUserID(ctx)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	rtx := rpc.RequestContext(ctx)
+	s.Debugf("list personal tokens: %s", uid)
+	list, err := s.API.GetPersonalTokens(rtx, uid)
+	if err != nil {
+		return nil, s.RPCError(codes.NotFound, err)
+	}
+	res := &user.PersonalTokensResponse{
+		Tokens: make([]*user.PersonalToken, len(list)),
+	}
+	for i, pt := range list {
+		res.Tokens[i] = newPersonalTokenResponse(pt)
+	}
+	return res, nil
+}
+
+func (s *userServer) RevokePersonalToken(ctx context.Context,
+	req *user.PersonalTokenRequest) (*emptypb.Empty, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	uid, err := rpc.GetUserID(ctx)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	tid, err := uuid.Parse(req.GetTokenId())
+	if err != nil {
+		err = fmt.Errorf("invalid token id '%s': %w", req.GetTokenId(), err)
+		return nil, s.RPCError(codes.InvalidArgument, err)
+	}
+	rtx := rpc.RequestContext(ctx)
+	s.Debugf("revoke personal token: %s %s", uid, tid)
+	err = s.API.RevokePersonalToken(rtx, uid, tid)
+	if err != nil {
+		return nil, s.RPCError(codes.NotFound, err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func newPersonalTokenResponse(pt *token.PersonalToken) *user.PersonalToken {
+	res := &user.PersonalToken{
+		TokenId:   pt.TokenID.String(),
+		Token:     pt.Secret,
+		Name:      pt.Name,
+		Scopes:    pt.Scopes(),
+		CreatedAt: timestamppb.New(pt.CreatedAt),
+		IpAddress: pt.IPAddress,
+	}
+	if pt.ExpiredAt != nil {
+		res.ExpiresAt = timestamppb.New(*pt.ExpiredAt)
+	}
+	if pt.UsedAt != nil {
+		res.LastUsedAt = timestamppb.New(*pt.UsedAt)
+	}
+	return res
+}
@@ -0,0 +1,85 @@
+package user
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/api/grpc/rpc/user"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/hosts/rpc"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/test/tcore"
+	"github.com/jrapoport/gothic/test/tsrv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestUserServer_PersonalTokens(t *testing.T) {
+	t.Parallel()
+	srv := testServer(t)
+	ctx := context.Background()
+	// no id
+	_, err := srv.CreatePersonalToken(ctx, &user.CreatePersonalTokenRequest{})
+	assert.Error(t, err)
+	_, err = srv.ListPersonalTokens(ctx, &emptypb.Empty{})
+	assert.Error(t, err)
+	_, err = srv.RevokePersonalToken(ctx, &user.PersonalTokenRequest{})
+	assert.Error(t, err)
+	_, tok := tcore.TestUser(t, srv.API, "", false)
+	ctx = tsrv.RPCAuthContext(t, srv.Config(), tok)
+	// invalid req
+	tests := []*user.CreatePersonalTokenRequest{
+		nil,
+		{Name: "", Scopes: []string{token.ScopeRead}},
+		{Name: "test"},
+		{Name: "test", Scopes: []string{"bad"}},
+		{Name: "test", Scopes: []string{token.ScopeAdmin}},
+		{Name: "test", Scopes: []string{token.ScopeRead}, ExpiresIn: -1},
+	}
+	for _, test := range tests {
+		_, err = srv.CreatePersonalToken(ctx, test)
+		assert.Error(t, err)
+	}
+	req := &user.CreatePersonalTokenRequest{
+		Name:      "test",
+		Scopes:    []string{token.ScopeRead},
+		ExpiresIn: 3600,
+	}
+	pt, err := srv.CreatePersonalToken(ctx, req)
+	require.NoError(t, err)
+	assert.NotEmpty(t, pt.GetTokenId())
+	assert.True(t, token.IsPersonalToken(pt.GetToken()))
+	assert.Equal(t, req.GetName(), pt.GetName())
+	assert.Equal(t, req.GetScopes(), pt.GetScopes())
+	assert.NotNil(t, pt.GetCreatedAt())
+	assert.NotNil(t, pt.GetExpiresAt())
+	assert.Nil(t, pt.GetLastUsedAt())
+	// personal tokens can not create personal tokens
+	claims, err := srv.API.ValidatePersonalToken(pt.GetToken(), "127.0.0.1")
+	require.NoError(t, err)
+	ptx := rpc.WithClaims(context.Background(), claims)
+	_, err = srv.CreatePersonalToken(ptx, req)
+	assert.Error(t, err)
+	res, err := srv.ListPersonalTokens(ptx, &emptypb.Empty{})
+	require.NoError(t, err)
+	require.Len(t, res.GetTokens(), 1)
+	assert.Equal(t, pt.GetTokenId(), res.GetTokens()[0].GetTokenId())
+	assert.Empty(t, res.GetTokens()[0].GetToken())
+	assert.NotNil(t, res.GetTokens()[0].GetLastUsedAt())
+	assert.Equal(t, "127.0.0.1", res.GetTokens()[0].GetIpAddress())
+	// revoke
+	_, err = srv.RevokePersonalToken(ctx, nil)
+	assert.Error(t, err)
+	_, err = srv.RevokePersonalToken(ctx, &user.PersonalTokenRequest{TokenId: "bad"})
+	assert.Error(t, err)
+	_, err = srv.RevokePersonalToken(ctx, &user.PersonalTokenRequest{TokenId: uuid.New().String()})
+	assert.Error(t, err)
+	_, err = srv.RevokePersonalToken(ctx, &user.PersonalTokenRequest{TokenId: pt.GetTokenId()})
+	assert.NoError(t, err)
+	res, err = srv.ListPersonalTokens(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	assert.Len(t, res.GetTokens(), 0)
+	_, err = srv.API.ValidatePersonalToken(pt.GetToken(), "127.0.0.1")
+	assert.Error(t, err)
+}
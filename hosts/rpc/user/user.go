@@ -26,7 +26,7 @@ func newUserServer(srv *rpc.Server) *userServer {
 }
 
 // RegisterServer registers a new admin server.
-func RegisterServer(s *grpc.Server, srv *rpc.Server) {
+func RegisterServer(s grpc.ServiceRegistrar, srv *rpc.Server) {
 	user.RegisterUserServer(s, newUserServer(srv))
 }
 
@@ -18,6 +18,8 @@ import (
 	"github.com/jrapoport/gothic/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 	"google.golang.org/protobuf/types/known/structpb"
 )
@@ -159,6 +161,7 @@ func TestUserServer_ChangePassword(t *testing.T) {
 	_, err = srv.ChangePassword(ctx, req)
 	assert.Error(t, err)
 	// success
+	srv.Config().Password.History = 2
 	req.Password = testPass
 	res, err := srv.ChangePassword(ctx, req)
 	assert.NoError(t, err)
@@ -170,6 +173,11 @@ func TestUserServer_ChangePassword(t *testing.T) {
 	assert.NoError(t, err)
 	err = u.Authenticate(newPassword)
 	assert.NoError(t, err)
+	// reused password
+	req.Password = newPassword
+	req.NewPassword = testPass
+	_, err = srv.ChangePassword(ctx, req)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
 }
 
 func TestRequestErrors(t *testing.T) {
@@ -0,0 +1,120 @@
+package user
+
+import (
+	"context"
+
+	"github.com/jrapoport/gothic/api/grpc/rpc"
+	"github.com/jrapoport/gothic/api/grpc/rpc/user"
+	"github.com/jrapoport/gothic/hosts/rpc"
+	"github.com/jrapoport/gothic/models/credential"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *userServer) BeginWebAuthnRegistration(ctx context.Context,
+	_ *emptypb.Empty) (*api.WebAuthnResponse, error) {
+	uid, err := rpc.GetUserID(ctx)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	rtx := rpc.RequestContext(ctx)
+	s.Debugf("begin webauthn registration: %s", uid)
+	c, err := s.API.BeginWebAuthnRegistration(rtx, uid)
+	if err != nil {
+		return nil, s.RPCError(codes.FailedPrecondition, err)
+	}
+	return rpc.NewWebAuthnResponse(c), nil
+}
+
+func (s *userServer) FinishWebAuthnRegistration(ctx context.Context,
+	req *user.FinishWebAuthnRegistrationRequest) (*user.WebAuthnCredential, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	uid, err := rpc.GetUserID(ctx)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	rtx := rpc.RequestContext(ctx)
+	s.Debugf("finish webauthn registration: %s", uid)
+	c, err := s.API.FinishWebAuthnRegistration(rtx, uid,
+		req.GetToken(), req.GetName(), []byte(req.GetResponse()))
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	return newCredentialResponse(c), nil
+}
+
+func (s *userServer) ListWebAuthnCredentials(ctx context.Context,
+	_ *emptypb.Empty) (*user.WebAuthnCredentialsResponse, error) {
+	uid, err := rpc.GetUserID(ctx)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	s.Debugf("list webauthn credentials: %s", uid)
+	creds, err := s.API.GetWebAuthnCredentials(uid)
+	if err != nil {
+		return nil, s.RPCError(codes.NotFound, err)
+	}
+	res := &user.WebAuthnCredentialsResponse{
+		Credentials: make([]*user.WebAuthnCredential, len(creds)),
+	}
+	for i, c := range creds {
+		res.Credentials[i] = newCredentialResponse(c)
+	}
+	return res, nil
+}
+
+func (s *userServer) RenameWebAuthnCredential(ctx context.Context,
+	req *user.RenameWebAuthnCredentialRequest) (*user.WebAuthnCredential, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	uid, err := rpc.GetUserID(ctx)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	rtx := rpc.RequestContext(ctx)
+	s.Debugf("rename webauthn credential: %s %s", uid, req.GetCredentialId())
+	c, err := s.API.RenameWebAuthnCredential(rtx, uid,
+		req.GetCredentialId(), req.GetName())
+	if err != nil {
+		return nil, s.RPCError(codes.NotFound, err)
+	}
+	return newCredentialResponse(c), nil
+}
+
+func (s *userServer) DeleteWebAuthnCredential(ctx context.Context,
+	req *user.WebAuthnCredentialRequest) (*emptypb.Empty, error) {
+	if req == nil {
+		return nil, s.RPCError(codes.InvalidArgument, nil)
+	}
+	uid, err := rpc.GetUserID(ctx)
+	if err != nil {
+		return nil, s.RPCError(codes.PermissionDenied, err)
+	}
+	rtx := rpc.RequestContext(ctx)
+	s.Debugf("delete webauthn credential: %s %s", uid, req.GetCredentialId())
+	err = s.API.DeleteWebAuthnCredential(rtx, uid, req.GetCredentialId())
+	if err != nil {
+		return nil, s.RPCError(codes.NotFound, err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func newCredentialResponse(c *credential.Credential) *user.WebAuthnCredential {
+	res := &user.WebAuthnCredential{
+		CredentialId:   c.CredentialID,
+		Name:           c.Name,
+		Transports:     c.TransportList(),
+		SignCount:      c.SignCount,
+		BackupEligible: c.BackupEligible,
+		BackupState:    c.BackupState,
+		CreatedAt:      timestamppb.New(c.CreatedAt),
+	}
+	if c.LastUsedAt != nil {
+		res.LastUsedAt = timestamppb.New(*c.LastUsedAt)
+	}
+	return res
+}
@@ -0,0 +1,109 @@
+package user
+
+import (
+	"testing"
+
+	"github.com/jrapoport/gothic/api/grpc/rpc/user"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/test/tcore"
+	"github.com/jrapoport/gothic/test/tsrv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestUserServer_WebAuthnRegistration(t *testing.T) {
+	t.Parallel()
+	srv := testServer(t)
+	ctx := context.Background()
+	// no id
+	_, err := srv.BeginWebAuthnRegistration(ctx, &emptypb.Empty{})
+	assert.Error(t, err)
+	_, err = srv.FinishWebAuthnRegistration(ctx, &user.FinishWebAuthnRegistrationRequest{})
+	assert.Error(t, err)
+	u, tok := tcore.TestUser(t, srv.API, "", false)
+	ctx = tsrv.RPCAuthContext(t, srv.Config(), tok)
+	// invalid req
+	_, err = srv.FinishWebAuthnRegistration(ctx, nil)
+	assert.Error(t, err)
+	res, err := srv.BeginWebAuthnRegistration(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, res.GetToken())
+	assert.NotEmpty(t, res.GetOptions())
+	assert.NotNil(t, res.GetExpiresAt())
+	auth := tcore.NewAuthenticator(srv.Config())
+	attest := auth.AttestationResponse(t, []byte(res.GetOptions()))
+	// bad token
+	_, err = srv.FinishWebAuthnRegistration(ctx, &user.FinishWebAuthnRegistrationRequest{
+		Token:    "bad",
+		Response: string(attest),
+	})
+	assert.Error(t, err)
+	res, err = srv.BeginWebAuthnRegistration(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	attest = auth.AttestationResponse(t, []byte(res.GetOptions()))
+	cred, err := srv.FinishWebAuthnRegistration(ctx, &user.FinishWebAuthnRegistrationRequest{
+		Token:    res.GetToken(),
+		Name:     "passkey",
+		Response: string(attest),
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, cred.GetCredentialId())
+	assert.Equal(t, "passkey", cred.GetName())
+	assert.NotNil(t, cred.GetCreatedAt())
+	creds, err := srv.GetWebAuthnCredentials(u.ID)
+	require.NoError(t, err)
+	assert.Len(t, creds, 1)
+}
+
+func TestUserServer_WebAuthnCredentials(t *testing.T) {
+	t.Parallel()
+	srv := testServer(t)
+	ctx := context.Background()
+	// no id
+	_, err := srv.ListWebAuthnCredentials(ctx, &emptypb.Empty{})
+	assert.Error(t, err)
+	_, err = srv.RenameWebAuthnCredential(ctx, &user.RenameWebAuthnCredentialRequest{})
+	assert.Error(t, err)
+	_, err = srv.DeleteWebAuthnCredential(ctx, &user.WebAuthnCredentialRequest{})
+	assert.Error(t, err)
+	u, tok := tcore.TestUser(t, srv.API, "", false)
+	ctx = tsrv.RPCAuthContext(t, srv.Config(), tok)
+	// invalid req
+	_, err = srv.RenameWebAuthnCredential(ctx, nil)
+	assert.Error(t, err)
+	_, err = srv.DeleteWebAuthnCredential(ctx, nil)
+	assert.Error(t, err)
+	res, err := srv.ListWebAuthnCredentials(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	assert.Empty(t, res.GetCredentials())
+	tcore.RegisterWebAuthn(t, srv.API, srv.Config(), u)
+	res, err = srv.ListWebAuthnCredentials(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	require.Len(t, res.GetCredentials(), 1)
+	id := res.GetCredentials()[0].GetCredentialId()
+	// bad id
+	_, err = srv.RenameWebAuthnCredential(ctx, &user.RenameWebAuthnCredentialRequest{
+		CredentialId: "bad",
+		Name:         "security key",
+	})
+	assert.Error(t, err)
+	cred, err := srv.RenameWebAuthnCredential(ctx, &user.RenameWebAuthnCredentialRequest{
+		CredentialId: id,
+		Name:         "security key",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "security key", cred.GetName())
+	// bad id
+	_, err = srv.DeleteWebAuthnCredential(ctx, &user.WebAuthnCredentialRequest{
+		CredentialId: "bad",
+	})
+	assert.Error(t, err)
+	_, err = srv.DeleteWebAuthnCredential(ctx, &user.WebAuthnCredentialRequest{
+		CredentialId: id,
+	})
+	require.NoError(t, err)
+	res, err = srv.ListWebAuthnCredentials(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	assert.Empty(t, res.GetCredentials())
+}
@@ -7,14 +7,20 @@ import (
 	"github.com/jrapoport/gothic/api/grpc/rpc/account"
 	"github.com/jrapoport/gothic/api/grpc/rpc/auth"
 	"github.com/jrapoport/gothic/api/grpc/rpc/user"
+	"github.com/jrapoport/gothic/config"
 	"github.com/jrapoport/gothic/core"
 	"github.com/jrapoport/gothic/core/context"
+	guser "github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconf"
 	"github.com/jrapoport/gothic/test/tcore"
 	"github.com/jrapoport/gothic/test/tsrv"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 func accountClient(t *testing.T, h core.Hosted) account.AccountClient {
@@ -55,7 +61,7 @@ func TestRPCWebHost(t *testing.T) {
 	// create a test user
 	c.Signup.AutoConfirm = true
 	c.Security.MaskEmails = false
-	const pass = "1234567890asdfghjkl"
+	const pass = "q8Ld!3xPz#w9Vk2m"
 	test, _ := tcore.TestUser(t, a, pass, false)
 	// unauthenticated call
 	ctx := context.Background()
@@ -95,3 +101,72 @@ func TestRPCWebHost(t *testing.T) {
 		return !h.Online()
 	}, 1*time.Second, 10*time.Millisecond)
 }
+
+func TestRPCWebHost_Tenants(t *testing.T) {
+	t.Parallel()
+	c := tconf.TempDB(t)
+	c.Signup.AutoConfirm = true
+	c.Security.MaskEmails = false
+	c.Tenants.Enabled = true
+	a, err := core.NewAPI(c)
+	require.NoError(t, err)
+	h := NewRPCWebHost(a, "127.0.0.1:0")
+	require.NotNil(t, h)
+	err = h.ListenAndServe()
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return h.Online()
+	}, 1*time.Second, 10*time.Millisecond)
+	t.Cleanup(func() {
+		err = h.Shutdown()
+		assert.NoError(t, err)
+	})
+	ctx := context.Background()
+	ctx.SetAdminID(guser.SuperAdminID)
+	const acmeHost = "acme.example.com"
+	tn, err := a.CreateTenant(ctx, "acme", acmeHost, config.TenantConfig{
+		JWT: config.TenantJWT{Secret: "acme-secret"},
+	})
+	require.NoError(t, err)
+	ta, err := a.TenantAPI(tn.ID)
+	require.NoError(t, err)
+	tc := core.NewServer(ta, "test").Config()
+	const pass = "q8Ld!3xPz#w9Vk2m"
+	test, _ := tcore.TestUser(t, ta, pass, false)
+	req := &account.LoginRequest{
+		Email:    test.Email,
+		Password: pass,
+	}
+	ac := accountClient(t, h)
+	// default tenant
+	_, err = ac.Login(ctx, req)
+	assert.Error(t, err)
+	// unknown tenant
+	md := metadata.Pairs(c.Tenants.Header, "missing")
+	_, err = ac.Login(metadata.NewOutgoingContext(ctx, md), req)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	// tenant metadata
+	md = metadata.Pairs(c.Tenants.Header, "acme")
+	ur, err := ac.Login(metadata.NewOutgoingContext(ctx, md), req)
+	require.NoError(t, err)
+	assert.Equal(t, test.Email, ur.Email)
+	// authenticated tenant call
+	uc := userClient(t, h)
+	actx := tsrv.RPCAuthContext(t, tc, ur.Token.Access)
+	_, err = uc.GetUser(actx, &user.UserRequest{})
+	assert.Error(t, err)
+	tctx := metadata.AppendToOutgoingContext(actx, c.Tenants.Header, tn.ID.String())
+	res, err := uc.GetUser(tctx, &user.UserRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, test.Email, res.Email)
+	// tenant authority
+	cc, err := grpc.Dial(h.Address(), grpc.WithInsecure(), grpc.WithAuthority(acmeHost))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		err = cc.Close()
+		assert.NoError(t, err)
+	})
+	ur, err = account.NewAccountClient(cc).Login(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, test.Email, ur.Email)
+}
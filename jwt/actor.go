@@ -0,0 +1,35 @@
+package jwt
+
+import "github.com/google/uuid"
+
+// ActorKey is the jwt key for the actor of an impersonation (RFC 8693).
+const ActorKey = "act"
+
+// SetActor sets the admin that is acting as (impersonating) the user. If the
+// admin id is uuid.Nil, the actor is removed.
+func (c *UserClaims) SetActor(aid uuid.UUID) {
+	if aid == uuid.Nil {
+		_ = c.Remove(ActorKey)
+		return
+	}
+	_ = c.Set(ActorKey, map[string]interface{}{
+		"sub": aid.String(),
+	})
+}
+
+// ActorID returns the id of the admin that is impersonating the user.
+func (c UserClaims) ActorID() uuid.UUID {
+	v, _ := c.Get(ActorKey)
+	act, _ := v.(map[string]interface{})
+	sub, _ := act["sub"].(string)
+	id, err := uuid.Parse(sub)
+	if err != nil {
+		return uuid.Nil
+	}
+	return id
+}
+
+// Impersonated returns true if an admin is impersonating the user.
+func (c UserClaims) Impersonated() bool {
+	return c.ActorID() != uuid.Nil
+}
@@ -0,0 +1,39 @@
+package jwt
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserClaims_Actor(t *testing.T) {
+	t.Parallel()
+	c := tconf.Config(t)
+	u := &user.User{
+		ID:       uuid.New(),
+		Provider: c.Provider(),
+		Role:     user.RoleUser,
+		Status:   user.Active,
+	}
+	claims := NewUserClaims(u)
+	assert.Equal(t, uuid.Nil, claims.ActorID())
+	assert.False(t, claims.Impersonated())
+	aid := uuid.New()
+	claims.SetActor(aid)
+	assert.Equal(t, aid, claims.ActorID())
+	assert.True(t, claims.Impersonated())
+	b, err := NewToken(c.JWT, claims).Bearer()
+	require.NoError(t, err)
+	claims, err = ParseUserClaims(c.JWT, b)
+	require.NoError(t, err)
+	assert.Equal(t, u.ID, claims.UserID())
+	assert.Equal(t, aid, claims.ActorID())
+	assert.True(t, claims.Impersonated())
+	claims.SetActor(uuid.Nil)
+	assert.Equal(t, uuid.Nil, claims.ActorID())
+	assert.False(t, claims.Impersonated())
+}
@@ -2,12 +2,29 @@ package jwt
 
 import (
 	"strings"
+	"time"
 
 	"github.com/lestrrat-go/jwx/jwt"
 )
 
-// ScopeKey is the jwt key for the scope field
-const ScopeKey = "scope"
+// Standard claims jwt keys
+const (
+	// ScopeKey is the jwt key for the scope field
+	ScopeKey = "scope"
+	// IssuedKey is the jwt key for the time the token was issued
+	// in microseconds, since iat is only precise to the second.
+	IssuedKey = "iat_us"
+	// SubjectTypeKey is the jwt key for the type of the subject.
+	SubjectTypeKey = "sbt"
+)
+
+// Subject types
+const (
+	// SubjectUser is the subject type of tokens issued to users.
+	SubjectUser = "user"
+	// SubjectService is the subject type of tokens issued to service clients.
+	SubjectService = "service"
+)
 
 // Claims interface for jwt.
 type Claims interface {
@@ -50,6 +67,34 @@ func (c *StandardClaims) Scope() []string {
 	return strings.Split(scopes, " ")
 }
 
+// SubjectType returns the type of the subject. Tokens without
+// a subject type are issued to users.
+func (c *StandardClaims) SubjectType() string {
+	v, ok := c.Get(SubjectTypeKey)
+	if !ok {
+		return SubjectUser
+	}
+	typ, _ := v.(string)
+	return typ
+}
+
+// Issued returns the time the token was issued. Unlike
+// IssuedAt it is precise to the microsecond if it is set.
+func (c *StandardClaims) Issued() time.Time {
+	v, ok := c.Get(IssuedKey)
+	if !ok {
+		return c.IssuedAt()
+	}
+	switch us := v.(type) {
+	case int64:
+		return time.UnixMicro(us).UTC()
+	case float64:
+		return time.UnixMicro(int64(us)).UTC()
+	default:
+		return c.IssuedAt()
+	}
+}
+
 // ParseToken handles the parsed values coming back from a token
 func (c *StandardClaims) parseToken(tok *Token) {
 	c.Token = tok.Token
@@ -2,8 +2,10 @@ package jwt
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lestrrat-go/jwx/jwt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -21,3 +23,22 @@ func TestNewStandardClaims(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, []string{}, claims.Scope())
 }
+
+func TestStandardClaims_Issued(t *testing.T) {
+	claims := NewStandardClaims(uuid.New().String())
+	assert.True(t, claims.Issued().IsZero())
+	now := time.Now().UTC().Truncate(time.Second)
+	err := claims.Set(jwt.IssuedAtKey, now)
+	require.NoError(t, err)
+	assert.Equal(t, now, claims.Issued())
+	iat := now.Add(time.Microsecond)
+	err = claims.Set(IssuedKey, iat.UnixMicro())
+	require.NoError(t, err)
+	assert.Equal(t, iat, claims.Issued())
+	err = claims.Set(IssuedKey, float64(iat.UnixMicro()))
+	require.NoError(t, err)
+	assert.Equal(t, iat, claims.Issued())
+	err = claims.Set(IssuedKey, "bad")
+	require.NoError(t, err)
+	assert.Equal(t, now, claims.Issued())
+}
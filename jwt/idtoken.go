@@ -0,0 +1,75 @@
+package jwt
+
+import (
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/models/user"
+)
+
+// IDClaims jwt keys
+const (
+	NonceKey           = "nonce"
+	AuthorizedPartyKey = "azp"
+	AuthTimeKey        = "auth_time"
+)
+
+// IDClaims holds the claims for an OpenID Connect ID token.
+type IDClaims struct {
+	UserClaims
+}
+
+var _ Claims = (*IDClaims)(nil)
+
+// NewIDClaims returns a new set of ID token claims for the user issued to the client.
+func NewIDClaims(u *user.User, clientID, nonce string) *IDClaims {
+	uc := NewUserClaims(u)
+	if uc == nil {
+		return nil
+	}
+	_ = uc.Set(AuthorizedPartyKey, clientID)
+	if nonce != "" {
+		_ = uc.Set(NonceKey, nonce)
+	}
+	if u.LoginAt != nil {
+		_ = uc.Set(AuthTimeKey, u.LoginAt.Unix())
+	}
+	return &IDClaims{*uc}
+}
+
+// Nonce returns the nonce the client sent with the authorization request.
+func (c IDClaims) Nonce() string {
+	v, _ := c.Get(NonceKey)
+	nonce, _ := v.(string)
+	return nonce
+}
+
+// AuthorizedParty returns the client id of the client the token was issued to.
+func (c IDClaims) AuthorizedParty() string {
+	v, _ := c.Get(AuthorizedPartyKey)
+	azp, _ := v.(string)
+	return azp
+}
+
+// NewIDToken returns a new ID token for the claims issued by the issuer.
+// The audience of the token is the client the token was issued to.
+func NewIDToken(c config.JWT, issuer string, claims *IDClaims) *Token {
+	c.Issuer = issuer
+	c.Audience = ""
+	c.Scope = ""
+	if claims != nil {
+		c.Audience = claims.AuthorizedParty()
+	}
+	return NewToken(c, claims)
+}
+
+// ParseIDClaims parses and returns a set of IDClaims from an ID token
+// issued by the issuer to the client.
+func ParseIDClaims(c config.JWT, issuer, clientID, token string) (*IDClaims, error) {
+	c.Issuer = issuer
+	c.Audience = clientID
+	claims := &IDClaims{}
+	err := ParseClaims(c, token, claims)
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
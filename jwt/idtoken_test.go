@@ -0,0 +1,59 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/types/provider"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconf"
+	"github.com/jrapoport/gothic/test/tutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewIDClaims(t *testing.T) {
+	const (
+		issuer = "https://id.example.com"
+		nonce  = "nonce"
+	)
+	c := tconf.Config(t)
+	assert.Nil(t, NewIDClaims(nil, "", ""))
+	now := time.Now().Truncate(time.Second)
+	u := &user.User{
+		ID:       uuid.New(),
+		Provider: provider.Google,
+		Role:     user.RoleUser,
+		Status:   user.Verified,
+		Email:    tutils.RandomEmail(),
+		LoginAt:  &now,
+	}
+	cid := uuid.New().String()
+	claims := NewIDClaims(u, cid, nonce)
+	require.NotNil(t, claims)
+	assert.Equal(t, u.ID, claims.UserID())
+	assert.Equal(t, cid, claims.AuthorizedParty())
+	assert.Equal(t, nonce, claims.Nonce())
+	b, err := NewIDToken(c.JWT, issuer, claims).Bearer()
+	require.NoError(t, err)
+	parsed, err := ParseIDClaims(c.JWT, issuer, cid, b)
+	require.NoError(t, err)
+	assert.Equal(t, u.ID, parsed.UserID())
+	assert.Equal(t, []string{cid}, parsed.Audience())
+	assert.Equal(t, issuer, parsed.Issuer())
+	assert.Equal(t, nonce, parsed.Nonce())
+	assert.Equal(t, u.IsVerified(), parsed.Verified())
+	v, ok := parsed.Get(AuthTimeKey)
+	require.True(t, ok)
+	assert.EqualValues(t, now.Unix(), v)
+	// wrong client
+	_, err = ParseIDClaims(c.JWT, issuer, uuid.New().String(), b)
+	assert.Error(t, err)
+	// ID tokens are not bearer tokens
+	_, err = ParseUserClaims(c.JWT, b)
+	assert.Error(t, err)
+	// no nonce
+	claims = NewIDClaims(u, cid, "")
+	assert.Empty(t, claims.Nonce())
+}
@@ -0,0 +1,27 @@
+package jwt
+
+import (
+	"github.com/jrapoport/gothic/config"
+	"github.com/lestrrat-go/jwx/jwa"
+	"github.com/lestrrat-go/jwx/jwk"
+)
+
+// PublicKeySet returns the public keys that can be used to verify tokens as a
+// JSON Web Key Set (JWKS). Symmetric keys (secrets) are never included.
+func PublicKeySet(c config.JWT) (jwk.Set, error) {
+	keys := c.KeySet()
+	set := jwk.NewSet()
+	for i := 0; i < keys.Len(); i++ {
+		key, _ := keys.Get(i)
+		if key.KeyType() == jwa.OctetSeq {
+			continue
+		}
+		pub, err := jwk.PublicKeyOf(key)
+		if err != nil {
+			return nil, err
+		}
+		_ = pub.Set(jwk.KeyUsageKey, jwk.ForSignature)
+		set.Add(pub)
+	}
+	return set, nil
+}
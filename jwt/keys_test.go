@@ -0,0 +1,125 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/config"
+	"github.com/lestrrat-go/jwx/jwa"
+	"github.com/lestrrat-go/jwx/jws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	rsaKey  = "./testdata/rsa-key.pem"
+	rsaKey2 = "./testdata/rsa-key-2.pem"
+)
+
+func signedKeyID(t *testing.T, c config.JWT) (string, string) {
+	tok := NewToken(c, NewStandardClaims(uuid.New().String()))
+	b, err := tok.Bearer()
+	require.NoError(t, err)
+	msg, err := jws.ParseString(b)
+	require.NoError(t, err)
+	require.Len(t, msg.Signatures(), 1)
+	return b, msg.Signatures()[0].ProtectedHeaders().KeyID()
+}
+
+func TestKeyRotation(t *testing.T) {
+	old := config.JWT{
+		PEM:       config.PEM{PrivateKey: rsaKey},
+		Algorithm: string(jwa.RS512),
+		KeyID:     "key-1",
+	}
+	bearer1, kid := signedKeyID(t, old)
+	assert.Equal(t, "key-1", kid)
+	// rotate the keys
+	c := config.JWT{
+		PEM:       config.PEM{PrivateKey: rsaKey2},
+		Algorithm: string(jwa.RS256),
+		KeyID:     "key-2",
+		Keys: config.VerifyKeys{
+			"key-1": {
+				PublicKey: rsaKey + ".pub",
+				Algorithm: string(jwa.RS512),
+			},
+		},
+	}
+	bearer2, kid := signedKeyID(t, c)
+	assert.Equal(t, "key-2", kid)
+	var claims StandardClaims
+	err := ParseClaims(c, bearer1, &claims)
+	assert.NoError(t, err)
+	err = ParseClaims(c, bearer2, &claims)
+	assert.NoError(t, err)
+	// the old key cannot verify new tokens
+	err = ParseClaims(old, bearer2, &claims)
+	assert.Error(t, err)
+	// the grace period has ended
+	vk := c.Keys["key-1"]
+	vk.ExpiresAt = time.Now().Add(-time.Minute).Format(time.RFC3339)
+	c.Keys = config.VerifyKeys{"key-1": vk}
+	err = ParseClaims(c, bearer1, &claims)
+	assert.Error(t, err)
+	err = ParseClaims(c, bearer2, &claims)
+	assert.NoError(t, err)
+	// unknown key id
+	c.Keys = nil
+	err = ParseClaims(c, bearer1, &claims)
+	assert.Error(t, err)
+	// tokens without a key id use the signing key
+	c.KeyID = ""
+	bearer3, kid := signedKeyID(t, c)
+	assert.Empty(t, kid)
+	err = ParseClaims(c, bearer3, &claims)
+	assert.NoError(t, err)
+}
+
+func TestPublicKeySet(t *testing.T) {
+	c := config.JWT{
+		PEM:       config.PEM{PrivateKey: rsaKey2},
+		Algorithm: string(jwa.RS256),
+		KeyID:     "key-2",
+		Keys: config.VerifyKeys{
+			"key-1": {
+				PublicKey: rsaKey + ".pub",
+				Algorithm: string(jwa.RS512),
+			},
+			"secret": {
+				Secret: "i-am-a-secret",
+			},
+			"expired": {
+				PublicKey: rsaKey + ".pub",
+				ExpiresAt: time.Now().Add(-time.Minute).Format(time.RFC3339),
+			},
+		},
+	}
+	set, err := PublicKeySet(c)
+	require.NoError(t, err)
+	require.Equal(t, 2, set.Len())
+	kids := []string{"key-2", "key-1"}
+	algs := []jwa.SignatureAlgorithm{jwa.RS256, jwa.RS512}
+	for i := 0; i < set.Len(); i++ {
+		key, ok := set.Get(i)
+		require.True(t, ok)
+		assert.Equal(t, kids[i], key.KeyID())
+		assert.Equal(t, algs[i].String(), key.Algorithm())
+		assert.Equal(t, jwa.RSA, key.KeyType())
+		assert.EqualValues(t, "sig", key.KeyUsage())
+		var raw interface{}
+		err = key.Raw(&raw)
+		require.NoError(t, err)
+		assert.NotNil(t, raw)
+	}
+	// secrets are never published
+	c = config.JWT{
+		Secret:    "i-am-a-secret",
+		Algorithm: string(jwa.HS256),
+		KeyID:     "secret",
+	}
+	set, err = PublicKeySet(c)
+	require.NoError(t, err)
+	assert.Equal(t, 0, set.Len())
+}
@@ -0,0 +1,45 @@
+package jwt
+
+import "github.com/google/uuid"
+
+// Organization jwt keys
+const (
+	// OrgKey is the jwt key for the active organization of the user.
+	OrgKey = "org"
+	// OrgRoleKey is the jwt key for the role of the user in the active organization.
+	OrgRoleKey = "orl"
+)
+
+// SetOrg sets the active organization of the user and the role of the user
+// in the organization. If the organization id is uuid.Nil, the active
+// organization is removed.
+func (c *UserClaims) SetOrg(orgID uuid.UUID, role string) {
+	if orgID == uuid.Nil {
+		_ = c.Remove(OrgKey)
+		_ = c.Remove(OrgRoleKey)
+		return
+	}
+	_ = c.Set(OrgKey, orgID.String())
+	_ = c.Set(OrgRoleKey, role)
+}
+
+// OrgID returns the id of the active organization of the user.
+func (c UserClaims) OrgID() uuid.UUID {
+	v, _ := c.Get(OrgKey)
+	oid, _ := v.(string)
+	id, err := uuid.Parse(oid)
+	if err != nil {
+		return uuid.Nil
+	}
+	return id
+}
+
+// OrgRole returns the role of the user in the active organization.
+func (c UserClaims) OrgRole() string {
+	if c.OrgID() == uuid.Nil {
+		return ""
+	}
+	v, _ := c.Get(OrgRoleKey)
+	role, _ := v.(string)
+	return role
+}
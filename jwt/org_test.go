@@ -0,0 +1,22 @@
+package jwt
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserClaims_Org(t *testing.T) {
+	t.Parallel()
+	claims := &UserClaims{*NewStandardClaims(uuid.New().String())}
+	assert.Equal(t, uuid.Nil, claims.OrgID())
+	assert.Empty(t, claims.OrgRole())
+	oid := uuid.New()
+	claims.SetOrg(oid, "admin")
+	assert.Equal(t, oid, claims.OrgID())
+	assert.Equal(t, "admin", claims.OrgRole())
+	claims.SetOrg(uuid.Nil, "admin")
+	assert.Equal(t, uuid.Nil, claims.OrgID())
+	assert.Empty(t, claims.OrgRole())
+}
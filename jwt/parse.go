@@ -2,11 +2,14 @@ package jwt
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/jrapoport/gothic/config"
 	"github.com/jrapoport/gothic/models/types"
 	"github.com/lestrrat-go/jwx/jwa"
+	"github.com/lestrrat-go/jwx/jwk"
+	"github.com/lestrrat-go/jwx/jws"
 	"github.com/lestrrat-go/jwx/jwt"
 )
 
@@ -34,8 +37,10 @@ func ParseData(c config.JWT, token string) (types.Map, error) {
 }
 
 func parse(c config.JWT, token string) (jwt.Token, error) {
-	alg := jwa.SignatureAlgorithm(c.Algorithm)
-	key := c.PublicKey()
+	alg, key, err := verifyKey(c, token)
+	if err != nil {
+		return nil, err
+	}
 	opts := []jwt.ParseOption{
 		jwt.WithValidate(true),
 		jwt.WithVerify(alg, key),
@@ -57,3 +62,25 @@ func parse(c config.JWT, token string) (jwt.Token, error) {
 	}
 	return tok, nil
 }
+
+// verifyKey returns the key to verify the token by its key id ("kid").
+// The algorithm always comes from the configured key, never the token.
+func verifyKey(c config.JWT, token string) (jwa.SignatureAlgorithm, jwk.Key, error) {
+	msg, err := jws.ParseString(token)
+	if err != nil {
+		return "", nil, err
+	}
+	var kid string
+	if sigs := msg.Signatures(); len(sigs) > 0 {
+		kid = sigs[0].ProtectedHeaders().KeyID()
+	}
+	key, ok := c.VerifyKey(kid)
+	if !ok {
+		return "", nil, fmt.Errorf("invalid key id: %s", kid)
+	}
+	alg := jwa.SignatureAlgorithm(c.Algorithm)
+	if a := key.Algorithm(); a != "" {
+		alg = jwa.SignatureAlgorithm(a)
+	}
+	return alg, key, nil
+}
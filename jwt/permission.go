@@ -0,0 +1,41 @@
+package jwt
+
+import "strings"
+
+// PermissionsKey is the jwt key for the permissions of the user.
+const PermissionsKey = "perms"
+
+// SetPermissions sets the permissions of the user.
+func (c *UserClaims) SetPermissions(perms []string) {
+	if len(perms) == 0 {
+		_ = c.Remove(PermissionsKey)
+		return
+	}
+	_ = c.Set(PermissionsKey, strings.Join(perms, " "))
+}
+
+// Permissions returns the permissions of the user.
+func (c UserClaims) Permissions() []string {
+	v, _ := c.Get(PermissionsKey)
+	perms, _ := v.(string)
+	return strings.Fields(perms)
+}
+
+// HasPermission returns true if the user has the permission.
+// Admins have all the permissions.
+func (c UserClaims) HasPermission(perm string) bool {
+	if c.Admin() {
+		return true
+	}
+	for _, p := range c.Permissions() {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// Privileged returns true if the user is an admin or has any permissions.
+func (c UserClaims) Privileged() bool {
+	return c.Admin() || len(c.Permissions()) > 0
+}
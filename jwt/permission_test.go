@@ -0,0 +1,28 @@
+package jwt
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserClaims_Permissions(t *testing.T) {
+	t.Parallel()
+	claims := &UserClaims{*NewStandardClaims(uuid.New().String())}
+	assert.Empty(t, claims.Permissions())
+	assert.False(t, claims.HasPermission("users:read"))
+	assert.False(t, claims.Privileged())
+	claims.SetPermissions([]string{"audit:read", "users:read"})
+	assert.Equal(t, []string{"audit:read", "users:read"}, claims.Permissions())
+	assert.True(t, claims.HasPermission("users:read"))
+	assert.False(t, claims.HasPermission("users:ban"))
+	assert.True(t, claims.Privileged())
+	claims.SetPermissions(nil)
+	assert.Empty(t, claims.Permissions())
+	assert.False(t, claims.Privileged())
+	// admins have all the permissions
+	_ = claims.Set(AdminKey, true)
+	assert.True(t, claims.HasPermission("users:ban"))
+	assert.True(t, claims.Privileged())
+}
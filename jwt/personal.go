@@ -0,0 +1,65 @@
+package jwt
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/lestrrat-go/jwx/jwt"
+)
+
+// PersonalTokenKey is the jwt key for the token id of a personal access token.
+const PersonalTokenKey = "pat"
+
+// NewPersonalClaims returns a new set of claims for the user of a personal
+// access token. The claims are never signed, they are only used to authorize
+// a request that was authenticated with the personal access token. Unless the
+// personal access token has the admin scope, the claims are not admin claims.
+func NewPersonalClaims(u *user.User, pt *token.PersonalToken) *UserClaims {
+	if pt == nil {
+		return nil
+	}
+	c := NewUserClaims(u)
+	if c == nil {
+		return nil
+	}
+	iat := time.Now().UTC().Truncate(time.Microsecond)
+	_ = c.Set(jwt.IssuedAtKey, iat)
+	_ = c.Set(IssuedKey, iat.UnixMicro())
+	_ = c.Set(jwt.JwtIDKey, pt.TokenID.String())
+	_ = c.Set(PersonalTokenKey, pt.TokenID.String())
+	_ = c.Set(ScopeKey, pt.Scope)
+	if !c.HasScope(token.ScopeAdmin) {
+		_ = c.Set(AdminKey, false)
+	}
+	return c
+}
+
+// PersonalTokenID returns the token id of the personal access token the
+// claims are for. If the claims are not for a personal access token,
+// uuid.Nil is returned.
+func (c UserClaims) PersonalTokenID() uuid.UUID {
+	v, _ := c.Get(PersonalTokenKey)
+	tid, _ := v.(string)
+	id, err := uuid.Parse(tid)
+	if err != nil {
+		return uuid.Nil
+	}
+	return id
+}
+
+// PersonalToken returns true if the claims are for a personal access token.
+func (c UserClaims) PersonalToken() bool {
+	return c.PersonalTokenID() != uuid.Nil
+}
+
+// HasScope returns true if the claims have the scope.
+func (c UserClaims) HasScope(scope string) bool {
+	for _, s := range c.Scope() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPersonalClaims(t *testing.T) {
+	u := &user.User{
+		ID:     uuid.New(),
+		Role:   user.RoleAdmin,
+		Status: user.Active,
+		Email:  tutils.RandomEmail(),
+	}
+	u.CreatedAt = time.Now()
+	pt := token.NewPersonalToken(u.ID, "test", "read write", token.NoExpiration)
+	assert.Nil(t, NewPersonalClaims(u, nil))
+	assert.Nil(t, NewPersonalClaims(nil, pt))
+	claims := NewPersonalClaims(u, pt)
+	require.NotNil(t, claims)
+	assert.Equal(t, u.ID, claims.UserID())
+	assert.True(t, claims.PersonalToken())
+	assert.Equal(t, pt.TokenID, claims.PersonalTokenID())
+	assert.Equal(t, pt.TokenID.String(), claims.JwtID())
+	assert.False(t, claims.Issued().IsZero())
+	assert.True(t, claims.HasScope(token.ScopeRead))
+	assert.True(t, claims.HasScope(token.ScopeWrite))
+	assert.False(t, claims.HasScope(token.ScopeAdmin))
+	assert.False(t, claims.Admin())
+	pt = token.NewPersonalToken(u.ID, "test", "read admin", token.NoExpiration)
+	claims = NewPersonalClaims(u, pt)
+	require.NotNil(t, claims)
+	assert.True(t, claims.HasScope(token.ScopeAdmin))
+	assert.True(t, claims.Admin())
+	// user claims are not personal claims
+	uc := NewUserClaims(u)
+	assert.False(t, uc.PersonalToken())
+	assert.Equal(t, uuid.Nil, uc.PersonalTokenID())
+	assert.False(t, uc.HasScope(token.ScopeRead))
+}
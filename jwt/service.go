@@ -0,0 +1,53 @@
+package jwt
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/models/client"
+)
+
+// ServiceClaims holds the claims for a token issued to a service client.
+type ServiceClaims struct {
+	StandardClaims
+}
+
+var _ Claims = (*ServiceClaims)(nil)
+
+// NewServiceClaims returns a new set of claims for the service client.
+func NewServiceClaims(s *client.Service) *ServiceClaims {
+	if s == nil {
+		return nil
+	}
+	c := &ServiceClaims{
+		*NewStandardClaims(s.ClientID),
+	}
+	_ = c.Set(SubjectTypeKey, SubjectService)
+	return c
+}
+
+// ClientID returns the client id of the service client.
+func (c ServiceClaims) ClientID() string {
+	return c.Subject()
+}
+
+// NewServiceToken returns a new Token for the service client with the scopes.
+func NewServiceToken(c config.JWT, s *client.Service, scopes []string) *Token {
+	c.Scope = strings.Join(scopes, ",")
+	return NewToken(c, NewServiceClaims(s))
+}
+
+// ParseServiceClaims parses and returns a set of ServiceClaims from a token.
+// Tokens issued to users are rejected.
+func ParseServiceClaims(c config.JWT, token string) (*ServiceClaims, error) {
+	claims := &ServiceClaims{}
+	err := ParseClaims(c, token, claims)
+	if err != nil {
+		return nil, err
+	}
+	if claims.SubjectType() != SubjectService {
+		return nil, errors.New("invalid subject type")
+	}
+	return claims, nil
+}
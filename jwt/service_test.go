@@ -0,0 +1,57 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/client"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconf"
+	"github.com/jrapoport/gothic/test/tutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewServiceClaims(t *testing.T) {
+	assert.Nil(t, NewServiceClaims(nil))
+	s := client.NewService("test", nil, uuid.New(), nil)
+	claims := NewServiceClaims(s)
+	require.NotNil(t, claims)
+	assert.Equal(t, s.ClientID, claims.ClientID())
+	assert.Equal(t, SubjectService, claims.SubjectType())
+}
+
+func TestNewServiceToken(t *testing.T) {
+	c := tconf.Config(t)
+	c.JWT.Expiration = 100 * time.Minute
+	scopes := []string{"read", "write"}
+	s := client.NewService("test", scopes, uuid.New(), nil)
+	tok := NewServiceToken(c.JWT, s, scopes)
+	require.NotNil(t, tok)
+	assert.Equal(t, c.JWT.Expiration, tok.Expiration())
+	b, err := tok.Bearer()
+	require.NoError(t, err)
+	claims, err := ParseServiceClaims(c.JWT, b)
+	require.NoError(t, err)
+	assert.Equal(t, s.ClientID, claims.ClientID())
+	assert.Equal(t, scopes, claims.Scope())
+	// service tokens are not user tokens
+	_, err = ParseUserClaims(c.JWT, b)
+	assert.Error(t, err)
+	uc := &UserClaims{claims.StandardClaims}
+	assert.Equal(t, uuid.Nil, uc.UserID())
+	// user tokens are not service tokens
+	u := &user.User{
+		ID:    uuid.New(),
+		Role:  user.RoleUser,
+		Email: tutils.RandomEmail(),
+	}
+	b, err = NewUserToken(c.JWT, u).Bearer()
+	require.NoError(t, err)
+	_, err = ParseServiceClaims(c.JWT, b)
+	assert.Error(t, err)
+	uc, err = ParseUserClaims(c.JWT, b)
+	require.NoError(t, err)
+	assert.Equal(t, SubjectUser, uc.SubjectType())
+}
@@ -4,6 +4,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/jrapoport/gothic/config"
 	"github.com/jrapoport/gothic/models/types"
 	"github.com/lestrrat-go/jwx/jwa"
@@ -30,6 +31,9 @@ func NewToken(c config.JWT, claims Claims) *Token {
 	iat := time.Now().UTC().Truncate(time.Microsecond)
 	_ = tok.Set(jwt.IssuerKey, iss)
 	_ = tok.Set(jwt.IssuedAtKey, iat)
+	_ = tok.Set(IssuedKey, iat.UnixMicro())
+	// every token gets a unique id so it can be revoked
+	_ = tok.Set(jwt.JwtIDKey, uuid.New().String())
 	if c.Audience != "" {
 		aud := strings.Split(c.Audience, ",")
 		_ = tok.Set(jwt.AudienceKey, aud)
@@ -74,6 +74,9 @@ func TestNewToken(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, sub, parsed.Subject())
 		assert.Equal(t, []string{"foo", "bar"}, parsed.Scope())
+		_, err = uuid.Parse(parsed.JwtID())
+		assert.NoError(t, err)
+		assert.Equal(t, tok.IssuedAt(), parsed.Issued())
 	}
 }
 
@@ -1,6 +1,8 @@
 package jwt
 
 import (
+	"errors"
+
 	"github.com/google/uuid"
 	"github.com/jrapoport/gothic/config"
 	"github.com/jrapoport/gothic/models/types/provider"
@@ -14,6 +16,7 @@ const (
 	RestrictedKey = "rst"
 	ConfirmedKey  = "cnf"
 	VerifiedKey   = "vrd"
+	SessionKey    = "sid"
 )
 
 // UserClaims is a struct to hold extended jwt claims
@@ -41,6 +44,9 @@ func NewUserClaims(u *user.User) *UserClaims {
 
 // UserID returns the jwt subject as a uuid.
 func (c UserClaims) UserID() uuid.UUID {
+	if c.SubjectType() != SubjectUser {
+		return uuid.Nil
+	}
 	uid, err := uuid.Parse(c.Subject())
 	if err != nil || uid == user.SuperAdminID {
 		return uuid.Nil
@@ -78,6 +84,17 @@ func (c UserClaims) Verified() bool {
 	return c.getBool(VerifiedKey)
 }
 
+// SessionID returns the session id of the refresh token the claims were issued with.
+func (c UserClaims) SessionID() uuid.UUID {
+	v, _ := c.Get(SessionKey)
+	sid, _ := v.(string)
+	id, err := uuid.Parse(sid)
+	if err != nil {
+		return uuid.Nil
+	}
+	return id
+}
+
 func (c UserClaims) getBool(key string) bool {
 	v, _ := c.Get(key)
 	b, _ := v.(bool)
@@ -85,12 +102,16 @@ func (c UserClaims) getBool(key string) bool {
 }
 
 // ParseUserClaims parses and returns a set of UserClaims form a token.
+// Tokens issued to service clients are rejected.
 func ParseUserClaims(c config.JWT, token string) (*UserClaims, error) {
 	claims := &UserClaims{}
 	err := ParseClaims(c, token, claims)
 	if err != nil {
 		return nil, err
 	}
+	if claims.SubjectType() != SubjectUser {
+		return nil, errors.New("invalid subject type")
+	}
 	return claims, nil
 }
 
@@ -98,3 +119,23 @@ func ParseUserClaims(c config.JWT, token string) (*UserClaims, error) {
 func NewUserToken(c config.JWT, u *user.User) *Token {
 	return NewToken(c, NewUserClaims(u))
 }
+
+// NewSessionClaims returns a new set of claims for the user for the
+// session with the permissions of the user.
+func NewSessionClaims(u *user.User, sessionID uuid.UUID, perms []string) *UserClaims {
+	claims := NewUserClaims(u)
+	if claims == nil {
+		return nil
+	}
+	if sessionID != uuid.Nil {
+		_ = claims.Set(SessionKey, sessionID.String())
+	}
+	claims.SetPermissions(perms)
+	return claims
+}
+
+// NewSessionToken returns a new Token for the user with UserClaims for the
+// session and the permissions of the user.
+func NewSessionToken(c config.JWT, u *user.User, sessionID uuid.UUID, perms []string) *Token {
+	return NewToken(c, NewSessionClaims(u, sessionID, perms))
+}
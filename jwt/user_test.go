@@ -91,3 +91,38 @@ func TestNewUserToken(t *testing.T) {
 	claims.SetSubject("1")
 	assert.Equal(t, uuid.Nil, claims.UserID())
 }
+
+func TestNewSessionToken(t *testing.T) {
+	c := tconf.Config(t)
+	u := &user.User{
+		ID:       uuid.New(),
+		Provider: c.Provider(),
+		Role:     user.RoleUser,
+		Status:   user.Active,
+	}
+	sid := uuid.New()
+	perms := []string{"audit:read", "users:read"}
+	tok := NewSessionToken(c.JWT, u, sid, perms)
+	require.NotNil(t, tok)
+	b, err := tok.Bearer()
+	require.NoError(t, err)
+	claims, err := ParseUserClaims(c.JWT, b)
+	assert.NoError(t, err)
+	assert.Equal(t, u.ID, claims.UserID())
+	assert.Equal(t, sid, claims.SessionID())
+	assert.Equal(t, perms, claims.Permissions())
+	// no session
+	tok = NewSessionToken(c.JWT, u, uuid.Nil, nil)
+	b, err = tok.Bearer()
+	require.NoError(t, err)
+	claims, err = ParseUserClaims(c.JWT, b)
+	assert.NoError(t, err)
+	assert.Equal(t, uuid.Nil, claims.SessionID())
+	assert.Empty(t, claims.Permissions())
+	tok = NewUserToken(c.JWT, u)
+	b, err = tok.Bearer()
+	require.NoError(t, err)
+	claims, err = ParseUserClaims(c.JWT, b)
+	assert.NoError(t, err)
+	assert.Equal(t, uuid.Nil, claims.SessionID())
+}
@@ -425,6 +425,74 @@ func (m *Client) SendResetPassword(to, token, referrerURL string) error {
 	return m.sendTemplate(e)
 }
 
+// SendPasswordExpiry sends a password expiry reminder mail to a user
+func (m *Client) SendPasswordExpiry(to, token, referrerURL string, expires time.Time) error {
+	if m.IsOffline() {
+		m.log.Warn("mail client is offline")
+		return nil
+	}
+	if token == "" {
+		return errors.New("invalid token")
+	}
+	toAddr, err := parseAddress(to)
+	if err != nil {
+		return err
+	}
+	e := template.NewPasswordExpiry(m.config.PasswordExpiry, toAddr, token, referrerURL, expires)
+	return m.sendTemplate(e)
+}
+
+// SendTokenReused sends a security alert mail to a user after the reuse of a refresh token
+func (m *Client) SendTokenReused(to, token, referrerURL string) error {
+	if m.IsOffline() {
+		m.log.Warn("mail client is offline")
+		return nil
+	}
+	if token == "" {
+		return errors.New("invalid token")
+	}
+	toAddr, err := parseAddress(to)
+	if err != nil {
+		return err
+	}
+	e := template.NewTokenReused(m.config.TokenReused, toAddr, token, referrerURL)
+	return m.sendTemplate(e)
+}
+
+// SendMagicLink sends a passwordless login mail to a user
+func (m *Client) SendMagicLink(to, token, referrerURL string) error {
+	if m.IsOffline() {
+		m.log.Warn("mail client is offline")
+		return nil
+	}
+	if token == "" {
+		return errors.New("invalid token")
+	}
+	toAddr, err := parseAddress(to)
+	if err != nil {
+		return err
+	}
+	e := template.NewMagicLink(m.config.MagicLink, toAddr, token, referrerURL)
+	return m.sendTemplate(e)
+}
+
+// SendUnlockUser sends an unlock mail to a locked user
+func (m *Client) SendUnlockUser(to, token, referrerURL string) error {
+	if m.IsOffline() {
+		m.log.Warn("mail client is offline")
+		return nil
+	}
+	if token == "" {
+		return errors.New("invalid token")
+	}
+	toAddr, err := parseAddress(to)
+	if err != nil {
+		return err
+	}
+	e := template.NewUnlockUser(m.config.UnlockUser, toAddr, token, referrerURL)
+	return m.sendTemplate(e)
+}
+
 // SendInviteUser sends an invite mail to a new user
 func (m *Client) SendInviteUser(from, to, token, referrerURL string) error {
 	if m.IsOffline() {
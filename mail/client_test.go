@@ -277,6 +277,10 @@ func (ts *ClientTestSuite) TestSendInviteUser() {
 	ts.testSendInviteUser()
 }
 
+func (ts *ClientTestSuite) TestSendMagicLink() {
+	ts.testSendMagicLink()
+}
+
 func (ts *ClientTestSuite) TestSendResetPassword() {
 	ts.testSendResetPassword()
 }
@@ -285,6 +289,18 @@ func (ts *ClientTestSuite) TestSendSignupCode() {
 	ts.testSendSignupCode()
 }
 
+func (ts *ClientTestSuite) TestSendPasswordExpiry() {
+	ts.testSendPasswordExpiry()
+}
+
+func (ts *ClientTestSuite) TestSendTokenReused() {
+	ts.testSendTokenReused()
+}
+
+func (ts *ClientTestSuite) TestSendUnlockUser() {
+	ts.testSendUnlockUser()
+}
+
 func (ts *ClientTestSuite) TestKeepalive() {
 	if !ts.keepalive {
 		return
@@ -300,8 +316,12 @@ func (ts *ClientTestSuite) TestKeepalive() {
 		{"SendChangeEmail", ts.testSendChangeEmail},
 		{"SendConfirmUser", ts.testSendConfirmUser},
 		{"SendInviteUser", ts.testSendInviteUser},
+		{"SendMagicLink", ts.testSendMagicLink},
 		{"SendResetPassword", ts.testSendResetPassword},
 		{"SendSignupCode", ts.testSendSignupCode},
+		{"SendPasswordExpiry", ts.testSendPasswordExpiry},
+		{"SendTokenReused", ts.testSendTokenReused},
+		{"SendUnlockUser", ts.testSendUnlockUser},
 	}
 	ts.client.keepalive.Reset(100 * time.Millisecond)
 	for _, test := range tests {
@@ -518,6 +538,12 @@ func (ts *ClientTestSuite) testSendInviteUser() {
 	})
 }
 
+func (ts *ClientTestSuite) testSendMagicLink() {
+	ts.sendTest(func(tc testCase) error {
+		return ts.client.SendMagicLink(tc.to.String(), tc.tok, tc.ref)
+	})
+}
+
 func (ts *ClientTestSuite) testSendResetPassword() {
 	ts.sendTest(func(tc testCase) error {
 		return ts.client.SendResetPassword(tc.to.String(), tc.tok, tc.ref)
@@ -529,3 +555,22 @@ func (ts *ClientTestSuite) testSendSignupCode() {
 		return ts.client.SendSignupCode(tc.from.String(), tc.to.String(), tc.tok, tc.ref)
 	})
 }
+
+func (ts *ClientTestSuite) testSendPasswordExpiry() {
+	ts.sendTest(func(tc testCase) error {
+		expires := time.Now().Add(time.Hour)
+		return ts.client.SendPasswordExpiry(tc.to.String(), tc.tok, tc.ref, expires)
+	})
+}
+
+func (ts *ClientTestSuite) testSendTokenReused() {
+	ts.sendTest(func(tc testCase) error {
+		return ts.client.SendTokenReused(tc.to.String(), tc.tok, tc.ref)
+	})
+}
+
+func (ts *ClientTestSuite) testSendUnlockUser() {
+	ts.sendTest(func(tc testCase) error {
+		return ts.client.SendUnlockUser(tc.to.String(), tc.tok, tc.ref)
+	})
+}
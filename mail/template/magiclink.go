@@ -0,0 +1,88 @@
+package template
+
+import (
+	"fmt"
+	"net/mail"
+
+	"github.com/jrapoport/gothic/config"
+	"github.com/matcornic/hermes/v2"
+)
+
+// MagicLinkAction magic link action
+const MagicLinkAction = "login/magic"
+
+// MagicLink mail template
+type MagicLink struct {
+	MailTemplate
+}
+
+var _ Template = (*MagicLink)(nil)
+
+// NewMagicLink returns a new magic link email
+func NewMagicLink(c config.MailTemplate, to mail.Address, token, referralURL string) *MagicLink {
+	e := new(MagicLink)
+	e.Configure(c, to, token, referralURL)
+	return e
+}
+
+// Action returns the action for the mail template.
+func (e MagicLink) Action() string {
+	return MagicLinkAction
+}
+
+// Subject returns the subject for the mail.
+func (e MagicLink) Subject() string {
+	if e.MailTemplate.Subject() != "" {
+		return e.MailTemplate.Subject()
+	}
+	return e.subject()
+}
+
+// LoadBody loads the body for the mail.
+func (e *MagicLink) LoadBody(action string, tc config.MailTemplate) error {
+	err := e.MailTemplate.LoadBody(action, tc)
+	if err != nil {
+		return err
+	}
+	if len(e.Body.Intros) <= 0 {
+		e.Body.Intros = []string{e.intro()}
+	}
+	if len(e.Body.Actions) <= 0 {
+		e.Body.Actions = append(e.Body.Actions, hermes.Action{})
+	}
+	a := &e.Body.Actions[0]
+	if a.Instructions == "" {
+		a.Instructions = e.instructions()
+	}
+	if a.Button.Text == "" {
+		a.Button.Text = e.buttonText()
+	}
+	if a.Button.Link == "" {
+		a.Button.Link = e.Link()
+	}
+	e.Body.Outros = append([]string{e.outro()}, e.Body.Outros...)
+	return nil
+}
+
+func (e MagicLink) subject() string {
+	return "Your sign in link"
+}
+
+func (e MagicLink) intro() string {
+	const introFormat = "You received this message because there was a request " +
+		"to sign in to your %s account."
+	return fmt.Sprintf(introFormat, e.Service())
+}
+
+func (e MagicLink) instructions() string {
+	return "To sign in, please click the button below. This link can only be used once:"
+}
+
+func (e MagicLink) buttonText() string {
+	return "Sign In"
+}
+
+func (e MagicLink) outro() string {
+	return "If you did not request this link, no further action is required. " +
+		"You can safely ignore this message."
+}
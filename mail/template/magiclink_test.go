@@ -0,0 +1,21 @@
+package template
+
+import "testing"
+
+func TestMagicLink_Load(t *testing.T) {
+	t.Parallel()
+	testTemplateLoad(t, func(sub string, test testCase) Template {
+		c := test.mc.MagicLink
+		c.Subject = sub
+		c.Template = test.tmpl
+		return NewMagicLink(c, test.to, test.tok, test.ref)
+	})
+}
+
+func TestMagicLink_Content(t *testing.T) {
+	t.Parallel()
+	testTemplateContent(t, func(tc testCase) (string, Template) {
+		e := NewMagicLink(tc.mc.MagicLink, tc.to, tc.tok, tc.ref)
+		return e.Action(), e
+	})
+}
@@ -0,0 +1,89 @@
+package template
+
+import (
+	"fmt"
+	"net/mail"
+	"time"
+
+	"github.com/jrapoport/gothic/config"
+	"github.com/matcornic/hermes/v2"
+)
+
+// PasswordExpiry mail template
+type PasswordExpiry struct {
+	MailTemplate
+	expires time.Time
+}
+
+var _ Template = (*PasswordExpiry)(nil)
+
+// NewPasswordExpiry returns a new password expiry reminder email. The token
+// is a reset password token so the email links to the reset password action.
+func NewPasswordExpiry(c config.MailTemplate, to mail.Address, token, referralURL string, expires time.Time) *PasswordExpiry {
+	e := new(PasswordExpiry)
+	e.Configure(c, to, token, referralURL)
+	e.expires = expires
+	return e
+}
+
+// Action returns the action for the mail template.
+func (e PasswordExpiry) Action() string {
+	return ResetPasswordAction
+}
+
+// Subject returns the subject for the mail.
+func (e PasswordExpiry) Subject() string {
+	if e.MailTemplate.Subject() != "" {
+		return e.MailTemplate.Subject()
+	}
+	return e.subject()
+}
+
+// LoadBody loads the body for the mail.
+func (e *PasswordExpiry) LoadBody(action string, tc config.MailTemplate) error {
+	err := e.MailTemplate.LoadBody(action, tc)
+	if err != nil {
+		return err
+	}
+	if len(e.Body.Intros) <= 0 {
+		e.Body.Intros = []string{e.intro()}
+	}
+	if len(e.Body.Actions) <= 0 {
+		e.Body.Actions = append(e.Body.Actions, hermes.Action{})
+	}
+	a := &e.Body.Actions[0]
+	if a.Instructions == "" {
+		a.Instructions = e.instructions()
+	}
+	if a.Button.Text == "" {
+		a.Button.Text = e.buttonText()
+	}
+	if a.Button.Link == "" {
+		a.Button.Link = e.Link()
+	}
+	e.Body.Outros = append([]string{e.outro()}, e.Body.Outros...)
+	return nil
+}
+
+func (e PasswordExpiry) subject() string {
+	return "Your password will expire soon"
+}
+
+func (e PasswordExpiry) intro() string {
+	const introFormat = "You received this message because the password " +
+		"for your %s account will expire on %s."
+	return fmt.Sprintf(introFormat, e.Service(), e.expires.Format("January 2, 2006"))
+}
+
+func (e PasswordExpiry) instructions() string {
+	return "To change your password now, please click the button below:"
+}
+
+func (e PasswordExpiry) buttonText() string {
+	return "Change Password"
+}
+
+func (e PasswordExpiry) outro() string {
+	return "If you do not change your password before it expires, " +
+		"you will be asked to change it the next time you sign in."
+}
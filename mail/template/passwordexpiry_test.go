@@ -0,0 +1,26 @@
+package template
+
+import (
+	"testing"
+	"time"
+)
+
+var testExpires = time.Date(2026, time.March, 14, 0, 0, 0, 0, time.UTC)
+
+func TestPasswordExpiry_Load(t *testing.T) {
+	t.Parallel()
+	testTemplateLoad(t, func(sub string, test testCase) Template {
+		c := test.mc.PasswordExpiry
+		c.Subject = sub
+		c.Template = test.tmpl
+		return NewPasswordExpiry(c, test.to, test.tok, test.ref, testExpires)
+	})
+}
+
+func TestPasswordExpiry_Content(t *testing.T) {
+	t.Parallel()
+	testTemplateContent(t, func(tc testCase) (string, Template) {
+		e := NewPasswordExpiry(tc.mc.PasswordExpiry, tc.to, tc.tok, tc.ref, testExpires)
+		return "password_expiry", e
+	})
+}
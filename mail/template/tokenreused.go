@@ -0,0 +1,85 @@
+package template
+
+import (
+	"fmt"
+	"net/mail"
+
+	"github.com/jrapoport/gothic/config"
+	"github.com/matcornic/hermes/v2"
+)
+
+// TokenReused mail template
+type TokenReused struct {
+	MailTemplate
+}
+
+var _ Template = (*TokenReused)(nil)
+
+// NewTokenReused returns a new refresh token reuse alert email. The token
+// is a reset password token so the email links to the reset password action.
+func NewTokenReused(c config.MailTemplate, to mail.Address, token, referralURL string) *TokenReused {
+	e := new(TokenReused)
+	e.Configure(c, to, token, referralURL)
+	return e
+}
+
+// Action returns the action for the mail template.
+func (e TokenReused) Action() string {
+	return ResetPasswordAction
+}
+
+// Subject returns the subject for the mail.
+func (e TokenReused) Subject() string {
+	if e.MailTemplate.Subject() != "" {
+		return e.MailTemplate.Subject()
+	}
+	return e.subject()
+}
+
+// LoadBody loads the body for the mail.
+func (e *TokenReused) LoadBody(action string, tc config.MailTemplate) error {
+	err := e.MailTemplate.LoadBody(action, tc)
+	if err != nil {
+		return err
+	}
+	if len(e.Body.Intros) <= 0 {
+		e.Body.Intros = []string{e.intro()}
+	}
+	if len(e.Body.Actions) <= 0 {
+		e.Body.Actions = append(e.Body.Actions, hermes.Action{})
+	}
+	a := &e.Body.Actions[0]
+	if a.Instructions == "" {
+		a.Instructions = e.instructions()
+	}
+	if a.Button.Text == "" {
+		a.Button.Text = e.buttonText()
+	}
+	if a.Button.Link == "" {
+		a.Button.Link = e.Link()
+	}
+	e.Body.Outros = append([]string{e.outro()}, e.Body.Outros...)
+	return nil
+}
+
+func (e TokenReused) subject() string {
+	return "A device was signed out of your account"
+}
+
+func (e TokenReused) intro() string {
+	const introFormat = "You received this message because an old sign in token for your " +
+		"%s account was used again. To protect your account, the device it belonged to was signed out."
+	return fmt.Sprintf(introFormat, e.Service())
+}
+
+func (e TokenReused) instructions() string {
+	return "If you do not recognize this activity, please click the button below to reset your password:"
+}
+
+func (e TokenReused) buttonText() string {
+	return "Reset Password"
+}
+
+func (e TokenReused) outro() string {
+	return "If this was you, you may need to sign in again on your device."
+}
@@ -0,0 +1,23 @@
+package template
+
+import (
+	"testing"
+)
+
+func TestTokenReused_Load(t *testing.T) {
+	t.Parallel()
+	testTemplateLoad(t, func(sub string, test testCase) Template {
+		c := test.mc.TokenReused
+		c.Subject = sub
+		c.Template = test.tmpl
+		return NewTokenReused(c, test.to, test.tok, test.ref)
+	})
+}
+
+func TestTokenReused_Content(t *testing.T) {
+	t.Parallel()
+	testTemplateContent(t, func(tc testCase) (string, Template) {
+		e := NewTokenReused(tc.mc.TokenReused, tc.to, tc.tok, tc.ref)
+		return "token_reused", e
+	})
+}
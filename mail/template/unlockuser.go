@@ -0,0 +1,88 @@
+package template
+
+import (
+	"fmt"
+	"net/mail"
+
+	"github.com/jrapoport/gothic/config"
+	"github.com/matcornic/hermes/v2"
+)
+
+// UnlockUserAction unlock user action
+const UnlockUserAction = "unlock"
+
+// UnlockUser mail template
+type UnlockUser struct {
+	MailTemplate
+}
+
+var _ Template = (*UnlockUser)(nil)
+
+// NewUnlockUser returns a new unlock user email
+func NewUnlockUser(c config.MailTemplate, to mail.Address, token, referralURL string) *UnlockUser {
+	e := new(UnlockUser)
+	e.Configure(c, to, token, referralURL)
+	return e
+}
+
+// Action returns the action for the mail template.
+func (e UnlockUser) Action() string {
+	return UnlockUserAction
+}
+
+// Subject returns the subject for the mail.
+func (e UnlockUser) Subject() string {
+	if e.MailTemplate.Subject() != "" {
+		return e.MailTemplate.Subject()
+	}
+	return e.subject()
+}
+
+// LoadBody loads the body for the mail.
+func (e *UnlockUser) LoadBody(action string, tc config.MailTemplate) error {
+	err := e.MailTemplate.LoadBody(action, tc)
+	if err != nil {
+		return err
+	}
+	if len(e.Body.Intros) <= 0 {
+		e.Body.Intros = []string{e.intro()}
+	}
+	if len(e.Body.Actions) <= 0 {
+		e.Body.Actions = append(e.Body.Actions, hermes.Action{})
+	}
+	a := &e.Body.Actions[0]
+	if a.Instructions == "" {
+		a.Instructions = e.instructions()
+	}
+	if a.Button.Text == "" {
+		a.Button.Text = e.buttonText()
+	}
+	if a.Button.Link == "" {
+		a.Button.Link = e.Link()
+	}
+	e.Body.Outros = append([]string{e.outro()}, e.Body.Outros...)
+	return nil
+}
+
+func (e UnlockUser) subject() string {
+	return "Your account has been locked"
+}
+
+func (e UnlockUser) intro() string {
+	const introFormat = "You received this message because your %s account was " +
+		"temporarily locked after too many failed sign in attempts."
+	return fmt.Sprintf(introFormat, e.Service())
+}
+
+func (e UnlockUser) instructions() string {
+	return "If this was you, you can unlock your account now by clicking the button below:"
+}
+
+func (e UnlockUser) buttonText() string {
+	return "Unlock Account"
+}
+
+func (e UnlockUser) outro() string {
+	return "If this was not you, someone may be trying to access your account. " +
+		"We recommend you change your password once your account is unlocked."
+}
@@ -0,0 +1,21 @@
+package template
+
+import "testing"
+
+func TestUnlockUser_Load(t *testing.T) {
+	t.Parallel()
+	testTemplateLoad(t, func(sub string, test testCase) Template {
+		c := test.mc.UnlockUser
+		c.Subject = sub
+		c.Template = test.tmpl
+		return NewUnlockUser(c, test.to, test.tok, test.ref)
+	})
+}
+
+func TestUnlockUser_Content(t *testing.T) {
+	t.Parallel()
+	testTemplateContent(t, func(tc testCase) (string, Template) {
+		e := NewUnlockUser(tc.mc.UnlockUser, tc.to, tc.tok, tc.ref)
+		return e.Action(), e
+	})
+}
@@ -12,18 +12,22 @@ import (
 
 func init() {
 	var accountIndexes = []string{
-		"idx_provider_account_id",
+		"idx_tenant_provider_account_id",
 	}
 	store.AddAutoMigrationWithIndexes("4500-linked-account",
 		Account{}, accountIndexes)
+	// adds tenants, linked accounts are unique per tenant
+	store.AddAutoMigrationReplacingIndexes("4501-linked-account-tenants",
+		Account{}, accountIndexes, []string{"idx_provider_account_id"})
 }
 
-// Account holds a linked account
+// Account holds a linked account. Linked accounts are unique per tenant.
 type Account struct {
 	gorm.Model
+	TenantID  uuid.UUID     `json:"-" gorm:"<-:create;uniqueIndex:idx_tenant_provider_account_id,priority:1;type:char(36);default:'00000000-0000-0000-0000-000000000000'"`
 	Type      Type          `json:"type" gorm:"<-:create"`
-	Provider  provider.Name `json:"provider" gorm:"<-:create;uniqueIndex:idx_provider_account_id;type:varchar(255)"`
-	AccountID string        `json:"account_id" gorm:"<-:create;uniqueIndex:idx_provider_account_id;type:varchar(320)"`
+	Provider  provider.Name `json:"provider" gorm:"<-:create;uniqueIndex:idx_tenant_provider_account_id,priority:2;type:varchar(255)"`
+	AccountID string        `json:"account_id" gorm:"<-:create;uniqueIndex:idx_tenant_provider_account_id,priority:3;type:varchar(320)"`
 	Email     string        `json:"email" gorm:"type:varchar(320)"`
 	Data      types.Map     `json:"data"`
 	UserID    uuid.UUID     `json:"user_id" gorm:"<-:create;type:char(36)"`
@@ -0,0 +1,48 @@
+package attempt
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+	"gorm.io/gorm"
+)
+
+func init() {
+	store.AddAutoMigration("5600-attempts", Attempt{})
+}
+
+// Attempt is a failed login attempt. Attempts with a nil
+// user id are failed logins for users that do not exist.
+type Attempt struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uuid.UUID `json:"user_id" gorm:"<-:create;index;type:char(36)"`
+	IPAddress string    `json:"ip_address" gorm:"<-:create;index;type:varchar(45)"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+// NewAttempt returns a new failed login attempt for the user id & ip address.
+func NewAttempt(userID uuid.UUID, ip string) *Attempt {
+	return &Attempt{
+		UserID:    userID,
+		IPAddress: ip,
+	}
+}
+
+// BeforeSave runs before create or update.
+func (a *Attempt) BeforeSave(*gorm.DB) error {
+	return a.Valid()
+}
+
+// Valid returns nil if the attempt is valid.
+func (a *Attempt) Valid() error {
+	if a.UserID == user.SuperAdminID {
+		return errors.New("invalid user id")
+	}
+	if a.UserID == uuid.Nil && a.IPAddress == "" {
+		return errors.New("user id or ip address required")
+	}
+	return nil
+}
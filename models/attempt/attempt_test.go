@@ -0,0 +1,45 @@
+package attempt
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testIP = "127.0.0.1"
+
+func TestAttempt_Valid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		uid uuid.UUID
+		ip  string
+		Err assert.ErrorAssertionFunc
+	}{
+		{uuid.Nil, "", assert.Error},
+		{user.SuperAdminID, testIP, assert.Error},
+		{uuid.Nil, testIP, assert.NoError},
+		{uuid.New(), "", assert.NoError},
+		{uuid.New(), testIP, assert.NoError},
+	}
+	for _, test := range tests {
+		err := NewAttempt(test.uid, test.ip).Valid()
+		test.Err(t, err)
+	}
+}
+
+func TestAttempt_BeforeSave(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	a := NewAttempt(uuid.Nil, "")
+	err := conn.Create(a).Error
+	assert.Error(t, err)
+	a = NewAttempt(uuid.New(), testIP)
+	err = conn.Create(a).Error
+	require.NoError(t, err)
+	assert.NotZero(t, a.ID)
+	assert.False(t, a.CreatedAt.IsZero())
+}
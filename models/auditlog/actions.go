@@ -2,37 +2,89 @@ package auditlog
 
 // Account actions
 const (
-	Banned      Action = "banned"
-	CodeSent    Action = "code_sent"
-	ConfirmSent Action = "confirm_sent"
-	Confirmed   Action = "confirmed"
-	Deleted     Action = "deleted"
-	Signup      Action = "signup"
+	Banned          Action = "banned"
+	CodeSent        Action = "code_sent"
+	ConfirmSent     Action = "confirm_sent"
+	Confirmed       Action = "confirmed"
+	Deleted         Action = "deleted"
+	Imported        Action = "imported"
+	Locked          Action = "locked"
+	MagicLinkSent   Action = "magic_link_sent"
+	MFADisabled     Action = "mfa_disabled"
+	MFAEnrolled     Action = "mfa_enrolled"
+	MFAVerified     Action = "mfa_verified"
+	PasswordForced  Action = "password_forced"
+	PhoneCodeSent   Action = "phone_code_sent"
+	Signup          Action = "signup"
+	Unlocked        Action = "unlocked"
+	WebAuthnAdded   Action = "webauthn_added"
+	WebAuthnRemoved Action = "webauthn_removed"
+)
+
+// Organization actions
+const (
+	OrgCreated       Action = "org_created"
+	OrgDeleted       Action = "org_deleted"
+	OrgInviteSent    Action = "org_invite_sent"
+	OrgJoined        Action = "org_joined"
+	OrgMemberRemoved Action = "org_member_removed"
+	OrgMemberRole    Action = "org_member_role"
+	OrgSwitched      Action = "org_switched"
+	OrgUpdated       Action = "org_updated"
+)
+
+// Security actions
+const (
+	ClientCreated  Action = "client_created"
+	ClientDeleted  Action = "client_deleted"
+	Impersonated   Action = "impersonated"
+	Impersonation  Action = "impersonation"
+	RoleCreated    Action = "role_created"
+	RoleDeleted    Action = "role_deleted"
+	RoleUpdated    Action = "role_updated"
+	ServiceCreated Action = "service_created"
+	ServiceDeleted Action = "service_deleted"
+	TokenReused    Action = "token_reused"
 )
 
 // System actions
 const (
-	Startup  Action = "startup"
-	Shutdown Action = "shutdown"
+	Startup       Action = "startup"
+	Shutdown      Action = "shutdown"
+	TenantCreated Action = "tenant_created"
+	TenantDeleted Action = "tenant_deleted"
+	TenantUpdated Action = "tenant_updated"
 )
 
 // Token actions
 const (
-	Granted    Action = "granted"
-	Refreshed  Action = "refreshed"
-	Revoked    Action = "revoked"
-	RevokedAll Action = "revoked_all"
+	BearerRevoked   Action = "bearer_revoked"
+	BearersRevoked  Action = "bearers_revoked"
+	Granted         Action = "granted"
+	Refreshed       Action = "refreshed"
+	Revoked         Action = "revoked"
+	RevokedAll      Action = "revoked_all"
+	ServiceGranted  Action = "service_granted"
+	SessionRevoked  Action = "session_revoked"
+	SessionsRevoked Action = "sessions_revoked"
 )
 
 // User actions
 const (
-	ChangeRole Action = "change_role"
-	Email      Action = "email"
-	Linked     Action = "linked"
-	Login      Action = "login"
-	Logout     Action = "logout"
-	Password   Action = "password"
-	Updated    Action = "updated"
+	ChangeRole     Action = "change_role"
+	ConsentGranted Action = "consent_granted"
+	ConsentRevoked Action = "consent_revoked"
+	DeviceApproved Action = "device_approved"
+	Email          Action = "email"
+	Linked         Action = "linked"
+	Login          Action = "login"
+	LoginFailed    Action = "login_failed"
+	Logout         Action = "logout"
+	Password       Action = "password"
+	Phone          Action = "phone"
+	RoleAssigned   Action = "role_assigned"
+	RoleUnassigned Action = "role_unassigned"
+	Updated        Action = "updated"
 )
 
 // Action is action captured by the log entry.
@@ -46,6 +98,12 @@ func (a Action) Type() Type {
 		return System
 	case Shutdown:
 		return System
+	case TenantCreated:
+		return System
+	case TenantDeleted:
+		return System
+	case TenantUpdated:
+		return System
 	// Account actions
 	case Signup:
 		return Account
@@ -59,7 +117,33 @@ func (a Action) Type() Type {
 		return Account
 	case Deleted:
 		return Account
+	case Imported:
+		return Account
+	case Locked:
+		return Account
+	case Unlocked:
+		return Account
+	case MagicLinkSent:
+		return Account
+	case MFAEnrolled:
+		return Account
+	case MFAVerified:
+		return Account
+	case MFADisabled:
+		return Account
+	case PasswordForced:
+		return Account
+	case PhoneCodeSent:
+		return Account
+	case WebAuthnAdded:
+		return Account
+	case WebAuthnRemoved:
+		return Account
 	// Token actions
+	case BearerRevoked:
+		return Token
+	case BearersRevoked:
+		return Token
 	case Granted:
 		return Token
 	case Refreshed:
@@ -68,21 +152,79 @@ func (a Action) Type() Type {
 		return Token
 	case RevokedAll:
 		return Token
+	case SessionRevoked:
+		return Token
+	case SessionsRevoked:
+		return Token
+	case ServiceGranted:
+		return Token
+	// Security actions
+	case ClientCreated:
+		return Security
+	case ClientDeleted:
+		return Security
+	case Impersonated:
+		return Security
+	case Impersonation:
+		return Security
+	case RoleCreated:
+		return Security
+	case RoleDeleted:
+		return Security
+	case RoleUpdated:
+		return Security
+	case ServiceCreated:
+		return Security
+	case ServiceDeleted:
+		return Security
+	case TokenReused:
+		return Security
 	// User actions
 	case Linked:
 		return User
 	case Login:
 		return User
+	case LoginFailed:
+		return User
 	case Logout:
 		return User
 	case Password:
 		return User
 	case Email:
 		return User
+	case Phone:
+		return User
 	case Updated:
 		return User
 	case ChangeRole:
 		return User
+	case ConsentGranted:
+		return User
+	case ConsentRevoked:
+		return User
+	case DeviceApproved:
+		return User
+	case RoleAssigned:
+		return User
+	case RoleUnassigned:
+		return User
+	// Organization actions
+	case OrgCreated:
+		return User
+	case OrgDeleted:
+		return User
+	case OrgInviteSent:
+		return User
+	case OrgJoined:
+		return User
+	case OrgMemberRemoved:
+		return User
+	case OrgMemberRole:
+		return User
+	case OrgSwitched:
+		return User
+	case OrgUpdated:
+		return User
 	// Unknown action
 	default:
 		return Unknown
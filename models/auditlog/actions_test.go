@@ -17,19 +17,63 @@ func TestAction_Type(t *testing.T) {
 		{ConfirmSent, Account},
 		{Confirmed, Account},
 		{Deleted, Account},
+		{Imported, Account},
+		{Locked, Account},
+		{MagicLinkSent, Account},
+		{MFADisabled, Account},
+		{MFAEnrolled, Account},
+		{MFAVerified, Account},
+		{PasswordForced, Account},
+		{PhoneCodeSent, Account},
 		{Signup, Account},
+		{Unlocked, Account},
+		{WebAuthnAdded, Account},
+		{WebAuthnRemoved, Account},
 		{Startup, System},
 		{Shutdown, System},
+		{TenantCreated, System},
+		{TenantDeleted, System},
+		{TenantUpdated, System},
+		{ClientCreated, Security},
+		{ClientDeleted, Security},
+		{Impersonated, Security},
+		{Impersonation, Security},
+		{RoleCreated, Security},
+		{RoleDeleted, Security},
+		{RoleUpdated, Security},
+		{ServiceCreated, Security},
+		{ServiceDeleted, Security},
+		{TokenReused, Security},
+		{BearerRevoked, Token},
+		{BearersRevoked, Token},
 		{Granted, Token},
 		{Refreshed, Token},
 		{Revoked, Token},
 		{RevokedAll, Token},
+		{SessionRevoked, Token},
+		{SessionsRevoked, Token},
+		{ServiceGranted, Token},
 		{ChangeRole, User},
+		{OrgCreated, User},
+		{OrgDeleted, User},
+		{OrgInviteSent, User},
+		{OrgJoined, User},
+		{OrgMemberRemoved, User},
+		{OrgMemberRole, User},
+		{OrgSwitched, User},
+		{OrgUpdated, User},
+		{ConsentGranted, User},
+		{ConsentRevoked, User},
+		{DeviceApproved, User},
 		{Email, User},
 		{Linked, User},
 		{Login, User},
+		{LoginFailed, User},
 		{Logout, User},
 		{Password, User},
+		{Phone, User},
+		{RoleAssigned, User},
+		{RoleUnassigned, User},
 		{Updated, User},
 		{"", Unknown},
 	}
@@ -9,15 +9,18 @@ import (
 
 func init() {
 	store.AddAutoMigration("1000-audit_logs", AuditLog{})
+	// adds tenants
+	store.AddAutoMigration("1001-audit_logs-tenants", AuditLog{})
 }
 
 // AuditLog is the database model for audit log entries.
 type AuditLog struct {
 	gorm.Model
-	Type   Type      `json:"type"`
-	Action Action    `json:"action"`
-	UserID uuid.UUID `json:"user_id" gorm:"index;type:char(36)"`
-	Fields types.Map `json:"fields"`
+	TenantID uuid.UUID `json:"-" gorm:"type:char(36);default:'00000000-0000-0000-0000-000000000000'"`
+	Type     Type      `json:"type"`
+	Action   Action    `json:"action"`
+	UserID   uuid.UUID `json:"user_id" gorm:"index;type:char(36)"`
+	Fields   types.Map `json:"fields"`
 }
 
 // NewAuditLog returns a new log entry
@@ -10,6 +10,7 @@ const (
 	Account
 	Token
 	User
+	Security
 )
 
 // TypeFromString returns the log type for a string.
@@ -23,6 +24,8 @@ func TypeFromString(s string) Type {
 		return Token
 	case "user":
 		return User
+	case "security":
+		return Security
 	default:
 		return Unknown
 	}
@@ -38,6 +41,8 @@ func (t Type) String() string {
 		return "token"
 	case User:
 		return "user"
+	case Security:
+		return "security"
 	default:
 		return ""
 	}
@@ -16,6 +16,7 @@ func TestTypeFromString(t *testing.T) {
 		{"account", Account},
 		{"token", Token},
 		{"user", User},
+		{"security", Security},
 		{"", Unknown},
 	}
 	for _, test := range tests {
@@ -0,0 +1,154 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/hasher"
+	"github.com/jrapoport/gothic/store"
+	"gorm.io/gorm"
+)
+
+func init() {
+	var clientIndexes = []string{
+		"idx_tenant_client_id",
+	}
+	store.AddAutoMigrationWithIndexes("4700-oauth_clients",
+		Client{}, clientIndexes)
+	// adds tenants, clients are unique per tenant
+	store.AddAutoMigrationReplacingIndexes("4703-oauth_clients-tenants",
+		Client{}, clientIndexes, []string{"idx_client_id"})
+}
+
+// Client holds an OAuth 2.0 client registered with the OpenID Connect
+// provider. Clients are unique per tenant.
+type Client struct {
+	gorm.Model
+	TenantID     uuid.UUID `json:"-" gorm:"<-:create;uniqueIndex:idx_tenant_client_id,priority:1;type:char(36);default:'00000000-0000-0000-0000-000000000000'"`
+	ClientID     string    `json:"client_id" gorm:"<-:create;uniqueIndex:idx_tenant_client_id,priority:2;type:varchar(255)"`
+	Name         string    `json:"name" gorm:"type:varchar(255)"`
+	Secret       []byte    `json:"-" gorm:"type:varchar(255)"`
+	RedirectURIs string    `json:"redirect_uris"`
+	Scopes       string    `json:"scopes" gorm:"type:varchar(255)"`
+}
+
+// NewClient returns a new client. If the secret hash is nil the client is
+// a public client, which must use PKCE to exchange authorization codes.
+func NewClient(name string, redirectURIs, scopes []string, secret []byte) *Client {
+	return &Client{
+		ClientID:     uuid.New().String(),
+		Name:         name,
+		Secret:       secret,
+		RedirectURIs: JoinList(redirectURIs),
+		Scopes:       JoinList(scopes),
+	}
+}
+
+// BeforeSave runs before create or update.
+func (c *Client) BeforeSave(*gorm.DB) error {
+	return c.Valid()
+}
+
+// Valid returns nil if the client is valid.
+func (c *Client) Valid() error {
+	if c.ClientID == "" {
+		return errors.New("invalid client id")
+	}
+	if c.Name == "" {
+		return errors.New("invalid client name")
+	}
+	uris := c.RedirectURIList()
+	if len(uris) == 0 {
+		return errors.New("redirect uri required")
+	}
+	for _, uri := range uris {
+		u, err := url.Parse(uri)
+		if err != nil {
+			return err
+		}
+		if !u.IsAbs() || u.Fragment != "" {
+			return fmt.Errorf("invalid redirect uri: %s", uri)
+		}
+	}
+	return nil
+}
+
+// Confidential returns true if the client has a secret.
+func (c Client) Confidential() bool {
+	return len(c.Secret) > 0
+}
+
+// Authenticate returns nil if the secret matches. A public
+// client can only authenticate without a secret.
+func (c Client) Authenticate(secret string) error {
+	if !c.Confidential() {
+		if secret != "" {
+			return errors.New("invalid client secret")
+		}
+		return nil
+	}
+	return hasher.Verify(c.Secret, secret)
+}
+
+// RedirectURIList returns the redirect uris registered for the client.
+func (c Client) RedirectURIList() []string {
+	return SplitList(c.RedirectURIs)
+}
+
+// HasRedirectURI returns true if the redirect uri is registered for the client.
+// Redirect uris must match exactly.
+func (c Client) HasRedirectURI(uri string) bool {
+	for _, u := range c.RedirectURIList() {
+		if u == uri {
+			return true
+		}
+	}
+	return false
+}
+
+// ScopeList returns the scopes the client is allowed to request.
+func (c Client) ScopeList() []string {
+	return SplitList(c.Scopes)
+}
+
+// AllowsScopes returns true if the client is allowed to request the scopes.
+func (c Client) AllowsScopes(scopes []string) bool {
+	return HasAll(c.ScopeList(), scopes)
+}
+
+// SplitList splits a space separated list.
+func SplitList(s string) []string {
+	return strings.Fields(s)
+}
+
+// JoinList joins a list with spaces, dropping any empty or duplicate entries.
+func JoinList(list []string) string {
+	var out []string
+	seen := map[string]bool{}
+	for _, s := range list {
+		s = strings.TrimSpace(s)
+		if s == "" || seen[s] {
+			continue
+		}
+		seen[s] = true
+		out = append(out, s)
+	}
+	return strings.Join(out, " ")
+}
+
+// HasAll returns true if the list contains all the values.
+func HasAll(list, values []string) bool {
+	has := map[string]bool{}
+	for _, s := range list {
+		has[s] = true
+	}
+	for _, v := range values {
+		if !has[v] {
+			return false
+		}
+	}
+	return true
+}
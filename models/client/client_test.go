@@ -0,0 +1,93 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/jrapoport/gothic/hasher"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testName     = "client"
+	testRedirect = "https://client.example.com/callback"
+	testSecret   = "i-am-a-secret"
+)
+
+func TestNewClient(t *testing.T) {
+	t.Parallel()
+	uris := []string{testRedirect, " ", testRedirect, "https://example.com/cb"}
+	c := NewClient(testName, uris, []string{"openid", "email"}, nil)
+	assert.NotEmpty(t, c.ClientID)
+	assert.Equal(t, testName, c.Name)
+	assert.Equal(t, []string{testRedirect, "https://example.com/cb"}, c.RedirectURIList())
+	assert.Equal(t, []string{"openid", "email"}, c.ScopeList())
+	assert.False(t, c.Confidential())
+	c2 := NewClient(testName, uris, nil, nil)
+	assert.NotEqual(t, c.ClientID, c2.ClientID)
+	assert.Empty(t, c2.ScopeList())
+}
+
+func TestClient_Valid(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	tests := []struct {
+		c   *Client
+		Err assert.ErrorAssertionFunc
+	}{
+		{&Client{}, assert.Error},
+		{&Client{ClientID: "id"}, assert.Error},
+		{&Client{ClientID: "id", Name: testName}, assert.Error},
+		{NewClient(testName, []string{"/callback"}, nil, nil), assert.Error},
+		{NewClient(testName, []string{":bad"}, nil, nil), assert.Error},
+		{NewClient(testName, []string{testRedirect + "#frag"}, nil, nil), assert.Error},
+		{NewClient(testName, []string{testRedirect}, nil, nil), assert.NoError},
+	}
+	for _, test := range tests {
+		err := test.c.Valid()
+		test.Err(t, err)
+		err = conn.Create(test.c).Error
+		test.Err(t, err)
+	}
+}
+
+func TestClient_Authenticate(t *testing.T) {
+	t.Parallel()
+	c := NewClient(testName, []string{testRedirect}, nil, nil)
+	assert.NoError(t, c.Authenticate(""))
+	assert.Error(t, c.Authenticate(testSecret))
+	hash, err := hasher.Hash(testSecret)
+	require.NoError(t, err)
+	c.Secret = hash
+	assert.True(t, c.Confidential())
+	assert.NoError(t, c.Authenticate(testSecret))
+	assert.Error(t, c.Authenticate(""))
+	assert.Error(t, c.Authenticate("bad"))
+}
+
+func TestClient_HasRedirectURI(t *testing.T) {
+	t.Parallel()
+	c := NewClient(testName, []string{testRedirect}, nil, nil)
+	assert.True(t, c.HasRedirectURI(testRedirect))
+	assert.False(t, c.HasRedirectURI(testRedirect+"/"))
+	assert.False(t, c.HasRedirectURI(""))
+}
+
+func TestClient_AllowsScopes(t *testing.T) {
+	t.Parallel()
+	c := NewClient(testName, []string{testRedirect}, []string{"openid", "email"}, nil)
+	assert.True(t, c.AllowsScopes(nil))
+	assert.True(t, c.AllowsScopes([]string{"openid"}))
+	assert.True(t, c.AllowsScopes([]string{"email", "openid"}))
+	assert.False(t, c.AllowsScopes([]string{"openid", "phone"}))
+}
+
+func TestJoinList(t *testing.T) {
+	t.Parallel()
+	assert.Empty(t, JoinList(nil))
+	assert.Equal(t, "a b", JoinList([]string{"a", "", " b ", "a"}))
+	assert.Equal(t, []string{"a", "b"}, SplitList(" a  b "))
+	assert.True(t, HasAll([]string{"a", "b"}, []string{"b"}))
+	assert.False(t, HasAll([]string{"a"}, []string{"b"}))
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+	"gorm.io/gorm"
+)
+
+func init() {
+	var consentIndexes = []string{
+		"idx_consent_user_id",
+		"idx_tenant_consent_user_client",
+	}
+	store.AddAutoMigrationWithIndexes("4701-oauth_consents",
+		Consent{}, consentIndexes)
+	// adds tenants, consents are unique per tenant
+	store.AddAutoMigrationReplacingIndexes("4704-oauth_consents-tenants",
+		Consent{}, consentIndexes, []string{"idx_consent_user_client"})
+}
+
+// Consent holds the scopes a user has allowed a client to access.
+// A consent is deleted when it is revoked. Consents are unique per tenant.
+type Consent struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	TenantID  uuid.UUID `json:"-" gorm:"<-:create;uniqueIndex:idx_tenant_consent_user_client,priority:1;type:char(36);default:'00000000-0000-0000-0000-000000000000'"`
+	UserID    uuid.UUID `json:"user_id" gorm:"<-:create;index:idx_consent_user_id;uniqueIndex:idx_tenant_consent_user_client,priority:2;type:char(36)"`
+	ClientID  string    `json:"client_id" gorm:"<-:create;uniqueIndex:idx_tenant_consent_user_client,priority:3;type:varchar(255)"`
+	Scopes    string    `json:"scopes" gorm:"type:varchar(255)"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewConsent returns a new consent for the user and client.
+func NewConsent(userID uuid.UUID, clientID string, scopes []string) *Consent {
+	return &Consent{
+		UserID:   userID,
+		ClientID: clientID,
+		Scopes:   JoinList(scopes),
+	}
+}
+
+// BeforeSave runs before create or update.
+func (c *Consent) BeforeSave(*gorm.DB) error {
+	return c.Valid()
+}
+
+// Valid returns nil if the consent is valid.
+func (c *Consent) Valid() error {
+	if c.UserID == user.SystemID || c.UserID == user.SuperAdminID {
+		return errors.New("invalid user id")
+	}
+	if c.ClientID == "" {
+		return errors.New("invalid client id")
+	}
+	return nil
+}
+
+// ScopeList returns the scopes the user has consented to.
+func (c Consent) ScopeList() []string {
+	return SplitList(c.Scopes)
+}
+
+// Covers returns true if the user has consented to all the scopes.
+func (c Consent) Covers(scopes []string) bool {
+	return HasAll(c.ScopeList(), scopes)
+}
+
+// Grant adds the scopes to the consent.
+func (c *Consent) Grant(scopes []string) {
+	c.Scopes = JoinList(append(c.ScopeList(), scopes...))
+}
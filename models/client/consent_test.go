@@ -0,0 +1,49 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsent_Valid(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	cid := uuid.New().String()
+	tests := []struct {
+		c   *Consent
+		Err assert.ErrorAssertionFunc
+	}{
+		{&Consent{}, assert.Error},
+		{NewConsent(user.SystemID, cid, nil), assert.Error},
+		{NewConsent(user.SuperAdminID, cid, nil), assert.Error},
+		{NewConsent(uuid.New(), "", nil), assert.Error},
+		{NewConsent(uuid.New(), cid, []string{"openid"}), assert.NoError},
+	}
+	for _, test := range tests {
+		err := test.c.Valid()
+		test.Err(t, err)
+		err = conn.Create(test.c).Error
+		test.Err(t, err)
+	}
+	// one consent per user and client
+	uid := uuid.New()
+	err := conn.Create(NewConsent(uid, cid, nil)).Error
+	require.NoError(t, err)
+	err = conn.Create(NewConsent(uid, cid, nil)).Error
+	assert.Error(t, err)
+}
+
+func TestConsent_Grant(t *testing.T) {
+	t.Parallel()
+	c := NewConsent(uuid.New(), uuid.New().String(), []string{"openid"})
+	assert.True(t, c.Covers([]string{"openid"}))
+	assert.False(t, c.Covers([]string{"openid", "email"}))
+	c.Grant([]string{"email", "openid"})
+	assert.Equal(t, []string{"openid", "email"}, c.ScopeList())
+	assert.True(t, c.Covers([]string{"openid", "email"}))
+}
@@ -0,0 +1,110 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/hasher"
+	"github.com/jrapoport/gothic/store"
+	"gorm.io/gorm"
+)
+
+func init() {
+	var serviceIndexes = []string{
+		"idx_tenant_service_client_id",
+		"idx_service_owner_id",
+	}
+	store.AddAutoMigrationWithIndexes("4702-service_clients",
+		Service{}, serviceIndexes)
+	// adds tenants, service clients are unique per tenant
+	store.AddAutoMigrationReplacingIndexes("4705-service_clients-tenants",
+		Service{}, serviceIndexes, []string{"idx_service_client_id"})
+}
+
+// Service holds a service client that uses the client credentials grant
+// to get tokens for machine to machine calls. Service clients are unique
+// per tenant.
+type Service struct {
+	gorm.Model
+	TenantID uuid.UUID `json:"-" gorm:"<-:create;uniqueIndex:idx_tenant_service_client_id,priority:1;type:char(36);default:'00000000-0000-0000-0000-000000000000'"`
+	ClientID string    `json:"client_id" gorm:"<-:create;uniqueIndex:idx_tenant_service_client_id,priority:2;type:varchar(255)"`
+	Name     string    `json:"name" gorm:"type:varchar(255)"`
+	Secret   []byte    `json:"-" gorm:"type:varchar(255)"`
+	Scopes   string    `json:"scopes"`
+	OwnerID  uuid.UUID `json:"owner_id" gorm:"index:idx_service_owner_id;type:char(36)"`
+}
+
+// TableName returns the table name for service clients.
+func (Service) TableName() string {
+	return "service_clients"
+}
+
+// NewService returns a new service client owned by the user with the secret hash.
+func NewService(name string, scopes []string, ownerID uuid.UUID, secret []byte) *Service {
+	return &Service{
+		ClientID: uuid.New().String(),
+		Name:     name,
+		Secret:   secret,
+		Scopes:   JoinList(scopes),
+		OwnerID:  ownerID,
+	}
+}
+
+// BeforeSave runs before create or update.
+func (s *Service) BeforeSave(*gorm.DB) error {
+	return s.Valid()
+}
+
+// Valid returns nil if the service client is valid.
+func (s *Service) Valid() error {
+	if s.ClientID == "" {
+		return errors.New("invalid client id")
+	}
+	if s.Name == "" {
+		return errors.New("invalid client name")
+	}
+	if len(s.Secret) == 0 {
+		return errors.New("client secret required")
+	}
+	if s.OwnerID == uuid.Nil {
+		return errors.New("invalid owner id")
+	}
+	for _, scope := range s.ScopeList() {
+		if !ValidScope(scope) {
+			return fmt.Errorf("invalid scope: %s", scope)
+		}
+	}
+	return nil
+}
+
+// Authenticate returns nil if the secret matches.
+func (s Service) Authenticate(secret string) error {
+	if secret == "" {
+		return errors.New("invalid client secret")
+	}
+	return hasher.Verify(s.Secret, secret)
+}
+
+// ScopeList returns the scopes the service client is allowed to request.
+func (s Service) ScopeList() []string {
+	return SplitList(s.Scopes)
+}
+
+// AllowsScopes returns true if the service client is allowed to request the scopes.
+func (s Service) AllowsScopes(scopes []string) bool {
+	return HasAll(s.ScopeList(), scopes)
+}
+
+// ValidScope returns true if the scope is a valid scope token (RFC 6749 3.3).
+func ValidScope(scope string) bool {
+	if scope == "" {
+		return false
+	}
+	for _, r := range scope {
+		if r < 0x21 || r > 0x7e || r == '"' || r == '\\' {
+			return false
+		}
+	}
+	return true
+}
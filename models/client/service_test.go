@@ -0,0 +1,81 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/hasher"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewService(t *testing.T) {
+	t.Parallel()
+	oid := uuid.New()
+	s := NewService(testName, []string{"read", " ", "write", "read"}, oid, nil)
+	assert.NotEmpty(t, s.ClientID)
+	assert.Equal(t, testName, s.Name)
+	assert.Equal(t, []string{"read", "write"}, s.ScopeList())
+	assert.Equal(t, oid, s.OwnerID)
+	s2 := NewService(testName, nil, oid, nil)
+	assert.NotEqual(t, s.ClientID, s2.ClientID)
+	assert.Empty(t, s2.ScopeList())
+}
+
+func TestService_Valid(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	oid := uuid.New()
+	hash, err := hasher.Hash(testSecret)
+	require.NoError(t, err)
+	tests := []struct {
+		s   *Service
+		Err assert.ErrorAssertionFunc
+	}{
+		{&Service{}, assert.Error},
+		{&Service{ClientID: "id"}, assert.Error},
+		{&Service{ClientID: "id", Name: testName}, assert.Error},
+		{NewService(testName, nil, uuid.Nil, hash), assert.Error},
+		{NewService(testName, nil, oid, nil), assert.Error},
+		{NewService(testName, []string{`bad"scope`}, oid, hash), assert.Error},
+		{NewService(testName, nil, oid, hash), assert.NoError},
+		{NewService(testName, []string{"read:users", "write"}, oid, hash), assert.NoError},
+	}
+	for _, test := range tests {
+		err = test.s.Valid()
+		test.Err(t, err)
+		err = conn.Create(test.s).Error
+		test.Err(t, err)
+	}
+}
+
+func TestService_Authenticate(t *testing.T) {
+	t.Parallel()
+	hash, err := hasher.Hash(testSecret)
+	require.NoError(t, err)
+	s := NewService(testName, nil, uuid.New(), hash)
+	assert.NoError(t, s.Authenticate(testSecret))
+	assert.Error(t, s.Authenticate(""))
+	assert.Error(t, s.Authenticate("bad"))
+}
+
+func TestService_AllowsScopes(t *testing.T) {
+	t.Parallel()
+	s := NewService(testName, []string{"read", "write"}, uuid.New(), nil)
+	assert.True(t, s.AllowsScopes(nil))
+	assert.True(t, s.AllowsScopes([]string{"read"}))
+	assert.True(t, s.AllowsScopes([]string{"write", "read"}))
+	assert.False(t, s.AllowsScopes([]string{"read", "admin"}))
+}
+
+func TestValidScope(t *testing.T) {
+	t.Parallel()
+	assert.True(t, ValidScope("read"))
+	assert.True(t, ValidScope("https://api.example.com/read"))
+	assert.False(t, ValidScope(""))
+	assert.False(t, ValidScope("a b"))
+	assert.False(t, ValidScope(`a"b`))
+	assert.False(t, ValidScope(`a\b`))
+	assert.False(t, ValidScope("é"))
+}
@@ -16,6 +16,7 @@ type Format uint8
 const (
 	Invite Format = iota
 	PIN
+	UserCode
 )
 
 // NoExpiration indicates the code will not expire.
@@ -44,6 +45,8 @@ func NewAccessCode(f Format, uses int, exp time.Duration) *AccessCode {
 	switch f {
 	case PIN:
 		c = utils.PINCode()
+	case UserCode:
+		c = utils.UserCode()
 	case Invite:
 		fallthrough
 	default:
@@ -14,7 +14,7 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-var testFormats = []Format{Invite, PIN}
+var testFormats = []Format{Invite, PIN, UserCode}
 
 func testName(f Format) string {
 	switch f {
@@ -22,6 +22,8 @@ func testName(f Format) string {
 		return "Token"
 	case PIN:
 		return "PIN"
+	case UserCode:
+		return "UserCode"
 	default:
 		return ""
 	}
@@ -0,0 +1,85 @@
+package code
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/store"
+	"github.com/jrapoport/gothic/utils"
+)
+
+func init() {
+	indexes := append(token.AccessTokenIndexes, "idx_device", "idx_client_id")
+	store.AddAutoMigrationWithIndexes("2100-device_codes",
+		DeviceCode{}, indexes)
+}
+
+// Device class
+const Device token.Class = "device"
+
+// DeviceCode holds an OAuth 2.0 device authorization request. The code
+// is the user code a user enters to approve the request. The device code
+// is the secret the device polls the token endpoint with. Until the request
+// is approved, the device code is issued to the system user.
+type DeviceCode struct {
+	AccessCode
+	Device     string        `json:"-" gorm:"uniqueIndex:idx_device;type:varchar(255)"`
+	ClientID   string        `json:"client_id" gorm:"index:idx_client_id;type:varchar(255)"`
+	Scope      string        `json:"scope" gorm:"type:varchar(255)"`
+	Interval   time.Duration `json:"interval"`
+	PolledAt   *time.Time    `json:"polled_at,omitempty"`
+	ApprovedAt *time.Time    `json:"approved_at,omitempty"`
+	DeniedAt   *time.Time    `json:"denied_at,omitempty"`
+}
+
+var _ token.Token = (*DeviceCode)(nil)
+
+// NewDeviceCode generates a new single use device code for the client.
+// The device must wait for the interval between polls.
+func NewDeviceCode(clientID, scope string, exp, interval time.Duration) *DeviceCode {
+	ac := NewAccessCode(UserCode, SingleUse, exp)
+	return &DeviceCode{
+		AccessCode: *ac,
+		Device:     utils.SecureToken(),
+		ClientID:   clientID,
+		Scope:      scope,
+		Interval:   interval,
+	}
+}
+
+// Class returns the class of the device code.
+func (dc DeviceCode) Class() token.Class {
+	return Device
+}
+
+// HasCode returns true if the user code was found.
+func (dc DeviceCode) HasCode(tx *store.Connection) (bool, error) {
+	if dc.Token == "" {
+		return false, errors.New("invalid code")
+	}
+	return tx.Has(&dc, "token = ?", dc.Token)
+}
+
+// Approved returns true if a user approved the device.
+func (dc DeviceCode) Approved() bool {
+	return dc.ApprovedAt != nil
+}
+
+// Denied returns true if a user denied the device.
+func (dc DeviceCode) Denied() bool {
+	return dc.DeniedAt != nil
+}
+
+// Pending returns true if the device has not been approved or denied.
+func (dc DeviceCode) Pending() bool {
+	return !dc.Approved() && !dc.Denied()
+}
+
+// PolledTooSoon returns true if the device polled before the interval passed.
+func (dc DeviceCode) PolledTooSoon() bool {
+	if dc.PolledAt == nil {
+		return false
+	}
+	return time.Since(*dc.PolledAt) < dc.Interval
+}
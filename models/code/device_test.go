@@ -0,0 +1,77 @@
+package code
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/jrapoport/gothic/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDeviceCode(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	dc := NewDeviceCode("client", "openid", time.Minute, 5*time.Second)
+	require.NotNil(t, dc)
+	assert.Equal(t, Device, dc.Class())
+	assert.Equal(t, UserCode, dc.Format)
+	assert.Equal(t, utils.FormatUserCode(dc.Code()), dc.Code())
+	assert.NotEmpty(t, dc.Device)
+	assert.NotEqual(t, dc.Code(), dc.Device)
+	assert.Equal(t, user.SystemID, dc.UserID)
+	assert.Equal(t, "client", dc.ClientID)
+	assert.Equal(t, "openid", dc.Scope)
+	assert.Equal(t, 5*time.Second, dc.Interval)
+	assert.Equal(t, SingleUse, dc.MaxUses)
+	assert.False(t, dc.Usable())
+	err := conn.Create(dc).Error
+	require.NoError(t, err)
+	assert.True(t, dc.Usable())
+	assert.NotNil(t, dc.ExpiredAt)
+}
+
+func TestDeviceCode_HasCode(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	dc := NewDeviceCode("client", "", time.Minute, time.Second)
+	has, err := dc.HasCode(conn)
+	assert.NoError(t, err)
+	assert.False(t, has)
+	err = conn.Create(dc).Error
+	require.NoError(t, err)
+	has, err = dc.HasCode(conn)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	_, err = DeviceCode{}.HasCode(conn)
+	assert.Error(t, err)
+}
+
+func TestDeviceCode_Pending(t *testing.T) {
+	t.Parallel()
+	dc := NewDeviceCode("client", "", time.Minute, time.Second)
+	assert.True(t, dc.Pending())
+	assert.False(t, dc.Approved())
+	assert.False(t, dc.Denied())
+	now := time.Now().UTC()
+	dc.ApprovedAt = &now
+	assert.False(t, dc.Pending())
+	assert.True(t, dc.Approved())
+	dc.ApprovedAt = nil
+	dc.DeniedAt = &now
+	assert.False(t, dc.Pending())
+	assert.True(t, dc.Denied())
+}
+
+func TestDeviceCode_PolledTooSoon(t *testing.T) {
+	t.Parallel()
+	dc := NewDeviceCode("client", "", time.Minute, time.Hour)
+	assert.False(t, dc.PolledTooSoon())
+	now := time.Now().UTC()
+	dc.PolledAt = &now
+	assert.True(t, dc.PolledTooSoon())
+	dc.Interval = 0
+	assert.False(t, dc.PolledTooSoon())
+}
@@ -15,6 +15,9 @@ import (
 func init() {
 	store.AddAutoMigrationWithIndexes("2000-signup_codes",
 		SignupCode{}, token.AccessTokenIndexes)
+	// adds tenants
+	store.AddAutoMigrationWithIndexes("2001-signup_codes-tenants",
+		SignupCode{}, token.AccessTokenIndexes)
 }
 
 // Signup class
@@ -0,0 +1,140 @@
+package credential
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+	"gorm.io/gorm"
+)
+
+func init() {
+	var credentialIndexes = []string{
+		"idx_credential_id",
+		"idx_credential_user_id",
+	}
+	store.AddAutoMigrationWithIndexes("4600-webauthn-credentials",
+		Credential{}, credentialIndexes)
+}
+
+// Credential holds a webauthn (passkey) credential.
+type Credential struct {
+	gorm.Model
+	UserID          uuid.UUID  `json:"user_id" gorm:"<-:create;index:idx_credential_user_id;type:char(36)"`
+	Name            string     `json:"name" gorm:"type:varchar(255)"`
+	CredentialID    string     `json:"credential_id" gorm:"<-:create;uniqueIndex:idx_credential_id;type:varchar(255)"`
+	PublicKey       []byte     `json:"-" gorm:"<-:create"`
+	AttestationType string     `json:"attestation_type" gorm:"<-:create;type:varchar(255)"`
+	AAGUID          []byte     `json:"aaguid" gorm:"<-:create"`
+	Transports      string     `json:"transports" gorm:"type:varchar(255)"`
+	SignCount       uint32     `json:"sign_count"`
+	CloneWarning    bool       `json:"clone_warning"`
+	UserPresent     bool       `json:"-"`
+	UserVerified    bool       `json:"-"`
+	BackupEligible  bool       `json:"backup_eligible"`
+	BackupState     bool       `json:"backup_state"`
+	LastUsedAt      *time.Time `json:"last_used_at,omitempty"`
+}
+
+// NewCredential returns a new credential for the user.
+func NewCredential(userID uuid.UUID, name string, c *webauthn.Credential) *Credential {
+	cred := &Credential{
+		UserID: userID,
+		Name:   name,
+	}
+	if c == nil {
+		return cred
+	}
+	transports := make([]string, len(c.Transport))
+	for i, t := range c.Transport {
+		transports[i] = string(t)
+	}
+	cred.CredentialID = EncodeID(c.ID)
+	cred.PublicKey = c.PublicKey
+	cred.AttestationType = c.AttestationType
+	cred.AAGUID = c.Authenticator.AAGUID
+	cred.Transports = strings.Join(transports, ",")
+	cred.Update(c)
+	return cred
+}
+
+// BeforeSave runs before create or update.
+func (c *Credential) BeforeSave(*gorm.DB) error {
+	return c.Valid()
+}
+
+// Valid returns nil if the credential is valid.
+func (c *Credential) Valid() error {
+	if c.UserID == uuid.Nil || c.UserID == user.SystemID {
+		return errors.New("invalid user id")
+	}
+	if c.CredentialID == "" {
+		return errors.New("invalid credential id")
+	}
+	if len(c.PublicKey) == 0 {
+		return errors.New("invalid public key")
+	}
+	return nil
+}
+
+// Update updates the sign count and flags from a credential
+// returned by a successful webauthn login.
+func (c *Credential) Update(wc *webauthn.Credential) {
+	c.SignCount = wc.Authenticator.SignCount
+	c.CloneWarning = c.CloneWarning || wc.Authenticator.CloneWarning
+	c.UserPresent = wc.Flags.UserPresent
+	c.UserVerified = wc.Flags.UserVerified
+	c.BackupEligible = wc.Flags.BackupEligible
+	c.BackupState = wc.Flags.BackupState
+}
+
+// TransportList returns the transports supported by the credential.
+func (c Credential) TransportList() []string {
+	if c.Transports == "" {
+		return []string{}
+	}
+	return strings.Split(c.Transports, ",")
+}
+
+// WebAuthn returns the credential as a webauthn credential.
+func (c Credential) WebAuthn() webauthn.Credential {
+	id, _ := DecodeID(c.CredentialID)
+	transports := c.TransportList()
+	wt := make([]protocol.AuthenticatorTransport, len(transports))
+	for i, t := range transports {
+		wt[i] = protocol.AuthenticatorTransport(t)
+	}
+	return webauthn.Credential{
+		ID:              id,
+		PublicKey:       c.PublicKey,
+		AttestationType: c.AttestationType,
+		Transport:       wt,
+		Flags: webauthn.CredentialFlags{
+			UserPresent:    c.UserPresent,
+			UserVerified:   c.UserVerified,
+			BackupEligible: c.BackupEligible,
+			BackupState:    c.BackupState,
+		},
+		Authenticator: webauthn.Authenticator{
+			AAGUID:       c.AAGUID,
+			SignCount:    c.SignCount,
+			CloneWarning: c.CloneWarning,
+		},
+	}
+}
+
+// EncodeID returns the url safe encoding of a raw credential id.
+func EncodeID(id []byte) string {
+	return base64.RawURLEncoding.EncodeToString(id)
+}
+
+// DecodeID returns the raw credential id for an encoded credential id.
+func DecodeID(id string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(id)
+}
@@ -0,0 +1,122 @@
+package credential
+
+import (
+	"testing"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCredential() *webauthn.Credential {
+	return &webauthn.Credential{
+		ID:              []byte("credential-id"),
+		PublicKey:       []byte("public-key"),
+		AttestationType: "none",
+		Transport: []protocol.AuthenticatorTransport{
+			protocol.USB,
+			protocol.Internal,
+		},
+		Flags: webauthn.CredentialFlags{
+			UserPresent:    true,
+			BackupEligible: true,
+		},
+		Authenticator: webauthn.Authenticator{
+			AAGUID:    []byte("aaguid"),
+			SignCount: 1,
+		},
+	}
+}
+
+func TestNewCredential(t *testing.T) {
+	t.Parallel()
+	uid := uuid.New()
+	wc := testCredential()
+	c := NewCredential(uid, "name", wc)
+	assert.Equal(t, uid, c.UserID)
+	assert.Equal(t, "name", c.Name)
+	assert.Equal(t, EncodeID(wc.ID), c.CredentialID)
+	assert.Equal(t, wc.PublicKey, c.PublicKey)
+	assert.Equal(t, "usb,internal", c.Transports)
+	assert.Equal(t, []string{"usb", "internal"}, c.TransportList())
+	assert.EqualValues(t, 1, c.SignCount)
+	assert.True(t, c.BackupEligible)
+	assert.Equal(t, *wc, c.WebAuthn())
+	c = NewCredential(uid, "name", nil)
+	assert.Empty(t, c.CredentialID)
+	assert.Empty(t, c.TransportList())
+}
+
+func TestCredential_Valid(t *testing.T) {
+	t.Parallel()
+	wc := testCredential()
+	tests := []struct {
+		c   *Credential
+		Err assert.ErrorAssertionFunc
+	}{
+		{&Credential{}, assert.Error},
+		{NewCredential(uuid.Nil, "", wc), assert.Error},
+		{NewCredential(user.SystemID, "", wc), assert.Error},
+		{NewCredential(uuid.New(), "", nil), assert.Error},
+		{&Credential{UserID: uuid.New(), CredentialID: "id"}, assert.Error},
+		{NewCredential(uuid.New(), "", wc), assert.NoError},
+	}
+	for _, test := range tests {
+		err := test.c.Valid()
+		test.Err(t, err)
+	}
+}
+
+func TestCredential_Update(t *testing.T) {
+	t.Parallel()
+	wc := testCredential()
+	c := NewCredential(uuid.New(), "", wc)
+	wc.Authenticator.SignCount = 10
+	wc.Flags.BackupState = true
+	c.Update(wc)
+	assert.EqualValues(t, 10, c.SignCount)
+	assert.True(t, c.BackupState)
+	assert.False(t, c.CloneWarning)
+	wc.Authenticator.CloneWarning = true
+	c.Update(wc)
+	assert.True(t, c.CloneWarning)
+	wc.Authenticator.CloneWarning = false
+	c.Update(wc)
+	assert.True(t, c.CloneWarning)
+}
+
+func TestCredential_Save(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	c := NewCredential(uuid.New(), "name", testCredential())
+	err := conn.Create(c).Error
+	require.NoError(t, err)
+	var test Credential
+	err = conn.First(&test, "credential_id = ?", c.CredentialID).Error
+	require.NoError(t, err)
+	assert.Equal(t, c.UserID, test.UserID)
+	assert.Equal(t, c.PublicKey, test.PublicKey)
+	assert.Equal(t, c.WebAuthn(), test.WebAuthn())
+	// duplicate credential id
+	dupe := NewCredential(uuid.New(), "dupe", testCredential())
+	err = conn.Create(dupe).Error
+	assert.Error(t, err)
+	// invalid
+	err = conn.Create(&Credential{}).Error
+	assert.Error(t, err)
+}
+
+func TestEncodeID(t *testing.T) {
+	t.Parallel()
+	id := []byte("credential-id")
+	enc := EncodeID(id)
+	dec, err := DecodeID(enc)
+	assert.NoError(t, err)
+	assert.Equal(t, id, dec)
+	_, err = DecodeID("!")
+	assert.Error(t, err)
+}
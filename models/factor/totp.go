@@ -0,0 +1,101 @@
+package factor
+
+import (
+	"crypto/subtle"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"gorm.io/gorm"
+)
+
+func init() {
+	store.AddAutoMigration("5500-totp", TOTP{})
+}
+
+const (
+	// Period is the number of seconds a totp code is valid.
+	Period = 30
+	// Digits is the number of digits in a totp code.
+	Digits = otp.DigitsSix
+	// Skew is the number of periods before and after
+	// the current period that will be accepted.
+	Skew = 1
+)
+
+// TOTP holds a time-based one-time password (RFC 6238) enrollment.
+type TOTP struct {
+	UserID      uuid.UUID  `json:"user_id" gorm:"<-:create;primaryKey;type:char(36)"`
+	Secret      string     `json:"-" gorm:"type:varchar(255)"`
+	LastStep    int64      `json:"-"`
+	ConfirmedAt *time.Time `json:"confirmed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// NewTOTP returns a new unconfirmed totp enrollment for the user.
+func NewTOTP(userID uuid.UUID, secret string) *TOTP {
+	return &TOTP{
+		UserID: userID,
+		Secret: secret,
+	}
+}
+
+// BeforeSave runs before create or update.
+func (t *TOTP) BeforeSave(*gorm.DB) error {
+	return t.Valid()
+}
+
+// Valid returns nil if the totp enrollment is valid.
+func (t *TOTP) Valid() error {
+	if t.UserID == uuid.Nil || t.UserID == user.SystemID {
+		return errors.New("invalid user id")
+	}
+	if t.Secret == "" {
+		return errors.New("invalid secret")
+	}
+	return nil
+}
+
+// IsConfirmed returns true if the enrollment has been confirmed.
+func (t *TOTP) IsConfirmed() bool {
+	return t.ConfirmedAt != nil
+}
+
+// Validate returns true if the code is valid for the current period. To
+// prevent replays, a code is only accepted once and codes for periods
+// that precede the last accepted code are rejected.
+func (t *TOTP) Validate(code string) bool {
+	return t.validate(code, time.Now().UTC())
+}
+
+func (t *TOTP) validate(code string, now time.Time) bool {
+	if t.Secret == "" || len(code) != Digits.Length() {
+		return false
+	}
+	opts := totp.ValidateOpts{
+		Period:    Period,
+		Digits:    Digits,
+		Algorithm: otp.AlgorithmSHA1,
+	}
+	for skew := -Skew; skew <= Skew; skew++ {
+		at := now.Add(time.Duration(skew*Period) * time.Second)
+		step := at.Unix() / Period
+		if step <= t.LastStep {
+			continue
+		}
+		c, err := totp.GenerateCodeCustom(t.Secret, at, opts)
+		if err != nil {
+			return false
+		}
+		if subtle.ConstantTimeCompare([]byte(c), []byte(code)) == 1 {
+			t.LastStep = step
+			return true
+		}
+	}
+	return false
+}
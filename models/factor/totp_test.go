@@ -0,0 +1,96 @@
+package factor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSecret(t *testing.T) string {
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      "gothic",
+		AccountName: "peaches@example.com",
+	})
+	require.NoError(t, err)
+	return key.Secret()
+}
+
+func testCode(t *testing.T, secret string, at time.Time) string {
+	code, err := totp.GenerateCodeCustom(secret, at, totp.ValidateOpts{
+		Period:    Period,
+		Digits:    Digits,
+		Algorithm: otp.AlgorithmSHA1,
+	})
+	require.NoError(t, err)
+	return code
+}
+
+func TestTOTP_Valid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		uid    uuid.UUID
+		secret string
+		Err    assert.ErrorAssertionFunc
+	}{
+		{uuid.Nil, "", assert.Error},
+		{user.SystemID, "", assert.Error},
+		{uuid.New(), "", assert.Error},
+		{uuid.Nil, testSecret(t), assert.Error},
+		{uuid.New(), testSecret(t), assert.NoError},
+	}
+	for _, test := range tests {
+		err := NewTOTP(test.uid, test.secret).Valid()
+		test.Err(t, err)
+	}
+}
+
+func TestTOTP_BeforeSave(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	tp := NewTOTP(uuid.New(), "")
+	err := conn.Create(tp).Error
+	assert.Error(t, err)
+	tp.Secret = testSecret(t)
+	err = conn.Create(tp).Error
+	assert.NoError(t, err)
+	assert.False(t, tp.IsConfirmed())
+	now := time.Now().UTC()
+	tp.ConfirmedAt = &now
+	err = conn.Save(tp).Error
+	assert.NoError(t, err)
+	db := new(TOTP)
+	err = conn.First(db, "user_id = ?", tp.UserID).Error
+	assert.NoError(t, err)
+	assert.True(t, db.IsConfirmed())
+	assert.Equal(t, tp.Secret, db.Secret)
+}
+
+func TestTOTP_Validate(t *testing.T) {
+	t.Parallel()
+	secret := testSecret(t)
+	now := time.Now().UTC()
+	tp := NewTOTP(uuid.New(), secret)
+	assert.False(t, tp.validate("", now))
+	assert.False(t, tp.validate("1234567", now))
+	old := now.Add(-10 * Period * time.Second)
+	assert.False(t, tp.validate(testCode(t, secret, old), now))
+	code := testCode(t, secret, now)
+	assert.True(t, tp.validate(code, now))
+	// codes cannot be replayed
+	assert.False(t, tp.validate(code, now))
+	prev := now.Add(-Period * time.Second)
+	assert.False(t, tp.validate(testCode(t, secret, prev), now))
+	next := now.Add(Period * time.Second)
+	assert.True(t, tp.validate(testCode(t, secret, next), now))
+	tp = NewTOTP(uuid.New(), secret)
+	assert.True(t, tp.Validate(testCode(t, secret, time.Now().UTC())))
+	tp = NewTOTP(uuid.New(), "")
+	assert.False(t, tp.Validate(code))
+}
@@ -0,0 +1,48 @@
+package history
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+	"gorm.io/gorm"
+)
+
+func init() {
+	store.AddAutoMigration("5700-password_hashes", PasswordHash{})
+}
+
+// PasswordHash is a password hash previously used by a user. The
+// password history is used to prevent a user from reusing passwords.
+type PasswordHash struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uuid.UUID `json:"user_id" gorm:"<-:create;index;type:char(36)"`
+	Password  []byte    `json:"-" gorm:"<-:create;type:varchar(255)"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+// NewPasswordHash returns a new password history entry for the user id & hash.
+func NewPasswordHash(userID uuid.UUID, hash []byte) *PasswordHash {
+	return &PasswordHash{
+		UserID:   userID,
+		Password: hash,
+	}
+}
+
+// BeforeSave runs before create or update.
+func (p *PasswordHash) BeforeSave(*gorm.DB) error {
+	return p.Valid()
+}
+
+// Valid returns nil if the password history entry is valid.
+func (p *PasswordHash) Valid() error {
+	if p.UserID == user.SystemID || p.UserID == user.SuperAdminID {
+		return errors.New("invalid user id")
+	}
+	if len(p.Password) == 0 {
+		return errors.New("invalid password")
+	}
+	return nil
+}
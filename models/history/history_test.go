@@ -0,0 +1,44 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testHash = []byte("$argon2id$v=19$m=65536,t=1,p=4$c2FsdA$aGFzaA")
+
+func TestPasswordHash_Valid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		uid  uuid.UUID
+		hash []byte
+		Err  assert.ErrorAssertionFunc
+	}{
+		{user.SystemID, testHash, assert.Error},
+		{user.SuperAdminID, testHash, assert.Error},
+		{uuid.New(), nil, assert.Error},
+		{uuid.New(), testHash, assert.NoError},
+	}
+	for _, test := range tests {
+		err := NewPasswordHash(test.uid, test.hash).Valid()
+		test.Err(t, err)
+	}
+}
+
+func TestPasswordHash_BeforeSave(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	p := NewPasswordHash(user.SystemID, testHash)
+	err := conn.Create(p).Error
+	assert.Error(t, err)
+	p = NewPasswordHash(uuid.New(), testHash)
+	err = conn.Create(p).Error
+	require.NoError(t, err)
+	assert.NotZero(t, p.ID)
+	assert.False(t, p.CreatedAt.IsZero())
+}
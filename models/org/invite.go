@@ -0,0 +1,82 @@
+package org
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/store"
+	"github.com/jrapoport/gothic/utils"
+	"gorm.io/gorm"
+)
+
+func init() {
+	store.AddAutoMigrationWithIndexes("3000-org_invites",
+		Invite{}, InviteIndexes)
+	// adds tenants
+	store.AddAutoMigrationWithIndexes("3001-org_invites-tenants",
+		Invite{}, InviteIndexes)
+}
+
+// InviteIndexes are the db indexes for the invite in the db.
+var InviteIndexes = append([]string{
+	"idx_org_invite_org_id",
+}, token.AccessTokenIndexes...)
+
+// Invite holds an invitation to join an organization with a role. The
+// user id of the invite is the user that sent the invitation.
+type Invite struct {
+	token.AccessToken
+	OrgID  uuid.UUID  `json:"org_id" gorm:"index:idx_org_invite_org_id;type:char(36)"`
+	Email  string     `json:"email" gorm:"type:varchar(320)"`
+	Role   Role       `json:"role"`
+	SentAt *time.Time `json:"sent_at"`
+}
+
+var _ token.Token = (*Invite)(nil)
+
+// TableName returns the table name for organization invites.
+func (Invite) TableName() string {
+	return "org_invites"
+}
+
+// NewInvite returns a new single use invitation to join the organization
+// with the role, sent by the user to the email address.
+func NewInvite(orgID, userID uuid.UUID, email string, role Role, exp time.Duration) *Invite {
+	at := *token.NewAccessToken(utils.SecureToken(), token.SingleUse, exp)
+	at.UserID = userID
+	return &Invite{
+		AccessToken: at,
+		OrgID:       orgID,
+		Email:       email,
+		Role:        role,
+	}
+}
+
+// BeforeCreate runs before create.
+func (i *Invite) BeforeCreate(tx *gorm.DB) error {
+	if i.OrgID == uuid.Nil {
+		return errors.New("invalid organization id")
+	}
+	if i.Email == "" {
+		return errors.New("invalid email")
+	}
+	if !i.Role.Valid() || i.Role == RoleOwner {
+		return errors.New("invalid role")
+	}
+	return i.AccessToken.BeforeCreate(tx)
+}
+
+// Class returns the class of the invite.
+func (i Invite) Class() token.Class {
+	return token.OrgInvite
+}
+
+// Usable returns true if the invite is usable.
+func (i Invite) Usable() bool {
+	if i.CreatedAt.IsZero() {
+		return false
+	}
+	return i.AccessToken.Usable()
+}
@@ -0,0 +1,45 @@
+package org
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/token"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/jrapoport/gothic/test/tutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewInvite(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	oid := uuid.New()
+	uid := uuid.New()
+	em := tutils.RandomEmail()
+	inv := NewInvite(oid, uid, em, RoleAdmin, time.Hour)
+	assert.NotEmpty(t, inv.Token)
+	assert.Equal(t, oid, inv.OrgID)
+	assert.Equal(t, uid, inv.UserID)
+	assert.Equal(t, em, inv.Email)
+	assert.Equal(t, RoleAdmin, inv.Role)
+	assert.Equal(t, token.OrgInvite, inv.Class())
+	assert.Equal(t, token.Timed, inv.Usage())
+	assert.False(t, inv.Usable())
+	err := conn.Create(inv).Error
+	require.NoError(t, err)
+	assert.True(t, inv.Usable())
+	inv.Use()
+	assert.False(t, inv.Usable())
+	tests := []*Invite{
+		NewInvite(uuid.Nil, uid, em, RoleMember, 0),
+		NewInvite(oid, uid, "", RoleMember, 0),
+		NewInvite(oid, uid, em, InvalidRole, 0),
+		NewInvite(oid, uid, em, RoleOwner, 0),
+	}
+	for _, test := range tests {
+		err = conn.Create(test).Error
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,112 @@
+package org
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/store"
+	"gorm.io/gorm"
+)
+
+func init() {
+	store.AddAutoMigration("4900-organizations", Organization{})
+	store.AddAutoMigrationWithIndexes("4901-org_members",
+		Member{}, []string{"idx_org_member_user_id"})
+	// adds tenants
+	store.AddAutoMigration("4902-organizations-tenants", Organization{})
+}
+
+// MaxName is the max length of an organization name.
+const MaxName = 255
+
+// Organization is an organization users are members of.
+type Organization struct {
+	ID        uuid.UUID      `json:"id" gorm:"primaryKey;type:char(36)"`
+	TenantID  uuid.UUID      `json:"-" gorm:"<-:create;type:char(36);default:'00000000-0000-0000-0000-000000000000'"`
+	Name      string         `json:"name" gorm:"type:varchar(255)"`
+	Data      types.Map      `json:"data"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// TableName returns the table name for organizations.
+func (Organization) TableName() string {
+	return "organizations"
+}
+
+// NewOrganization returns a new organization.
+func NewOrganization(name string, data types.Map) *Organization {
+	return &Organization{
+		ID:   uuid.New(),
+		Name: strings.TrimSpace(name),
+		Data: data,
+	}
+}
+
+// BeforeSave runs before create or update.
+func (o *Organization) BeforeSave(*gorm.DB) error {
+	return o.Valid()
+}
+
+// Valid returns nil if the organization is valid.
+func (o *Organization) Valid() error {
+	if o.ID == uuid.Nil {
+		return errors.New("invalid organization id")
+	}
+	name := strings.TrimSpace(o.Name)
+	if name == "" || len(name) > MaxName {
+		return errors.New("invalid organization name")
+	}
+	return nil
+}
+
+// Member is the membership of a user in an organization.
+type Member struct {
+	OrgID     uuid.UUID `json:"org_id" gorm:"primaryKey;type:char(36)"`
+	UserID    uuid.UUID `json:"user_id" gorm:"primaryKey;index:idx_org_member_user_id;type:char(36)"`
+	Role      Role      `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName returns the table name for organization members.
+func (Member) TableName() string {
+	return "org_members"
+}
+
+// NewMember returns a new member of the organization with the role.
+func NewMember(orgID, userID uuid.UUID, role Role) *Member {
+	return &Member{
+		OrgID:  orgID,
+		UserID: userID,
+		Role:   role,
+	}
+}
+
+// BeforeSave runs before create or update.
+func (m *Member) BeforeSave(*gorm.DB) error {
+	if m.OrgID == uuid.Nil {
+		return errors.New("invalid organization id")
+	}
+	if m.UserID == uuid.Nil {
+		return errors.New("invalid user id")
+	}
+	if !m.Role.Valid() {
+		return errors.New("invalid role")
+	}
+	return nil
+}
+
+// IsOwner returns true if the member is an owner of the organization.
+func (m Member) IsOwner() bool {
+	return m.Role == RoleOwner
+}
+
+// IsAdmin returns true if the member is an admin or an owner of the organization.
+func (m Member) IsAdmin() bool {
+	return m.Role >= RoleAdmin
+}
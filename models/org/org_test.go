@@ -0,0 +1,77 @@
+package org
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOrganization(t *testing.T) {
+	t.Parallel()
+	data := types.Map{"foo": "bar"}
+	o := NewOrganization(" acme ", data)
+	assert.NotEqual(t, uuid.Nil, o.ID)
+	assert.Equal(t, "acme", o.Name)
+	assert.Equal(t, data, o.Data)
+}
+
+func TestOrganization_Valid(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	tests := []struct {
+		o   *Organization
+		Err assert.ErrorAssertionFunc
+	}{
+		{&Organization{}, assert.Error},
+		{&Organization{Name: "acme"}, assert.Error},
+		{NewOrganization("", nil), assert.Error},
+		{NewOrganization(" ", nil), assert.Error},
+		{NewOrganization(strings.Repeat("a", MaxName+1), nil), assert.Error},
+		{NewOrganization("acme", nil), assert.NoError},
+	}
+	for _, test := range tests {
+		test.Err(t, test.o.Valid())
+	}
+	o := NewOrganization("acme", nil)
+	err := conn.Create(o).Error
+	require.NoError(t, err)
+	o.Name = ""
+	err = conn.Save(o).Error
+	assert.Error(t, err)
+}
+
+func TestMember(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	oid := uuid.New()
+	uid := uuid.New()
+	tests := []struct {
+		m   *Member
+		Err assert.ErrorAssertionFunc
+	}{
+		{NewMember(uuid.Nil, uid, RoleMember), assert.Error},
+		{NewMember(oid, uuid.Nil, RoleMember), assert.Error},
+		{NewMember(oid, uid, InvalidRole), assert.Error},
+		{NewMember(oid, uid, RoleMember), assert.NoError},
+	}
+	for _, test := range tests {
+		test.Err(t, conn.Create(test.m).Error)
+	}
+	// duplicate
+	err := conn.Create(NewMember(oid, uid, RoleAdmin)).Error
+	assert.Error(t, err)
+	m := NewMember(oid, uid, RoleMember)
+	assert.False(t, m.IsAdmin())
+	assert.False(t, m.IsOwner())
+	m.Role = RoleAdmin
+	assert.True(t, m.IsAdmin())
+	assert.False(t, m.IsOwner())
+	m.Role = RoleOwner
+	assert.True(t, m.IsAdmin())
+	assert.True(t, m.IsOwner())
+}
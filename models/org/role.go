@@ -0,0 +1,62 @@
+package org
+
+// Role is the role of a member of an organization.
+type Role int8
+
+const (
+	// InvalidRole is an invalid role.
+	InvalidRole Role = iota - 1
+	// RoleMember is a member role.
+	RoleMember
+	// RoleAdmin is an admin role.
+	RoleAdmin
+	// RoleOwner is an owner role.
+	RoleOwner
+)
+
+// Valid returns true if the role is valid.
+func (r Role) Valid() bool {
+	switch r {
+	case RoleMember:
+		break
+	case RoleAdmin:
+		break
+	case RoleOwner:
+		break
+	default:
+		return false
+	}
+	return true
+}
+
+// ToRole returns a role for a string.
+func ToRole(s string) Role {
+	switch s {
+	case "member":
+		return RoleMember
+	case "admin":
+		return RoleAdmin
+	case "owner":
+		return RoleOwner
+	default:
+		return InvalidRole
+	}
+}
+
+func (r Role) String() string {
+	switch r {
+	case RoleMember:
+		return "member"
+	case RoleAdmin:
+		return "admin"
+	case RoleOwner:
+		return "owner"
+	default:
+		return ""
+	}
+}
+
+// MarshalJSON marshals a role to json
+func (r Role) MarshalJSON() ([]byte, error) {
+	return []byte("\"" + r.String() + "\""), nil
+}
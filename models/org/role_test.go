@@ -0,0 +1,36 @@
+package org
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRole(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		role  Role
+		name  string
+		Valid assert.BoolAssertionFunc
+	}{
+		{-2, "", assert.False},
+		{RoleMember, "member", assert.True},
+		{RoleAdmin, "admin", assert.True},
+		{RoleOwner, "owner", assert.True},
+		{math.MaxInt8, "", assert.False},
+	}
+	for _, test := range tests {
+		r := test.role
+		assert.Equal(t, test.name, r.String())
+		test.Valid(t, r.Valid())
+		if r.Valid() {
+			assert.Equal(t, r, ToRole(test.name))
+		} else {
+			assert.Equal(t, InvalidRole, ToRole(test.name))
+		}
+	}
+	b, err := RoleOwner.MarshalJSON()
+	assert.NoError(t, err)
+	assert.Equal(t, `"owner"`, string(b))
+}
@@ -0,0 +1,153 @@
+package rbac
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/jrapoport/gothic/models/user"
+)
+
+// Permission is a named permission that can be granted to a role.
+type Permission string
+
+// Permissions
+const (
+	// AuditRead allows reading the audit logs.
+	AuditRead Permission = "audit:read"
+	// ClientsRead allows reading the oauth & service clients.
+	ClientsRead Permission = "clients:read"
+	// ClientsWrite allows creating & deleting the oauth & service clients.
+	ClientsWrite Permission = "clients:write"
+	// CodesCreate allows creating signup codes.
+	CodesCreate Permission = "codes:create"
+	// CodesDelete allows deleting signup codes.
+	CodesDelete Permission = "codes:delete"
+	// CodesRead allows checking signup codes.
+	CodesRead Permission = "codes:read"
+	// RolesAssign allows changing the role of a user and assigning roles to users.
+	RolesAssign Permission = "roles:assign"
+	// RolesRead allows reading roles and the roles of users.
+	RolesRead Permission = "roles:read"
+	// RolesWrite allows creating, updating & deleting roles.
+	RolesWrite Permission = "roles:write"
+	// SessionsRead allows reading the sessions of users.
+	SessionsRead Permission = "sessions:read"
+	// SessionsRevoke allows revoking the sessions & tokens of users.
+	SessionsRevoke Permission = "sessions:revoke"
+	// SettingsRead allows reading the settings.
+	SettingsRead Permission = "settings:read"
+	// TenantsRead allows reading the tenants.
+	TenantsRead Permission = "tenants:read"
+	// TenantsWrite allows creating, updating & deleting the tenants.
+	TenantsWrite Permission = "tenants:write"
+	// UsersCreate allows creating & importing users.
+	UsersCreate Permission = "users:create"
+	// UsersDelete allows deleting users.
+	UsersDelete Permission = "users:delete"
+	// UsersImpersonate allows impersonating users.
+	UsersImpersonate Permission = "users:impersonate"
+	// UsersRead allows reading & searching users.
+	UsersRead Permission = "users:read"
+	// UsersWrite allows updating, unlocking & forcing password changes for users.
+	UsersWrite Permission = "users:write"
+)
+
+// Permissions are all the permissions.
+var Permissions = []Permission{
+	AuditRead,
+	ClientsRead,
+	ClientsWrite,
+	CodesCreate,
+	CodesDelete,
+	CodesRead,
+	RolesAssign,
+	RolesRead,
+	RolesWrite,
+	SessionsRead,
+	SessionsRevoke,
+	SettingsRead,
+	TenantsRead,
+	TenantsWrite,
+	UsersCreate,
+	UsersDelete,
+	UsersImpersonate,
+	UsersRead,
+	UsersWrite,
+}
+
+// Valid returns true if the permission is valid.
+func (p Permission) Valid() bool {
+	for _, perm := range Permissions {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+func (p Permission) String() string {
+	return string(p)
+}
+
+// RolePermissions returns the permissions for a built-in user role.
+// Admins & super admins have all the permissions.
+func RolePermissions(r user.Role) []Permission {
+	if r < user.RoleAdmin {
+		return []Permission{}
+	}
+	return append([]Permission{}, Permissions...)
+}
+
+// ToPermissions returns a sorted list of unique permissions for strings.
+func ToPermissions(list []string) []Permission {
+	perms := make([]Permission, len(list))
+	for i, s := range list {
+		perms[i] = Permission(s)
+	}
+	return Merge(perms)
+}
+
+// Strings returns the permissions as strings.
+func Strings(perms []Permission) []string {
+	list := make([]string, len(perms))
+	for i, p := range perms {
+		list[i] = p.String()
+	}
+	return list
+}
+
+// Merge returns a sorted list of the unique permissions in the lists.
+func Merge(lists ...[]Permission) []Permission {
+	set := map[Permission]struct{}{}
+	for _, list := range lists {
+		for _, p := range list {
+			set[p] = struct{}{}
+		}
+	}
+	perms := make([]Permission, 0, len(set))
+	for p := range set {
+		perms = append(perms, p)
+	}
+	sort.Slice(perms, func(i, j int) bool {
+		return perms[i] < perms[j]
+	})
+	return perms
+}
+
+// Has returns true if the permissions have the permission.
+func Has(perms []Permission, p Permission) bool {
+	for _, perm := range perms {
+		if perm == p {
+			return true
+		}
+	}
+	return false
+}
+
+func joinPermissions(perms []Permission) string {
+	return strings.Join(Strings(Merge(perms)), " ")
+}
+
+func splitPermissions(s string) []Permission {
+	return ToPermissions(strings.Fields(s))
+}
@@ -0,0 +1,47 @@
+package rbac
+
+import (
+	"testing"
+
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPermission_Valid(t *testing.T) {
+	t.Parallel()
+	for _, p := range Permissions {
+		assert.True(t, p.Valid())
+		assert.Equal(t, string(p), p.String())
+	}
+	assert.False(t, Permission("").Valid())
+	assert.False(t, Permission("users:bad").Valid())
+}
+
+func TestRolePermissions(t *testing.T) {
+	t.Parallel()
+	assert.Empty(t, RolePermissions(user.InvalidRole))
+	assert.Empty(t, RolePermissions(user.RoleSystem))
+	assert.Empty(t, RolePermissions(user.RoleUser))
+	assert.Equal(t, Permissions, RolePermissions(user.RoleAdmin))
+	assert.Equal(t, Permissions, RolePermissions(user.RoleSuper))
+	// the list is a copy
+	perms := RolePermissions(user.RoleAdmin)
+	perms[0] = "bad"
+	assert.Equal(t, AuditRead, Permissions[0])
+}
+
+func TestMerge(t *testing.T) {
+	t.Parallel()
+	perms := Merge(
+		[]Permission{UsersWrite, UsersRead},
+		[]Permission{AuditRead, UsersRead},
+		nil,
+	)
+	assert.Equal(t, []Permission{AuditRead, UsersRead, UsersWrite}, perms)
+	assert.Empty(t, Merge())
+	assert.True(t, Has(perms, UsersRead))
+	assert.False(t, Has(perms, UsersDelete))
+	strs := Strings(perms)
+	assert.Equal(t, []string{"audit:read", "users:read", "users:write"}, strs)
+	assert.Equal(t, perms, ToPermissions([]string{"users:write", "audit:read", "users:read"}))
+}
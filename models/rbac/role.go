@@ -0,0 +1,105 @@
+package rbac
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+	"gorm.io/gorm"
+)
+
+func init() {
+	var roleIndexes = []string{
+		"idx_rbac_tenant_role_name",
+	}
+	store.AddAutoMigrationWithIndexes("4800-rbac_roles",
+		Role{}, roleIndexes)
+	store.AddAutoMigrationWithIndexes("4801-rbac_assignments",
+		Assignment{}, []string{"idx_rbac_assignment_role_id"})
+	// adds tenants, roles are unique per tenant
+	store.AddAutoMigrationReplacingIndexes("4802-rbac_roles-tenants",
+		Role{}, roleIndexes, []string{"idx_rbac_role_name"})
+}
+
+var roleName = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,63}$`)
+
+// Role is a custom role composed of permissions. Roles are unique per tenant.
+type Role struct {
+	gorm.Model
+	TenantID    uuid.UUID `json:"-" gorm:"<-:create;uniqueIndex:idx_rbac_tenant_role_name,priority:1;type:char(36);default:'00000000-0000-0000-0000-000000000000'"`
+	Name        string    `json:"name" gorm:"<-:create;uniqueIndex:idx_rbac_tenant_role_name,priority:2;type:varchar(64)"`
+	Description string    `json:"description"`
+	Permissions string    `json:"permissions"`
+}
+
+// TableName returns the table name for roles.
+func (Role) TableName() string {
+	return "rbac_roles"
+}
+
+// NewRole returns a new role with the permissions.
+func NewRole(name, description string, perms []Permission) *Role {
+	r := &Role{
+		Name:        name,
+		Description: description,
+	}
+	r.SetPermissions(perms)
+	return r
+}
+
+// BeforeSave runs before create or update.
+func (r *Role) BeforeSave(*gorm.DB) error {
+	return r.Valid()
+}
+
+// Valid returns nil if the role is valid.
+func (r *Role) Valid() error {
+	if !ValidRoleName(r.Name) {
+		return fmt.Errorf("invalid role name: %s", r.Name)
+	}
+	perms := r.PermissionList()
+	if len(perms) == 0 {
+		return errors.New("permissions required")
+	}
+	for _, p := range perms {
+		if !p.Valid() {
+			return fmt.Errorf("invalid permission: %s", p)
+		}
+	}
+	return nil
+}
+
+// PermissionList returns the permissions granted to the role.
+func (r Role) PermissionList() []Permission {
+	return splitPermissions(r.Permissions)
+}
+
+// SetPermissions sets the permissions granted to the role.
+func (r *Role) SetPermissions(perms []Permission) {
+	r.Permissions = joinPermissions(perms)
+}
+
+// ValidRoleName returns true if the name can be used for a role. Role names
+// are lowercase and can not be the name of a built-in user role.
+func ValidRoleName(name string) bool {
+	if user.ToRole(name) != user.InvalidRole {
+		return false
+	}
+	return roleName.MatchString(name)
+}
+
+// Assignment assigns a role to a user.
+type Assignment struct {
+	UserID    uuid.UUID `json:"user_id" gorm:"primaryKey;type:char(36)"`
+	RoleID    uint      `json:"role_id" gorm:"primaryKey;autoIncrement:false;index:idx_rbac_assignment_role_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName returns the table name for role assignments.
+func (Assignment) TableName() string {
+	return "rbac_assignments"
+}
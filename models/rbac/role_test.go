@@ -0,0 +1,76 @@
+package rbac
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRole = "support"
+
+func TestNewRole(t *testing.T) {
+	t.Parallel()
+	r := NewRole(testRole, "support staff", []Permission{UsersRead, AuditRead, UsersRead})
+	assert.Equal(t, testRole, r.Name)
+	assert.Equal(t, "support staff", r.Description)
+	assert.Equal(t, "audit:read users:read", r.Permissions)
+	assert.Equal(t, []Permission{AuditRead, UsersRead}, r.PermissionList())
+	r.SetPermissions(nil)
+	assert.Empty(t, r.PermissionList())
+}
+
+func TestRole_Valid(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	perms := []Permission{UsersRead}
+	tests := []struct {
+		r   *Role
+		Err assert.ErrorAssertionFunc
+	}{
+		{&Role{}, assert.Error},
+		{NewRole("", "", perms), assert.Error},
+		{NewRole("Support", "", perms), assert.Error},
+		{NewRole("1support", "", perms), assert.Error},
+		{NewRole("support staff", "", perms), assert.Error},
+		{NewRole("admin", "", perms), assert.Error},
+		{NewRole("super", "", perms), assert.Error},
+		{NewRole(testRole, "", nil), assert.Error},
+		{NewRole(testRole, "", []Permission{"bad"}), assert.Error},
+		{NewRole(testRole, "", perms), assert.NoError},
+		{NewRole("support_staff-2", "", perms), assert.NoError},
+	}
+	for _, test := range tests {
+		test.Err(t, test.r.Valid())
+	}
+	r := NewRole(testRole, "", perms)
+	err := conn.Create(r).Error
+	require.NoError(t, err)
+	// names are unique
+	err = conn.Create(NewRole(testRole, "", perms)).Error
+	assert.Error(t, err)
+	r.SetPermissions(nil)
+	err = conn.Save(r).Error
+	assert.Error(t, err)
+}
+
+func TestAssignment(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	r := NewRole(testRole, "", []Permission{UsersRead})
+	err := conn.Create(r).Error
+	require.NoError(t, err)
+	uid := uuid.New()
+	err = conn.Create(&Assignment{UserID: uid, RoleID: r.ID}).Error
+	require.NoError(t, err)
+	// assignments are unique
+	err = conn.Create(&Assignment{UserID: uid, RoleID: r.ID}).Error
+	assert.Error(t, err)
+	var a Assignment
+	err = conn.First(&a, "user_id = ?", uid).Error
+	require.NoError(t, err)
+	assert.Equal(t, r.ID, a.RoleID)
+	assert.False(t, a.CreatedAt.IsZero())
+}
@@ -0,0 +1,109 @@
+package tenant
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/models/types"
+	"github.com/jrapoport/gothic/store"
+	"github.com/segmentio/encoding/json"
+	"gorm.io/gorm"
+)
+
+func init() {
+	store.AddAutoMigrationWithIndexes("4950-tenants",
+		Tenant{}, []string{"idx_tenant_name", "idx_tenant_host"})
+}
+
+var nameRx = regexp.MustCompile("^[a-z0-9][a-z0-9-]{0,62}$")
+
+// Tenant is a tenant of a multi-tenant service. Each tenant has its own
+// configuration & data.
+type Tenant struct {
+	ID        uuid.UUID `json:"id" gorm:"primaryKey;type:char(36)"`
+	Name      string    `json:"name" gorm:"uniqueIndex:idx_tenant_name;type:varchar(63)"`
+	Host      string    `json:"host" gorm:"index:idx_tenant_host;type:varchar(255)"`
+	Config    types.Map `json:"config"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName returns the table name for tenants.
+func (Tenant) TableName() string {
+	return "tenants"
+}
+
+// NewTenant returns a new tenant. The name must be a lowercase slug (e.g.
+// "acme-inc"). If the host is not empty, requests for the host resolve
+// to the tenant.
+func NewTenant(name, host string, tc config.TenantConfig) (*Tenant, error) {
+	t := &Tenant{
+		ID:   uuid.New(),
+		Name: strings.TrimSpace(name),
+		Host: NormalizeHost(host),
+	}
+	err := t.SetConfig(tc)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// TenantConfig returns the configuration of the tenant.
+func (t Tenant) TenantConfig() (config.TenantConfig, error) {
+	var tc config.TenantConfig
+	if len(t.Config) <= 0 {
+		return tc, nil
+	}
+	b, err := json.Marshal(t.Config)
+	if err != nil {
+		return tc, err
+	}
+	err = json.Unmarshal(b, &tc)
+	return tc, err
+}
+
+// SetConfig sets the configuration of the tenant.
+func (t *Tenant) SetConfig(tc config.TenantConfig) error {
+	b, err := json.Marshal(tc)
+	if err != nil {
+		return err
+	}
+	m := types.Map{}
+	err = json.Unmarshal(b, &m)
+	if err != nil {
+		return err
+	}
+	t.Config = m
+	return nil
+}
+
+// BeforeSave runs before create or update.
+func (t *Tenant) BeforeSave(*gorm.DB) error {
+	return t.Valid()
+}
+
+// Valid returns nil if the tenant is valid.
+func (t *Tenant) Valid() error {
+	if t.ID == uuid.Nil {
+		return errors.New("invalid tenant id")
+	}
+	if !nameRx.MatchString(t.Name) {
+		return errors.New("invalid tenant name")
+	}
+	return nil
+}
+
+// NormalizeHost returns the lowercase host without a port.
+func NormalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if i := strings.LastIndex(host, ":"); i >= 0 &&
+		!strings.HasSuffix(host, "]") {
+		host = host[:i]
+	}
+	return host
+}
@@ -0,0 +1,87 @@
+package tenant
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTenant(t *testing.T) {
+	t.Parallel()
+	signup := config.Signup{Disabled: true}
+	tc := config.TenantConfig{
+		SiteURL: "http://acme.example.com",
+		Signup:  &signup,
+		JWT:     config.TenantJWT{Issuer: "acme"},
+	}
+	tn, err := NewTenant(" acme ", "ACME.example.com:8080", tc)
+	require.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, tn.ID)
+	assert.Equal(t, "acme", tn.Name)
+	assert.Equal(t, "acme.example.com", tn.Host)
+	assert.Equal(t, tc.SiteURL, tn.Config["site_url"])
+	got, err := tn.TenantConfig()
+	require.NoError(t, err)
+	assert.Equal(t, tc, got)
+	tn.Config = nil
+	got, err = tn.TenantConfig()
+	require.NoError(t, err)
+	assert.Equal(t, config.TenantConfig{}, got)
+}
+
+func TestTenant_Valid(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	newTenant := func(name string) *Tenant {
+		tn, err := NewTenant(name, "", config.TenantConfig{})
+		require.NoError(t, err)
+		return tn
+	}
+	tests := []struct {
+		tn  *Tenant
+		Err assert.ErrorAssertionFunc
+	}{
+		{&Tenant{}, assert.Error},
+		{&Tenant{Name: "acme"}, assert.Error},
+		{newTenant(""), assert.Error},
+		{newTenant("Acme"), assert.Error},
+		{newTenant("-acme"), assert.Error},
+		{newTenant("acme inc"), assert.Error},
+		{newTenant("acme-inc"), assert.NoError},
+		{newTenant("acme2"), assert.NoError},
+	}
+	for _, test := range tests {
+		test.Err(t, test.tn.Valid())
+	}
+	tn := newTenant("acme")
+	err := conn.Create(tn).Error
+	require.NoError(t, err)
+	// names are unique
+	err = conn.Create(newTenant("acme")).Error
+	assert.Error(t, err)
+	tn.Name = ""
+	err = conn.Save(tn).Error
+	assert.Error(t, err)
+}
+
+func TestNormalizeHost(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		host   string
+		expect string
+	}{
+		{"", ""},
+		{"example.com", "example.com"},
+		{" Example.COM ", "example.com"},
+		{"example.com:8080", "example.com"},
+		{"[::1]", "[::1]"},
+		{"[::1]:8080", "[::1]"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expect, NormalizeHost(test.host))
+	}
+}
@@ -26,6 +26,7 @@ const NoExpiration time.Duration = 0
 // AccessToken holds an access token.
 type AccessToken struct {
 	gorm.Model
+	TenantID   uuid.UUID     `json:"-" gorm:"type:char(36);default:'00000000-0000-0000-0000-000000000000'"`
 	UserID     uuid.UUID     `json:"user_id" gorm:"index:idx_user_id;uniqueIndex:idx_user_id_token;type:char(36)"`
 	Type       Type          `json:"type"`
 	Token      string        `json:"token" gorm:"index:idx_token;uniqueIndex:idx_user_id_token"`
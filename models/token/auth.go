@@ -1,6 +1,7 @@
 package token
 
 import (
+	"crypto/subtle"
 	"errors"
 	"time"
 
@@ -13,12 +14,23 @@ func init() {
 	indexes := append(AccessTokenIndexes, "idx_provider")
 	store.AddAutoMigrationWithIndexes("3000-auth_tokens",
 		AuthToken{}, indexes)
+	// adds tenants
+	store.AddAutoMigrationWithIndexes("3001-auth_tokens-tenants",
+		AuthToken{}, indexes)
+	// adds pkce, nonce & client binding
+	store.AddAutoMigrationWithIndexes("3002-auth_tokens-binding",
+		AuthToken{}, indexes)
 }
 
-// AuthToken holds an auth token.
+// AuthToken holds an auth token. The auth token is the state of an
+// external provider flow, and it is bound to the client that started
+// the flow with a PKCE code challenge, a client binding, or both.
 type AuthToken struct {
 	AccessToken
 	Provider provider.Name `json:"provider" gorm:"index:idx_provider;type:char(255)"`
+	Nonce    string        `json:"nonce" gorm:"type:varchar(255)"`
+	Binding  string        `json:"-" gorm:"type:varchar(255)"`
+	Challenge
 }
 
 var _ Token = (*AuthToken)(nil)
@@ -35,6 +47,33 @@ func (at AuthToken) Class() Class {
 	return Auth
 }
 
+// SetBinding binds the auth token to a secret held by the client, e.g. in a
+// cookie. Only a hash of the secret is stored.
+func (at *AuthToken) SetBinding(secret string) {
+	if secret == "" {
+		at.Binding = ""
+		return
+	}
+	at.Binding = hashSecret(secret)
+}
+
+// VerifyBinding returns true if the secret matches the client binding.
+// If there is no client binding, the secret must be empty.
+func (at AuthToken) VerifyBinding(secret string) bool {
+	if at.Binding == "" {
+		return secret == ""
+	}
+	if secret == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(at.Binding)) == 1
+}
+
+// Bound returns true if the auth token is bound to a client.
+func (at AuthToken) Bound() bool {
+	return at.Binding != "" || at.CodeChallenge != ""
+}
+
 // HasToken returns true if the auth token is found.
 func (at AuthToken) HasToken(tx *store.Connection) (bool, error) {
 	if at.Token == "" {
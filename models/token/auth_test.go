@@ -48,3 +48,24 @@ func TestAuthToken_HasToken(t *testing.T) {
 		test.Has(t, has)
 	}
 }
+
+func TestAuthToken_VerifyBinding(t *testing.T) {
+	t.Parallel()
+	const secret = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	tk := NewAuthToken(provider.Google, 0)
+	assert.False(t, tk.Bound())
+	assert.True(t, tk.VerifyBinding(""))
+	assert.False(t, tk.VerifyBinding(secret))
+	tk.SetBinding(secret)
+	assert.True(t, tk.Bound())
+	assert.NotEqual(t, secret, tk.Binding)
+	assert.True(t, tk.VerifyBinding(secret))
+	assert.False(t, tk.VerifyBinding(""))
+	assert.False(t, tk.VerifyBinding("bad"))
+	tk.SetBinding("")
+	assert.False(t, tk.Bound())
+	err := tk.SetChallenge(secret, ChallengePlain)
+	require.NoError(t, err)
+	assert.True(t, tk.Bound())
+	assert.True(t, tk.VerifyChallenge(secret))
+}
@@ -0,0 +1,56 @@
+package token
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/store"
+	"github.com/jrapoport/gothic/utils"
+)
+
+func init() {
+	indexes := append(AccessTokenIndexes, "idx_client_id")
+	store.AddAutoMigrationWithIndexes("3000-authorization_codes",
+		AuthorizationCode{}, indexes)
+	// adds tenants
+	store.AddAutoMigrationWithIndexes("3001-authorization_codes-tenants",
+		AuthorizationCode{}, indexes)
+}
+
+// AuthorizationCode holds an OAuth 2.0 authorization code
+// issued to a client by the OpenID Connect provider.
+type AuthorizationCode struct {
+	AccessToken
+	ClientID    string `json:"client_id" gorm:"index:idx_client_id;type:varchar(255)"`
+	RedirectURI string `json:"redirect_uri"`
+	Scope       string `json:"scope" gorm:"type:varchar(255)"`
+	Nonce       string `json:"nonce" gorm:"type:varchar(255)"`
+	Challenge
+}
+
+var _ Token = (*AuthorizationCode)(nil)
+
+// NewAuthorizationCode generates a new single use authorization code.
+func NewAuthorizationCode(userID uuid.UUID, clientID, redirectURI, scope string, exp time.Duration) *AuthorizationCode {
+	at := *NewAccessToken(utils.SecureToken(), SingleUse, exp)
+	at.UserID = userID
+	return &AuthorizationCode{
+		AccessToken: at,
+		ClientID:    clientID,
+		RedirectURI: redirectURI,
+		Scope:       scope,
+	}
+}
+
+// Class returns the class of the authorization code.
+func (ac AuthorizationCode) Class() Class {
+	return Authorization
+}
+
+// Usable returns true if the authorization code is usable.
+func (ac AuthorizationCode) Usable() bool {
+	if ac.CreatedAt.IsZero() {
+		return false
+	}
+	return ac.AccessToken.Usable()
+}
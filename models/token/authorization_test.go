@@ -0,0 +1,73 @@
+package token
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRedirect = "https://client.example.com/callback"
+
+func TestAuthorizationCode_Class(t *testing.T) {
+	t.Parallel()
+	uid := uuid.New()
+	cid := uuid.New().String()
+	ac := NewAuthorizationCode(uid, cid, testRedirect, "openid", time.Minute)
+	assert.Equal(t, Authorization, ac.Class())
+	assert.Equal(t, Timed, ac.Usage())
+	assert.Equal(t, SingleUse, ac.MaxUses)
+	assert.Equal(t, uid, ac.UserID)
+	assert.Equal(t, cid, ac.ClientID)
+	assert.Equal(t, testRedirect, ac.RedirectURI)
+	assert.Equal(t, "openid", ac.Scope)
+	assert.NotEmpty(t, ac.String())
+}
+
+func TestAuthorizationCode_Usable(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	ac := NewAuthorizationCode(uuid.New(), uuid.New().String(), testRedirect, "", time.Minute)
+	assert.False(t, ac.Usable())
+	err := conn.Create(ac).Error
+	require.NoError(t, err)
+	assert.True(t, ac.Usable())
+	ac.Use()
+	assert.False(t, ac.Usable())
+}
+
+func TestAuthorizationCode_VerifyChallenge(t *testing.T) {
+	t.Parallel()
+	const verifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	sum := sha256.Sum256([]byte(verifier))
+	s256 := base64.RawURLEncoding.EncodeToString(sum[:])
+	ac := NewAuthorizationCode(uuid.New(), uuid.New().String(), testRedirect, "", 0)
+	// no challenge
+	assert.True(t, ac.VerifyChallenge(""))
+	assert.False(t, ac.VerifyChallenge(verifier))
+	err := ac.SetChallenge(verifier, "bad")
+	assert.Error(t, err)
+	// plain
+	err = ac.SetChallenge(verifier, "")
+	require.NoError(t, err)
+	assert.Equal(t, ChallengePlain, ac.CodeChallengeMethod)
+	assert.True(t, ac.VerifyChallenge(verifier))
+	assert.False(t, ac.VerifyChallenge(""))
+	assert.False(t, ac.VerifyChallenge("bad"))
+	// S256
+	err = ac.SetChallenge(s256, ChallengeS256)
+	require.NoError(t, err)
+	assert.True(t, ac.VerifyChallenge(verifier))
+	assert.False(t, ac.VerifyChallenge(s256))
+	assert.False(t, ac.VerifyChallenge(""))
+	// cleared
+	err = ac.SetChallenge("", ChallengeS256)
+	require.NoError(t, err)
+	assert.Empty(t, ac.CodeChallengeMethod)
+	assert.True(t, ac.VerifyChallenge(""))
+}
@@ -0,0 +1,62 @@
+package token
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+)
+
+// PKCE code challenge methods.
+const (
+	ChallengePlain = "plain"
+	ChallengeS256  = "S256"
+)
+
+// Challenge holds a PKCE code challenge that binds a token to the client
+// that requested it. Only the client that holds the code verifier can use
+// the token.
+type Challenge struct {
+	CodeChallenge       string `json:"code_challenge" gorm:"type:varchar(255)"`
+	CodeChallengeMethod string `json:"code_challenge_method" gorm:"type:varchar(16)"`
+}
+
+// SetChallenge sets the PKCE code challenge. If the method is empty it defaults to plain.
+func (c *Challenge) SetChallenge(challenge, method string) error {
+	if challenge == "" {
+		c.CodeChallenge = ""
+		c.CodeChallengeMethod = ""
+		return nil
+	}
+	if method == "" {
+		method = ChallengePlain
+	}
+	if method != ChallengePlain && method != ChallengeS256 {
+		return errors.New("invalid code challenge method")
+	}
+	c.CodeChallenge = challenge
+	c.CodeChallengeMethod = method
+	return nil
+}
+
+// VerifyChallenge returns true if the PKCE code verifier matches the code challenge.
+// If there is no code challenge, the code verifier must be empty.
+func (c Challenge) VerifyChallenge(verifier string) bool {
+	if c.CodeChallenge == "" {
+		return verifier == ""
+	}
+	if verifier == "" {
+		return false
+	}
+	challenge := verifier
+	if c.CodeChallengeMethod == ChallengeS256 {
+		challenge = hashSecret(verifier)
+	}
+	return subtle.ConstantTimeCompare([]byte(challenge), []byte(c.CodeChallenge)) == 1
+}
+
+// hashSecret returns the unpadded base64 url encoded sha256 hash of a secret.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -12,6 +12,9 @@ import (
 func init() {
 	store.AddAutoMigrationWithIndexes("3000-confirm_tokens",
 		ConfirmToken{}, AccessTokenIndexes)
+	// adds tenants
+	store.AddAutoMigrationWithIndexes("3001-confirm_tokens-tenants",
+		ConfirmToken{}, AccessTokenIndexes)
 }
 
 // ConfirmToken holds a confirmation token.
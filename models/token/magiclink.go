@@ -0,0 +1,54 @@
+package token
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/store"
+	"github.com/jrapoport/gothic/utils"
+)
+
+func init() {
+	store.AddAutoMigrationWithIndexes("3000-magic_link_tokens",
+		MagicLinkToken{}, AccessTokenIndexes)
+	// adds tenants
+	store.AddAutoMigrationWithIndexes("3001-magic_link_tokens-tenants",
+		MagicLinkToken{}, AccessTokenIndexes)
+}
+
+// MagicLinkToken holds a passwordless email login token.
+type MagicLinkToken struct {
+	AccessToken
+	SentAt *time.Time `json:"sent_at"`
+}
+
+var _ Token = (*MagicLinkToken)(nil)
+
+// NewMagicLinkToken generates a new single use magic link token.
+func NewMagicLinkToken(userID uuid.UUID, exp time.Duration) *MagicLinkToken {
+	at := *NewAccessToken(utils.SecureToken(), SingleUse, exp)
+	at.UserID = userID
+	return &MagicLinkToken{AccessToken: at}
+}
+
+// Class returns the class of the magic link token.
+func (mt MagicLinkToken) Class() Class {
+	return MagicLink
+}
+
+// Usable returns true if the token is usable.
+func (mt MagicLinkToken) Usable() bool {
+	if mt.CreatedAt.IsZero() {
+		return false
+	}
+	return mt.AccessToken.Usable()
+}
+
+// HasToken returns true if the magic link token is found.
+func (mt MagicLinkToken) HasToken(tx *store.Connection) (bool, error) {
+	if mt.Token == "" {
+		return false, errors.New("invalid token")
+	}
+	return tx.Has(&mt, "token = ?", mt.Token)
+}
@@ -0,0 +1,60 @@
+package token
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/store"
+	"github.com/jrapoport/gothic/utils"
+)
+
+func init() {
+	store.AddAutoMigrationWithIndexes("3000-mfa_tokens",
+		MFAToken{}, AccessTokenIndexes)
+	// adds tenants
+	store.AddAutoMigrationWithIndexes("3001-mfa_tokens-tenants",
+		MFAToken{}, AccessTokenIndexes)
+}
+
+// MFAAttempts is the number of times an mfa challenge may be attempted.
+const MFAAttempts = 5
+
+// MFAChallenges is the number of mfa challenges a user
+// may be granted within the lockout window.
+const MFAChallenges = 10
+
+// MFAToken holds a multi-factor authentication challenge token.
+type MFAToken struct {
+	AccessToken
+}
+
+var _ Token = (*MFAToken)(nil)
+
+// NewMFAToken generates a new mfa challenge token for the user.
+func NewMFAToken(userID uuid.UUID, exp time.Duration) *MFAToken {
+	at := *NewAccessToken(utils.SecureToken(), MFAAttempts, exp)
+	at.UserID = userID
+	return &MFAToken{at}
+}
+
+// Class returns the class of the mfa token.
+func (mt MFAToken) Class() Class {
+	return MFA
+}
+
+// Usable returns true if the token is usable.
+func (mt MFAToken) Usable() bool {
+	if mt.CreatedAt.IsZero() {
+		return false
+	}
+	return mt.AccessToken.Usable()
+}
+
+// HasToken returns true if the mfa token is found.
+func (mt MFAToken) HasToken(tx *store.Connection) (bool, error) {
+	if mt.Token == "" {
+		return false, errors.New("invalid token")
+	}
+	return tx.Has(&mt, "token = ?", mt.Token)
+}
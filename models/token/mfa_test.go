@@ -0,0 +1,65 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMFAToken_Kind(t *testing.T) {
+	t.Parallel()
+	assert.NotPanics(t, func() {
+		tk := NewMFAToken(uuid.New(), 0)
+		cls := tk.Class()
+		assert.Equal(t, MFA, cls)
+	})
+}
+
+func TestMFAToken_HasToken(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	createToken := func() *MFAToken {
+		tk := NewMFAToken(uuid.New(), 0)
+		assert.False(t, tk.Usable())
+		err := conn.Create(tk).Error
+		require.NoError(t, err)
+		assert.True(t, tk.Usable())
+		return tk
+	}
+	deletedToken := createToken()
+	err := conn.Delete(deletedToken).Error
+	require.NoError(t, err)
+	tests := []struct {
+		ct  *MFAToken
+		Err assert.ErrorAssertionFunc
+		Has assert.BoolAssertionFunc
+	}{
+		{&MFAToken{}, assert.Error, assert.False},
+		{NewMFAToken(uuid.New(), 0), assert.NoError, assert.False},
+		{createToken(), assert.NoError, assert.True},
+		{deletedToken, assert.NoError, assert.False},
+	}
+	var has bool
+	for _, test := range tests {
+		has, err = test.ct.HasToken(conn)
+		test.Err(t, err)
+		test.Has(t, has)
+	}
+}
+
+func TestMFAToken_Attempts(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	tk := NewMFAToken(uuid.New(), time.Minute)
+	err := conn.Create(tk).Error
+	require.NoError(t, err)
+	for i := 0; i < MFAAttempts; i++ {
+		assert.True(t, tk.Usable())
+		tk.Use()
+	}
+	assert.False(t, tk.Usable())
+}
@@ -0,0 +1,52 @@
+package token
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/store"
+	"github.com/jrapoport/gothic/utils"
+)
+
+func init() {
+	indexes := append(AccessTokenIndexes, "idx_client_id")
+	store.AddAutoMigrationWithIndexes("3000-oauth_tokens",
+		OAuthToken{}, indexes)
+	// adds tenants
+	store.AddAutoMigrationWithIndexes("3001-oauth_tokens-tenants",
+		OAuthToken{}, indexes)
+}
+
+// OAuthToken holds an access token issued to an OAuth 2.0 client by the
+// OpenID Connect provider. It is only accepted by the userinfo endpoint.
+type OAuthToken struct {
+	AccessToken
+	ClientID string `json:"client_id" gorm:"index:idx_client_id;type:varchar(255)"`
+	Scope    string `json:"scope" gorm:"type:varchar(255)"`
+}
+
+var _ Token = (*OAuthToken)(nil)
+
+// NewOAuthToken generates a new timed access token for the client.
+func NewOAuthToken(userID uuid.UUID, clientID, scope string, exp time.Duration) *OAuthToken {
+	at := *NewAccessToken(utils.SecureToken(), InfiniteUse, exp)
+	at.UserID = userID
+	return &OAuthToken{
+		AccessToken: at,
+		ClientID:    clientID,
+		Scope:       scope,
+	}
+}
+
+// Class returns the class of the oauth token.
+func (ot OAuthToken) Class() Class {
+	return OAuth
+}
+
+// Usable returns true if the oauth token is usable.
+func (ot OAuthToken) Usable() bool {
+	if ot.CreatedAt.IsZero() {
+		return false
+	}
+	return ot.AccessToken.Usable()
+}
@@ -0,0 +1,40 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOAuthToken_Class(t *testing.T) {
+	t.Parallel()
+	uid := uuid.New()
+	cid := uuid.New().String()
+	ot := NewOAuthToken(uid, cid, "openid email", time.Hour)
+	assert.Equal(t, OAuth, ot.Class())
+	assert.Equal(t, Timed, ot.Usage())
+	assert.Equal(t, uid, ot.UserID)
+	assert.Equal(t, cid, ot.ClientID)
+	assert.Equal(t, "openid email", ot.Scope)
+}
+
+func TestOAuthToken_Usable(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	ot := NewOAuthToken(uuid.New(), uuid.New().String(), "openid", time.Hour)
+	assert.False(t, ot.Usable())
+	err := conn.Create(ot).Error
+	require.NoError(t, err)
+	assert.True(t, ot.Usable())
+	// may be used repeatedly
+	ot.Use()
+	ot.Use()
+	assert.True(t, ot.Usable())
+	past := time.Now().UTC().Add(-time.Minute)
+	ot.ExpiredAt = &past
+	assert.False(t, ot.Usable())
+}
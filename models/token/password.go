@@ -0,0 +1,56 @@
+package token
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/store"
+	"github.com/jrapoport/gothic/utils"
+)
+
+func init() {
+	store.AddAutoMigrationWithIndexes("3000-password_change_tokens",
+		PasswordChangeToken{}, AccessTokenIndexes)
+	// adds tenants
+	store.AddAutoMigrationWithIndexes("3001-password_change_tokens-tenants",
+		PasswordChangeToken{}, AccessTokenIndexes)
+}
+
+// PasswordChangeAttempts is the number of times a password change may be attempted.
+const PasswordChangeAttempts = 5
+
+// PasswordChangeToken holds a token that may only be used to change a password.
+type PasswordChangeToken struct {
+	AccessToken
+}
+
+var _ Token = (*PasswordChangeToken)(nil)
+
+// NewPasswordChangeToken generates a new password change token for the user.
+func NewPasswordChangeToken(userID uuid.UUID, exp time.Duration) *PasswordChangeToken {
+	at := *NewAccessToken(utils.SecureToken(), PasswordChangeAttempts, exp)
+	at.UserID = userID
+	return &PasswordChangeToken{at}
+}
+
+// Class returns the class of the password change token.
+func (pt PasswordChangeToken) Class() Class {
+	return PasswordChange
+}
+
+// Usable returns true if the token is usable.
+func (pt PasswordChangeToken) Usable() bool {
+	if pt.CreatedAt.IsZero() {
+		return false
+	}
+	return pt.AccessToken.Usable()
+}
+
+// HasToken returns true if the password change token is found.
+func (pt PasswordChangeToken) HasToken(tx *store.Connection) (bool, error) {
+	if pt.Token == "" {
+		return false, errors.New("invalid token")
+	}
+	return tx.Has(&pt, "token = ?", pt.Token)
+}
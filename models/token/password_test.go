@@ -0,0 +1,65 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordChangeToken_Kind(t *testing.T) {
+	t.Parallel()
+	assert.NotPanics(t, func() {
+		tk := NewPasswordChangeToken(uuid.New(), 0)
+		cls := tk.Class()
+		assert.Equal(t, PasswordChange, cls)
+	})
+}
+
+func TestPasswordChangeToken_HasToken(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	createToken := func() *PasswordChangeToken {
+		tk := NewPasswordChangeToken(uuid.New(), 0)
+		assert.False(t, tk.Usable())
+		err := conn.Create(tk).Error
+		require.NoError(t, err)
+		assert.True(t, tk.Usable())
+		return tk
+	}
+	deletedToken := createToken()
+	err := conn.Delete(deletedToken).Error
+	require.NoError(t, err)
+	tests := []struct {
+		ct  *PasswordChangeToken
+		Err assert.ErrorAssertionFunc
+		Has assert.BoolAssertionFunc
+	}{
+		{&PasswordChangeToken{}, assert.Error, assert.False},
+		{NewPasswordChangeToken(uuid.New(), 0), assert.NoError, assert.False},
+		{createToken(), assert.NoError, assert.True},
+		{deletedToken, assert.NoError, assert.False},
+	}
+	var has bool
+	for _, test := range tests {
+		has, err = test.ct.HasToken(conn)
+		test.Err(t, err)
+		test.Has(t, has)
+	}
+}
+
+func TestPasswordChangeToken_Attempts(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	tk := NewPasswordChangeToken(uuid.New(), time.Minute)
+	err := conn.Create(tk).Error
+	require.NoError(t, err)
+	for i := 0; i < PasswordChangeAttempts; i++ {
+		assert.True(t, tk.Usable())
+		tk.Use()
+	}
+	assert.False(t, tk.Usable())
+}
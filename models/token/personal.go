@@ -0,0 +1,115 @@
+package token
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/store"
+	"github.com/jrapoport/gothic/utils"
+)
+
+func init() {
+	store.AddAutoMigrationWithIndexes("3000-personal_tokens",
+		PersonalToken{}, PersonalTokenIndexes)
+	// adds tenants
+	store.AddAutoMigrationWithIndexes("3001-personal_tokens-tenants",
+		PersonalToken{}, PersonalTokenIndexes)
+}
+
+// PersonalTokenPrefix is the prefix of a personal access token.
+const PersonalTokenPrefix = "gpat_"
+
+// Personal access token scopes
+const (
+	// ScopeRead allows requests that do not change anything.
+	ScopeRead = "read"
+	// ScopeWrite allows all requests.
+	ScopeWrite = "write"
+	// ScopeAdmin allows admin requests if the user is an admin.
+	ScopeAdmin = "admin"
+)
+
+// PersonalScopes are the scopes a personal access token can be granted.
+var PersonalScopes = []string{
+	ScopeRead,
+	ScopeWrite,
+	ScopeAdmin,
+}
+
+// PersonalTokenIndexes are the db indexes for the personal token in the db.
+var PersonalTokenIndexes = append([]string{
+	"idx_personal_token_id",
+}, AccessTokenIndexes...)
+
+// PersonalToken holds a long lived personal access token issued by a user.
+// Only a hash of the token is stored, so the token can not be returned again.
+type PersonalToken struct {
+	AccessToken
+	TokenID   uuid.UUID `json:"token_id" gorm:"<-:create;uniqueIndex:idx_personal_token_id;type:char(36)"`
+	Name      string    `json:"name" gorm:"type:varchar(255)"`
+	Scope     string    `json:"scope" gorm:"type:varchar(255)"`
+	IPAddress string    `json:"ip_address,omitempty"`
+	// Secret is the token. It is only set when the token is created.
+	Secret string `json:"-" gorm:"-"`
+}
+
+var _ Token = (*PersonalToken)(nil)
+
+// NewPersonalToken generates a new personal access token for the user. If
+// the expiration is token.NoExpiration, the token does not expire.
+func NewPersonalToken(userID uuid.UUID, name, scope string, exp time.Duration) *PersonalToken {
+	secret := PersonalTokenPrefix + utils.SecureToken() + utils.SecureToken()
+	at := *NewAccessToken(HashPersonalToken(secret), InfiniteUse, exp)
+	at.UserID = userID
+	return &PersonalToken{
+		AccessToken: at,
+		TokenID:     uuid.New(),
+		Name:        name,
+		Scope:       scope,
+		Secret:      secret,
+	}
+}
+
+// Class returns the class of the personal token.
+func (pt PersonalToken) Class() Class {
+	return Personal
+}
+
+// Usable returns true if the personal token is usable.
+func (pt PersonalToken) Usable() bool {
+	if pt.CreatedAt.IsZero() {
+		return false
+	}
+	return pt.AccessToken.Usable()
+}
+
+// UseFrom uses the token from the ip address.
+func (pt *PersonalToken) UseFrom(ip string) {
+	pt.Use()
+	pt.IPAddress = ip
+}
+
+// String returns the token id. The token is never logged.
+func (pt PersonalToken) String() string {
+	return pt.TokenID.String()
+}
+
+// Scopes returns the scopes granted to the personal token.
+func (pt PersonalToken) Scopes() []string {
+	return strings.Fields(pt.Scope)
+}
+
+// IsPersonalToken returns true if the token is a personal access token.
+func IsPersonalToken(tok string) bool {
+	return strings.HasPrefix(tok, PersonalTokenPrefix)
+}
+
+// HashPersonalToken returns the hash of a personal access token that is
+// stored. The token has enough entropy that it does not need to be salted.
+func HashPersonalToken(tok string) string {
+	sum := sha256.Sum256([]byte(tok))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,65 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersonalToken_Class(t *testing.T) {
+	t.Parallel()
+	uid := uuid.New()
+	pt := NewPersonalToken(uid, "test", "read write", NoExpiration)
+	assert.Equal(t, Personal, pt.Class())
+	assert.Equal(t, Infinite, pt.Usage())
+	assert.Equal(t, uid, pt.UserID)
+	assert.NotEqual(t, uuid.Nil, pt.TokenID)
+	assert.Equal(t, "test", pt.Name)
+	assert.Equal(t, []string{ScopeRead, ScopeWrite}, pt.Scopes())
+	assert.True(t, IsPersonalToken(pt.Secret))
+	assert.Equal(t, HashPersonalToken(pt.Secret), pt.Token)
+	assert.NotContains(t, pt.Token, pt.Secret)
+	assert.Equal(t, pt.TokenID.String(), pt.String())
+	pt = NewPersonalToken(uid, "test", ScopeRead, time.Hour)
+	assert.Equal(t, Timed, pt.Usage())
+}
+
+func TestPersonalToken_Usable(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	pt := NewPersonalToken(uuid.New(), "test", ScopeRead, time.Hour)
+	assert.False(t, pt.Usable())
+	err := conn.Create(pt).Error
+	require.NoError(t, err)
+	assert.True(t, pt.Usable())
+	assert.NotNil(t, pt.ExpiredAt)
+	// may be used repeatedly
+	pt.UseFrom("127.0.0.1")
+	pt.UseFrom("127.0.0.2")
+	assert.True(t, pt.Usable())
+	assert.Equal(t, 2, pt.Used)
+	assert.Equal(t, "127.0.0.2", pt.IPAddress)
+	assert.False(t, pt.LastUsed().IsZero())
+	// the secret is not stored
+	var got PersonalToken
+	err = conn.First(&got, "token_id = ?", pt.TokenID).Error
+	require.NoError(t, err)
+	assert.Empty(t, got.Secret)
+	assert.Equal(t, pt.Token, got.Token)
+	past := time.Now().UTC().Add(-time.Minute)
+	pt.ExpiredAt = &past
+	assert.False(t, pt.Usable())
+}
+
+func TestIsPersonalToken(t *testing.T) {
+	t.Parallel()
+	assert.True(t, IsPersonalToken(PersonalTokenPrefix+"token"))
+	assert.False(t, IsPersonalToken("eyJhbGciOiJIUzI1NiJ9.e30.sig"))
+	assert.False(t, IsPersonalToken(""))
+	assert.Equal(t, HashPersonalToken("a"), HashPersonalToken("a"))
+	assert.NotEqual(t, HashPersonalToken("a"), HashPersonalToken("b"))
+}
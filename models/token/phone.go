@@ -0,0 +1,87 @@
+package token
+
+import (
+	"crypto/subtle"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/store"
+	"github.com/jrapoport/gothic/utils"
+)
+
+func init() {
+	store.AddAutoMigrationWithIndexes("3000-phone_tokens",
+		PhoneToken{}, AccessTokenIndexes)
+	// adds tenants
+	store.AddAutoMigrationWithIndexes("3001-phone_tokens-tenants",
+		PhoneToken{}, AccessTokenIndexes)
+	// adds purposes, phone changes & logins have separate tokens
+	store.AddAutoMigrationWithIndexes("3002-phone_tokens-purpose",
+		PhoneToken{}, AccessTokenIndexes)
+}
+
+// PhoneAttempts is the number of times a phone code may be attempted.
+const PhoneAttempts = 5
+
+// PhonePurpose is what a phone token is used for.
+type PhonePurpose int8
+
+const (
+	// PhoneChange is a phone token that confirms a phone number change.
+	PhoneChange PhonePurpose = iota
+	// PhoneLogin is a phone token that logs in a user.
+	PhoneLogin
+)
+
+// PhoneToken holds a phone confirmation token and its sms code.
+type PhoneToken struct {
+	AccessToken
+	Purpose PhonePurpose `json:"purpose" gorm:"<-:create;default:0"`
+	Phone   string       `json:"phone" gorm:"type:varchar(16)"`
+	Code    string       `json:"-" gorm:"type:varchar(6)"`
+	SentAt  *time.Time   `json:"sent_at"`
+}
+
+var _ Token = (*PhoneToken)(nil)
+
+// NewPhoneToken generates a new phone token and code for the user.
+func NewPhoneToken(userID uuid.UUID, purpose PhonePurpose, phone string, exp time.Duration) *PhoneToken {
+	at := *NewAccessToken(utils.SecureToken(), PhoneAttempts, exp)
+	at.UserID = userID
+	return &PhoneToken{
+		AccessToken: at,
+		Purpose:     purpose,
+		Phone:       phone,
+		Code:        utils.PINCode(),
+	}
+}
+
+// Class returns the class of the phone token.
+func (pt PhoneToken) Class() Class {
+	return Phone
+}
+
+// Usable returns true if the token is usable.
+func (pt PhoneToken) Usable() bool {
+	if pt.CreatedAt.IsZero() {
+		return false
+	}
+	return pt.AccessToken.Usable()
+}
+
+// HasToken returns true if the phone token is found.
+func (pt PhoneToken) HasToken(tx *store.Connection) (bool, error) {
+	if pt.Token == "" {
+		return false, errors.New("invalid token")
+	}
+	return tx.Has(&pt, "token = ?", pt.Token)
+}
+
+// Verify returns true if the code matches the phone token code.
+func (pt PhoneToken) Verify(code string) bool {
+	if pt.Code == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(pt.Code), []byte(code)) == 1
+}
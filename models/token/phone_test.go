@@ -0,0 +1,78 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPhone = "+15555550100"
+
+func TestPhoneToken_Kind(t *testing.T) {
+	t.Parallel()
+	assert.NotPanics(t, func() {
+		tk := NewPhoneToken(uuid.New(), PhoneChange, testPhone, 0)
+		cls := tk.Class()
+		assert.Equal(t, Phone, cls)
+	})
+}
+
+func TestPhoneToken_HasToken(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	createToken := func() *PhoneToken {
+		tk := NewPhoneToken(uuid.New(), PhoneChange, testPhone, 0)
+		assert.False(t, tk.Usable())
+		err := conn.Create(tk).Error
+		require.NoError(t, err)
+		assert.True(t, tk.Usable())
+		return tk
+	}
+	deletedToken := createToken()
+	err := conn.Delete(deletedToken).Error
+	require.NoError(t, err)
+	tests := []struct {
+		ct  *PhoneToken
+		Err assert.ErrorAssertionFunc
+		Has assert.BoolAssertionFunc
+	}{
+		{&PhoneToken{}, assert.Error, assert.False},
+		{NewPhoneToken(uuid.New(), PhoneChange, testPhone, 0), assert.NoError, assert.False},
+		{createToken(), assert.NoError, assert.True},
+		{deletedToken, assert.NoError, assert.False},
+	}
+	var has bool
+	for _, test := range tests {
+		has, err = test.ct.HasToken(conn)
+		test.Err(t, err)
+		test.Has(t, has)
+	}
+}
+
+func TestPhoneToken_Attempts(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	tk := NewPhoneToken(uuid.New(), PhoneChange, testPhone, time.Minute)
+	err := conn.Create(tk).Error
+	require.NoError(t, err)
+	for i := 0; i < PhoneAttempts; i++ {
+		assert.True(t, tk.Usable())
+		tk.Use()
+	}
+	assert.False(t, tk.Usable())
+}
+
+func TestPhoneToken_Verify(t *testing.T) {
+	t.Parallel()
+	tk := NewPhoneToken(uuid.New(), PhoneChange, testPhone, 0)
+	assert.Len(t, tk.Code, 6)
+	assert.True(t, tk.Verify(tk.Code))
+	assert.False(t, tk.Verify(""))
+	assert.False(t, tk.Verify("bad"))
+	tk.Code = ""
+	assert.False(t, tk.Verify(""))
+}
@@ -2,6 +2,7 @@ package token
 
 import (
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jrapoport/gothic/store"
@@ -10,21 +11,70 @@ import (
 
 func init() {
 	store.AddAutoMigrationWithIndexes("4000-refresh_tokens",
-		RefreshToken{}, AccessTokenIndexes)
+		RefreshToken{}, RefreshTokenIndexes)
+	// adds device sessions
+	store.AddAutoMigrationWithIndexes("4001-refresh_tokens-sessions",
+		RefreshToken{}, RefreshTokenIndexes)
+	// adds the active organization of sessions
+	store.AddAutoMigrationWithIndexes("4002-refresh_tokens-orgs",
+		RefreshToken{}, RefreshTokenIndexes)
+	// adds tenants
+	store.AddAutoMigrationWithIndexes("4003-refresh_tokens-tenants",
+		RefreshToken{}, RefreshTokenIndexes)
+}
+
+// MaxUserAgent is the max length of a session user agent.
+const MaxUserAgent = 255
+
+// Session holds the device session for a refresh token. The session
+// is carried over to the new refresh token when the token is swapped.
+type Session struct {
+	SessionID  uuid.UUID `json:"session_id" gorm:"index:idx_session_id;type:char(36)"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	IPAddress  string    `json:"ip_address,omitempty"`
+	DeviceName string    `json:"device_name,omitempty"`
+	SignedInAt time.Time `json:"signed_in_at"`
+	// OrgID is the active organization of the session.
+	OrgID *uuid.UUID `json:"org_id,omitempty" gorm:"type:char(36)"`
+}
+
+// RefreshTokenIndexes are the db indexes for the refresh token in the db.
+var RefreshTokenIndexes = append([]string{
+	"idx_session_id",
+}, AccessTokenIndexes...)
+
+// NewSession returns a new session for a device.
+func NewSession(userAgent, ipAddress, deviceName string) Session {
+	return Session{
+		UserAgent:  truncate(userAgent, MaxUserAgent),
+		IPAddress:  ipAddress,
+		DeviceName: truncate(deviceName, MaxUserAgent),
+	}
 }
 
 // RefreshToken holds a refresh token.
 type RefreshToken struct {
 	AccessToken
+	Session
 }
 
 var _ Token = (*RefreshToken)(nil)
 
-// NewRefreshToken generates a new token. The type is inferred from uses.
+// NewRefreshToken generates a new token for a new session.
 func NewRefreshToken(userID uuid.UUID) *RefreshToken {
+	return NewSessionRefreshToken(userID, Session{})
+}
+
+// NewSessionRefreshToken generates a new token for the session. If the
+// session does not have a session id a new session is started.
+func NewSessionRefreshToken(userID uuid.UUID, s Session) *RefreshToken {
 	at := *NewAccessToken(utils.SecureToken(), InfiniteUse, NoExpiration)
 	at.UserID = userID
-	return &RefreshToken{at}
+	if s.SessionID == uuid.Nil {
+		s.SessionID = uuid.New()
+		s.SignedInAt = time.Now().UTC()
+	}
+	return &RefreshToken{at, s}
 }
 
 // Class returns the class of the refresh token.
@@ -40,6 +90,20 @@ func (rt RefreshToken) Usable() bool {
 	return rt.AccessToken.Usable()
 }
 
+// Swapped returns true if the token was swapped for a new token.
+// Swapped tokens are soft deleted, while revoked tokens are deleted.
+func (rt RefreshToken) Swapped() bool {
+	return rt.DeletedAt.Valid
+}
+
+// LastActive returns the last time the session was used.
+func (rt RefreshToken) LastActive() time.Time {
+	if rt.UsedAt != nil {
+		return *rt.UsedAt
+	}
+	return rt.CreatedAt
+}
+
 // HasToken returns true if the refresh token is found.
 func (rt RefreshToken) HasToken(tx *store.Connection) (bool, error) {
 	if rt.Token == "" {
@@ -47,3 +111,11 @@ func (rt RefreshToken) HasToken(tx *store.Connection) (bool, error) {
 	}
 	return tx.Has(&rt, "token = ?", rt.Token)
 }
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
@@ -1,7 +1,9 @@
 package token
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jrapoport/gothic/test/tconn"
@@ -49,3 +51,43 @@ func TestRefreshToken_HasToken(t *testing.T) {
 		test.Has(t, has)
 	}
 }
+
+func TestNewSession(t *testing.T) {
+	t.Parallel()
+	s := NewSession("agent", "127.0.0.1", "laptop")
+	assert.Equal(t, uuid.Nil, s.SessionID)
+	assert.Equal(t, "agent", s.UserAgent)
+	assert.Equal(t, "127.0.0.1", s.IPAddress)
+	assert.Equal(t, "laptop", s.DeviceName)
+	long := strings.Repeat("a", MaxUserAgent+1)
+	s = NewSession(long, "", long)
+	assert.Len(t, s.UserAgent, MaxUserAgent)
+	assert.Len(t, s.DeviceName, MaxUserAgent)
+	// new session
+	tk := NewSessionRefreshToken(uuid.New(), s)
+	assert.NotEqual(t, uuid.Nil, tk.SessionID)
+	assert.False(t, tk.SignedInAt.IsZero())
+	assert.Equal(t, s.UserAgent, tk.UserAgent)
+	// existing session
+	tk2 := NewSessionRefreshToken(tk.UserID, tk.Session)
+	assert.Equal(t, tk.SessionID, tk2.SessionID)
+	assert.Equal(t, tk.SignedInAt, tk2.SignedInAt)
+	assert.NotEqual(t, tk.Token, tk2.Token)
+}
+
+func TestRefreshToken_LastActive(t *testing.T) {
+	t.Parallel()
+	tk := NewRefreshToken(uuid.New())
+	tk.CreatedAt = time.Now().UTC()
+	assert.Equal(t, tk.CreatedAt, tk.LastActive())
+	tk.Use()
+	assert.Equal(t, *tk.UsedAt, tk.LastActive())
+}
+
+func TestRefreshToken_Swapped(t *testing.T) {
+	t.Parallel()
+	rt := NewRefreshToken(uuid.New())
+	assert.False(t, rt.Swapped())
+	rt.DeletedAt.Valid = true
+	assert.True(t, rt.Swapped())
+}
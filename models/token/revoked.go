@@ -0,0 +1,111 @@
+package token
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/store"
+	"gorm.io/gorm"
+)
+
+func init() {
+	store.AddAutoMigrationWithIndexes("4100-revoked_tokens",
+		RevokedToken{}, RevokedTokenIndexes)
+	store.AddAutoMigrationWithIndexes("4101-revoked_tokens-sessions",
+		RevokedToken{}, RevokedTokenIndexes)
+}
+
+// RevokedToken is a revoked jwt bearer token. If the token id is empty,
+// every bearer token issued to the user before RevokedAt is revoked. If
+// the session id is also set, only the tokens of the session are revoked.
+type RevokedToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"<-:create;index:idx_user_id;type:char(36)"`
+	SessionID uuid.UUID  `json:"session_id,omitempty" gorm:"<-:create;index:idx_session_id;type:char(36)"`
+	TokenID   string     `json:"token_id,omitempty" gorm:"<-:create;index:idx_token_id"`
+	RevokedAt time.Time  `json:"revoked_at"`
+	ExpiredAt *time.Time `json:"expired_at,omitempty" gorm:"index:idx_expired_at"`
+}
+
+// RevokedTokenIndexes are the db indexes for the revoked token in the db.
+var RevokedTokenIndexes = []string{
+	"idx_user_id",
+	"idx_session_id",
+	"idx_token_id",
+	"idx_expired_at",
+}
+
+// NewRevokedToken returns a revoked bearer token for the token id. Once the
+// token has expired it no longer needs to be revoked, so the revocation
+// expires with it. If exp is NoExpiration the revocation never expires.
+func NewRevokedToken(userID uuid.UUID, tokenID string, exp time.Duration) *RevokedToken {
+	now := time.Now().UTC()
+	return &RevokedToken{
+		UserID:    userID,
+		TokenID:   tokenID,
+		RevokedAt: now,
+		ExpiredAt: revokedUntil(now, exp),
+	}
+}
+
+// NewRevokedBefore returns a revocation for all the bearer tokens issued to
+// the user before a timestamp. The revocation expires once the last of those
+// tokens has expired. If exp is NoExpiration the revocation never expires.
+func NewRevokedBefore(userID uuid.UUID, before time.Time, exp time.Duration) *RevokedToken {
+	before = before.UTC().Truncate(time.Microsecond)
+	return &RevokedToken{
+		UserID:    userID,
+		RevokedAt: before,
+		ExpiredAt: revokedUntil(before, exp),
+	}
+}
+
+// NewRevokedSession returns a revocation for all the bearer tokens issued to
+// the user for the session before a timestamp. The revocation expires once the
+// last of those tokens has expired. If exp is NoExpiration it never expires.
+func NewRevokedSession(userID, sessionID uuid.UUID, before time.Time, exp time.Duration) *RevokedToken {
+	rt := NewRevokedBefore(userID, before, exp)
+	rt.SessionID = sessionID
+	return rt
+}
+
+func revokedUntil(t time.Time, exp time.Duration) *time.Time {
+	if exp <= NoExpiration {
+		return nil
+	}
+	until := t.Add(exp)
+	return &until
+}
+
+// BeforeSave runs before create or update.
+func (rt *RevokedToken) BeforeSave(*gorm.DB) error {
+	if rt.UserID == uuid.Nil || rt.UserID == user.SuperAdminID {
+		return errors.New("invalid user id")
+	}
+	if rt.RevokedAt.IsZero() {
+		return errors.New("invalid revoked at")
+	}
+	return nil
+}
+
+// Expired returns true if the revocation has expired.
+func (rt RevokedToken) Expired() bool {
+	return rt.ExpiredAt != nil && rt.ExpiredAt.Before(time.Now().UTC())
+}
+
+// Revokes returns true if a bearer token issued to the user for the
+// session with the token id at the issued time is revoked.
+func (rt RevokedToken) Revokes(userID, sessionID uuid.UUID, tokenID string, issued time.Time) bool {
+	if rt.UserID != userID || rt.Expired() {
+		return false
+	}
+	if rt.TokenID != "" {
+		return rt.TokenID == tokenID
+	}
+	if rt.SessionID != uuid.Nil && rt.SessionID != sessionID {
+		return false
+	}
+	return issued.Before(rt.RevokedAt)
+}
@@ -0,0 +1,105 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRevokedToken(t *testing.T) {
+	t.Parallel()
+	uid := uuid.New()
+	jti := uuid.New().String()
+	rt := NewRevokedToken(uid, jti, time.Hour)
+	assert.Equal(t, uid, rt.UserID)
+	assert.Equal(t, jti, rt.TokenID)
+	require.NotNil(t, rt.ExpiredAt)
+	assert.Equal(t, rt.RevokedAt.Add(time.Hour), *rt.ExpiredAt)
+	assert.False(t, rt.Expired())
+	rt = NewRevokedToken(uid, jti, NoExpiration)
+	assert.Nil(t, rt.ExpiredAt)
+	assert.False(t, rt.Expired())
+	rt = NewRevokedToken(uid, jti, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	assert.True(t, rt.Expired())
+}
+
+func TestNewRevokedBefore(t *testing.T) {
+	t.Parallel()
+	uid := uuid.New()
+	before := time.Now().Add(-time.Minute)
+	rt := NewRevokedBefore(uid, before, time.Hour)
+	assert.Equal(t, uid, rt.UserID)
+	assert.Empty(t, rt.TokenID)
+	assert.Equal(t, before.UTC().Truncate(time.Microsecond), rt.RevokedAt)
+	require.NotNil(t, rt.ExpiredAt)
+	assert.Equal(t, rt.RevokedAt.Add(time.Hour), *rt.ExpiredAt)
+	rt = NewRevokedBefore(uid, before, NoExpiration)
+	assert.Nil(t, rt.ExpiredAt)
+}
+
+func TestNewRevokedSession(t *testing.T) {
+	t.Parallel()
+	uid := uuid.New()
+	sid := uuid.New()
+	before := time.Now().Add(-time.Minute)
+	rt := NewRevokedSession(uid, sid, before, time.Hour)
+	assert.Equal(t, uid, rt.UserID)
+	assert.Equal(t, sid, rt.SessionID)
+	assert.Empty(t, rt.TokenID)
+	assert.Equal(t, before.UTC().Truncate(time.Microsecond), rt.RevokedAt)
+	require.NotNil(t, rt.ExpiredAt)
+	assert.Equal(t, rt.RevokedAt.Add(time.Hour), *rt.ExpiredAt)
+}
+
+func TestRevokedToken_Revokes(t *testing.T) {
+	t.Parallel()
+	uid := uuid.New()
+	jti := uuid.New().String()
+	now := time.Now().UTC()
+	sid := uuid.New()
+	rt := NewRevokedToken(uid, jti, time.Hour)
+	assert.True(t, rt.Revokes(uid, sid, jti, now))
+	assert.False(t, rt.Revokes(uuid.New(), sid, jti, now))
+	assert.False(t, rt.Revokes(uid, sid, uuid.New().String(), now))
+	rt = NewRevokedBefore(uid, now, time.Hour)
+	assert.True(t, rt.Revokes(uid, sid, jti, now.Add(-time.Second)))
+	assert.True(t, rt.Revokes(uid, uuid.Nil, "", now.Add(-time.Second)))
+	assert.False(t, rt.Revokes(uid, sid, jti, now.Add(time.Second)))
+	assert.False(t, rt.Revokes(uuid.New(), sid, jti, now.Add(-time.Second)))
+	// session
+	rt = NewRevokedSession(uid, sid, now, time.Hour)
+	assert.True(t, rt.Revokes(uid, sid, jti, now.Add(-time.Second)))
+	assert.False(t, rt.Revokes(uid, uuid.New(), jti, now.Add(-time.Second)))
+	assert.False(t, rt.Revokes(uid, uuid.Nil, jti, now.Add(-time.Second)))
+	assert.False(t, rt.Revokes(uid, sid, jti, now.Add(time.Second)))
+	// expired
+	rt = NewRevokedBefore(uid, now.Add(-time.Hour), time.Minute)
+	assert.False(t, rt.Revokes(uid, sid, jti, now.Add(-2*time.Hour)))
+}
+
+func TestRevokedToken_BeforeSave(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	uid := uuid.New()
+	tests := []struct {
+		rt  *RevokedToken
+		Err assert.ErrorAssertionFunc
+	}{
+		{NewRevokedToken(uuid.Nil, "", time.Hour), assert.Error},
+		{NewRevokedToken(user.SuperAdminID, "", time.Hour), assert.Error},
+		{&RevokedToken{UserID: uid}, assert.Error},
+		{NewRevokedToken(uid, uuid.New().String(), time.Hour), assert.NoError},
+		{NewRevokedBefore(uid, time.Now(), NoExpiration), assert.NoError},
+		{NewRevokedSession(uid, uuid.New(), time.Now(), time.Hour), assert.NoError},
+	}
+	for _, test := range tests {
+		err := conn.Create(test.rt).Error
+		test.Err(t, err)
+	}
+}
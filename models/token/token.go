@@ -67,4 +67,24 @@ const (
 	Refresh Class = "refresh"
 	// Auth is an authorization token.
 	Auth Class = "auth"
+	// MFA is a multi-factor authentication challenge token.
+	MFA Class = "mfa"
+	// WebAuthn is a webauthn ceremony token.
+	WebAuthn Class = "webauthn"
+	// PasswordChange is a password change only token.
+	PasswordChange Class = "password_change"
+	// MagicLink is a passwordless email login token.
+	MagicLink Class = "magic_link"
+	// Phone is a phone confirmation (sms) token.
+	Phone Class = "phone"
+	// Unlock is a locked user unlock token.
+	Unlock Class = "unlock"
+	// Authorization is an OAuth 2.0 authorization code.
+	Authorization Class = "authorization"
+	// OAuth is an access token issued to an OAuth 2.0 client.
+	OAuth Class = "oauth"
+	// Personal is a personal access token issued by a user.
+	Personal Class = "personal"
+	// OrgInvite is an invitation to join an organization.
+	OrgInvite Class = "org_invite"
 )
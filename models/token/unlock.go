@@ -0,0 +1,54 @@
+package token
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/store"
+	"github.com/jrapoport/gothic/utils"
+)
+
+func init() {
+	store.AddAutoMigrationWithIndexes("3000-unlock_tokens",
+		UnlockToken{}, AccessTokenIndexes)
+	// adds tenants
+	store.AddAutoMigrationWithIndexes("3001-unlock_tokens-tenants",
+		UnlockToken{}, AccessTokenIndexes)
+}
+
+// UnlockToken holds a user unlock token.
+type UnlockToken struct {
+	AccessToken
+	SentAt *time.Time `json:"sent_at"`
+}
+
+var _ Token = (*UnlockToken)(nil)
+
+// NewUnlockToken generates a new single use unlock token.
+func NewUnlockToken(userID uuid.UUID, exp time.Duration) *UnlockToken {
+	at := *NewAccessToken(utils.SecureToken(), SingleUse, exp)
+	at.UserID = userID
+	return &UnlockToken{AccessToken: at}
+}
+
+// Class returns the class of the unlock token.
+func (ut UnlockToken) Class() Class {
+	return Unlock
+}
+
+// Usable returns true if the token is usable.
+func (ut UnlockToken) Usable() bool {
+	if ut.CreatedAt.IsZero() {
+		return false
+	}
+	return ut.AccessToken.Usable()
+}
+
+// HasToken returns true if the unlock token is found.
+func (ut UnlockToken) HasToken(tx *store.Connection) (bool, error) {
+	if ut.Token == "" {
+		return false, errors.New("invalid token")
+	}
+	return tx.Has(&ut, "token = ?", ut.Token)
+}
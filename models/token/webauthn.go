@@ -0,0 +1,57 @@
+package token
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/store"
+	"github.com/jrapoport/gothic/utils"
+)
+
+func init() {
+	store.AddAutoMigrationWithIndexes("3000-webauthn_tokens",
+		WebAuthnToken{}, AccessTokenIndexes)
+	// adds tenants
+	store.AddAutoMigrationWithIndexes("3001-webauthn_tokens-tenants",
+		WebAuthnToken{}, AccessTokenIndexes)
+}
+
+// WebAuthnToken holds the session for a webauthn ceremony. Tokens
+// for discoverable logins are issued to the system user.
+type WebAuthnToken struct {
+	AccessToken
+	Session []byte `json:"-"`
+}
+
+var _ Token = (*WebAuthnToken)(nil)
+
+// NewWebAuthnToken generates a new single use webauthn ceremony token.
+func NewWebAuthnToken(userID uuid.UUID, session []byte, exp time.Duration) *WebAuthnToken {
+	at := *NewAccessToken(utils.SecureToken(), SingleUse, exp)
+	if userID != uuid.Nil {
+		at.UserID = userID
+	}
+	return &WebAuthnToken{AccessToken: at, Session: session}
+}
+
+// Class returns the class of the webauthn token.
+func (wt WebAuthnToken) Class() Class {
+	return WebAuthn
+}
+
+// Usable returns true if the token is usable.
+func (wt WebAuthnToken) Usable() bool {
+	if wt.CreatedAt.IsZero() {
+		return false
+	}
+	return wt.AccessToken.Usable()
+}
+
+// HasToken returns true if the webauthn token is found.
+func (wt WebAuthnToken) HasToken(tx *store.Connection) (bool, error) {
+	if wt.Token == "" {
+		return false, errors.New("invalid token")
+	}
+	return tx.Has(&wt, "token = ?", wt.Token)
+}
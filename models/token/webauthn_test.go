@@ -0,0 +1,66 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebAuthnToken_Kind(t *testing.T) {
+	t.Parallel()
+	assert.NotPanics(t, func() {
+		tk := NewWebAuthnToken(uuid.New(), nil, 0)
+		cls := tk.Class()
+		assert.Equal(t, WebAuthn, cls)
+	})
+}
+
+func TestNewWebAuthnToken(t *testing.T) {
+	t.Parallel()
+	uid := uuid.New()
+	session := []byte("session")
+	tk := NewWebAuthnToken(uid, session, time.Minute)
+	assert.Equal(t, uid, tk.UserID)
+	assert.Equal(t, session, tk.Session)
+	assert.Equal(t, Timed, tk.Type)
+	assert.Equal(t, SingleUse, tk.MaxUses)
+	tk = NewWebAuthnToken(uuid.Nil, session, time.Minute)
+	assert.Equal(t, user.SystemID, tk.UserID)
+}
+
+func TestWebAuthnToken_HasToken(t *testing.T) {
+	t.Parallel()
+	conn, _ := tconn.TempConn(t)
+	createToken := func() *WebAuthnToken {
+		tk := NewWebAuthnToken(uuid.New(), []byte("session"), 0)
+		assert.False(t, tk.Usable())
+		err := conn.Create(tk).Error
+		require.NoError(t, err)
+		assert.True(t, tk.Usable())
+		return tk
+	}
+	deletedToken := createToken()
+	err := conn.Delete(deletedToken).Error
+	require.NoError(t, err)
+	tests := []struct {
+		ct  *WebAuthnToken
+		Err assert.ErrorAssertionFunc
+		Has assert.BoolAssertionFunc
+	}{
+		{&WebAuthnToken{}, assert.Error, assert.False},
+		{NewWebAuthnToken(uuid.New(), nil, 0), assert.NoError, assert.False},
+		{createToken(), assert.NoError, assert.True},
+		{deletedToken, assert.NoError, assert.False},
+	}
+	var has bool
+	for _, test := range tests {
+		has, err = test.ct.HasToken(conn)
+		test.Err(t, err)
+		test.Has(t, has)
+	}
+}
@@ -5,55 +5,78 @@ type Key string
 
 // Defined keys
 const (
-	Unknown        Key = ""
-	AccountID          = "account_id"
-	Action             = "action"
-	AdminID            = "admin_id"
-	AvatarURL          = "avatar_url"
-	Class              = "class"
-	Code               = "code"
-	Color              = "color"
-	ConfirmedAt        = "confirmed_at"
-	Count              = "count"
-	Data               = "data"
-	Description        = "description"
-	Email              = "email"
-	Event              = "event"
-	ExpirationDate     = "expiration_date"
-	Fields             = "fields"
-	Filters            = "filters"
-	FirstName          = "first_name"
-	Hard               = "hard"
-	Hostname           = "hostname"
-	ID                 = "id"
-	IPAddress          = "ip_address"
-	Issued             = "issued"
-	JWT                = "jwt"
-	LastName           = "last_name"
-	LastUsed           = "last_used"
-	Metadata           = "metadata"
-	Name               = "name"
-	Nickname           = "nickname"
-	Page               = "page"
-	PageSize           = "page_size"
-	Password           = "password"
-	PerPage            = "per_page"
-	Provider           = "provider"
-	ReCaptcha          = "recaptcha"
-	Revoked            = "revoked"
-	Role               = "role"
-	Service            = "service"
-	Session            = "session"
-	Sort               = "sort"
-	State              = "state"
-	Status             = "status"
-	Timestamp          = "timestamp"
-	Token              = "token"
-	Type               = "type"
-	UserID             = "user_id"
-	Username           = "username"
-	Uses               = "uses"
-	Valid              = "valid"
+	Unknown            Key = ""
+	AccountID              = "account_id"
+	Action                 = "action"
+	AdminID                = "admin_id"
+	Algorithm              = "algorithm"
+	AvatarURL              = "avatar_url"
+	ChallengeMethod        = "code_challenge_method"
+	Class                  = "class"
+	ClientID               = "client_id"
+	Code                   = "code"
+	CodeChallenge          = "code_challenge"
+	CodeVerifier           = "code_verifier"
+	Color                  = "color"
+	ConfirmedAt            = "confirmed_at"
+	Count                  = "count"
+	Data                   = "data"
+	Description            = "description"
+	DeviceName             = "device_name"
+	Email                  = "email"
+	Event                  = "event"
+	ExpirationDate         = "expiration_date"
+	Fields                 = "fields"
+	Filters                = "filters"
+	FirstName              = "first_name"
+	Hard                   = "hard"
+	Hostname               = "hostname"
+	ID                     = "id"
+	IPAddress              = "ip_address"
+	Issued                 = "issued"
+	JWT                    = "jwt"
+	LastName               = "last_name"
+	LastUsed               = "last_used"
+	LockedUntil            = "locked_until"
+	Metadata               = "metadata"
+	Method                 = "method"
+	MustChangePassword     = "must_change_password"
+	Name                   = "name"
+	Nickname               = "nickname"
+	Nonce                  = "nonce"
+	OrgID                  = "org_id"
+	Page                   = "page"
+	PageSize               = "page_size"
+	Password               = "password"
+	PasswordChangedAt      = "password_changed_at"
+	PasswordRemindedAt     = "password_reminded_at"
+	Path                   = "path"
+	PerPage                = "per_page"
+	Permissions            = "permissions"
+	Phone                  = "phone"
+	Provider               = "provider"
+	ReCaptcha              = "recaptcha"
+	RedirectURI            = "redirect_uri"
+	Revoked                = "revoked"
+	Role                   = "role"
+	Scope                  = "scope"
+	Service                = "service"
+	Session                = "session"
+	SessionID              = "session_id"
+	Sort                   = "sort"
+	State                  = "state"
+	Status                 = "status"
+	TenantID               = "tenant_id"
+	Timestamp              = "timestamp"
+	Token                  = "token"
+	TokenID                = "token_id"
+	Type                   = "type"
+	UserAgent              = "user_agent"
+	UserCode               = "user_code"
+	UserID                 = "user_id"
+	Username               = "username"
+	Uses                   = "uses"
+	Valid                  = "valid"
 )
 
 var Reserved = map[string]struct{}{
@@ -2,7 +2,7 @@ package user
 
 import (
 	"github.com/google/uuid"
-	"github.com/jrapoport/gothic/utils"
+	"github.com/jrapoport/gothic/hasher"
 )
 
 // SuperAdminID is the user id for the super admin account.
@@ -10,10 +10,13 @@ var SuperAdminID = uuid.MustParse("11111111-1111-1111-1111-111111111111")
 
 // NewSuperAdmin returns a new super admin user.
 func NewSuperAdmin(password string) *User {
+	// an error here leaves the password empty,
+	// so the super admin will fail to authenticate.
+	hash, _ := hasher.Hash(password)
 	return &User{
 		ID:       SuperAdminID,
 		Role:     RoleSuper,
 		Status:   Verified,
-		Password: utils.HashPassword(password),
+		Password: hash,
 	}
 }
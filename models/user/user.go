@@ -6,20 +6,37 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/hasher"
 	"github.com/jrapoport/gothic/models/account"
 	"github.com/jrapoport/gothic/models/types"
 	"github.com/jrapoport/gothic/models/types/provider"
 	"github.com/jrapoport/gothic/store"
-	"github.com/jrapoport/gothic/utils"
 	"gorm.io/gorm"
 )
 
 func init() {
 	var userIndexes = []string{
-		"idx_email",
+		"idx_tenant_email",
+		"idx_tenant_phone",
 	}
 	store.AddAutoMigrationWithIndexes("5000-users",
 		User{}, userIndexes)
+	// widens the password column for PHC formatted hashes
+	store.AddAutoMigrationWithIndexes("5001-users-password",
+		User{}, userIndexes)
+	// adds password expiry and forced password changes
+	store.AddAutoMigrationWithIndexes("5002-users-password-expiry",
+		User{}, userIndexes)
+	// adds tenants, emails & phone numbers are unique per tenant
+	store.AddAutoMigrationReplacingIndexes("5003-users-tenants",
+		User{}, userIndexes, []string{"idx_email", "idx_phone"})
+	// existing passwords expire max age after the upgrade
+	store.AddAutoMigrationWithUpdate("5004-users-password-changed",
+		User{}, userIndexes, func(tx *gorm.DB) error {
+			return tx.Model(&User{}).
+				Where("password_changed_at IS NULL").
+				UpdateColumn("password_changed_at", time.Now().UTC()).Error
+		})
 }
 
 // Status is the user status
@@ -38,24 +55,31 @@ const (
 // User represents a registered user with email/password authentication
 // TODO: support additional verification (beyond email confirmation) via VerifiedAt
 type User struct {
-	ID          uuid.UUID         `json:"id" gorm:"primaryKey;type:char(36)"`
-	Provider    provider.Name     `json:"provider" gorm:"type:varchar(255)"`
-	Role        Role              `json:"role"`
-	Status      Status            `json:"status"`
-	Email       string            `json:"email" gorm:"uniqueIndex;type:varchar(320)"`
-	Username    string            `json:"username" gorm:"type:varchar(255)"`
-	Password    []byte            `json:"-" gorm:"type:varchar(60)"`
-	Data        types.Map         `json:"data"`
-	Metadata    types.Map         `json:"metadata"`
-	SignupCode  *uint             `json:"signup_code"`
-	Linked      []account.Account `json:"linked"`
-	CreatedAt   time.Time         `json:"created_at"`
-	UpdatedAt   time.Time         `json:"updated_at"`
-	LoginAt     *time.Time        `json:"login_at,omitempty"`
-	ConfirmedAt *time.Time        `json:"confirmed_at,omitempty"`
-	VerifiedAt  *time.Time        `json:"verified_at,omitempty"`
-	InvitedAt   *time.Time        `json:"invited_at,omitempty"`
-	DeletedAt   gorm.DeletedAt    `json:"deleted_at"`
+	ID                 uuid.UUID         `json:"id" gorm:"primaryKey;type:char(36)"`
+	TenantID           uuid.UUID         `json:"-" gorm:"uniqueIndex:idx_tenant_email,priority:1;uniqueIndex:idx_tenant_phone,priority:1;type:char(36);default:'00000000-0000-0000-0000-000000000000'"`
+	Provider           provider.Name     `json:"provider" gorm:"type:varchar(255)"`
+	Role               Role              `json:"role"`
+	Status             Status            `json:"status"`
+	Email              string            `json:"email" gorm:"uniqueIndex:idx_tenant_email,priority:2;type:varchar(320)"`
+	Phone              *string           `json:"phone,omitempty" gorm:"uniqueIndex:idx_tenant_phone,priority:2;type:varchar(16)"`
+	Username           string            `json:"username" gorm:"type:varchar(255)"`
+	Password           []byte            `json:"-" gorm:"type:varchar(255)"`
+	MustChangePassword bool              `json:"must_change_password"`
+	Data               types.Map         `json:"data"`
+	Metadata           types.Map         `json:"metadata"`
+	SignupCode         *uint             `json:"signup_code"`
+	Linked             []account.Account `json:"linked"`
+	CreatedAt          time.Time         `json:"created_at"`
+	UpdatedAt          time.Time         `json:"updated_at"`
+	LoginAt            *time.Time        `json:"login_at,omitempty"`
+	ConfirmedAt        *time.Time        `json:"confirmed_at,omitempty"`
+	PhoneConfirmedAt   *time.Time        `json:"phone_confirmed_at,omitempty"`
+	VerifiedAt         *time.Time        `json:"verified_at,omitempty"`
+	InvitedAt          *time.Time        `json:"invited_at,omitempty"`
+	LockedUntil        *time.Time        `json:"locked_until,omitempty"`
+	PasswordChangedAt  *time.Time        `json:"password_changed_at,omitempty"`
+	PasswordRemindedAt *time.Time        `json:"-"`
+	DeletedAt          gorm.DeletedAt    `json:"deleted_at"`
 }
 
 // NewUser initializes a new user from an email, password and user data.
@@ -75,16 +99,18 @@ func NewUser(p provider.Name, role Role, email, username string, password []byte
 		}
 		break
 	}
+	now := time.Now().UTC()
 	u := &User{
-		ID:       uid,
-		Provider: p,
-		Role:     role,
-		Status:   Restricted,
-		Email:    email,
-		Username: username,
-		Password: password,
-		Data:     data,
-		Metadata: meta,
+		ID:                uid,
+		Provider:          p,
+		Role:              role,
+		Status:            Restricted,
+		Email:             email,
+		Username:          username,
+		Password:          password,
+		PasswordChangedAt: &now,
+		Data:              data,
+		Metadata:          meta,
 	}
 	return u
 }
@@ -121,7 +147,13 @@ func (u User) Authenticate(pw string) error {
 	if u.IsLocked() {
 		return errors.New("invalid user")
 	}
-	return utils.CheckPassword(u.Password, pw)
+	return hasher.Verify(u.Password, pw)
+}
+
+// NeedsRehash returns true if the password hash does
+// not match the current password hashing policy.
+func (u User) NeedsRehash() bool {
+	return hasher.NeedsRehash(u.Password)
 }
 
 // IsSystemUser returns true if a user is a system account.
@@ -155,6 +187,39 @@ func (u User) IsLocked() bool {
 	return u.IsBanned() || u.Status <= Locked
 }
 
+// LockExpired returns true if the user was temporarily locked and the lock has expired.
+func (u User) LockExpired() bool {
+	if u.IsBanned() || u.Status != Locked || u.LockedUntil == nil {
+		return false
+	}
+	return !time.Now().UTC().Before(*u.LockedUntil)
+}
+
+// PasswordExpiresAt returns the time the password expires after maxAge. If
+// the user does not have a password changed timestamp it returns zero.
+func (u User) PasswordExpiresAt(maxAge time.Duration) time.Time {
+	if u.PasswordChangedAt == nil {
+		return time.Time{}
+	}
+	return u.PasswordChangedAt.Add(maxAge).UTC()
+}
+
+// PasswordExpired returns true if the password is older than maxAge. If
+// maxAge is zero, or the user does not have a password changed timestamp,
+// the password does not expire.
+func (u User) PasswordExpired(maxAge time.Duration) bool {
+	if maxAge <= 0 || u.PasswordChangedAt == nil {
+		return false
+	}
+	return !time.Now().UTC().Before(u.PasswordExpiresAt(maxAge))
+}
+
+// NeedsPasswordChange returns true if the user must change their
+// password, or their password is older than maxAge.
+func (u User) NeedsPasswordChange(maxAge time.Duration) bool {
+	return u.MustChangePassword || u.PasswordExpired(maxAge)
+}
+
 // IsRestricted returns true if the user is not confirmed.
 func (u User) IsRestricted() bool {
 	return !u.IsLocked() && (u.Status <= Restricted || u.ConfirmedAt == nil)
@@ -170,6 +235,11 @@ func (u User) IsActive() bool {
 	return u.IsConfirmed() && u.Status >= Active
 }
 
+// HasPhone returns true if the user has a confirmed phone number.
+func (u User) HasPhone() bool {
+	return u.Phone != nil && u.PhoneConfirmedAt != nil
+}
+
 // IsVerified returns true  if a user has been verified.
 func (u User) IsVerified() bool {
 	return u.IsConfirmed() && u.VerifiedAt != nil && u.Status >= Verified
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/hasher"
 	"github.com/jrapoport/gothic/models/types/provider"
 	"github.com/jrapoport/gothic/test/tconn"
 	"github.com/jrapoport/gothic/test/tutils"
@@ -37,6 +38,15 @@ func TestNewUser(t *testing.T) {
 	u = NewUser(p, RoleUser, email, "", []byte(""), nil, nil)
 	err = conn.Create(u).Error
 	assert.Error(t, err)
+	// emails are unique per tenant
+	tid := uuid.New()
+	u = NewUser(p, RoleUser, email, "", []byte(""), nil, nil)
+	err = conn.Tenant(tid).Create(u).Error
+	assert.NoError(t, err)
+	assert.Equal(t, tid, u.TenantID)
+	u = NewUser(p, RoleUser, email, "", []byte(""), nil, nil)
+	err = conn.Tenant(tid).Create(u).Error
+	assert.Error(t, err)
 }
 
 func TestUser_BeforeSave(t *testing.T) {
@@ -79,14 +89,89 @@ func TestUser_EmailAddress(t *testing.T) {
 
 }
 
+func TestUser_HasPhone(t *testing.T) {
+	t.Parallel()
+	conn, c := tconn.TempConn(t)
+	u := NewUser(c.Provider(), RoleUser, tutils.RandomEmail(), "", []byte{}, nil, nil)
+	assert.False(t, u.HasPhone())
+	phone := "+15555550100"
+	u.Phone = &phone
+	assert.False(t, u.HasPhone())
+	now := time.Now().UTC()
+	u.PhoneConfirmedAt = &now
+	assert.True(t, u.HasPhone())
+	err := conn.Save(u).Error
+	require.NoError(t, err)
+	// phone numbers are unique
+	u2 := NewUser(c.Provider(), RoleUser, tutils.RandomEmail(), "", []byte{}, nil, nil)
+	u2.Phone = &phone
+	err = conn.Save(u2).Error
+	assert.Error(t, err)
+	// but may be empty
+	u2.Phone = nil
+	err = conn.Save(u2).Error
+	assert.NoError(t, err)
+	u3 := NewUser(c.Provider(), RoleUser, tutils.RandomEmail(), "", []byte{}, nil, nil)
+	err = conn.Save(u3).Error
+	assert.NoError(t, err)
+}
+
+func TestUser_LockExpired(t *testing.T) {
+	t.Parallel()
+	conn, c := tconn.TempConn(t)
+	u := NewUser(c.Provider(), RoleUser, tutils.RandomEmail(), "", []byte{}, nil, nil)
+	err := conn.Save(u).Error
+	require.NoError(t, err)
+	assert.False(t, u.LockExpired())
+	u.Status = Locked
+	assert.False(t, u.LockExpired())
+	future := time.Now().UTC().Add(time.Hour)
+	u.LockedUntil = &future
+	assert.False(t, u.LockExpired())
+	past := time.Now().UTC().Add(-time.Second)
+	u.LockedUntil = &past
+	assert.True(t, u.LockExpired())
+	u.Status = Banned
+	assert.False(t, u.LockExpired())
+}
+
+func TestUser_NeedsPasswordChange(t *testing.T) {
+	t.Parallel()
+	conn, c := tconn.TempConn(t)
+	u := NewUser(c.Provider(), RoleUser, tutils.RandomEmail(), "", []byte{}, nil, nil)
+	err := conn.Save(u).Error
+	require.NoError(t, err)
+	const maxAge = time.Hour
+	require.NotNil(t, u.PasswordChangedAt)
+	assert.Equal(t, u.PasswordChangedAt.Add(maxAge).UTC(), u.PasswordExpiresAt(maxAge))
+	assert.False(t, u.PasswordExpired(0))
+	assert.False(t, u.PasswordExpired(maxAge))
+	assert.False(t, u.NeedsPasswordChange(maxAge))
+	past := time.Now().UTC().Add(-2 * maxAge)
+	u.PasswordChangedAt = &past
+	assert.Equal(t, past.Add(maxAge), u.PasswordExpiresAt(maxAge))
+	assert.False(t, u.PasswordExpired(0))
+	assert.True(t, u.PasswordExpired(maxAge))
+	assert.True(t, u.NeedsPasswordChange(maxAge))
+	assert.False(t, u.NeedsPasswordChange(0))
+	u.MustChangePassword = true
+	assert.True(t, u.NeedsPasswordChange(0))
+	// never changed
+	u.MustChangePassword = false
+	u.PasswordChangedAt = nil
+	assert.True(t, u.PasswordExpiresAt(maxAge).IsZero())
+	assert.False(t, u.PasswordExpired(maxAge))
+}
+
 func TestUser_Authenticate(t *testing.T) {
 	t.Parallel()
 	const testPass = "password"
 	conn, c := tconn.TempConn(t)
 	email := tutils.RandomEmail()
-	hash := utils.HashPassword(testPass)
+	hash, err := hasher.Hash(testPass)
+	require.NoError(t, err)
 	u := NewUser(c.Provider(), RoleUser, email, "", hash, nil, nil)
-	err := u.Authenticate(testPass)
+	err = u.Authenticate(testPass)
 	assert.Error(t, err)
 	err = conn.Save(u).Error
 	require.NoError(t, err)
@@ -96,6 +181,22 @@ func TestUser_Authenticate(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestUser_NeedsRehash(t *testing.T) {
+	t.Parallel()
+	const testPass = "password"
+	hash, err := hasher.Hash(testPass)
+	require.NoError(t, err)
+	u := &User{Password: hash}
+	assert.False(t, u.NeedsRehash())
+	bc, err := hasher.NewBcrypt(0)
+	require.NoError(t, err)
+	u.Password, err = bc.Hash(testPass)
+	require.NoError(t, err)
+	assert.True(t, u.NeedsRehash())
+	u.Password = nil
+	assert.True(t, u.NeedsRehash())
+}
+
 func TestUser_Status(t *testing.T) {
 	t.Parallel()
 	email := tutils.RandomEmail()
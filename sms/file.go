@@ -0,0 +1,53 @@
+package sms
+
+import (
+	"io"
+	"os"
+	"sync"
+
+	"github.com/segmentio/encoding/json"
+)
+
+// FileSender writes sms messages to a file as json lines.
+// It is intended for development and testing.
+type FileSender struct {
+	path string
+	from string
+	mu   sync.Mutex
+	out  io.Writer
+}
+
+var _ SMSSender = (*FileSender)(nil)
+
+// NewFileSender returns a new file sms sender. If path
+// is empty messages are written to stdout instead.
+func NewFileSender(path, from string) *FileSender {
+	return &FileSender{path: path, from: from, out: os.Stdout}
+}
+
+// Send appends the message to the file.
+func (s *FileSender) Send(to, message string) error {
+	b, err := json.Marshal(&Message{
+		From:    s.from,
+		To:      to,
+		Message: message,
+	})
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.path == "" {
+		_, err = s.out.Write(b)
+		return err
+	}
+	const perm = 0600
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(b)
+	return err
+}
@@ -0,0 +1,46 @@
+package sms
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSender_Send(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "sms.log")
+	s := NewFileSender(path, testFrom)
+	err := s.Send(testTo, testMsg)
+	require.NoError(t, err)
+	err = s.Send(testTo, testMsg)
+	require.NoError(t, err)
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	require.Len(t, lines, 2)
+	for _, line := range lines {
+		var msg Message
+		err = json.Unmarshal([]byte(line), &msg)
+		require.NoError(t, err)
+		assert.Equal(t, testFrom, msg.From)
+		assert.Equal(t, testTo, msg.To)
+		assert.Equal(t, testMsg, msg.Message)
+	}
+	// stdout
+	var buf bytes.Buffer
+	s = NewFileSender("", testFrom)
+	s.out = &buf
+	err = s.Send(testTo, testMsg)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), testMsg)
+	// bad path
+	s = NewFileSender(t.TempDir(), testFrom)
+	err = s.Send(testTo, testMsg)
+	assert.Error(t, err)
+}
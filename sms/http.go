@@ -0,0 +1,71 @@
+package sms
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/segmentio/encoding/json"
+)
+
+// HTTPSender sends sms messages through a generic http provider.
+// Messages are POSTed to the provider url as a json Message.
+type HTTPSender struct {
+	url    string
+	token  string
+	from   string
+	client http.Client
+}
+
+var _ SMSSender = (*HTTPSender)(nil)
+
+// NewHTTPSender returns a new http sms sender. If token is
+// not empty it is sent to the provider as a bearer token.
+func NewHTTPSender(url, token, from string) *HTTPSender {
+	return &HTTPSender{
+		url:   url,
+		token: token,
+		from:  from,
+		client: http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Send sends the message to the http provider.
+func (s *HTTPSender) Send(to, message string) error {
+	b, err := json.Marshal(&Message{
+		From:    s.from,
+		To:      to,
+		Message: message,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < http.StatusOK ||
+		res.StatusCode >= http.StatusMultipleChoices {
+		b, err = ioutil.ReadAll(res.Body)
+		if err != nil {
+			return err
+		}
+		body := strings.TrimRight(string(b), "\n")
+		return fmt.Errorf("%s: %s", res.Status, body)
+	}
+	return nil
+}
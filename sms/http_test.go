@@ -0,0 +1,55 @@
+package sms
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testFrom  = "Gothic"
+	testTo    = "+15555550100"
+	testMsg   = "Gothic verification code: 123456"
+	testToken = "i-am-an-sms-token"
+)
+
+func TestHTTPSender_Send(t *testing.T) {
+	t.Parallel()
+	var msg Message
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		if r.Header.Get("Authorization") != "Bearer "+testToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		err := json.NewDecoder(r.Body).Decode(&msg)
+		require.NoError(t, err)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(srv.Close)
+	s := NewHTTPSender(srv.URL, testToken, testFrom)
+	err := s.Send(testTo, testMsg)
+	require.NoError(t, err)
+	assert.Equal(t, Message{
+		From:    testFrom,
+		To:      testTo,
+		Message: testMsg,
+	}, msg)
+	// bad token
+	s = NewHTTPSender(srv.URL, "bad", testFrom)
+	err = s.Send(testTo, testMsg)
+	assert.Error(t, err)
+	// bad url
+	s = NewHTTPSender("\n", testToken, testFrom)
+	err = s.Send(testTo, testMsg)
+	assert.Error(t, err)
+	// no server
+	s = NewHTTPSender("http://localhost:0", testToken, testFrom)
+	err = s.Send(testTo, testMsg)
+	assert.Error(t, err)
+}
@@ -0,0 +1,36 @@
+package sms
+
+import (
+	"fmt"
+
+	"github.com/jrapoport/gothic/config"
+)
+
+// SMSSender sends sms messages.
+type SMSSender interface {
+	// Send sends the message to an E.164 formatted phone number.
+	Send(to, message string) error
+}
+
+// NewSMSSender returns a new sms sender for the config. If
+// no sms sender is configured, NewSMSSender returns nil.
+func NewSMSSender(c *config.Config) (SMSSender, error) {
+	s := c.SMS
+	switch s.Sender {
+	case "":
+		return nil, nil
+	case config.SMSHTTP:
+		return NewHTTPSender(s.URL, s.Token, s.From), nil
+	case config.SMSFile:
+		return NewFileSender(s.File, s.From), nil
+	default:
+		return nil, fmt.Errorf("invalid sms sender: %s", s.Sender)
+	}
+}
+
+// Message is an outbound sms message.
+type Message struct {
+	From    string `json:"from,omitempty"`
+	To      string `json:"to"`
+	Message string `json:"message"`
+}
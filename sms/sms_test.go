@@ -0,0 +1,32 @@
+package sms
+
+import (
+	"testing"
+
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/test/tconf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSMSSender(t *testing.T) {
+	t.Parallel()
+	c := tconf.Config(t)
+	c.SMS.Sender = ""
+	s, err := NewSMSSender(c)
+	assert.NoError(t, err)
+	assert.Nil(t, s)
+	c.SMS.Sender = config.SMSHTTP
+	c.SMS.URL = "http://sms.example.com"
+	s, err = NewSMSSender(c)
+	require.NoError(t, err)
+	assert.IsType(t, &HTTPSender{}, s)
+	c.SMS.Sender = config.SMSFile
+	s, err = NewSMSSender(c)
+	require.NoError(t, err)
+	assert.IsType(t, &FileSender{}, s)
+	c.SMS.Sender = "bad"
+	s, err = NewSMSSender(c)
+	assert.Error(t, err)
+	assert.Nil(t, s)
+}
@@ -1,6 +1,9 @@
 package store
 
-import "github.com/jrapoport/gothic/store/migration"
+import (
+	"github.com/jrapoport/gothic/store/migration"
+	"gorm.io/gorm"
+)
 
 var plan *migration.Plan
 
@@ -25,3 +28,17 @@ func AddAutoMigrationWithIndexes(id string, model interface{}, indexes []string)
 	m := migration.NewMigrationWithIndexes(id, model, indexes)
 	AddAutoMigrationToPlan(m)
 }
+
+// AddAutoMigrationReplacingIndexes adds a model to the global migration plan with
+// indexes. The replaced indexes are dropped (if found) before the model is migrated.
+func AddAutoMigrationReplacingIndexes(id string, model interface{}, indexes, replaced []string) {
+	m := migration.NewMigrationReplacingIndexes(id, model, indexes, replaced)
+	AddAutoMigrationToPlan(m)
+}
+
+// AddAutoMigrationWithUpdate adds a model to the global migration plan with
+// indexes. The update is run after the model is migrated.
+func AddAutoMigrationWithUpdate(id string, model interface{}, indexes []string, update func(tx *gorm.DB) error) {
+	m := migration.NewMigrationWithUpdate(id, model, indexes, update)
+	AddAutoMigrationToPlan(m)
+}
@@ -76,6 +76,10 @@ func NewConnection(ctx context.Context, c *config.Config, l log.Logger) (*Connec
 		err = fmt.Errorf("error opening database: %w", err)
 		return nil, err
 	}
+	err = registerTenantCallbacks(db)
+	if err != nil {
+		return nil, err
+	}
 	return &Connection{db}, nil
 }
 
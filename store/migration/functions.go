@@ -80,6 +80,28 @@ func migrateIndexes(tx *gorm.DB, dst interface{}, indexes []string) error {
 	return nil
 }
 
+// dropIndexes drops the indexes & their namespaced equivalents (if found).
+func dropIndexes(tx *gorm.DB, dst interface{}, indexes []string) error {
+	name, err := tableName(tx, dst)
+	if err != nil {
+		return err
+	}
+	const idxPrefix = "idx_"
+	for _, idx := range indexes {
+		nsIdx := idxPrefix + name + "_" + strings.TrimPrefix(idx, idxPrefix)
+		for _, n := range []string{idx, nsIdx} {
+			if !tx.Migrator().HasIndex(dst, n) {
+				continue
+			}
+			err = tx.Migrator().DropIndex(dst, n)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func dropIndexIfExists(tx *gorm.DB, name string) error {
 	return tx.Exec("DROP INDEX IF EXISTS ?", clause.Column{Name: name}).Error
 }
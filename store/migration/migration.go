@@ -41,3 +41,33 @@ func NewMigrationWithIndexes(id string, model interface{}, indexes []string) *Mi
 		indexes: indexes,
 	}
 }
+
+// NewMigrationReplacingIndexes returns a new Migration for the indexed model
+// with id that drops the replaced indexes (if found) before it migrates.
+func NewMigrationReplacingIndexes(id string, model interface{}, indexes, replaced []string) *Migration {
+	m := NewMigrationWithIndexes(id, model, indexes)
+	mg := m.Migrate
+	m.Migrate = func(tx *gorm.DB) error {
+		err := dropIndexes(tx, model, replaced)
+		if err != nil {
+			return err
+		}
+		return mg(tx)
+	}
+	return m
+}
+
+// NewMigrationWithUpdate returns a new Migration for the indexed model with
+// id that runs update after it migrates, e.g. to backfill a new column.
+func NewMigrationWithUpdate(id string, model interface{}, indexes []string, update func(tx *gorm.DB) error) *Migration {
+	m := NewMigrationWithIndexes(id, model, indexes)
+	mg := m.Migrate
+	m.Migrate = func(tx *gorm.DB) error {
+		err := mg(tx)
+		if err != nil {
+			return err
+		}
+		return update(tx)
+	}
+	return m
+}
@@ -47,3 +47,61 @@ func TestMigration_Run(t *testing.T) {
 	has = db.Migrator().HasIndex(mb, ModelBIndex)
 	assert.False(t, has)
 }
+
+// ModelB2 replaces the index of ModelB for tests.
+type ModelB2 struct {
+	gorm.Model
+	Value string `gorm:"index:idx_value_v2"`
+}
+
+// TableName returns the table name of ModelB.
+func (ModelB2) TableName() string {
+	return "model_bs"
+}
+
+func TestMigration_ReplacingIndexes(t *testing.T) {
+	t.Parallel()
+	const (
+		tableIdx   = "idx_model_bs_value"
+		tableIdxV2 = "idx_model_bs_value_v2"
+	)
+	db := tdb.DB(t)
+	mb := ModelB{}
+	err := NewMigrationWithIndexes("B", mb, []string{ModelBIndex}).Run(db)
+	assert.NoError(t, err)
+	has := db.Migrator().HasIndex(mb, tableIdx)
+	assert.True(t, has)
+	mb2 := ModelB2{}
+	mig := NewMigrationReplacingIndexes("B2", mb2,
+		[]string{"idx_value_v2"}, []string{ModelBIndex})
+	assert.NotNil(t, mig)
+	err = mig.Run(db)
+	assert.NoError(t, err)
+	has = db.Migrator().HasIndex(mb2, tableIdx)
+	assert.False(t, has)
+	has = db.Migrator().HasIndex(mb2, tableIdxV2)
+	assert.True(t, has)
+}
+
+func TestMigration_WithUpdate(t *testing.T) {
+	t.Parallel()
+	db := tdb.DB(t)
+	ma := ModelA{}
+	err := NewMigration("A", ma).Run(db)
+	assert.NoError(t, err)
+	err = db.Create(&ModelA{}).Error
+	assert.NoError(t, err)
+	update := func(tx *gorm.DB) error {
+		return tx.Model(&ModelA{}).
+			Where("value = ?", "").
+			Update("value", "updated").Error
+	}
+	mig := NewMigrationWithUpdate("A2", ma, nil, update)
+	assert.NotNil(t, mig)
+	err = mig.Run(db)
+	assert.NoError(t, err)
+	var test ModelA
+	err = db.First(&test).Error
+	assert.NoError(t, err)
+	assert.Equal(t, "updated", test.Value)
+}
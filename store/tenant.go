@@ -0,0 +1,154 @@
+package store
+
+import (
+	"reflect"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	tenantSetting = "gothic:tenant_id"
+	tenantField   = "TenantID"
+)
+
+// Tenant returns a copy of the connection scoped to the tenant. Queries,
+// updates & deletes of models with a TenantID field are restricted to the
+// rows of the tenant, and models that are created are assigned the tenant.
+func (conn *Connection) Tenant(tid uuid.UUID) *Connection {
+	db := conn.DB.Set(tenantSetting, tid).Session(&gorm.Session{})
+	return &Connection{db}
+}
+
+// TenantID returns the tenant id of the connection and true if the
+// connection is scoped to a tenant.
+func (conn *Connection) TenantID() (uuid.UUID, bool) {
+	return tenantID(conn.DB)
+}
+
+func tenantID(db *gorm.DB) (uuid.UUID, bool) {
+	v, ok := db.Get(tenantSetting)
+	if !ok {
+		return uuid.Nil, false
+	}
+	tid, ok := v.(uuid.UUID)
+	return tid, ok
+}
+
+func registerTenantCallbacks(db *gorm.DB) error {
+	const name = "gothic:tenant"
+	cb := db.Callback()
+	err := cb.Create().Before("gorm:create").Register(name, tenantCreate)
+	if err != nil {
+		return err
+	}
+	err = cb.Query().Before("gorm:query").Register(name, tenantWhere)
+	if err != nil {
+		return err
+	}
+	err = cb.Row().Before("gorm:row").Register(name, tenantWhere)
+	if err != nil {
+		return err
+	}
+	err = cb.Update().Before("gorm:update").Register(name, tenantWhereKeyed)
+	if err != nil {
+		return err
+	}
+	return cb.Delete().Before("gorm:delete").Register(name, tenantWhereKeyed)
+}
+
+func tenantSchemaField(db *gorm.DB) (uuid.UUID, *schema.Field, bool) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return uuid.Nil, nil, false
+	}
+	tid, ok := tenantID(db)
+	if !ok {
+		return uuid.Nil, nil, false
+	}
+	f := db.Statement.Schema.LookUpField(tenantField)
+	if f == nil {
+		return uuid.Nil, nil, false
+	}
+	return tid, f, true
+}
+
+// tenantCreate assigns the tenant to the models being created.
+func tenantCreate(db *gorm.DB) {
+	tid, f, ok := tenantSchemaField(db)
+	if !ok {
+		return
+	}
+	ctx := db.Statement.Context
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			el := reflect.Indirect(rv.Index(i))
+			if el.Kind() != reflect.Struct {
+				continue
+			}
+			_ = db.AddError(f.Set(ctx, el, tid))
+		}
+	case reflect.Struct:
+		_ = db.AddError(f.Set(ctx, rv, tid))
+	}
+}
+
+// tenantWhere restricts the statement to the rows of the tenant.
+func tenantWhere(db *gorm.DB) {
+	tid, f, ok := tenantSchemaField(db)
+	if !ok {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{
+			Column: clause.Column{Table: clause.CurrentTable, Name: f.DBName},
+			Value:  tid,
+		},
+	}})
+}
+
+// tenantWhereKeyed restricts updates & deletes to the rows of the tenant. If
+// the statement has no conditions it is left for gorm to reject as a global
+// update instead of turning it into an update of every row of the tenant.
+func tenantWhereKeyed(db *gorm.DB) {
+	if !db.AllowGlobalUpdate && !hasConditions(db) && !hasPrimaryKey(db) {
+		return
+	}
+	tenantWhere(db)
+}
+
+// hasConditions mirrors the gorm check for missing where conditions.
+func hasConditions(db *gorm.DB) bool {
+	c, ok := db.Statement.Clauses["WHERE"]
+	if !ok {
+		return false
+	}
+	if _, sd := db.Statement.Clauses["soft_delete_enabled"]; sd {
+		where, _ := c.Expression.(clause.Where)
+		return len(where.Exprs) > 1
+	}
+	return true
+}
+
+func hasPrimaryKey(db *gorm.DB) bool {
+	if db.Statement.Schema == nil {
+		return false
+	}
+	pf := db.Statement.Schema.PrioritizedPrimaryField
+	if pf == nil {
+		return false
+	}
+	rv := reflect.Indirect(db.Statement.ReflectValue)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		return rv.Len() > 0
+	case reflect.Struct:
+		_, zero := pf.ValueOf(db.Statement.Context, rv)
+		return !zero
+	default:
+		return false
+	}
+}
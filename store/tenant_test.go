@@ -0,0 +1,121 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jrapoport/gothic/store/migration"
+	"github.com/jrapoport/gothic/test/tdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type tenantModel struct {
+	gorm.Model
+	TenantID uuid.UUID `gorm:"type:char(36);default:'00000000-0000-0000-0000-000000000000'"`
+	Value    string
+}
+
+type globalModel struct {
+	gorm.Model
+	Value string
+}
+
+func tenantConn(t *testing.T) *Connection {
+	db := tdb.DB(t)
+	err := registerTenantCallbacks(db)
+	require.NoError(t, err)
+	p := migration.NewPlan()
+	p.AddMigrations([]*migration.Migration{
+		migration.NewMigration("1", tenantModel{}),
+		migration.NewMigration("2", globalModel{}),
+	})
+	err = p.Run(db, false)
+	require.NoError(t, err)
+	return &Connection{db}
+}
+
+func TestConnection_Tenant(t *testing.T) {
+	t.Parallel()
+	const testValue = "test-value"
+	conn := tenantConn(t)
+	_, ok := conn.TenantID()
+	assert.False(t, ok)
+	tidA := uuid.New()
+	tidB := uuid.New()
+	connA := conn.Tenant(tidA)
+	connB := conn.Tenant(tidB)
+	tid, ok := connA.TenantID()
+	assert.True(t, ok)
+	assert.Equal(t, tidA, tid)
+	// create
+	ma := &tenantModel{Value: testValue}
+	err := connA.Create(ma).Error
+	require.NoError(t, err)
+	assert.Equal(t, tidA, ma.TenantID)
+	mb := []*tenantModel{{Value: testValue}, {Value: testValue}}
+	err = connB.Create(&mb).Error
+	require.NoError(t, err)
+	for _, m := range mb {
+		assert.Equal(t, tidB, m.TenantID)
+	}
+	mn := &tenantModel{Value: testValue}
+	err = conn.Create(mn).Error
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Nil, mn.TenantID)
+	// query
+	var list []tenantModel
+	err = connA.Where("value = ?", testValue).Find(&list).Error
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+	err = connB.Find(&list).Error
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+	err = conn.Tenant(uuid.Nil).Find(&list).Error
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+	err = conn.Find(&list).Error
+	require.NoError(t, err)
+	assert.Len(t, list, 4)
+	has, err := connB.Has(&tenantModel{}, ma.ID)
+	require.NoError(t, err)
+	assert.False(t, has)
+	var count int64
+	err = connA.Model(&tenantModel{}).Count(&count).Error
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	// transactions keep the tenant
+	err = connA.Transaction(func(tx *Connection) error {
+		tid, ok = tx.TenantID()
+		assert.True(t, ok)
+		assert.Equal(t, tidA, tid)
+		return tx.Find(&list).Error
+	})
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+	// update
+	res := connB.Model(&tenantModel{}).
+		Where("id = ?", ma.ID).Update("value", "b")
+	require.NoError(t, res.Error)
+	assert.Zero(t, res.RowsAffected)
+	res = connA.Model(ma).Update("value", "a")
+	require.NoError(t, res.Error)
+	assert.Equal(t, int64(1), res.RowsAffected)
+	err = connA.Model(&tenantModel{}).Update("value", "a").Error
+	assert.ErrorIs(t, err, gorm.ErrMissingWhereClause)
+	// delete
+	res = connB.Delete(&tenantModel{}, ma.ID)
+	require.NoError(t, res.Error)
+	assert.Zero(t, res.RowsAffected)
+	res = connA.Delete(ma)
+	require.NoError(t, res.Error)
+	assert.Equal(t, int64(1), res.RowsAffected)
+	// models without a tenant are not scoped
+	g := &globalModel{Value: testValue}
+	err = connA.Create(g).Error
+	require.NoError(t, err)
+	has, err = connB.Has(&globalModel{}, g.ID)
+	require.NoError(t, err)
+	assert.True(t, has)
+}
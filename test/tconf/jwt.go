@@ -0,0 +1,31 @@
+package tconf
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/test/tutils"
+	"github.com/lestrrat-go/jwx/jwa"
+)
+
+// RSAKeyID is the key id of the test rsa signing key.
+const RSAKeyID = "test-rsa-key"
+
+// RSAKeys configures jwt tokens to be signed with an rsa key pair.
+func RSAKeys(t *testing.T, c *config.Config) *config.Config {
+	const rsaKey = "jwt/testdata/rsa-key.pem"
+	root := tutils.ProjectRoot(t)
+	c.JWT = config.JWT{
+		PEM: config.PEM{
+			PrivateKey: filepath.Join(root, rsaKey),
+		},
+		Algorithm:  string(jwa.RS256),
+		Expiration: c.JWT.Expiration,
+		Issuer:     c.JWT.Issuer,
+		Audience:   c.JWT.Audience,
+		Scope:      c.JWT.Scope,
+		KeyID:      RSAKeyID,
+	}
+	return c
+}
@@ -0,0 +1,48 @@
+package tconf
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/jrapoport/gothic/config"
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/require"
+)
+
+// MockSMS configures a file sms sender and returns the path it writes to.
+func MockSMS(t *testing.T, c *config.Config) (*config.Config, string) {
+	c.SMS.Sender = config.SMSFile
+	c.SMS.File = filepath.Join(t.TempDir(), "sms.log")
+	return c, c.SMS.File
+}
+
+// GetSMSCode returns the last code sent to the phone number, or
+// an empty string if no code was sent to the phone number.
+func GetSMSCode(t *testing.T, path, phone string) string {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return ""
+	}
+	require.NoError(t, err)
+	defer f.Close()
+	rx := regexp.MustCompile(`[0-9]{6}`)
+	var code string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		var msg struct {
+			To      string `json:"to"`
+			Message string `json:"message"`
+		}
+		err = json.Unmarshal(s.Bytes(), &msg)
+		require.NoError(t, err)
+		if msg.To != phone {
+			continue
+		}
+		code = rx.FindString(msg.Message)
+	}
+	require.NoError(t, s.Err())
+	return code
+}
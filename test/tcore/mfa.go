@@ -0,0 +1,42 @@
+package tcore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jrapoport/gothic/core"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/models/factor"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/require"
+)
+
+// EnrollTOTP enrolls and confirms totp for a test user and
+// returns the secret. The code for the current period is used.
+func EnrollTOTP(t *testing.T, a *core.API, u *user.User) string {
+	ctx := context.Background()
+	ctx.SetProvider(a.Provider())
+	e, err := a.EnrollTOTP(ctx, u.ID)
+	require.NoError(t, err)
+	require.NotNil(t, e)
+	code := TOTPCode(t, e.Secret, 0)
+	err = a.ConfirmTOTP(ctx, u.ID, code)
+	require.NoError(t, err)
+	return e.Secret
+}
+
+// TOTPCode returns a totp code for the secret offset from
+// the current period by skew periods. Since codes cannot be
+// reused, tests use the skew to generate more than one code.
+func TOTPCode(t *testing.T, secret string, skew int) string {
+	at := time.Now().UTC().Add(time.Duration(skew*factor.Period) * time.Second)
+	code, err := totp.GenerateCodeCustom(secret, at, totp.ValidateOpts{
+		Period:    factor.Period,
+		Digits:    factor.Digits,
+		Algorithm: otp.AlgorithmSHA1,
+	})
+	require.NoError(t, err)
+	return code
+}
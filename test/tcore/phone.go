@@ -0,0 +1,37 @@
+package tcore
+
+import (
+	"testing"
+
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/core"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/jrapoport/gothic/test/tconf"
+	"github.com/jrapoport/gothic/test/tutils"
+	"github.com/stretchr/testify/require"
+)
+
+// MockSMS configures the api with a file sms sender and
+// returns the path to the file the messages are written to.
+func MockSMS(t *testing.T, a *core.API, c *config.Config) string {
+	_, path := tconf.MockSMS(t, c)
+	err := a.OpenSMS()
+	require.NoError(t, err)
+	return path
+}
+
+// ConfirmPhone adds a random confirmed phone number to a test user and returns
+// it. The api must be configured to send sms messages to the path.
+func ConfirmPhone(t *testing.T, a *core.API, path string, u *user.User) string {
+	ctx := context.Background()
+	ctx.SetProvider(a.Provider())
+	phone := tutils.RandomPhone()
+	err := a.SendChangePhone(ctx, u.ID, phone)
+	require.NoError(t, err)
+	code := tconf.GetSMSCode(t, path, phone)
+	require.NotEmpty(t, code)
+	_, err = a.ConfirmChangePhone(ctx, u.ID, phone, code)
+	require.NoError(t, err)
+	return phone
+}
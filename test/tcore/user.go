@@ -3,8 +3,10 @@ package tcore
 import (
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/jrapoport/gothic/core"
 	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/models/rbac"
 	"github.com/jrapoport/gothic/models/user"
 	"github.com/jrapoport/gothic/test/tutils"
 	"github.com/stretchr/testify/require"
@@ -36,3 +38,20 @@ func TestUser(t *testing.T, a *core.API, pass string, admin bool) (*user.User, s
 	require.NotNil(t, bt)
 	return u, bt.String()
 }
+
+// TestRoleUser creates a test user with a custom role that has the permissions.
+func TestRoleUser(t *testing.T, a *core.API, perms ...rbac.Permission) (*user.User, string) {
+	u, _ := TestUser(t, a, "", false)
+	ctx := context.Background()
+	ctx.SetProvider(a.Provider())
+	ctx.SetAdminID(user.SuperAdminID)
+	name := "role-" + uuid.New().String()[:8]
+	r, err := a.CreateRole(ctx, name, "", rbac.Strings(perms))
+	require.NoError(t, err)
+	err = a.AssignUserRole(ctx, u.ID, r.Name)
+	require.NoError(t, err)
+	bt, err := a.GrantBearerToken(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, bt)
+	return u, bt.String()
+}
@@ -0,0 +1,69 @@
+package tcore
+
+import (
+	"testing"
+
+	"github.com/descope/virtualwebauthn"
+	"github.com/jrapoport/gothic/config"
+	"github.com/jrapoport/gothic/core"
+	"github.com/jrapoport/gothic/core/context"
+	"github.com/jrapoport/gothic/models/user"
+	"github.com/stretchr/testify/require"
+)
+
+// Authenticator is a software webauthn authenticator for tests.
+type Authenticator struct {
+	virtualwebauthn.Authenticator
+	RP         virtualwebauthn.RelyingParty
+	Credential virtualwebauthn.Credential
+}
+
+// NewAuthenticator returns a new software authenticator
+// with a single credential for the relying party.
+func NewAuthenticator(c *config.Config) *Authenticator {
+	return &Authenticator{
+		Authenticator: virtualwebauthn.NewAuthenticator(),
+		RP: virtualwebauthn.RelyingParty{
+			ID:     c.WebAuthn.RPID,
+			Name:   c.WebAuthn.RPName,
+			Origin: c.WebAuthn.Origins[0],
+		},
+		Credential: virtualwebauthn.NewCredential(virtualwebauthn.KeyTypeEC2),
+	}
+}
+
+// AttestationResponse returns the attestation response for
+// the json encoded webauthn registration options.
+func (a *Authenticator) AttestationResponse(t *testing.T, options []byte) []byte {
+	opts, err := virtualwebauthn.ParseAttestationOptions(string(options))
+	require.NoError(t, err)
+	res := virtualwebauthn.CreateAttestationResponse(a.RP,
+		a.Authenticator, a.Credential, *opts)
+	a.Options.UserHandle = []byte(opts.UserID)
+	a.AddCredential(a.Credential)
+	return []byte(res)
+}
+
+// AssertionResponse returns the assertion response for
+// the json encoded webauthn login options.
+func (a *Authenticator) AssertionResponse(t *testing.T, options []byte) []byte {
+	opts, err := virtualwebauthn.ParseAssertionOptions(string(options))
+	require.NoError(t, err)
+	res := virtualwebauthn.CreateAssertionResponse(a.RP,
+		a.Authenticator, a.Credential, *opts)
+	return []byte(res)
+}
+
+// RegisterWebAuthn registers a webauthn credential for a test user
+// and returns the authenticator that holds the credential.
+func RegisterWebAuthn(t *testing.T, a *core.API, c *config.Config, u *user.User) *Authenticator {
+	ctx := context.Background()
+	ctx.SetProvider(a.Provider())
+	auth := NewAuthenticator(c)
+	cer, err := a.BeginWebAuthnRegistration(ctx, u.ID)
+	require.NoError(t, err)
+	res := auth.AttestationResponse(t, cer.Options)
+	_, err = a.FinishWebAuthnRegistration(ctx, u.ID, cer.Token, "passkey", res)
+	require.NoError(t, err)
+	return auth
+}
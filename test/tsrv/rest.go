@@ -24,6 +24,9 @@ func RESTServer(t *testing.T, smtp bool) (*rest.Server, *tconf.SMTPMock) {
 func RESTHost(t *testing.T, reg []rest.RegisterServer, smtp bool) (*rest.Host, *httptest.Server, *tconf.SMTPMock) {
 	a, c, mock := tcore.API(t, smtp)
 	rt := rest.NewRouter(c)
+	rt.UseRevocation(a.ValidateBearerToken)
+	rt.UsePersonalTokens(a.ValidatePersonalToken)
+	rt.UseImpersonationLog(a.LogImpersonation)
 	web := httptest.NewServer(rt)
 	for i, r := range reg {
 		reg[i] = func(s *http.Server, srv *rest.Server) {
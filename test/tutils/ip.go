@@ -0,0 +1,13 @@
+package tutils
+
+import (
+	"crypto/rand"
+	"net"
+)
+
+// RandomIP returns a random private ip address for tests.
+func RandomIP() string {
+	b := make([]byte, 3)
+	_, _ = rand.Read(b)
+	return net.IPv4(10, b[0], b[1], b[2]).String()
+}
@@ -0,0 +1,10 @@
+package tutils
+
+import (
+	"github.com/jrapoport/gothic/utils"
+)
+
+// RandomPhone returns a random E.164 phone number for tests.
+func RandomPhone() string {
+	return "+1555" + utils.RandomPIN(7)
+}
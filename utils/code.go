@@ -1,5 +1,11 @@
 package utils
 
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+)
+
 // maxPIN is the max length of a PINCode (6).
 const maxPIN = 6
 
@@ -23,3 +29,39 @@ func PINCode() string {
 func IsValidCode(code string) bool {
 	return len(code) > 0
 }
+
+// userCodeChars are the characters of a user code. There are no vowels so
+// a user code can not spell a word, and no characters that look alike.
+const userCodeChars = "BCDFGHJKLMNPQRSTVWXZ"
+
+// userCodeLen is the length of a user code (8).
+const userCodeLen = 8
+
+// UserCode returns a new random user code that is easy to type (e.g. WDJB-MJHT).
+func UserCode() string {
+	b := make([]byte, userCodeLen)
+	max := big.NewInt(int64(len(userCodeChars)))
+	for i := range b {
+		// rand should never fail, if it does we have bigger problems
+		n, _ := rand.Int(rand.Reader, max)
+		b[i] = userCodeChars[n.Int64()]
+	}
+	return FormatUserCode(string(b))
+}
+
+// FormatUserCode returns the user code in its canonical form. Lowercase
+// characters are converted and characters that are not part of a user
+// code (like dashes and spaces) are removed.
+func FormatUserCode(code string) string {
+	code = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(userCodeChars, r) {
+			return r
+		}
+		return -1
+	}, strings.ToUpper(code))
+	if len(code) != userCodeLen {
+		return code
+	}
+	half := userCodeLen / 2
+	return code[:half] + "-" + code[half:]
+}
@@ -22,3 +22,32 @@ func TestIsValidPIN(t *testing.T) {
 	is = IsValidCode("")
 	assert.False(t, is)
 }
+
+func TestUserCode(t *testing.T) {
+	t.Parallel()
+	code := UserCode()
+	assert.Len(t, code, userCodeLen+1)
+	assert.Equal(t, "-", code[4:5])
+	assert.Equal(t, code, FormatUserCode(code))
+	assert.NotEqual(t, code, UserCode())
+}
+
+func TestFormatUserCode(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		code   string
+		format string
+	}{
+		{"", ""},
+		{"WDJB-MJHT", "WDJB-MJHT"},
+		{"WDJBMJHT", "WDJB-MJHT"},
+		{"wdjb-mjht", "WDJB-MJHT"},
+		{" wdjb mjht ", "WDJB-MJHT"},
+		{"WDJB", "WDJB"},
+		{"WDJB-MJHTX", "WDJBMJHTX"},
+		{"AEIO-1234", ""},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.format, FormatUserCode(test.code))
+	}
+}
@@ -5,8 +5,6 @@ import (
 	"encoding/base64"
 	"io"
 	"strings"
-
-	"golang.org/x/crypto/bcrypt"
 )
 
 // SecureToken creates a new random token
@@ -17,18 +15,6 @@ func SecureToken() string {
 	return removePadding(base64.URLEncoding.EncodeToString(b))
 }
 
-// HashPassword generates a hashed password from a plaintext string
-func HashPassword(password string) []byte {
-	// we can safely ignore any error because we control the cost
-	pw, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	return pw
-}
-
-// CheckPassword checks to see if the password matches the hashed password.
-func CheckPassword(hash []byte, password string) error {
-	return bcrypt.CompareHashAndPassword(hash, []byte(password))
-}
-
 func removePadding(token string) string {
 	return strings.TrimRight(token, "=")
 }
@@ -4,7 +4,6 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/require"
 )
 
 func TestSecureToken(t *testing.T) {
@@ -15,21 +14,3 @@ func TestSecureToken(t *testing.T) {
 	assert.NotEmpty(t, tok2)
 	assert.NotEqual(t, tok1, tok2)
 }
-
-func TestHashPassword(t *testing.T) {
-	t.Parallel()
-	const pw = "SXJAm7qJ4?3dH!aN8T3f5p!oNnpXbaRy#Gtx#8jG"
-	hash := HashPassword(pw)
-	assert.NotEmpty(t, hash)
-}
-
-func TestCheckPassword(t *testing.T) {
-	t.Parallel()
-	const pw = "SXJAm7qJ4?3dH!aN8T3f5p!oNnpXbaRy#Gtx#8jG"
-	hash := HashPassword(pw)
-	require.NotEmpty(t, hash)
-	err := CheckPassword(hash, pw)
-	assert.NoError(t, err)
-	err = CheckPassword(hash, "")
-	assert.Error(t, err)
-}